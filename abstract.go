@@ -0,0 +1,234 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// derivedTypesLangs holds the languages the derived types of the abstract
+// types are generated for, as a Go interface, a Rust enum and a TypeScript
+// union type. The references to the abstract types are generated as the
+// abstract types in the other languages.
+var derivedTypesLangs = map[string]bool{"Go": true, "Rust": true, "TypeScript": true}
+
+// xsiNamespace is the namespace of the xsi:type attribute naming the type of
+// the element in the instances.
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// resolveDerivedTypes adds the derived types of each abstract complex type
+// to the proto tree after the abstract type, that is the named complex types
+// which aren't abstract and are derived from the abstract type by extension
+// directly or through the other types. The references to the abstract types
+// in the complex types and the groups are given the type of the derived
+// types. The abstract types are kept, as the structs of the derived types
+// embed them.
+func (opt *Options) resolveDerivedTypes() {
+	if !derivedTypesLangs[opt.Lang] {
+		return
+	}
+	derivedTypes := map[string]string{}
+	protoTree := make([]interface{}, 0, len(opt.ProtoTree))
+	for _, ele := range opt.ProtoTree {
+		protoTree = append(protoTree, ele)
+		v, ok := ele.(*ComplexType)
+		if !ok || !v.Abstract {
+			continue
+		}
+		derived := &DerivedTypes{Doc: v.Doc, Name: v.Name + "Derived", Abstract: v.Name}
+		for _, ele := range opt.ProtoTree {
+			if c, ok := ele.(*ComplexType); ok && !c.Abstract && !c.Anonymous && isDerivedType(c, v, opt.ProtoTree) {
+				derived.Members = append(derived.Members, Element{Name: c.Name, Type: c.Name})
+			}
+		}
+		if len(derived.Members) == 0 {
+			continue
+		}
+		derivedTypes[v.Name] = derived.Name
+		protoTree = append(protoTree, derived)
+	}
+	opt.ProtoTree = protoTree
+	substitute := func(elements []Element) {
+		for i, element := range elements {
+			if derived, ok := derivedTypes[trimNSPrefix(element.Type)]; ok {
+				elements[i].Type = derived
+			}
+		}
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *ComplexType:
+			substitute(v.Elements)
+		case *Group:
+			substitute(v.Elements)
+		}
+	}
+}
+
+// isDerivedType returns true if the complex type is derived from the given
+// base type by extension directly or through the other types.
+func isDerivedType(v, base *ComplexType, XSDSchema []interface{}) bool {
+	visited := map[*ComplexType]bool{v: true}
+	for ancestor := getExtensionBase(v, XSDSchema); ancestor != nil && !visited[ancestor]; ancestor = getExtensionBase(ancestor, XSDSchema) {
+		if ancestor == base {
+			return true
+		}
+		visited[ancestor] = true
+	}
+	return false
+}
+
+// genDerivedTypesDoc returns the documentation of the type of the derived
+// types, listing the concrete types.
+func genDerivedTypesDoc(v *DerivedTypes) string {
+	var names []string
+	for _, member := range v.Members {
+		names = append(names, member.Name)
+	}
+	list := names[len(names)-1]
+	if len(names) > 1 {
+		list = strings.Join(names[:len(names)-1], ", ") + " and " + list
+	}
+	return fmt.Sprintf("a value of the abstract %s type, one of the derived %s types given by the xsi:type attribute.", v.Abstract, list)
+}
+
+// GoDerivedTypes generates code for the derived types of the abstract type
+// in Go language syntax, as an interface implemented by the derived types,
+// and the struct holding the value which is decoded and encoded by the
+// xsi:type attribute of the element. The struct is the type of the fields
+// referencing the abstract type, so the abstract struct isn't decoded by
+// itself.
+func (gen *CodeGenerator) GoDerivedTypes(v *DerivedTypes) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	gen.ImportEncodingXML = true
+	typeName := genGoFieldName(v.Name, false)
+	gen.addSymbol(v, typeName)
+	gen.StructAST[v.Name] = fmt.Sprintf("\tValue\t%sMember\n", typeName)
+	gen.Field += fmt.Sprintf("%stype %s struct {\n%s}\n", gen.genComment(typeName, genDerivedTypesDoc(v)), typeName, gen.StructAST[v.Name])
+	gen.Field += fmt.Sprintf("\n// %sMember is the type of a value of a type derived from the\n// abstract %s type.\ntype %sMember interface {\n\tis%s()\n}\n", typeName, v.Abstract, typeName, typeName)
+	var decode, encode string
+	for _, member := range v.Members {
+		memberType := strings.TrimPrefix(gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(member.Type), gen.ProtoTree)), "*")
+		decode += fmt.Sprintf("\tcase \"%s\":\n\t\tvalue = new(%s)\n", member.Name, memberType)
+		encode += fmt.Sprintf("\tcase *%s:\n\t\ttypeName = \"%s\"\n", memberType, member.Name)
+		gen.Field += fmt.Sprintf("\nfunc (*%s) is%s() {}\n", memberType, typeName)
+	}
+	gen.Field += fmt.Sprintf(`
+// UnmarshalXML decodes the value of the abstract %s type by the derived type
+// given by the xsi:type attribute of the element.
+func (v *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var typeName string
+	for _, attr := range start.Attr {
+		if attr.Name.Space == "%s" && attr.Name.Local == "type" {
+			typeName = attr.Value
+		}
+	}
+	for i := len(typeName) - 1; i >= 0; i-- {
+		if typeName[i] == ':' {
+			typeName = typeName[i+1:]
+			break
+		}
+	}
+	var value %sMember
+	switch typeName {
+%s	default:
+		return xml.UnmarshalError("unexpected type " + typeName + " of the abstract %s type")
+	}
+	if err := d.DecodeElement(value, &start); err != nil {
+		return err
+	}
+	v.Value = value
+	return nil
+}
+
+// MarshalXML encodes the value of the abstract %s type with the xsi:type
+// attribute giving the derived type of the value.
+func (v %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	var typeName string
+	switch v.Value.(type) {
+%s	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "xmlns:xsi"}, Value: "%s"}, xml.Attr{Name: xml.Name{Local: "xsi:type"}, Value: typeName})
+	return e.EncodeElement(v.Value, start)
+}
+`, v.Abstract, typeName, xsiNamespace, typeName, decode, v.Abstract, v.Abstract, typeName, encode, xsiNamespace)
+}
+
+// RustDerivedTypes generates code for the derived types of the abstract type
+// in Rust language syntax, as an enum with a variant per derived type,
+// internally tagged by serde with the xsi:type attribute.
+func (gen *CodeGenerator) RustDerivedTypes(v *DerivedTypes) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	enumName := genRustStructName(v.Name, true)
+	gen.addSymbol(v, enumName)
+	gen.StructAST[v.Name] = enumName
+	if gen.choiceEnums == nil {
+		gen.choiceEnums = map[string]bool{}
+	}
+	gen.choiceEnums[enumName] = true
+	indent, receiver := "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	var variants, validation, normalize string
+	var validated, normalized int
+	variantNames := make([]string, len(v.Members))
+	for i, member := range v.Members {
+		fieldType := getBasefromSimpleType(trimNSPrefix(member.Type), gen.ProtoTree)
+		declType := gen.genRustFieldDeclType(fieldType, false, false)
+		gen.addRustFieldType(enumName, declType)
+		if gen.isRustBoxedField(enumName, declType) {
+			declType = "Box<" + declType + ">"
+		}
+		variantNames[i] = genRustEnumVariantName(member.Name)
+		variants += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\t%s(%s),\n", member.Name, variantNames[i], declType)
+		if body := gen.genRustValueValidation(indent+"\t\t", genRustFieldName(member.Name), "(*value)", fieldType, false, false, &member.Restriction); body != "" {
+			validation += fmt.Sprintf("%s\t%s::%s(value) => {\n%s%s\t}\n", indent, enumName, variantNames[i], body, indent)
+			validated++
+		}
+		if body := gen.genRustValueNormalize(indent+"\t\t", "(*value)", fieldType, false, false, &member.Restriction); body != "" {
+			normalize += fmt.Sprintf("%s\t%s::%s(value) => {\n%s%s\t}\n", indent, enumName, variantNames[i], body, indent)
+			normalized++
+		}
+	}
+	gen.Field += fmt.Sprintf("\n%s#[derive(Debug, PartialEq, Clone, Serialize, Deserialize)]\n#[serde(tag = \"@xsi:type\")]\npub enum %s {\n%s}\n", gen.genComment(enumName, genDerivedTypesDoc(v)), enumName, variants)
+	gen.Field += fmt.Sprintf("\nimpl Default for %s {\n\tfn default() -> Self {\n\t\t%s::%s(Default::default())\n\t}\n}\n", enumName, enumName, variantNames[0])
+	match := func(arms string, count int) string {
+		if count == 0 {
+			return ""
+		}
+		if count < len(v.Members) {
+			arms += indent + "\t_ => {}\n"
+		}
+		return fmt.Sprintf("%smatch %s {\n%s%s}\n", indent, receiver, arms, indent)
+	}
+	gen.genRustValidationCode(enumName, match(validation, validated))
+	gen.genRustNormalizeCode(enumName, match(normalize, normalized))
+}
+
+// TypeScriptDerivedTypes generates code for the derived types of the
+// abstract type in TypeScript language syntax, as the union of the derived
+// types.
+func (gen *CodeGenerator) TypeScriptDerivedTypes(v *DerivedTypes) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	typeName := genTypeScriptFieldName(v.Name, true)
+	gen.addSymbol(v, typeName)
+	var memberTypes []string
+	for _, member := range v.Members {
+		memberTypes = append(memberTypes, gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(member.Type), gen.ProtoTree), false))
+	}
+	gen.StructAST[v.Name] = strings.Join(memberTypes, " | ")
+	gen.Field += fmt.Sprintf("%sexport type %s = %s;\n", gen.genComment(typeName, genDerivedTypesDoc(v)), typeName, gen.StructAST[v.Name])
+}
@@ -0,0 +1,89 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	rustPublicFieldRegexp       = regexp.MustCompile(`(?m)^\tpub ((?:r#)?\w+): (.+),$`)
+	javaProtectedFieldRegexp    = regexp.MustCompile(`(?m)^\tprotected (.+) (\w+);$`)
+	typeScriptPublicFieldRegexp = regexp.MustCompile(`(?m)^\t(\w+): (.+);$`)
+)
+
+// genRustAccessors generate the private fields of the struct for Rust code in
+// accessors mode, along with the impl block of the getter, the mutable getter
+// and the setter methods of the fields. The fields are kept public if the
+// accessors mode is off.
+func (gen *CodeGenerator) genRustAccessors(structName, fieldContent string) (string, string) {
+	if !gen.Accessors {
+		return fieldContent, ""
+	}
+	var methods []string
+	for _, match := range rustPublicFieldRegexp.FindAllStringSubmatch(fieldContent, -1) {
+		fieldName, fieldType := match[1], match[2]
+		name := strings.TrimPrefix(fieldName, "r#")
+		methods = append(methods, fmt.Sprintf("\tpub fn %s(&self) -> &%s {\n\t\t&self.%s\n\t}\n\n\tpub fn %s_mut(&mut self) -> &mut %s {\n\t\t&mut self.%s\n\t}\n\n\tpub fn set_%s(&mut self, value: %s) {\n\t\tself.%s = value;\n\t}\n",
+			fieldName, fieldType, fieldName, name, fieldType, fieldName, name, fieldType, fieldName))
+	}
+	if len(methods) == 0 {
+		return fieldContent, ""
+	}
+	return rustPublicFieldRegexp.ReplaceAllString(fieldContent, "\t$1: $2,"), fmt.Sprintf("\nimpl %s {\n%s}\n", structName, strings.Join(methods, "\n"))
+}
+
+// genJavaAccessors generate the private fields of the class for Java code in
+// accessors mode, along with the getter and setter methods of the fields
+// which aren't accessed by the methods of a mixin. The fields are kept
+// protected if the accessors mode is off.
+func (gen *CodeGenerator) genJavaAccessors(content string) string {
+	if !gen.Accessors {
+		return content
+	}
+	var methods string
+	for _, match := range javaProtectedFieldRegexp.FindAllStringSubmatch(content, -1) {
+		fieldType, fieldName := match[1], match[2]
+		if strings.Contains(content, fmt.Sprintf("this.%s = value;", fieldName)) {
+			continue
+		}
+		methods += fmt.Sprintf("\n\tpublic %s get%s() {\n\t\treturn %s;\n\t}\n\n\tpublic void set%s(%s value) {\n\t\tthis.%s = value;\n\t}\n",
+			fieldType, MakeFirstUpperCase(fieldName), fieldName, MakeFirstUpperCase(fieldName), fieldType, fieldName)
+	}
+	return javaProtectedFieldRegexp.ReplaceAllString(content, "\tprivate $1 $2;") + methods
+}
+
+// genJavaAccessorType generate the annotation binding the fields of the class
+// for Java code in accessors mode, so JAXB doesn't bind the getter and setter
+// methods as properties too.
+func (gen *CodeGenerator) genJavaAccessorType() string {
+	if !gen.Accessors {
+		return ""
+	}
+	return "@XmlAccessorType(XmlAccessType.FIELD)\n"
+}
+
+// genTypeScriptAccessors generate the private fields of the class for
+// TypeScript code in accessors mode, prefixed by an underscore, along with
+// the get and set accessors of the fields. The fields are kept public if the
+// accessors mode is off.
+func (gen *CodeGenerator) genTypeScriptAccessors(content string) string {
+	if !gen.Accessors {
+		return content
+	}
+	var accessors string
+	for _, match := range typeScriptPublicFieldRegexp.FindAllStringSubmatch(content, -1) {
+		fieldName, fieldType := match[1], match[2]
+		accessors += fmt.Sprintf("\n\tget %s(): %s {\n\t\treturn this._%s;\n\t}\n\n\tset %s(value: %s) {\n\t\tthis._%s = value;\n\t}\n",
+			fieldName, fieldType, fieldName, fieldName, fieldType, fieldName)
+	}
+	return typeScriptPublicFieldRegexp.ReplaceAllString(content, "\tprivate _$1: $2;") + accessors
+}
@@ -0,0 +1,718 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Assert fallbacks of the code generator, which apply to each assertion of
+// the complex types whose XPath expression can't be translated to the
+// validation code of Go and Rust. With the skip fallback the validation of
+// the assertion is skipped with a warning, and with the fail fallback the
+// generation fails.
+const (
+	AssertFallbackSkip = ""
+	AssertFallbackFail = "fail"
+)
+
+// checkAssertFallback returns an error if the assert fallback isn't
+// supported.
+func checkAssertFallback(fallback string) error {
+	switch fallback {
+	case AssertFallbackSkip, AssertFallbackFail:
+		return nil
+	}
+	return fmt.Errorf("unsupport assert fallback %s, expected %s", fallback, AssertFallbackFail)
+}
+
+// The kinds of the values of the XPath expressions of the assertions.
+const (
+	assertString   = "string"
+	assertNumber   = "number"
+	assertBoolean  = "boolean"
+	assertSequence = "sequence"
+	assertNode     = "node"
+)
+
+// assertExpr is a node of the syntax tree of the XPath expression of an
+// assertion. The operators hold their operands, the function calls their
+// arguments, and the paths and the literals their names and values.
+type assertExpr struct {
+	op        string
+	value     string
+	attribute bool
+	args      []*assertExpr
+}
+
+// assertComparisons maps the general and the value comparisons of XPath to
+// the comparison operators of Go and Rust.
+var assertComparisons = map[string]string{
+	"=": "==", "!=": "!=", "<": "<", "<=": "<=", ">": ">", ">=": ">=",
+	"eq": "==", "ne": "!=", "lt": "<", "le": "<=", "gt": ">", "ge": ">=",
+}
+
+// assertParser parses the practical subset of XPath the assertions are
+// translated from: the comparisons and the arithmetic of the values of the
+// child elements and the attributes, the and, or operators, and the
+// boolean, sequence and string functions.
+type assertParser struct {
+	tokens []string
+	pos    int
+}
+
+// parseAssert returns the syntax tree of the XPath expression of the
+// assertion.
+func parseAssert(test string) (*assertExpr, error) {
+	tokens, err := tokenizeAssert(test)
+	if err != nil {
+		return nil, err
+	}
+	p := &assertParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// tokenizeAssert splits the XPath expression into the tokens of the names,
+// the numbers, the string literals and the operators.
+func tokenizeAssert(test string) (tokens []string, err error) {
+	runes := []rune(test)
+	isName := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == ':'
+	}
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '"' || r == '\'':
+			// The quote is escaped by doubling it.
+			for i++; i < len(runes); i++ {
+				if runes[i] == r {
+					if i+1 < len(runes) && runes[i+1] == r {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string literal %s", string(runes[start:]))
+			}
+			i++
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			for i++; i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.'); i++ {
+			}
+		case unicode.IsLetter(r) || r == '_':
+			for i++; i < len(runes) && isName(runes[i]); i++ {
+			}
+		case strings.ContainsRune("!<>", r) && i+1 < len(runes) && runes[i+1] == '=':
+			i += 2
+		case strings.ContainsRune("=<>()[],+-*/@$.", r):
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %c", r)
+		}
+		tokens = append(tokens, string(runes[start:i]))
+	}
+	return
+}
+
+// peek returns the token at the position, or an empty token at the end.
+func (p *assertParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+// expect moves past the token, or returns an error if it's another one.
+func (p *assertParser) expect(token string) error {
+	if p.peek() != token {
+		if p.peek() == "" {
+			return fmt.Errorf("missing %s", token)
+		}
+		return fmt.Errorf("expected %s instead of %s", token, p.peek())
+	}
+	p.pos++
+	return nil
+}
+
+// parseBinary parses the operands of the binary operators of the same
+// precedence, by given parser of the operands.
+func (p *assertParser) parseBinary(operand func() (*assertExpr, error), ops ...string) (*assertExpr, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek()
+		if !inStringSlice(op, ops) {
+			return left, nil
+		}
+		p.pos++
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &assertExpr{op: op, args: []*assertExpr{left, right}}
+	}
+}
+
+func (p *assertParser) parseOr() (*assertExpr, error) {
+	return p.parseBinary(p.parseAnd, "or")
+}
+
+func (p *assertParser) parseAnd() (*assertExpr, error) {
+	return p.parseBinary(p.parseComparison, "and")
+}
+
+func (p *assertParser) parseComparison() (*assertExpr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	op := p.peek()
+	if _, ok := assertComparisons[op]; !ok {
+		return left, nil
+	}
+	p.pos++
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	return &assertExpr{op: op, args: []*assertExpr{left, right}}, nil
+}
+
+func (p *assertParser) parseAdditive() (*assertExpr, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *assertParser) parseMultiplicative() (*assertExpr, error) {
+	return p.parseBinary(p.parsePrimary, "*", "div")
+}
+
+// parsePrimary parses the literals, the parenthesized expressions, the
+// function calls, and the paths of the child elements, the attributes and
+// the $value of the simple content.
+func (p *assertParser) parsePrimary() (*assertExpr, error) {
+	token := p.peek()
+	p.pos++
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of the expression")
+	case token == "(":
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(")")
+	case token[0] == '"' || token[0] == '\'':
+		quote := token[:1]
+		return &assertExpr{op: assertString, value: strings.Replace(token[1:len(token)-1], quote+quote, quote, -1)}, nil
+	case unicode.IsDigit(rune(token[0])) || token[0] == '.' && len(token) > 1:
+		if _, err := strconv.ParseFloat(token, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %s", token)
+		}
+		return &assertExpr{op: assertNumber, value: token}, nil
+	case token == "$":
+		if name := p.peek(); name != "value" {
+			return nil, fmt.Errorf("unsupported variable $%s", name)
+		}
+		p.pos++
+		return &assertExpr{op: "path", value: "$value"}, nil
+	case token == "@":
+		name := p.peek()
+		p.pos++
+		if name == "" || !unicode.IsLetter(rune(name[0])) && name[0] != '_' {
+			return nil, fmt.Errorf("invalid attribute name %s", name)
+		}
+		return p.parsePath(&assertExpr{op: "path", value: trimNSPrefix(name), attribute: true})
+	case token == ".":
+		if p.peek() != "/" {
+			return nil, fmt.Errorf("unsupported context item")
+		}
+		p.pos++
+		return p.parsePrimary()
+	case unicode.IsLetter(rune(token[0])) || token[0] == '_':
+		if p.peek() == "(" {
+			p.pos++
+			call := &assertExpr{op: "call", value: strings.TrimPrefix(token, "fn:")}
+			for p.peek() != ")" {
+				if len(call.args) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				arg, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				call.args = append(call.args, arg)
+			}
+			p.pos++
+			return call, nil
+		}
+		return p.parsePath(&assertExpr{op: "path", value: trimNSPrefix(token)})
+	}
+	return nil, fmt.Errorf("unexpected %s", token)
+}
+
+// parsePath returns the path of the child, the paths of several steps and
+// the predicates aren't supported.
+func (p *assertParser) parsePath(path *assertExpr) (*assertExpr, error) {
+	switch p.peek() {
+	case "/":
+		return nil, fmt.Errorf("unsupported path of several steps from %s", path.value)
+	case "[":
+		return nil, fmt.Errorf("unsupported predicate of %s", path.value)
+	}
+	return path, nil
+}
+
+// inStringSlice returns true if the slice contains the string.
+func inStringSlice(s string, slice []string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// assertValue is the code of the value of an XPath expression translated for
+// the language, with the kind of the value. The guard is the condition the
+// value of the optional member is present on, and the exists is the
+// condition the path is present on, which is its effective boolean value.
+type assertValue struct {
+	code, kind    string
+	guard, exists string
+	count         string
+}
+
+// assertTranslator translates the XPath expressions of the assertions of
+// the complex type to the conditions of the validation code of the language
+// of the code generator.
+type assertTranslator struct {
+	gen      *CodeGenerator
+	v        *ComplexType
+	receiver string
+}
+
+// translate returns the condition of the validation code the XPath
+// expression of the assertion translates to.
+func (t *assertTranslator) translate(test string) (string, error) {
+	expr, err := parseAssert(test)
+	if err != nil {
+		return "", err
+	}
+	return t.boolean(expr)
+}
+
+// boolean returns the effective boolean value of the expression, the paths
+// are true if they are present.
+func (t *assertTranslator) boolean(expr *assertExpr) (string, error) {
+	value, err := t.value(expr)
+	if err != nil {
+		return "", err
+	}
+	if expr.op == "path" {
+		return value.exists, nil
+	}
+	if value.kind != assertBoolean {
+		return "", fmt.Errorf("the %s value of %s isn't a boolean", value.kind, formatAssert(expr))
+	}
+	return value.code, nil
+}
+
+// text returns the code of the string argument of the string functions, the
+// absent optional values are the empty strings.
+func (t *assertTranslator) text(expr *assertExpr) (string, error) {
+	value, err := t.value(expr)
+	if err != nil {
+		return "", err
+	}
+	if value.kind != assertString {
+		return "", fmt.Errorf("the %s value of %s isn't a string", value.kind, formatAssert(expr))
+	}
+	return value.code, nil
+}
+
+// value returns the translated value of the expression.
+func (t *assertTranslator) value(expr *assertExpr) (value assertValue, err error) {
+	rust := t.gen.Lang == "Rust"
+	switch expr.op {
+	case assertString:
+		if rust {
+			return assertValue{code: "\"" + escapeRustString(expr.value) + "\"", kind: assertString}, nil
+		}
+		return assertValue{code: strconv.Quote(expr.value), kind: assertString}, nil
+	case assertNumber:
+		number, _ := strconv.ParseFloat(expr.value, 64)
+		code := strconv.FormatFloat(number, 'f', -1, 64)
+		if rust && !strings.Contains(code, ".") {
+			code += ".0"
+		}
+		return assertValue{code: code, kind: assertNumber}, nil
+	case "path":
+		return t.member(expr)
+	case "call":
+		return t.call(expr)
+	case "and", "or":
+		var left, right string
+		if left, err = t.boolean(expr.args[0]); err != nil {
+			return
+		}
+		if right, err = t.boolean(expr.args[1]); err != nil {
+			return
+		}
+		op := map[string]string{"and": "&&", "or": "||"}[expr.op]
+		return assertValue{code: fmt.Sprintf("(%s %s %s)", left, op, right), kind: assertBoolean}, nil
+	}
+	var left, right assertValue
+	if left, err = t.value(expr.args[0]); err != nil {
+		return
+	}
+	if right, err = t.value(expr.args[1]); err != nil {
+		return
+	}
+	guard := joinAssertGuards(left.guard, right.guard)
+	if op, ok := assertComparisons[expr.op]; ok {
+		if left.kind != right.kind || left.kind == assertSequence || left.kind == assertNode {
+			return value, fmt.Errorf("can't compare the %s value of %s with the %s value of %s", left.kind, formatAssert(expr.args[0]), right.kind, formatAssert(expr.args[1]))
+		}
+		code := fmt.Sprintf("%s %s %s", left.code, op, right.code)
+		// The comparisons with the absent values are false.
+		if guard != "" {
+			code = guard + " && " + code
+		}
+		return assertValue{code: "(" + code + ")", kind: assertBoolean}, nil
+	}
+	if left.kind != assertNumber || right.kind != assertNumber {
+		return value, fmt.Errorf("the operands of %s aren't numbers", expr.op)
+	}
+	op := map[string]string{"+": "+", "-": "-", "*": "*", "div": "/"}[expr.op]
+	return assertValue{code: fmt.Sprintf("(%s %s %s)", left.code, op, right.code), kind: assertNumber, guard: guard}, nil
+}
+
+// call returns the translated value of the function call.
+func (t *assertTranslator) call(expr *assertExpr) (value assertValue, err error) {
+	rust := t.gen.Lang == "Rust"
+	arity := map[string]int{
+		"true": 0, "false": 0, "not": 1, "exists": 1, "empty": 1, "count": 1,
+		"string-length": 1, "upper-case": 1, "lower-case": 1,
+		"contains": 2, "starts-with": 2, "ends-with": 2,
+	}
+	n, ok := arity[expr.value]
+	if !ok {
+		return value, fmt.Errorf("unsupported function %s", expr.value)
+	}
+	if len(expr.args) != n {
+		return value, fmt.Errorf("the function %s takes %d arguments", expr.value, n)
+	}
+	switch expr.value {
+	case "true", "false":
+		return assertValue{code: expr.value, kind: assertBoolean}, nil
+	case "not":
+		var code string
+		if code, err = t.boolean(expr.args[0]); err != nil {
+			return
+		}
+		return assertValue{code: "!(" + trimAssertParens(code) + ")", kind: assertBoolean}, nil
+	case "exists", "empty", "count":
+		if expr.args[0].op != "path" {
+			return value, fmt.Errorf("the argument of %s isn't a path", expr.value)
+		}
+		var member assertValue
+		if member, err = t.member(expr.args[0]); err != nil {
+			return
+		}
+		switch expr.value {
+		case "exists":
+			return assertValue{code: member.exists, kind: assertBoolean}, nil
+		case "empty":
+			return assertValue{code: "!(" + member.exists + ")", kind: assertBoolean}, nil
+		}
+		if member.kind != assertSequence {
+			return value, fmt.Errorf("the argument of count isn't a repeated element")
+		}
+		return assertValue{code: member.count, kind: assertNumber}, nil
+	}
+	args := make([]string, len(expr.args))
+	for i, arg := range expr.args {
+		if args[i], err = t.text(arg); err != nil {
+			return
+		}
+	}
+	switch expr.value {
+	case "string-length":
+		if rust {
+			return assertValue{code: fmt.Sprintf("(%s.chars().count() as f64)", args[0]), kind: assertNumber}, nil
+		}
+		return assertValue{code: fmt.Sprintf("float64(utf8.RuneCountInString(%s))", args[0]), kind: assertNumber}, nil
+	case "upper-case", "lower-case":
+		if rust {
+			return assertValue{code: fmt.Sprintf("%s.to_%s().as_str()", args[0], strings.TrimSuffix(expr.value, "-case")+"case"), kind: assertString}, nil
+		}
+		return assertValue{code: fmt.Sprintf("strings.To%s(%s)", map[string]string{"upper-case": "Upper", "lower-case": "Lower"}[expr.value], args[0]), kind: assertString}, nil
+	}
+	if rust {
+		return assertValue{code: fmt.Sprintf("%s.%s(%s)", args[0], strings.Replace(expr.value, "-", "_", -1), args[1]), kind: assertBoolean}, nil
+	}
+	function := map[string]string{"contains": "Contains", "starts-with": "HasPrefix", "ends-with": "HasSuffix"}[expr.value]
+	return assertValue{code: fmt.Sprintf("strings.%s(%s, %s)", function, args[0], args[1]), kind: assertBoolean}, nil
+}
+
+// member returns the translated value of the path of the child element, the
+// attribute, or the $value of the simple content of the complex type.
+func (t *assertTranslator) member(expr *assertExpr) (assertValue, error) {
+	if expr.value == "$value" {
+		switch {
+		case t.v.Base != "" && t.gen.Lang == "Rust" && t.gen.isRustBuiltInType(t.v.Base):
+			return t.rustMember(t.receiver+".value", getBasefromSimpleType(trimNSPrefix(t.v.Base), t.gen.ProtoTree), false, false)
+		case t.v.Base != "" && t.gen.Lang != "Rust" && t.gen.isGoBuiltInType(t.v.Base):
+			return t.goMember("v.Value", t.v.Base, false, false)
+		}
+		return assertValue{}, fmt.Errorf("unsupported $value of the type without the simple content of a built-in type")
+	}
+	if expr.attribute {
+		for _, attribute := range t.v.Attributes {
+			if attribute.Name != expr.value {
+				continue
+			}
+			if attribute.Plural {
+				return assertValue{}, fmt.Errorf("unsupported list attribute @%s", expr.value)
+			}
+			typeName := getBasefromSimpleType(trimNSPrefix(attribute.Type), t.gen.ProtoTree)
+			if t.gen.Lang == "Rust" {
+				return t.rustMember(t.receiver+"."+genRustFieldName(attribute.Name), typeName, false, attribute.Optional)
+			}
+			return t.goMember("v."+genGoFieldName(attribute.Name, false)+"Attr", typeName, false, attribute.Optional)
+		}
+		return assertValue{}, fmt.Errorf("unknown attribute @%s", expr.value)
+	}
+	for _, element := range t.v.Elements {
+		if element.Name != expr.value {
+			continue
+		}
+		typeName := getBasefromSimpleType(trimNSPrefix(element.Type), t.gen.ProtoTree)
+		if t.gen.Lang == "Rust" {
+			switch {
+			case t.v.Mixed:
+				return assertValue{}, fmt.Errorf("unsupported element %s of the mixed content", expr.value)
+			case t.gen.getRustChoiceVariant(t.v, element) != "":
+				return assertValue{}, fmt.Errorf("unsupported element %s of the choice enum", expr.value)
+			case t.gen.isRustNillable(element, typeName):
+				return assertValue{}, fmt.Errorf("unsupported nillable element %s", expr.value)
+			}
+			return t.rustMember(t.receiver+"."+genRustFieldName(t.gen.genRustPluralName(element.Name, element.Plural)), typeName, element.Plural, element.Optional)
+		}
+		return t.goMember("v."+genGoFieldName(t.gen.genPluralName(element.Name, element.Plural), false), typeName, element.Plural, element.Optional)
+	}
+	return assertValue{}, fmt.Errorf("unknown element %s", expr.value)
+}
+
+// goMember returns the value of the field for Go code. The absent optional
+// strings are empty, and the comparisons of the absent optional numbers and
+// booleans, whose fields are pointers, are false.
+func (t *assertTranslator) goMember(field, typeName string, plural, optional bool) (assertValue, error) {
+	fieldType := t.gen.genGoFieldType(typeName)
+	if optional && !plural && t.gen.isGoOptionalPointer(fieldType) {
+		value := "*" + field
+		if fieldType != "bool" && fieldType != "float64" {
+			value = "float64(" + value + ")"
+		}
+		kind := assertNumber
+		if fieldType == "bool" {
+			kind = assertBoolean
+		}
+		return assertValue{code: value, kind: kind, guard: field + " != nil", exists: field + " != nil"}, nil
+	}
+	switch {
+	case plural || strings.HasPrefix(fieldType, "[]"):
+		return assertValue{kind: assertSequence, exists: fmt.Sprintf("len(%s) > 0", field), count: fmt.Sprintf("float64(len(%s))", field)}, nil
+	case fieldType == "string":
+		return assertValue{code: field, kind: assertString, exists: goAssertExists(optional, field+" != \"\"")}, nil
+	case fieldType == "bool":
+		return assertValue{code: field, kind: assertBoolean, exists: goAssertExists(optional, field)}, nil
+	case fieldType == "float64":
+		return assertValue{code: field, kind: assertNumber, exists: goAssertExists(optional, field+" != 0")}, nil
+	case t.gen.isGoNumericType(fieldType):
+		return assertValue{code: "float64(" + field + ")", kind: assertNumber, exists: goAssertExists(optional, field+" != 0")}, nil
+	case strings.HasPrefix(fieldType, "*"):
+		return assertValue{kind: assertNode, exists: field + " != nil"}, nil
+	}
+	return assertValue{}, fmt.Errorf("unsupported type %s of %s", fieldType, field)
+}
+
+// goAssertExists returns the condition the member is present on for Go
+// code, the required members are present.
+func goAssertExists(optional bool, condition string) string {
+	if optional {
+		return condition
+	}
+	return "true"
+}
+
+// rustMember returns the value of the field for Rust code. The comparisons
+// of the absent optional values are false.
+func (t *assertTranslator) rustMember(field, typeName string, plural, optional bool) (assertValue, error) {
+	fieldType := t.gen.genRustFieldType(typeName)
+	exists, guard, value := "true", "", field
+	if optional {
+		exists, guard, value = field+".is_some()", field+".is_some()", field+".unwrap_or_default()"
+	}
+	switch {
+	case plural && optional:
+		return assertValue{kind: assertSequence, exists: fmt.Sprintf("%s.as_ref().map_or(false, |x| !x.is_empty())", field), count: fmt.Sprintf("(%s.as_ref().map_or(0, |x| x.len()) as f64)", field)}, nil
+	case plural:
+		return assertValue{kind: assertSequence, exists: fmt.Sprintf("!%s.is_empty()", field), count: fmt.Sprintf("(%s.len() as f64)", field)}, nil
+	case fieldType == "String":
+		value = field + ".as_str()"
+		if optional {
+			value = field + ".as_deref().unwrap_or_default()"
+		}
+		return assertValue{code: value, kind: assertString, guard: guard, exists: exists}, nil
+	case fieldType == "bool":
+		return assertValue{code: value, kind: assertBoolean, guard: guard, exists: exists}, nil
+	case fieldType == "f64":
+		return assertValue{code: value, kind: assertNumber, guard: guard, exists: exists}, nil
+	case isDecimalType(fieldType):
+		return assertValue{code: fmt.Sprintf("f64::from(%s)", value), kind: assertNumber, guard: guard, exists: exists}, nil
+	case t.gen.isRustNumericType(fieldType) && fieldType != rustDecimalType:
+		return assertValue{code: fmt.Sprintf("(%s as f64)", value), kind: assertNumber, guard: guard, exists: exists}, nil
+	case !t.gen.isRustBuiltInType(fieldType):
+		return assertValue{kind: assertNode, exists: exists}, nil
+	}
+	return assertValue{}, fmt.Errorf("unsupported type %s of %s", fieldType, field)
+}
+
+// joinAssertGuards returns the conditions the values of both operands are
+// present on.
+func joinAssertGuards(left, right string) string {
+	switch {
+	case left == "" || left == right:
+		return right
+	case right == "":
+		return left
+	}
+	return left + " && " + right
+}
+
+// formatAssert returns the XPath expression of the node for the errors.
+func formatAssert(expr *assertExpr) string {
+	switch expr.op {
+	case assertString:
+		return strconv.Quote(expr.value)
+	case assertNumber:
+		return expr.value
+	case "path":
+		if expr.attribute {
+			return "@" + expr.value
+		}
+		return expr.value
+	case "call":
+		args := make([]string, len(expr.args))
+		for i, arg := range expr.args {
+			args[i] = formatAssert(arg)
+		}
+		return fmt.Sprintf("%s(%s)", expr.value, strings.Join(args, ", "))
+	}
+	return fmt.Sprintf("%s %s %s", formatAssert(expr.args[0]), expr.op, formatAssert(expr.args[1]))
+}
+
+// translateAsserts returns the conditions of the assertions of the complex
+// type translated for the language of the code generator, by their XPath
+// expressions. The assertions which can't be translated are recorded to be
+// reported by the assert fallback.
+func (gen *CodeGenerator) translateAsserts(v *ComplexType, receiver string) (tests, conditions []string) {
+	if gen.Validation == ValidationNone {
+		return
+	}
+	t := &assertTranslator{gen: gen, v: v, receiver: receiver}
+	for _, assert := range v.Asserts {
+		test := strings.Join(strings.Fields(assert.Test), " ")
+		condition, err := t.translate(test)
+		if err != nil {
+			gen.unsupportedAsserts = append(gen.unsupportedAsserts, fmt.Sprintf("%s of %s: %v", test, v.Name, err))
+			continue
+		}
+		tests, conditions = append(tests, test), append(conditions, condition)
+	}
+	return
+}
+
+// genGoAssertValidation generate validation code of the assertions of the
+// complex type for Go code.
+func (gen *CodeGenerator) genGoAssertValidation(v *ComplexType, typeName string) (code string) {
+	tests, conditions := gen.translateAsserts(v, "v")
+	for i, condition := range conditions {
+		code += fmt.Sprintf("if !%s {\nreturn errors.New(%q)\n}\n", "("+trimAssertParens(condition)+")", typeName+" does not satisfy the assertion "+tests[i])
+	}
+	return
+}
+
+// genRustAssertValidation generate validation code of the assertions of the
+// complex type for Rust code.
+func (gen *CodeGenerator) genRustAssertValidation(v *ComplexType, typeName string) (code string) {
+	indent, receiver := "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	tests, conditions := gen.translateAsserts(v, receiver)
+	for i, condition := range conditions {
+		code += fmt.Sprintf("%sif !%s {\n%s\treturn Err(ValidationError::new(1013, \"%s\".to_string()));\n%s}\n", indent, "("+trimAssertParens(condition)+")", indent, escapeRustString(typeName+" does not satisfy the assertion "+tests[i]), indent)
+	}
+	return
+}
+
+// trimAssertParens returns the condition without the parentheses enclosing
+// all of it, skipping the parentheses in the string literals.
+func trimAssertParens(condition string) string {
+	for strings.HasPrefix(condition, "(") && strings.HasSuffix(condition, ")") {
+		depth, quoted := 0, false
+		for i := 0; i < len(condition); i++ {
+			switch c := condition[i]; {
+			case quoted && c == '\\':
+				i++
+			case c == '"':
+				quoted = !quoted
+			case quoted:
+			case c == '(':
+				depth++
+			case c == ')':
+				if depth--; depth == 0 && i < len(condition)-1 {
+					return condition
+				}
+			}
+		}
+		condition = condition[1 : len(condition)-1]
+	}
+	return condition
+}
+
+// checkAsserts returns the error of the assertions which can't be
+// translated with the fail fallback, before the code is written.
+func (gen *CodeGenerator) checkAsserts() error {
+	if len(gen.unsupportedAsserts) > 0 && gen.AssertFallback == AssertFallbackFail {
+		return fmt.Errorf("unsupported assertion %s", gen.unsupportedAsserts[0])
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "fmt"
+
+// Boolean forms of the code generator. With the native form xs:boolean is
+// generated as the boolean type of the language. With the literal and the
+// numeric form it's generated as a boolean type accepting both the
+// true/false and the 1/0 lexical forms, which is serialized as true/false or
+// 1/0 respectively.
+const (
+	BooleanFormNative  = ""
+	BooleanFormLiteral = "literal"
+	BooleanFormNumeric = "numeric"
+)
+
+// booleanTypes holds the name of the generated boolean type by language.
+var booleanTypes = map[string]string{
+	"Go":   "XSDBoolean",
+	"Rust": "XsdBoolean",
+}
+
+// checkBooleanForm returns an error if the boolean form isn't supported.
+func checkBooleanForm(form string) error {
+	switch form {
+	case BooleanFormNative, BooleanFormLiteral, BooleanFormNumeric:
+		return nil
+	}
+	return fmt.Errorf("unsupport boolean form %s, expected %s or %s", form, BooleanFormLiteral, BooleanFormNumeric)
+}
+
+// getBooleanType returns the generated boolean type of the language, which
+// xs:boolean is mapped to if the boolean form option is set.
+func (opt *Options) getBooleanType() (string, bool) {
+	if opt.BooleanForm == BooleanFormNative {
+		return "", false
+	}
+	booleanType, ok := booleanTypes[opt.Lang]
+	return booleanType, ok
+}
+
+// getBooleanLiterals returns the canonical lexical forms of true and false
+// serialized by the generated boolean type.
+func (gen *CodeGenerator) getBooleanLiterals() (string, string) {
+	if gen.BooleanForm == BooleanFormNumeric {
+		return "1", "0"
+	}
+	return "true", "false"
+}
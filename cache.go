@@ -0,0 +1,146 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SchemaCache holds the parsed schemas keyed by the hash of their content and
+// the options affecting the parse, and by the path of the file and of the
+// output for the schemas parsed with generating code. The schemas imported by
+// many files of a batch, such as the shared data type libraries, are parsed
+// once and the parsed declarations are reused by each importer, instead of
+// parsing the schema and generating its code again. A cache is safe for
+// concurrent use.
+type SchemaCache struct {
+	mu      sync.Mutex
+	schemas map[string]*cachedSchema
+	hits    int
+	misses  int
+}
+
+// cachedSchema holds the results of a parse, with the entries the parse set
+// in the namespace maps shared with the importer, which are set again when
+// the schema is reused.
+type cachedSchema struct {
+	protoTree           []interface{}
+	targetNamespace     string
+	schemaVersion       string
+	warnings            []Warning
+	localNameNSMap      map[string]string
+	nsSchemaLocationMap map[string]string
+	includeMap          map[string]bool
+}
+
+// NewSchemaCache creates a new empty schema cache, shared by the options of
+// the files of a batch.
+func NewSchemaCache() *SchemaCache {
+	return &SchemaCache{schemas: map[string]*cachedSchema{}}
+}
+
+// Stats returns the number of the parses reusing a cached schema, and the
+// number of the parses which weren't cached.
+func (cache *SchemaCache) Stats() (hits, misses int) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.hits, cache.misses
+}
+
+// schemaCacheKey returns the key of the schema by given content and the
+// options affecting the parse.
+func (opt *Options) schemaCacheKey(data []byte) string {
+	sum := sha256.Sum256(data)
+	key := []string{hex.EncodeToString(sum[:]), strconv.FormatBool(opt.Extract)}
+	if !opt.Extract {
+		key = append(key, opt.FilePath, opt.InputDir, opt.OutputDir)
+	}
+	for name, value := range opt.getGenerationOptions() {
+		key = append(key, name+"="+value)
+	}
+	key = append(key, "langs="+strings.Join(opt.Langs, ","))
+	sort.Strings(key[1:])
+	return strings.Join(key, "\x00")
+}
+
+// load sets the results of the cached schema by given key to the options,
+// and returns if the schema is cached.
+func (cache *SchemaCache) load(key string, opt *Options) bool {
+	cache.mu.Lock()
+	schema, ok := cache.schemas[key]
+	if ok {
+		cache.hits++
+	} else {
+		cache.misses++
+	}
+	cache.mu.Unlock()
+	if !ok {
+		return false
+	}
+	opt.ProtoTree = schema.protoTree
+	opt.TargetNamespace = schema.targetNamespace
+	opt.SchemaVersion = schema.schemaVersion
+	opt.addWarnings(schema.warnings)
+	opt.mergeNamespaceMaps(schema.localNameNSMap, schema.nsSchemaLocationMap, schema.includeMap)
+	if !opt.Extract {
+		opt.ParseFileList[opt.FilePath] = true
+		opt.ParseFileMap[opt.FilePath] = opt.ProtoTree
+	}
+	return true
+}
+
+// parse parses the schema of the options with the empty namespace maps, so
+// the results only depend on the key. The results are cached by given key
+// unless the parse fails, and the entries set by the parse are set to the
+// namespace maps shared with the importer afterward.
+func (cache *SchemaCache) parse(key string, opt *Options, parse func() error) error {
+	localNameNSMap, nsSchemaLocationMap, includeMap, warnings := opt.LocalNameNSMap, opt.NSSchemaLocationMap, opt.IncludeMap, opt.Warnings
+	opt.LocalNameNSMap, opt.NSSchemaLocationMap, opt.IncludeMap, opt.Warnings = map[string]string{}, map[string]string{}, map[string]bool{}, nil
+	err := parse()
+	if err == nil {
+		cache.mu.Lock()
+		cache.schemas[key] = &cachedSchema{
+			protoTree:           opt.ProtoTree,
+			targetNamespace:     opt.TargetNamespace,
+			schemaVersion:       opt.SchemaVersion,
+			warnings:            opt.Warnings,
+			localNameNSMap:      opt.LocalNameNSMap,
+			nsSchemaLocationMap: opt.NSSchemaLocationMap,
+			includeMap:          opt.IncludeMap,
+		}
+		cache.mu.Unlock()
+	}
+	parsedNSMap, parsedLocationMap, parsedIncludeMap, parsedWarnings := opt.LocalNameNSMap, opt.NSSchemaLocationMap, opt.IncludeMap, opt.Warnings
+	opt.LocalNameNSMap, opt.NSSchemaLocationMap, opt.IncludeMap, opt.Warnings = localNameNSMap, nsSchemaLocationMap, includeMap, warnings
+	opt.addWarnings(parsedWarnings)
+	opt.mergeNamespaceMaps(parsedNSMap, parsedLocationMap, parsedIncludeMap)
+	return err
+}
+
+// mergeNamespaceMaps sets the entries set by a parse to the namespace maps of
+// the options, as the parse sets them: the prefixes are overridden, and the
+// first schema location of a namespace and of an include is kept.
+func (opt *Options) mergeNamespaceMaps(localNameNSMap, nsSchemaLocationMap map[string]string, includeMap map[string]bool) {
+	for prefix, namespace := range localNameNSMap {
+		opt.LocalNameNSMap[prefix] = namespace
+	}
+	for namespace, location := range nsSchemaLocationMap {
+		if _, ok := opt.NSSchemaLocationMap[namespace]; !ok {
+			opt.NSSchemaLocationMap[namespace] = location
+		}
+	}
+	for location := range includeMap {
+		opt.IncludeMap[location] = true
+	}
+}
@@ -0,0 +1,362 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Choice representations of the code generator, which are the serde
+// representations of the Rust enums of the choices. With the external
+// representation the variant is tagged by the name of the element, such as
+// {"IBAN": "..."}, with the internal representation by the tag field within
+// the content, such as {"type": "IBAN", "value": "..."}, and with the
+// untagged representation the content isn't tagged, so the variant is the
+// first one the content deserializes to.
+const (
+	ChoiceReprExternal = ""
+	ChoiceReprInternal = "internal"
+	ChoiceReprUntagged = "untagged"
+)
+
+// ChoiceTagDefault is the name of the tag field of the internal choice
+// representation if not specified.
+const ChoiceTagDefault = "type"
+
+// checkChoiceRepr returns an error if the choice representation isn't
+// supported.
+func checkChoiceRepr(repr string) error {
+	switch repr {
+	case ChoiceReprExternal, ChoiceReprInternal, ChoiceReprUntagged:
+		return nil
+	}
+	return fmt.Errorf("unsupport choice representation %s, expected %s or %s", repr, ChoiceReprInternal, ChoiceReprUntagged)
+}
+
+// rustChoice holds the choice of the complex type which is generated as an
+// enum for Rust code, with a variant per element of the choice. The content
+// of the mixed complex type is held as well, with the variant of the
+// character data. The choice among the other members of the type in the
+// external choice representation is generated as the optional fields of its
+// elements, with the accessors of the enum.
+type rustChoice struct {
+	Choice
+	elements            []Element
+	variants            []string
+	enumName, fieldName string
+	mixed, fields       bool
+}
+
+// getRustChoices returns the choices of the complex type which are generated
+// as enums for Rust code in choice enums mode, by ID. The nested and the
+// repeated choices, and the choices of the mixed types are generated as the
+// optional fields of their elements. In the external choice representation,
+// serde can't flatten the externally tagged enum into the elements of the
+// struct deserialized by quick-xml, so the choices among the other members
+// of the type are generated as the optional fields of their elements with
+// the accessors of the enum, and the ones of the nillable elements as the
+// optional fields only.
+func (gen *CodeGenerator) getRustChoices(v *ComplexType) map[string]*rustChoice {
+	if !gen.ChoiceEnums || v.Mixed {
+		return nil
+	}
+	choices := map[string]*rustChoice{}
+	for _, c := range v.Choice {
+		if c.ID != "" && !c.Nested && !c.Plural {
+			choices[c.ID] = &rustChoice{Choice: c}
+		}
+	}
+	for _, element := range v.Elements {
+		if c, ok := choices[element.Choice]; ok {
+			c.elements = append(c.elements, element)
+		}
+	}
+	for id, c := range choices {
+		if len(c.elements) == 0 {
+			delete(choices, id)
+			continue
+		}
+		c.variants = genRustElementVariants(c.elements, nil)
+	}
+	if gen.ChoiceRepr != ChoiceReprExternal || getRustChoiceType(v, choices) != nil {
+		return choices
+	}
+	for id, c := range choices {
+		c.fields = true
+		for _, element := range c.elements {
+			if gen.isRustNillable(element, getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)) {
+				delete(choices, id)
+				break
+			}
+		}
+	}
+	return choices
+}
+
+// genRustElementVariants generate the names of the enum variants of the
+// elements for Rust code, numbered after the first variant of the same name
+// or the reserved names of the other variants.
+func genRustElementVariants(elements []Element, reserved []string) (variants []string) {
+	variantNameCount := map[string]int{}
+	for _, name := range reserved {
+		variantNameCount[name]++
+	}
+	for _, element := range elements {
+		variantName := genRustEnumVariantName(element.Name)
+		variantNameCount[variantName]++
+		if count := variantNameCount[variantName]; count != 1 {
+			variantName = fmt.Sprintf("%s%d", variantName, count)
+		}
+		variants = append(variants, variantName)
+	}
+	return
+}
+
+// getRustChoiceType returns the choice the complex type consists of, which
+// is generated as the enum of the type itself, or nil if the type has any
+// other member.
+func getRustChoiceType(v *ComplexType, choices map[string]*rustChoice) *rustChoice {
+	if len(choices) != 1 || len(v.Attributes) > 0 || len(v.AttributeGroup) > 0 || len(v.Groups) > 0 || len(v.Base) > 0 || v.Any || v.AnyAttribute {
+		return nil
+	}
+	for _, element := range v.Elements {
+		if c, ok := choices[element.Choice]; !ok || c.Optional {
+			return nil
+		}
+	}
+	return choices[v.Elements[0].Choice]
+}
+
+// genRustChoiceField generate the field of the choice of the complex type
+// by given struct name for Rust code, which is empty if the choice is
+// generated as the fields of its elements. The enum of the choice is named
+// after the struct, and the fields of the choices, or their accessors, are
+// named choice, choice2 and so on.
+func (gen *CodeGenerator) genRustChoiceField(structName string, c *rustChoice, index int) string {
+	c.enumName, c.fieldName = genRustStructName(structName+"Choice", true), "choice"
+	if index > 0 {
+		c.fieldName = fmt.Sprintf("choice%d", index+1)
+	}
+	if gen.choiceEnums == nil {
+		gen.choiceEnums = map[string]bool{}
+	}
+	gen.choiceEnums[c.enumName] = true
+	if c.fields {
+		return ""
+	}
+	return fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", gen.genRustFieldRename(c.fieldName), c.fieldName, gen.genRustFieldDeclType(c.enumName, false, c.Optional))
+}
+
+// genRustChoiceElementField returns the field of the element of the choice
+// generated as the fields of its elements, which is skipped by serde unless
+// the element is present, so the serialized choice has one element only.
+func genRustChoiceElementField(content string) string {
+	return strings.Replace(content, ")]\n\tpub ", ", skip_serializing_if = \"Option::is_none\")]\n\tpub ", 1)
+}
+
+// genRustChoiceAccessors generate the accessors of the choice generated as
+// the optional fields of its elements in the struct by given name for Rust
+// code. The getter returns the variant of the element which is present, the
+// first one if several are, and the setter sets the element of the variant
+// and unsets the other ones.
+func (gen *CodeGenerator) genRustChoiceAccessors(structName string, c *rustChoice) {
+	var getter, unset, arms string
+	for i, element := range c.elements {
+		fieldType := getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)
+		fieldName := genRustFieldName(gen.genRustPluralName(element.Name, element.Plural))
+		getter += fmt.Sprintf("\t\tif let Some(value) = &self.%s {\n\t\t\treturn Some(%s::%s(<%s>::clone(value).into()));\n\t\t}\n", fieldName, c.enumName, c.variants[i], gen.genRustFieldDeclType(fieldType, element.Plural, false))
+		unset += fmt.Sprintf("\t\tself.%s = None;\n", fieldName)
+		arms += fmt.Sprintf("\t\t\t%s::%s(value) => self.%s = Some(value.into()),\n", c.enumName, c.variants[i], fieldName)
+	}
+	gen.Field += fmt.Sprintf("\nimpl %s {\n\t// %s returns the element of the choice which is present, the first one if several are.\n\tpub fn %s(&self) -> Option<%s> {\n%s\t\tNone\n\t}\n", structName, c.fieldName, c.fieldName, c.enumName, getter)
+	gen.Field += fmt.Sprintf("\n\t// set_%s sets the element of the choice, and unsets the other elements of the choice.\n\tpub fn set_%s(&mut self, %s: %s) {\n%s\t\tmatch %s {\n%s\t\t}\n\t}\n}\n", c.fieldName, c.fieldName, c.fieldName, c.enumName, unset, c.fieldName, arms)
+}
+
+// genRustChoiceType generate the complex type consisting of a choice as the
+// enum of the choice for Rust code.
+func (gen *CodeGenerator) genRustChoiceType(v *ComplexType, c *rustChoice) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	structName := genRustStructName(v.Name, true)
+	gen.addSymbol(v, structName)
+	if shared := gen.getSharedAnonymousType(v, structName); shared != "" {
+		gen.genRustTypeAlias(v, structName, shared)
+		return
+	}
+	gen.StructAST[v.Name] = structName
+	gen.genRustChoiceEnum(structName, v.Doc, c)
+}
+
+// getRustChoiceVariant returns the name of the variant generated for the
+// element of the complex type in choice enums mode, or an empty string if
+// the element is generated as a field.
+func (gen *CodeGenerator) getRustChoiceVariant(v *ComplexType, element Element) string {
+	if c, ok := gen.getRustChoices(v)[element.Choice]; ok && !c.fields {
+		for i, e := range c.elements {
+			if e.Name == element.Name && e.Type == element.Type {
+				return c.variants[i]
+			}
+		}
+	}
+	return ""
+}
+
+// isRustStructType returns whether the type is a complex type generated as
+// a struct for Rust code.
+func (gen *CodeGenerator) isRustStructType(name string) bool {
+	for _, ele := range gen.ProtoTree {
+		if v, ok := ele.(*ComplexType); ok && v.Name == name {
+			return getRustChoiceType(v, gen.getRustChoices(v)) == nil
+		}
+	}
+	return false
+}
+
+// genRustChoiceEnum generate the enum of the choice for Rust code, with a
+// variant per element of the choice which is renamed to the element by
+// serde, so only one of the elements can be present. The enum has the serde
+// attribute of the choice representation. As serde tags the content of the
+// variant in the internal choice representation, the variants of the other
+// types than structs hold the value in the value field. The first variant is
+// the default. The validation and the normalize code of the enum apply to
+// the value of the variant. The enum of the content of a mixed type has the
+// variant of the character data first, and is tagged by the elements
+// regardless of the choice representation. The enum of the choice of a
+// complex type in the external choice representation is serialized as the
+// map of the element of the variant, which quick-xml reads from and writes
+// to the child element of the element holding the choice.
+func (gen *CodeGenerator) genRustChoiceEnum(enumName, doc string, c *rustChoice) {
+	var attr, variants, defaultValue string
+	repr := gen.ChoiceRepr
+	if c.mixed {
+		// The content of the mixed type is the sequence of the elements and
+		// the character data, which are tagged by the element names.
+		repr, variants, defaultValue = ChoiceReprExternal, fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tText(String),\n", rustMixedTextTag), "Text(Default::default())"
+	}
+	switch repr {
+	case ChoiceReprInternal:
+		tag := gen.ChoiceTag
+		if tag == "" {
+			tag = ChoiceTagDefault
+		}
+		attr = fmt.Sprintf("#[serde(tag = \"%s\")]\n", tag)
+	case ChoiceReprUntagged:
+		attr = "#[serde(untagged)]\n"
+	}
+	// The substitution groups and the contents of the mixed types have no
+	// choice ID, and are tagged by serde.
+	mapped := repr == ChoiceReprExternal && c.ID != ""
+	var names, serializes, deserializes string
+	patterns := make([]string, len(c.elements))
+	for i, element := range c.elements {
+		fieldType := getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)
+		declType := gen.genRustFieldDeclType(fieldType, element.Plural, false)
+		if !element.Plural {
+			gen.addRustFieldType(enumName, declType)
+			if gen.isRustBoxedField(enumName, declType) {
+				declType = "Box<" + declType + ">"
+			}
+		}
+		variant, value := fmt.Sprintf("%s(%s)", c.variants[i], declType), "(Default::default())"
+		patterns[i] = fmt.Sprintf("%s::%s(value)", enumName, c.variants[i])
+		if repr == ChoiceReprInternal && (element.Plural || !gen.isRustStructType(fieldType)) {
+			variant, value = fmt.Sprintf("%s { value: %s }", c.variants[i], declType), " { value: Default::default() }"
+			patterns[i] = fmt.Sprintf("%s::%s { value }", enumName, c.variants[i])
+		}
+		if defaultValue == "" {
+			defaultValue = c.variants[i] + value
+		}
+		name := gen.genRustFieldRename(element.Name)
+		if mapped {
+			names += fmt.Sprintf("\"%s\", ", name)
+			serializes += fmt.Sprintf("\t\t\t%s => map.serialize_entry(\"%s\", value)?,\n", patterns[i], name)
+			deserializes += fmt.Sprintf("\t\t\t\t\t\"%s\" => %s::%s(map.next_value()?),\n", name, enumName, c.variants[i])
+			variants += fmt.Sprintf("\t%s,\n", variant)
+			continue
+		}
+		variants += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\t%s,\n", name, variant)
+	}
+	if mapped {
+		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, PartialEq, Clone)]\npub enum %s {\n%s}\n", gen.genComment(enumName, doc), enumName, variants)
+	} else {
+		gen.Field += fmt.Sprintf("\n%s#[derive(Debug, PartialEq, Clone, Serialize, Deserialize)]\n%spub enum %s {\n%s}\n", gen.genComment(enumName, doc), attr, enumName, variants)
+	}
+	gen.Field += fmt.Sprintf("\nimpl Default for %s {\n\tfn default() -> Self {\n\t\t%s::%s\n\t}\n}\n", enumName, enumName, defaultValue)
+	if mapped {
+		gen.Field += fmt.Sprintf(`
+impl Serialize for %s {
+	fn serialize<S: serde::Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+		use serde::ser::SerializeMap;
+		let mut map = serializer.serialize_map(Some(1))?;
+		match self {
+%s		}
+		map.end()
+	}
+}
+
+impl<'de> Deserialize<'de> for %s {
+	fn deserialize<D: serde::Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+		struct ChoiceVisitor;
+
+		impl<'de> serde::de::Visitor<'de> for ChoiceVisitor {
+			type Value = %s;
+
+			fn expecting(&self, f: &mut std::fmt::Formatter) -> std::fmt::Result {
+				f.write_str("an element of the choice %s")
+			}
+
+			fn visit_map<A: serde::de::MapAccess<'de>>(self, mut map: A) -> Result<Self::Value, A::Error> {
+				let key: String = map.next_key()?.ok_or_else(|| serde::de::Error::custom("missing the element of the choice %s"))?;
+				let value = match key.as_str() {
+%s					_ => return Err(serde::de::Error::unknown_variant(&key, &[%s])),
+				};
+				if let Some(key) = map.next_key::<String>()? {
+					return Err(serde::de::Error::custom(format!("unexpected element {} after the element of the choice %s", key)));
+				}
+				Ok(value)
+			}
+		}
+
+		deserializer.deserialize_map(ChoiceVisitor)
+	}
+}
+`, enumName, serializes, enumName, enumName, enumName, enumName, deserializes, strings.TrimSuffix(names, ", "), enumName)
+	}
+
+	indent, receiver := "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	match := func(code func(element Element, fieldType string) string) string {
+		var arms string
+		var count int
+		for i, element := range c.elements {
+			fieldType := getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)
+			if body := code(element, fieldType); body != "" {
+				arms += fmt.Sprintf("%s\t%s => {\n%s%s\t}\n", indent, patterns[i], body, indent)
+				count++
+			}
+		}
+		if count == 0 {
+			return ""
+		}
+		if count < len(c.elements) || c.mixed {
+			arms += indent + "\t_ => {}\n"
+		}
+		return fmt.Sprintf("%smatch %s {\n%s%s}\n", indent, receiver, arms, indent)
+	}
+	gen.genRustValidationCode(enumName, match(func(element Element, fieldType string) string {
+		return gen.genRustValueValidation(indent+"\t\t", genRustFieldName(element.Name), "(*value)", fieldType, element.Plural, false, &element.Restriction)
+	}))
+	gen.genRustNormalizeCode(enumName, match(func(element Element, fieldType string) string {
+		return gen.genRustValueNormalize(indent+"\t\t", "(*value)", fieldType, element.Plural, false, &element.Restriction)
+	}))
+}
@@ -0,0 +1,133 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// rustChronoTypes holds the date and time types of the generated structs for
+// Rust code in the Rust chrono mode, which wrap the types of the chrono crate
+// and are parsed from and serialized as the lexical form of the XSD type.
+var rustChronoTypes = []struct {
+	xsd, name, chrono, parse, format string
+}{
+	{
+		xsd: "date", name: "XsdDate", chrono: "chrono::NaiveDate",
+		parse:  `chrono::NaiveDate::parse_from_str(value, "%Y-%m-%d").map(XsdDate)`,
+		format: `write!(f, "{}", self.0.format("%Y-%m-%d"))`,
+	},
+	{
+		xsd: "dateTime", name: "XsdDateTime", chrono: "chrono::DateTime<chrono::Utc>",
+		parse: `chrono::DateTime::parse_from_rfc3339(value)
+			.map(|v| v.with_timezone(&chrono::Utc))
+			.or_else(|_| chrono::NaiveDateTime::parse_from_str(value, "%Y-%m-%dT%H:%M:%S%.f").map(|v| chrono::TimeZone::from_utc_datetime(&chrono::Utc, &v)))
+			.map(XsdDateTime)`,
+		format: `f.write_str(&self.0.to_rfc3339_opts(chrono::SecondsFormat::AutoSi, true))`,
+	},
+	{
+		xsd: "time", name: "XsdTime", chrono: "chrono::NaiveTime",
+		parse:  `chrono::NaiveTime::parse_from_str(value, "%H:%M:%S%.f").map(XsdTime)`,
+		format: `write!(f, "{}", self.0.format("%H:%M:%S%.f"))`,
+	},
+	{
+		xsd: "gYearMonth", name: "XsdGYearMonth", chrono: "chrono::NaiveDate",
+		parse:  `chrono::NaiveDate::parse_from_str(&format!("{}-01", value), "%Y-%m-%d").map(XsdGYearMonth)`,
+		format: `write!(f, "{}", self.0.format("%Y-%m"))`,
+	},
+}
+
+// getChronoType returns the generated date and time type of the XSD type by
+// given name, which it's mapped to if the Rust chrono option is set.
+func (opt *Options) getChronoType(name string) (string, bool) {
+	if !opt.RustChrono || opt.Lang != "Rust" {
+		return "", false
+	}
+	for _, chronoType := range rustChronoTypes {
+		if chronoType.xsd == name {
+			return chronoType.name, true
+		}
+	}
+	return "", false
+}
+
+// isRustChronoType returns true if the type by given name is a generated
+// date and time type.
+func isRustChronoType(name string) bool {
+	for _, chronoType := range rustChronoTypes {
+		if chronoType.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// genRustChrono generate the date and time types used by the code for Rust
+// code, which wrap the chrono types, and parse and serialize the lexical
+// forms of the XSD types. The xs:dateTime values without timezone are taken
+// as UTC, and the xs:gYearMonth values are held by the first day of the month.
+func (gen *CodeGenerator) genRustChrono(code string) (types string) {
+	for _, chronoType := range rustChronoTypes {
+		if !regexp.MustCompile(`\b` + chronoType.name + `\b`).MatchString(code) {
+			continue
+		}
+		types += fmt.Sprintf(`
+%s#[derive(Debug, Default, PartialEq, Eq, PartialOrd, Ord, Clone, Copy, Hash)]
+pub struct %s(pub %s);
+
+impl std::str::FromStr for %s {
+	type Err = chrono::ParseError;
+
+	fn from_str(value: &str) -> Result<Self, Self::Err> {
+		let value = value.trim();
+		%s
+	}
+}
+
+impl std::fmt::Display for %s {
+	fn fmt(&self, f: &mut std::fmt::Formatter) -> std::fmt::Result {
+		%s
+	}
+}
+
+impl Serialize for %s {
+	fn serialize<S: serde::Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+		serializer.collect_str(self)
+	}
+}
+
+impl<'de> Deserialize<'de> for %s {
+	fn deserialize<D: serde::Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+		let value = String::deserialize(deserializer)?;
+		value.parse().map_err(|_| serde::de::Error::custom(format!("invalid xs:%s value {:?}", value)))
+	}
+}
+
+impl From<%s> for %s {
+	fn from(v: %s) -> Self {
+		%s(v)
+	}
+}
+
+impl From<%s> for %s {
+	fn from(v: %s) -> Self {
+		v.0
+	}
+}
+`, gen.genComment(chronoType.name, fmt.Sprintf("the xs:%s, which is held by the %s.", chronoType.xsd, chronoType.chrono)), chronoType.name, chronoType.chrono,
+			chronoType.name, chronoType.parse,
+			chronoType.name, chronoType.format,
+			chronoType.name,
+			chronoType.name, chronoType.xsd,
+			chronoType.chrono, chronoType.name, chronoType.chrono, chronoType.name,
+			chronoType.name, chronoType.chrono, chronoType.name)
+	}
+	return
+}
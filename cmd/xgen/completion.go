@@ -0,0 +1,190 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// completionShells defines the shells supported by the completion command.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// genCompletion returns the completion script of the shell by given name,
+// which covers the flags, the accepted values of the flags and the
+// subcommands.
+func genCompletion(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return genBashCompletion(), nil
+	case "zsh":
+		return genZshCompletion(), nil
+	case "fish":
+		return genFishCompletion(), nil
+	}
+	return "", fmt.Errorf("unsupport completion shell %s", shell)
+}
+
+func genBashCompletion() string {
+	var flags []string
+	var cases string
+	for _, group := range flagGroups {
+		for _, f := range group.Flags {
+			flags = append(flags, "-"+f.Name)
+			switch {
+			case len(f.Values) > 0:
+				cases += fmt.Sprintf("\t-%s)\n\t\tCOMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n\t\treturn\n\t\t;;\n", f.Name, strings.Join(f.Values, " "))
+			case f.Files:
+				cases += fmt.Sprintf("\t-%s)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n\t\treturn\n\t\t;;\n", f.Name)
+			case f.Arg != "":
+				cases += fmt.Sprintf("\t-%s)\n\t\treturn\n\t\t;;\n", f.Name)
+			}
+		}
+	}
+	return fmt.Sprintf(`# bash completion for xgen
+
+_xgen() {
+	local cur prev
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	if [ "$COMP_CWORD" -eq 2 ] && [ "$prev" = "completion" ]; then
+		COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		return
+	fi
+	if [ "${COMP_WORDS[1]}" = "dump" ]; then
+		if [ "$prev" = "-format" ]; then
+			COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		elif [[ "$cur" == -* ]]; then
+			COMPREPLY=($(compgen -W "-format" -- "$cur"))
+		else
+			COMPREPLY=($(compgen -f -- "$cur"))
+		fi
+		return
+	fi
+	if [ "${COMP_WORDS[1]}" = "merge" ]; then
+		if [[ "$cur" == -* ]]; then
+			COMPREPLY=($(compgen -W "-o" -- "$cur"))
+		else
+			COMPREPLY=($(compgen -f -- "$cur"))
+		fi
+		return
+	fi
+	if [ "${COMP_WORDS[1]}" = "mapping" ]; then
+		if [ "$prev" = "-l" ]; then
+			COMPREPLY=($(compgen -W "%s" -- "$cur"))
+		elif [ "$prev" = "-old" ] || [ "$prev" = "-p" ]; then
+			return
+		elif [[ "$cur" == -* ]]; then
+			COMPREPLY=($(compgen -W "-l -old -p -o" -- "$cur"))
+		else
+			COMPREPLY=($(compgen -f -- "$cur"))
+		fi
+		return
+	fi
+	if [ "${COMP_WORDS[1]}" = "verify" ]; then
+		if [ "$prev" = "-seed" ]; then
+			return
+		elif [[ "$cur" == -* ]]; then
+			COMPREPLY=($(compgen -W "-seed" -- "$cur"))
+		else
+			COMPREPLY=($(compgen -f -- "$cur"))
+		fi
+		return
+	fi
+	case "$prev" in
+%s	esac
+	if [ "$COMP_CWORD" -eq 1 ] && [[ "$cur" != -* ]]; then
+		COMPREPLY=($(compgen -W "completion dump lsp mapping merge verify" -- "$cur"))
+		return
+	fi
+	COMPREPLY=($(compgen -W "%s" -- "$cur"))
+}
+
+complete -o default -F _xgen xgen
+`, strings.Join(completionShells, " "), strings.Join(dumpFormats, " "), strings.Join(mappingLangs, " "), cases, strings.Join(flags, " "))
+}
+
+func genZshCompletion() string {
+	var specs string
+	for _, group := range flagGroups {
+		for _, f := range group.Flags {
+			spec := fmt.Sprintf("-%s[%s]", f.Name, escapeZshDescription(f.Usage))
+			switch {
+			case len(f.Values) > 0:
+				spec += fmt.Sprintf(":%s:(%s)", strings.Trim(f.Arg, "<>"), strings.Join(f.Values, " "))
+			case f.Files:
+				spec += fmt.Sprintf(":%s:_files", strings.Trim(f.Arg, "<>"))
+			case f.Arg != "":
+				spec += fmt.Sprintf(":%s: ", strings.Trim(f.Arg, "<>"))
+			}
+			specs += fmt.Sprintf(" \\\n\t\t'%s'", strings.Replace(spec, "'", `'\''`, -1))
+		}
+	}
+	return fmt.Sprintf(`#compdef xgen
+
+_xgen() {
+	if [[ $words[2] == completion ]]; then
+		(( CURRENT == 3 )) && _values shell %s
+		return
+	fi
+	if [[ $words[2] == dump ]]; then
+		_arguments '-format[Specify the output format]:format:(%s)' '*:file:_files'
+		return
+	fi
+	if [[ $words[2] == merge ]]; then
+		_arguments '-o[Output file path for the merged schema]:path:_files' '*:file:_files'
+		return
+	fi
+	if [[ $words[2] == mapping ]]; then
+		_arguments '-l[Specify the language of the mapping code]:language:(%s)' '-old[Import path of the old Go package, or path of the old Rust module]:ref: ' '-p[Specify the package name of the new version]:package: ' '-o[Output file path for the mapping code]:path:_files' '*:file:_files'
+		return
+	fi
+	if [[ $words[2] == verify ]]; then
+		_arguments '-seed[Specify the seed of the sample instances]:seed: ' '*:file:_files'
+		return
+	fi
+	_arguments%s \
+		'1::command:(completion dump lsp mapping merge verify)'
+}
+
+compdef _xgen xgen
+`, strings.Join(completionShells, " "), strings.Join(dumpFormats, " "), strings.Join(mappingLangs, " "), specs)
+}
+
+func genFishCompletion() string {
+	completion := "# fish completion for xgen\n\n"
+	completion += "complete -c xgen -n '__fish_use_subcommand' -a completion -d 'Output the shell completion script'\n"
+	completion += "complete -c xgen -n '__fish_use_subcommand' -a dump -d 'Output the parsed XML schema'\n"
+	completion += "complete -c xgen -n '__fish_use_subcommand' -a merge -d 'Combine the XML schemas into a single schema'\n"
+	completion += "complete -c xgen -n '__fish_use_subcommand' -a mapping -d 'Output the mapping code between two schema versions'\n"
+	completion += "complete -c xgen -n '__fish_use_subcommand' -a lsp -d 'Serve the language server protocol'\n"
+	completion += "complete -c xgen -n '__fish_use_subcommand' -a verify -d 'Check the round trip of the generated code'\n"
+	completion += fmt.Sprintf("complete -c xgen -n '__fish_seen_subcommand_from completion' -f -a '%s'\n", strings.Join(completionShells, " "))
+	completion += fmt.Sprintf("complete -c xgen -n '__fish_seen_subcommand_from dump' -o format -d 'Specify the output format' -x -a '%s'\n", strings.Join(dumpFormats, " "))
+	completion += fmt.Sprintf("complete -c xgen -n '__fish_seen_subcommand_from mapping' -o l -d 'Specify the language of the mapping code' -x -a '%s'\n", strings.Join(mappingLangs, " "))
+	completion += "complete -c xgen -n '__fish_seen_subcommand_from mapping' -o old -d 'Import path of the old Go package, or path of the old Rust module' -x\n"
+	completion += "complete -c xgen -n '__fish_seen_subcommand_from verify' -o seed -d 'Specify the seed of the sample instances' -x\n"
+	for _, group := range flagGroups {
+		for _, f := range group.Flags {
+			line := fmt.Sprintf("complete -c xgen -o %s -d '%s'", f.Name, strings.Replace(f.Usage, "'", `\'`, -1))
+			switch {
+			case len(f.Values) > 0:
+				line += fmt.Sprintf(" -x -a '%s'", strings.Join(f.Values, " "))
+			case f.Files:
+				line += " -r -F"
+			case f.Arg != "":
+				line += " -x"
+			}
+			completion += line + "\n"
+		}
+	}
+	return completion
+}
+
+// escapeZshDescription escapes the characters with special meaning in the
+// description of the zsh _arguments specs.
+func escapeZshDescription(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`, ":", `\:`).Replace(s)
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenCompletion(t *testing.T) {
+	for shell, expected := range map[string][]string{
+		"bash": {
+			"complete -o default -F _xgen xgen\n",
+			"\t-l)\n\t\tCOMPREPLY=($(compgen -W \"C Go Java Rust TypeScript\" -- \"$cur\"))\n",
+			"\t-i)\n\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))\n",
+			"COMPREPLY=($(compgen -W \"bash zsh fish\" -- \"$cur\"))",
+		},
+		"zsh": {
+			"#compdef xgen\n",
+			"'-validation[Generate validation code]:mode:(method standalone)'",
+			"'-o[Output file path or directory for the generated code]:path:_files'",
+			"(( CURRENT == 3 )) && _values shell bash zsh fish\n",
+		},
+		"fish": {
+			"complete -c xgen -o l -d 'Specify the language of generated code",
+			" -x -a 'C Go Java Rust TypeScript'\n",
+			"complete -c xgen -o i -d 'Input file path or directory for the XML schema definition' -r -F\n",
+			"complete -c xgen -n '__fish_seen_subcommand_from completion' -f -a 'bash zsh fish'\n",
+		},
+	} {
+		completion, err := genCompletion(shell)
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, completion, code, shell)
+		}
+		// Every flag of the help output is completed.
+		for _, group := range flagGroups {
+			for _, f := range group.Flags {
+				assert.Regexp(t, `(^|[\s'(])-(o )?`+f.Name+`\b`, completion, shell)
+			}
+		}
+	}
+	assert.Equal(t, `-format[Specify the output format \: json\]`, "-format["+escapeZshDescription("Specify the output format : json]"))
+
+	_, err := genCompletion("powershell")
+	assert.EqualError(t, err, "unsupport completion shell powershell")
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/xuri/xgen"
+	"gopkg.in/yaml.v3"
+)
+
+// dumpFormats defines the output formats supported by the dump command.
+var dumpFormats = []string{"json", "yaml"}
+
+// runDump outputs the parsed XML schema of the file in the args in the
+// stable form of xgen.SchemaDump. The flags may follow the file.
+func runDump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ContinueOnError)
+	formatPtr := fs.String("format", "json", "Specify the output format (json/yaml)")
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("must specify one XML schema definition file to dump")
+	}
+	dump, err := xgen.NewParser(&xgen.Options{
+		FilePath:            files[0],
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+		RemoteSchema:        make(map[string][]byte),
+	}).Dump()
+	if err != nil {
+		return err
+	}
+	var data []byte
+	switch *formatPtr {
+	case "json":
+		if data, err = json.MarshalIndent(dump, "", "  "); err != nil {
+			return err
+		}
+		data = append(data, '\n')
+	case "yaml":
+		if data, err = yaml.Marshal(dump); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupport dump format %s", *formatPtr)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
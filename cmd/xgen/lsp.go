@@ -0,0 +1,26 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/xuri/xgen"
+)
+
+// runLSP serves the language server protocol for editing the XML schema
+// files on the standard input and output.
+func runLSP(args []string) error {
+	fs := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments of the lsp command")
+	}
+	return xgen.ServeLSP(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/xuri/xgen"
+)
+
+// mappingLangs defines the languages supported by the mapping command.
+var mappingLangs = []string{"Go", "Rust"}
+
+// runMapping outputs the best-effort mapping code from the old to the new
+// version of the XML schema in the args, written to the output file or to
+// the standard output. The flags may follow the files.
+func runMapping(args []string) error {
+	fs := flag.NewFlagSet("mapping", flag.ContinueOnError)
+	langPtr := fs.String("l", "", "Specify the language of the mapping code (Go/Rust)")
+	oldPtr := fs.String("old", "", "Import path of the old Go package, or path of the old Rust module")
+	pkgPtr := fs.String("p", "", "Specify the package name of the new version")
+	oPtr := fs.String("o", "", "Output file path for the mapping code")
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) != 2 {
+		return fmt.Errorf("must specify the old and the new XML schema definition file to map")
+	}
+	if *langPtr != "Go" && *langPtr != "Rust" {
+		return fmt.Errorf("must specify the language of the mapping code by -l, Go or Rust")
+	}
+	if *oldPtr == "" {
+		return fmt.Errorf("must specify the reference to the code of the old version by -old")
+	}
+	opts := make([]*xgen.Options, len(files))
+	for i, file := range files {
+		opts[i] = &xgen.Options{
+			FilePath:            file,
+			Lang:                *langPtr,
+			Package:             *pkgPtr,
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+			RemoteSchema:        make(map[string][]byte),
+		}
+	}
+	data, err := xgen.GenerateMapping(opts[0], opts[1], *oldPtr)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("the versions don't share any type to map")
+	}
+	if *oPtr == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(*oPtr, data, 0644)
+}
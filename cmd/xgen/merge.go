@@ -0,0 +1,50 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/xuri/xgen"
+)
+
+// runMerge combines the XML schema files in the args into a single schema,
+// written to the output file or to the standard output. The flags may follow
+// the files.
+func runMerge(args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+	oPtr := fs.String("o", "", "Output file path for the merged schema")
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("must specify the XML schema definition files to merge")
+	}
+	outputDir := "."
+	if *oPtr != "" {
+		outputDir = filepath.Dir(*oPtr)
+	}
+	data, err := xgen.MergeSchemas(files, outputDir)
+	if err != nil {
+		return err
+	}
+	if *oPtr == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(*oPtr, data, 0644)
+}
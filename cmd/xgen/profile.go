@@ -0,0 +1,111 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// builtinProfiles holds the flags of the built-in profiles by name: the
+// minimal profile generates the fewest types, the serde-only profile the
+// Rust types with the serde derives and the root element wrappers, and the
+// full-validation profile the validation and normalize code failing on the
+// facets and assertions which can't be translated.
+var builtinProfiles = map[string][]string{
+	"minimal":         {"-prune-unused", "-type-aliases"},
+	"serde-only":      {"-l", "Rust", "-root-wrappers"},
+	"full-validation": {"-validation", "method", "-normalize", "-pattern-fallback", "fail", "-assert-fallback", "fail"},
+}
+
+// profileNames returns the names of the built-in profiles in order.
+func profileNames() []string {
+	var names []string
+	for name := range builtinProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readProfiles reads the profiles file of the given name, each line of the
+// file holds the name of a profile, a colon and its flags separated by
+// spaces, such as "payments: -l Rust -validation method", the blank lines
+// and the lines starting with # are ignored. The profiles of the file
+// replace the built-in profiles of the same names.
+func readProfiles(name string) (map[string][]string, error) {
+	profiles := map[string][]string{}
+	for profile, args := range builtinProfiles {
+		profiles[profile] = args
+	}
+	if name == "" {
+		return profiles, nil
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		idx := strings.Index(text, ":")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid profile at %s:%d, expected <name>: <flag> ...", name, line)
+		}
+		profiles[strings.TrimSpace(text[:idx])] = strings.Fields(text[idx+1:])
+	}
+	return profiles, scanner.Err()
+}
+
+// applyProfile sets the flags of the profile by given name on the flag set,
+// except the ones given on the command line, which take precedence over the
+// profile.
+func applyProfile(fs *flag.FlagSet, profiles map[string][]string, name string) error {
+	args, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %s", name)
+	}
+	given := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { given[f.Name] = true })
+	for i := 0; i < len(args); i++ {
+		flagName := strings.TrimLeft(args[i], "-")
+		if flagName == args[i] || flagName == "" {
+			return fmt.Errorf("invalid flag %s of the profile %s", args[i], name)
+		}
+		var value string
+		hasValue := false
+		if idx := strings.Index(flagName, "="); idx != -1 {
+			flagName, value, hasValue = flagName[:idx], flagName[idx+1:], true
+		}
+		f := fs.Lookup(flagName)
+		if f == nil || flagName == "profile" || flagName == "profiles" {
+			return fmt.Errorf("invalid flag %s of the profile %s", args[i], name)
+		}
+		if !hasValue {
+			if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && boolFlag.IsBoolFlag() {
+				value = "true"
+			} else if i++; i < len(args) {
+				value = args[i]
+			} else {
+				return fmt.Errorf("missing the value of the flag -%s of the profile %s", flagName, name)
+			}
+		}
+		if given[flagName] {
+			continue
+		}
+		if err := fs.Set(flagName, value); err != nil {
+			return fmt.Errorf("invalid value %s of the flag -%s of the profile %s: %v", value, flagName, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newProfileFlagSet returns a flag set with the flags set by the profiles of
+// the tests, parsed from the given command line arguments.
+func newProfileFlagSet(t *testing.T, args ...string) (*flag.FlagSet, map[string]*string) {
+	fs := flag.NewFlagSet("xgen", flag.ContinueOnError)
+	values := map[string]*string{}
+	for _, name := range []string{"l", "validation", "pattern-fallback", "assert-fallback", "profile", "profiles"} {
+		values[name] = fs.String(name, "", "")
+	}
+	for _, name := range []string{"prune-unused", "type-aliases", "root-wrappers", "normalize"} {
+		fs.Bool(name, false, "")
+	}
+	require.NoError(t, fs.Parse(args))
+	return fs, values
+}
+
+func TestApplyProfile(t *testing.T) {
+	profiles, err := readProfiles("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"full-validation", "minimal", "serde-only"}, profileNames())
+
+	// The built-in profile sets its flags.
+	fs, values := newProfileFlagSet(t)
+	require.NoError(t, applyProfile(fs, profiles, "full-validation"))
+	assert.Equal(t, "method", *values["validation"])
+	assert.Equal(t, "fail", *values["pattern-fallback"])
+	assert.Equal(t, "true", fs.Lookup("normalize").Value.String())
+
+	// The flags given on the command line take precedence over the profile.
+	fs, values = newProfileFlagSet(t, "-validation", "standalone", "-l", "Go")
+	require.NoError(t, applyProfile(fs, profiles, "full-validation"))
+	assert.Equal(t, "standalone", *values["validation"])
+	assert.Equal(t, "fail", *values["assert-fallback"])
+	require.NoError(t, applyProfile(fs, profiles, "serde-only"))
+	assert.Equal(t, "Go", *values["l"])
+	assert.Equal(t, "true", fs.Lookup("root-wrappers").Value.String())
+
+	assert.EqualError(t, applyProfile(fs, profiles, "unknown"), "unknown profile unknown")
+}
+
+func TestReadProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "profiles.txt")
+	require.NoError(t, ioutil.WriteFile(file, []byte("# profiles\n\npayments: -l Rust -validation=method -prune-unused\nminimal: -type-aliases\n"), 0644))
+	profiles, err := readProfiles(file)
+	require.NoError(t, err)
+
+	// The custom profile sets its flags, and replaces the built-in profile
+	// of the same name.
+	fs, values := newProfileFlagSet(t, "-validation", "standalone")
+	require.NoError(t, applyProfile(fs, profiles, "payments"))
+	assert.Equal(t, "Rust", *values["l"])
+	assert.Equal(t, "standalone", *values["validation"])
+	assert.Equal(t, "true", fs.Lookup("prune-unused").Value.String())
+	assert.Equal(t, []string{"-type-aliases"}, profiles["minimal"])
+	assert.Equal(t, builtinProfiles["serde-only"], profiles["serde-only"])
+
+	for content, expected := range map[string]string{
+		"payments -l Rust\n":     "invalid profile at " + file + ":1, expected <name>: <flag> ...",
+		"payments: l Rust\n":     "invalid flag l of the profile payments",
+		"payments: -unknown\n":   "invalid flag -unknown of the profile payments",
+		"payments: -profile x\n": "invalid flag -profile of the profile payments",
+		"payments: -l\n":         "missing the value of the flag -l of the profile payments",
+	} {
+		require.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+		if profiles, err = readProfiles(file); err == nil {
+			fs, _ = newProfileFlagSet(t)
+			err = applyProfile(fs, profiles, "payments")
+		}
+		assert.EqualError(t, err, expected, content)
+	}
+	_, err = readProfiles(filepath.Join(dir, "missing.txt"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xuri/xgen"
+)
+
+// progressBarWidth defines the number of cells of the progress bar.
+const progressBarWidth = 30
+
+// newProgressReporter returns the progress callback which outputs the
+// progress of the batch and the warnings of the files to the file. A
+// progress bar is drawn when the file is a terminal, otherwise a line is
+// written per schema file.
+func newProgressReporter(file *os.File) func(xgen.Progress) {
+	fi, err := file.Stat()
+	terminal := err == nil && fi.Mode()&os.ModeCharDevice != 0
+	return func(p xgen.Progress) {
+		elapsed, remaining := p.Elapsed.Round(time.Millisecond), p.Remaining.Round(time.Millisecond)
+		for _, warning := range p.Warnings {
+			if terminal {
+				// Clear the progress bar before the warning.
+				fmt.Fprint(file, "\r\033[K")
+			}
+			fmt.Fprintf(file, "warning: %s\n", warning)
+		}
+		if !terminal {
+			fmt.Fprintf(file, "[%d/%d] %s (elapsed %s, ETA %s)\n", p.Completed, p.Total, p.File, elapsed, remaining)
+			return
+		}
+		filled := progressBarWidth * p.Completed / p.Total
+		fmt.Fprintf(file, "\r[%s%s] %d/%d elapsed %s ETA %s\033[K", strings.Repeat("=", filled), strings.Repeat(" ", progressBarWidth-filled), p.Completed, p.Total, elapsed, remaining)
+		if p.Completed == p.Total {
+			fmt.Fprintln(file)
+		}
+	}
+}
+
+// newEventReporter returns the events callback which writes the events of
+// the files to the file as JSON Lines, an object per line.
+func newEventReporter(file *os.File) func(xgen.Event) {
+	encoder := json.NewEncoder(file)
+	return func(e xgen.Event) {
+		_ = encoder.Encode(e)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 - 2022 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/xuri/xgen"
+)
+
+// runVerify checks the round trip of the sample instance of each root element
+// of the XML schema in the args through the generated Go code, and outputs
+// the issues found. The flags may follow the file.
+func runVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	seedPtr := fs.Int64("seed", 1, "Specify the seed of the sample instances")
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		files = append(files, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(files) != 1 {
+		return fmt.Errorf("must specify one XML schema definition file to verify")
+	}
+	report, err := xgen.NewParser(&xgen.Options{
+		FilePath:            files[0],
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+		RemoteSchema:        make(map[string][]byte),
+	}).Verify(*seedPtr)
+	if err != nil {
+		return err
+	}
+	for _, issue := range report.Issues {
+		if issue.Path == "" {
+			fmt.Printf("%s: %s\r\n", issue.Root, issue.Message)
+			continue
+		}
+		fmt.Printf("%s %s: %s\r\n", issue.Root, issue.Path, issue.Message)
+	}
+	if len(report.Issues) > 0 {
+		return fmt.Errorf("found %d issues in the round trip of %d root elements", len(report.Issues), len(report.Roots))
+	}
+	fmt.Printf("verified the round trip of %d root elements\r\n", len(report.Roots))
+	return nil
+}
@@ -11,10 +11,233 @@
 //        -i <path> Input file path or directory for the XML schema definition
 //        -o <path> Output file path or directory for the generated code
 //        -p        Specify the package name
-//        -l        Specify the language of generated code (Go/C/Java/Rust/TypeScript)
+//        -l        Specify the language of generated code (Go/C/Java/Rust/TypeScript), a
+//                  comma-separated list or the repeated flag generates each language from
+//                  one parse into its directory under the output directory (go/c/java/rust/
+//                  typescript), the name ts is TypeScript
+//        -events   Stream the parse events as JSON Lines on stderr instead of the progress
+//        -profile <name>
+//                  Apply the flags of the profile, except the ones given on the command line
+//                  (full-validation/minimal/serde-only)
+//        -profiles <file>
+//                  Read the custom profiles of the lines of the file (name: flag ...), which
+//                  replace the built-in profiles of the same names
+//        -validation <mode>
+//                  Generate validation code for Go and Rust (method/standalone)
+//        -validation-max-depth <n>
+//                  Limit the nesting depth checked by the validation code, 0 is unlimited
+//        -validation-tracing
+//                  Trace the validation code by a hook enabled by the xgen_trace build tag
+//                  in Go and the xgen-trace feature in Rust
+//        -lenient
+//                  Decode the documents missing the required elements, which fail the
+//                  validation code of Go and Rust instead of the decoding in Rust
+//        -pattern-fallback <policy>
+//                  Fail on the pattern facets which can't be translated to the regular
+//                  expressions of Go and Rust, instead of skipping their validation with a
+//                  warning (fail)
+//        -assert-fallback <policy>
+//                  Fail on the assertions which can't be translated to the validation
+//                  code of Go and Rust, instead of skipping their validation with a
+//                  warning (fail)
+//        -test-vectors
+//                  Generate JSON test vectors derived from facets with test stubs
+//        -normalize
+//                  Generate normalize code for Go and Rust applying whiteSpace and case facets
+//        -type-aliases
+//                  Generate type aliases for Go and Rust simple types restricting a type without facets
+//        -boolean-form <form>
+//                  Generate xs:boolean for Go and Rust as a type accepting both the true/false
+//                  and the 1/0 lexical forms, serialized in the form (literal/numeric)
+//        -decimal-form <form>
+//                  Generate xs:decimal for Go and Rust as a type serialized in the decimal
+//                  lexical form regardless of the locale, with the fewest fraction digits or
+//                  the fraction digits of the fractionDigits facet (canonical/fixed-scale)
+//        -patch-types
+//                  Generate a patch type for Go and Rust per complex type with every member
+//                  optional, and the method applying the members which are set
+//        -visitor
+//                  Generate a visitor for Go and Rust with a visit method per type, and the
+//                  accept method of each type calling the visitor on the type and its members
+//        -equality
+//                  Generate the equality method for Go and Rust per complex type comparing
+//                  the values in the canonical form implied by the facets of the schema
+//        -namespace-prefixes <prefix=namespace,...>
+//                  Specify the prefixes the root element wrappers of Go and Rust write the
+//                  namespaces with, the empty prefix writes the default namespace
+//        -pretty-xml
+//                  Generate the pretty XML writers of the root element wrappers for Go and
+//                  Rust with the canonical indentation and attribute order
+//        -rename-case <case>
+//                  Specify the case of the names the Rust fields are renamed to by serde,
+//                  such as for the JSON mappings, defaults to the names of the schema
+//                  (camel/pascal/snake)
+//        -choice-enums
+//                  Generate the choices of elements as Rust enums with a variant per
+//                  element, instead of the optional fields of the elements
+//        -choice-repr <repr>
+//                  Specify the serde representation of the Rust enums of the choices,
+//                  defaults to externally tagged by the element (internal/untagged)
+//        -choice-tag <field>
+//                  Specify the tag field of the internal representation of the Rust
+//                  enums of the choices, defaults to type
+//        -json-value
+//                  Generate the methods converting the Rust types to and from
+//                  serde_json::Value, enabled by the xgen-json feature
+//        -rust-decimal
+//                  Generate xs:decimal as the Decimal type of the rust_decimal crate for
+//                  Rust, instead of f64, and validate the facets on the Decimal values
+//        -rust-chrono
+//                  Generate xs:date, xs:dateTime, xs:time and xs:gYearMonth as Rust types
+//                  wrapping the chrono types, serialized in the XSD lexical forms
+//        -rust-error-type <path>
+//                  Specify the path of the Rust type of the validation errors, such as
+//                  open_payments_common::ValidationError, instead of generating the type
+//        -rust-uses <path,...>
+//                  Specify the paths of the use declarations added to the Rust code, such
+//                  as crate::common::*
+//        -rust-derives <derive[=feature],...>
+//                  Specify the derives added to the Rust types, each optionally gated by the
+//                  Cargo feature, such as Hash,Eq,schemars::JsonSchema=schema
+//        -rust-modules
+//                  Split the Rust code of each schema into a module per root element,
+//                  under the mod.rs of the shared types
+//        -validate-tags
+//                  Generate the validate struct tags of go-playground/validator on the Go
+//                  fields translated from the facets
+//        -sql-methods
+//                  Generate the Scan and Value methods of the Go simple types implementing
+//                  the sql.Scanner and driver.Valuer interfaces
+//        -java-project <tool>
+//                  Generate a Maven or Gradle project around the Java code, with a file per
+//                  class in the package directory and the module-info.java (maven/gradle)
+//        -npm-package <name>
+//                  Generate an npm package of the name around the TypeScript code, with the
+//                  package.json, the tsconfig.json and the index.ts barrel
+//        -json-schemas
+//                  Embed the JSON Schema of each root element for Go, Rust and TypeScript
+//                  as a string constant adjacent to the type of the element
+//        -project-version <version>
+//                  Specify the version of the generated Java project and npm package,
+//                  defaults to 1.0.0
+//        -versioned-packages <import path>
+//                  Generate a Go package per namespace version under the import path of the output
+//                  directory, with conversion stubs between the versions
+//        -group-by-message
+//                  Generate a module per message of the message set for Go, Rust and TypeScript,
+//                  sharing the common module of the other schemas, with the index of the modules
+//        -provenance
+//                  Embed provenance header and write provenance.json
+//        -provenance-timestamp
+//                  Include the generation timestamp in the provenance
+//        -root-wrappers
+//                  Generate typed root element wrappers with XML parse and serialize functions
+//        -constants
+//                  Generate a constants file with the target namespace, the schema version,
+//                  the message identifier and the root element names of the schema
+//        -plural-names
+//                  Generate the fields of the repeated elements and groups named after the
+//                  plural of their names
+//        -plural-overrides <name=plural,...>
+//                  Specify the plurals of the repeated elements and groups by name, instead
+//                  of the pluralization rules and the irregular plurals
+//        -optional-overrides <name=optional|required,...>
+//                  Force the elements and attributes by name, or by type.name, to be optional
+//                  or required in the generated types and validation regardless of the schema
+//        -deprecations <file>
+//                  Mark the elements and attributes by name, or by type.name, of the lines of
+//                  the file as deprecated with the reasons (name[=reason]), in addition to the
+//                  ones the deprecated appinfo annotations mark
+//        -customizations <file>
+//                  Add the Rust derives and attributes and the Go struct tags of the lines of
+//                  the file to the types by name and their members by type.name
+//                  (name=keyword: value), in addition to the appinfo annotations
+//        -external-types <file>
+//                  Resolve the declarations of the external namespaces to the existing types
+//                  of the lines of the file instead of generating them
+//                  (lang {namespace}name=type)
+//        -doc-lang <lang>
+//                  Specify the language of the documentation used in the comments by the
+//                  xml:lang of the documentation elements, defaults to the last documentation
+//        -symbol-map
+//                  Write a JSON map between the qualified names of the declarations and the
+//                  generated type and field identifiers
+//        -size-report
+//                  Write a JSON report of the lines and bytes of each generated file and of
+//                  the code of each declaration, the largest first
+//        -prune-unused
+//                  Omit the types which aren't reachable from any root element
+//        -comment-style <style>
+//                  Specify the style of the comments (block/docstring/hash/slash/triple-slash)
+//        -comment-width <n>
+//                  Wrap the comments at the width preserving the paragraphs, 0 is unwrapped
+//        -any-type <type>
+//                  Specify the type of the elements and attributes without type, and of
+//                  xs:anyType and xs:anySimpleType, such as serde_json::Value, defaults to
+//                  the string type
+//        -charset <label>
+//                  Decode the schema files in the charset regardless of their XML
+//                  declaration, such as ISO-8859-1
+//        -entities <name=value,...>
+//                  Specify the custom entities the schema files reference by name
+//        -non-strict
+//                  Accept the schema files which aren't well-formed, such as the unquoted
+//                  attribute values and the unknown entities
+//        -max-tokens <n>
+//                  Limit the number of the XML tokens of each schema file, 0 is unlimited
+//        -max-nesting <n>
+//                  Limit the nesting depth of the elements of each schema file, 0 is
+//                  unlimited
+//        -max-import-depth <n>
+//                  Limit the depth of the chains of the imported and included schemas, 0
+//                  is unlimited
+//        -max-declarations <n>
+//                  Limit the number of the top-level declarations of each schema file, 0
+//                  is unlimited
+//        -max-entity-expansion <n>
+//                  Limit the number of the bytes the custom entities of each schema file
+//                  expand to, 0 is unlimited
+//        -max-file-size <n>
+//                  Limit the size in bytes of each schema file, 0 is unlimited
+//        -remote-schemas <dir>
+//                  Fetch the schemas imported and included from the URLs into the
+//                  directory caching them, instead of skipping them
+//        -offline
+//                  Fail on the remote schemas which aren't cached instead of fetching them
+//        -mixins
+//                  Generate the attribute groups and groups as mixins for Go, Java and Rust,
+//                  embedded structs, interfaces and traits, instead of the nested fields
+//        -accessors
+//                  Generate the private fields with the getter and setter methods for Java,
+//                  Rust and TypeScript, instead of the public fields
 //        -h        Output this help and exit
 //        -v        Output version and exit
 //
+//    $ xgen completion <bash|zsh|fish>
+//    $ xgen dump <XSD file> [-format json|yaml]
+//    $ xgen merge <XSD file> ... [-o <path>]
+//    $ xgen mapping <old XSD file> <new XSD file> -l <Go|Rust> -old <ref> [-p <package>] [-o <path>]
+//    $ xgen lsp
+//    $ xgen verify <XSD file> [-seed <n>]
+//
+// The dump command outputs the parsed XML schema, the form of the output is
+// documented by the xgen.SchemaDump type. The merge command combines the
+// schemas sharing a target namespace into a single schema. The mapping
+// command outputs the best-effort mapping code between the old and the new
+// version of a schema, with TODO markers for the fields which don't match,
+// the -old flag specifies the import path of the old Go package or the path
+// of the old Rust module. The lsp command serves the language server
+// protocol on the standard input and output, with the hover, the definition
+// and the diagnostics of the XML schema files. The verify command checks the
+// round trip of a sample instance of each root element through the generated
+// Go code, flagging the elements and the namespaces the code loses, and it
+// requires the go command.
+//
+// The completion command outputs the completion script of the shell, for
+// example:
+//
+//    $ source <(xgen completion bash)
+//
 // If the path specified by the -i flag is a directory, all files in the
 // directory will be processed as XML schema definition.
 //
@@ -28,6 +251,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/xuri/xgen"
 )
@@ -35,27 +259,241 @@ import (
 // Config holds user-defined overrides and filters that are used when
 // generating source code from an XSD document.
 type Config struct {
-	I       string
-	O       string
-	Pkg     string
-	Lang    string
-	Version string
+	I            string
+	O            string
+	Pkg          string
+	Lang         string
+	Langs        []string
+	Validation   string
+	TestVectors  bool
+	Normalize    bool
+	MaxDepth     int
+	Tracing      bool
+	Lenient      bool
+	Provenance   bool
+	Timestamp    bool
+	RootWrappers bool
+	PruneUnused  bool
+	TypeAliases  bool
+	Versioned    string
+	GroupByMsg   bool
+	CommentStyle string
+	CommentWidth int
+	AnyType      string
+	Mixins       bool
+	BooleanForm  string
+	DecimalForm  string
+	RenameCase   string
+	ChoiceEnums  bool
+	ChoiceRepr   string
+	ChoiceTag    string
+	JSONValue    bool
+	RustDecimal  bool
+	RustChrono   bool
+	RustError    string
+	RustUses     []string
+	RustDerives  []xgen.RustDerive
+	RustModules  bool
+	ValidateTags bool
+	SQLMethods   bool
+	JavaProject  string
+	ProjectVer   string
+	NPMPackage   string
+	JSONSchemas  bool
+	PatternMode  string
+	AssertMode   string
+	NSPrefixes   map[string]string
+	Constants    bool
+	SymbolMap    bool
+	SizeReport   bool
+	PluralNames  bool
+	Plurals      map[string]string
+	Optionals    map[string]bool
+	Deprecations map[string]string
+	Customs      map[string][]string
+	Externals    xgen.ExternalTypes
+	Charset      string
+	Entities     map[string]string
+	NonStrict    bool
+	MaxTokens    int
+	MaxNesting   int
+	MaxImports   int
+	MaxDecls     int
+	MaxExpansion int64
+	MaxFileSize  int64
+	RemoteDir    string
+	Offline      bool
+	DocLang      string
+	Accessors    bool
+	PatchTypes   bool
+	Visitor      bool
+	Equality     bool
+	PrettyXML    bool
+	Events       bool
+	Version      string
 }
 
 // Cfg are the default config for xgen. The default package name and output
 // directory are "schema" and "xgen_out".
 var Cfg = Config{
 	Pkg:     "schema",
-	Version: "0.1.0",
+	Version: xgen.Version,
+}
+
+// Flag groups and usage of the program, which are used for the help output
+// and the shell completion scripts.
+var flagGroups = []flagGroup{
+	{Title: "General", Flags: []flagUsage{
+		{Name: "i", Arg: "<path>", Usage: "Input file path or directory for the XML schema definition", Files: true},
+		{Name: "o", Arg: "<path>", Usage: "Output file path or directory for the generated code", Files: true},
+		{Name: "p", Arg: "<name>", Usage: "Specify the package name"},
+		{Name: "l", Arg: "<lang>", Usage: "Specify the language of generated code, a comma-separated list or the repeated flag generates each language from one parse into its directory under the output directory", Values: []string{"C", "Go", "Java", "Rust", "TypeScript"}},
+		{Name: "events", Usage: "Stream the parse events as JSON Lines on stderr instead of the progress"},
+		{Name: "profile", Arg: "<name>", Usage: "Apply the flags of the profile, except the ones given on the command line", Values: profileNames()},
+		{Name: "profiles", Arg: "<file>", Usage: "Read the custom profiles of the lines of the file (name: flag ...), which replace the built-in profiles of the same names", Files: true},
+	}},
+	{Title: "Go and Rust", Flags: []flagUsage{
+		{Name: "validation", Arg: "<mode>", Usage: "Generate validation code", Values: []string{xgen.ValidationMethod, xgen.ValidationStandalone}},
+		{Name: "validation-max-depth", Arg: "<n>", Usage: "Limit the nesting depth checked by the validation code, 0 is unlimited"},
+		{Name: "validation-tracing", Usage: "Trace the validation code by a hook enabled by the xgen_trace build tag in Go and the xgen-trace feature in Rust"},
+		{Name: "lenient", Usage: "Decode the documents missing the required elements, which fail the validation code instead of the decoding in Rust"},
+		{Name: "pattern-fallback", Arg: "<policy>", Usage: "Fail on the pattern facets which can't be translated to the regular expressions of Go and Rust, instead of skipping their validation with a warning", Values: []string{xgen.PatternFallbackFail}},
+		{Name: "assert-fallback", Arg: "<policy>", Usage: "Fail on the assertions which can't be translated to the validation code of Go and Rust, instead of skipping their validation with a warning", Values: []string{xgen.AssertFallbackFail}},
+		{Name: "normalize", Usage: "Generate normalize code applying whiteSpace and case facets"},
+		{Name: "test-vectors", Usage: "Generate JSON test vectors derived from facets with test stubs"},
+		{Name: "type-aliases", Usage: "Generate type aliases for simple types restricting a type without facets"},
+		{Name: "boolean-form", Arg: "<form>", Usage: "Generate xs:boolean as a type accepting both the true/false and the 1/0 lexical forms, serialized in the form", Values: []string{xgen.BooleanFormLiteral, xgen.BooleanFormNumeric}},
+		{Name: "decimal-form", Arg: "<form>", Usage: "Generate xs:decimal as a type serialized in the decimal lexical form, with the fewest fraction digits or the fraction digits of the fractionDigits facet", Values: []string{xgen.DecimalFormCanonical, xgen.DecimalFormFixedScale}},
+		{Name: "patch-types", Usage: "Generate a patch type per complex type with every member optional, and the method applying the members which are set"},
+		{Name: "visitor", Usage: "Generate a visitor with a visit method per type, and the accept method of each type calling the visitor on the type and its members"},
+		{Name: "equality", Usage: "Generate the equality method per complex type comparing the values in the canonical form implied by the facets of the schema"},
+		{Name: "namespace-prefixes", Arg: "<prefix=namespace,...>", Usage: "Specify the prefixes the root element wrappers write the namespaces with, the empty prefix writes the default namespace"},
+		{Name: "pretty-xml", Usage: "Generate the pretty XML writers of the root element wrappers with the canonical indentation and attribute order"},
+		{Name: "versioned-packages", Arg: "<import path>", Usage: "Generate a Go package per namespace version under the import path of the output directory"},
+		{Name: "group-by-message", Usage: "Generate a module per message of the message set for Go, Rust and TypeScript, sharing the common module of the other schemas, with the index of the modules"},
+	}},
+	{Title: "Go", Flags: []flagUsage{
+		{Name: "validate-tags", Usage: "Generate the validate struct tags of go-playground/validator on the fields translated from the facets"},
+		{Name: "sql-methods", Usage: "Generate the Scan and Value methods of the simple types implementing the sql.Scanner and driver.Valuer interfaces"},
+	}},
+	{Title: "Java", Flags: []flagUsage{
+		{Name: "java-project", Arg: "<tool>", Usage: "Generate a project of the build tool around the code, with a file per class in the package directory and the module-info.java", Values: []string{xgen.JavaProjectMaven, xgen.JavaProjectGradle}},
+	}},
+	{Title: "Rust", Flags: []flagUsage{
+		{Name: "rename-case", Arg: "<case>", Usage: "Specify the case of the names the fields are renamed to by serde, defaults to the names of the schema", Values: []string{xgen.RenameCaseCamel, xgen.RenameCasePascal, xgen.RenameCaseSnake}},
+		{Name: "choice-enums", Usage: "Generate the choices of elements as enums with a variant per element, instead of the optional fields of the elements"},
+		{Name: "choice-repr", Arg: "<repr>", Usage: "Specify the serde representation of the enums of the choices, defaults to externally tagged by the element", Values: []string{xgen.ChoiceReprInternal, xgen.ChoiceReprUntagged}},
+		{Name: "choice-tag", Arg: "<field>", Usage: "Specify the tag field of the internal representation of the enums of the choices, defaults to " + xgen.ChoiceTagDefault},
+		{Name: "json-value", Usage: "Generate the methods converting the types to and from serde_json::Value, enabled by the xgen-json feature"},
+		{Name: "rust-decimal", Usage: "Generate xs:decimal as the Decimal type of the rust_decimal crate instead of f64"},
+		{Name: "rust-chrono", Usage: "Generate xs:date, xs:dateTime, xs:time and xs:gYearMonth as types wrapping the chrono types, serialized in the XSD lexical forms"},
+		{Name: "rust-error-type", Arg: "<path>", Usage: "Specify the path of the type of the validation errors, such as open_payments_common::ValidationError, instead of generating the type"},
+		{Name: "rust-uses", Arg: "<path,...>", Usage: "Specify the paths of the use declarations added to the code, such as crate::common::*"},
+		{Name: "rust-derives", Arg: "<derive[=feature],...>", Usage: "Specify the derives added to the types, each optionally gated by the Cargo feature, such as Hash,Eq,schemars::JsonSchema=schema"},
+		{Name: "rust-modules", Usage: "Split the code of each schema into a module per root element, holding the types only the element reaches, under the mod.rs of the shared types"},
+	}},
+	{Title: "Go, Java and Rust", Flags: []flagUsage{
+		{Name: "mixins", Usage: "Generate the attribute groups and groups as mixins instead of the nested fields"},
+	}},
+	{Title: "TypeScript", Flags: []flagUsage{
+		{Name: "npm-package", Arg: "<name>", Usage: "Generate an npm package of the name around the code, with the package.json, the tsconfig.json and the index.ts barrel"},
+	}},
+	{Title: "Go, Rust and TypeScript", Flags: []flagUsage{
+		{Name: "json-schemas", Usage: "Embed the JSON Schema of each root element as a string constant adjacent to the type of the element"},
+	}},
+	{Title: "Java and TypeScript", Flags: []flagUsage{
+		{Name: "project-version", Arg: "<version>", Usage: "Specify the version of the generated project and package, defaults to " + xgen.ProjectVersionDefault},
+	}},
+	{Title: "Java, Rust and TypeScript", Flags: []flagUsage{
+		{Name: "accessors", Usage: "Generate the private fields with the getter and setter methods instead of the public fields"},
+	}},
+	{Title: "All languages", Flags: []flagUsage{
+		{Name: "root-wrappers", Usage: "Generate typed root element wrappers with XML parse and serialize functions"},
+		{Name: "constants", Usage: "Generate a constants file with the target namespace, the schema version, the message identifier and the root element names"},
+		{Name: "plural-names", Usage: "Generate the fields of the repeated elements and groups named after the plural of their names"},
+		{Name: "plural-overrides", Arg: "<name=plural,...>", Usage: "Specify the plurals of the repeated elements and groups by name, instead of the pluralization rules and the irregular plurals"},
+		{Name: "optional-overrides", Arg: "<name=optional|required,...>", Usage: "Force the elements and attributes by name, or by type.name, to be optional or required in the generated types and validation regardless of the schema"},
+		{Name: "deprecations", Arg: "<file>", Usage: "Mark the elements and attributes by name, or by type.name, of the lines of the file as deprecated with the reasons (name[=reason]), in addition to the ones the deprecated appinfo annotations mark"},
+		{Name: "customizations", Arg: "<file>", Usage: "Add the Rust derives and attributes and the Go struct tags of the lines of the file to the types by name and their members by type.name (name=keyword: value), in addition to the appinfo annotations"},
+		{Name: "external-types", Arg: "<file>", Usage: "Resolve the declarations of the external namespaces to the existing types of the lines of the file instead of generating them (lang {namespace}name=type)", Files: true},
+		{Name: "doc-lang", Arg: "<lang>", Usage: "Specify the language of the documentation used in the comments by the xml:lang of the documentation elements, defaults to the last documentation"},
+		{Name: "symbol-map", Usage: "Write a JSON map between the qualified names of the declarations and the generated type and field identifiers"},
+		{Name: "size-report", Usage: "Write a JSON report of the lines and bytes of each generated file and of the code of each declaration, the largest first"},
+		{Name: "prune-unused", Usage: "Omit the types which aren't reachable from any root element"},
+		{Name: "comment-style", Arg: "<style>", Usage: "Specify the style of the comments", Values: xgen.CommentStyleNames()},
+		{Name: "comment-width", Arg: "<n>", Usage: "Wrap the comments at the width preserving the paragraphs, 0 is unwrapped"},
+		{Name: "any-type", Arg: "<type>", Usage: "Specify the type of the elements and attributes without type, and of xs:anyType and xs:anySimpleType"},
+		{Name: "charset", Arg: "<label>", Usage: "Decode the schema files in the charset regardless of their XML declaration, such as ISO-8859-1"},
+		{Name: "entities", Arg: "<name=value,...>", Usage: "Specify the custom entities the schema files reference by name"},
+		{Name: "non-strict", Usage: "Accept the schema files which aren't well-formed, such as the unquoted attribute values and the unknown entities"},
+		{Name: "max-tokens", Arg: "<n>", Usage: "Limit the number of the XML tokens of each schema file, 0 is unlimited"},
+		{Name: "max-nesting", Arg: "<n>", Usage: "Limit the nesting depth of the elements of each schema file, 0 is unlimited"},
+		{Name: "max-import-depth", Arg: "<n>", Usage: "Limit the depth of the chains of the imported and included schemas, 0 is unlimited"},
+		{Name: "max-declarations", Arg: "<n>", Usage: "Limit the number of the top-level declarations of each schema file, 0 is unlimited"},
+		{Name: "max-entity-expansion", Arg: "<n>", Usage: "Limit the number of the bytes the custom entities of each schema file expand to, 0 is unlimited"},
+		{Name: "max-file-size", Arg: "<n>", Usage: "Limit the size in bytes of each schema file, 0 is unlimited"},
+		{Name: "remote-schemas", Arg: "<dir>", Usage: "Fetch the schemas imported and included from the URLs into the directory caching them, instead of skipping them"},
+		{Name: "offline", Usage: "Fail on the remote schemas which aren't cached instead of fetching them"},
+		{Name: "provenance", Usage: "Embed provenance header and write provenance.json"},
+		{Name: "provenance-timestamp", Usage: "Include the generation timestamp in the provenance"},
+	}},
+	{Title: "Other", Flags: []flagUsage{
+		{Name: "h", Usage: "Output this help and exit"},
+		{Name: "v", Usage: "Output version and exit"},
+	}},
+}
+
+// langsFlag holds the values of the repeated -l flag, each a language or a
+// comma-separated list of languages.
+type langsFlag []string
+
+// String returns the languages of the flag.
+func (f *langsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set adds the value of the flag.
+func (f *langsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// flagGroup holds the flags shown together in the help output.
+type flagGroup struct {
+	Title string
+	Flags []flagUsage
 }
 
-// SupportLang defines supported language types.
-var SupportLang = map[string]bool{
-	"Go":         true,
-	"C":          true,
-	"Java":       true,
-	"Rust":       true,
-	"TypeScript": true,
+// flagUsage describes a flag of the program. Values holds the accepted
+// values of the flag, and Files reports whether the flag takes a path.
+type flagUsage struct {
+	Name   string
+	Arg    string
+	Usage  string
+	Values []string
+	Files  bool
+}
+
+// printUsage outputs the help of the program with the flags grouped by the
+// languages they apply to.
+func printUsage() {
+	fmt.Printf("xgen version: %s\r\nCopyright (c) 2020 - 2022 Ri Xu https://xuri.me All rights reserved.\r\n\r\nUsage:\r\n$ xgen [<flag> ...] <XSD file or directory> ...\r\n$ xgen completion <%s>\r\n$ xgen dump <XSD file> [-format %s]\r\n$ xgen merge <XSD file> ... [-o <path>]\r\n$ xgen mapping <old XSD file> <new XSD file> -l <%s> -old <ref> [-p <package>] [-o <path>]\r\n$ xgen lsp\r\n$ xgen verify <XSD file> [-seed <n>]\r\n", Cfg.Version, strings.Join(completionShells, "|"), strings.Join(dumpFormats, "|"), strings.Join(mappingLangs, "|"))
+	for _, group := range flagGroups {
+		fmt.Printf("\r\n%s:\r\n", group.Title)
+		for _, f := range group.Flags {
+			usage := f.Usage
+			if len(f.Values) > 0 {
+				usage += fmt.Sprintf(" (%s)", strings.Join(f.Values, "/"))
+			}
+			name := strings.TrimSpace("-" + f.Name + " " + f.Arg)
+			if len(name) >= 28 {
+				// The usage of the long flag goes to the next line.
+				fmt.Printf("  %s\r\n  %-28s%s\r\n", name, "", usage)
+				continue
+			}
+			fmt.Printf("  %-28s%s\r\n", name, usage)
+		}
+	}
 }
 
 // parseFlags parse flags of program.
@@ -63,54 +501,353 @@ func parseFlags() *Config {
 	iPtr := flag.String("i", "", "Input file path or directory for the XML schema definition")
 	oPtr := flag.String("o", "xgen_out", "Output file path or directory for the generated code")
 	pkgPtr := flag.String("p", "", "Specify the package name")
-	langPtr := flag.String("l", "", "Specify the language of generated code")
+	var langs langsFlag
+	flag.Var(&langs, "l", "Specify the language of generated code, a comma-separated list or the repeated flag generates each language into its directory under the output directory")
+	eventsPtr := flag.Bool("events", false, "Stream the parse events as JSON Lines on stderr instead of the progress")
+	profilePtr := flag.String("profile", "", "Apply the flags of the profile, except the ones given on the command line")
+	profilesPtr := flag.String("profiles", "", "Read the custom profiles of the lines of the file (name: flag ...)")
+	validationPtr := flag.String("validation", "", "Generate validation code (method/standalone)")
+	maxDepthPtr := flag.Int("validation-max-depth", 0, "Limit the nesting depth checked by the validation code, 0 is unlimited")
+	tracingPtr := flag.Bool("validation-tracing", false, "Trace the validation code by a hook enabled by the xgen_trace build tag in Go and the xgen-trace feature in Rust")
+	lenientPtr := flag.Bool("lenient", false, "Decode the documents missing the required elements, which fail the validation code instead of the decoding in Rust")
+	testVectorsPtr := flag.Bool("test-vectors", false, "Generate JSON test vectors derived from facets with test stubs")
+	normalizePtr := flag.Bool("normalize", false, "Generate normalize code applying whiteSpace and case facets")
+	provenancePtr := flag.Bool("provenance", false, "Embed provenance header and write provenance.json")
+	timestampPtr := flag.Bool("provenance-timestamp", false, "Include the generation timestamp in the provenance")
+	visitorPtr := flag.Bool("visitor", false, "Generate a visitor with a visit method per type")
+	equalityPtr := flag.Bool("equality", false, "Generate the equality method per complex type comparing the values in the canonical form")
+	patchTypesPtr := flag.Bool("patch-types", false, "Generate a patch type per complex type with every member optional")
+	accessorsPtr := flag.Bool("accessors", false, "Generate the private fields with the getter and setter methods instead of the public fields")
+	constantsPtr := flag.Bool("constants", false, "Generate a constants file with the target namespace, the schema version, the message identifier and the root element names")
+	symbolMapPtr := flag.Bool("symbol-map", false, "Write a JSON map between the qualified names of the declarations and the generated type and field identifiers")
+	sizeReportPtr := flag.Bool("size-report", false, "Write a JSON report of the lines and bytes of each generated file and of the code of each declaration")
+	pluralNamesPtr := flag.Bool("plural-names", false, "Generate the fields of the repeated elements and groups named after the plural of their names")
+	pluralOverridesPtr := flag.String("plural-overrides", "", "Specify the plurals of the repeated elements and groups by name (name=plural,...)")
+	optionalOverridesPtr := flag.String("optional-overrides", "", "Force the elements and attributes by name, or by type.name, to be optional or required regardless of the schema (name=optional|required,...)")
+	deprecationsPtr := flag.String("deprecations", "", "Mark the elements and attributes by name, or by type.name, of the lines of the file as deprecated with the reasons (name[=reason])")
+	customizationsPtr := flag.String("customizations", "", "Add the Rust derives and attributes and the Go struct tags of the lines of the file to the types by name and their members by type.name (name=keyword: value)")
+	externalTypesPtr := flag.String("external-types", "", "Resolve the declarations of the external namespaces to the existing types of the lines of the file instead of generating them (lang {namespace}name=type)")
+	docLangPtr := flag.String("doc-lang", "", "Specify the language of the documentation used in the comments by the xml:lang of the documentation elements")
+	rootWrappersPtr := flag.Bool("root-wrappers", false, "Generate typed root element wrappers with XML parse and serialize functions")
+	pruneUnusedPtr := flag.Bool("prune-unused", false, "Omit the types which aren't reachable from any root element")
+	typeAliasesPtr := flag.Bool("type-aliases", false, "Generate type aliases for simple types restricting a type without facets")
+	booleanFormPtr := flag.String("boolean-form", "", "Generate xs:boolean as a type accepting both the true/false and the 1/0 lexical forms, serialized in the form (literal/numeric)")
+	decimalFormPtr := flag.String("decimal-form", "", "Generate xs:decimal as a type serialized in the decimal lexical form (canonical/fixed-scale)")
+	patternFallbackPtr := flag.String("pattern-fallback", "", "Fail on the pattern facets which can't be translated to the regular expressions of Go and Rust, instead of skipping their validation with a warning (fail)")
+	assertFallbackPtr := flag.String("assert-fallback", "", "Fail on the assertions which can't be translated to the validation code of Go and Rust, instead of skipping their validation with a warning (fail)")
+	choiceEnumsPtr := flag.Bool("choice-enums", false, "Generate the choices of elements as enums with a variant per element, instead of the optional fields of the elements")
+	choiceReprPtr := flag.String("choice-repr", "", "Specify the serde representation of the enums of the choices (internal/untagged)")
+	choiceTagPtr := flag.String("choice-tag", "", "Specify the tag field of the internal representation of the enums of the choices, defaults to "+xgen.ChoiceTagDefault)
+	validateTagsPtr := flag.Bool("validate-tags", false, "Generate the validate struct tags of go-playground/validator on the fields translated from the facets")
+	sqlMethodsPtr := flag.Bool("sql-methods", false, "Generate the Scan and Value methods of the simple types implementing the sql.Scanner and driver.Valuer interfaces")
+	javaProjectPtr := flag.String("java-project", "", "Generate a project of the build tool around the code, with a file per class in the package directory and the module-info.java (maven/gradle)")
+	npmPackagePtr := flag.String("npm-package", "", "Generate an npm package of the name around the code, with the package.json, the tsconfig.json and the index.ts barrel")
+	jsonSchemasPtr := flag.Bool("json-schemas", false, "Embed the JSON Schema of each root element as a string constant adjacent to the type of the element")
+	projectVersionPtr := flag.String("project-version", "", "Specify the version of the generated project and package, defaults to "+xgen.ProjectVersionDefault)
+	jsonValuePtr := flag.Bool("json-value", false, "Generate the methods converting the types to and from serde_json::Value, enabled by the xgen-json feature")
+	rustDecimalPtr := flag.Bool("rust-decimal", false, "Generate xs:decimal as the Decimal type of the rust_decimal crate instead of f64")
+	rustChronoPtr := flag.Bool("rust-chrono", false, "Generate xs:date, xs:dateTime, xs:time and xs:gYearMonth as types wrapping the chrono types, serialized in the XSD lexical forms")
+	rustErrorTypePtr := flag.String("rust-error-type", "", "Specify the path of the type of the validation errors, such as open_payments_common::ValidationError, instead of generating the type")
+	rustUsesPtr := flag.String("rust-uses", "", "Specify the paths of the use declarations added to the code (path,...)")
+	rustDerivesPtr := flag.String("rust-derives", "", "Specify the derives added to the types, each optionally gated by the Cargo feature (derive[=feature],...)")
+	rustModulesPtr := flag.Bool("rust-modules", false, "Split the code of each schema into a module per root element, under the mod.rs of the shared types")
+	renameCasePtr := flag.String("rename-case", "", "Specify the case of the names the fields are renamed to by serde (camel/pascal/snake)")
+	prettyXMLPtr := flag.Bool("pretty-xml", false, "Generate the pretty XML writers of the root element wrappers with the canonical indentation and attribute order")
+	nsPrefixesPtr := flag.String("namespace-prefixes", "", "Specify the prefixes the root element wrappers write the namespaces with (prefix=namespace,...)")
+	versionedPtr := flag.String("versioned-packages", "", "Generate a Go package per namespace version under the import path of the output directory")
+	groupByMsgPtr := flag.Bool("group-by-message", false, "Generate a module per message of the message set, sharing the common module of the other schemas")
+	commentStylePtr := flag.String("comment-style", "", "Specify the style of the comments")
+	commentWidthPtr := flag.Int("comment-width", 0, "Wrap the comments at the width preserving the paragraphs, 0 is unwrapped")
+	anyTypePtr := flag.String("any-type", "", "Specify the type of the elements and attributes without type, and of xs:anyType and xs:anySimpleType")
+	charsetPtr := flag.String("charset", "", "Decode the schema files in the charset regardless of their XML declaration")
+	entitiesPtr := flag.String("entities", "", "Specify the custom entities the schema files reference by name (name=value,...)")
+	nonStrictPtr := flag.Bool("non-strict", false, "Accept the schema files which aren't well-formed")
+	maxTokensPtr := flag.Int("max-tokens", 0, "Limit the number of the XML tokens of each schema file, 0 is unlimited")
+	maxNestingPtr := flag.Int("max-nesting", 0, "Limit the nesting depth of the elements of each schema file, 0 is unlimited")
+	maxImportsPtr := flag.Int("max-import-depth", 0, "Limit the depth of the chains of the imported and included schemas, 0 is unlimited")
+	maxDeclsPtr := flag.Int("max-declarations", 0, "Limit the number of the top-level declarations of each schema file, 0 is unlimited")
+	maxExpansionPtr := flag.Int64("max-entity-expansion", 0, "Limit the number of the bytes the custom entities of each schema file expand to, 0 is unlimited")
+	maxFileSizePtr := flag.Int64("max-file-size", 0, "Limit the size in bytes of each schema file, 0 is unlimited")
+	remoteDirPtr := flag.String("remote-schemas", "", "Fetch the schemas imported and included from the URLs into the directory caching them")
+	offlinePtr := flag.Bool("offline", false, "Fail on the remote schemas which aren't cached instead of fetching them")
+	mixinsPtr := flag.Bool("mixins", false, "Generate the attribute groups and groups as mixins instead of the nested fields")
 	verPtr := flag.Bool("v", false, "Show version and exit")
 	helpPtr := flag.Bool("h", false, "Show this help and exit")
+	flag.Usage = printUsage
 	flag.Parse()
 	if *helpPtr {
-		fmt.Printf("xgen version: %s\r\nCopyright (c) 2020 - 2022 Ri Xu https://xuri.me All rights reserved.\r\n\r\nUsage:\r\n$ xgen [<flag> ...] <XSD file or directory> ...\n  -i <path>\tInput file path or directory for the XML schema definition\r\n  -o <path>\tOutput file path or directory for the generated code\r\n  -p     \tSpecify the package name\r\n  -l      \tSpecify the language of generated code (Go/C/Java/Rust/TypeScript)\r\n  -h     \tOutput this help and exit\r\n  -v     \tOutput version and exit\r\n", Cfg.Version)
+		printUsage()
 		os.Exit(0)
 	}
 	if *verPtr {
 		fmt.Printf("xgen version: %s\r\n", Cfg.Version)
 		os.Exit(0)
 	}
+	if *profilePtr != "" {
+		profiles, err := readProfiles(*profilesPtr)
+		if err == nil {
+			err = applyProfile(flag.CommandLine, profiles, *profilePtr)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
 	if *iPtr == "" {
 		fmt.Println("must specify input file path or directory for the XML schema definition")
 		os.Exit(1)
 	}
 	Cfg.I = *iPtr
-	if *langPtr == "" {
+	parsedLangs, err := xgen.ParseLangs(strings.Join(langs, ","))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if len(parsedLangs) == 0 {
 		fmt.Println("must specify the language of generated code (Go/C/Java/Rust/TypeScript)")
 		os.Exit(1)
 	}
-	Cfg.Lang = *langPtr
 	if *oPtr != "" {
 		Cfg.O = *oPtr
 	}
-	if ok := SupportLang[Cfg.Lang]; !ok {
-		fmt.Println("unsupport language", Cfg.Lang)
-		os.Exit(1)
+	if Cfg.Lang = parsedLangs[0]; len(parsedLangs) > 1 {
+		Cfg.Lang, Cfg.Langs = "", parsedLangs
 	}
 	if *pkgPtr != "" {
 		Cfg.Pkg = *pkgPtr
 	}
+	switch *validationPtr {
+	case xgen.ValidationNone, xgen.ValidationMethod, xgen.ValidationStandalone:
+		Cfg.Validation = *validationPtr
+	default:
+		fmt.Println("unsupport validation mode", *validationPtr)
+		os.Exit(1)
+	}
+	if *maxDepthPtr < 0 {
+		fmt.Println("invalid validation max depth", *maxDepthPtr)
+		os.Exit(1)
+	}
+	Cfg.MaxDepth = *maxDepthPtr
+	Cfg.Tracing = *tracingPtr
+	Cfg.Lenient = *lenientPtr
+	switch *booleanFormPtr {
+	case xgen.BooleanFormNative, xgen.BooleanFormLiteral, xgen.BooleanFormNumeric:
+		Cfg.BooleanForm = *booleanFormPtr
+	default:
+		fmt.Println("unsupport boolean form", *booleanFormPtr)
+		os.Exit(1)
+	}
+	switch *decimalFormPtr {
+	case xgen.DecimalFormNative, xgen.DecimalFormCanonical, xgen.DecimalFormFixedScale:
+		Cfg.DecimalForm = *decimalFormPtr
+	default:
+		fmt.Println("unsupport decimal form", *decimalFormPtr)
+		os.Exit(1)
+	}
+	switch *patternFallbackPtr {
+	case xgen.PatternFallbackSkip, xgen.PatternFallbackFail:
+		Cfg.PatternMode = *patternFallbackPtr
+	default:
+		fmt.Println("unsupport pattern fallback", *patternFallbackPtr)
+		os.Exit(1)
+	}
+	switch *assertFallbackPtr {
+	case xgen.AssertFallbackSkip, xgen.AssertFallbackFail:
+		Cfg.AssertMode = *assertFallbackPtr
+	default:
+		fmt.Println("unsupport assert fallback", *assertFallbackPtr)
+		os.Exit(1)
+	}
+	switch *choiceReprPtr {
+	case xgen.ChoiceReprExternal, xgen.ChoiceReprInternal, xgen.ChoiceReprUntagged:
+		Cfg.ChoiceRepr = *choiceReprPtr
+	default:
+		fmt.Println("unsupport choice representation", *choiceReprPtr)
+		os.Exit(1)
+	}
+	switch *javaProjectPtr {
+	case xgen.JavaProjectNone, xgen.JavaProjectMaven, xgen.JavaProjectGradle:
+		Cfg.JavaProject = *javaProjectPtr
+	default:
+		fmt.Println("unsupport Java project", *javaProjectPtr)
+		os.Exit(1)
+	}
+	switch *renameCasePtr {
+	case xgen.RenameCaseSchema, xgen.RenameCaseCamel, xgen.RenameCasePascal, xgen.RenameCaseSnake:
+		Cfg.RenameCase = *renameCasePtr
+	default:
+		fmt.Println("unsupport rename case", *renameCasePtr)
+		os.Exit(1)
+	}
+	nsPrefixes, err := xgen.ParseNamespacePrefixes(*nsPrefixesPtr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	Cfg.NSPrefixes = nsPrefixes
+	plurals, err := xgen.ParsePluralOverrides(*pluralOverridesPtr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	Cfg.Plurals = plurals
+	optionals, err := xgen.ParseOptionalOverrides(*optionalOverridesPtr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	Cfg.Optionals = optionals
+	deprecations, err := xgen.ReadDeprecations(*deprecationsPtr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	Cfg.Deprecations = deprecations
+	customizations, err := xgen.ReadCustomizations(*customizationsPtr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	Cfg.Customs = customizations
+	externalTypes, err := xgen.ReadExternalTypes(*externalTypesPtr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	Cfg.Externals = externalTypes
+	rustUses, err := xgen.ParseRustUses(*rustUsesPtr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	Cfg.RustUses = rustUses
+	rustDerives, err := xgen.ParseRustDerives(*rustDerivesPtr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	Cfg.RustDerives = rustDerives
+	Cfg.RustModules = *rustModulesPtr
+	entities, err := xgen.ParseEntities(*entitiesPtr)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	Cfg.Entities = entities
+	if *maxTokensPtr < 0 || *maxNestingPtr < 0 {
+		fmt.Println("invalid decoder limits", *maxTokensPtr, *maxNestingPtr)
+		os.Exit(1)
+	}
+	if *maxImportsPtr < 0 || *maxDeclsPtr < 0 || *maxExpansionPtr < 0 || *maxFileSizePtr < 0 {
+		fmt.Println("invalid parser limits", *maxImportsPtr, *maxDeclsPtr, *maxExpansionPtr, *maxFileSizePtr)
+		os.Exit(1)
+	}
+	if _, ok := xgen.CommentStyles[*commentStylePtr]; *commentStylePtr != "" && !ok {
+		fmt.Println("unsupport comment style", *commentStylePtr)
+		os.Exit(1)
+	}
+	if *commentWidthPtr < 0 {
+		fmt.Println("invalid comment width", *commentWidthPtr)
+		os.Exit(1)
+	}
+	Cfg.ChoiceEnums = *choiceEnumsPtr
+	Cfg.ChoiceTag = *choiceTagPtr
+	Cfg.JSONValue = *jsonValuePtr
+	Cfg.RustDecimal = *rustDecimalPtr
+	Cfg.RustChrono = *rustChronoPtr
+	Cfg.RustError = *rustErrorTypePtr
+	Cfg.ValidateTags = *validateTagsPtr
+	Cfg.SQLMethods = *sqlMethodsPtr
+	Cfg.ProjectVer = *projectVersionPtr
+	Cfg.NPMPackage = *npmPackagePtr
+	Cfg.JSONSchemas = *jsonSchemasPtr
+	Cfg.CommentStyle = *commentStylePtr
+	Cfg.CommentWidth = *commentWidthPtr
+	Cfg.AnyType = *anyTypePtr
+	Cfg.Charset = *charsetPtr
+	Cfg.NonStrict = *nonStrictPtr
+	Cfg.MaxTokens = *maxTokensPtr
+	Cfg.MaxNesting = *maxNestingPtr
+	Cfg.MaxImports = *maxImportsPtr
+	Cfg.MaxDecls = *maxDeclsPtr
+	Cfg.MaxExpansion = *maxExpansionPtr
+	Cfg.MaxFileSize = *maxFileSizePtr
+	Cfg.RemoteDir = *remoteDirPtr
+	Cfg.Offline = *offlinePtr
+	Cfg.TestVectors = *testVectorsPtr
+	Cfg.Normalize = *normalizePtr
+	Cfg.Provenance = *provenancePtr
+	Cfg.Timestamp = *timestampPtr
+	Cfg.RootWrappers = *rootWrappersPtr
+	Cfg.Constants = *constantsPtr
+	Cfg.SymbolMap = *symbolMapPtr
+	Cfg.SizeReport = *sizeReportPtr
+	Cfg.PluralNames = *pluralNamesPtr
+	Cfg.DocLang = *docLangPtr
+	Cfg.Accessors = *accessorsPtr
+	Cfg.PatchTypes = *patchTypesPtr
+	Cfg.Visitor = *visitorPtr
+	Cfg.Equality = *equalityPtr
+	Cfg.PrettyXML = *prettyXMLPtr
+	Cfg.Events = *eventsPtr
+	Cfg.PruneUnused = *pruneUnusedPtr
+	Cfg.TypeAliases = *typeAliasesPtr
+	Cfg.Versioned = *versionedPtr
+	Cfg.GroupByMsg = *groupByMsgPtr
+	Cfg.Mixins = *mixinsPtr
 	return &Cfg
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "completion" {
+		if len(os.Args) != 3 {
+			fmt.Printf("must specify the shell of completion (%s)\r\n", strings.Join(completionShells, "/"))
+			os.Exit(1)
+		}
+		script, err := genCompletion(os.Args[2])
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Print(script)
+		return
+	}
+	if len(os.Args) > 1 && (os.Args[1] == "dump" || os.Args[1] == "merge" || os.Args[1] == "mapping" || os.Args[1] == "lsp" || os.Args[1] == "verify") {
+		run := runDump
+		switch os.Args[1] {
+		case "merge":
+			run = runMerge
+		case "mapping":
+			run = runMapping
+		case "lsp":
+			run = runLSP
+		case "verify":
+			run = runVerify
+		}
+		if err := run(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
 	cfg := parseFlags()
 	files, err := xgen.GetFileList(cfg.I)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
-	for _, file := range files {
-		if err = xgen.NewParser(&xgen.Options{
+	progress := newProgressReporter(os.Stderr)
+	var events func(xgen.Event)
+	if cfg.Events {
+		progress, events = nil, newEventReporter(os.Stderr)
+	}
+	if err = xgen.ParseFiles(files, func(file string) *xgen.Options {
+		return &xgen.Options{
 			FilePath:            file,
 			InputDir:            cfg.I,
 			OutputDir:           cfg.O,
 			Lang:                cfg.Lang,
+			Langs:               cfg.Langs,
 			Package:             cfg.Pkg,
 			IncludeMap:          make(map[string]bool),
 			LocalNameNSMap:      make(map[string]string),
@@ -119,10 +856,76 @@ func main() {
 			ParseFileMap:        make(map[string][]interface{}),
 			ProtoTree:           make([]interface{}, 0),
 			RemoteSchema:        make(map[string][]byte),
-		}).Parse(); err != nil {
-			fmt.Printf("process error on %s: %s\r\n", file, err.Error())
-			os.Exit(1)
+			Validation:          cfg.Validation,
+			TestVectors:         cfg.TestVectors,
+			Normalize:           cfg.Normalize,
+			ValidationMaxDepth:  cfg.MaxDepth,
+			ValidationTracing:   cfg.Tracing,
+			Lenient:             cfg.Lenient,
+			Provenance:          cfg.Provenance,
+			ProvenanceTimestamp: cfg.Timestamp,
+			RootWrappers:        cfg.RootWrappers,
+			Constants:           cfg.Constants,
+			SymbolMap:           cfg.SymbolMap,
+			SizeReport:          cfg.SizeReport,
+			PluralNames:         cfg.PluralNames,
+			PluralOverrides:     cfg.Plurals,
+			OptionalOverrides:   cfg.Optionals,
+			Deprecations:        cfg.Deprecations,
+			Customizations:      cfg.Customs,
+			ExternalTypes:       cfg.Externals,
+			DocLang:             cfg.DocLang,
+			Accessors:           cfg.Accessors,
+			PatchTypes:          cfg.PatchTypes,
+			Visitor:             cfg.Visitor,
+			Equality:            cfg.Equality,
+			PrettyXML:           cfg.PrettyXML,
+			PruneUnused:         cfg.PruneUnused,
+			TypeAliases:         cfg.TypeAliases,
+			VersionedPackages:   cfg.Versioned,
+			GroupByMessage:      cfg.GroupByMsg,
+			CommentStyle:        cfg.CommentStyle,
+			CommentWidth:        cfg.CommentWidth,
+			AnyTypeFallback:     cfg.AnyType,
+			Charset:             cfg.Charset,
+			Entities:            cfg.Entities,
+			NonStrict:           cfg.NonStrict,
+			MaxTokens:           cfg.MaxTokens,
+			MaxDepth:            cfg.MaxNesting,
+			MaxImportDepth:      cfg.MaxImports,
+			MaxDeclarations:     cfg.MaxDecls,
+			MaxEntityExpansion:  cfg.MaxExpansion,
+			MaxFileSize:         cfg.MaxFileSize,
+			RemoteSchemaDir:     cfg.RemoteDir,
+			Offline:             cfg.Offline,
+			Mixins:              cfg.Mixins,
+			BooleanForm:         cfg.BooleanForm,
+			DecimalForm:         cfg.DecimalForm,
+			PatternFallback:     cfg.PatternMode,
+			AssertFallback:      cfg.AssertMode,
+			RenameCase:          cfg.RenameCase,
+			ChoiceEnums:         cfg.ChoiceEnums,
+			ChoiceRepr:          cfg.ChoiceRepr,
+			ChoiceTag:           cfg.ChoiceTag,
+			JSONValue:           cfg.JSONValue,
+			RustDecimal:         cfg.RustDecimal,
+			RustChrono:          cfg.RustChrono,
+			RustErrorType:       cfg.RustError,
+			RustUses:            cfg.RustUses,
+			RustDerives:         cfg.RustDerives,
+			RustModules:         cfg.RustModules,
+			ValidateTags:        cfg.ValidateTags,
+			SQLMethods:          cfg.SQLMethods,
+			JavaProject:         cfg.JavaProject,
+			ProjectVersion:      cfg.ProjectVer,
+			NPMPackage:          cfg.NPMPackage,
+			JSONSchemas:         cfg.JSONSchemas,
+			NamespacePrefixes:   cfg.NSPrefixes,
+			Events:              events,
 		}
+	}, progress); err != nil {
+		fmt.Printf("%s\r\n", err.Error())
+		os.Exit(1)
 	}
 	fmt.Println("done")
 }
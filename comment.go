@@ -0,0 +1,137 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CommentStyle defines how the comments of the declarations are rendered.
+// Each line of the comment is rendered with the prefix, between the begin
+// and the end lines if they aren't empty.
+type CommentStyle struct {
+	Begin  string
+	Prefix string
+	End    string
+}
+
+// CommentStyles holds the comment styles by name, which are selected by the
+// comment style option. The default style is "slash", and more styles can be
+// added before parsing.
+var CommentStyles = map[string]CommentStyle{
+	"slash":        {Prefix: "// "},
+	"triple-slash": {Prefix: "/// "},
+	"block":        {Begin: "/**", Prefix: " * ", End: " */"},
+	"hash":         {Prefix: "# "},
+	"docstring":    {Begin: `"""`, End: `"""`},
+}
+
+// defaultCommentStyle is the comment style used if the comment style option
+// is empty.
+const defaultCommentStyle = "slash"
+
+// CommentStyleNames returns the sorted names of the comment styles.
+func CommentStyleNames() []string {
+	names := make([]string, 0, len(CommentStyles))
+	for name := range CommentStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkCommentStyle returns an error if the comment style by given name
+// isn't defined.
+func checkCommentStyle(name string) error {
+	if _, ok := CommentStyles[name]; name != "" && !ok {
+		return fmt.Errorf("unsupport comment style %s, expected one of %s", name, strings.Join(CommentStyleNames(), " "))
+	}
+	return nil
+}
+
+// genComment returns the comment of the declaration by given name and
+// documentation, rendered by the comment style of the generator. If the
+// comment width is set, the documentation is wrapped at the width and the
+// paragraphs separated by blank lines are preserved.
+func (gen *CodeGenerator) genComment(name, doc string) string {
+	styleName := gen.CommentStyle
+	if styleName == "" {
+		styleName = defaultCommentStyle
+	}
+	style := CommentStyles[styleName]
+	text := name + " ..."
+	if doc != "" {
+		text = name + " is " + doc
+	}
+	var lines []string
+	if gen.CommentWidth > 0 {
+		lines = wrapCommentText(text, gen.CommentWidth-utf8.RuneCountInString(style.Prefix))
+	} else {
+		lines = strings.Split(strings.Replace(text, "\t", "", -1), "\n")
+	}
+	comment := "\r\n"
+	if style.Begin != "" {
+		comment += style.Begin + "\r\n"
+	}
+	for _, line := range lines {
+		if line == "" {
+			comment += strings.TrimRight(style.Prefix, " ") + "\r\n"
+			continue
+		}
+		comment += style.Prefix + line + "\r\n"
+	}
+	if style.End != "" {
+		comment += style.End + "\r\n"
+	}
+	return comment
+}
+
+// wrapCommentText splits the text into the paragraphs separated by blank
+// lines, and wraps the words of each paragraph into lines no longer than the
+// width, except the words longer than the width. The paragraphs are
+// separated by an empty line.
+func wrapCommentText(text string, width int) (lines []string) {
+	var paragraphs [][]string
+	var words []string
+	for _, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			if len(words) > 0 {
+				paragraphs = append(paragraphs, words)
+				words = nil
+			}
+			continue
+		}
+		words = append(words, fields...)
+	}
+	if len(words) > 0 {
+		paragraphs = append(paragraphs, words)
+	}
+	for i, paragraph := range paragraphs {
+		if i > 0 {
+			lines = append(lines, "")
+		}
+		var line string
+		for _, word := range paragraph {
+			if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			if line != "" {
+				line += " "
+			}
+			line += word
+		}
+		lines = append(lines, line)
+	}
+	return
+}
@@ -0,0 +1,119 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// schemaConstant holds the name, the description and the value of a
+// constant of the schema metadata.
+type schemaConstant struct {
+	name, doc, value string
+}
+
+// getSchemaConstants returns the constants of the schema metadata, which are
+// the target namespace, the schema version, the message identifier of the
+// versioned target namespace and the name of each root element, and the
+// names of the root elements.
+func (gen *CodeGenerator) getSchemaConstants() (constants []schemaConstant, rootElements []string) {
+	if gen.TargetNamespace != "" {
+		constants = append(constants, schemaConstant{"TargetNamespace", "the target namespace of the schema.", gen.TargetNamespace})
+	}
+	if gen.SchemaVersion != "" {
+		constants = append(constants, schemaConstant{"SchemaVersion", "the version of the schema.", gen.SchemaVersion})
+	}
+	if message, version, ok := getNamespaceVersion(gen.TargetNamespace); ok {
+		constants = append(constants, schemaConstant{"MessageIdentifier", "the message identifier of the target namespace.", message + "." + version})
+	}
+	for _, ele := range gen.ProtoTree {
+		if v, ok := ele.(*Element); ok && isRootElement(v, gen.ProtoTree) {
+			constants = append(constants, schemaConstant{genGoFieldName(v.Name, false) + "ElementName", fmt.Sprintf("the name of the %s root element.", v.Name), v.Name})
+			rootElements = append(rootElements, v.Name)
+		}
+	}
+	return
+}
+
+// GenConstants writes the constants of the schema metadata, so the
+// application code can reference the target namespace, the schema version,
+// the message identifier and the root element names without hardcoding
+// them.
+func (gen *CodeGenerator) GenConstants() error {
+	constants, rootElements := gen.getSchemaConstants()
+	var quoted []string
+	for _, name := range rootElements {
+		quoted = append(quoted, strconv.Quote(name))
+	}
+	var code string
+	switch gen.Lang {
+	case "Go":
+		for _, c := range constants {
+			code += fmt.Sprintf("%sconst %s = %s\n", gen.genComment(c.name, c.doc), c.name, strconv.Quote(c.value))
+		}
+		code += fmt.Sprintf("%svar RootElementNames = []string{%s}\n", gen.genComment("RootElementNames", "the list of the names of the root elements of the schema."), strings.Join(quoted, ", "))
+		packageName := gen.Package
+		if packageName == "" {
+			packageName = "schema"
+		}
+		source, err := format.Source([]byte(fmt.Sprintf("%s\n\npackage %s\n%s", gen.fileHeader(), packageName, code)))
+		if err != nil {
+			return err
+		}
+		return gen.WriteFile(gen.FileWithExtension(".constants.go"), source)
+	case "Rust":
+		for _, c := range constants {
+			name := strings.ToUpper(ToSnakeCase(c.name))
+			code += fmt.Sprintf("%spub const %s: &str = \"%s\";\n", gen.genComment(name, c.doc), name, escapeRustString(c.value))
+		}
+		var names []string
+		for _, name := range rootElements {
+			names = append(names, fmt.Sprintf("\"%s\"", escapeRustString(name)))
+		}
+		code += fmt.Sprintf("%spub const ROOT_ELEMENT_NAMES: &[&str] = &[%s];\n", gen.genComment("ROOT_ELEMENT_NAMES", "the list of the names of the root elements of the schema."), strings.Join(names, ", "))
+		return gen.WriteFile(gen.FileWithExtension(".constants.rs"), []byte(fmt.Sprintf("%s\n%s", gen.fileHeader(), code)))
+	case "TypeScript":
+		for _, c := range constants {
+			name := strings.ToUpper(ToSnakeCase(c.name))
+			code += fmt.Sprintf("%sexport const %s = %s;\n", gen.genComment(name, c.doc), name, strconv.Quote(c.value))
+		}
+		code += fmt.Sprintf("%sexport const ROOT_ELEMENT_NAMES: readonly string[] = [%s];\n", gen.genComment("ROOT_ELEMENT_NAMES", "the list of the names of the root elements of the schema."), strings.Join(quoted, ", "))
+		return gen.WriteFile(gen.FileWithExtension(".constants.ts"), []byte(fmt.Sprintf("%s\n%s", gen.fileHeader(), code)))
+	case "C":
+		for _, c := range constants {
+			name := strings.ToUpper(ToSnakeCase(c.name))
+			code += fmt.Sprintf("%s#define %s %s\n", gen.genComment(name, c.doc), name, strconv.Quote(c.value))
+		}
+		code += fmt.Sprintf("%s#define ROOT_ELEMENT_NAMES { %s }\n", gen.genComment("ROOT_ELEMENT_NAMES", "the list of the names of the root elements of the schema."), strings.Join(quoted, ", "))
+		return gen.WriteFile(gen.FileWithExtension(".constants.h"), []byte(fmt.Sprintf("%s\n%s", gen.fileHeader(), code)))
+	case "Java":
+		for _, c := range constants {
+			name := strings.ToUpper(ToSnakeCase(c.name))
+			code += fmt.Sprintf("%spublic static final String %s = %s;\n", gen.genComment(name, c.doc), name, strconv.Quote(c.value))
+		}
+		code += fmt.Sprintf("%spublic static final List<String> ROOT_ELEMENT_NAMES = Collections.unmodifiableList(Arrays.asList(%s));\n", gen.genComment("ROOT_ELEMENT_NAMES", "the list of the names of the root elements of the schema."), strings.Join(quoted, ", "))
+		var members string
+		for _, line := range strings.SplitAfter(code, "\n") {
+			if strings.TrimSpace(line) != "" {
+				line = "\t" + line
+			}
+			members += line
+		}
+		packageName, file := getJavaPackage(gen.Package), gen.FileWithExtension(".constants.java")
+		if gen.JavaProject != "" {
+			file = filepath.Join(filepath.Dir(gen.File), "SchemaConstants.java")
+		}
+		return gen.WriteFile(file, []byte(fmt.Sprintf("%s\n\npackage %s;\n\nimport java.util.Arrays;\nimport java.util.Collections;\nimport java.util.List;\n%spublic final class SchemaConstants {\n%s\n\tprivate SchemaConstants() {\n\t}\n}\n", gen.fileHeader(), packageName, gen.genComment("SchemaConstants", "the metadata of the schema."), members)))
+	}
+	return nil
+}
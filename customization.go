@@ -0,0 +1,292 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// AppinfoRustDerive is the keyword of the appinfo content adding the
+	// comma-separated derives to the generated Rust type, such as
+	// "rust-derive: Hash, Eq".
+	AppinfoRustDerive = "rust-derive"
+	// AppinfoRustAttr is the keyword of the appinfo content adding the
+	// attribute to the generated Rust type or field, such as
+	// "rust-attr: #[serde(deny_unknown_fields)]".
+	AppinfoRustAttr = "rust-attr"
+	// AppinfoGoTag is the keyword of the appinfo content adding the struct
+	// tags to the generated Go field, such as `go-tag: json:"id"`, which
+	// replace the generated tags of the same keys.
+	AppinfoGoTag = "go-tag"
+)
+
+var (
+	// goTagPattern matches the key:"value" pairs of the Go struct tags.
+	goTagPattern = regexp.MustCompile(`[^\s:"]+:"(?:\\.|[^"\\])*"`)
+	// rustAttrPattern matches the outer attributes of Rust.
+	rustAttrPattern = regexp.MustCompile(`^#\[.+\]$`)
+)
+
+// Customization holds the language-specific additions to the generated type
+// or field, given by the appinfo annotations of the declaration or by the
+// customizations option: the derives and the attributes of the Rust types,
+// the attributes of the Rust fields and the struct tags of the Go fields.
+type Customization struct {
+	RustDerives []string
+	RustAttrs   []string
+	GoTags      []string
+}
+
+// parseAppinfoCustomization returns the keyword and the value of the content
+// of the appinfo element and true if the content is a customization, that is
+// one of the keywords followed by a colon and the value.
+func parseAppinfoCustomization(content string) (string, string, bool) {
+	idx := strings.Index(content, ":")
+	if idx == -1 {
+		return "", "", false
+	}
+	keyword := strings.ToLower(strings.TrimSpace(content[:idx]))
+	switch keyword {
+	case AppinfoRustDerive, AppinfoRustAttr, AppinfoGoTag:
+		return keyword, strings.TrimSpace(content[idx+1:]), true
+	}
+	return "", "", false
+}
+
+// add adds the value of the customization by given keyword, or returns an
+// error if the value isn't valid in the language.
+func (c *Customization) add(keyword, value string) error {
+	switch keyword {
+	case AppinfoRustDerive:
+		var derives []string
+		for _, derive := range strings.Split(value, ",") {
+			if derive = strings.TrimSpace(derive); derive == "" {
+				continue
+			}
+			if !rustTypePathPattern.MatchString(derive) {
+				return fmt.Errorf("invalid Rust derive %s", derive)
+			}
+			derives = append(derives, derive)
+		}
+		if len(derives) == 0 {
+			return fmt.Errorf("missing Rust derive")
+		}
+		for _, derive := range derives {
+			if !inStringSlice(derive, c.RustDerives) {
+				c.RustDerives = append(c.RustDerives, derive)
+			}
+		}
+	case AppinfoRustAttr:
+		if !rustAttrPattern.MatchString(value) {
+			return fmt.Errorf("invalid Rust attribute %s, expected #[...]", value)
+		}
+		c.RustAttrs = append(c.RustAttrs, value)
+	case AppinfoGoTag:
+		tags := goTagPattern.FindAllString(value, -1)
+		if len(tags) == 0 || strings.TrimSpace(goTagPattern.ReplaceAllString(value, "")) != "" {
+			return fmt.Errorf("invalid Go struct tag %s, expected key:\"value\" pairs", value)
+		}
+		for _, tag := range tags {
+			c.GoTags = setGoTag(c.GoTags, tag)
+		}
+	}
+	return nil
+}
+
+// parse adds the customizations of the lines of the content, the lines
+// which aren't customizations are ignored.
+func (c *Customization) parse(content string) error {
+	for _, line := range strings.Split(content, "\n") {
+		if keyword, value, ok := parseAppinfoCustomization(line); ok {
+			if err := c.add(keyword, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setGoTag returns the struct tags with the key:"value" pair of the tag,
+// replacing the pair of the same key.
+func setGoTag(tags []string, tag string) []string {
+	key := tag[:strings.Index(tag, ":")]
+	for i, t := range tags {
+		if t[:strings.Index(t, ":")] == key {
+			tags[i] = tag
+			return tags
+		}
+	}
+	return append(tags, tag)
+}
+
+// ReadCustomizations reads the customizations file of the given name into
+// the customizations option, which adds the language-specific derives,
+// attributes and struct tags to the generated types and fields regardless of
+// the annotations of the schema. Each line of the file holds the name, an
+// equals sign and the customization as the content of the appinfo element,
+// such as "Dbtr=rust-derive: Hash", the blank lines and the lines starting
+// with # are ignored. The name is the name of the type, or the name of the
+// element or attribute qualified by the name of the complex type, the group
+// or the attribute group declaring it, such as Dbtr.Nm, or by * for any
+// type.
+func ReadCustomizations(name string) (map[string][]string, error) {
+	customizations := map[string][]string{}
+	if name == "" {
+		return customizations, nil
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		idx := strings.Index(text, "=")
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid customization at %s:%d, expected <name>=<keyword>: <value>", name, line)
+		}
+		declName, content := strings.TrimSpace(text[:idx]), strings.TrimSpace(text[idx+1:])
+		keyword, value, ok := parseAppinfoCustomization(content)
+		if !ok {
+			return nil, fmt.Errorf("invalid customization at %s:%d, expected the %s, %s or %s keyword", name, line, AppinfoRustDerive, AppinfoRustAttr, AppinfoGoTag)
+		}
+		if err = new(Customization).add(keyword, value); err != nil {
+			return nil, fmt.Errorf("invalid customization at %s:%d: %v", name, line, err)
+		}
+		customizations[declName] = append(customizations[declName], content)
+	}
+	return customizations, scanner.Err()
+}
+
+// formatCustomizations returns the customizations option as the
+// comma-separated name=customization pairs ordered by name.
+func formatCustomizations(customizations map[string][]string) string {
+	var names, pairs []string
+	for name := range customizations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, content := range customizations[name] {
+			pairs = append(pairs, name+"="+content)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// resolveCustomizations adds the customizations of the option to the types
+// and their members, after the ones of the annotations. The customizations
+// of the members of any type are added before the ones qualified by the
+// name of the declaring type, whose struct tags take precedence.
+func (opt *Options) resolveCustomizations() {
+	if len(opt.Customizations) == 0 {
+		return
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			opt.customize(&v.Custom, v.Name)
+		case *Element:
+			opt.customize(&v.Custom, v.Name)
+		case *ComplexType:
+			opt.customize(&v.Custom, v.Name)
+			for i := range v.Elements {
+				opt.customize(&v.Elements[i].Custom, "*."+v.Elements[i].Name, v.Name+"."+v.Elements[i].Name)
+			}
+			for i := range v.Attributes {
+				opt.customize(&v.Attributes[i].Custom, "*."+v.Attributes[i].Name, v.Name+"."+v.Attributes[i].Name)
+			}
+		case *Group:
+			for i := range v.Elements {
+				opt.customize(&v.Elements[i].Custom, "*."+v.Elements[i].Name, v.Name+"."+v.Elements[i].Name)
+			}
+		case *AttributeGroup:
+			for i := range v.Attributes {
+				opt.customize(&v.Attributes[i].Custom, "*."+v.Attributes[i].Name, v.Name+"."+v.Attributes[i].Name)
+			}
+		}
+	}
+}
+
+// customize adds the customizations of the option by the given names, the
+// invalid ones are skipped with a warning.
+func (opt *Options) customize(custom *Customization, names ...string) {
+	for _, name := range names {
+		for _, content := range opt.Customizations[name] {
+			if err := custom.parse(content); err != nil {
+				opt.warn(fmt.Sprintf("ignored the customization of %s: %v", name, err))
+			}
+		}
+	}
+}
+
+// genGoCustomTags returns the field of Go code with the struct tags of the
+// customization, which replace the tags of the same keys.
+func genGoCustomTags(field string, tags []string) string {
+	if len(tags) == 0 {
+		return field
+	}
+	line := strings.TrimSuffix(field, "\n")
+	var pairs []string
+	if strings.HasSuffix(line, "`") {
+		start := strings.LastIndex(line[:len(line)-1], "`")
+		pairs = goTagPattern.FindAllString(line[start+1:len(line)-1], -1)
+		line = line[:start]
+	} else {
+		line += "\t"
+	}
+	for _, tag := range tags {
+		pairs = setGoTag(pairs, tag)
+	}
+	return line + "`" + strings.Join(pairs, " ") + "`\n"
+}
+
+// genRustCustomAttrs generate the attributes of the customization of the
+// field for Rust code.
+func genRustCustomAttrs(custom Customization) (attrs string) {
+	for _, attr := range custom.RustAttrs {
+		attrs += "\t" + attr + "\n"
+	}
+	return
+}
+
+// genRustCustomType returns the code of the type for Rust code with the
+// derives of the customization added to the ones of the type, and the
+// attributes of the customization following them.
+func genRustCustomType(code string, custom Customization) string {
+	if len(custom.RustDerives) == 0 && len(custom.RustAttrs) == 0 {
+		return code
+	}
+	start := strings.Index(code, "#[derive(")
+	if start == -1 {
+		return code
+	}
+	end := start + strings.Index(code[start:], ")]\n")
+	derives := strings.Split(code[start+len("#[derive("):end], ", ")
+	for _, derive := range custom.RustDerives {
+		if !inStringSlice(derive, derives) {
+			derives = append(derives, derive)
+		}
+	}
+	var attrs string
+	for _, attr := range custom.RustAttrs {
+		attrs += attr + "\n"
+	}
+	return code[:start] + "#[derive(" + strings.Join(derives, ", ") + ")]\n" + attrs + code[end+len(")]\n"):]
+}
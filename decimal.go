@@ -0,0 +1,153 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Decimal forms of the code generator. With the native form xs:decimal is
+// generated as the floating-point type of the language. With the canonical
+// form it's generated as a decimal type, which is parsed from and serialized
+// as the decimal lexical form regardless of the locale, without exponent and
+// with the fewest fraction digits representing the value. With the fixed
+// scale form the decimals restricted by the fractionDigits facet are
+// serialized with exactly that number of fraction digits.
+const (
+	DecimalFormNative     = ""
+	DecimalFormCanonical  = "canonical"
+	DecimalFormFixedScale = "fixed-scale"
+)
+
+// decimalTypes holds the name of the generated decimal type by language, the
+// decimal types with fixed scale are suffixed by Scale and the scale.
+var decimalTypes = map[string]string{
+	"Go":   "XSDDecimal",
+	"Rust": "XsdDecimal",
+}
+
+// rustDecimalType is the type of the rust_decimal crate xs:decimal is mapped
+// to for Rust code if the Rust decimal option is set.
+const rustDecimalType = "Decimal"
+
+// rustDecimalTypePattern matches the Decimal type of the rust_decimal crate
+// in the Rust code.
+var rustDecimalTypePattern = regexp.MustCompile(`\b` + rustDecimalType + `\b`)
+
+// rustDecimalLexicalPattern matches the lexical form of xs:decimal.
+var rustDecimalLexicalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// checkDecimalForm returns an error if the decimal form isn't supported.
+func checkDecimalForm(form string) error {
+	switch form {
+	case DecimalFormNative, DecimalFormCanonical, DecimalFormFixedScale:
+		return nil
+	}
+	return fmt.Errorf("unsupport decimal form %s, expected %s or %s", form, DecimalFormCanonical, DecimalFormFixedScale)
+}
+
+// getDecimalType returns the generated decimal type of the language, which
+// xs:decimal is mapped to if the decimal form option is set. The Rust decimal
+// option takes precedence over the decimal form for Rust code.
+func (opt *Options) getDecimalType() (string, bool) {
+	if opt.RustDecimal && opt.Lang == "Rust" {
+		return rustDecimalType, true
+	}
+	if opt.DecimalForm == DecimalFormNative {
+		return "", false
+	}
+	decimalType, ok := decimalTypes[opt.Lang]
+	return decimalType, ok
+}
+
+// isDecimalType returns true if the type by given name is a generated
+// decimal type of any language.
+func isDecimalType(name string) bool {
+	for _, decimalType := range decimalTypes {
+		if name == decimalType || strings.HasPrefix(name, decimalType+"Scale") {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveDecimalScales maps the simple types, elements and attributes of the
+// decimal type restricted by the fractionDigits facet to the decimal type
+// with that fixed scale in the fixed scale form.
+func (opt *Options) resolveDecimalScales() {
+	decimalType, ok := opt.getDecimalType()
+	if !ok || decimalType == rustDecimalType || opt.DecimalForm != DecimalFormFixedScale {
+		return
+	}
+	scale := func(typeName *string, restriction Restriction) {
+		if *typeName != decimalType || restriction.FractionDigits == 0 {
+			return
+		}
+		*typeName = fmt.Sprintf("%sScale%d", decimalType, restriction.FractionDigits)
+		opt.registerBuiltInType(*typeName)
+	}
+	elements := func(elements []Element) {
+		for i := range elements {
+			scale(&elements[i].Type, elements[i].Restriction)
+		}
+	}
+	attributes := func(attributes []Attribute) {
+		for i := range attributes {
+			scale(&attributes[i].Type, attributes[i].Restriction)
+		}
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			scale(&v.Base, v.Restriction)
+		case *ComplexType:
+			elements(v.Elements)
+			attributes(v.Attributes)
+		case *Group:
+			elements(v.Elements)
+		case *AttributeGroup:
+			attributes(v.Attributes)
+		case *Element:
+			scale(&v.Type, v.Restriction)
+		case *Attribute:
+			scale(&v.Type, v.Restriction)
+		}
+	}
+}
+
+// getDecimalScales returns the scales of the decimal types by given name
+// used in the code, the scale of the decimal type without fixed scale is -1.
+func getDecimalScales(decimalType, code string) (scales []int) {
+	seen := map[int]bool{}
+	for _, match := range regexp.MustCompile(`\b`+decimalType+`(Scale(\d+))?\b`).FindAllStringSubmatch(code, -1) {
+		scale := -1
+		if match[2] != "" {
+			scale, _ = strconv.Atoi(match[2])
+		}
+		if !seen[scale] {
+			seen[scale] = true
+			scales = append(scales, scale)
+		}
+	}
+	sort.Ints(scales)
+	return
+}
+
+// genDecimalTypeName returns the name of the decimal type with the scale, or
+// the decimal type without fixed scale if the scale is negative.
+func genDecimalTypeName(decimalType string, scale int) string {
+	if scale < 0 {
+		return decimalType
+	}
+	return fmt.Sprintf("%sScale%d", decimalType, scale)
+}
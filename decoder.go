@@ -0,0 +1,129 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// newDecoder returns the decoder of the XML document from the source with the
+// decoder settings of the options. The charset of the options decodes the
+// document regardless of its XML declaration, and the charset reader of the
+// options decodes the documents declaring other charsets than UTF-8 instead
+// of the charsets known by their labels. The entities of the options are
+// the custom entities the document may reference, and the document isn't
+// required to be well-formed if the options are non-strict.
+func (opt *Options) newDecoder(source io.Reader) (*xml.Decoder, error) {
+	charsetReader := opt.CharsetReader
+	if charsetReader == nil {
+		charsetReader = charset.NewReaderLabel
+	}
+	if opt.Charset != "" {
+		reader, err := charset.NewReaderLabel(opt.Charset, source)
+		if err != nil {
+			return nil, err
+		}
+		// The document is decoded already, whatever charset it declares.
+		source, charsetReader = reader, func(label string, input io.Reader) (io.Reader, error) {
+			return input, nil
+		}
+	}
+	decoder := xml.NewDecoder(source)
+	decoder.CharsetReader = charsetReader
+	decoder.Entity = opt.Entities
+	decoder.Strict = !opt.NonStrict
+	return decoder, nil
+}
+
+// lineReader reads the XML document from the reader, recording the offsets
+// of its line breaks, so the lines of the offsets of the decoder are known.
+type lineReader struct {
+	reader   io.Reader
+	offset   int64
+	newlines []int64
+}
+
+// Read reads the document into given buffer and records its line breaks.
+func (r *lineReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			r.newlines = append(r.newlines, r.offset+int64(i))
+		}
+	}
+	r.offset += int64(n)
+	return n, err
+}
+
+// line returns the line of the byte before the offset, the last byte of the
+// token read by the decoder up to the offset.
+func (r *lineReader) line(offset int64) int {
+	return sort.Search(len(r.newlines), func(i int) bool { return r.newlines[i] >= offset-1 }) + 1
+}
+
+// decoderLimits counts the tokens and the nesting depth of the elements of
+// the XML document, which are limited by the options, so the documents from
+// the untrusted sources can't exhaust the resources of the parser.
+type decoderLimits struct {
+	maxTokens, maxDepth int
+	tokens, depth       int
+}
+
+// check counts the token and returns the LimitError if the document exceeds
+// the limits.
+func (limits *decoderLimits) check(token xml.Token) error {
+	limits.tokens++
+	if limits.maxTokens > 0 && limits.tokens > limits.maxTokens {
+		return &LimitError{Limit: LimitTokens, Max: int64(limits.maxTokens)}
+	}
+	switch token.(type) {
+	case xml.StartElement:
+		limits.depth++
+		if limits.maxDepth > 0 && limits.depth > limits.maxDepth {
+			return &LimitError{Limit: LimitDepth, Max: int64(limits.maxDepth)}
+		}
+	case xml.EndElement:
+		limits.depth--
+	}
+	return nil
+}
+
+// ParseEntities parses the custom entities of the decoder, in the form
+// name=value separated by commas.
+func ParseEntities(value string) (map[string]string, error) {
+	entities := map[string]string{}
+	if value == "" {
+		return entities, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid entity %s, expected <name>=<value>", pair)
+		}
+		entities[parts[0]] = parts[1]
+	}
+	return entities, nil
+}
+
+// formatEntities returns the entities option as the comma-separated
+// name=value pairs ordered by name.
+func formatEntities(entities map[string]string) string {
+	var pairs []string
+	for name, value := range entities {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
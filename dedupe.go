@@ -0,0 +1,60 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/json"
+
+// getSharedAnonymousType returns the generated name of the anonymous complex
+// type generated before with the same structure as the given anonymous type,
+// which is then generated as an alias of it. An empty name is returned and
+// the type is recorded by its generated name for the later ones if no such
+// type has been generated.
+func (gen *CodeGenerator) getSharedAnonymousType(v *ComplexType, typeName string) string {
+	key := gen.anonymousTypeKey(v)
+	if key == "" {
+		return ""
+	}
+	if gen.anonymousTypes == nil {
+		gen.anonymousTypes = map[string]string{}
+	}
+	if name, ok := gen.anonymousTypes[key]; ok {
+		return name
+	}
+	gen.anonymousTypes[key] = typeName
+	return ""
+}
+
+// anonymousTypeKey returns the structural key of the anonymous complex type,
+// which covers the members of the type but not its name and documentation.
+// Named types and the types with identity constraints, which are checked by
+// the type name, return an empty key and are never shared.
+func (gen *CodeGenerator) anonymousTypeKey(v *ComplexType) string {
+	if !v.Anonymous || len(getUniqueConstraints(v.Name, gen.ProtoTree)) > 0 {
+		return ""
+	}
+	d := dumpComplexType(v)
+	d.Name = ""
+	clearDeclarationDoc(&d)
+	key, err := json.Marshal(d)
+	if err != nil {
+		return ""
+	}
+	return string(key)
+}
+
+// clearDeclarationDoc removes the documentation of the declaration and its
+// nested declarations.
+func clearDeclarationDoc(d *Declaration) {
+	d.Doc = ""
+	for _, nested := range [][]Declaration{d.Elements, d.Attributes, d.Groups, d.AttributeGroups} {
+		for i := range nested {
+			clearDeclarationDoc(&nested[i])
+		}
+	}
+}
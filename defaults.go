@@ -0,0 +1,151 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// rustValueFieldRegexp matches the fields of the Rust structs which have the
+// doc comment of the default or the fixed value declared by the schema.
+var rustValueFieldRegexp = regexp.MustCompile(`(?m)^\t/// (Defaults|Fixed) to ("(?:[^"\\]|\\.)*") by the schema\.\n(?:\t(?:///|#\[).*\n)*\tpub ((?:r#)?\w+): (.+),$`)
+
+// genRustStringLiteral generate the string literal of the value for Rust
+// code.
+func genRustStringLiteral(value string) string {
+	return "\"" + strings.NewReplacer("\n", "\\n", "\r", "\\r", "\t", "\\t").Replace(escapeRustString(value)) + "\""
+}
+
+// genRustValueDoc generate the doc comment of the field for Rust code, if
+// the schema declares the default or the fixed value of the element or the
+// attribute, which populates the field in the Default impl of the struct.
+func genRustValueDoc(defaultValue, fixed string) string {
+	if fixed != "" {
+		return fmt.Sprintf("\t/// Fixed to %s by the schema.\n", genRustStringLiteral(fixed))
+	}
+	if defaultValue != "" {
+		return fmt.Sprintf("\t/// Defaults to %s by the schema.\n", genRustStringLiteral(defaultValue))
+	}
+	return ""
+}
+
+// trimRustOption returns the type wrapped by Option for Rust code, or the
+// type itself and false if it isn't optional.
+func trimRustOption(fieldType string) (string, bool) {
+	if strings.HasPrefix(fieldType, "Option<") && strings.HasSuffix(fieldType, ">") {
+		return fieldType[len("Option<") : len(fieldType)-1], true
+	}
+	return fieldType, false
+}
+
+// genRustValueLiteral returns the expression of the value of the field type
+// for Rust code by given string literal of the value, or false if the type
+// isn't a built-in type the value converts to. The value of the optional
+// field is wrapped by Some, and the one of the nillable field by
+// Nillable::Value, unless it's the value of a constant.
+func genRustValueLiteral(fieldType, literal string, constant bool) (string, bool) {
+	if inner, ok := trimRustOption(fieldType); ok && !constant {
+		value, ok := genRustValueLiteral(inner, literal, constant)
+		return "Some(" + value + ")", ok
+	}
+	if inner, ok := trimRustNillable(fieldType); ok && !constant {
+		value, ok := genRustValueLiteral(inner, literal, constant)
+		return "Nillable::Value(" + value + ")", ok
+	}
+	if fieldType == "String" {
+		if constant {
+			return literal, true
+		}
+		return literal + ".to_string()", true
+	}
+	value := strings.TrimSpace(strings.Trim(literal, "\""))
+	switch fieldType {
+	case "bool":
+		switch value {
+		case "true", "1":
+			return "true", true
+		case "false", "0":
+			return "false", true
+		}
+	case "i8", "i16", "i32", "i64", "i128", "isize":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10), true
+		}
+	case "u8", "u16", "u32", "u64", "u128", "usize":
+		if n, err := strconv.ParseUint(strings.TrimPrefix(value, "+"), 10, 64); err == nil {
+			return strconv.FormatUint(n, 10), true
+		}
+	case "f32", "f64":
+		switch value {
+		case "INF":
+			return fieldType + "::INFINITY", true
+		case "-INF":
+			return fieldType + "::NEG_INFINITY", true
+		case "NaN":
+			return fieldType + "::NAN", true
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			value = strconv.FormatFloat(f, 'g', -1, 64)
+			if !strings.ContainsAny(value, ".e") {
+				value += ".0"
+			}
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// genRustDefaultImpl generate the Default impl of the struct for Rust code,
+// populating the fields of the default and the fixed values declared by the
+// schema, along with the impl block of the constants of the fixed values,
+// which are named after the fields, such as FIXED_CURRENCY. The other fields
+// are populated by their Default impl. It returns false if none of the
+// values converts to the type of its field, so the Default impl is derived.
+func genRustDefaultImpl(structName, fieldContent string) (string, bool) {
+	values := map[string]string{}
+	var constants string
+	for _, match := range rustValueFieldRegexp.FindAllStringSubmatch(fieldContent, -1) {
+		kind, literal, fieldName, fieldType := match[1], match[2], match[3], match[4]
+		if value, ok := genRustValueLiteral(fieldType, literal, false); ok {
+			values[fieldName] = value
+		}
+		if kind != "Fixed" {
+			continue
+		}
+		constType, _ := trimRustOption(fieldType)
+		constType, _ = trimRustNillable(constType)
+		value, ok := genRustValueLiteral(constType, literal, true)
+		if !ok || constType == "String" {
+			constType, value = "&'static str", literal
+		}
+		constants += fmt.Sprintf("\tpub const FIXED_%s: %s = %s;\n", strings.ToUpper(strings.TrimPrefix(fieldName, "r#")), constType, value)
+	}
+	if len(values) == 0 && constants == "" {
+		return "", false
+	}
+	var code string
+	if constants != "" {
+		code = fmt.Sprintf("\nimpl %s {\n%s}\n", structName, constants)
+	}
+	if len(values) == 0 {
+		return code, false
+	}
+	var fields string
+	for _, match := range rustPublicFieldRegexp.FindAllStringSubmatch(fieldContent, -1) {
+		value, ok := values[match[1]]
+		if !ok {
+			value = "Default::default()"
+		}
+		fields += fmt.Sprintf("\t\t\t%s: %s,\n", match[1], value)
+	}
+	return fmt.Sprintf("\nimpl Default for %s {\n\tfn default() -> Self {\n\t\tSelf {\n%s\t\t}\n\t}\n}\n", structName, fields) + code, true
+}
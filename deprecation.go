@@ -0,0 +1,197 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// AppinfoDeprecated is the content of the appinfo element marking the element
+// or the attribute declaring it as deprecated, optionally followed by a colon
+// and the reason, such as "deprecated: use Id instead". The members of the
+// deprecated declarations are generated with the deprecation markers of the
+// language, so the consumers get the warnings of their compilers.
+const AppinfoDeprecated = "deprecated"
+
+// rustAllowDeprecated is the inner attribute of the Rust files accessing the
+// deprecated fields, so the generated code itself doesn't warn.
+const rustAllowDeprecated = "#![allow(deprecated)]\n\n"
+
+// parseAppinfoDeprecation returns the reason of the deprecation on a single
+// line and true if the content of the appinfo element is the deprecated
+// marker.
+func parseAppinfoDeprecation(content string) (string, bool) {
+	content = strings.TrimSpace(content)
+	if len(content) < len(AppinfoDeprecated) || !strings.EqualFold(content[:len(AppinfoDeprecated)], AppinfoDeprecated) {
+		return "", false
+	}
+	reason := strings.TrimSpace(content[len(AppinfoDeprecated):])
+	if reason == "" {
+		return "", true
+	}
+	if !strings.HasPrefix(reason, ":") {
+		return "", false
+	}
+	return strings.Join(strings.Fields(reason[1:]), " "), true
+}
+
+// ReadDeprecations reads the deprecations file of the given name into the
+// deprecations option, which marks the elements and attributes by name as
+// deprecated regardless of the annotations of the schema. Each line of the
+// file holds the name, optionally followed by an equals sign and the reason,
+// the blank lines and the lines starting with # are ignored. The name is the
+// name of the element or attribute in any type, or qualified by the name of
+// the complex type, the group or the attribute group declaring it, such as
+// Dbtr.Nm.
+func ReadDeprecations(name string) (map[string]string, error) {
+	deprecations := map[string]string{}
+	if name == "" {
+		return deprecations, nil
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		declName, reason := text, ""
+		if idx := strings.Index(text, "="); idx != -1 {
+			declName, reason = strings.TrimSpace(text[:idx]), strings.TrimSpace(text[idx+1:])
+		}
+		if declName == "" {
+			return nil, fmt.Errorf("invalid deprecation at %s:%d, expected <name> or <name>=<reason>", name, line)
+		}
+		deprecations[declName] = reason
+	}
+	return deprecations, scanner.Err()
+}
+
+// formatDeprecations returns the deprecations option as the comma-separated
+// name=reason pairs ordered by name.
+func formatDeprecations(deprecations map[string]string) string {
+	var names, pairs []string
+	for name := range deprecations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pairs = append(pairs, name+"="+deprecations[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// resolveDeprecations marks the elements and attributes of the deprecations
+// option as deprecated, with the reasons of the option replacing the ones of
+// the annotations. The deprecation qualified by the name of the declaring
+// type takes precedence over the one of the name only.
+func (opt *Options) resolveDeprecations() {
+	if len(opt.Deprecations) == 0 {
+		return
+	}
+	deprecate := func(typeName, name string, deprecated *bool, reason *string) {
+		if value, ok := opt.Deprecations[typeName+"."+name]; ok {
+			*deprecated, *reason = true, value
+		} else if value, ok := opt.Deprecations[name]; ok {
+			*deprecated, *reason = true, value
+		}
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *ComplexType:
+			for i := range v.Elements {
+				deprecate(v.Name, v.Elements[i].Name, &v.Elements[i].Deprecated, &v.Elements[i].Deprecation)
+			}
+			for i := range v.Attributes {
+				deprecate(v.Name, v.Attributes[i].Name, &v.Attributes[i].Deprecated, &v.Attributes[i].Deprecation)
+			}
+		case *Group:
+			for i := range v.Elements {
+				deprecate(v.Name, v.Elements[i].Name, &v.Elements[i].Deprecated, &v.Elements[i].Deprecation)
+			}
+		case *AttributeGroup:
+			for i := range v.Attributes {
+				deprecate(v.Name, v.Attributes[i].Name, &v.Attributes[i].Deprecated, &v.Attributes[i].Deprecation)
+			}
+		}
+	}
+}
+
+// genGoDeprecatedDoc generate the deprecation paragraph of the field for Go
+// code, if the field is deprecated, which the linters report the uses of.
+func genGoDeprecatedDoc(deprecated bool, reason string) string {
+	if !deprecated {
+		return ""
+	}
+	if reason == "" {
+		reason = "The member is deprecated by the schema."
+	}
+	return fmt.Sprintf("\t// Deprecated: %s\n", reason)
+}
+
+// genRustDeprecatedAttr generate the deprecated attribute of the field for
+// Rust code, if the field is deprecated.
+func genRustDeprecatedAttr(deprecated bool, reason string) string {
+	if !deprecated {
+		return ""
+	}
+	if reason == "" {
+		return "\t#[deprecated]\n"
+	}
+	return fmt.Sprintf("\t#[deprecated(note = \"%s\")]\n", escapeRustString(reason))
+}
+
+// genRustAllowDeprecated returns the inner attribute allowing the uses of
+// the deprecated fields in the generated Rust code, if the code declares
+// any.
+func genRustAllowDeprecated(code string) string {
+	if !strings.Contains(code, "\t#[deprecated") {
+		return ""
+	}
+	return rustAllowDeprecated
+}
+
+// genJavaDeprecatedAnnotation generate the deprecated annotation of the field
+// for Java code, if the field is deprecated, along with the Javadoc tag of
+// the reason.
+func genJavaDeprecatedAnnotation(deprecated bool, reason string) string {
+	if !deprecated {
+		return ""
+	}
+	if reason == "" {
+		return "\t@Deprecated\n"
+	}
+	return fmt.Sprintf("\t/** @deprecated %s */\n\t@Deprecated\n", escapeDocComment(reason))
+}
+
+// genTypeScriptDeprecatedDoc generate the JSDoc comment of the field for
+// TypeScript code, if the field is deprecated.
+func genTypeScriptDeprecatedDoc(deprecated bool, reason string) string {
+	if !deprecated {
+		return ""
+	}
+	if reason == "" {
+		return "\t/** @deprecated */\n"
+	}
+	return fmt.Sprintf("\t/** @deprecated %s */\n", escapeDocComment(reason))
+}
+
+// escapeDocComment returns the text escaped for the block comment, which
+// can't end the comment.
+func escapeDocComment(text string) string {
+	return strings.Replace(text, "*/", "*\\/", -1)
+}
@@ -0,0 +1,254 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"sort"
+)
+
+// DumpVersion is the version of the SchemaDump form. It is increased on
+// changes which aren't backward compatible, adding fields is compatible.
+const DumpVersion = 1
+
+// Kinds of the declarations in the SchemaDump.
+const (
+	KindSimpleType     = "simpleType"
+	KindComplexType    = "complexType"
+	KindElement        = "element"
+	KindAttribute      = "attribute"
+	KindGroup          = "group"
+	KindAttributeGroup = "attributeGroup"
+	KindUnique         = "unique"
+	KindKey            = "key"
+	KindKeyRef         = "keyref"
+	KindNotation       = "notation"
+)
+
+// KindSubstitutionGroup is the kind of the substitution groups added to the
+// proto tree by the resolve stage, which are named by their head element.
+const KindSubstitutionGroup = "substitutionGroup"
+
+// KindDerivedTypes is the kind of the derived types of the abstract complex
+// types added to the proto tree by the resolve stage, which are named by
+// their abstract type.
+const KindDerivedTypes = "derivedTypes"
+
+// SchemaDump is the stable form of the parsed XML schema, for the tools
+// which consume the parse results without generated code. The declarations
+// keep the order of the schema. Built-in types are referenced by their local
+// name, such as "string", and the other types by their name in the schema.
+// As in the parsed schema, elements and attributes of simple types refer to
+// the built-in base type and hold the facets of the simple type.
+type SchemaDump struct {
+	Version         int               `json:"version" yaml:"version"`
+	File            string            `json:"file" yaml:"file"`
+	TargetNamespace string            `json:"targetNamespace,omitempty" yaml:"targetNamespace,omitempty"`
+	Namespaces      map[string]string `json:"namespaces" yaml:"namespaces"`
+	Declarations    []Declaration     `json:"declarations" yaml:"declarations"`
+}
+
+// Declaration is a declaration of the SchemaDump, Kind tells which of the
+// fields apply. The members of the complex types and groups are nested
+// declarations.
+type Declaration struct {
+	Kind              string        `json:"kind" yaml:"kind"`
+	Name              string        `json:"name" yaml:"name"`
+	Doc               string        `json:"doc,omitempty" yaml:"doc,omitempty"`
+	Docs              []DocEntry    `json:"docs,omitempty" yaml:"docs,omitempty"`
+	Type              string        `json:"type,omitempty" yaml:"type,omitempty"`
+	Base              string        `json:"base,omitempty" yaml:"base,omitempty"`
+	Ref               string        `json:"ref,omitempty" yaml:"ref,omitempty"`
+	Default           string        `json:"default,omitempty" yaml:"default,omitempty"`
+	Fixed             string        `json:"fixed,omitempty" yaml:"fixed,omitempty"`
+	Choice            string        `json:"choice,omitempty" yaml:"choice,omitempty"`
+	Anonymous         bool          `json:"anonymous,omitempty" yaml:"anonymous,omitempty"`
+	Abstract          bool          `json:"abstract,omitempty" yaml:"abstract,omitempty"`
+	SubstitutionGroup string        `json:"substitutionGroup,omitempty" yaml:"substitutionGroup,omitempty"`
+	List              bool          `json:"list,omitempty" yaml:"list,omitempty"`
+	Union             bool          `json:"union,omitempty" yaml:"union,omitempty"`
+	Mixed             bool          `json:"mixed,omitempty" yaml:"mixed,omitempty"`
+	Plural            bool          `json:"plural,omitempty" yaml:"plural,omitempty"`
+	Optional          bool          `json:"optional,omitempty" yaml:"optional,omitempty"`
+	Nillable          bool          `json:"nillable,omitempty" yaml:"nillable,omitempty"`
+	Wildcard          bool          `json:"wildcard,omitempty" yaml:"wildcard,omitempty"`
+	AttributeWildcard bool          `json:"attributeWildcard,omitempty" yaml:"attributeWildcard,omitempty"`
+	Sensitive         bool          `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+	Deprecated        bool          `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+	Deprecation       string        `json:"deprecation,omitempty" yaml:"deprecation,omitempty"`
+	MemberTypes       []string      `json:"memberTypes,omitempty" yaml:"memberTypes,omitempty"`
+	ItemType          string        `json:"itemType,omitempty" yaml:"itemType,omitempty"`
+	ItemFacets        *Facets       `json:"itemFacets,omitempty" yaml:"itemFacets,omitempty"`
+	Facets            *Facets       `json:"facets,omitempty" yaml:"facets,omitempty"`
+	Elements          []Declaration `json:"elements,omitempty" yaml:"elements,omitempty"`
+	Attributes        []Declaration `json:"attributes,omitempty" yaml:"attributes,omitempty"`
+	Groups            []Declaration `json:"groups,omitempty" yaml:"groups,omitempty"`
+	AttributeGroups   []Declaration `json:"attributeGroups,omitempty" yaml:"attributeGroups,omitempty"`
+	Public            string        `json:"public,omitempty" yaml:"public,omitempty"`
+	System            string        `json:"system,omitempty" yaml:"system,omitempty"`
+	Selector          string        `json:"selector,omitempty" yaml:"selector,omitempty"`
+	Fields            []string      `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Refer             string        `json:"refer,omitempty" yaml:"refer,omitempty"`
+}
+
+// DocEntry is a documentation of the declaration in the language given by
+// the xml:lang attribute, if any.
+type DocEntry struct {
+	Lang string `json:"lang,omitempty" yaml:"lang,omitempty"`
+	Text string `json:"text" yaml:"text"`
+}
+
+// Facets holds the facets of a restriction, named as the XML schema facets.
+type Facets struct {
+	Enumeration []string `json:"enumeration,omitempty" yaml:"enumeration,omitempty"`
+	// MinInclusive, MaxInclusive, MinExclusive and MaxExclusive hold the
+	// values of the bounds as declared, such as 2024-01-31 of the dates and
+	// the decimals beyond the precision of the floats.
+	MinInclusive string `json:"minInclusive,omitempty" yaml:"minInclusive,omitempty"`
+	MaxInclusive string `json:"maxInclusive,omitempty" yaml:"maxInclusive,omitempty"`
+	MinExclusive string `json:"minExclusive,omitempty" yaml:"minExclusive,omitempty"`
+	MaxExclusive string `json:"maxExclusive,omitempty" yaml:"maxExclusive,omitempty"`
+	MinLength    int    `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength    int    `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Length       *int   `json:"length,omitempty" yaml:"length,omitempty"`
+	Pattern      string `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	WhiteSpace   string `json:"whiteSpace,omitempty" yaml:"whiteSpace,omitempty"`
+	Precision    int    `json:"precision,omitempty" yaml:"precision,omitempty"`
+	// TotalDigits and FractionDigits hold the values of the totalDigits and
+	// fractionDigits facets.
+	TotalDigits    int `json:"totalDigits,omitempty" yaml:"totalDigits,omitempty"`
+	FractionDigits int `json:"fractionDigits,omitempty" yaml:"fractionDigits,omitempty"`
+}
+
+// Dump parses the XML schema without generating code, and returns the
+// parsed declarations in the stable form of SchemaDump.
+func (opt *Options) Dump() (*SchemaDump, error) {
+	opt.Extract = true
+	if err := opt.Parse(); err != nil {
+		return nil, err
+	}
+	dump := &SchemaDump{
+		Version:         DumpVersion,
+		File:            opt.FilePath,
+		TargetNamespace: opt.TargetNamespace,
+		Namespaces:      map[string]string{},
+		Declarations:    []Declaration{},
+	}
+	for prefix, namespace := range opt.LocalNameNSMap {
+		dump.Namespaces[prefix] = namespace
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			dump.Declarations = append(dump.Declarations, dumpSimpleType(v))
+		case *ComplexType:
+			dump.Declarations = append(dump.Declarations, dumpComplexType(v))
+		case *Element:
+			dump.Declarations = append(dump.Declarations, dumpElement(v))
+		case *Attribute:
+			dump.Declarations = append(dump.Declarations, dumpAttribute(v))
+		case *Group:
+			dump.Declarations = append(dump.Declarations, dumpGroup(v))
+		case *AttributeGroup:
+			dump.Declarations = append(dump.Declarations, dumpAttributeGroup(v))
+		case *Unique:
+			dump.Declarations = append(dump.Declarations, Declaration{Kind: v.kind(), Name: v.Name, Type: v.Type, Selector: v.Selector, Fields: v.Fields, Refer: v.Refer})
+		case *Notation:
+			dump.Declarations = append(dump.Declarations, Declaration{Kind: KindNotation, Name: v.Name, Doc: v.Doc, Docs: dumpDocs(v.Docs), Public: v.Public, System: v.System})
+		}
+	}
+	return dump, nil
+}
+
+func dumpSimpleType(v *SimpleType) Declaration {
+	d := Declaration{Kind: KindSimpleType, Name: v.Name, Doc: v.Doc, Docs: dumpDocs(v.Docs), Base: v.Base, Anonymous: v.Anonymous, List: v.List, Union: v.Union, ItemType: v.ItemType, Facets: dumpFacets(v.Restriction), ItemFacets: dumpFacets(v.ItemRestriction)}
+	for memberType := range v.MemberTypes {
+		d.MemberTypes = append(d.MemberTypes, memberType)
+	}
+	sort.Strings(d.MemberTypes)
+	return d
+}
+
+func dumpComplexType(v *ComplexType) Declaration {
+	d := Declaration{Kind: KindComplexType, Name: v.Name, Doc: v.Doc, Docs: dumpDocs(v.Docs), Base: v.Base, Anonymous: v.Anonymous, Abstract: v.Abstract, Mixed: v.Mixed, Wildcard: v.Any, AttributeWildcard: v.AnyAttribute}
+	for i := range v.Elements {
+		d.Elements = append(d.Elements, dumpElement(&v.Elements[i]))
+	}
+	for i := range v.Attributes {
+		d.Attributes = append(d.Attributes, dumpAttribute(&v.Attributes[i]))
+	}
+	for i := range v.Groups {
+		d.Groups = append(d.Groups, dumpGroup(&v.Groups[i]))
+	}
+	for i := range v.AttributeGroup {
+		d.AttributeGroups = append(d.AttributeGroups, dumpAttributeGroup(&v.AttributeGroup[i]))
+	}
+	return d
+}
+
+func dumpElement(v *Element) Declaration {
+	return Declaration{Kind: KindElement, Name: v.Name, Doc: v.Doc, Docs: dumpDocs(v.Docs), Type: v.Type, Default: v.Default, Fixed: v.Fixed, Abstract: v.Abstract, SubstitutionGroup: v.SubstitutionGroup, Plural: v.Plural, Optional: v.Optional, Choice: v.Choice, Nillable: v.Nillable, Wildcard: v.Wildcard, Sensitive: v.Sensitive, Deprecated: v.Deprecated, Deprecation: v.Deprecation, Facets: dumpFacets(v.Restriction)}
+}
+
+func dumpAttribute(v *Attribute) Declaration {
+	return Declaration{Kind: KindAttribute, Name: v.Name, Doc: v.Doc, Docs: dumpDocs(v.Docs), Type: v.Type, Default: v.Default, Fixed: v.Fixed, Plural: v.Plural, Optional: v.Optional, Sensitive: v.Sensitive, Deprecated: v.Deprecated, Deprecation: v.Deprecation, Facets: dumpFacets(v.Restriction)}
+}
+
+func dumpGroup(v *Group) Declaration {
+	d := Declaration{Kind: KindGroup, Name: v.Name, Doc: v.Doc, Docs: dumpDocs(v.Docs), Ref: v.Ref, Plural: v.Plural, Wildcard: v.Any}
+	for i := range v.Elements {
+		d.Elements = append(d.Elements, dumpElement(&v.Elements[i]))
+	}
+	for i := range v.Groups {
+		d.Groups = append(d.Groups, dumpGroup(&v.Groups[i]))
+	}
+	return d
+}
+
+func dumpAttributeGroup(v *AttributeGroup) Declaration {
+	d := Declaration{Kind: KindAttributeGroup, Name: v.Name, Doc: v.Doc, Docs: dumpDocs(v.Docs), Ref: v.Ref, AttributeWildcard: v.AnyAttribute}
+	for i := range v.Attributes {
+		d.Attributes = append(d.Attributes, dumpAttribute(&v.Attributes[i]))
+	}
+	return d
+}
+
+// dumpDocs returns the documentation entries of the declaration.
+func dumpDocs(docs []Documentation) []DocEntry {
+	var entries []DocEntry
+	for _, doc := range docs {
+		entries = append(entries, DocEntry{Lang: doc.Lang, Text: doc.Text})
+	}
+	return entries
+}
+
+// dumpFacets returns the facets of the restriction, or nil if the
+// restriction doesn't declare any facet.
+func dumpFacets(r Restriction) *Facets {
+	if r.IsEmpty() {
+		return nil
+	}
+	f := &Facets{Enumeration: r.Enum, MinLength: r.MinLength, MaxLength: r.MaxLength, WhiteSpace: r.WhiteSpace, Precision: r.Precision, TotalDigits: r.TotalDigits, FractionDigits: r.FractionDigits}
+	if length := r.Length; r.HasLength {
+		f.Length = &length
+	}
+	if min := r.minValue().Lexical; r.HasMin && r.MinExclusive {
+		f.MinExclusive = min
+	} else if r.HasMin {
+		f.MinInclusive = min
+	}
+	if max := r.maxValue().Lexical; r.HasMax && r.MaxExclusive {
+		f.MaxExclusive = max
+	} else if r.HasMax {
+		f.MaxInclusive = max
+	}
+	if r.Pattern != nil {
+		f.Pattern = r.Pattern.String()
+	}
+	return f
+}
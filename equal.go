@@ -0,0 +1,197 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// equalType holds the name and the field content of a struct the equality
+// method is generated for, with the restrictions of its fields by field
+// name, whose string values are compared in their canonical form.
+type equalType struct {
+	name, content string
+	restrictions  map[string]*Restriction
+}
+
+// addEqualType records the struct by given name and field content, so the
+// equality methods are generated once all the structs are known, and the
+// members holding the structs are compared by their equality methods.
+func (gen *CodeGenerator) addEqualType(name, content string, restrictions map[string]*Restriction) {
+	if gen.Equality {
+		gen.equalTypes = append(gen.equalTypes, equalType{name, content, restrictions})
+	}
+}
+
+// isEqualType returns whether the equality method is generated for a struct
+// by given name.
+func (gen *CodeGenerator) isEqualType(name string) bool {
+	for _, t := range gen.equalTypes {
+		if t.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getGoFieldRestrictions returns the restrictions of the fields of the
+// elements and the attributes by their field names for Go code.
+func (gen *CodeGenerator) getGoFieldRestrictions(elements []Element, attributes []Attribute) map[string]*Restriction {
+	restrictions := map[string]*Restriction{}
+	for i, element := range elements {
+		restrictions[genGoFieldName(gen.genPluralName(element.Name, element.Plural), false)] = &elements[i].Restriction
+	}
+	for i, attribute := range attributes {
+		restrictions[genGoFieldName(attribute.Name, false)+"Attr"] = &attributes[i].Restriction
+	}
+	return restrictions
+}
+
+// getRustFieldRestrictions returns the restrictions of the fields of the
+// elements and the attributes by their field names for Rust code.
+func (gen *CodeGenerator) getRustFieldRestrictions(elements []Element, attributes []Attribute) map[string]*Restriction {
+	restrictions := map[string]*Restriction{}
+	for i, element := range elements {
+		restrictions[genRustFieldName(gen.genRustPluralName(element.Name, element.Plural))] = &elements[i].Restriction
+	}
+	for i, attribute := range attributes {
+		restrictions[genRustFieldName(attribute.Name)] = &attributes[i].Restriction
+	}
+	return restrictions
+}
+
+// goComparableTypes holds the Go types of the fields compared by the
+// operator, the values of the numeric types are compared by their values
+// whatever their lexical forms are.
+var goComparableTypes = map[string]bool{
+	"xml.Name": true, "byte": true, "bool": true, "complex64": true, "complex128": true,
+	"float32": true, "float64": true, "int": true, "int8": true, "int16": true,
+	"int32": true, "int64": true, "string": true, "uint": true, "uint8": true,
+	"uint16": true, "uint32": true, "uint64": true,
+	booleanTypes["Go"]: true, decimalTypes["Go"]: true,
+}
+
+// genGoEqual generate the Equal method of each struct for Go code, which
+// reports whether the struct and the other struct hold the same values in
+// the canonical form implied by the facets of the schema. The strings are
+// compared after the normalization of their whiteSpace and case facets, the
+// nested structs by their Equal methods, and the members of the other types
+// by their values.
+func (gen *CodeGenerator) genGoEqual() {
+	for _, t := range gen.equalTypes {
+		var body string
+		for _, match := range goStructFieldRegexp.FindAllStringSubmatch(t.content, -1) {
+			fieldName, fieldType := match[1], match[2]
+			if fieldName == "XMLName" {
+				continue
+			}
+			if fieldType == "" {
+				// The embedded struct is named after its type.
+				fieldName, fieldType = strings.TrimPrefix(fieldName, "*"), fieldName
+			}
+			body += gen.genGoFieldEqual(fieldName, fieldType, t.restrictions[fieldName])
+		}
+		gen.Field += fmt.Sprintf("\n// Equal reports whether the %s and the other %s hold the same values in\n// the canonical form implied by the facets of the schema.\nfunc (v *%s) Equal(o *%s) bool {\n\tif v == nil || o == nil {\n\t\treturn v == o\n\t}\n%s\treturn true\n}\n", t.name, t.name, t.name, t.name, body)
+	}
+}
+
+// genGoFieldEqual generate the code of the Equal method comparing the field
+// of the struct for Go code, the members of the types which aren't compared
+// otherwise are compared deeply.
+func (gen *CodeGenerator) genGoFieldEqual(fieldName, fieldType string, restriction *Restriction) string {
+	elemType := strings.TrimPrefix(fieldType, "[]")
+	notEqual := func(a, b string) string {
+		switch {
+		case strings.HasPrefix(elemType, "*") && gen.isEqualType(elemType[1:]):
+			return fmt.Sprintf("!%s.Equal(%s)", a, b)
+		case elemType == "time.Time":
+			return fmt.Sprintf("!%s.Equal(%s)", a, b)
+		case elemType == "string":
+			if expr := genGoNormalizeExpr(elemType, a, restriction); expr != "" {
+				return fmt.Sprintf("%s != %s", expr, genGoNormalizeExpr(elemType, b, restriction))
+			}
+			return fmt.Sprintf("%s != %s", a, b)
+		case goComparableTypes[elemType]:
+			return fmt.Sprintf("%s != %s", a, b)
+		}
+		return ""
+	}
+	field, other := "v."+fieldName, "o."+fieldName
+	if notEqual(field, other) == "" {
+		return fmt.Sprintf("\tif !reflect.DeepEqual(%s, %s) {\n\t\treturn false\n\t}\n", field, other)
+	}
+	if elemType != fieldType {
+		return fmt.Sprintf("\tif len(%s) != len(%s) {\n\t\treturn false\n\t}\n\tfor i := range %s {\n\t\tif %s {\n\t\t\treturn false\n\t\t}\n\t}\n", field, other, field, notEqual(field+"[i]", other+"[i]"))
+	}
+	return fmt.Sprintf("\tif %s {\n\t\treturn false\n\t}\n", notEqual(field, other))
+}
+
+// genRustEqual generate the canonical_eq method of each struct for Rust
+// code, which returns whether the struct and the other struct hold the same
+// values in the canonical form implied by the facets of the schema. The
+// strings are compared after the normalization of their whiteSpace and case
+// facets, the nested structs by their canonical_eq methods, and the members
+// of the other types by their values.
+func (gen *CodeGenerator) genRustEqual() {
+	for _, t := range gen.equalTypes {
+		var body string
+		for _, match := range rustPublicFieldRegexp.FindAllStringSubmatch(t.content, -1) {
+			body += gen.genRustFieldEqual(match[1], match[2], t.restrictions[match[1]])
+		}
+		if body == "" {
+			body = "\t\tif self != other {\n\t\t\treturn false;\n\t\t}\n"
+		}
+		gen.Field += fmt.Sprintf("\nimpl %s {\n\t/// Returns whether the values of the %s are the same as the values of\n\t/// the other one in the canonical form implied by the facets of the schema.\n\tpub fn canonical_eq(&self, other: &Self) -> bool {\n%s\t\ttrue\n\t}\n}\n", t.name, t.name, body)
+	}
+}
+
+// genRustFieldEqual generate the code of the canonical_eq method comparing
+// the field of the struct for Rust code, the options and the vectors are
+// compared item by item.
+func (gen *CodeGenerator) genRustFieldEqual(fieldName, fieldType string, restriction *Restriction) string {
+	elemType, optional, plural := fieldType, false, false
+	if strings.HasPrefix(elemType, "Option<") {
+		elemType, optional = strings.TrimSuffix(strings.TrimPrefix(elemType, "Option<"), ">"), true
+	}
+	if strings.HasPrefix(elemType, "Vec<") {
+		elemType, plural = strings.TrimSuffix(strings.TrimPrefix(elemType, "Vec<"), ">"), true
+	}
+	if strings.HasPrefix(elemType, "Box<") {
+		elemType = strings.TrimSuffix(strings.TrimPrefix(elemType, "Box<"), ">")
+	}
+	notEqual := func(a, b string) string {
+		if gen.isEqualType(elemType) {
+			// The items of the options and the vectors are references.
+			if b != "b" {
+				b = "&" + b
+			}
+			return fmt.Sprintf("!%s.canonical_eq(%s)", a, b)
+		}
+		if expr := genRustNormalizeExpr(elemType, a, restriction); expr != "" {
+			return fmt.Sprintf("%s != %s", expr, genRustNormalizeExpr(elemType, b, restriction))
+		}
+		return ""
+	}
+	field, other := "self."+fieldName, "other."+fieldName
+	var condition string
+	switch {
+	case notEqual("a", "b") == "":
+		condition = fmt.Sprintf("%s != %s", field, other)
+	case optional && plural:
+		condition = fmt.Sprintf("%s.is_some() != %s.is_some() || %s.iter().flatten().count() != %s.iter().flatten().count() || %s.iter().flatten().zip(%s.iter().flatten()).any(|(a, b)| %s)", field, other, field, other, field, other, notEqual("a", "b"))
+	case optional:
+		condition = fmt.Sprintf("%s.is_some() != %s.is_some() || %s.iter().zip(%s.iter()).any(|(a, b)| %s)", field, other, field, other, notEqual("a", "b"))
+	case plural:
+		condition = fmt.Sprintf("%s.len() != %s.len() || %s.iter().zip(%s.iter()).any(|(a, b)| %s)", field, other, field, other, notEqual("a", "b"))
+	default:
+		condition = notEqual(field, other)
+	}
+	return fmt.Sprintf("\t\tif %s {\n\t\t\treturn false;\n\t\t}\n", condition)
+}
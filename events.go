@@ -0,0 +1,102 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "time"
+
+// Kinds of the events reported while parsing a batch of XML schema files.
+const (
+	EventFileStarted  = "file-started"
+	EventTypeParsed   = "type-parsed"
+	EventWarning      = "warning"
+	EventFileFinished = "file-finished"
+)
+
+// Event describes the parse event reported to the Events callback of the
+// options, in the stable form the xgen command streams as JSON Lines, so the
+// editor plugins and the build orchestrators can surface the live progress
+// and the diagnostics. Kind and Name hold the kind and the name of the
+// parsed declaration, Message holds the warning prefixed by the file and the
+// line it's found at, and Error holds the error of the file which failed.
+type Event struct {
+	Event     string `json:"event"`
+	File      string `json:"file"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Completed int    `json:"completed,omitempty"`
+	Total     int    `json:"total,omitempty"`
+	ElapsedMs int64  `json:"elapsedMs,omitempty"`
+}
+
+// emitEvent reports the event of the file to the Events callback of the
+// options, if it isn't nil.
+func (opt *Options) emitEvent(event Event) {
+	if opt.Events == nil {
+		return
+	}
+	event.File = opt.FilePath
+	opt.Events(event)
+}
+
+// emitTypesParsed reports the declarations appended to the proto tree from
+// given index as parsed.
+func (opt *Options) emitTypesParsed(from int) {
+	if opt.Events == nil {
+		return
+	}
+	for _, ele := range opt.ProtoTree[from:] {
+		if kind, name := getDeclarationKind(ele); name != "" {
+			opt.emitEvent(Event{Event: EventTypeParsed, Kind: kind, Name: name})
+		}
+	}
+}
+
+// emitFileFinished reports given warnings of the file and the file finished
+// by given error, the number of the files processed and the time since the
+// batch started.
+func (opt *Options) emitFileFinished(warnings []Warning, err error, completed, total int, start time.Time) {
+	for _, warning := range warnings {
+		opt.emitEvent(Event{Event: EventWarning, Message: warning.String()})
+	}
+	event := Event{Event: EventFileFinished, Completed: completed, Total: total, ElapsedMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	opt.emitEvent(event)
+}
+
+// getDeclarationKind returns the kind and the name of the declaration in the
+// proto tree, the kinds are the ones of the schema dump.
+func getDeclarationKind(ele interface{}) (kind, name string) {
+	switch v := ele.(type) {
+	case *SimpleType:
+		return KindSimpleType, v.Name
+	case *ComplexType:
+		return KindComplexType, v.Name
+	case *Element:
+		return KindElement, v.Name
+	case *Attribute:
+		return KindAttribute, v.Name
+	case *Group:
+		return KindGroup, v.Name
+	case *AttributeGroup:
+		return KindAttributeGroup, v.Name
+	case *Unique:
+		return v.kind(), v.Name
+	case *Notation:
+		return KindNotation, v.Name
+	case *SubstitutionGroup:
+		return KindSubstitutionGroup, v.Head
+	case *DerivedTypes:
+		return KindDerivedTypes, v.Abstract
+	}
+	return
+}
@@ -0,0 +1,223 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ExternalTypes is the registry of the external namespaces, such as the
+// namespaces of XML Signature and XML Encryption, whose declarations are
+// resolved to the pre-existing types of each language, as the built-in types
+// of XSD are, instead of generated. It maps the languages to the namespaces,
+// and the local names of the declarations of each namespace to the types.
+// The Go types are given by the import paths of their packages, such as
+// github.com/russellhaering/goxmldsig/types.Signature, and imported by the
+// generated code, the types of the other languages are used as given.
+type ExternalTypes map[string]map[string]map[string]string
+
+// Register registers the pre-existing type of the language for the
+// declaration by the local name of the external namespace, such as the
+// xmldsig::Signature type of Rust for the Signature element of the
+// http://www.w3.org/2000/09/xmldsig# namespace.
+func (e ExternalTypes) Register(lang, namespace, name, typeName string) error {
+	if _, ok := LangDirs[lang]; !ok {
+		return fmt.Errorf("unknown language %s of the external type %s", lang, typeName)
+	}
+	if namespace == "" || namespace == xsdNamespace {
+		return fmt.Errorf("invalid namespace %q of the external type %s", namespace, typeName)
+	}
+	if name == "" || strings.ContainsAny(name, " :{}") {
+		return fmt.Errorf("invalid name %q of the external type %s", name, typeName)
+	}
+	if typeName == "" || strings.ContainsAny(typeName, " \t") {
+		return fmt.Errorf("invalid external type %q of %s", typeName, formatVerifyName(xml.Name{Space: namespace, Local: name}))
+	}
+	if e[lang] == nil {
+		e[lang] = map[string]map[string]string{}
+	}
+	if e[lang][namespace] == nil {
+		e[lang][namespace] = map[string]string{}
+	}
+	e[lang][namespace][name] = typeName
+	return nil
+}
+
+// ReadExternalTypes reads the external types file of the given name into the
+// registry of the external namespaces. Each line of the file holds the
+// language, a space, the qualified name of the declaration in the
+// {namespace}local form, an equals sign and the type, such as
+// "Rust {http://www.w3.org/2000/09/xmldsig#}Signature=xmldsig::Signature",
+// the blank lines and the lines starting with # are ignored.
+func ReadExternalTypes(name string) (ExternalTypes, error) {
+	externalTypes := ExternalTypes{}
+	if name == "" {
+		return externalTypes, nil
+	}
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+		idx := strings.Index(text[end+1:], "=")
+		if len(fields) != 2 || start == -1 || end < start || idx == -1 {
+			return nil, fmt.Errorf("invalid external type at %s:%d, expected <lang> {<namespace>}<name>=<type>", name, line)
+		}
+		if err = externalTypes.Register(fields[0], text[start+1:end], text[end+1:end+1+idx], text[end+2+idx:]); err != nil {
+			return nil, fmt.Errorf("invalid external type at %s:%d: %v", name, line, err)
+		}
+	}
+	return externalTypes, scanner.Err()
+}
+
+// formatExternalTypes returns the registry of the external namespaces as the
+// comma-separated lines of the external types file in order.
+func formatExternalTypes(externalTypes ExternalTypes) string {
+	var lines []string
+	for lang, namespaces := range externalTypes {
+		for namespace, types := range namespaces {
+			for name, typeName := range types {
+				lines = append(lines, fmt.Sprintf("%s %s=%s", lang, formatVerifyName(xml.Name{Space: namespace, Local: name}), typeName))
+			}
+		}
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, ",")
+}
+
+// getLangs returns the languages the code is generated in by the options.
+func (opt *Options) getLangs() []string {
+	if len(opt.Langs) > 0 {
+		return opt.Langs
+	}
+	return []string{opt.Lang}
+}
+
+// isExternalNamespace returns true if the namespace is registered as an
+// external namespace for all the languages of the options.
+func (opt *Options) isExternalNamespace(namespace string) bool {
+	if namespace == "" || len(opt.ExternalTypes) == 0 {
+		return false
+	}
+	for _, lang := range opt.getLangs() {
+		if _, ok := opt.ExternalTypes[lang][namespace]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// getCommonExternalTypes returns the external types of the namespaces which
+// are registered for all the languages of the options, the namespaces of
+// the other languages are generated in all of them.
+func (opt *Options) getCommonExternalTypes() ExternalTypes {
+	externalTypes := ExternalTypes{}
+	for lang, namespaces := range opt.ExternalTypes {
+		for namespace, types := range namespaces {
+			if !opt.isExternalNamespace(namespace) {
+				continue
+			}
+			if externalTypes[lang] == nil {
+				externalTypes[lang] = map[string]map[string]string{}
+			}
+			externalTypes[lang][namespace] = types
+		}
+	}
+	return externalTypes
+}
+
+// getExternalType returns the type of the declaration by the given prefixed
+// name, and true if the declaration belongs to an external namespace other
+// than the target namespace of the schema. With the languages of the Langs,
+// the qualified name of the declaration is returned, which is mapped to the
+// type of each language before the resolve stage. It returns an error if no
+// type is registered for the declaration.
+func (opt *Options) getExternalType(value string) (string, bool, error) {
+	namespace := opt.parseNS(value)
+	if namespace == opt.TargetNamespace || !opt.isExternalNamespace(namespace) {
+		return "", false, nil
+	}
+	name := xml.Name{Space: namespace, Local: trimNSPrefix(value)}
+	for _, lang := range opt.getLangs() {
+		if _, ok := opt.ExternalTypes[lang][namespace][name.Local]; !ok {
+			return "", true, fmt.Errorf("no %s type registered for %s of the external namespace", lang, formatVerifyName(name))
+		}
+	}
+	if len(opt.Langs) > 0 {
+		return formatVerifyName(name), true, nil
+	}
+	return getExternalTypeName(opt.Lang, opt.ExternalTypes[opt.Lang][namespace][name.Local]), true, nil
+}
+
+// getExternalTypeByQName returns the type of the language of the options for
+// the declaration by the qualified name in the {namespace}local form, and
+// true if it's registered.
+func (opt *Options) getExternalTypeByQName(qname string) (string, bool) {
+	end := strings.LastIndex(qname, "}")
+	if !strings.HasPrefix(qname, "{") || end == -1 {
+		return "", false
+	}
+	typeName, ok := opt.ExternalTypes[opt.Lang][qname[1:end]][qname[end+1:]]
+	return getExternalTypeName(opt.Lang, typeName), ok
+}
+
+// getExternalTypeName returns the name of the external type in the code of
+// the language, which is the type qualified by the name of its package for
+// the Go type given by the import path of the package.
+func getExternalTypeName(lang, typeName string) string {
+	if lang == "Go" {
+		return path.Base(typeName)
+	}
+	return typeName
+}
+
+// registerExternalTypes adds the external types of the language of the
+// options to the types the options use as is in the generated code, so they
+// don't leak into the other parses.
+func (opt *Options) registerExternalTypes() {
+	for _, types := range opt.ExternalTypes[opt.Lang] {
+		for _, typeName := range types {
+			opt.registerBuiltInType(getExternalTypeName(opt.Lang, typeName))
+		}
+	}
+}
+
+// genGoExternalImports returns the import packages of the external types of
+// Go referenced by the given code.
+func (gen *CodeGenerator) genGoExternalImports(code string) (packages string) {
+	imports := map[string]bool{}
+	for _, types := range gen.ExternalTypes["Go"] {
+		for _, typeName := range types {
+			name := getExternalTypeName("Go", typeName)
+			if name == typeName || !regexp.MustCompile(`\b`+regexp.QuoteMeta(name)+`\b`).MatchString(code) {
+				continue
+			}
+			imports[strings.TrimSuffix(typeName, path.Ext(typeName))] = true
+		}
+	}
+	for _, pkg := range sortedKeys(imports) {
+		packages += fmt.Sprintf("\t\"%s\"\n", pkg)
+	}
+	return
+}
@@ -0,0 +1,395 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FacetError is returned by Evaluate when a value violates a facet of the
+// restriction.
+type FacetError struct {
+	Facet   string
+	Value   string
+	Message string
+}
+
+// Error returns the description of the violated facet.
+func (e *FacetError) Error() string {
+	return fmt.Sprintf("value %q violates %s facet: %s", e.Value, e.Facet, e.Message)
+}
+
+// Evaluate validates the lexical value against the facets of the
+// restriction, and returns a *FacetError for the first violated facet. The
+// value is normalized by the whiteSpace facet before the facets are checked.
+func (r Restriction) Evaluate(value string) error {
+	value = normalizeWhiteSpace(value, r.WhiteSpace)
+	if len(r.Enum) > 0 {
+		var found bool
+		for _, enum := range r.Enum {
+			if enum == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return &FacetError{Facet: "enumeration", Value: value, Message: fmt.Sprintf("must be one of [%s]", strings.Join(r.Enum, ", "))}
+		}
+	}
+	length := utf8.RuneCountInString(value)
+	if r.Octets {
+		length = len(value) / 2
+	}
+	if r.HasLength && length != r.Length {
+		return &FacetError{Facet: "length", Value: value, Message: fmt.Sprintf("length %d is not %d", length, r.Length)}
+	}
+	if r.MinLength > 0 && length < r.MinLength {
+		return &FacetError{Facet: "minLength", Value: value, Message: fmt.Sprintf("length %d is less than %d", length, r.MinLength)}
+	}
+	if r.MaxLength > 0 && length > r.MaxLength {
+		return &FacetError{Facet: "maxLength", Value: value, Message: fmt.Sprintf("length %d is greater than %d", length, r.MaxLength)}
+	}
+	if r.Pattern != nil {
+		// Pattern facets are implicitly anchored at both ends, the leftmost
+		// match isn't the longest one of the alternations, such as a|ab.
+		if !anchorPattern(r.Pattern).MatchString(value) {
+			return &FacetError{Facet: "pattern", Value: value, Message: fmt.Sprintf("does not match %s", r.Pattern.String())}
+		}
+	}
+	if r.HasMin || r.HasMax {
+		// The decimals are compared exactly with the bounds of the integer
+		// and the decimal types, such as the integers beyond the precision of
+		// the floats, and the bounds kept lexical, such as the dates, are
+		// compared as the times. The ok is false if the bound can't be
+		// compared, such as the durations, which are partially ordered.
+		compare := func(bound FacetValue) (cmp int, ok bool, err error) {
+			if !bound.IsNumeric() {
+				cmp, bounded, ok := compareTimeBound(strings.TrimSpace(value), bound.Lexical)
+				if bounded && !ok {
+					return 0, false, &FacetError{Facet: "value", Value: value, Message: fmt.Sprintf("is not comparable with %s", bound)}
+				}
+				return cmp, ok, nil
+			}
+			if cmp, ok := bound.Compare(value); ok {
+				return cmp, true, nil
+			}
+			number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				return 0, false, &FacetError{Facet: "value", Value: value, Message: "is not a number"}
+			}
+			switch {
+			case number < bound.Float64():
+				return -1, true, nil
+			case number > bound.Float64():
+				return 1, true, nil
+			}
+			return 0, true, nil
+		}
+		if r.HasMin {
+			min := r.minValue()
+			cmp, ok, err := compare(min)
+			if err != nil {
+				return err
+			}
+			if ok && r.MinExclusive && cmp <= 0 {
+				return &FacetError{Facet: "minExclusive", Value: value, Message: fmt.Sprintf("is not greater than %s", min)}
+			} else if ok && !r.MinExclusive && cmp < 0 {
+				return &FacetError{Facet: "minInclusive", Value: value, Message: fmt.Sprintf("is less than %s", min)}
+			}
+		}
+		if r.HasMax {
+			max := r.maxValue()
+			cmp, ok, err := compare(max)
+			if err != nil {
+				return err
+			}
+			if ok && r.MaxExclusive && cmp >= 0 {
+				return &FacetError{Facet: "maxExclusive", Value: value, Message: fmt.Sprintf("is not less than %s", max)}
+			} else if ok && !r.MaxExclusive && cmp > 0 {
+				return &FacetError{Facet: "maxInclusive", Value: value, Message: fmt.Sprintf("is greater than %s", max)}
+			}
+		}
+	}
+	if r.TotalDigits > 0 || r.FractionDigits > 0 {
+		total, fraction, ok := getDecimalDigits(strings.TrimSpace(value))
+		if !ok {
+			return &FacetError{Facet: "value", Value: value, Message: "is not a decimal number"}
+		}
+		if r.TotalDigits > 0 && total > r.TotalDigits {
+			return &FacetError{Facet: "totalDigits", Value: value, Message: fmt.Sprintf("has %d digits, more than %d", total, r.TotalDigits)}
+		}
+		if r.FractionDigits > 0 && fraction > r.FractionDigits {
+			return &FacetError{Facet: "fractionDigits", Value: value, Message: fmt.Sprintf("has %d fraction digits, more than %d", fraction, r.FractionDigits)}
+		}
+	}
+	return nil
+}
+
+// anchoredPatterns caches the pattern facets anchored at both ends by the
+// expressions of the patterns.
+var anchoredPatterns sync.Map
+
+// anchorPattern returns the pattern facet anchored at both ends.
+func anchorPattern(re *regexp.Regexp) *regexp.Regexp {
+	if anchored, ok := anchoredPatterns.Load(re.String()); ok {
+		return anchored.(*regexp.Regexp)
+	}
+	anchored := regexp.MustCompile(`^(?:` + re.String() + `)$`)
+	anchoredPatterns.Store(re.String(), anchored)
+	return anchored
+}
+
+// facetTimeLayouts holds the layouts of the lexical forms of the date and
+// time built-in data types, with and without the timezone.
+var facetTimeLayouts = [][]string{
+	{"2006-01-02T15:04:05.999999999Z07:00", "2006-01-02T15:04:05.999999999"},
+	{"2006-01-02Z07:00", "2006-01-02"},
+	{"2006-01Z07:00", "2006-01"},
+	{"2006Z07:00", "2006"},
+	{"15:04:05.999999999Z07:00", "15:04:05.999999999"},
+	{"--01-02Z07:00", "--01-02"},
+	{"---02Z07:00", "---02"},
+	{"--01Z07:00", "--01"},
+}
+
+// compareTimeBound compares the value with the bound kept lexical as the
+// dates or the times of the same built-in data type, the values without the
+// timezone are taken as UTC. The bounded is false if the bound isn't a date
+// or a time, and the ok is false if the value isn't one of the same type.
+func compareTimeBound(value, bound string) (cmp int, bounded, ok bool) {
+	for _, layouts := range facetTimeLayouts {
+		b, found := parseFacetTime(bound, layouts)
+		if !found {
+			continue
+		}
+		v, found := parseFacetTime(value, layouts)
+		if !found {
+			return 0, true, false
+		}
+		switch {
+		case v.Before(b):
+			return -1, true, true
+		case v.After(b):
+			return 1, true, true
+		}
+		return 0, true, true
+	}
+	return 0, false, false
+}
+
+// parseFacetTime parses the lexical value by the first of given layouts it
+// matches.
+func parseFacetTime(value string, layouts []string) (time.Time, bool) {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// numericEnum returns the values of the enumeration facets on the numeric
+// types typed by the base type, without the duplicates of the same value,
+// such as 1 and 1.0. The ok is false if a value isn't a finite number, which
+// can't be compared by the generated code.
+func (r Restriction) numericEnum() (values []FacetValue, ok bool) {
+	seen := map[string]bool{}
+	for _, enum := range r.Enum {
+		value, err := newFacetValue(enum, r.base)
+		if err != nil || !value.IsNumeric() || value.IsInf() {
+			return nil, false
+		}
+		exact, ok := value.Exact()
+		if !ok {
+			exact = new(big.Rat).SetFloat64(value.Float)
+		}
+		if key := exact.RatString(); !seen[key] {
+			seen[key] = true
+			values = append(values, value)
+		}
+	}
+	return values, len(values) > 0
+}
+
+// getDecimalDigits returns the total digits and the fraction digits of the
+// lexical form of the decimal, which are counted without the leading zeros of
+// the integer part and the trailing zeros of the fraction part.
+func getDecimalDigits(value string) (total, fraction int, ok bool) {
+	digits := strings.TrimLeft(value, "+-")
+	if len(value)-len(digits) > 1 || digits == "" || digits == "." {
+		return
+	}
+	integer, fractional := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		integer, fractional = digits[:i], digits[i+1:]
+	}
+	for _, c := range integer + fractional {
+		if c < '0' || c > '9' {
+			return
+		}
+	}
+	integer, fractional = strings.TrimLeft(integer, "0"), strings.TrimRight(fractional, "0")
+	return len(integer) + len(fractional), len(fractional), true
+}
+
+// Values of the whiteSpace facet.
+const (
+	WhiteSpacePreserve = "preserve"
+	WhiteSpaceReplace  = "replace"
+	WhiteSpaceCollapse = "collapse"
+)
+
+// Case rules implied by the facets of a restriction.
+const (
+	CaseUpper = "upper"
+	CaseLower = "lower"
+)
+
+// whiteSpaceBuildInTypes holds the whiteSpace facet fixed by the string
+// derived built-in data types.
+var whiteSpaceBuildInTypes = map[string]string{
+	"normalizedString": WhiteSpaceReplace,
+	"token":            WhiteSpaceCollapse,
+	"language":         WhiteSpaceCollapse,
+	"Name":             WhiteSpaceCollapse,
+	"NCName":           WhiteSpaceCollapse,
+	"NMTOKEN":          WhiteSpaceCollapse,
+	"NMTOKENS":         WhiteSpaceCollapse,
+	"ID":               WhiteSpaceCollapse,
+	"IDREF":            WhiteSpaceCollapse,
+	"IDREFS":           WhiteSpaceCollapse,
+	"ENTITY":           WhiteSpaceCollapse,
+	"ENTITIES":         WhiteSpaceCollapse,
+	"anyURI":           WhiteSpaceCollapse,
+}
+
+// Normalization describes the canonicalization of the lexical value implied
+// by the facets of a restriction.
+type Normalization struct {
+	WhiteSpace string
+	// Trim reports whether the leading and trailing white space should be
+	// removed, when the value is a code which can't contain them.
+	Trim bool
+	Case string
+}
+
+// IsEmpty returns true if the normalization leaves the value unchanged.
+func (n Normalization) IsEmpty() bool {
+	return (n.WhiteSpace == "" || n.WhiteSpace == WhiteSpacePreserve) && !n.Trim && n.Case == ""
+}
+
+// Normalization returns the canonicalization rules implied by the facets of
+// the restriction. The case rule is implied when all letters allowed by the
+// enumeration and pattern facets have the same case.
+func (r Restriction) Normalization() Normalization {
+	n := Normalization{WhiteSpace: r.WhiteSpace}
+	var upper, lower bool
+	for _, enum := range r.Enum {
+		upper = upper || strings.ToLower(enum) != enum
+		lower = lower || strings.ToUpper(enum) != enum
+	}
+	if r.Pattern != nil {
+		re, err := syntax.Parse(r.Pattern.String(), syntax.Perl)
+		if err != nil || patternFoldsCase(re) {
+			upper, lower = true, true
+		} else {
+			patternUpper, patternLower := patternLetterCase(re)
+			upper, lower = upper || patternUpper, lower || patternLower
+		}
+	}
+	switch {
+	case upper && !lower:
+		n.Case = CaseUpper
+	case lower && !upper:
+		n.Case = CaseLower
+	}
+	n.Trim = n.WhiteSpace != WhiteSpacePreserve && n.WhiteSpace != WhiteSpaceCollapse && (len(r.Enum) > 0 || n.Case != "")
+	return n
+}
+
+// Normalize returns the canonical form of the lexical value, by applying the
+// normalization implied by the facets of the restriction.
+func (r Restriction) Normalize(value string) string {
+	n := r.Normalization()
+	value = normalizeWhiteSpace(value, n.WhiteSpace)
+	if n.Trim {
+		value = strings.TrimSpace(value)
+	}
+	switch n.Case {
+	case CaseUpper:
+		value = strings.ToUpper(value)
+	case CaseLower:
+		value = strings.ToLower(value)
+	}
+	return value
+}
+
+// normalizeWhiteSpace returns the value normalized by given value of the
+// whiteSpace facet, the tabs, line feeds and carriage returns are replaced by
+// spaces, and the runs of spaces are collapsed to single spaces and trimmed.
+func normalizeWhiteSpace(value, whiteSpace string) string {
+	switch whiteSpace {
+	case WhiteSpaceReplace:
+		return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(value)
+	case WhiteSpaceCollapse:
+		return strings.Join(strings.Fields(value), " ")
+	}
+	return value
+}
+
+// patternFoldsCase returns true if the pattern matches case-insensitively.
+func patternFoldsCase(re *syntax.Regexp) bool {
+	if re.Flags&syntax.FoldCase != 0 {
+		return true
+	}
+	for _, sub := range re.Sub {
+		if patternFoldsCase(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// patternLetterCase reports whether the pattern allows upper case and lower
+// case letters, character classes are checked for the ASCII letters.
+func patternLetterCase(re *syntax.Regexp) (upper, lower bool) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			upper = upper || unicode.IsUpper(r)
+			lower = lower || unicode.IsLower(r)
+		}
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			lo, hi := re.Rune[i], re.Rune[i+1]
+			upper = upper || rangesOverlap(lo, hi, 'A', 'Z')
+			lower = lower || rangesOverlap(lo, hi, 'a', 'z')
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true, true
+	}
+	for _, sub := range re.Sub {
+		subUpper, subLower := patternLetterCase(sub)
+		upper, lower = upper || subUpper, lower || subLower
+	}
+	return
+}
+
+func rangesOverlap(lo, hi, from, to rune) bool {
+	return lo <= to && hi >= from
+}
@@ -0,0 +1,218 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FacetKind is the kind of the value of a bound facet, given by the built-in
+// type the restriction derives from.
+type FacetKind int
+
+const (
+	// FacetString is the kind of the values kept lexical, such as the bounds
+	// of the dates.
+	FacetString FacetKind = iota
+	// FacetInteger is the kind of the values of the integer types.
+	FacetInteger
+	// FacetDecimal is the kind of the values of xs:decimal, and of the
+	// decimal values of the base types which aren't resolved.
+	FacetDecimal
+	// FacetFloat is the kind of the values of xs:float and xs:double.
+	FacetFloat
+)
+
+// FacetValue is the value of a bound facet typed by the base type of the
+// restriction, so the bounds of the 64-bit integers and the decimals beyond
+// the precision of the floats are kept exactly. Lexical holds the value as
+// declared, Integer the value of the integers, Decimal the value of the
+// decimals, and Float the value of the floats.
+type FacetValue struct {
+	Kind    FacetKind
+	Lexical string
+	Integer *big.Int
+	Decimal *big.Rat
+	Float   float64
+}
+
+// facetLexicalPatterns holds the lexical spaces of the numeric built-in types
+// the values of the bound facets are parsed in: the integers without
+// fraction, the decimals without exponent, and the floats with exponent and
+// the INF, -INF and NaN special values.
+var facetLexicalPatterns = map[FacetKind]*regexp.Regexp{
+	FacetInteger: regexp.MustCompile(`^[+-]?[0-9]+$`),
+	FacetDecimal: regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`),
+	FacetFloat:   regexp.MustCompile(`^([+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?|[+-]?INF|NaN)$`),
+}
+
+// facetTemporalPattern matches the values of the bound facets which look like
+// the values of the date, time and duration types, such as 2024-01-31,
+// --05, 10:00:00 and P1D.
+var facetTemporalPattern = regexp.MustCompile(`^-?P|^-?[0-9]*-|:`)
+
+// facetKinds maps the numeric built-in types to the kinds of their values.
+var facetKinds = map[string]FacetKind{
+	"integer": FacetInteger, "long": FacetInteger, "int": FacetInteger, "short": FacetInteger, "byte": FacetInteger,
+	"nonNegativeInteger": FacetInteger, "positiveInteger": FacetInteger, "nonPositiveInteger": FacetInteger, "negativeInteger": FacetInteger,
+	"unsignedLong": FacetInteger, "unsignedInt": FacetInteger, "unsignedShort": FacetInteger, "unsignedByte": FacetInteger,
+	"decimal": FacetDecimal, "float": FacetFloat, "double": FacetFloat,
+}
+
+// newFacetValue returns the value of the minInclusive, minExclusive,
+// maxInclusive or maxExclusive facets parsed in the lexical space of the
+// built-in base type, so a typo such as 1e-2 of an xs:decimal isn't taken
+// as zero. The values of the bases which aren't numeric, such as the dates,
+// are kept lexical. The bases which aren't resolved, such as the types of
+// the imported schemas, are taken as the dates by the values which look like
+// them, as the decimals by the decimal values, and as the floats without the
+// special values otherwise.
+func newFacetValue(value, base string) (FacetValue, error) {
+	lexical := strings.TrimSpace(value)
+	v := FacetValue{Lexical: lexical}
+	kind, ok := facetKinds[base]
+	switch {
+	case ok && !facetLexicalPatterns[kind].MatchString(lexical):
+		return v, fmt.Errorf("invalid xs:%s value %q", base, value)
+	case ok:
+	case base != "":
+		return v, nil
+	case facetLexicalPatterns[FacetDecimal].MatchString(lexical):
+		kind = FacetDecimal
+	case facetTemporalPattern.MatchString(lexical):
+		return v, nil
+	case !facetLexicalPatterns[FacetFloat].MatchString(lexical) || strings.HasSuffix(lexical, "INF") || lexical == "NaN":
+		return v, fmt.Errorf("invalid value %q", value)
+	default:
+		kind = FacetFloat
+	}
+	v.Kind = kind
+	switch kind {
+	case FacetInteger:
+		v.Integer, _ = new(big.Int).SetString(strings.TrimPrefix(lexical, "+"), 10)
+	case FacetDecimal:
+		v.Decimal, _ = new(big.Rat).SetString(lexical)
+	default:
+		switch lexical {
+		case "INF", "+INF":
+			v.Float = math.Inf(1)
+		case "-INF":
+			v.Float = math.Inf(-1)
+		case "NaN":
+			return v, errors.New("the NaN value isn't comparable")
+		default:
+			// The values out of the range of the floats are the infinities.
+			v.Float, _ = strconv.ParseFloat(lexical, 64)
+		}
+	}
+	return v, nil
+}
+
+// floatFacetValue returns the value of the bound by given float, for the
+// restrictions declaring their bounds by Min and Max only.
+func floatFacetValue(f float64) FacetValue {
+	return FacetValue{Kind: FacetFloat, Lexical: strconv.FormatFloat(f, 'g', -1, 64), Float: f}
+}
+
+// IsNumeric returns true if the value is a number.
+func (v FacetValue) IsNumeric() bool {
+	return v.Kind != FacetString
+}
+
+// IsInf returns true if the value is the INF or -INF special value.
+func (v FacetValue) IsInf() bool {
+	return v.Kind == FacetFloat && math.IsInf(v.Float, 0)
+}
+
+// Float64 returns the value as the nearest float, which is zero for the
+// values kept lexical.
+func (v FacetValue) Float64() float64 {
+	switch v.Kind {
+	case FacetInteger:
+		f, _ := new(big.Float).SetInt(v.Integer).Float64()
+		return f
+	case FacetDecimal:
+		f, _ := v.Decimal.Float64()
+		return f
+	}
+	return v.Float
+}
+
+// String returns the value in its canonical form: the integers and the
+// decimals without the plus sign, the leading zeros and the trailing zeros
+// of the fraction, the floats in the shortest form and the infinities as INF
+// and -INF, and the values kept lexical as declared.
+func (v FacetValue) String() string {
+	switch v.Kind {
+	case FacetInteger:
+		return v.Integer.String()
+	case FacetDecimal:
+		if v.Decimal.IsInt() {
+			return v.Decimal.Num().String()
+		}
+		return strings.TrimRight(v.Decimal.FloatString(len(v.Lexical)), "0")
+	case FacetFloat:
+		if math.IsInf(v.Float, 1) {
+			return "INF"
+		}
+		if math.IsInf(v.Float, -1) {
+			return "-INF"
+		}
+		return fmt.Sprint(v.Float)
+	}
+	return v.Lexical
+}
+
+// Exact returns the value as the integer or the decimal, and ok is false if
+// the value is a float or is kept lexical.
+func (v FacetValue) Exact() (r *big.Rat, ok bool) {
+	switch v.Kind {
+	case FacetInteger:
+		return new(big.Rat).SetInt(v.Integer), true
+	case FacetDecimal:
+		return v.Decimal, true
+	}
+	return nil, false
+}
+
+// Compare compares the lexical decimal value with the value exactly, and ok
+// is false if either of them isn't a decimal.
+func (v FacetValue) Compare(value string) (cmp int, ok bool) {
+	exact, ok := v.Exact()
+	value = strings.TrimSpace(value)
+	if !ok || !facetLexicalPatterns[FacetDecimal].MatchString(value) {
+		return 0, false
+	}
+	x, _ := new(big.Rat).SetString(value)
+	return x.Cmp(exact), true
+}
+
+// minValue returns the typed value of the lower bound of the restriction,
+// which is the float of Min if the restriction isn't parsed from a schema.
+func (r Restriction) minValue() FacetValue {
+	if r.MinValue.Lexical == "" {
+		return floatFacetValue(r.Min)
+	}
+	return r.MinValue
+}
+
+// maxValue returns the typed value of the upper bound of the restriction,
+// which is the float of Max if the restriction isn't parsed from a schema.
+func (r Restriction) maxValue() FacetValue {
+	if r.MaxValue.Lexical == "" {
+		return floatFacetValue(r.Max)
+	}
+	return r.MaxValue
+}
@@ -0,0 +1,591 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Faker produces randomized instance values for the declarations of a parsed
+// schema. The values respect the facets of the schema, and the same seed
+// produces the same sequence of values, so the instances can be used for
+// reproducible load testing.
+type Faker struct {
+	ProtoTree []interface{}
+	// MaxDepth limits the nesting of generated elements, which stops the
+	// generation on recursive types.
+	MaxDepth int
+	// MaxOccurs limits the number of generated items of repeated elements.
+	MaxOccurs int
+	rand      *rand.Rand
+}
+
+// fakeNode is the ordered representation of a generated instance value.
+type fakeNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*fakeNode
+	text     string
+}
+
+var (
+	fakerIntegerTypes = map[string]bool{
+		"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+		"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+		"i8": true, "i16": true, "i32": true, "i64": true, "i128": true, "isize": true,
+		"u8": true, "u16": true, "u32": true, "u64": true, "u128": true, "usize": true,
+		"Integer": true, "Long": true, "Short": true, "unsigned int": true,
+	}
+	fakerFloatTypes = map[string]bool{
+		"float32": true, "float64": true, "f32": true, "f64": true,
+		"Float": true, "float": true, "number": true, "Decimal": true,
+	}
+	fakerBoolTypes = map[string]bool{
+		"bool": true, "boolean": true, "Boolean": true, "XSDBoolean": true, "XsdBoolean": true,
+	}
+)
+
+// NewFaker creates a new faker for the proto tree of the parsed schema with
+// given seed.
+func NewFaker(protoTree []interface{}, seed int64) *Faker {
+	return &Faker{
+		ProtoTree: protoTree,
+		MaxDepth:  8,
+		MaxOccurs: 3,
+		rand:      rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Value generates an instance of the element or type by given name as Go
+// value. Simple values are returned as string in their lexical form, complex
+// values as map[string]interface{} keyed by the attribute and element names
+// with the character data under the "$value" key, and repeated elements as
+// []interface{}.
+func (f *Faker) Value(name string) (interface{}, error) {
+	node, err := f.node(name)
+	if err != nil {
+		return nil, err
+	}
+	return node.value(), nil
+}
+
+// XML generates an instance of the element or type by given name serialized
+// as XML document.
+func (f *Faker) XML(name string) ([]byte, error) {
+	node, err := f.node(name)
+	if err != nil {
+		return nil, err
+	}
+	return node.xml()
+}
+
+// JSON generates an instance of the element or type by given name serialized
+// as JSON document.
+func (f *Faker) JSON(name string) ([]byte, error) {
+	value, err := f.Value(name)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(value, "", "  ")
+}
+
+func (f *Faker) node(name string) (*fakeNode, error) {
+	decl := f.lookup(name)
+	if decl == nil {
+		return nil, fmt.Errorf("declaration %s not found", name)
+	}
+	if element, ok := decl.(*Element); ok {
+		return f.genNode(element.Name, element.Type, element.Restriction, 0), nil
+	}
+	return f.genNode(name, name, Restriction{}, 0), nil
+}
+
+// lookup returns the top-level declaration by given name, types take
+// precedence over elements.
+func (f *Faker) lookup(name string) interface{} {
+	name = trimNSPrefix(name)
+	var element interface{}
+	for _, ele := range f.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			if v.Name == name {
+				return v
+			}
+		case *ComplexType:
+			if v.Name == name {
+				return v
+			}
+		case *Group:
+			if v.Name == name {
+				return v
+			}
+		case *AttributeGroup:
+			if v.Name == name {
+				return v
+			}
+		case *Element:
+			if v.Name == name && element == nil {
+				element = v
+			}
+		}
+	}
+	return element
+}
+
+func (f *Faker) genNode(name, typeName string, restriction Restriction, depth int) *fakeNode {
+	node := &fakeNode{name: trimNSPrefix(name)}
+	switch v := f.lookup(typeName).(type) {
+	case *ComplexType:
+		f.fillComplexType(node, v, depth, map[*ComplexType]bool{})
+	case *SimpleType:
+		node.text = f.genSimpleType(v, depth)
+	case *Element:
+		if trimNSPrefix(v.Type) != trimNSPrefix(typeName) {
+			return f.genNode(name, v.Type, v.Restriction, depth)
+		}
+		node.text = f.genBuildInValue(v.Type, restriction)
+	default:
+		node.text = f.genBuildInValue(typeName, restriction)
+	}
+	return node
+}
+
+// fillComplexType fills the node by the complex type and its base types,
+// the visited types stop the cycles of the derivation, such as A extending
+// B extending A.
+func (f *Faker) fillComplexType(node *fakeNode, v *ComplexType, depth int, visited map[*ComplexType]bool) {
+	visited[v] = true
+	if len(v.Base) > 0 {
+		if base, ok := f.lookup(v.Base).(*ComplexType); ok {
+			if !visited[base] {
+				f.fillComplexType(node, base, depth, visited)
+			}
+		} else {
+			node.text = f.genBuildInValue(v.Base, Restriction{})
+		}
+	}
+	for _, attrGroup := range v.AttributeGroup {
+		if group, ok := f.lookup(attrGroup.Ref).(*AttributeGroup); ok {
+			f.fillAttributes(node, group.Attributes)
+		}
+	}
+	f.fillAttributes(node, v.Attributes)
+	for _, group := range v.Groups {
+		if g, ok := f.lookup(group.Ref).(*Group); ok {
+			f.fillElements(node, g.Elements, nil, depth)
+		}
+	}
+	f.fillElements(node, v.Elements, v.Choice, depth)
+}
+
+func (f *Faker) fillAttributes(node *fakeNode, attributes []Attribute) {
+	for _, attribute := range attributes {
+		if attribute.Optional && f.rand.Intn(2) == 0 {
+			continue
+		}
+		node.attrs = append(node.attrs, xml.Attr{
+			Name:  xml.Name{Local: trimNSPrefix(attribute.Name)},
+			Value: f.genNode(attribute.Name, attribute.Type, attribute.Restriction, 0).text,
+		})
+	}
+}
+
+// fillElements fills the node by the elements, exactly one element of each
+// choice of the complex type is picked, unless the choice is optional and
+// left out. The elements of the nested choices, whose alternatives can't be
+// told apart, are generated as the optional elements.
+func (f *Faker) fillElements(node *fakeNode, elements []Element, choices []Choice, depth int) {
+	if depth >= f.MaxDepth {
+		return
+	}
+	picked, counts := map[string]int{}, map[string]int{}
+	for i, element := range elements {
+		if id, _ := strconv.Atoi(element.Choice); id > 0 && id <= len(choices) && !choices[id-1].Nested {
+			if counts[element.Choice]++; f.rand.Intn(counts[element.Choice]) == 0 {
+				picked[element.Choice] = i
+			}
+		}
+	}
+	for _, choice := range choices {
+		if counts[choice.ID] > 0 && choice.Optional && f.rand.Intn(2) == 0 {
+			picked[choice.ID] = -1
+		}
+	}
+	for i, element := range elements {
+		occurs := 1
+		if element.Plural {
+			occurs = 1 + f.rand.Intn(f.MaxOccurs)
+		}
+		if j, ok := picked[element.Choice]; ok {
+			if i != j {
+				continue
+			}
+		} else if element.Optional && f.rand.Intn(2) == 0 {
+			occurs = 0
+		}
+		for i := 0; i < occurs; i++ {
+			node.children = append(node.children, f.genNode(element.Name, element.Type, element.Restriction, depth+1))
+		}
+	}
+}
+
+func (f *Faker) genSimpleType(v *SimpleType, depth int) string {
+	// The depth stops the cycles of the member types and the item types.
+	if v.Union && len(v.MemberTypes) > 0 && depth < f.MaxDepth {
+		members := toSortedPairs(v.MemberTypes)
+		member := members[f.rand.Intn(len(members))]
+		memberType := member.value
+		if memberType == "" {
+			memberType = member.key
+		}
+		return f.genNode(v.Name, memberType, Restriction{}, depth+1).text
+	}
+	if v.List && depth < f.MaxDepth {
+		items := make([]string, 1+f.rand.Intn(f.MaxOccurs))
+		for i := range items {
+			items[i] = f.genNode(v.Name, v.Base, Restriction{}, depth+1).text
+		}
+		return strings.Join(items, " ")
+	}
+	if base, ok := f.lookup(v.Base).(*SimpleType); ok && base != v {
+		restriction := v.Restriction
+		if restriction.IsEmpty() {
+			restriction = base.Restriction
+		}
+		return f.genBuildInValue(base.Base, restriction)
+	}
+	return f.genBuildInValue(v.Base, v.Restriction)
+}
+
+// genBuildInValue generates the lexical value of the build-in type which
+// respects the facets of the restriction.
+func (f *Faker) genBuildInValue(typeName string, r Restriction) string {
+	if len(r.Enum) > 0 {
+		return r.Enum[f.rand.Intn(len(r.Enum))]
+	}
+	typeName = trimNSPrefix(typeName)
+	// The bounds kept lexical, such as the bounds of the dates, times and
+	// durations, aren't numbers.
+	if (r.HasMin && !r.minValue().IsNumeric()) || (r.HasMax && !r.maxValue().IsNumeric()) {
+		if value, ok := f.genTimeValue(r); ok {
+			return value
+		}
+		if r.HasMin && !r.MinExclusive {
+			return r.MinValue.Lexical
+		}
+		if r.HasMax && !r.MaxExclusive {
+			return r.MaxValue.Lexical
+		}
+	} else if fakerIntegerTypes[typeName] || fakerFloatTypes[typeName] || isDecimalType(typeName) || r.HasMin || r.HasMax {
+		// The infinite bounds are left to the range of the samples.
+		min, max := 0.0, 1000.0
+		hasMin, hasMax := r.HasMin && !math.IsInf(r.Min, 0), r.HasMax && !math.IsInf(r.Max, 0)
+		if hasMin {
+			min = r.Min
+			if !hasMax {
+				max = min + 1000
+			}
+		}
+		if hasMax {
+			max = r.Max
+			if !hasMin {
+				min = math.Min(0, max-1000)
+			}
+		}
+		// The values have the fraction digits of the scale, and the bounds
+		// are narrowed to the values with the total digits.
+		scale, float := 0, fakerFloatTypes[typeName] || isDecimalType(typeName)
+		if float {
+			scale = 2
+			if r.FractionDigits > 0 && r.FractionDigits < scale {
+				scale = r.FractionDigits
+			}
+			if r.TotalDigits > 0 && r.TotalDigits < scale {
+				scale = r.TotalDigits
+			}
+		}
+		if r.TotalDigits > 0 {
+			limit := math.Pow10(r.TotalDigits-scale) - math.Pow10(-scale)
+			min, max = math.Max(min, -limit), math.Min(max, limit)
+		}
+		if float {
+			value := math.Max(math.Floor((min+f.rand.Float64()*(max-min))*math.Pow10(scale))/math.Pow10(scale), min)
+			if (r.MinExclusive && value <= min) || (r.MaxExclusive && value >= max) {
+				value = min + (max-min)/2
+			}
+			return strconv.FormatFloat(value, 'f', -1, 64)
+		}
+		low, high := getFakerInt64(math.Ceil(min)), getFakerInt64(math.Floor(max))
+		if r.MinExclusive && float64(low) == min && low < math.MaxInt64 {
+			low++
+		}
+		if r.MaxExclusive && float64(high) == max && high > math.MinInt64 {
+			high--
+		}
+		if high < low {
+			return strconv.FormatInt(low, 10)
+		}
+		// The span is counted in uint64, which holds the full range of the
+		// 64-bit integers, such as the range of xs:long.
+		offset, span := f.rand.Uint64(), uint64(high)-uint64(low)
+		if span < math.MaxUint64 {
+			offset %= span + 1
+		}
+		return strconv.FormatInt(low+int64(offset), 10)
+	}
+	if fakerBoolTypes[typeName] {
+		return strconv.FormatBool(f.rand.Intn(2) == 0)
+	}
+	if r.Pattern != nil {
+		if value, ok := f.genPatternValue(r.Pattern.String()); ok && r.Evaluate(value) == nil {
+			return value
+		}
+		if value, ok := genPatternSample(r.Pattern.String()); ok {
+			return value
+		}
+	}
+	minLength, maxLength := r.MinLength, r.MaxLength
+	if r.HasLength {
+		minLength, maxLength = r.Length, r.Length
+	}
+	if minLength == 0 && !r.HasLength {
+		minLength = 1
+	}
+	if (maxLength == 0 && !r.HasLength) || maxLength < minLength {
+		maxLength = minLength + 15
+	}
+	if maxLength > minLength+35 {
+		maxLength = minLength + 35
+	}
+	length := minLength + f.rand.Intn(maxLength-minLength+1)
+	letters := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	value := make([]byte, length)
+	for i := range value {
+		value[i] = letters[f.rand.Intn(len(letters))]
+	}
+	return string(value)
+}
+
+// getFakerInt64 returns the integer of the float clamped to the range of the
+// 64-bit integers.
+func getFakerInt64(f float64) int64 {
+	switch {
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
+
+// genTimeValue generates the date or the time within the bounds kept
+// lexical of the restriction, in the lexical form of the bounds, such as
+// 2024-01-31 for the bounds of xs:date. It returns false if the bounds
+// aren't the dates or the times, such as the durations.
+func (f *Faker) genTimeValue(r Restriction) (string, bool) {
+	var layout string
+	var min, max time.Time
+	for _, layouts := range facetTimeLayouts {
+		for _, l := range layouts {
+			if t, err := time.Parse(l, r.MinValue.Lexical); r.HasMin && err == nil {
+				layout, min = l, t
+			}
+			if t, err := time.Parse(l, r.MaxValue.Lexical); r.HasMax && err == nil {
+				layout, max = l, t
+			}
+		}
+		if layout != "" {
+			break
+		}
+	}
+	if layout == "" {
+		return "", false
+	}
+	switch {
+	case !r.HasMin:
+		min = max.AddDate(-1, 0, 0)
+	case !r.HasMax:
+		max = min.AddDate(1, 0, 0)
+	}
+	// The values are formatted in the layout of the bounds, which may
+	// truncate them beyond the bounds, such as the times of the day.
+	var value string
+	for i := 0; i < 8; i++ {
+		t := min
+		if span := max.Sub(min); span > 0 {
+			t = t.Add(time.Duration(f.rand.Int63n(int64(span))))
+		}
+		value = t.Format(layout)
+		if r.Evaluate(value) == nil {
+			return value, true
+		}
+	}
+	if r.HasMin && !r.MinExclusive {
+		return r.MinValue.Lexical, true
+	}
+	return value, true
+}
+
+// genPatternValue generates a random value matching the common regular
+// expression constructs of the pattern facet.
+func (f *Faker) genPatternValue(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var value strings.Builder
+	var walk func(re *syntax.Regexp) bool
+	repeat := func(re *syntax.Regexp, min, max int) bool {
+		if max < 0 {
+			max = min + 3
+		}
+		for i, n := 0, min+f.rand.Intn(max-min+1); i < n; i++ {
+			if !walk(re) {
+				return false
+			}
+		}
+		return true
+	}
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpLiteral:
+			value.WriteString(string(re.Rune))
+		case syntax.OpCharClass:
+			// The negated classes range over the characters which aren't
+			// allowed in the XML documents, such as the control characters
+			// and the surrogates.
+			ranges := getXMLCharRanges(re.Rune)
+			if len(ranges) == 0 {
+				return false
+			}
+			i := f.rand.Intn(len(ranges)/2) * 2
+			value.WriteRune(ranges[i] + rune(f.rand.Intn(int(ranges[i+1]-ranges[i])+1)))
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			value.WriteRune(rune('a' + f.rand.Intn(26)))
+		case syntax.OpCapture:
+			return walk(re.Sub[0])
+		case syntax.OpStar:
+			return repeat(re.Sub[0], 0, -1)
+		case syntax.OpPlus:
+			return repeat(re.Sub[0], 1, -1)
+		case syntax.OpQuest:
+			return repeat(re.Sub[0], 0, 1)
+		case syntax.OpRepeat:
+			return repeat(re.Sub[0], re.Min, re.Max)
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				if !walk(sub) {
+					return false
+				}
+			}
+		case syntax.OpAlternate:
+			return walk(re.Sub[f.rand.Intn(len(re.Sub))])
+		case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		default:
+			return false
+		}
+		return true
+	}
+	if !walk(re) {
+		return "", false
+	}
+	return value.String(), true
+}
+
+// xmlCharRanges holds the ranges of the characters allowed in the XML
+// documents.
+var xmlCharRanges = []rune{0x9, 0xA, 0xD, 0xD, 0x20, 0xD7FF, 0xE000, 0xFFFD, 0x10000, 0x10FFFF}
+
+// getXMLCharRanges returns the ranges of the character class restricted to
+// the characters allowed in the XML documents.
+func getXMLCharRanges(class []rune) (ranges []rune) {
+	for i := 0; i+1 < len(class); i += 2 {
+		for j := 0; j+1 < len(xmlCharRanges); j += 2 {
+			lo, hi := class[i], class[i+1]
+			if lo < xmlCharRanges[j] {
+				lo = xmlCharRanges[j]
+			}
+			if hi > xmlCharRanges[j+1] {
+				hi = xmlCharRanges[j+1]
+			}
+			if lo <= hi {
+				ranges = append(ranges, lo, hi)
+			}
+		}
+	}
+	return
+}
+
+// value converts the node into Go value.
+func (node *fakeNode) value() interface{} {
+	if len(node.attrs) == 0 && len(node.children) == 0 {
+		return node.text
+	}
+	value := map[string]interface{}{}
+	for _, attr := range node.attrs {
+		value[attr.Name.Local] = attr.Value
+	}
+	for _, child := range node.children {
+		if existing, ok := value[child.name]; ok {
+			if items, ok := existing.([]interface{}); ok {
+				value[child.name] = append(items, child.value())
+				continue
+			}
+			value[child.name] = []interface{}{existing, child.value()}
+			continue
+		}
+		value[child.name] = child.value()
+	}
+	if node.text != "" {
+		value["$value"] = node.text
+	}
+	return value
+}
+
+// xml serializes the node as XML document.
+func (node *fakeNode) xml() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "    ")
+	if err := node.encode(encoder); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encode writes the node as XML element by given encoder.
+func (node *fakeNode) encode(encoder *xml.Encoder) error {
+	start := xml.StartElement{Name: xml.Name{Local: node.name}, Attr: node.attrs}
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+	if node.text != "" {
+		if err := encoder.EncodeToken(xml.CharData(node.text)); err != nil {
+			return err
+		}
+	}
+	for _, child := range node.children {
+		if err := child.encode(encoder); err != nil {
+			return err
+		}
+	}
+	return encoder.EncodeToken(start.End())
+}
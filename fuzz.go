@@ -0,0 +1,247 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+)
+
+// fuzzFile is the path the fuzzed schema documents are parsed as, which is
+// never read, since the document is given by the source of the options.
+const fuzzFile = "fuzz.xsd"
+
+// fuzzCorpus holds the seed schema documents of the fuzz entry functions,
+// using the constructs of XML schema the parser handles.
+var fuzzCorpus = []string{
+	`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="1"/>
+      <xs:maxLength value="35"/>
+      <xs:whiteSpace value="collapse"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="CurrencyCode">
+    <xs:restriction base="xs:token">
+      <xs:length value="3"/>
+      <xs:pattern value="[A-Z]{3}"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Status">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="ACTC"/>
+      <xs:enumeration value="RJCT"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`,
+	`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal">
+      <xs:minInclusive value="0"/>
+      <xs:maxExclusive value="1000000"/>
+      <xs:totalDigits value="18"/>
+      <xs:fractionDigits value="5"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Priority">
+    <xs:restriction base="xs:int">
+      <xs:enumeration value="1"/>
+      <xs:enumeration value="2"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="CodeList">
+    <xs:list itemType="xs:string"/>
+  </xs:simpleType>
+  <xs:simpleType name="DateOrCode">
+    <xs:union memberTypes="xs:date CodeList"/>
+  </xs:simpleType>
+</xs:schema>`,
+	`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:xgen" xmlns="urn:xgen" elementFormDefault="qualified">
+  <xs:element name="Document" type="Document"/>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="Id" type="xs:string"/>
+      <xs:element name="Pty" type="Party" maxOccurs="unbounded"/>
+      <xs:choice minOccurs="0">
+        <xs:element name="Cd" type="xs:string"/>
+        <xs:element name="Prtry" type="xs:string"/>
+      </xs:choice>
+      <xs:any namespace="##other" processContents="lax" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="version" type="xs:string" use="required"/>
+    <xs:anyAttribute namespace="##other"/>
+  </xs:complexType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string" minOccurs="0"/>
+      <xs:element name="BirthDt" type="xs:date" nillable="true"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:ID"/>
+  </xs:complexType>
+</xs:schema>`,
+	`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:group name="Names">
+    <xs:sequence>
+      <xs:element name="First" type="xs:string"/>
+      <xs:element name="Last" type="xs:string"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:attributeGroup name="Audit">
+    <xs:attribute name="created" type="xs:dateTime"/>
+  </xs:attributeGroup>
+  <xs:complexType name="Person">
+    <xs:sequence>
+      <xs:group ref="Names"/>
+    </xs:sequence>
+    <xs:attributeGroup ref="Audit"/>
+  </xs:complexType>
+  <xs:complexType name="Employee">
+    <xs:complexContent>
+      <xs:extension base="Person">
+        <xs:sequence>
+          <xs:element name="Salary" type="xs:decimal"/>
+        </xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="Price">
+    <xs:simpleContent>
+      <xs:extension base="xs:decimal">
+        <xs:attribute name="Ccy" type="xs:string"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+</xs:schema>`,
+	`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Shape" type="Shape" abstract="true"/>
+  <xs:element name="Circle" type="Circle" substitutionGroup="Shape"/>
+  <xs:complexType name="Shape" abstract="true"/>
+  <xs:complexType name="Circle">
+    <xs:complexContent>
+      <xs:extension base="Shape">
+        <xs:attribute name="r" type="xs:double"/>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:element name="Ledger">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Acct" maxOccurs="unbounded">
+          <xs:complexType>
+            <xs:attribute name="id" type="xs:string"/>
+          </xs:complexType>
+        </xs:element>
+        <xs:element ref="Shape" maxOccurs="unbounded"/>
+      </xs:sequence>
+    </xs:complexType>
+    <xs:key name="AcctKey">
+      <xs:selector xpath="Acct"/>
+      <xs:field xpath="@id"/>
+    </xs:key>
+  </xs:element>
+</xs:schema>`,
+	`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:ext="urn:ext" vc:minVersion="1.1" xmlns:vc="http://www.w3.org/2007/XMLSchema-versioning">
+  <xs:import namespace="urn:ext" schemaLocation="ext.xsd"/>
+  <xs:include schemaLocation="common.xsd"/>
+  <xs:complexType name="Range">
+    <xs:annotation>
+      <xs:documentation xml:lang="en">The range of the values.</xs:documentation>
+    </xs:annotation>
+    <xs:sequence>
+      <xs:element name="Min" type="xs:int"/>
+      <xs:element name="Max" type="xs:int"/>
+      <xs:element name="Ext" type="ext:Ext" minOccurs="0"/>
+    </xs:sequence>
+    <xs:assert test="Min le Max"/>
+  </xs:complexType>
+</xs:schema>`,
+}
+
+// FuzzCorpus returns the seed corpus of the fuzz entry functions, the schema
+// documents using the constructs of XML schema the parser handles, which the
+// fuzzers mutate into the inputs.
+func FuzzCorpus() [][]byte {
+	corpus := make([][]byte, len(fuzzCorpus))
+	for i, source := range fuzzCorpus {
+		corpus[i] = []byte(source)
+	}
+	return corpus
+}
+
+// WriteFuzzCorpus writes the seed corpus of the fuzz entry functions into
+// the directory by given path, one schema document per file, as the corpus
+// directories of the fuzzers, such as go-fuzz and OSS-Fuzz, are laid out.
+func WriteFuzzCorpus(dir string) error {
+	if err := PrepareOutputDir(dir); err != nil {
+		return err
+	}
+	for i, source := range FuzzCorpus() {
+		if err := ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("seed-%d.xsd", i+1)), source, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FuzzParse is the fuzz entry function of the parser in the signature of
+// go-fuzz and OSS-Fuzz, which parses the schema document given by data into
+// the proto tree. It returns 1 if the document is parsed, and 0 otherwise,
+// so the fuzzers prefer the inputs which are parsed. The parser panics on
+// the inputs crashing it.
+func FuzzParse(data []byte) int {
+	if err := newFuzzOptions(data, true).Parse(); err != nil {
+		return 0
+	}
+	return 1
+}
+
+// FuzzGenerate is the fuzz entry function of the code generators in the
+// signature of go-fuzz and OSS-Fuzz, which parses the schema document given
+// by data and generates its code in each language into memory. It returns 1
+// if the code is generated, and 0 otherwise.
+func FuzzGenerate(data []byte) int {
+	if err := newFuzzOptions(data, false).Parse(); err != nil {
+		return 0
+	}
+	return 1
+}
+
+// newFuzzOptions returns the options parsing the schema document given by
+// data with all the file I/O stubbed: the document is read from the source,
+// the schemas it imports, includes and redefines aren't resolved, and the
+// code of the languages is kept in the artifacts unless extracted.
+func newFuzzOptions(data []byte, extract bool) *Options {
+	opt := &Options{
+		FilePath:            fuzzFile,
+		Extract:             extract,
+		Source:              data,
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+		RemoteSchema:        make(map[string][]byte),
+		MaxTokens:           1 << 16,
+		MaxDepth:            256,
+		MaxEntityExpansion:  1 << 20,
+		sourceOnly:          true,
+	}
+	if !extract {
+		for lang := range LangDirs {
+			opt.Langs = append(opt.Langs, lang)
+		}
+		sort.Strings(opt.Langs)
+		opt.Artifacts = make(map[string][]byte)
+	}
+	return opt
+}
@@ -0,0 +1,60 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build go1.18
+// +build go1.18
+
+package xgen
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzParseSchema(f *testing.F) {
+	for _, seed := range FuzzCorpus() {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		FuzzParse(data)
+	})
+}
+
+func FuzzGenerateCode(f *testing.F) {
+	for _, seed := range FuzzCorpus() {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		FuzzGenerate(data)
+	})
+}
+
+func TestFuzzCorpus(t *testing.T) {
+	for _, seed := range FuzzCorpus() {
+		assert.Equal(t, 1, FuzzParse(seed))
+		assert.Equal(t, 1, FuzzGenerate(seed))
+	}
+	assert.Equal(t, 0, FuzzParse([]byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">`)))
+
+	// The schemas imported and included by the document aren't read.
+	opt := newFuzzOptions(FuzzCorpus()[5], false)
+	require.NoError(t, opt.Parse())
+	assert.NotEmpty(t, opt.Artifacts)
+	for name := range opt.Artifacts {
+		assert.False(t, filepath.IsAbs(name), name)
+	}
+
+	dir, err := ioutil.TempDir("", "xgen-fuzz-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, WriteFuzzCorpus(filepath.Join(dir, "corpus")))
+	data, err := ioutil.ReadFile(filepath.Join(dir, "corpus", "seed-1.xsd"))
+	require.NoError(t, err)
+	assert.Equal(t, FuzzCorpus()[0], data)
+}
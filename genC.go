@@ -10,8 +10,6 @@ package xgen
 
 import (
 	"fmt"
-	"os"
-	"reflect"
 	"strings"
 )
 
@@ -39,20 +37,13 @@ var cBuildInType = map[string]bool{
 func (gen *CodeGenerator) GenC() error {
 	fieldNameCount = make(map[string]int)
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isPruned(ele) {
 			continue
 		}
-		funcName := fmt.Sprintf("C%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+		gen.genDeclaration("C", ele)
 	}
-	f, err := os.Create(gen.FileWithExtension(".h"))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	source := []byte(fmt.Sprintf("%s\n%s", copyright, gen.Field))
-	f.Write(source)
-	return err
+	source := []byte(fmt.Sprintf("%s\n%s", gen.fileHeader(), gen.Field))
+	return gen.WriteFile(gen.FileWithExtension(".h"), source)
 }
 
 func innerArray(dataType string) (string, bool) {
@@ -81,8 +72,8 @@ func genCFieldName(name string, unique bool) (fieldName string) {
 	return
 }
 
-func genCFieldType(name string) string {
-	if _, ok := cBuildInType[name]; ok {
+func (gen *CodeGenerator) genCFieldType(name string) string {
+	if _, ok := cBuildInType[name]; ok || gen.builtInTypes[name] {
 		return name
 	}
 	var fieldType string
@@ -101,11 +92,12 @@ func genCFieldType(name string) string {
 func (gen *CodeGenerator) CSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-			content := fmt.Sprintf("%s %s[];\n", genCFieldType(fieldType), genCFieldName(v.Name, false))
+			fieldType := gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+			content := fmt.Sprintf("%s %s[];\n", gen.genCFieldType(fieldType), genCFieldName(v.Name, false))
 			gen.StructAST[v.Name] = content
 			fieldName := genCFieldName(v.Name, true)
-			gen.Field += fmt.Sprintf("%stypedef %s", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name])
+			gen.addSymbol(v, genCFieldName(v.Name, false))
+			gen.Field += fmt.Sprintf("%stypedef %s", gen.genComment(fieldName, v.Doc), gen.StructAST[v.Name])
 			return
 		}
 	}
@@ -121,7 +113,7 @@ func (gen *CodeGenerator) CSimpleType(v *SimpleType) {
 				}
 				var plural, fieldType string
 				var ok bool
-				if fieldType, ok = innerArray(genCFieldType(memberType)); ok {
+				if fieldType, ok = innerArray(gen.genCFieldType(memberType)); ok {
 					plural = "[]"
 				}
 				content += fmt.Sprintf("\t%s %s%s;\n", fieldType, genCFieldName(memberName, false), plural)
@@ -129,19 +121,21 @@ func (gen *CodeGenerator) CSimpleType(v *SimpleType) {
 			content += "}"
 			gen.StructAST[v.Name] = content
 			fieldName := genCFieldName(v.Name, true)
-			gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name], fieldName)
+			gen.addSymbol(v, fieldName)
+			gen.Field += fmt.Sprintf("%stypedef %s %s;\n", gen.genComment(fieldName, v.Doc), gen.StructAST[v.Name], fieldName)
 		}
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		var plural, fieldType string
 		var ok bool
-		if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))); ok {
+		if fieldType, ok = innerArray(gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))); ok {
 			plural = "[]"
 		}
 		gen.StructAST[v.Name] = fmt.Sprintf("%s %s%s", fieldType, genCFieldName(v.Name, false), plural)
 		fieldName := genCFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%stypedef %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name])
+		gen.addSymbol(v, genCFieldName(v.Name, false))
+		gen.Field += fmt.Sprintf("%stypedef %s;\n", gen.genComment(fieldName, v.Doc), gen.StructAST[v.Name])
 	}
 }
 
@@ -149,10 +143,18 @@ func (gen *CodeGenerator) CSimpleType(v *SimpleType) {
 // syntax.
 func (gen *CodeGenerator) CComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
+		fieldName := genCFieldName(v.Name, true)
+		gen.addSymbol(v, fieldName)
+		if shared := gen.getSharedAnonymousType(v, fieldName); shared != "" {
+			// Structurally identical anonymous types share the struct.
+			gen.StructAST[v.Name] = shared
+			gen.Field += fmt.Sprintf("%stypedef %s %s;\n", gen.genComment(fieldName, v.Doc), gen.StructAST[v.Name], fieldName)
+			return
+		}
 		content := "struct {\n"
 		for _, attrGroup := range v.AttributeGroup {
 			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-			content += fmt.Sprintf("\t%s %s;\n", genCFieldType(fieldType), genCFieldName(attrGroup.Name, false))
+			content += fmt.Sprintf("\t%s %s;\n", gen.genCFieldType(fieldType), genCFieldName(attrGroup.Name, false))
 		}
 
 		for _, attribute := range v.Attributes {
@@ -162,7 +164,7 @@ func (gen *CodeGenerator) CComplexType(v *ComplexType) {
 			}
 			var plural, fieldType string
 			var ok bool
-			if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))); ok {
+			if fieldType, ok = innerArray(gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))); ok {
 				plural = "[]"
 			}
 			content += fmt.Sprintf("\t%s %sAttr%s; // attr%s\n", fieldType, genCFieldName(attribute.Name, false), plural, optional)
@@ -173,23 +175,22 @@ func (gen *CodeGenerator) CComplexType(v *ComplexType) {
 			if group.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %s%s;\n", genCFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)), genCFieldName(group.Name, false), plural)
+			content += fmt.Sprintf("\t%s %s%s;\n", gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)), genCFieldName(gen.genPluralName(group.Name, group.Plural), false), plural)
 		}
 
 		for _, element := range v.Elements {
 			var plural, fieldType string
 			var ok bool
-			if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))); ok || element.Plural {
+			if fieldType, ok = innerArray(gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))); ok || element.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %s%s;\n", fieldType, genCFieldName(element.Name, false), plural)
+			content += fmt.Sprintf("\t%s %s%s;\n", fieldType, genCFieldName(gen.genPluralName(element.Name, element.Plural), false), plural)
 		}
 		// TODO: Implement handling of v.Base for the cases of the type being a built-in one and
 		// the case of inheritance/embedding
 		content += "}"
 		gen.StructAST[v.Name] = content
-		fieldName := genCFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name], fieldName)
+		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", gen.genComment(fieldName, v.Doc), gen.StructAST[v.Name], fieldName)
 	}
 }
 
@@ -202,7 +203,7 @@ func (gen *CodeGenerator) CGroup(v *Group) {
 			if element.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %s%s;\n", genCFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)), genCFieldName(element.Name, false), plural)
+			content += fmt.Sprintf("\t%s %s%s;\n", gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)), genCFieldName(gen.genPluralName(element.Name, element.Plural), false), plural)
 		}
 
 		for _, group := range v.Groups {
@@ -210,13 +211,14 @@ func (gen *CodeGenerator) CGroup(v *Group) {
 			if group.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s %s%s;\n", genCFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)), genCFieldName(group.Name, false), plural)
+			content += fmt.Sprintf("\t%s %s%s;\n", gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)), genCFieldName(gen.genPluralName(group.Name, group.Plural), false), plural)
 		}
 
 		content += "}"
 		gen.StructAST[v.Name] = content
 		fieldName := genCFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name], fieldName)
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", gen.genComment(fieldName, v.Doc), gen.StructAST[v.Name], fieldName)
 	}
 }
 
@@ -231,7 +233,7 @@ func (gen *CodeGenerator) CAttributeGroup(v *AttributeGroup) {
 			if attribute.Optional {
 				optional = `, optional`
 			}
-			if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))); ok {
+			if fieldType, ok = innerArray(gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))); ok {
 				plural = "[]"
 			}
 			content += fmt.Sprintf("\t%s %sAttr%s; // attr%s\n", fieldType, genCFieldName(attribute.Name, false), plural, optional)
@@ -239,33 +241,58 @@ func (gen *CodeGenerator) CAttributeGroup(v *AttributeGroup) {
 		content += "}"
 		gen.StructAST[v.Name] = content
 		fieldName := genCFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name], fieldName)
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%stypedef %s %s;\n", gen.genComment(fieldName, v.Doc), gen.StructAST[v.Name], fieldName)
 	}
 }
 
 // CElement generates code for element XML schema in C language syntax.
 func (gen *CodeGenerator) CElement(v *Element) {
+	if gen.RootWrappers && isRootElement(v, gen.ProtoTree) {
+		gen.genCRootWrapper(v)
+		return
+	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		var plural, fieldType string
 		var ok bool
-		if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))); ok || v.Plural {
+		if fieldType, ok = innerArray(gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))); ok || v.Plural {
 			plural = "[]"
 		}
 		gen.StructAST[v.Name] = fmt.Sprintf("%s %s%s", fieldType, genCFieldName(v.Name, false), plural)
+		gen.addSymbol(v, genCFieldName(v.Name, false))
 		gen.Field += fmt.Sprintf("\ntypedef %s;\n", gen.StructAST[v.Name])
 	}
 }
 
+// genCRootWrapper generates the typed wrapper of the root element with the
+// name and namespace of the root element. The C declarations don't depend on
+// an XML library, so the parse_xml and to_xml functions are declared for the
+// application to implement.
+func (gen *CodeGenerator) genCRootWrapper(v *Element) {
+	wrapperName := genCFieldName(v.Name, false) + "Root"
+	if _, ok := gen.StructAST[wrapperName]; ok {
+		return
+	}
+	gen.addSymbol(v, wrapperName)
+	fieldName := genCFieldName(v.Name, false)
+	macroName := strings.ToUpper(ToSnakeCase(wrapperName))
+	gen.StructAST[wrapperName] = fmt.Sprintf("struct {\n\t%s %s;\n}", gen.genCFieldType(trimNSPrefix(v.Type)), fieldName)
+	gen.Field += fmt.Sprintf("\n#define %s_ELEMENT_NAME \"%s\"\n#define %s_NAMESPACE \"%s\"\n", macroName, v.Name, macroName, gen.TargetNamespace)
+	gen.Field += fmt.Sprintf("%stypedef %s %s;\n", gen.genComment(wrapperName, v.Doc), gen.StructAST[wrapperName], wrapperName)
+	gen.Field += fmt.Sprintf("\nint %s_parse_xml(const char *xml, %s *root);\n\nchar *%s_to_xml(const %s *root);\n", ToSnakeCase(wrapperName), wrapperName, ToSnakeCase(wrapperName), wrapperName)
+}
+
 // CAttribute generates code for attribute XML schema in C language syntax.
 func (gen *CodeGenerator) CAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		var plural, fieldType string
 		var ok bool
-		if fieldType, ok = innerArray(genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))); ok || v.Plural {
+		if fieldType, ok = innerArray(gen.genCFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))); ok || v.Plural {
 			plural = "[]"
 		}
 		gen.StructAST[v.Name] = fmt.Sprintf("%s %s%s", fieldType, genCFieldName(v.Name, false), plural)
 		fieldName := genCFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%stypedef %s;\n", genFieldComment(fieldName, v.Doc, "//"), gen.StructAST[v.Name])
+		gen.addSymbol(v, genCFieldName(v.Name, false))
+		gen.Field += fmt.Sprintf("%stypedef %s;\n", gen.genComment(fieldName, v.Doc), gen.StructAST[v.Name])
 	}
 }
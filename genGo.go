@@ -11,24 +11,104 @@ package xgen
 import (
 	"fmt"
 	"go/format"
-	"os"
-	"reflect"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 // CodeGenerator holds code generator overrides and runtime data that are used
 // when generate code from proto tree.
 type CodeGenerator struct {
-	Lang              string
-	File              string
-	Field             string
-	Package           string
-	ImportTime        bool // For Go language
-	ImportEncodingXML bool // For Go language
-	ProtoTree         []interface{}
-	StructAST         map[string]string
+	Lang               string
+	File               string
+	OutputNamer        OutputNamer
+	Field              string
+	Package            string
+	ImportTime         bool // For Go language
+	ImportEncodingXML  bool // For Go language
+	ProtoTree          []interface{}
+	StructAST          map[string]string
+	Validation         string // For Go and Rust language
+	ValidationCode     string // For Go and Rust language
+	Normalize          bool   // For Go and Rust language
+	ValidationMaxDepth int    // For Go and Rust language
+	Lenient            bool   // For Go and Rust language
+	AssertFallback     string // For Go and Rust language
+	Artifacts          map[string][]byte
+	Provenance         *Provenance
+	RootWrappers       bool
+	TargetNamespace    string
+	SchemaVersion      string
+	PruneUnused        bool
+	TypeAliases        bool // For Go and Rust language
+	CommentStyle       string
+	CommentWidth       int
+	AnyTypeFallback    string
+	Mixins             bool              // For Go, Java and Rust language
+	BooleanForm        string            // For Go and Rust language
+	DecimalForm        string            // For Go and Rust language
+	NamespacePrefixes  map[string]string // For Go and Rust language
+	Accessors          bool              // For Java, Rust and TypeScript language
+	PatchTypes         bool              // For Go and Rust language
+	Visitor            bool              // For Go and Rust language
+	Equality           bool              // For Go and Rust language
+	PrettyXML          bool              // For Go and Rust language
+	ValidationTracing  bool              // For Go and Rust language
+	RenameCase         string            // For Rust language
+	ChoiceEnums        bool              // For Rust language
+	ChoiceRepr         string            // For Rust language
+	ChoiceTag          string            // For Rust language
+	JSONValue          bool              // For Rust language
+	RustDecimal        bool              // For Rust language
+	RustChrono         bool              // For Rust language
+	RustErrorType      string            // For Rust language
+	RustUses           []string          // For Rust language
+	RustDerives        []RustDerive      // For Rust language
+	RustModules        bool              // For Rust language
+	ValidateTags       bool              // For Go language
+	SQLMethods         bool              // For Go language
+	JSONSchemas        bool              // For Go, Rust and TypeScript language
+	JavaProject        string            // For Java language
+	PluralNames        bool
+	PluralOverrides    map[string]string
+	SymbolMap          bool
+	SizeReport         bool
+	ExternalTypes      ExternalTypes
+
+	builtInTypes     map[string]bool
+	reachable        map[interface{}]bool
+	anonymousTypes   map[string]string
+	mixinCode        string
+	visitorTypes     []visitorType
+	equalTypes       []equalType
+	choiceEnums      map[string]bool
+	validatePatterns map[string]string
+	symbols          []Symbol
+	typeSizes        []TypeSize
+	fileSizes        []FileSize
+	rustFieldTypes   map[string][]string
+	rustBoxedFields  map[string]map[string]bool
+	rustDeclarations []rustDeclaration
+	// unsupportedAsserts holds the assertions which can't be translated
+	// to the validation code, reported by the assert fallback.
+	unsupportedAsserts []string
+	// unsupportedUniques holds the identity constraints which can't be
+	// checked by the validation code, reported by the warnings.
+	unsupportedUniques []string
 }
 
+// Validation modes of the code generator. In method mode the validation
+// code is generated as methods on the data types, in standalone mode as
+// functions in a separate validator file, which keeps the data models free
+// of validation dependencies.
+const (
+	ValidationNone       = ""
+	ValidationMethod     = "method"
+	ValidationStandalone = "standalone"
+)
+
 var goBuildinType = map[string]bool{
 	"xml.Name":      true,
 	"byte":          true,
@@ -61,24 +141,38 @@ var goBuildinType = map[string]bool{
 func (gen *CodeGenerator) GenGo() error {
 	fieldNameCount = make(map[string]int)
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isPruned(ele) {
 			continue
 		}
-		funcName := fmt.Sprintf("Go%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+		gen.genDeclaration("Go", ele)
 	}
-	f, err := os.Create(gen.FileWithExtension(".go"))
-	if err != nil {
+	if err := gen.checkAsserts(); err != nil {
 		return err
 	}
-	defer f.Close()
+	gen.genGoVisitor()
+	gen.genGoEqual()
+	gen.Field += gen.genGoValidatePatterns() + genGoPatternInit(gen.Field)
 	var importPackage, packages string
+	// The any type fallback may be a type of the standard packages.
+	if strings.HasPrefix(gen.AnyTypeFallback, "xml.") && strings.Contains(gen.Field, gen.AnyTypeFallback) {
+		gen.ImportEncodingXML = true
+	}
+	if strings.HasPrefix(gen.AnyTypeFallback, "json.") && strings.Contains(gen.Field, gen.AnyTypeFallback) {
+		packages += "\t\"encoding/json\"\n"
+	}
 	if gen.ImportTime {
 		packages += "\t\"time\"\n"
 	}
+	packages += gen.genGoExternalImports(gen.Field)
 	if gen.ImportEncodingXML {
 		packages += "\t\"encoding/xml\"\n"
 	}
+	if gen.Validation == ValidationMethod || (gen.Normalize && gen.Validation != ValidationStandalone) || gen.Equality {
+		packages += genGoValidationImports(gen.Field)
+	}
+	if hasGoSQLMethods(gen.Field) {
+		packages += "\t\"database/sql/driver\"\n"
+	}
 	if packages != "" {
 		importPackage = fmt.Sprintf("import (\n%s)", packages)
 	}
@@ -86,15 +180,95 @@ func (gen *CodeGenerator) GenGo() error {
 	if packageName == "" {
 		packageName = "schema"
 	}
-	source, err := format.Source([]byte(fmt.Sprintf("%s\n\npackage %s\n%s%s", copyright, packageName, importPackage, gen.Field)))
+	source, err := format.Source([]byte(fmt.Sprintf("%s\n\npackage %s\n%s%s", gen.fileHeader(), packageName, importPackage, gen.Field)))
 	if err != nil {
-		f.WriteString(fmt.Sprintf("package %s\n%s%s", packageName, importPackage, gen.Field))
+		gen.WriteFile(gen.FileWithExtension(".go"), []byte(fmt.Sprintf("package %s\n%s%s", packageName, importPackage, gen.Field)))
+		return err
+	}
+	if err = gen.WriteFile(gen.FileWithExtension(".go"), source); err != nil {
 		return err
 	}
-	f.Write(source)
+	if gen.Validation != ValidationNone && gen.ValidationMaxDepth > 0 {
+		if err = gen.genGoValidationDepthError(packageName); err != nil {
+			return err
+		}
+	}
+	if gen.Validation != ValidationNone && gen.ValidationTracing {
+		if err = gen.genGoValidationTraceFiles(packageName); err != nil {
+			return err
+		}
+	}
+	if gen.BooleanForm != BooleanFormNative && strings.Contains(gen.Field, booleanTypes["Go"]) {
+		if err = gen.genGoBoolean(packageName); err != nil {
+			return err
+		}
+	}
+	if strings.Contains(gen.Field, "XSDAnyElement") {
+		if err = gen.genGoAnyElement(packageName); err != nil {
+			return err
+		}
+	}
+	if strings.Contains(gen.Field, "prefixXMLNamespace(") {
+		if err = gen.genGoXMLNamespace(packageName); err != nil {
+			return err
+		}
+	}
+	if strings.Contains(gen.Field, "prettyXML(") {
+		if err = gen.genGoPrettyXML(packageName); err != nil {
+			return err
+		}
+	}
+	if gen.DecimalForm != DecimalFormNative {
+		if scales := getDecimalScales(decimalTypes["Go"], gen.Field); len(scales) > 0 {
+			if err = gen.genGoDecimal(packageName, scales); err != nil {
+				return err
+			}
+		}
+	}
+	if len(gen.validatePatterns) > 0 {
+		if err = gen.genGoValidateRegistration(packageName); err != nil {
+			return err
+		}
+	}
+	if hasGoSQLMethods(gen.Field) {
+		if err = gen.genGoSQL(packageName); err != nil {
+			return err
+		}
+	}
+	if strings.Contains(gen.Field+gen.ValidationCode, "xsdPatterns[") {
+		if err = gen.genGoPatterns(packageName); err != nil {
+			return err
+		}
+	}
+	if strings.Contains(gen.Field+gen.ValidationCode, "xsdTotalDigits(") || strings.Contains(gen.Field+gen.ValidationCode, "xsdFractionDigits(") {
+		if err = gen.genGoDigits(packageName); err != nil {
+			return err
+		}
+	}
+	if gen.Validation == ValidationStandalone {
+		return gen.genGoValidator(packageName)
+	}
 	return err
 }
 
+// genGoValidator writes the validation functions for the generated types
+// into a standalone validator file, which keeps the generated types free of
+// validation code. The file declares the package of the types, so it's
+// compiled with them and calls their unexported helpers.
+func (gen *CodeGenerator) genGoValidator(packageName string) error {
+	gen.ValidationCode += genGoPatternInit(gen.ValidationCode)
+	var importPackage string
+	if packages := genGoValidationImports(gen.ValidationCode); packages != "" {
+		importPackage = fmt.Sprintf("import (\n%s)", packages)
+	}
+	source, err := format.Source([]byte(fmt.Sprintf("%s\n\npackage %s\n%s%s", gen.fileHeader(), packageName, importPackage, gen.ValidationCode)))
+	if err != nil {
+		gen.WriteFile(gen.FileWithExtension(".validator.go"), []byte(fmt.Sprintf("package %s\n%s%s", packageName, importPackage, gen.ValidationCode)))
+		return err
+	}
+	return gen.WriteFile(gen.FileWithExtension(".validator.go"), source)
+}
+
 func genGoFieldName(name string, unique bool) (fieldName string) {
 	for _, str := range strings.Split(name, ":") {
 		fieldName += MakeFirstUpperCase(str)
@@ -114,8 +288,8 @@ func genGoFieldName(name string, unique bool) (fieldName string) {
 	return
 }
 
-func genGoFieldType(name string) string {
-	if _, ok := goBuildinType[name]; ok {
+func (gen *CodeGenerator) genGoFieldType(name string) string {
+	if _, ok := goBuildinType[name]; ok || gen.builtInTypes[name] {
 		return name
 	}
 	var fieldType string
@@ -129,19 +303,30 @@ func genGoFieldType(name string) string {
 	return "interface{}"
 }
 
+// isTypeAlias returns true if the simple type is generated as an alias of
+// its base type instead of a new type by the type aliases option, which
+// applies to the restrictions without any facet.
+func (gen *CodeGenerator) isTypeAlias(v *SimpleType) bool {
+	return gen.TypeAliases && !v.List && !v.Union && v.Restriction.IsEmpty()
+}
+
 // GoSimpleType generates code for simple type XML schema in Go language
 // syntax.
 func (gen *CodeGenerator) GoSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+			fieldType := gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
 			if fieldType == "time.Time" {
 				gen.ImportTime = true
 			}
-			content := fmt.Sprintf(" []%s\n", genGoFieldType(fieldType))
+			content := fmt.Sprintf(" []%s\n", gen.genGoFieldType(fieldType))
 			gen.StructAST[v.Name] = content
 			fieldName := genGoFieldName(v.Name, true)
-			gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			gen.addSymbol(v, fieldName)
+			gen.Field += fmt.Sprintf("%stype %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
+			if body := gen.genGoListValidation(v, fieldName); body != "" {
+				gen.genGoValidationCode(fieldName, body)
+			}
 			return
 		}
 	}
@@ -149,6 +334,7 @@ func (gen *CodeGenerator) GoSimpleType(v *SimpleType) {
 		if _, ok := gen.StructAST[v.Name]; !ok {
 			content := " struct {\n"
 			fieldName := genGoFieldName(v.Name, true)
+			gen.addSymbol(v, fieldName)
 			if fieldName != v.Name {
 				gen.ImportEncodingXML = true
 				content += fmt.Sprintf("\tXMLName\txml.Name\t`xml:\"%s\"`\n", v.Name)
@@ -160,19 +346,34 @@ func (gen *CodeGenerator) GoSimpleType(v *SimpleType) {
 				if memberType == "" { // fix order issue
 					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
 				}
-				content += fmt.Sprintf("\t%s\t%s\n", genGoFieldName(memberName, false), genGoFieldType(memberType))
+				content += fmt.Sprintf("\t%s\t%s\n", genGoFieldName(memberName, false), gen.genGoFieldType(memberType))
 			}
 			content += "}\n"
 			gen.StructAST[v.Name] = content
-			gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			gen.Field += fmt.Sprintf("%stype %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
+			gen.addVisitorType(fieldName, content)
 		}
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := fmt.Sprintf(" %s\n", genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)))
+		fieldType := gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+		content := fmt.Sprintf(" %s\n", fieldType)
+		if gen.isTypeAlias(v) {
+			// Methods can't be declared on the alias, and there are no facets
+			// to check.
+			content = fmt.Sprintf(" = %s\n", fieldType)
+		}
 		gen.StructAST[v.Name] = content
 		fieldName := genGoFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%stype %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
+		if gen.isGoBuiltInType(fieldType) && !gen.isTypeAlias(v) {
+			gen.genGoValidationCode(fieldName, gen.genGoFacetChecks(fieldName, fieldType, fmt.Sprintf("%s(*v)", fieldType), "*v", &v.Restriction))
+			if expr := genGoNormalizeExpr(fieldType, "string(*v)", &v.Restriction); expr != "" {
+				gen.genGoNormalizeCode(fieldName, fmt.Sprintf("*v = %s(%s)\n", fieldName, expr))
+			}
+			gen.Field += gen.genGoSQLMethods(fieldName, fieldType)
+		}
 	}
 }
 
@@ -182,16 +383,32 @@ func (gen *CodeGenerator) GoComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := " struct {\n"
 		fieldName := genGoFieldName(v.Name, true)
+		gen.addSymbol(v, fieldName)
+		// The struct with an XMLName field can't be shared, since the field
+		// holds the element name.
+		if fieldName == v.Name {
+			if shared := gen.getSharedAnonymousType(v, fieldName); shared != "" {
+				gen.genGoTypeAlias(v, fieldName, shared)
+				return
+			}
+		}
 		if fieldName != v.Name {
 			gen.ImportEncodingXML = true
 			content += fmt.Sprintf("\tXMLName\txml.Name\t`xml:\"%s\"`\n", v.Name)
 		}
+		var validation, normalize string
 		for _, attrGroup := range v.AttributeGroup {
 			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
 			if fieldType == "time.Time" {
 				gen.ImportTime = true
 			}
-			content += fmt.Sprintf("\t%s\t%s\n", genGoFieldName(attrGroup.Name, false), genGoFieldType(fieldType))
+			if gen.Mixins {
+				content += gen.genGoMixinField(fieldType, &validation, &normalize)
+				continue
+			}
+			content += fmt.Sprintf("\t%s\t%s\n", genGoFieldName(attrGroup.Name, false), gen.genGoFieldType(fieldType))
+			validation += gen.genGoFieldValidation(genGoFieldName(attrGroup.Name, false), fieldType, false, false, nil)
+			normalize += gen.genGoFieldNormalize(genGoFieldName(attrGroup.Name, false), fieldType, false, nil)
 		}
 
 		for _, attribute := range v.Attributes {
@@ -199,18 +416,28 @@ func (gen *CodeGenerator) GoComplexType(v *ComplexType) {
 			if attribute.Optional {
 				optional = `,omitempty`
 			}
-			fieldType := genGoFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
+			fieldType := gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
 			if fieldType == "time.Time" {
 				gen.ImportTime = true
 			}
-			content += fmt.Sprintf("\t%sAttr\t%s\t`xml:\"%s,attr%s\"`\n", genGoFieldName(attribute.Name, false), fieldType, attribute.Name, optional)
+			content += genGoDeprecatedDoc(attribute.Deprecated, attribute.Deprecation) + genGoCustomTags(fmt.Sprintf("\t%sAttr\t%s\t`xml:\"%s,attr%s\"%s%s`\n", genGoFieldName(attribute.Name, false), gen.genGoOptionalFieldType(fieldType, attribute.Plural, attribute.Optional), attribute.Name, optional, genGoSensitiveTag(attribute.Sensitive), gen.genGoValidateTag(fieldType, attribute.Plural, attribute.Optional, &attribute.Restriction)), attribute.Custom.GoTags)
+			validation += gen.genGoRequiredValidation(genGoFieldName(attribute.Name, false)+"Attr", getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute)
+			validation += gen.genGoFieldValidation(genGoFieldName(attribute.Name, false)+"Attr", getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural, attribute.Optional, &attribute.Restriction)
+			normalize += gen.genGoFieldNormalize(genGoFieldName(attribute.Name, false)+"Attr", getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural, &attribute.Restriction)
 		}
 		for _, group := range v.Groups {
+			if gen.Mixins && !group.Plural {
+				content += gen.genGoMixinField(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), &validation, &normalize)
+				continue
+			}
 			var plural string
 			if group.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\n", genGoFieldName(group.Name, false), plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)))
+			memberName := genGoFieldName(gen.genPluralName(group.Name, group.Plural), false)
+			content += fmt.Sprintf("\t%s\t%s%s%s\n", memberName, plural, gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)), genGoPluralTag(memberName, group.Name, ""))
+			validation += gen.genGoFieldValidation(memberName, getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural, false, nil)
+			normalize += gen.genGoFieldNormalize(memberName, getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural, nil)
 		}
 
 		for _, element := range v.Elements {
@@ -218,31 +445,76 @@ func (gen *CodeGenerator) GoComplexType(v *ComplexType) {
 			if element.Plural {
 				plural = "[]"
 			}
-			fieldType := genGoFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
+			fieldType := gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
 			if fieldType == "time.Time" {
 				gen.ImportTime = true
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\t`xml:\"%s\"`\n", genGoFieldName(element.Name, false), plural, fieldType, element.Name)
+			memberName := genGoFieldName(gen.genPluralName(element.Name, element.Plural), false)
+			content += genGoDeprecatedDoc(element.Deprecated, element.Deprecation) + genGoCustomTags(fmt.Sprintf("\t%s\t%s%s\t`xml:\"%s\"%s%s`\n", memberName, plural, gen.genGoOptionalFieldType(fieldType, element.Plural, element.Optional), gen.getGoElementTagName(element, v.Elements), genGoSensitiveTag(element.Sensitive), gen.genGoValidateTag(fieldType, element.Plural, element.Optional, &element.Restriction)), element.Custom.GoTags)
+			validation += gen.genGoRequiredElementValidation(memberName, getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element)
+			validation += gen.genGoFieldValidation(memberName, getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural, element.Optional, &element.Restriction)
+			normalize += gen.genGoFieldNormalize(memberName, getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural, &element.Restriction)
 		}
+		content += gen.genGoWildcardFields(v.Any, v.AnyAttribute)
+		validation += gen.genGoWildcardValidation(v.AnyAttribute, v.AnyAttributeNamespace)
+		content += gen.genGoMixedField(v)
 		if len(v.Base) > 0 {
 			// If the type is a built-in type, generate a Value field as chardata.
 			// If it's not built-in one, embed the base type in the struct for the child type
 			// to effectively inherit all of the base type's fields
-			if isGoBuiltInType(v.Base) {
-				content += fmt.Sprintf("\tValue\t%s\t`xml:\",chardata\"`\n", genGoFieldType(v.Base))
+			if gen.isGoBuiltInType(v.Base) {
+				content += fmt.Sprintf("\tValue\t%s\t`xml:\",chardata\"`\n", gen.genGoFieldType(v.Base))
 			} else {
-				content += fmt.Sprintf("\t%s\n", genGoFieldType(v.Base))
+				content += fmt.Sprintf("\t%s\n", gen.genGoFieldType(v.Base))
+				validation += gen.genGoFieldValidation(strings.TrimPrefix(gen.genGoFieldType(v.Base), "*"), v.Base, false, false, nil)
+				normalize += gen.genGoFieldNormalize(strings.TrimPrefix(gen.genGoFieldType(v.Base), "*"), v.Base, false, nil)
 			}
 		}
+		validation += gen.genGoUniqueValidation(v.Name)
+		validation += gen.genGoAssertValidation(v, v.Name)
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%stype %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
+		gen.genGoValidationCode(fieldName, validation)
+		gen.genGoNormalizeCode(fieldName, normalize)
+		gen.genGoPatch(fieldName, content)
+		gen.addVisitorType(fieldName, content)
+		gen.addEqualType(fieldName, content, gen.getGoFieldRestrictions(v.Elements, v.Attributes))
 	}
 }
 
-func isGoBuiltInType(typeName string) bool {
-	_, builtIn := goBuildinType[typeName]
-	return builtIn
+// genGoMixinField generates the embedded field of the attribute group or
+// group by given type in mixin mode, which promotes the fields of the
+// group into the struct embedding it, and appends the validation and
+// normalize code of the field.
+func (gen *CodeGenerator) genGoMixinField(typeName string, validation, normalize *string) string {
+	fieldType := gen.genGoFieldType(typeName)
+	*validation += gen.genGoFieldValidation(strings.TrimPrefix(fieldType, "*"), typeName, false, false, nil)
+	*normalize += gen.genGoFieldNormalize(strings.TrimPrefix(fieldType, "*"), typeName, false, nil)
+	return fmt.Sprintf("\t%s\n", fieldType)
+}
+
+// genGoTypeAlias generates the anonymous complex type as an alias of the
+// structurally identical type generated before, which shares its methods.
+// The standalone validation and normalize functions of the alias forward to
+// the ones of the shared type.
+func (gen *CodeGenerator) genGoTypeAlias(v *ComplexType, typeName, shared string) {
+	gen.StructAST[v.Name] = fmt.Sprintf(" = %s\n", shared)
+	gen.Field += fmt.Sprintf("%stype %s%s", gen.genComment(typeName, v.Doc), typeName, gen.StructAST[v.Name])
+	if gen.Validation != ValidationStandalone {
+		return
+	}
+	gen.ValidationCode += fmt.Sprintf("\n// Validate%s checks the %s against the facets of the schema.\nfunc Validate%s(v *%s) error {\nreturn Validate%s(v)\n}\n", typeName, typeName, typeName, typeName, shared)
+	if gen.ValidationMaxDepth > 0 {
+		gen.ValidationCode += fmt.Sprintf("\n// validate%s checks the %s at given nesting depth of the document.\nfunc validate%s(v *%s, depth int) error {\nreturn validate%s(v, depth)\n}\n", typeName, typeName, typeName, typeName, shared)
+	}
+	if gen.Normalize {
+		gen.ValidationCode += fmt.Sprintf("\n// Normalize%s converts the %s into the canonical form implied by the\n// facets of the schema.\nfunc Normalize%s(v *%s) {\nNormalize%s(v)\n}\n", typeName, typeName, typeName, typeName, shared)
+	}
+}
+
+func (gen *CodeGenerator) isGoBuiltInType(typeName string) bool {
+	return goBuildinType[typeName] || gen.builtInTypes[typeName]
 }
 
 // GoGroup generates code for group XML schema in Go language syntax.
@@ -250,29 +522,49 @@ func (gen *CodeGenerator) GoGroup(v *Group) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := " struct {\n"
 		fieldName := genGoFieldName(v.Name, true)
-		if fieldName != v.Name {
+		gen.addSymbol(v, fieldName)
+		// The embedded struct can't hold the element name of the struct
+		// embedding it.
+		if fieldName != v.Name && !gen.Mixins {
 			gen.ImportEncodingXML = true
 			content += fmt.Sprintf("\tXMLName\txml.Name\t`xml:\"%s\"`\n", v.Name)
 		}
+		var validation, normalize string
 		for _, element := range v.Elements {
 			var plural string
 			if element.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\n", genGoFieldName(element.Name, false), plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)))
+			memberName := genGoFieldName(gen.genPluralName(element.Name, element.Plural), false)
+			fieldType := gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
+			content += genGoDeprecatedDoc(element.Deprecated, element.Deprecation) + genGoCustomTags(fmt.Sprintf("\t%s\t%s%s%s\n", memberName, plural, gen.genGoOptionalFieldType(fieldType, element.Plural, element.Optional), genGoPluralTag(memberName, gen.getGoElementTagName(element, v.Elements), genGoSensitiveTag(element.Sensitive)+gen.genGoValidateTag(fieldType, element.Plural, element.Optional, &element.Restriction))), element.Custom.GoTags)
+			validation += gen.genGoRequiredElementValidation(memberName, getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element)
+			validation += gen.genGoFieldValidation(memberName, getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural, element.Optional, &element.Restriction)
+			normalize += gen.genGoFieldNormalize(memberName, getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural, &element.Restriction)
 		}
 
 		for _, group := range v.Groups {
+			if gen.Mixins && !group.Plural {
+				content += gen.genGoMixinField(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), &validation, &normalize)
+				continue
+			}
 			var plural string
 			if group.Plural {
 				plural = "[]"
 			}
-			content += fmt.Sprintf("\t%s\t%s%s\n", genGoFieldName(group.Name, false), plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)))
+			memberName := genGoFieldName(gen.genPluralName(group.Name, group.Plural), false)
+			content += fmt.Sprintf("\t%s\t%s%s%s\n", memberName, plural, gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)), genGoPluralTag(memberName, group.Name, ""))
+			validation += gen.genGoFieldValidation(memberName, getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural, false, nil)
+			normalize += gen.genGoFieldNormalize(memberName, getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural, nil)
 		}
-
+		content += gen.genGoWildcardFields(v.Any, false)
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%stype %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
+		gen.genGoValidationCode(fieldName, validation)
+		gen.genGoNormalizeCode(fieldName, normalize)
+		gen.addVisitorType(fieldName, content)
+		gen.addEqualType(fieldName, content, gen.getGoFieldRestrictions(v.Elements, nil))
 	}
 }
 
@@ -282,35 +574,100 @@ func (gen *CodeGenerator) GoAttributeGroup(v *AttributeGroup) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := " struct {\n"
 		fieldName := genGoFieldName(v.Name, true)
-		if fieldName != v.Name {
+		gen.addSymbol(v, fieldName)
+		if fieldName != v.Name && !gen.Mixins {
 			gen.ImportEncodingXML = true
 			content += fmt.Sprintf("\tXMLName\txml.Name\t`xml:\"%s\"`\n", v.Name)
 		}
+		var validation, normalize string
 		for _, attribute := range v.Attributes {
 			var optional string
 			if attribute.Optional {
 				optional = `,omitempty`
 			}
-			content += fmt.Sprintf("\t%sAttr\t%s\t`xml:\"%s,attr%s\"`\n", genGoFieldName(attribute.Name, false), genGoFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)), attribute.Name, optional)
+			fieldType := gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
+			content += genGoDeprecatedDoc(attribute.Deprecated, attribute.Deprecation) + genGoCustomTags(fmt.Sprintf("\t%sAttr\t%s\t`xml:\"%s,attr%s\"%s%s`\n", genGoFieldName(attribute.Name, false), gen.genGoOptionalFieldType(fieldType, attribute.Plural, attribute.Optional), attribute.Name, optional, genGoSensitiveTag(attribute.Sensitive), gen.genGoValidateTag(fieldType, attribute.Plural, attribute.Optional, &attribute.Restriction)), attribute.Custom.GoTags)
+			validation += gen.genGoRequiredValidation(genGoFieldName(attribute.Name, false)+"Attr", getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute)
+			validation += gen.genGoFieldValidation(genGoFieldName(attribute.Name, false)+"Attr", getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural, attribute.Optional, &attribute.Restriction)
+			normalize += gen.genGoFieldNormalize(genGoFieldName(attribute.Name, false)+"Attr", getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural, &attribute.Restriction)
 		}
+		content += gen.genGoWildcardFields(false, v.AnyAttribute)
+		validation += gen.genGoWildcardValidation(v.AnyAttribute, v.AnyAttributeNamespace)
 		content += "}\n"
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%stype %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
+		gen.genGoValidationCode(fieldName, validation)
+		gen.genGoNormalizeCode(fieldName, normalize)
+		gen.addVisitorType(fieldName, content)
+		gen.addEqualType(fieldName, content, gen.getGoFieldRestrictions(nil, v.Attributes))
 	}
 }
 
 // GoElement generates code for element XML schema in Go language syntax.
 func (gen *CodeGenerator) GoElement(v *Element) {
+	defer gen.genJSONSchemaConstant(v)
+	if gen.RootWrappers && isRootElement(v, gen.ProtoTree) {
+		gen.genGoRootWrapper(v)
+		return
+	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		var plural string
 		if v.Plural {
 			plural = "[]"
 		}
-		content := fmt.Sprintf("\t%s%s\n", plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree)))
+		content := fmt.Sprintf("\t%s%s\n", plural, gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree)))
 		gen.StructAST[v.Name] = content
 		fieldName := genGoFieldName(v.Name, false)
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%stype %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
+	}
+}
+
+// genGoRootWrapper generates the typed wrapper of the root element, which
+// embeds the type of the element and binds the XML name, with the ParseXML
+// and ToXML methods for decoding and encoding the XML documents, the
+// ToPrettyXML method with the pretty XML option, and the validation code
+// validating the element.
+func (gen *CodeGenerator) genGoRootWrapper(v *Element) {
+	wrapperName := genGoFieldName(v.Name, false) + "Root"
+	if _, ok := gen.StructAST[wrapperName]; ok {
+		return
 	}
+	gen.ImportEncodingXML = true
+	gen.addSymbol(v, wrapperName)
+	xmlName := v.Name
+	if gen.TargetNamespace != "" {
+		xmlName = gen.TargetNamespace + " " + v.Name
+	}
+	fieldType := strings.TrimPrefix(gen.genGoFieldType(trimNSPrefix(v.Type)), "*")
+	gen.StructAST[wrapperName] = fmt.Sprintf("\tXMLName\txml.Name\t`xml:\"%s\"`\n\t%s\n", xmlName, fieldType)
+	gen.Field += fmt.Sprintf("%stype %s struct {\n%s}\n", gen.genComment(wrapperName, v.Doc), wrapperName, gen.StructAST[wrapperName])
+	gen.addVisitorType(wrapperName, gen.StructAST[wrapperName])
+	gen.Field += fmt.Sprintf("\n// ParseXML decodes the XML document with the %s root element.\nfunc (v *%s) ParseXML(data []byte) error {\n\treturn xml.Unmarshal(data, v)\n}\n", v.Name, wrapperName)
+	if prefix := gen.getNamespacePrefix(); prefix != "" {
+		gen.Field += fmt.Sprintf("\n// ToXML encodes the XML document with the %s root element, the elements of\n// the namespace are written with the %s prefix.\nfunc (v *%s) ToXML() ([]byte, error) {\n\tdata, err := xml.Marshal(v)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn prefixXMLNamespace(data, \"%s\", \"%s\")\n}\n", v.Name, prefix, wrapperName, prefix, gen.TargetNamespace)
+	} else {
+		gen.Field += fmt.Sprintf("\n// ToXML encodes the XML document with the %s root element.\nfunc (v *%s) ToXML() ([]byte, error) {\n\treturn xml.Marshal(v)\n}\n", v.Name, wrapperName)
+	}
+	gen.Field += gen.genGoPrettyMethod(wrapperName, v.Name)
+	if gen.Validation != ValidationNone && gen.goHasValidator(trimNSPrefix(v.Type)) {
+		gen.genGoValidationCode(wrapperName, gen.genGoRootWrapperValidation(fieldType))
+	}
+}
+
+// genGoRootWrapperValidation generate the validation code of the root element
+// wrapper for Go code, which validates the embedded type of the element.
+func (gen *CodeGenerator) genGoRootWrapperValidation(fieldType string) string {
+	call := fmt.Sprintf("v.%s.Validate()", fieldType)
+	switch {
+	case gen.ValidationMaxDepth > 0 && gen.Validation == ValidationStandalone:
+		call = fmt.Sprintf("validate%s(&v.%s, depth)", fieldType, fieldType)
+	case gen.ValidationMaxDepth > 0:
+		call = fmt.Sprintf("v.%s.validate(depth)", fieldType)
+	case gen.Validation == ValidationStandalone:
+		call = fmt.Sprintf("Validate%s(&v.%s)", fieldType, fieldType)
+	}
+	return fmt.Sprintf("if err := %s; err != nil {\nreturn err\n}\n", call)
 }
 
 // GoAttribute generates code for attribute XML schema in Go language syntax.
@@ -320,19 +677,934 @@ func (gen *CodeGenerator) GoAttribute(v *Attribute) {
 		if v.Plural {
 			plural = "[]"
 		}
-		content := fmt.Sprintf("\t%s%s\n", plural, genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree)))
+		content := fmt.Sprintf("\t%s%s\n", plural, gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree)))
 		gen.StructAST[v.Name] = content
 		fieldName := genGoFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%stype %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%stype %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
+	}
+}
+
+// WriteFile writes the generated code into the file by given name, or keeps
+// it in the artifacts when the in-memory output is used.
+func (gen *CodeGenerator) WriteFile(name string, data []byte) error {
+	if gen.Provenance != nil {
+		gen.Provenance.Sources[0].Outputs = append(gen.Provenance.Sources[0].Outputs, name)
+	}
+	gen.addFileSize(name, data)
+	if gen.Artifacts != nil {
+		gen.Artifacts[name] = data
+		return nil
+	}
+	return ioutil.WriteFile(name, data, 0644)
+}
+
+// genGoValidationImports returns the import packages required by the given
+// validation code.
+func genGoValidationImports(code string) (packages string) {
+	for _, pkg := range []string{"errors", "fmt", "math", "reflect", "regexp", "strings", "unicode/utf8"} {
+		if strings.Contains(code, pkg[strings.LastIndex(pkg, "/")+1:]+".") {
+			packages += fmt.Sprintf("\t\"%s\"\n", pkg)
+		}
+	}
+	return
+}
+
+// genGoValidationCode generate validation code for the type with given
+// validation body in Go language syntax.
+func (gen *CodeGenerator) genGoValidationCode(typeName, body string) {
+	if gen.ValidationMaxDepth > 0 {
+		gen.genGoDepthValidationCode(typeName, body)
+		return
+	}
+	result, trace := gen.genGoValidationTrace(typeName)
+	switch gen.Validation {
+	case ValidationMethod:
+		gen.Field += fmt.Sprintf("\n// Validate checks the %s against the facets of the schema.\nfunc (v *%s) Validate() %s {\n%s%s\treturn nil\n}\n", typeName, typeName, result, trace, body)
+	case ValidationStandalone:
+		gen.ValidationCode += fmt.Sprintf("\n// Validate%s checks the %s against the facets of the schema.\nfunc Validate%s(v *%s) %s {\n%s%s\treturn nil\n}\n", typeName, typeName, typeName, typeName, result, trace, body)
+	}
+}
+
+// genGoDepthValidationCode generate depth-limited validation code for the
+// type with given validation body in Go language syntax. Nested types are
+// validated with the increased depth, and ErrValidationDepth is returned
+// instead of overflowing the stack on deeply nested documents.
+func (gen *CodeGenerator) genGoDepthValidationCode(typeName, body string) {
+	check := fmt.Sprintf("if depth > %d {\nreturn ErrValidationDepth\n}\n", gen.ValidationMaxDepth)
+	result, trace := gen.genGoValidationTrace(typeName)
+	switch gen.Validation {
+	case ValidationMethod:
+		gen.Field += fmt.Sprintf("\n// Validate checks the %s against the facets of the schema.\nfunc (v *%s) Validate() error {\nreturn v.validate(0)\n}\n", typeName, typeName)
+		gen.Field += fmt.Sprintf("\n// validate checks the %s at given nesting depth of the document.\nfunc (v *%s) validate(depth int) %s {\n%s%s%s\treturn nil\n}\n", typeName, typeName, result, trace, check, body)
+	case ValidationStandalone:
+		gen.ValidationCode += fmt.Sprintf("\n// Validate%s checks the %s against the facets of the schema.\nfunc Validate%s(v *%s) error {\nreturn validate%s(v, 0)\n}\n", typeName, typeName, typeName, typeName, typeName)
+		gen.ValidationCode += fmt.Sprintf("\n// validate%s checks the %s at given nesting depth of the document.\nfunc validate%s(v *%s, depth int) %s {\n%s%s%s\treturn nil\n}\n", typeName, typeName, typeName, typeName, result, trace, check, body)
+	}
+}
+
+// genGoValidationDepthError writes the ErrValidationDepth shared by the
+// depth-limited validation code of the generated files in the package.
+func (gen *CodeGenerator) genGoValidationDepthError(packageName string) error {
+	source, err := format.Source([]byte(fmt.Sprintf("%s\n\npackage %s\n\nimport \"errors\"\n\n// ErrValidationDepth is returned by the validation when the nesting depth of\n// the document exceeds %d.\nvar ErrValidationDepth = errors.New(\"maximum validation depth exceeded\")\n", gen.fileHeader(), packageName, gen.ValidationMaxDepth)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "validation_depth.go"), source)
+}
+
+// genGoBoolean writes the boolean type shared by the generated types of the
+// package, which accepts both the true/false and the 1/0 lexical forms of
+// xs:boolean, and is serialized in the canonical form of the boolean form.
+func (gen *CodeGenerator) genGoBoolean(packageName string) error {
+	t, f := gen.getBooleanLiterals()
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+package %s
+
+import (
+	"fmt"
+	"strings"
+)
+
+// XSDBoolean is the xs:boolean, which accepts both the true/false and the
+// 1/0 lexical forms, and is serialized as %s or %s.
+type XSDBoolean bool
+
+// UnmarshalText parses the lexical form of the xs:boolean.
+func (b *XSDBoolean) UnmarshalText(text []byte) error {
+	switch strings.TrimSpace(string(text)) {
+	case "true", "1":
+		*b = true
+	case "false", "0":
+		*b = false
+	default:
+		return fmt.Errorf("invalid xs:boolean value %%q", text)
+	}
+	return nil
+}
+
+// MarshalText returns the canonical lexical form of the xs:boolean.
+func (b XSDBoolean) MarshalText() ([]byte, error) {
+	if b {
+		return []byte("%s"), nil
+	}
+	return []byte("%s"), nil
+}
+`, gen.fileHeader(), packageName, t, f, t, f)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "xsd_boolean.go"), source)
+}
+
+// genGoXMLNamespace writes the function shared by the root element wrappers
+// of the package, which rewrites the encoded XML document to write the
+// elements of the namespace with the prefix instead of the default namespace.
+func (gen *CodeGenerator) genGoXMLNamespace(packageName string) error {
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+package %s
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+)
+
+// prefixXMLNamespace rewrites the encoded XML document to write the elements
+// of the namespace with the prefix, which is declared on the root element.
+func prefixXMLNamespace(data []byte, prefix, namespace string) ([]byte, error) {
+	var buf bytes.Buffer
+	decoder, encoder := xml.NewDecoder(bytes.NewReader(data)), xml.NewEncoder(&buf)
+	root := true
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var attrs []xml.Attr
+			if root {
+				attrs, root = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: namespace}), false
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Space != "xmlns" && !(attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					attrs = append(attrs, attr)
+				}
+			}
+			if t.Name.Space == namespace {
+				t.Name = xml.Name{Local: prefix + ":" + t.Name.Local}
+			}
+			t.Attr = attrs
+			token = t
+		case xml.EndElement:
+			if t.Name.Space == namespace {
+				t.Name = xml.Name{Local: prefix + ":" + t.Name.Local}
+			}
+			token = t
+		}
+		if err = encoder.EncodeToken(token); err != nil {
+			return nil, err
+		}
+	}
+	err := encoder.Flush()
+	return buf.Bytes(), err
+}
+`, gen.fileHeader(), packageName)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "xml_namespace.go"), source)
+}
+
+// goPatternRegexp matches the patterns of the pattern facets checked by the
+// generated Go code.
+var goPatternRegexp = regexp.MustCompile(`xsdPatterns\[("(?:[^"\\]|\\.)*")\]`)
+
+// genGoPatternInit generate the init function compiling the patterns of the
+// pattern facets checked by given code into the patterns of the package for
+// Go code, so each pattern is compiled once instead of on each validation.
+// The files of the package add the same pattern by the same key.
+func genGoPatternInit(code string) string {
+	patterns := map[string]bool{}
+	for _, match := range goPatternRegexp.FindAllStringSubmatch(code, -1) {
+		patterns[match[1]] = true
+	}
+	if len(patterns) == 0 {
+		return ""
+	}
+	init := "\nfunc init() {\n"
+	for _, pattern := range sortedKeys(patterns) {
+		init += fmt.Sprintf("\txsdPatterns[%s] = regexp.MustCompile(%s)\n", pattern, pattern)
+	}
+	return init + "}\n"
+}
+
+// genGoPatterns writes the patterns of the pattern facets shared by the
+// generated types of the package.
+func (gen *CodeGenerator) genGoPatterns(packageName string) error {
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+package %s
+
+import "regexp"
+
+// xsdPatterns holds the compiled patterns of the pattern facets by their
+// expressions, which are added by the generated files of the package.
+var xsdPatterns = map[string]*regexp.Regexp{}
+`, gen.fileHeader(), packageName)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "xsd_pattern.go"), source)
+}
+
+// genGoDigits writes the functions counting the digits of the numeric values
+// validated against the totalDigits and fractionDigits facets.
+func (gen *CodeGenerator) genGoDigits(packageName string) error {
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+package %s
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// xsdDigits returns the total digits and the fraction digits of the value in
+// the canonical form, without the leading zeros of the integer part and the
+// trailing zeros of the fraction part.
+func xsdDigits(f float64) (total, fraction int) {
+	value := strings.TrimLeft(strconv.FormatFloat(math.Abs(f), 'f', -1, 64), "0")
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		return len(value) - 1, len(value) - i - 1
+	}
+	return len(value), 0
+}
+
+// xsdTotalDigits returns the total digits of the value.
+func xsdTotalDigits(f float64) int {
+	total, _ := xsdDigits(f)
+	return total
+}
+
+// xsdFractionDigits returns the fraction digits of the value.
+func xsdFractionDigits(f float64) int {
+	_, fraction := xsdDigits(f)
+	return fraction
+}
+`, gen.fileHeader(), packageName)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "xsd_digits.go"), source)
+}
+
+// genGoDecimal writes the decimal types shared by the generated types of the
+// package, which parse and serialize the lexical form of xs:decimal
+// regardless of the locale, for the scales used by the generated types.
+func (gen *CodeGenerator) genGoDecimal(packageName string, scales []int) error {
+	var types string
+	for _, scale := range scales {
+		typeName, doc := genDecimalTypeName("XSDDecimal", scale), "the xs:decimal serialized with the fewest fraction digits representing the value"
+		if scale >= 0 {
+			doc = fmt.Sprintf("the xs:decimal serialized with %d fraction digits", scale)
+		}
+		types += fmt.Sprintf(`
+// %s is %s.
+type %s float64
+
+// UnmarshalText parses the lexical form of the xs:decimal.
+func (d *%s) UnmarshalText(text []byte) error {
+	f, err := parseXSDDecimal(string(text))
+	*d = %s(f)
+	return err
+}
+
+// MarshalText returns the lexical form of the xs:decimal.
+func (d %s) MarshalText() ([]byte, error) {
+	return formatXSDDecimal(float64(d), %d)
+}
+`, typeName, doc, typeName, typeName, typeName, typeName, scale)
+	}
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+package %s
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// parseXSDDecimal parses the lexical form of the xs:decimal, which consists
+// of an optional sign and the digits with an optional decimal point.
+func parseXSDDecimal(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	digits, point := strings.TrimLeft(value, "+-"), false
+	for _, c := range digits {
+		if c == '.' && !point {
+			point = true
+			continue
+		}
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid xs:decimal value %%q", value)
+		}
+	}
+	if len(value)-len(digits) > 1 || digits == "" || digits == "." {
+		return 0, fmt.Errorf("invalid xs:decimal value %%q", value)
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// formatXSDDecimal returns the lexical form of the xs:decimal without
+// exponent, with given number of fraction digits, or the fewest digits
+// representing the value if the scale is negative.
+func formatXSDDecimal(f float64, scale int) ([]byte, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("invalid xs:decimal value %%v", f)
+	}
+	value := strconv.FormatFloat(f, 'f', scale, 64)
+	if strings.Trim(value, "-0.") == "" {
+		value = strings.TrimPrefix(value, "-")
+	}
+	return []byte(value), nil
+}
+%s`, gen.fileHeader(), packageName, types)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "xsd_decimal.go"), source)
+}
+
+// goHasValidator returns true if the validation code is generated for the
+// type by given name.
+func (gen *CodeGenerator) goHasValidator(name string) bool {
+	for _, ele := range gen.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			if v.Name == name && v.List {
+				return gen.genGoListValidation(v, v.Name) != ""
+			}
+			if v.Name == name && !v.Union {
+				return !gen.isTypeAlias(v) && gen.isGoBuiltInType(gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)))
+			}
+		case *ComplexType:
+			if v.Name == name {
+				return true
+			}
+		case *Group:
+			if v.Name == name {
+				return true
+			}
+		case *AttributeGroup:
+			if v.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// genGoListValidation generate validation code of the list type by given
+// type name for Go code, the length facets of the list are checked on the
+// number of items, and the facets of the item type on each item. It returns
+// empty if there are no facets to check.
+func (gen *CodeGenerator) genGoListValidation(v *SimpleType, fieldName string) (code string) {
+	if gen.Validation == ValidationNone {
+		return
+	}
+	check := func(condition, message string) string {
+		return fmt.Sprintf("if %s {\nreturn errors.New(%q)\n}\n", condition, fieldName+" "+message)
+	}
+	code = genGoLengthChecks(check, "len(*v)", &v.Restriction)
+	fieldType := gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+	if itemRestriction := getListItemRestriction(v, gen.ProtoTree); gen.isGoBuiltInType(fieldType) && !itemRestriction.IsEmpty() {
+		if checks := gen.genGoFacetChecks(fieldName, fieldType, "item", "item", &itemRestriction); checks != "" {
+			code += fmt.Sprintf("for _, item := range *v {\n%s}\n", checks)
+		}
+	}
+	return
+}
+
+// genGoFieldValidation generate validation code of the struct field for Go
+// code. Facets are checked on fields with built-in type, the length facets
+// on the number of items of the lists, and nested types are validated by
+// their own validation code.
+func (gen *CodeGenerator) genGoFieldValidation(fieldName, typeName string, plural, optional bool, restriction *Restriction) string {
+	if gen.Validation == ValidationNone {
+		return ""
+	}
+	fieldType := gen.genGoFieldType(typeName)
+	field := "v." + fieldName
+	validate := func(value string) string {
+		call := fmt.Sprintf("%s.Validate()", value)
+		if gen.Validation == ValidationStandalone {
+			call = fmt.Sprintf("Validate%s(%s)", strings.TrimPrefix(fieldType, "*"), value)
+		}
+		if gen.ValidationMaxDepth > 0 {
+			call = fmt.Sprintf("%s.validate(depth + 1)", value)
+			if gen.Validation == ValidationStandalone {
+				call = fmt.Sprintf("validate%s(%s, depth+1)", strings.TrimPrefix(fieldType, "*"), value)
+			}
+		}
+		return fmt.Sprintf("if err := %s; err != nil {\nreturn err\n}\n", call)
+	}
+	checks := func(value string) string {
+		if isListType(typeName, gen.ProtoTree) {
+			if !strings.HasPrefix(fieldType, "*") {
+				return ""
+			}
+			var code string
+			if restriction != nil {
+				code = genGoLengthChecks(func(condition, message string) string {
+					return fmt.Sprintf("if %s {\nreturn errors.New(%q)\n}\n", condition, fieldName+" "+message)
+				}, fmt.Sprintf("len(*%s)", value), restriction)
+			}
+			// The items are validated by the list type.
+			if gen.goHasValidator(typeName) {
+				code += validate(value)
+			}
+			if code == "" {
+				return ""
+			}
+			return fmt.Sprintf("if %s != nil {\n%s}\n", value, code)
+		}
+		if !gen.isGoBuiltInType(fieldType) {
+			if !gen.goHasValidator(typeName) {
+				return ""
+			}
+			return fmt.Sprintf("if %s != nil {\n%s}\n", value, validate(value))
+		}
+		if restriction == nil || restriction.IsEmpty() {
+			return ""
+		}
+		if optional && !plural && gen.isGoOptionalPointer(fieldType) {
+			// The optional field is absent if it's nil, the facets are
+			// checked on its zero value otherwise.
+			if code := gen.genGoFacetChecks(fieldName, fieldType, "*"+value, "*"+value, restriction); code != "" {
+				return fmt.Sprintf("if %s != nil {\n%s}\n", value, code)
+			}
+			return ""
+		}
+		code := gen.genGoFacetChecks(fieldName, fieldType, value, value, restriction)
+		if code != "" && optional && !plural {
+			// The empty string of the optional field means the field is
+			// absent.
+			code = fmt.Sprintf("if %s != %s {\n%s}\n", value, genGoZeroValue(fieldType), code)
+		}
+		return code
+	}
+	if plural {
+		if code := checks("item"); code != "" {
+			return fmt.Sprintf("for _, item := range %s {\n%s}\n", field, code)
+		}
+		return ""
+	}
+	return checks(field)
+}
+
+// genGoUniqueValidation generate validation code of the unique, key and
+// keyref identity constraints checked by the type for Go code. A duplicated
+// combination of the field values among the selected elements is reported
+// with the index of the duplicate, and elements without all the fields are
+// skipped, unless the key constraint requires them. The combination of the
+// keyref constraint matching none of the referred constraint is reported
+// with the index of the element. The elements selected by the steps nested
+// in other elements are collected first, and indexed in document order. The
+// constraints which can't be checked are recorded to be warned about.
+func (gen *CodeGenerator) genGoUniqueValidation(typeName string) (code string) {
+	if gen.Validation == ValidationNone {
+		return
+	}
+	for _, unique := range getUniqueConstraints(typeName, gen.ProtoTree) {
+		key, err := gen.getGoIdentityKey(typeName, unique, "selected"+genGoFieldName(unique.Name, false))
+		if err != nil {
+			gen.addUnsupportedUnique(typeName, unique, err)
+			continue
+		}
+		if unique.Refer != "" {
+			keyref, err := gen.genGoKeyRefValidation(typeName, unique, key)
+			if err != nil {
+				gen.addUnsupportedUnique(typeName, unique, err)
+			}
+			code += keyref
+			continue
+		}
+		seen := "seen" + genGoFieldName(unique.Name, false)
+		code += fmt.Sprintf("%s%s := make(map[[%d]interface{}]int)\nfor i, item := range %s {\n", key.collect, seen, len(key.values), key.items)
+		if unique.Key {
+			code += key.genSkip(false)
+			if len(key.absent) > 0 {
+				code += fmt.Sprintf("if %s {\nreturn fmt.Errorf(\"%s[%%d] misses the fields of key constraint %s\", i)\n}\n", strings.Join(key.absent, " || "), key.itemName, unique.Name)
+			}
+		} else {
+			code += key.genSkip(true)
+		}
+		code += fmt.Sprintf("key := %s\nif j, ok := %s[key]; ok {\nreturn fmt.Errorf(\"%s[%%d] duplicates %s[%%d] on %s constraint %s\", i, j)\n}\n%s[key] = i\n}\n",
+			key.genValue(), seen, key.itemName, key.itemName, unique.kind(), unique.Name, seen)
+	}
+	return
+}
+
+// addUnsupportedUnique records the identity constraint on the type by given
+// name which can't be checked by the validation code, with the reason.
+func (gen *CodeGenerator) addUnsupportedUnique(typeName string, unique *Unique, err error) {
+	gen.unsupportedUniques = append(gen.unsupportedUniques, fmt.Sprintf("%s %s of %s: %v", unique.kind(), unique.Name, typeName, err))
+}
+
+// genGoKeyRefValidation generate validation code of the keyref identity
+// constraint for Go code, collecting the combinations of the field values of
+// the referred constraint before checking the ones of the keyref constraint.
+func (gen *CodeGenerator) genGoKeyRefValidation(typeName string, keyref *Unique, key goIdentityKey) (string, error) {
+	referred := getReferredConstraint(keyref, gen.ProtoTree)
+	if referred == nil {
+		return "", fmt.Errorf("no key or unique constraint %s", keyref.Refer)
+	}
+	referredKey, err := gen.getGoIdentityKey(typeName, referred, "referred"+genGoFieldName(keyref.Name, false))
+	if err != nil {
+		return "", err
 	}
+	if len(referredKey.values) != len(key.values) {
+		return "", fmt.Errorf("the fields don't match the ones of %s", referred.Name)
+	}
+	keys := "keys" + genGoFieldName(keyref.Name, false)
+	code := fmt.Sprintf("%s%s := make(map[[%d]interface{}]bool)\nfor _, item := range %s {\n%s%s[%s] = true\n}\n", referredKey.collect, keys, len(key.values), referredKey.items, referredKey.genSkip(true), keys, referredKey.genValue())
+	return code + fmt.Sprintf("%sfor i, item := range %s {\n%sif !%s[%s] {\nreturn fmt.Errorf(\"%s[%%d] refers to no %s on keyref constraint %s\", i)\n}\n}\n",
+		key.collect, key.items, key.genSkip(true), keys, key.genValue(), key.itemName, referredKey.itemName, keyref.Name), nil
+}
+
+// goIdentityKey holds the field of the elements selected by an identity
+// constraint for Go code, named by the path of the fields if they're nested,
+// the code collecting the nested elements and the expression of the selected
+// elements ranged over, the conditions on which the selected item is nil and
+// on which its fields are absent, and the value expressions of the fields on
+// the item.
+type goIdentityKey struct {
+	itemName, collect, items string
+	conditions, absent       []string
+	values                   []string
 }
 
-func (gen *CodeGenerator) FileWithExtension(extension string) string {
-	if !strings.HasPrefix(extension, ".") {
-		extension = "." + extension
+// getGoIdentityKey returns the selected elements and the fields of the
+// identity constraint on the type by given name for Go code. The selector
+// may step through the nested elements, which are collected into the
+// variable by given name, and the error tells the selector or the field
+// which can't be resolved.
+func (gen *CodeGenerator) getGoIdentityKey(typeName string, unique *Unique, selected string) (key goIdentityKey, err error) {
+	selector, err := resolveUniqueSelector(typeName, unique, gen.ProtoTree)
+	if err != nil {
+		return key, err
+	}
+	item := selector[len(selector)-1]
+	fieldType := gen.genGoFieldType(item.Type)
+	if len(selector) == 1 && item.Plural {
+		key.itemName = genGoFieldName(gen.genPluralName(item.Name, true), false)
+		key.items = "v." + key.itemName
+	} else if key.collect, err = gen.genGoUniqueSelection(unique, selector, selected); err != nil {
+		return key, err
+	} else {
+		var names []string
+		for _, step := range selector {
+			names = append(names, genGoFieldName(gen.genPluralName(step.Name, step.Plural), false))
+		}
+		key.itemName, key.items = strings.Join(names, "/"), selected
+	}
+	if !gen.isGoBuiltInType(fieldType) {
+		key.conditions = append(key.conditions, "item == nil")
+	}
+	for _, field := range unique.Fields {
+		value, fieldConditions, ok := gen.genGoUniqueField(item, field)
+		if !ok {
+			return key, fmt.Errorf("unsupported field %s", field)
+		}
+		key.absent = append(key.absent, fieldConditions...)
+		key.values = append(key.values, value)
+	}
+	return key, nil
+}
+
+// genGoUniqueSelection generate the code collecting the elements selected
+// by the steps of the selector of the identity constraint into the variable
+// by given name for Go code, which steps through the elements of the complex
+// types, in document order.
+func (gen *CodeGenerator) genGoUniqueSelection(unique *Unique, selector []uniqueStep, selected string) (string, error) {
+	last := selector[len(selector)-1]
+	elemType := gen.genGoFieldType(last.Type)
+	if last.Optional && !last.Plural && gen.isGoOptionalPointer(elemType) {
+		return "", fmt.Errorf("unsupported selector %s", unique.Selector)
+	}
+	var open []string
+	value := "v"
+	for i, step := range selector {
+		field := value + "." + genGoFieldName(gen.genPluralName(step.Name, step.Plural), false)
+		if i == len(selector)-1 {
+			if step.Plural {
+				open = append(open, fmt.Sprintf("%s = append(%s, %s...)\n", selected, selected, field))
+			} else if !gen.isGoBuiltInType(elemType) {
+				open = append(open, fmt.Sprintf("if %s != nil {\n%s = append(%s, %s)\n}\n", field, selected, selected, field))
+			} else {
+				open = append(open, fmt.Sprintf("%s = append(%s, %s)\n", selected, selected, field))
+			}
+			break
+		}
+		if gen.isGoBuiltInType(gen.genGoFieldType(step.Type)) {
+			return "", fmt.Errorf("unsupported selector %s", unique.Selector)
+		}
+		if step.Plural {
+			open = append(open, fmt.Sprintf("for _, item := range %s {\nif item != nil {\n", field))
+		} else {
+			open = append(open, fmt.Sprintf("if item := %s; item != nil {\n", field))
+		}
+		value = "item"
+	}
+	var closing string
+	for _, step := range selector[:len(selector)-1] {
+		closing += "}\n"
+		if step.Plural {
+			closing += "}\n"
+		}
+	}
+	return fmt.Sprintf("var %s []%s\n%s%s", selected, elemType, strings.Join(open, ""), closing), nil
+}
+
+// genSkip generate the code skipping the nil items, and the items without
+// all the fields if absent is true.
+func (key goIdentityKey) genSkip(absent bool) string {
+	conditions := key.conditions
+	if absent {
+		conditions = append(conditions[:len(conditions):len(conditions)], key.absent...)
+	}
+	if len(conditions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("if %s {\ncontinue\n}\n", strings.Join(conditions, " || "))
+}
+
+// genValue generate the array expression of the field values of the item.
+func (key goIdentityKey) genValue() string {
+	return fmt.Sprintf("[%d]interface{}{%s}", len(key.values), strings.Join(key.values, ", "))
+}
+
+// genGoUniqueField generate the value expression of the field of unique
+// identity constraint on the selected item, and the conditions on which the
+// field is absent.
+func (gen *CodeGenerator) genGoUniqueField(item uniqueStep, field string) (value string, conditions []string, ok bool) {
+	value = "item"
+	if strings.TrimSpace(field) == "." {
+		return value, nil, gen.isGoBuiltInType(gen.genGoFieldType(item.Type))
+	}
+	steps, ok := resolveUniquePath(trimNSPrefix(item.Type), field, gen.ProtoTree)
+	if !ok {
+		return
+	}
+	for i, step := range steps {
+		fieldType := gen.genGoFieldType(step.Type)
+		value += "." + genGoFieldName(step.Name, false)
+		if step.Attribute {
+			value += "Attr"
+		}
+		if step.Plural || gen.isGoBuiltInType(fieldType) != (i == len(steps)-1) || strings.HasPrefix(fieldType, "[]") || strings.HasPrefix(fieldType, "interface") {
+			return "", nil, false
+		}
+		if i < len(steps)-1 {
+			conditions = append(conditions, value+" == nil")
+			continue
+		}
+		switch {
+		case step.Optional && gen.isGoOptionalPointer(fieldType):
+			conditions = append(conditions, value+" == nil")
+			value = "*" + value
+		case step.Optional && fieldType == "string":
+			conditions = append(conditions, value+` == ""`)
+		}
+	}
+	return value, conditions, true
+}
+
+// genGoNumberLiteral generate literal of the numeric bound for Go code,
+// written exactly from the values of the integer and the decimal types, so
+// the bounds beyond the precision of the floats are kept, and by math.Inf
+// for the infinities.
+func genGoNumberLiteral(value FacetValue) string {
+	if value.IsInf() {
+		if value.Float > 0 {
+			return "math.Inf(1)"
+		}
+		return "math.Inf(-1)"
+	}
+	if value.Kind == FacetFloat {
+		return strconv.FormatFloat(value.Float, 'f', -1, 64)
+	}
+	return value.String()
+}
+
+// genGoFacetChecks generate facet checks of the value with built-in type for
+// Go code.
+func (gen *CodeGenerator) genGoFacetChecks(fieldName, fieldType, value, number string, restriction *Restriction) (code string) {
+	check := func(condition, message string) string {
+		return fmt.Sprintf("if %s {\nreturn errors.New(%q)\n}\n", condition, fieldName+" "+message)
+	}
+	if fieldType == "string" {
+		// The facets are checked on the value normalized by the whiteSpace
+		// facet.
+		value = genGoWhiteSpaceExpr(value, restriction.WhiteSpace)
+		length := fmt.Sprintf("utf8.RuneCountInString(%s)", value)
+		if restriction.Octets {
+			length = fmt.Sprintf("len(%s)/2", value)
+		}
+		code += genGoLengthChecks(check, length, restriction)
+		if restriction.Pattern != nil {
+			code += check(fmt.Sprintf("!xsdPatterns[%q].MatchString(%s)", "^(?:"+restriction.Pattern.String()+")$", value), fmt.Sprintf("does not match the pattern %s", restriction.Pattern.String()))
+		}
+		if len(restriction.Enum) > 0 {
+			var quotedEnums []string
+			for _, enum := range restriction.Enum {
+				quotedEnums = append(quotedEnums, strconv.Quote(enum))
+			}
+			code += fmt.Sprintf("switch %s {\ncase %s:\ndefault:\nreturn errors.New(%q)\n}\n", value, strings.Join(quotedEnums, ", "), fieldName+" is not one of "+strings.Join(restriction.Enum, ", "))
+		}
+	}
+	if gen.isGoNumericType(fieldType) {
+		minValue, maxValue := restriction.minValue(), restriction.maxValue()
+		min, max := genGoNumberLiteral(minValue), genGoNumberLiteral(maxValue)
+		minText, maxText := minValue.String(), maxValue.String()
+		// The infinities aren't constants, and are compared as float64.
+		minNumber, maxNumber := number, number
+		if minValue.IsInf() {
+			minNumber = fmt.Sprintf("float64(%s)", number)
+		}
+		if maxValue.IsInf() {
+			maxNumber = fmt.Sprintf("float64(%s)", number)
+		}
+		if restriction.HasMin && restriction.MinExclusive {
+			code += check(fmt.Sprintf("%s <= %s", minNumber, min), fmt.Sprintf("is not greater than the exclusive minimum value of %s", minText))
+		} else if restriction.HasMin {
+			code += check(fmt.Sprintf("%s < %s", minNumber, min), fmt.Sprintf("is less than the minimum value of %s", minText))
+		}
+		if restriction.HasMax && restriction.MaxExclusive {
+			code += check(fmt.Sprintf("%s >= %s", maxNumber, max), fmt.Sprintf("is not less than the exclusive maximum value of %s", maxText))
+		} else if restriction.HasMax {
+			code += check(fmt.Sprintf("%s > %s", maxNumber, max), fmt.Sprintf("exceeds the maximum value of %s", maxText))
+		}
+		if values, ok := restriction.numericEnum(); ok {
+			var literals []string
+			for _, value := range values {
+				literals = append(literals, genGoNumberLiteral(value))
+			}
+			code += fmt.Sprintf("switch %s {\ncase %s:\ndefault:\nreturn errors.New(%q)\n}\n", number, strings.Join(literals, ", "), fieldName+" is not one of "+strings.Join(restriction.Enum, ", "))
+		}
+		if restriction.TotalDigits > 0 {
+			code += check(fmt.Sprintf("xsdTotalDigits(float64(%s)) > %d", number, restriction.TotalDigits), fmt.Sprintf("exceeds the total digits of %d", restriction.TotalDigits))
+		}
+		if restriction.FractionDigits > 0 {
+			code += check(fmt.Sprintf("xsdFractionDigits(float64(%s)) > %d", number, restriction.FractionDigits), fmt.Sprintf("exceeds the fraction digits of %d", restriction.FractionDigits))
+		}
+	}
+	return
+}
+
+// genGoLengthChecks generate the checks of the length facets on the length
+// expression of the value or the list for Go code.
+func genGoLengthChecks(check func(condition, message string) string, length string, restriction *Restriction) (code string) {
+	if restriction.HasLength {
+		return check(fmt.Sprintf("%s != %d", length, restriction.Length), fmt.Sprintf("does not have the length of %d", restriction.Length))
+	}
+	if restriction.MinLength > 0 {
+		code += check(fmt.Sprintf("%s < %d", length, restriction.MinLength), fmt.Sprintf("is shorter than the minimum length of %d", restriction.MinLength))
+	}
+	if restriction.MaxLength > 0 {
+		code += check(fmt.Sprintf("%s > %d", length, restriction.MaxLength), fmt.Sprintf("exceeds the maximum length of %d", restriction.MaxLength))
+	}
+	return
+}
+
+// genGoWhiteSpaceExpr returns the Go expression normalizing the string value
+// by given value of the whiteSpace facet.
+func genGoWhiteSpaceExpr(value, whiteSpace string) string {
+	switch whiteSpace {
+	case WhiteSpaceReplace:
+		return fmt.Sprintf("strings.NewReplacer(\"\\t\", \" \", \"\\n\", \" \", \"\\r\", \" \").Replace(%s)", value)
+	case WhiteSpaceCollapse:
+		return fmt.Sprintf("strings.Join(strings.Fields(%s), \" \")", value)
+	}
+	return value
+}
+
+func (gen *CodeGenerator) isGoNumericType(typeName string) bool {
+	return gen.isGoBuiltInType(typeName) && (strings.HasPrefix(typeName, "int") || strings.HasPrefix(typeName, "uint") || strings.HasPrefix(typeName, "float") || isDecimalType(typeName))
+}
+
+// isGoOptionalPointer returns true if the optional fields of the built-in
+// type are pointers for Go code, which are the numeric and boolean types, so
+// the absent value is told apart from the zero value, which is valid.
+func (gen *CodeGenerator) isGoOptionalPointer(fieldType string) bool {
+	return fieldType == "bool" || gen.isGoNumericType(fieldType)
+}
+
+// genGoOptionalFieldType returns the type of the field of the element or
+// attribute for Go code, the pointer to the type for the single optional
+// values of the numeric and boolean types.
+func (gen *CodeGenerator) genGoOptionalFieldType(fieldType string, plural, optional bool) string {
+	if optional && !plural && gen.isGoOptionalPointer(fieldType) {
+		return "*" + fieldType
+	}
+	return fieldType
+}
+
+// genGoZeroValue returns the zero value literal of the built-in type.
+func genGoZeroValue(typeName string) string {
+	switch typeName {
+	case "string":
+		return `""`
+	case "bool":
+		return "false"
+	}
+	return "0"
+}
+
+// genGoNormalizeCode generate normalize code for the type with given
+// normalize body in Go language syntax.
+func (gen *CodeGenerator) genGoNormalizeCode(typeName, body string) {
+	if !gen.Normalize {
+		return
+	}
+	if gen.Validation == ValidationStandalone {
+		gen.ValidationCode += fmt.Sprintf("\n// Normalize%s converts the %s into the canonical form implied by the\n// facets of the schema.\nfunc Normalize%s(v *%s) {\n%s}\n", typeName, typeName, typeName, typeName, body)
+		return
+	}
+	gen.Field += fmt.Sprintf("\n// Normalize converts the %s into the canonical form implied by the facets\n// of the schema.\nfunc (v *%s) Normalize() {\n%s}\n", typeName, typeName, body)
+}
+
+// goHasNormalizer returns true if the normalize code is generated for the
+// type by given name.
+func (gen *CodeGenerator) goHasNormalizer(name string) bool {
+	for _, ele := range gen.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			if v.Name == name && !v.List && !v.Union {
+				return genGoNormalizeExpr(gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)), "", &v.Restriction) != ""
+			}
+		case *ComplexType:
+			if v.Name == name {
+				return true
+			}
+		case *Group:
+			if v.Name == name {
+				return true
+			}
+		case *AttributeGroup:
+			if v.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// genGoFieldNormalize generate normalize code of the struct field for Go
+// code. Fields with built-in type are normalized by their facets, and nested
+// types are normalized by their own normalize code.
+func (gen *CodeGenerator) genGoFieldNormalize(fieldName, typeName string, plural bool, restriction *Restriction) string {
+	if !gen.Normalize {
+		return ""
+	}
+	fieldType := gen.genGoFieldType(typeName)
+	field := "v." + fieldName
+	if !gen.isGoBuiltInType(fieldType) {
+		if !gen.goHasNormalizer(typeName) {
+			return ""
+		}
+		call := func(value string) string {
+			if gen.Validation == ValidationStandalone {
+				return fmt.Sprintf("if %s != nil {\nNormalize%s(%s)\n}\n", value, strings.TrimPrefix(fieldType, "*"), value)
+			}
+			return fmt.Sprintf("if %s != nil {\n%s.Normalize()\n}\n", value, value)
+		}
+		if plural {
+			return fmt.Sprintf("for _, item := range %s {\n%s}\n", field, call("item"))
+		}
+		return call(field)
+	}
+	if plural {
+		if expr := genGoNormalizeExpr(fieldType, field+"[i]", restriction); expr != "" {
+			return fmt.Sprintf("for i := range %s {\n%s[i] = %s\n}\n", field, field, expr)
+		}
+		return ""
+	}
+	if expr := genGoNormalizeExpr(fieldType, field, restriction); expr != "" {
+		return fmt.Sprintf("%s = %s\n", field, expr)
+	}
+	return ""
+}
+
+// genGoNormalizeExpr generate the expression which applies the normalization
+// implied by the facets on the value with built-in type for Go code, returns
+// empty string if the value is left unchanged.
+func genGoNormalizeExpr(fieldType, value string, restriction *Restriction) string {
+	if fieldType != "string" || restriction == nil {
+		return ""
+	}
+	n := restriction.Normalization()
+	if n.IsEmpty() {
+		return ""
+	}
+	value = genGoWhiteSpaceExpr(value, n.WhiteSpace)
+	if n.Trim {
+		value = fmt.Sprintf("strings.TrimSpace(%s)", value)
 	}
-	if strings.HasSuffix(gen.File, extension) {
-		return gen.File
+	switch n.Case {
+	case CaseUpper:
+		value = fmt.Sprintf("strings.ToUpper(%s)", value)
+	case CaseLower:
+		value = fmt.Sprintf("strings.ToLower(%s)", value)
 	}
-	return gen.File + extension
+	return value
 }
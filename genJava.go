@@ -10,8 +10,6 @@ package xgen
 
 import (
 	"fmt"
-	"os"
-	"reflect"
 	"strings"
 )
 
@@ -34,21 +32,12 @@ var javaBuildInType = map[string]bool{
 func (gen *CodeGenerator) GenJava() error {
 	fieldNameCount = make(map[string]int)
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isPruned(ele) {
 			continue
 		}
-		funcName := fmt.Sprintf("Java%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
-	}
-	f, err := os.Create(gen.FileWithExtension(".java"))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	packageName := gen.Package
-	if packageName == "" {
-		packageName = "schema"
+		gen.genDeclaration("Java", ele)
 	}
+	packageName := getJavaPackage(gen.Package)
 	var importPackage = `import java.util.ArrayList;
 import java.util.List;
 import javax.xml.bind.annotation.XmlAccessType;
@@ -58,9 +47,24 @@ import javax.xml.bind.annotation.XmlElement;
 import javax.xml.bind.annotation.XmlSchemaType;
 import javax.xml.bind.annotation.XmlType;
 import javax.xml.bind.annotation.XmlValue;`
-
-	f.Write([]byte(fmt.Sprintf("%s\n\npackage %s;\n\n%s\n%s", copyright, packageName, importPackage, gen.Field)))
-	return err
+	if strings.Contains(gen.Field, "@XmlRootElement") {
+		importPackage = strings.NewReplacer(
+			"import java.util.ArrayList;", "import java.io.StringReader;\nimport java.io.StringWriter;\nimport java.util.ArrayList;",
+			"import javax.xml.bind.annotation.XmlAccessType;", "import javax.xml.bind.JAXBContext;\nimport javax.xml.bind.JAXBException;\nimport javax.xml.bind.annotation.XmlAccessType;",
+			"import javax.xml.bind.annotation.XmlElement;", "import javax.xml.bind.annotation.XmlElement;\nimport javax.xml.bind.annotation.XmlRootElement;",
+		).Replace(importPackage)
+	}
+	if strings.Contains(gen.Field, "@XmlAnyElement") || strings.Contains(gen.Field, "@XmlAnyAttribute") {
+		importPackage = strings.NewReplacer(
+			"import java.util.List;", "import java.util.List;\nimport java.util.Map;",
+			"import javax.xml.bind.annotation.XmlAccessorType;", "import javax.xml.bind.annotation.XmlAccessorType;\nimport javax.xml.bind.annotation.XmlAnyAttribute;\nimport javax.xml.bind.annotation.XmlAnyElement;",
+			"import javax.xml.bind.annotation.XmlValue;", "import javax.xml.bind.annotation.XmlValue;\nimport javax.xml.namespace.QName;",
+		).Replace(importPackage)
+	}
+	if gen.JavaProject != "" {
+		return gen.genJavaProjectSources(packageName, importPackage)
+	}
+	return gen.WriteFile(gen.FileWithExtension(".java"), []byte(fmt.Sprintf("%s\n\npackage %s;\n\n%s\n%s", gen.fileHeader(), packageName, importPackage, gen.Field)))
 }
 
 func genJavaFieldName(name string, unique bool) (fieldName string) {
@@ -82,8 +86,8 @@ func genJavaFieldName(name string, unique bool) (fieldName string) {
 	return
 }
 
-func genJavaFieldType(name string) string {
-	if _, ok := javaBuildInType[name]; ok {
+func (gen *CodeGenerator) genJavaFieldType(name string) string {
+	if _, ok := javaBuildInType[name]; ok || gen.builtInTypes[name] {
 		return name
 	}
 	var fieldType string
@@ -102,10 +106,12 @@ func genJavaFieldType(name string) string {
 func (gen *CodeGenerator) JavaSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-			content := fmt.Sprintf("\tprotected List<%s> %s;\n", fieldType, genJavaFieldName(v.Name, false))
+			fieldType := gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+			content := gen.genJavaAccessors(fmt.Sprintf("\tprotected List<%s> %s;\n", fieldType, genJavaFieldName(v.Name, false)))
 			gen.StructAST[v.Name] = content
-			gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, genJavaFieldName(v.Name, true), gen.StructAST[v.Name])
+			fieldName := genJavaFieldName(v.Name, true)
+			gen.addSymbol(v, fieldName)
+			gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, fieldName, gen.StructAST[v.Name])
 			return
 		}
 	}
@@ -119,22 +125,24 @@ func (gen *CodeGenerator) JavaSimpleType(v *SimpleType) {
 				if memberType == "" { // fix order issue
 					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
 				}
-				fieldType := genJavaFieldType(memberType)
+				fieldType := gen.genJavaFieldType(memberType)
 				content += fmt.Sprintf("\t@XmlElement(required = true)\n\tprotected %s %s;\n", fieldType, genJavaFieldName(memberName, false))
 			}
-			content += "}\n"
+			content = gen.genJavaAccessors(content) + "}\n"
 			gen.StructAST[v.Name] = content
 			fieldName := genJavaFieldName(v.Name, true)
-			gen.Field += fmt.Sprintf("%spublic class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			gen.addSymbol(v, fieldName)
+			gen.Field += fmt.Sprintf("%s%spublic class %s%s", gen.genComment(fieldName, v.Doc), gen.genJavaAccessorType(), fieldName, gen.StructAST[v.Name])
 		}
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
-		content := fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(v.Name, false))
+		fieldType := gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+		content := gen.genJavaAccessors(fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(v.Name, false)))
 		gen.StructAST[v.Name] = content
 		fieldName := genJavaFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%s@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), v.Name, fieldName, gen.StructAST[v.Name])
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%s@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", gen.genComment(fieldName, v.Doc), v.Name, fieldName, gen.StructAST[v.Name])
 	}
 }
 
@@ -142,10 +150,26 @@ func (gen *CodeGenerator) JavaSimpleType(v *SimpleType) {
 // syntax.
 func (gen *CodeGenerator) JavaComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
+		fieldName := genJavaFieldName(v.Name, true)
+		gen.addSymbol(v, fieldName)
+		if shared := gen.getSharedAnonymousType(v, fieldName); shared != "" {
+			// Java has no type aliases, structurally identical anonymous
+			// types extend the class of the first one.
+			gen.StructAST[v.Name] = " {\n}\n"
+			gen.Field += fmt.Sprintf("%spublic class %s extends %s%s", gen.genComment(fieldName, v.Doc), fieldName, shared, gen.StructAST[v.Name])
+			return
+		}
 		content := " {\n"
+		var accessors []javaMixinAccessor
+		var mixins []string
 		for _, attrGroup := range v.AttributeGroup {
+			if mixin := gen.getMixinAttributeGroup(attrGroup.Ref); mixin != nil {
+				c, a := gen.genJavaAttributeGroupFields(mixin)
+				content, accessors, mixins = joinWildcardFields(content, c, javaAnyAttributeField), append(accessors, a...), append(mixins, genJavaMixinName(mixin.Name))
+				continue
+			}
 			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-			content += fmt.Sprintf("\t@XmlElement(required = true)\n\tprotected %s %s;\n", genJavaFieldType(fieldType), genJavaFieldName(attrGroup.Name, false))
+			content += fmt.Sprintf("\t@XmlElement(required = true)\n\tprotected %s %s;\n", gen.genJavaFieldType(fieldType), genJavaFieldName(attrGroup.Name, false))
 		}
 
 		for _, attribute := range v.Attributes {
@@ -153,73 +177,88 @@ func (gen *CodeGenerator) JavaComplexType(v *ComplexType) {
 			if attribute.Optional {
 				required = ""
 			}
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
-			content += fmt.Sprintf("\t@XmlAttribute(name = \"%s\"%s)\n\tprotected %s %sAttr;\n", attribute.Name, required, fieldType, genJavaFieldName(attribute.Name, false))
+			fieldType := gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
+			content += genJavaDeprecatedAnnotation(attribute.Deprecated, attribute.Deprecation) + fmt.Sprintf("\t@XmlAttribute(name = \"%s\"%s)\n\tprotected %s %sAttr;\n", attribute.Name, required, fieldType, genJavaFieldName(attribute.Name, false))
 		}
 		for _, group := range v.Groups {
-			var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
+			if mixin := gen.getMixinGroup(group); mixin != nil {
+				c, a, m := gen.genJavaGroupFields(mixin)
+				content, accessors, mixins = joinWildcardFields(content, c, javaAnyElementField), append(accessors, a...), append(mixins, m...)
+				continue
+			}
+			var fieldType = gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
 			if group.Plural {
 				fieldType = fmt.Sprintf("List<%s>", fieldType)
 			}
-			content += fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(group.Name, false))
+			content += fmt.Sprintf("%s\tprotected %s %s;\n", genJavaPluralAnnotation(gen.genPluralName(group.Name, group.Plural), group.Name), fieldType, genJavaFieldName(gen.genPluralName(group.Name, group.Plural), false))
 		}
 
 		for _, element := range v.Elements {
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
+			fieldType := gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
 			if element.Plural {
 				fieldType = fmt.Sprintf("List<%s>", fieldType)
 			}
-			content += fmt.Sprintf("\t@XmlElement(required = true, name = \"%s\")\n\tprotected %s %s;\n", element.Name, fieldType, genJavaFieldName(element.Name, false))
+			content += genJavaDeprecatedAnnotation(element.Deprecated, element.Deprecation) + fmt.Sprintf("\t@XmlElement(required = true, name = \"%s\")\n\tprotected %s %s;\n", element.Name, fieldType, genJavaFieldName(gen.genPluralName(element.Name, element.Plural), false))
 		}
+		content += genJavaWildcardFields(content, v.Any, v.AnyAttribute)
 
-		if len(v.Base) > 0 && isBuiltInJavaType(v.Base) {
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+		if len(v.Base) > 0 && gen.isBuiltInJavaType(v.Base) {
+			fieldType := gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
 			content += fmt.Sprintf("\t@XmlValue\n\tprotected %s value;\n", fieldType)
 		}
 
-		content += "}\n"
+		content += genJavaMixinAccessors(accessors)
+		content = gen.genJavaAccessors(content) + "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genJavaFieldName(v.Name, true)
 
 		typeExtension := ""
-		if len(v.Base) > 0 && !isBuiltInJavaType(v.Base) {
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+		if len(v.Base) > 0 && !gen.isBuiltInJavaType(v.Base) {
+			fieldType := gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
 			typeExtension = fmt.Sprintf(" extends %s ", fieldType)
 		}
+		typeExtension += genJavaMixinImplements(typeExtension, mixins)
 
-		gen.Field += fmt.Sprintf("%spublic class %s%s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, typeExtension, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%s%spublic class %s%s%s", gen.genComment(fieldName, v.Doc), gen.genJavaAccessorType(), fieldName, typeExtension, gen.StructAST[v.Name])
 	}
 }
 
-func isBuiltInJavaType(typeName string) bool {
-	_, builtIn := javaBuildInType[typeName]
-	return builtIn
+func (gen *CodeGenerator) isBuiltInJavaType(typeName string) bool {
+	return javaBuildInType[typeName] || gen.builtInTypes[typeName]
 }
 
 // JavaGroup generates code for group XML schema in Java language syntax.
 func (gen *CodeGenerator) JavaGroup(v *Group) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
+		fieldName := genJavaFieldName(v.Name, true)
+		gen.addSymbol(v, fieldName)
+		if gen.Mixins {
+			content, accessors, mixins := gen.genJavaGroupFields(v)
+			gen.StructAST[v.Name] = " {\n" + gen.genJavaAccessors(content+genJavaMixinAccessors(accessors)) + "}\n"
+			gen.genJavaGroupMixin(v)
+			gen.Field += fmt.Sprintf("%s%spublic class %s%s%s", gen.genComment(fieldName, v.Doc), gen.genJavaAccessorType(), fieldName, genJavaMixinImplements("", mixins), gen.StructAST[v.Name])
+			return
+		}
 		content := " {\n"
 		for _, element := range v.Elements {
-			var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
+			var fieldType = gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
 			if element.Plural {
 				fieldType = fmt.Sprintf("List<%s>", fieldType)
 			}
-			content += fmt.Sprintf("\t@XmlElement(required = true, name = \"%s\")\n\tprotected %s %s;\n", element.Name, fieldType, genJavaFieldName(element.Name, false))
+			content += genJavaDeprecatedAnnotation(element.Deprecated, element.Deprecation) + fmt.Sprintf("\t@XmlElement(required = true, name = \"%s\")\n\tprotected %s %s;\n", element.Name, fieldType, genJavaFieldName(gen.genPluralName(element.Name, element.Plural), false))
 		}
 
 		for _, group := range v.Groups {
-			var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
+			var fieldType = gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
 			if group.Plural {
 				fieldType = fmt.Sprintf("List<%s>", fieldType)
 			}
-			content += fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(group.Name, false))
+			content += fmt.Sprintf("%s\tprotected %s %s;\n", genJavaPluralAnnotation(gen.genPluralName(group.Name, group.Plural), group.Name), fieldType, genJavaFieldName(gen.genPluralName(group.Name, group.Plural), false))
 		}
+		content += genJavaWildcardFields(content, v.Any, false)
 
-		content += "}\n"
+		content = gen.genJavaAccessors(content) + "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genJavaFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%spublic class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%s%spublic class %s%s", gen.genComment(fieldName, v.Doc), gen.genJavaAccessorType(), fieldName, gen.StructAST[v.Name])
 	}
 }
 
@@ -227,44 +266,212 @@ func (gen *CodeGenerator) JavaGroup(v *Group) {
 // syntax.
 func (gen *CodeGenerator) JavaAttributeGroup(v *AttributeGroup) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
+		fieldName := genJavaFieldName(v.Name, true)
+		gen.addSymbol(v, fieldName)
+		if gen.Mixins {
+			content, accessors := gen.genJavaAttributeGroupFields(v)
+			gen.StructAST[v.Name] = " {\n" + gen.genJavaAccessors(content+genJavaMixinAccessors(accessors)) + "}\n"
+			gen.genJavaMixin(v.Name, v.Doc, accessors, nil)
+			gen.Field += fmt.Sprintf("%s%spublic class %s%s%s", gen.genComment(fieldName, v.Doc), gen.genJavaAccessorType(), fieldName, genJavaMixinImplements("", []string{genJavaMixinName(v.Name)}), gen.StructAST[v.Name])
+			return
+		}
 		content := " {\n"
 		for _, attribute := range v.Attributes {
 			var required = ", required = true"
 			if attribute.Optional {
 				required = ""
 			}
-			fieldType := genJavaFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
-			content += fmt.Sprintf("\t@XmlAttribute(name = \"%s\"%s)\n\tprotected %sAttr %s;\n", attribute.Name, required, fieldType, genJavaFieldName(attribute.Name, false))
+			fieldType := gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
+			content += genJavaDeprecatedAnnotation(attribute.Deprecated, attribute.Deprecation) + fmt.Sprintf("\t@XmlAttribute(name = \"%s\"%s)\n\tprotected %sAttr %s;\n", attribute.Name, required, fieldType, genJavaFieldName(attribute.Name, false))
 		}
-		content += "}\n"
+		content += genJavaWildcardFields(content, false, v.AnyAttribute)
+		content = gen.genJavaAccessors(content) + "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genJavaFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%spublic class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%s%spublic class %s%s", gen.genComment(fieldName, v.Doc), gen.genJavaAccessorType(), fieldName, gen.StructAST[v.Name])
+	}
+}
+
+// javaMixinAccessor holds the property name, the field name and the type of
+// a field accessed by the interface of a mixin.
+type javaMixinAccessor struct {
+	name, field, fieldType string
+}
+
+// genJavaMixinName generate the interface name of the attribute group or
+// group mixin for Java code.
+func genJavaMixinName(name string) string {
+	return genJavaFieldName(name, false) + "Mixin"
+}
+
+// genJavaAttributeGroupFields generate the fields of the attribute group
+// inlined in the class for Java code in mixin mode, along with their
+// accessors.
+func (gen *CodeGenerator) genJavaAttributeGroupFields(v *AttributeGroup) (content string, accessors []javaMixinAccessor) {
+	for _, attribute := range v.Attributes {
+		var required = ", required = true"
+		if attribute.Optional {
+			required = ""
+		}
+		fieldType := gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree))
+		content += genJavaDeprecatedAnnotation(attribute.Deprecated, attribute.Deprecation) + fmt.Sprintf("\t@XmlAttribute(name = \"%s\"%s)\n\tprotected %s %sAttr;\n", attribute.Name, required, fieldType, genJavaFieldName(attribute.Name, false))
+		accessors = append(accessors, javaMixinAccessor{genJavaFieldName(attribute.Name, false), genJavaFieldName(attribute.Name, false) + "Attr", fieldType})
+	}
+	content += genJavaWildcardFields(content, false, v.AnyAttribute)
+	return
+}
+
+// genJavaGroupFields generate the fields of the group inlined in the class
+// for Java code in mixin mode, along with their accessors. The fields of the
+// nested groups are inlined, and the mixins of the group and the inlined
+// groups are returned.
+func (gen *CodeGenerator) genJavaGroupFields(v *Group) (content string, accessors []javaMixinAccessor, mixins []string) {
+	for _, element := range v.Elements {
+		var fieldType = gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
+		if element.Plural {
+			fieldType = fmt.Sprintf("List<%s>", fieldType)
+		}
+		content += genJavaDeprecatedAnnotation(element.Deprecated, element.Deprecation) + fmt.Sprintf("\t@XmlElement(required = true, name = \"%s\")\n\tprotected %s %s;\n", element.Name, fieldType, genJavaFieldName(gen.genPluralName(element.Name, element.Plural), false))
+		accessors = append(accessors, javaMixinAccessor{genJavaFieldName(gen.genPluralName(element.Name, element.Plural), false), genJavaFieldName(gen.genPluralName(element.Name, element.Plural), false), fieldType})
+	}
+	for _, group := range v.Groups {
+		if mixin := gen.getMixinGroup(group); mixin != nil {
+			c, a, m := gen.genJavaGroupFields(mixin)
+			content, accessors, mixins = joinWildcardFields(content, c, javaAnyElementField), append(accessors, a...), append(mixins, m...)
+			continue
+		}
+		var fieldType = gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
+		if group.Plural {
+			fieldType = fmt.Sprintf("List<%s>", fieldType)
+		}
+		content += fmt.Sprintf("%s\tprotected %s %s;\n", genJavaPluralAnnotation(gen.genPluralName(group.Name, group.Plural), group.Name), fieldType, genJavaFieldName(gen.genPluralName(group.Name, group.Plural), false))
+		accessors = append(accessors, javaMixinAccessor{genJavaFieldName(gen.genPluralName(group.Name, group.Plural), false), genJavaFieldName(gen.genPluralName(group.Name, group.Plural), false), fieldType})
+	}
+	content += genJavaWildcardFields(content, v.Any, false)
+	mixins = append(mixins, genJavaMixinName(v.Name))
+	return
+}
+
+// genJavaGroupMixin generates the mixin interface of the group in Java
+// language syntax. The interfaces of the nested groups inlined in the group
+// are extended by its interface.
+func (gen *CodeGenerator) genJavaGroupMixin(v *Group) {
+	var accessors []javaMixinAccessor
+	var extends []string
+	for _, element := range v.Elements {
+		var fieldType = gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree))
+		if element.Plural {
+			fieldType = fmt.Sprintf("List<%s>", fieldType)
+		}
+		accessors = append(accessors, javaMixinAccessor{genJavaFieldName(gen.genPluralName(element.Name, element.Plural), false), genJavaFieldName(gen.genPluralName(element.Name, element.Plural), false), fieldType})
+	}
+	for _, group := range v.Groups {
+		if mixin := gen.getMixinGroup(group); mixin != nil {
+			extends = append(extends, genJavaMixinName(mixin.Name))
+			continue
+		}
+		var fieldType = gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree))
+		if group.Plural {
+			fieldType = fmt.Sprintf("List<%s>", fieldType)
+		}
+		accessors = append(accessors, javaMixinAccessor{genJavaFieldName(gen.genPluralName(group.Name, group.Plural), false), genJavaFieldName(gen.genPluralName(group.Name, group.Plural), false), fieldType})
+	}
+	gen.genJavaMixin(v.Name, v.Doc, accessors, extends)
+}
+
+// genJavaMixin generates the interface of the attribute group or group
+// mixin with the getters and setters of its fields.
+func (gen *CodeGenerator) genJavaMixin(name, doc string, accessors []javaMixinAccessor, extends []string) {
+	interfaceName := genJavaMixinName(name)
+	var extension, methods string
+	if len(extends) > 0 {
+		extension = " extends " + strings.Join(extends, ", ")
+	}
+	for _, accessor := range accessors {
+		methods += fmt.Sprintf("\t%s get%s();\n\tvoid set%s(%s value);\n", accessor.fieldType, accessor.name, accessor.name, accessor.fieldType)
+	}
+	gen.Field += fmt.Sprintf("%spublic interface %s%s {\n%s}\n", gen.genComment(interfaceName, doc), interfaceName, extension, methods)
+}
+
+// genJavaMixinAccessors generate the getters and setters of the fields
+// inlined from the mixins for Java code.
+func genJavaMixinAccessors(accessors []javaMixinAccessor) (content string) {
+	for _, accessor := range accessors {
+		content += fmt.Sprintf("\n\tpublic %s get%s() {\n\t\treturn %s;\n\t}\n\n\tpublic void set%s(%s value) {\n\t\tthis.%s = value;\n\t}\n",
+			accessor.fieldType, accessor.name, accessor.field, accessor.name, accessor.fieldType, accessor.field)
+	}
+	return
+}
+
+// genJavaMixinImplements generate the implements clause of the class for the
+// mixins by given interface names, following the type extension.
+func genJavaMixinImplements(typeExtension string, mixins []string) string {
+	if len(mixins) == 0 {
+		return ""
 	}
+	if typeExtension == "" {
+		return " implements " + strings.Join(mixins, ", ")
+	}
+	return "implements " + strings.Join(mixins, ", ")
 }
 
 // JavaElement generates code for element XML schema in Java language syntax.
 func (gen *CodeGenerator) JavaElement(v *Element) {
+	if gen.RootWrappers && isRootElement(v, gen.ProtoTree) {
+		gen.genJavaRootWrapper(v)
+		return
+	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
+		var fieldType = gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
 		if v.Plural {
 			fieldType = fmt.Sprintf("List<%s>", fieldType)
 		}
-		content := fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(v.Name, false))
+		content := gen.genJavaAccessors(fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(v.Name, false)))
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlElement(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, genJavaFieldName(v.Name, true), gen.StructAST[v.Name])
+		fieldName := genJavaFieldName(v.Name, true)
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlElement(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, fieldName, gen.StructAST[v.Name])
 	}
 }
 
+// genJavaRootWrapper generates the typed wrapper of the root element, which
+// extends the type of the element, with the parseXML and toXML methods for
+// unmarshalling and marshalling the XML documents by JAXB.
+func (gen *CodeGenerator) genJavaRootWrapper(v *Element) {
+	wrapperName := genJavaFieldName(v.Name, false) + "Root"
+	if _, ok := gen.StructAST[wrapperName]; ok {
+		return
+	}
+	gen.addSymbol(v, wrapperName)
+	var namespace string
+	if gen.TargetNamespace != "" {
+		namespace = fmt.Sprintf(", namespace = \"%s\"", gen.TargetNamespace)
+	}
+	gen.StructAST[wrapperName] = fmt.Sprintf(` {
+	public static %s parseXML(String xml) throws JAXBException {
+		return (%s) JAXBContext.newInstance(%s.class).createUnmarshaller().unmarshal(new StringReader(xml));
+	}
+
+	public String toXML() throws JAXBException {
+		StringWriter writer = new StringWriter();
+		JAXBContext.newInstance(%s.class).createMarshaller().marshal(this, writer);
+		return writer.toString();
+	}
+}
+`, wrapperName, wrapperName, wrapperName, wrapperName)
+	gen.Field += fmt.Sprintf("%s@XmlRootElement(name = \"%s\"%s)\npublic class %s extends %s%s", gen.genComment(wrapperName, v.Doc), v.Name, namespace, wrapperName, gen.genJavaFieldType(trimNSPrefix(v.Type)), gen.StructAST[wrapperName])
+}
+
 // JavaAttribute generates code for attribute XML schema in Java language syntax.
 func (gen *CodeGenerator) JavaAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		var fieldType = genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
+		var fieldType = gen.genJavaFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree))
 		if v.Plural {
 			fieldType = fmt.Sprintf("List<%s>", fieldType)
 		}
-		content := fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(v.Name, false))
+		content := gen.genJavaAccessors(fmt.Sprintf("\tprotected %s %s;\n", fieldType, genJavaFieldName(v.Name, false)))
 		gen.StructAST[v.Name] = content
-		gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, genJavaFieldName(v.Name, true), gen.StructAST[v.Name])
+		fieldName := genJavaFieldName(v.Name, true)
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("\n@XmlAccessorType(XmlAccessType.FIELD)\n@XmlAttribute(required = true, name = \"%s\")\npublic class %s {\n%s}\n", v.Name, fieldName, gen.StructAST[v.Name])
 	}
 }
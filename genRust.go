@@ -10,8 +10,11 @@ package xgen
 
 import (
 	"fmt"
-	"os"
-	"reflect"
+	"math"
+	"math/big"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -96,23 +99,267 @@ var (
 // GenRust generate Go programming language source code for XML schema
 // definition files.
 func (gen *CodeGenerator) GenRust() error {
+	gen.resolveRustRecursiveFields()
 	fieldNameCount = make(map[string]int)
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isPruned(ele) {
 			continue
 		}
-		funcName := fmt.Sprintf("Rust%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+		gen.genDeclaration("Rust", ele)
 	}
-	f, err := os.Create(gen.FileWithExtension(".rs"))
-	if err != nil {
+	declared := len(gen.Field)
+	if err := gen.checkAsserts(); err != nil {
 		return err
 	}
-	defer f.Close()
-	var extern = "use serde::{Deserialize, Serialize};\n"
-	source := []byte(fmt.Sprintf("%s\n\n%s\n%s", copyright, extern, gen.Field))
-	f.Write(source)
-	return err
+	gen.genRustVisitor()
+	gen.genRustEqual()
+	if gen.JSONValue {
+		gen.Field += genRustJSONValue(gen.Field)
+	}
+	var extern = "use serde::{Deserialize, Serialize};\n" + gen.genRustUses()
+	if gen.Validation != ValidationMethod && strings.Contains(gen.Field, "xsd_pattern(") {
+		// The unions of the date and time types match their lexical forms.
+		extern += "use regex::Regex;\n"
+	}
+	if gen.Validation == ValidationMethod {
+		extern += gen.genRustValidationImports(gen.Field)
+		if strings.Contains(gen.Field, "ValidationError") {
+			gen.mixinCode += gen.genRustErrorTypeCode()
+		}
+		if gen.ValidationTracing {
+			gen.mixinCode += genRustValidationTraceCode()
+		}
+		if strings.Contains(gen.Field, "xsd_digits(") {
+			gen.mixinCode += genRustDigitsCode()
+		}
+	}
+	if strings.Contains(gen.Field, "xsd_pattern(") {
+		gen.mixinCode += genRustPatternCode(gen.Field)
+	}
+	if gen.RustDecimal && rustDecimalTypePattern.MatchString(gen.Field) {
+		extern += "use rust_decimal::Decimal;\n"
+	}
+	if gen.RustChrono {
+		gen.mixinCode = gen.genRustChrono(gen.Field) + gen.mixinCode
+	}
+	if strings.Contains(gen.Field, rustWildcardField) {
+		gen.mixinCode = gen.genRustAny() + gen.mixinCode
+	}
+	if strings.Contains(gen.Field, rustNillableType) {
+		gen.mixinCode = gen.genRustNillable() + gen.mixinCode
+	}
+	if gen.BooleanForm != BooleanFormNative && strings.Contains(gen.Field, booleanTypes["Rust"]) {
+		gen.mixinCode = gen.genRustBoolean() + gen.mixinCode
+	}
+	if gen.DecimalForm != DecimalFormNative {
+		if scales := getDecimalScales(decimalTypes["Rust"], gen.Field); len(scales) > 0 {
+			gen.mixinCode = gen.genRustDecimal(scales) + gen.mixinCode
+		}
+	}
+	gen.mixinCode = gen.genRustDerives(gen.mixinCode)
+	validator := gen.FileWithExtension(".validator.rs")
+	if gen.RustModules {
+		dir := gen.getRustModulesDir()
+		if err := gen.genRustModules(dir, extern, declared); err != nil {
+			return err
+		}
+		validator = filepath.Join(dir, rustValidatorModule+".rs")
+	} else {
+		gen.Field = gen.genRustDerives(gen.Field)
+		source := []byte(fmt.Sprintf("%s\n\n%s%s\n%s%s", gen.fileHeader(), genRustAllowDeprecated(gen.Field), extern, gen.mixinCode, gen.Field))
+		if err := gen.WriteFile(gen.FileWithExtension(".rs"), source); err != nil {
+			return err
+		}
+	}
+	if gen.Validation == ValidationStandalone {
+		return gen.genRustValidator(validator)
+	}
+	return nil
+}
+
+// genRustBoolean generate the boolean type of the generated structs for Rust
+// code, which accepts both the true/false and the 1/0 lexical forms of
+// xs:boolean, and is serialized in the canonical form of the boolean form.
+func (gen *CodeGenerator) genRustBoolean() string {
+	t, f := gen.getBooleanLiterals()
+	return fmt.Sprintf(`
+%s#[derive(Debug, Default, PartialEq, Eq, Clone, Copy)]
+pub struct XsdBoolean(pub bool);
+
+impl Serialize for XsdBoolean {
+	fn serialize<S: serde::Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+		serializer.serialize_str(if self.0 { "%s" } else { "%s" })
+	}
+}
+
+impl<'de> Deserialize<'de> for XsdBoolean {
+	fn deserialize<D: serde::Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+		let value = String::deserialize(deserializer)?;
+		match value.trim() {
+			"true" | "1" => Ok(XsdBoolean(true)),
+			"false" | "0" => Ok(XsdBoolean(false)),
+			_ => Err(serde::de::Error::custom(format!("invalid xs:boolean value {:?}", value))),
+		}
+	}
+}
+
+impl From<bool> for XsdBoolean {
+	fn from(v: bool) -> Self {
+		XsdBoolean(v)
+	}
+}
+
+impl From<XsdBoolean> for bool {
+	fn from(v: XsdBoolean) -> Self {
+		v.0
+	}
+}
+`, gen.genComment("XsdBoolean", fmt.Sprintf("the xs:boolean, which accepts both the true/false and the 1/0 lexical forms, and is serialized as %s or %s.", t, f)), t, f)
+}
+
+// genRustDecimal generate the decimal types of the generated structs for Rust
+// code, which parse and serialize the lexical form of xs:decimal regardless
+// of the locale, for the scales used by the generated structs.
+func (gen *CodeGenerator) genRustDecimal(scales []int) string {
+	code := `
+fn parse_xsd_decimal(value: &str) -> Option<f64> {
+	let value = value.trim();
+	let digits = value.strip_prefix(|c| c == '+' || c == '-').unwrap_or(value);
+	if digits.is_empty() || digits == "." || digits.matches('.').count() > 1 || !digits.chars().all(|c| c == '.' || c.is_ascii_digit()) {
+		return None;
+	}
+	value.parse::<f64>().ok()
+}
+
+fn format_xsd_decimal(value: f64, scale: Option<usize>) -> Option<String> {
+	if !value.is_finite() {
+		return None;
+	}
+	let value = match scale {
+		Some(scale) => format!("{:.*}", scale, value),
+		None => format!("{}", value),
+	};
+	if value.trim_start_matches('-').chars().all(|c| c == '0' || c == '.') {
+		return Some(value.trim_start_matches('-').to_string());
+	}
+	Some(value)
+}
+`
+	for _, scale := range scales {
+		structName, doc, scaleArg := genDecimalTypeName("XsdDecimal", scale), "the xs:decimal serialized with the fewest fraction digits representing the value.", "None"
+		if scale >= 0 {
+			doc, scaleArg = fmt.Sprintf("the xs:decimal serialized with %d fraction digits.", scale), fmt.Sprintf("Some(%d)", scale)
+		}
+		code += fmt.Sprintf(`
+%s#[derive(Debug, Default, PartialEq, PartialOrd, Clone, Copy)]
+pub struct %s(pub f64);
+
+impl Serialize for %s {
+	fn serialize<S: serde::Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+		match format_xsd_decimal(self.0, %s) {
+			Some(value) => serializer.serialize_str(&value),
+			None => Err(serde::ser::Error::custom(format!("invalid xs:decimal value {}", self.0))),
+		}
+	}
+}
+
+impl<'de> Deserialize<'de> for %s {
+	fn deserialize<D: serde::Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+		let value = String::deserialize(deserializer)?;
+		match parse_xsd_decimal(&value) {
+			Some(v) => Ok(%s(v)),
+			None => Err(serde::de::Error::custom(format!("invalid xs:decimal value {:?}", value))),
+		}
+	}
+}
+
+impl PartialEq<f64> for %s {
+	fn eq(&self, other: &f64) -> bool {
+		self.0 == *other
+	}
+}
+
+impl PartialOrd<f64> for %s {
+	fn partial_cmp(&self, other: &f64) -> Option<std::cmp::Ordering> {
+		self.0.partial_cmp(other)
+	}
+}
+
+impl From<f64> for %s {
+	fn from(v: f64) -> Self {
+		%s(v)
+	}
+}
+
+impl From<%s> for f64 {
+	fn from(v: %s) -> Self {
+		v.0
+	}
+}
+`, gen.genComment(structName, doc), structName, structName, scaleArg, structName, structName, structName, structName, structName, structName, structName, structName)
+	}
+	return code
+}
+
+// genRustDigitsCode generate the function counting the total digits and the
+// fraction digits of the numeric values validated against the totalDigits
+// and fractionDigits facets for Rust code, in the canonical form of the
+// value without the leading zeros of the integer part and the trailing zeros
+// of the fraction part.
+func genRustDigitsCode() string {
+	return `
+fn xsd_digits(value: &str) -> (usize, usize) {
+	let value = value.trim_start_matches('-').trim_start_matches('0');
+	match value.find('.') {
+		Some(i) => (value.len() - 1, value.len() - i - 1),
+		None => (value.len(), 0),
+	}
+}
+`
+}
+
+// rustPatternRegexp matches the patterns of the pattern facets checked by the
+// generated Rust code.
+var rustPatternRegexp = regexp.MustCompile(`xsd_pattern\(("(?:[^"\\]|\\.)*")\)`)
+
+// genRustPatternCode generate the function returning the compiled patterns
+// of the pattern facets checked by given code for Rust code, which compiles
+// the patterns once on the first validation instead of on each validation,
+// as the patterns of the Go package.
+func genRustPatternCode(code string) string {
+	patterns := map[string]bool{}
+	for _, match := range rustPatternRegexp.FindAllStringSubmatch(code, -1) {
+		patterns[match[1]] = true
+	}
+	return fmt.Sprintf(`
+// xsd_pattern returns the compiled pattern by its expression, the patterns are compiled once.
+fn xsd_pattern(pattern: &str) -> &'static Regex {
+	static XSD_PATTERNS: std::sync::OnceLock<std::collections::HashMap<&'static str, Regex>> = std::sync::OnceLock::new();
+	&XSD_PATTERNS.get_or_init(|| {
+		[%s].into_iter().map(|pattern| (pattern, Regex::new(pattern).unwrap())).collect()
+	})[pattern]
+}
+`, strings.Join(sortedKeys(patterns), ", "))
+}
+
+// genRustValidator writes the validation functions for the generated types
+// into a standalone validator module, which should be declared as a child
+// module of the generated types.
+func (gen *CodeGenerator) genRustValidator(name string) error {
+	extern := "use super::*;\n" + gen.genRustValidationImports(gen.ValidationCode)
+	if strings.Contains(gen.ValidationCode, "ValidationError") {
+		gen.ValidationCode = gen.genRustErrorTypeCode() + gen.ValidationCode
+	}
+	if gen.ValidationTracing {
+		gen.ValidationCode = genRustValidationTraceCode() + gen.ValidationCode
+	}
+	if strings.Contains(gen.ValidationCode, "xsd_digits(") {
+		gen.ValidationCode = genRustDigitsCode() + gen.ValidationCode
+	}
+	if strings.Contains(gen.ValidationCode, "xsd_pattern(") {
+		gen.ValidationCode = genRustPatternCode(gen.ValidationCode) + gen.ValidationCode
+	}
+	return gen.WriteFile(name, []byte(fmt.Sprintf("%s\n\n%s%s\n%s", gen.fileHeader(), genRustAllowDeprecated(gen.Field), extern, gen.ValidationCode)))
 }
 
 // genRustFieldName generate struct field name for Rust code.
@@ -153,8 +400,8 @@ func genRustStructName(name string, unique bool) (structName string) {
 }
 
 // genRustFieldType generate struct field type for Rust code.
-func genRustFieldType(name string) string {
-	if _, ok := rustBuildinType[name]; ok {
+func (gen *CodeGenerator) genRustFieldType(name string) string {
+	if _, ok := rustBuildinType[name]; ok || gen.builtInTypes[name] {
 		return name
 	}
 	fieldType := genRustStructName(name, false)
@@ -170,7 +417,7 @@ func escapeRustString(s string) string {
 	return s
 }
 
-func genRustFieldCode(name string, fieldType string, plural bool, optional bool, restriction *Restriction) string {
+func (gen *CodeGenerator) genRustFieldCode(name string, fieldType string, plural bool, optional bool, restriction *Restriction) string {
 	attributes := ""
 	// Only add validation attributes if there are restrictions
 	// if restriction != nil && !restriction.IsEmpty() {
@@ -202,25 +449,168 @@ func genRustFieldCode(name string, fieldType string, plural bool, optional bool,
 	// 		enumValidation := fmt.Sprintf("\t#[validate(enumerate = [%s])]\n", enumValues)
 	// 		attributes += enumValidation
 	// 	}
-	// } else if !isRustBuiltInType(fieldType) {
+	// } else if !gen.isRustBuiltInType(fieldType) {
 	// 	attributes += "\t#[validate]\n"
 	// }
 
-	fields := genRustFieldType(fieldType)
+	attributes += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", gen.genRustFieldRename(name), genRustFieldName(name), gen.genRustFieldDeclType(fieldType, plural, optional))
+	return attributes
+}
+
+// genRustMemberCode generate the field of the element or group for Rust code,
+// the field of the repeated one is named after the plural of the name in
+// plural names mode, and renamed to the name by serde.
+func (gen *CodeGenerator) genRustMemberCode(name, fieldType string, plural, optional bool) string {
+	return fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", gen.genRustFieldRename(name), genRustFieldName(gen.genRustPluralName(name, plural)), gen.genRustFieldDeclType(fieldType, plural, optional))
+}
+
+// genRustFieldDeclType generate the declared type of the struct field for
+// Rust code, wrapping the field type by Vec if plural and by Option if
+// optional.
+func (gen *CodeGenerator) genRustFieldDeclType(fieldType string, plural, optional bool) string {
+	fields := gen.genRustFieldType(fieldType)
 	if plural {
 		fields = "Vec<" + fields + ">"
 	}
 	if optional {
 		fields = "Option<" + fields + ">"
 	}
+	return fields
+}
 
-	attributes += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", genRustFieldRename(name), genRustFieldName(name), fields)
-	return attributes
+func (gen *CodeGenerator) genRustStructCode(name string, doc string, fieldContent string) string {
+	fieldContent = gen.genRustBoxedFields(name, fieldContent)
+	gen.addVisitorType(name, fieldContent)
+	redact := genRustRedact(name, fieldContent)
+	defaults, ok := genRustDefaultImpl(name, fieldContent)
+	derive := "Debug, Default, PartialEq, Clone, Serialize, Deserialize"
+	if ok {
+		derive = "Debug, PartialEq, Clone, Serialize, Deserialize"
+	}
+	fieldContent, accessors := gen.genRustAccessors(name, fieldContent)
+	content := fmt.Sprintf("\n%s#[derive(%s)]\npub struct %s {\n%s}\n", gen.genComment(name, doc), derive, name, fieldContent)
+	return content + defaults + accessors + redact
+}
+
+// genRustEnumVariantName generate enum variant name of the enumerated value
+// for Rust code. The letters and digits of the value are kept, and the value
+// which doesn't start with a letter is prefixed by Value.
+func genRustEnumVariantName(value string) (variantName string) {
+	for _, str := range strings.FieldsFunc(value, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	}) {
+		variantName += MakeFirstUpperCase(str)
+	}
+	if variantName == "" || ('0' <= variantName[0] && variantName[0] <= '9') {
+		variantName = "Value" + variantName
+	}
+	if _, ok := rustKeywords[variantName]; ok {
+		variantName += "Value"
+	}
+	return
+}
+
+// genRustEnumCode generate the unit enum of the enumerated values for Rust
+// code, the variants are renamed to the values and the first one is the
+// default.
+func (gen *CodeGenerator) genRustEnumCode(name, doc string, values []string) string {
+	var variants string
+	variantNameCount := map[string]int{}
+	for i, value := range values {
+		variantName := genRustEnumVariantName(value)
+		variantNameCount[variantName]++
+		if count := variantNameCount[variantName]; count != 1 {
+			variantName = fmt.Sprintf("%s%d", variantName, count)
+		}
+		if i == 0 {
+			variants += "\t#[default]\n"
+		}
+		variants += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\t%s,\n", escapeRustString(value), variantName)
+	}
+	return fmt.Sprintf("\n%s#[derive(Debug, Default, PartialEq, Clone, Serialize, Deserialize)]\npub enum %s {\n%s}\n", gen.genComment(name, doc), name, variants)
+}
+
+// genRustUnionCode generate the enum of the union for Rust code, with a
+// variant holding the value of each member type. The value is deserialized
+// as the first member type in the order of the union accepting its lexical
+// form, and serialized as the value of its variant. The first variant is the
+// default.
+func (gen *CodeGenerator) genRustUnionCode(name, doc string, members kvPairList) string {
+	var variants, parses, serializes, first string
+	variantNameCount := map[string]int{}
+	for _, member := range members {
+		variantName := genRustStructName(member.key, false)
+		variantNameCount[variantName]++
+		if count := variantNameCount[variantName]; count != 1 {
+			variantName = fmt.Sprintf("%s%d", variantName, count)
+		}
+		if first == "" {
+			first = variantName
+		}
+		fieldType := gen.genRustFieldType(member.value)
+		variants += fmt.Sprintf("\t%s(%s),\n", variantName, fieldType)
+		parses += fmt.Sprintf("\t\tif let Some(v) = %s {\n\t\t\treturn Ok(%s::%s(v));\n\t\t}\n", gen.genRustUnionParseExpr(member.key, fieldType), name, variantName)
+		serializes += fmt.Sprintf("\t\t\t%s::%s(v) => v.serialize(serializer),\n", name, variantName)
+	}
+	return fmt.Sprintf(`
+%s#[derive(Debug, PartialEq, Clone)]
+pub enum %s {
+%s}
+
+impl Default for %s {
+	fn default() -> Self {
+		%s::%s(Default::default())
+	}
+}
+
+impl Serialize for %s {
+	fn serialize<S: serde::Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+		match self {
+%s		}
+	}
+}
+
+impl<'de> Deserialize<'de> for %s {
+	fn deserialize<D: serde::Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+		let value = String::deserialize(deserializer)?;
+%s		Err(serde::de::Error::custom(format!("invalid %s value {:?}", value)))
+	}
+}
+`, gen.genComment(name, doc), name, variants, name, name, first, name, serializes, name, parses, name)
 }
 
-func genRustStructCode(name string, doc string, fieldContent string) string {
-	content := fmt.Sprintf("\n%s#[derive(Debug, Default, PartialEq, Clone, Serialize, Deserialize)]\npub struct %s {\n%s}\n", genFieldComment(name, doc, "//"), name, fieldContent)
-	return content
+// rustUnionLexicalPatterns holds the patterns of the lexical forms of the
+// built-in date and time types, which are held by strings unless the chrono
+// types are used, so the union of them and other types tries the next member
+// types on the values of other forms.
+var rustUnionLexicalPatterns = map[string]string{
+	"date":       `-?\d{4,}-\d{2}-\d{2}(Z|[+-]\d{2}:\d{2})?`,
+	"dateTime":   `-?\d{4,}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?`,
+	"time":       `\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})?`,
+	"duration":   `-?P(\d+Y)?(\d+M)?(\d+D)?(T(\d+H)?(\d+M)?(\d+(\.\d+)?S)?)?`,
+	"gYear":      `-?\d{4,}(Z|[+-]\d{2}:\d{2})?`,
+	"gYearMonth": `-?\d{4,}-\d{2}(Z|[+-]\d{2}:\d{2})?`,
+	"gMonth":     `--\d{2}(Z|[+-]\d{2}:\d{2})?`,
+	"gMonthDay":  `--\d{2}-\d{2}(Z|[+-]\d{2}:\d{2})?`,
+	"gDay":       `---\d{2}(Z|[+-]\d{2}:\d{2})?`,
+}
+
+// genRustUnionParseExpr generate the expression parsing the lexical form of
+// the union in the value variable as the member type by given name for Rust
+// code, which is None if the member type doesn't accept it.
+func (gen *CodeGenerator) genRustUnionParseExpr(memberName, fieldType string) string {
+	switch {
+	case fieldType == "String":
+		if pattern, ok := rustUnionLexicalPatterns[memberName]; ok {
+			return fmt.Sprintf("Some(value.clone()).filter(|v| xsd_pattern(\"%s\").is_match(v.trim()))", escapeRustString("^(?:"+pattern+")$"))
+		}
+		return "value.parse::<String>().ok()"
+	case fieldType == "bool":
+		return `match value.trim() { "true" | "1" => Some(true), "false" | "0" => Some(false), _ => None }`
+	case fieldType == "char" || gen.isRustNumericType(fieldType) && !isDecimalType(fieldType) && fieldType != rustDecimalType:
+		return fmt.Sprintf("value.trim().parse::<%s>().ok()", fieldType)
+	}
+	return fmt.Sprintf("<%s>::deserialize(serde::de::IntoDeserializer::<serde::de::value::Error>::into_deserializer(value.as_str())).ok()", fieldType)
 }
 
 // RustSimpleType generates code for simple type XML schema in Rust language
@@ -229,138 +619,1129 @@ func (gen *CodeGenerator) RustSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
 			fieldType := getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)
-			content := genRustFieldCode(v.Name, fieldType, true, false, &v.Restriction)
+			content := gen.genRustFieldCode(v.Name, fieldType, true, false, &v.Restriction)
 			gen.StructAST[v.Name] = content
-			gen.Field += genRustStructCode(genRustStructName(v.Name, true), v.Doc, gen.StructAST[v.Name])
+			structName := genRustStructName(v.Name, true)
+			gen.addSymbol(v, structName)
+			gen.Field += genRustCustomType(gen.genRustStructCode(structName, v.Doc, gen.StructAST[v.Name]), v.Custom)
+			gen.genRustValidationCode(structName, gen.genRustListValidation(v, fieldType))
 			return
 		}
 	}
 	if v.Union && len(v.MemberTypes) > 0 {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			var content string
-			for _, member := range toSortedPairs(v.MemberTypes) {
-				memberName := member.key
-				memberType := member.value
-
-				if memberType == "" { // fix order issue
-					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
+			// The union of enumerated types is a single enum of the values
+			// of all the members.
+			if values := getUnionEnumValues(v, gen.ProtoTree); len(values) > 0 {
+				structName := genRustStructName(v.Name, true)
+				gen.addSymbol(v, structName)
+				gen.StructAST[v.Name] = genRustCustomType(gen.genRustEnumCode(structName, v.Doc, values), v.Custom)
+				gen.Field += gen.StructAST[v.Name]
+				gen.genRustValidationCode(structName, "")
+				return
+			}
+			members := getUnionMembers(v)
+			for i, member := range members {
+				if member.value == "" { // fix order issue
+					members[i].value = getBasefromSimpleType(member.key, gen.ProtoTree)
 				}
-				content += genRustFieldCode(v.Name, memberType, false, false, &v.Restriction)
 			}
-			gen.StructAST[v.Name] = content
-			gen.Field += genRustStructCode(genRustStructName(v.Name, true), "", gen.StructAST[v.Name])
+			structName := genRustStructName(v.Name, true)
+			gen.addSymbol(v, structName)
+			gen.StructAST[v.Name] = genRustCustomType(gen.genRustUnionCode(structName, v.Doc, members), v.Custom)
+			gen.Field += gen.StructAST[v.Name]
+			gen.genRustValidationCode(structName, "")
 		}
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		fieldType := getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)
-		content := genRustFieldCode(v.Name, fieldType, false, false, &v.Restriction)
+		if gen.isTypeAlias(v) {
+			// Impls can't be declared on the alias of a foreign type, and
+			// there are no facets to check.
+			gen.StructAST[v.Name] = gen.genRustFieldType(fieldType)
+			structName := genRustStructName(v.Name, true)
+			gen.addSymbol(v, structName)
+			gen.Field += fmt.Sprintf("\n%spub type %s = %s;\n", gen.genComment(structName, v.Doc), structName, gen.StructAST[v.Name])
+			return
+		}
+		content := gen.genRustFieldCode(v.Name, fieldType, false, false, &v.Restriction)
 		gen.StructAST[v.Name] = content
-		gen.Field += genRustStructCode(genRustStructName(v.Name, true), v.Doc, gen.StructAST[v.Name])
+		structName := genRustStructName(v.Name, true)
+		gen.addSymbol(v, structName)
+		gen.Field += genRustCustomType(gen.genRustStructCode(structName, v.Doc, gen.StructAST[v.Name]), v.Custom)
+		gen.genRustValidationCode(structName, gen.genRustFieldValidation(v.Name, fieldType, false, false, &v.Restriction))
+		if normalize := gen.genRustFieldNormalize(v.Name, fieldType, false, false, &v.Restriction); normalize != "" {
+			gen.genRustNormalizeCode(structName, normalize)
+		}
 	}
 }
 
 // RustComplexType generates code for complex type XML schema in Rust language
 // syntax.
 func (gen *CodeGenerator) RustComplexType(v *ComplexType) {
-	var content string
+	choices := gen.getRustChoices(v)
+	if c := getRustChoiceType(v, choices); c != nil {
+		gen.genRustChoiceType(v, c)
+		return
+	}
+	var content, validation, normalize string
+	var mixins []string
+	var choiceFields []*rustChoice
 	for _, attrGroup := range v.AttributeGroup {
+		if mixin := gen.getMixinAttributeGroup(attrGroup.Ref); mixin != nil {
+			c, val, norm := gen.genRustAttributeGroupFields(mixin)
+			content, validation, normalize = joinWildcardFields(content, c, rustWildcardField), validation+val, normalize+norm
+			mixins = append(mixins, mixin.Name)
+			continue
+		}
 		fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-		content += genRustFieldCode(attrGroup.Name, fieldType, false, false, nil)
+		content += gen.genRustFieldCode(attrGroup.Name, fieldType, false, false, nil)
+		validation += gen.genRustFieldValidation(attrGroup.Name, fieldType, false, false, nil)
+		normalize += gen.genRustFieldNormalize(attrGroup.Name, fieldType, false, false, nil)
 	}
 	for _, attribute := range v.Attributes {
 		fieldType := getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)
-		content += genRustFieldCode(attribute.Name, fieldType, attribute.Plural, attribute.Optional, nil)
+		content += genRustValueDoc(attribute.Default, attribute.Fixed) + genRustDeprecatedAttr(attribute.Deprecated, attribute.Deprecation) + genRustCustomAttrs(attribute.Custom) + genRustSensitiveDoc(attribute.Sensitive) + gen.genRustFieldCode(attribute.Name, fieldType, attribute.Plural, attribute.Optional, nil)
+		validation += gen.genRustRequiredValidation(fieldType, attribute)
+		validation += gen.genRustFieldValidation(attribute.Name, fieldType, attribute.Plural, attribute.Optional, &attribute.Restriction)
+		normalize += gen.genRustFieldNormalize(attribute.Name, fieldType, attribute.Plural, attribute.Optional, &attribute.Restriction)
 	}
 	for _, group := range v.Groups {
+		if mixin := gen.getMixinGroup(group); mixin != nil {
+			c, val, norm, m := gen.genRustGroupFields(mixin)
+			content, validation, normalize = joinWildcardFields(content, c, rustWildcardField), validation+val, normalize+norm
+			mixins = append(mixins, m...)
+			continue
+		}
 		fieldType := getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)
-		content += genRustFieldCode(group.Name, fieldType, group.Plural, false, nil)
+		content += gen.genRustMemberCode(group.Name, fieldType, group.Plural, false)
+		validation += gen.genRustFieldValidation(gen.genRustPluralName(group.Name, group.Plural), fieldType, group.Plural, false, nil)
+		normalize += gen.genRustFieldNormalize(gen.genRustPluralName(group.Name, group.Plural), fieldType, group.Plural, false, nil)
 	}
-	for _, element := range v.Elements {
+	// The elements of the mixed type are the variants of the enum of the
+	// content field, along with the character data.
+	elements, mixed := v.Elements, gen.getRustMixedContent(v)
+	if mixed != nil {
+		elements = nil
+		content += gen.genRustMixedField(genRustStructName(v.Name, false), mixed)
+		validation += gen.genRustFieldValidation(mixed.fieldName, mixed.enumName, true, false, nil)
+		normalize += gen.genRustFieldNormalize(mixed.fieldName, mixed.enumName, true, false, nil)
+	}
+	for _, element := range elements {
+		if c, ok := choices[element.Choice]; ok {
+			// The elements of the choice are the variants of the enum of
+			// the choice field, generated at the first one, or the fields
+			// of the accessors of the enum.
+			if c.enumName == "" {
+				content += gen.genRustChoiceField(genRustStructName(v.Name, false), c, len(choiceFields))
+				if !c.fields {
+					validation += gen.genRustFieldValidation(c.fieldName, c.enumName, false, c.Optional, nil)
+					normalize += gen.genRustFieldNormalize(c.fieldName, c.enumName, false, c.Optional, nil)
+				}
+				choiceFields = append(choiceFields, c)
+			}
+			if !c.fields {
+				continue
+			}
+		}
 		fieldType := getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)
-		content += genRustFieldCode(element.Name, fieldType, element.Plural, element.Optional, nil)
+		c, val, norm := gen.genRustElementFields(element, fieldType)
+		if _, ok := choices[element.Choice]; ok {
+			c = genRustChoiceElementField(c)
+		}
+		content, validation, normalize = content+c, validation+val, normalize+norm
 	}
+	content += genRustWildcardField(content, v.Any || v.AnyAttribute)
 	if len(v.Base) > 0 {
 		fieldType := getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)
-		if isRustBuiltInType(v.Base) {
-			content += genRustFieldCode("value", fieldType, false, false, nil)
+		if gen.isRustBuiltInType(v.Base) {
+			content += gen.genRustFieldCode("value", fieldType, false, false, nil)
 		} else {
 			fieldName := genRustFieldName(fieldType)
 			// If the type is not a built-in one, add the base type as a nested field tagged with flatten
 			content += fmt.Sprintf("\t#[serde(flatten)]\n\tpub %s: %s,\n", fieldName, fieldType)
+			validation += gen.genRustFieldValidation(fieldType, fieldType, false, false, nil)
+			normalize += gen.genRustFieldNormalize(fieldType, fieldType, false, false, nil)
 		}
 	}
+	validation += gen.genRustUniqueValidation(v.Name)
+	validation += gen.genRustAssertValidation(v, v.Name)
 
 	if _, ok := gen.StructAST[v.Name]; !ok {
+		structName := genRustStructName(v.Name, true)
+		gen.addSymbol(v, structName)
+		if shared := gen.getSharedAnonymousType(v, structName); shared != "" {
+			gen.genRustTypeAlias(v, structName, shared)
+			return
+		}
 		gen.StructAST[v.Name] = content
-		gen.Field += genRustStructCode(genRustStructName(v.Name, true), v.Doc, gen.StructAST[v.Name])
+		gen.Field += genRustCustomType(gen.genRustStructCode(structName, v.Doc, gen.StructAST[v.Name]), v.Custom)
+		gen.genRustValidationCode(structName, validation)
+		gen.genRustNormalizeCode(structName, normalize)
+		gen.genRustMixinImpls(structName, mixins)
+		gen.genRustExtensionConversions(v, structName)
+		gen.addEqualType(structName, gen.genRustBoxedFields(structName, content), gen.getRustFieldRestrictions(v.Elements, v.Attributes))
+		gen.genRustPatch(structName, content)
+		for _, c := range choiceFields {
+			gen.genRustChoiceEnum(c.enumName, fmt.Sprintf("the choice of the elements of the %s, only one of which is present.", structName), c)
+			if c.fields {
+				gen.genRustChoiceAccessors(structName, c)
+			}
+		}
+		if mixed != nil {
+			gen.genRustChoiceEnum(mixed.enumName, fmt.Sprintf("the content of the %s, the character data and the elements in their order.", structName), mixed)
+		}
 	} else {
 		fmt.Printf("%s\n", content)
 	}
 }
 
-func isRustBuiltInType(typeName string) bool {
-	_, builtIn := rustBuildinType[typeName]
-	return builtIn
+// genRustTypeAlias generates the anonymous complex type as an alias of the
+// structurally identical struct generated before, which shares its impls.
+// The standalone validation and normalize functions of the alias forward to
+// the ones of the shared struct.
+func (gen *CodeGenerator) genRustTypeAlias(v *ComplexType, structName, shared string) {
+	gen.StructAST[v.Name] = shared
+	gen.Field += fmt.Sprintf("\n%spub type %s = %s;\n", gen.genComment(structName, v.Doc), structName, shared)
+	if gen.Validation != ValidationStandalone {
+		return
+	}
+	validator, sharedValidator := genRustValidatorName(structName), genRustValidatorName(shared)
+	gen.ValidationCode += fmt.Sprintf("\npub fn %s(v: &%s) -> Result<(), ValidationError> {\n\t%s(v)\n}\n", validator, structName, sharedValidator)
+	if gen.ValidationMaxDepth > 0 {
+		gen.ValidationCode += fmt.Sprintf("\nfn %s_depth(v: &%s, depth: usize) -> Result<(), ValidationError> {\n\t%s_depth(v, depth)\n}\n", validator, structName, sharedValidator)
+	}
+	if gen.Normalize {
+		gen.ValidationCode += fmt.Sprintf("\npub fn %s(v: &mut %s) {\n\t%s(v)\n}\n", genRustNormalizerName(structName), structName, genRustNormalizerName(shared))
+	}
+}
+
+// genRustExtensionConversions generate the conversions between the struct
+// derived by extension and its base types for Rust code. The derived struct
+// converts into each of its ancestors by projecting the flattened base
+// fields, and provides the helpers to upcast to its direct base type and to
+// downcast from it with the default values of the extension fields.
+func (gen *CodeGenerator) genRustExtensionConversions(v *ComplexType, structName string) {
+	base := getExtensionBase(v, gen.ProtoTree)
+	if base == nil || gen.isRustBuiltInType(v.Base) {
+		return
+	}
+	baseField := genRustFieldName(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+	baseType := gen.genRustFieldType(base.Name)
+	gen.Field += fmt.Sprintf("\nimpl %s {\n\tpub fn as_%s(&self) -> &%s {\n\t\t&self.%s\n\t}\n\n\tpub fn as_%s_mut(&mut self) -> &mut %s {\n\t\t&mut self.%s\n\t}\n\n\tpub fn from_%s(v: %s) -> Self {\n\t\tSelf {\n\t\t\t%s: v,\n\t\t\t..Default::default()\n\t\t}\n\t}\n}\n",
+		structName, baseField, baseType, baseField, baseField, baseType, baseField, baseField, baseType, baseField)
+	path := "v"
+	visited := map[*ComplexType]bool{v: true}
+	for derived := v; base != nil && !visited[base]; derived, base = base, getExtensionBase(base, gen.ProtoTree) {
+		if gen.isRustBuiltInType(derived.Base) {
+			break
+		}
+		visited[base] = true
+		path += "." + genRustFieldName(getBasefromSimpleType(trimNSPrefix(derived.Base), gen.ProtoTree))
+		gen.Field += fmt.Sprintf("\nimpl From<%s> for %s {\n\tfn from(v: %s) -> Self {\n\t\t%s\n\t}\n}\n", structName, gen.genRustFieldType(base.Name), structName, path)
+	}
+}
+
+func (gen *CodeGenerator) isRustBuiltInType(typeName string) bool {
+	return rustBuildinType[typeName] || gen.builtInTypes[typeName]
 }
 
 // RustGroup generates code for group XML schema in Rust language syntax.
 func (gen *CodeGenerator) RustGroup(v *Group) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		var content string
-		for _, element := range v.Elements {
-			fieldType := getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)
-			content += genRustFieldCode(element.Name, fieldType, element.Plural, element.Optional, &element.Restriction)
+		content, validation, normalize, mixins := gen.genRustGroupFields(v)
+		gen.StructAST[v.Name] = content
+		structName := genRustStructName(v.Name, true)
+		gen.addSymbol(v, structName)
+		gen.Field += gen.genRustStructCode(structName, v.Doc, gen.StructAST[v.Name])
+		gen.genRustValidationCode(structName, validation)
+		gen.genRustNormalizeCode(structName, normalize)
+		gen.addEqualType(structName, gen.genRustBoxedFields(structName, content), gen.getRustFieldRestrictions(v.Elements, nil))
+		if gen.Mixins {
+			gen.genRustGroupMixin(v)
+			gen.genRustMixinImpls(structName, mixins)
 		}
-		for _, group := range v.Groups {
-			fieldType := getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)
-			content += genRustFieldCode(group.Name, fieldType, group.Plural, false, nil)
+	}
+}
+
+// genRustGroupFields generate the fields of the group for Rust code, along
+// with their validation and normalize code. In mixin mode the fields of the
+// nested groups are inlined, and the mixins of the group and the inlined
+// groups are returned.
+func (gen *CodeGenerator) genRustGroupFields(v *Group) (content, validation, normalize string, mixins []string) {
+	for _, element := range v.Elements {
+		fieldType := getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)
+		c, val, norm := gen.genRustElementFields(element, fieldType)
+		content, validation, normalize = content+c, validation+val, normalize+norm
+	}
+	for _, group := range v.Groups {
+		if mixin := gen.getMixinGroup(group); mixin != nil {
+			c, val, norm, m := gen.genRustGroupFields(mixin)
+			content, validation, normalize = joinWildcardFields(content, c, rustWildcardField), validation+val, normalize+norm
+			mixins = append(mixins, m...)
+			continue
 		}
-		gen.StructAST[v.Name] = content
-		gen.Field += genRustStructCode(genRustStructName(v.Name, true), v.Doc, gen.StructAST[v.Name])
+		fieldType := getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)
+		content += gen.genRustMemberCode(group.Name, fieldType, group.Plural, false)
+		validation += gen.genRustFieldValidation(gen.genRustPluralName(group.Name, group.Plural), fieldType, group.Plural, false, nil)
+		normalize += gen.genRustFieldNormalize(gen.genRustPluralName(group.Name, group.Plural), fieldType, group.Plural, false, nil)
+	}
+	content += genRustWildcardField(content, v.Any)
+	if gen.Mixins {
+		mixins = append(mixins, v.Name)
 	}
+	return
 }
 
 // RustAttributeGroup generates code for attribute group XML schema in Rust language
 // syntax.
 func (gen *CodeGenerator) RustAttributeGroup(v *AttributeGroup) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		var content string
-		for _, attribute := range v.Attributes {
-			fieldType := getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)
-			content += genRustFieldCode(attribute.Name, fieldType, attribute.Plural, attribute.Optional, &attribute.Restriction)
-		}
+		content, validation, normalize := gen.genRustAttributeGroupFields(v)
 		gen.StructAST[v.Name] = content
-		gen.Field += genRustStructCode(genRustStructName(v.Name, true), v.Doc, gen.StructAST[v.Name])
+		structName := genRustStructName(v.Name, true)
+		gen.addSymbol(v, structName)
+		gen.Field += gen.genRustStructCode(structName, v.Doc, gen.StructAST[v.Name])
+		gen.genRustValidationCode(structName, validation)
+		gen.genRustNormalizeCode(structName, normalize)
+		gen.addEqualType(structName, gen.genRustBoxedFields(structName, content), gen.getRustFieldRestrictions(nil, v.Attributes))
+		if gen.Mixins {
+			var accessors []rustMixinAccessor
+			for _, attribute := range v.Attributes {
+				fieldType := getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)
+				accessors = append(accessors, rustMixinAccessor{genRustFieldName(attribute.Name), gen.genRustFieldDeclType(fieldType, attribute.Plural, attribute.Optional)})
+			}
+			gen.genRustMixin(v.Name, v.Doc, accessors, nil)
+			gen.genRustMixinImpls(structName, []string{v.Name})
+		}
+	}
+}
+
+// genRustAttributeGroupFields generate the fields of the attribute group for
+// Rust code, along with their validation and normalize code.
+func (gen *CodeGenerator) genRustAttributeGroupFields(v *AttributeGroup) (content, validation, normalize string) {
+	for _, attribute := range v.Attributes {
+		fieldType := getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree)
+		content += genRustValueDoc(attribute.Default, attribute.Fixed) + genRustDeprecatedAttr(attribute.Deprecated, attribute.Deprecation) + genRustCustomAttrs(attribute.Custom) + genRustSensitiveDoc(attribute.Sensitive) + gen.genRustFieldCode(attribute.Name, fieldType, attribute.Plural, attribute.Optional, &attribute.Restriction)
+		validation += gen.genRustRequiredValidation(fieldType, attribute)
+		validation += gen.genRustFieldValidation(attribute.Name, fieldType, attribute.Plural, attribute.Optional, &attribute.Restriction)
+		normalize += gen.genRustFieldNormalize(attribute.Name, fieldType, attribute.Plural, attribute.Optional, &attribute.Restriction)
+	}
+	content += genRustWildcardField(content, v.AnyAttribute)
+	return
+}
+
+// rustMixinAccessor holds the name and the declared type of a field
+// accessed by the trait of a mixin.
+type rustMixinAccessor struct {
+	name, fieldType string
+}
+
+// genRustMixinName generate the trait name of the attribute group or group
+// mixin for Rust code.
+func genRustMixinName(name string) string {
+	return genRustStructName(name, false) + "Mixin"
+}
+
+// genRustMixinMacroName generate the name of the macro implementing the
+// trait of the attribute group or group mixin for Rust code.
+func genRustMixinMacroName(name string) string {
+	return "impl_" + genRustFieldName(name) + "_mixin"
+}
+
+// genRustGroupMixin generates the mixin of the group in Rust language
+// syntax. The traits of the nested groups inlined in the group are the
+// supertraits of its trait.
+func (gen *CodeGenerator) genRustGroupMixin(v *Group) {
+	var accessors []rustMixinAccessor
+	var supertraits []string
+	for _, element := range v.Elements {
+		fieldType := getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree)
+		accessors = append(accessors, rustMixinAccessor{genRustFieldName(gen.genRustPluralName(element.Name, element.Plural)), gen.genRustFieldDeclType(fieldType, element.Plural, element.Optional)})
+	}
+	for _, group := range v.Groups {
+		if mixin := gen.getMixinGroup(group); mixin != nil {
+			supertraits = append(supertraits, genRustMixinName(mixin.Name))
+			continue
+		}
+		fieldType := getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)
+		accessors = append(accessors, rustMixinAccessor{genRustFieldName(gen.genRustPluralName(group.Name, group.Plural)), gen.genRustFieldDeclType(fieldType, group.Plural, false)})
+	}
+	gen.genRustMixin(v.Name, v.Doc, accessors, supertraits)
+}
+
+// genRustMixin generates the trait of the attribute group or group mixin
+// with the accessors of its fields, and the macro implementing the trait for
+// the structs holding the fields. Since the macro must be defined before its
+// use, the mixins are written ahead of the structs.
+func (gen *CodeGenerator) genRustMixin(name, doc string, accessors []rustMixinAccessor, supertraits []string) {
+	traitName, macroName := genRustMixinName(name), genRustMixinMacroName(name)
+	var bounds, methods, impls string
+	if len(supertraits) > 0 {
+		bounds = ": " + strings.Join(supertraits, " + ")
+	}
+	for _, accessor := range accessors {
+		methods += fmt.Sprintf("\tfn %s(&self) -> &%s;\n\tfn %s_mut(&mut self) -> &mut %s;\n", accessor.name, accessor.fieldType, accessor.name, accessor.fieldType)
+		impls += fmt.Sprintf("\t\t\tfn %s(&self) -> &%s {\n\t\t\t\t&self.%s\n\t\t\t}\n\t\t\tfn %s_mut(&mut self) -> &mut %s {\n\t\t\t\t&mut self.%s\n\t\t\t}\n",
+			accessor.name, accessor.fieldType, accessor.name, accessor.name, accessor.fieldType, accessor.name)
+	}
+	gen.mixinCode += fmt.Sprintf("\n%spub trait %s%s {\n%s}\n", gen.genComment(traitName, doc), traitName, bounds, methods)
+	gen.mixinCode += fmt.Sprintf("%smacro_rules! %s {\n\t($t:ty) => {\n\t\timpl %s for $t {\n%s\t\t}\n\t};\n}\n",
+		gen.genComment(macroName, fmt.Sprintf("the macro implementing the %s for the struct holding its fields.", traitName)), macroName, traitName, impls)
+}
+
+// genRustMixinImpls generates the implementations of the traits of the
+// attribute group and group mixins by given names for the struct.
+func (gen *CodeGenerator) genRustMixinImpls(structName string, mixins []string) {
+	for _, name := range mixins {
+		gen.Field += fmt.Sprintf("\n%s!(%s);\n", genRustMixinMacroName(name), structName)
 	}
 }
 
 // RustElement generates code for element XML schema in Rust language syntax.
 func (gen *CodeGenerator) RustElement(v *Element) {
+	defer gen.genJSONSchemaConstant(v)
+	if gen.RootWrappers && isRootElement(v, gen.ProtoTree) {
+		gen.genRustRootWrapper(v)
+		return
+	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		fieldType := getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree)
-		gen.StructAST[v.Name] = genRustFieldCode(v.Name, fieldType, v.Plural, v.Optional, &v.Restriction)
-		gen.Field += genRustStructCode(genRustFieldName(v.Name), v.Doc, gen.StructAST[v.Name])
+		gen.StructAST[v.Name] = gen.genRustFieldCode(v.Name, fieldType, v.Plural, v.Optional, &v.Restriction)
+		gen.addSymbol(v, genRustFieldName(v.Name))
+		gen.Field += genRustCustomType(gen.genRustStructCode(genRustFieldName(v.Name), v.Doc, gen.StructAST[v.Name]), v.Custom)
+		gen.genRustValidationCode(genRustFieldName(v.Name), gen.genRustFieldValidation(v.Name, fieldType, v.Plural, v.Optional, &v.Restriction))
+		gen.genRustNormalizeCode(genRustFieldName(v.Name), gen.genRustFieldNormalize(v.Name, fieldType, v.Plural, v.Optional, &v.Restriction))
 	}
 }
 
+// genRustRootWrapper generates the typed wrapper of the root element, with
+// the parse_xml and to_xml functions for deserializing and serializing the
+// XML documents by quick-xml, the to_pretty_xml function with the pretty XML
+// option, and the validation code validating the element.
+func (gen *CodeGenerator) genRustRootWrapper(v *Element) {
+	wrapperName := genRustStructName(v.Name, false) + "Root"
+	if _, ok := gen.StructAST[wrapperName]; ok {
+		return
+	}
+	gen.addSymbol(v, wrapperName)
+	fieldName := genRustFieldName(v.Name)
+	gen.StructAST[wrapperName] = fmt.Sprintf("\tpub %s: %s,\n", fieldName, gen.genRustFieldType(trimNSPrefix(v.Type)))
+	gen.Field += gen.genRustStructCode(wrapperName, v.Doc, gen.StructAST[wrapperName])
+	namespace := "xml"
+	if gen.TargetNamespace != "" {
+		namespace = fmt.Sprintf("format!(\"<{} xmlns=\\\"{}\\\"{}\", Self::ELEMENT_NAME, Self::NAMESPACE, &xml[Self::ELEMENT_NAME.len() + 1..])")
+	}
+	if prefix := gen.getNamespacePrefix(); prefix != "" {
+		namespace = fmt.Sprintf("prefix_xml_namespace(&xml, \"%s\", Self::NAMESPACE)?", escapeRustString(prefix))
+		if !strings.Contains(gen.mixinCode, "fn prefix_xml_namespace(") {
+			gen.mixinCode += genRustXMLNamespace()
+		}
+	}
+	gen.Field += fmt.Sprintf(`
+impl %s {
+	pub const ELEMENT_NAME: &'static str = "%s";
+	pub const NAMESPACE: &'static str = "%s";
+
+	pub fn parse_xml(xml: &str) -> Result<Self, Box<dyn std::error::Error>> {
+		Ok(%s { %s: quick_xml::de::from_str(xml)? })
+	}
+
+	pub fn to_xml(&self) -> Result<String, Box<dyn std::error::Error>> {
+		let xml = quick_xml::se::to_string_with_root(Self::ELEMENT_NAME, &self.%s)?;
+		Ok(%s)
+	}
+%s}
+`, wrapperName, escapeRustString(v.Name), escapeRustString(gen.TargetNamespace), wrapperName, fieldName, fieldName, namespace, gen.genRustPrettyMethod())
+	if gen.Validation != ValidationNone {
+		gen.genRustValidationCode(wrapperName, gen.genRustFieldValidation(v.Name, trimNSPrefix(v.Type), false, false, nil))
+	}
+}
+
+// genRustXMLNamespace generate the function of the root element wrappers for
+// Rust code, which rewrites the serialized XML document to write the elements
+// with the prefix of the namespace instead of the default namespace.
+func genRustXMLNamespace() string {
+	return `
+fn prefix_xml_namespace(xml: &str, prefix: &str, namespace: &str) -> Result<String, Box<dyn std::error::Error>> {
+	use quick_xml::events::{BytesEnd, BytesStart, Event};
+	let mut reader = quick_xml::Reader::from_str(xml);
+	let mut writer = quick_xml::Writer::new(Vec::new());
+	let mut root = true;
+	loop {
+		let event = reader.read_event()?;
+		let start = |e: &BytesStart, root: &mut bool| -> Result<BytesStart<'static>, Box<dyn std::error::Error>> {
+			let mut start = BytesStart::new(format!("{}:{}", prefix, String::from_utf8(e.name().as_ref().to_vec())?));
+			if *root {
+				start.push_attribute((format!("xmlns:{}", prefix).as_str(), namespace));
+				*root = false;
+			}
+			for attr in e.attributes() {
+				start.push_attribute(attr?);
+			}
+			Ok(start)
+		};
+		match event {
+			Event::Start(e) => writer.write_event(Event::Start(start(&e, &mut root)?))?,
+			Event::Empty(e) => writer.write_event(Event::Empty(start(&e, &mut root)?))?,
+			Event::End(e) => writer.write_event(Event::End(BytesEnd::new(format!("{}:{}", prefix, String::from_utf8(e.name().as_ref().to_vec())?))))?,
+			Event::Eof => break,
+			e => writer.write_event(e)?,
+		}
+	}
+	Ok(String::from_utf8(writer.into_inner())?)
+}
+`
+}
+
 // RustAttribute generates code for attribute XML schema in Rust language syntax.
 func (gen *CodeGenerator) RustAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		fieldType := getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree)
-		gen.StructAST[v.Name] = genRustFieldCode(v.Name, fieldType, v.Plural, v.Optional, &v.Restriction)
-		gen.Field += genRustStructCode(genRustFieldName(v.Name), v.Doc, gen.StructAST[v.Name])
+		gen.StructAST[v.Name] = gen.genRustFieldCode(v.Name, fieldType, v.Plural, v.Optional, &v.Restriction)
+		gen.addSymbol(v, genRustFieldName(v.Name))
+		gen.Field += gen.genRustStructCode(genRustFieldName(v.Name), v.Doc, gen.StructAST[v.Name])
+		gen.genRustValidationCode(genRustFieldName(v.Name), gen.genRustFieldValidation(v.Name, fieldType, v.Plural, v.Optional, &v.Restriction))
+		gen.genRustNormalizeCode(genRustFieldName(v.Name), gen.genRustFieldNormalize(v.Name, fieldType, v.Plural, v.Optional, &v.Restriction))
 	}
 }
 
-// genRustStructName generate struct name for Rust code.
-func genRustFieldRename(name string) string {
+// genRustFieldRename generate the name the field is renamed to by serde for
+// Rust code, in the rename case of the code generator.
+func (gen *CodeGenerator) genRustFieldRename(name string) string {
 	if strings.Count(name, ":") > 0 {
-		return strings.Split(name, ":")[1]
+		return applyRenameCase(strings.Split(name, ":")[1], gen.RenameCase)
 	} else {
 		if name == "value" {
-			name = "$" + name
+			return "$" + name
 		}
-		return name
+		return applyRenameCase(name, gen.RenameCase)
+	}
+}
+
+// genRustValidatorName generate validator function name of the struct for
+// Rust code.
+func genRustValidatorName(structName string) string {
+	return "validate_" + ToSnakeCase(structName)
+}
+
+// genRustValidationCode generate validation code for the struct with given
+// validation body in Rust language syntax.
+func (gen *CodeGenerator) genRustValidationCode(structName, body string) {
+	if gen.ValidationMaxDepth > 0 {
+		gen.genRustDepthValidationCode(structName, body)
+		return
+	}
+	switch gen.Validation {
+	case ValidationMethod:
+		gen.Field += fmt.Sprintf("\nimpl %s {\n\tpub fn validate(&self) -> Result<(), ValidationError> {\n%s\t}\n}\n", structName, gen.genRustValidationTrace(structName, "\t\t", body))
+	case ValidationStandalone:
+		receiver := "v"
+		if body == "" {
+			receiver = "_v"
+		}
+		gen.ValidationCode += fmt.Sprintf("\npub fn %s(%s: &%s) -> Result<(), ValidationError> {\n%s}\n", genRustValidatorName(structName), receiver, structName, gen.genRustValidationTrace(structName, "\t", body))
+	}
+}
+
+// genRustDepthValidationCode generate depth-limited validation code for the
+// struct with given validation body in Rust language syntax. Nested structs
+// are validated with the increased depth, and a dedicated validation error is
+// returned instead of overflowing the stack on deeply nested documents.
+func (gen *CodeGenerator) genRustDepthValidationCode(structName, body string) {
+	check := func(indent string) string {
+		return fmt.Sprintf("%sif depth > %d {\n%s\treturn Err(ValidationError::new(1008, \"%s exceeds the maximum validation depth of %d\".to_string()));\n%s}\n",
+			indent, gen.ValidationMaxDepth, indent, ToSnakeCase(structName), gen.ValidationMaxDepth, indent)
+	}
+	switch gen.Validation {
+	case ValidationMethod:
+		gen.Field += fmt.Sprintf("\nimpl %s {\n\tpub fn validate(&self) -> Result<(), ValidationError> {\n\t\tself.validate_depth(0)\n\t}\n\n\tfn validate_depth(&self, depth: usize) -> Result<(), ValidationError> {\n%s\t}\n}\n", structName, gen.genRustValidationTrace(structName, "\t\t", check("\t\t")+body))
+	case ValidationStandalone:
+		receiver, validator := "v", genRustValidatorName(structName)
+		if body == "" {
+			receiver = "_v"
+		}
+		gen.ValidationCode += fmt.Sprintf("\npub fn %s(v: &%s) -> Result<(), ValidationError> {\n\t%s_depth(v, 0)\n}\n", validator, structName, validator)
+		gen.ValidationCode += fmt.Sprintf("\nfn %s_depth(%s: &%s, depth: usize) -> Result<(), ValidationError> {\n%s}\n", validator, receiver, structName, gen.genRustValidationTrace(structName, "\t", check("\t")+body))
+	}
+}
+
+// genRustFieldValidation generate validation code of the struct field for
+// Rust code. Facets are checked on fields with built-in type, and nested
+// types are validated by their own validation code.
+func (gen *CodeGenerator) genRustFieldValidation(name, fieldType string, plural, optional bool, restriction *Restriction) string {
+	if gen.Validation == ValidationNone {
+		return ""
+	}
+	fieldName, indent, receiver := genRustFieldName(name), "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	return gen.genRustValueValidation(indent, fieldName, receiver+"."+fieldName, fieldType, plural, optional, restriction)
+}
+
+// genRustValueValidation generate validation code of the value of the field
+// by given name for Rust code, the value is given by the place expression.
+// The length facets of the lists are checked on the number of items.
+func (gen *CodeGenerator) genRustValueValidation(indent, fieldName, field, fieldType string, plural, optional bool, restriction *Restriction) string {
+	typeName, fieldType := fieldType, gen.genRustFieldType(fieldType)
+	checks := func(indent, value, ref, number string) string {
+		if !gen.isRustBuiltInType(fieldType) {
+			var code string
+			if restriction != nil && isListType(typeName, gen.ProtoTree) {
+				code = genRustLengthChecks(genRustCheck(indent, fieldName), fmt.Sprintf("%s.%s.len()", value, genRustFieldName(typeName)), restriction)
+			}
+			if gen.ValidationMaxDepth > 0 {
+				if gen.Validation == ValidationStandalone {
+					return code + fmt.Sprintf("%s%s_depth(%s, depth + 1)?;\n", indent, genRustValidatorName(fieldType), ref)
+				}
+				return code + fmt.Sprintf("%s%s.validate_depth(depth + 1)?;\n", indent, value)
+			}
+			if gen.Validation == ValidationStandalone {
+				return code + fmt.Sprintf("%s%s(%s)?;\n", indent, genRustValidatorName(fieldType), ref)
+			}
+			return code + fmt.Sprintf("%s%s.validate()?;\n", indent, value)
+		}
+		if restriction == nil || restriction.IsEmpty() {
+			return ""
+		}
+		return gen.genRustFacetChecks(indent, fieldName, fieldType, value, ref, number, restriction)
+	}
+	if checks("", field, "&"+field, field) == "" {
+		return ""
+	}
+	switch {
+	case optional && plural:
+		return fmt.Sprintf("%sif let Some(ref items) = %s {\n%s\tfor val in items {\n%s%s\t}\n%s}\n", indent, field, indent, checks(indent+"\t\t", "val", "val", "*val"), indent, indent)
+	case optional:
+		return fmt.Sprintf("%sif let Some(ref val) = %s {\n%s%s}\n", indent, field, checks(indent+"\t", "val", "val", "*val"), indent)
+	case plural:
+		return fmt.Sprintf("%sfor val in &%s {\n%s%s}\n", indent, field, checks(indent+"\t", "val", "val", "*val"), indent)
+	}
+	return checks(indent, field, "&"+field, field)
+}
+
+// genRustListValidation generate validation code of the list type for Rust
+// code, the length facets of the list are checked on the number of items,
+// and the facets of the item type on each item.
+func (gen *CodeGenerator) genRustListValidation(v *SimpleType, fieldType string) string {
+	if gen.Validation == ValidationNone {
+		return ""
+	}
+	fieldName, indent, receiver := genRustFieldName(v.Name), "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	field := receiver + "." + fieldName
+	itemRestriction := getListItemRestriction(v, gen.ProtoTree)
+	return genRustLengthChecks(genRustCheck(indent, fieldName), field+".len()", &v.Restriction) +
+		gen.genRustValueValidation(indent, fieldName, field, fieldType, true, false, &itemRestriction)
+}
+
+// genRustCheck returns the function generating the check of the condition
+// failing the validation of the field with the error code and message for
+// Rust code.
+func genRustCheck(indent, fieldName string) func(condition string, code int, message string) string {
+	return func(condition string, code int, message string) string {
+		return fmt.Sprintf("%sif %s {\n%s\treturn Err(ValidationError::new(%d, \"%s %s\".to_string()));\n%s}\n", indent, condition, indent, code, fieldName, escapeRustString(message), indent)
+	}
+}
+
+// genRustLengthChecks generate the checks of the length facets on the length
+// expression of the value or the list for Rust code, the length facet fails
+// with the error code 1016.
+func genRustLengthChecks(check func(condition string, code int, message string) string, length string, restriction *Restriction) (code string) {
+	if restriction.HasLength {
+		return check(fmt.Sprintf("%s != %d", length, restriction.Length), 1016, fmt.Sprintf("does not have the length of %d", restriction.Length))
+	}
+	if restriction.MinLength > 0 {
+		code += check(fmt.Sprintf("%s < %d", length, restriction.MinLength), 1001, fmt.Sprintf("is shorter than the minimum length of %d", restriction.MinLength))
+	}
+	if restriction.MaxLength > 0 {
+		code += check(fmt.Sprintf("%s > %d", length, restriction.MaxLength), 1002, fmt.Sprintf("exceeds the maximum length of %d", restriction.MaxLength))
+	}
+	return
+}
+
+// genRustFacetChecks generate facet checks of the value with built-in type
+// for Rust code.
+func (gen *CodeGenerator) genRustFacetChecks(indent, fieldName, fieldType, value, ref, number string, restriction *Restriction) (code string) {
+	check := genRustCheck(indent, fieldName)
+	// The date and time values are checked in their serialized lexical form.
+	if isRustChronoType(fieldType) {
+		fieldType, value, ref = "String", value+".to_string()", "&"+value+".to_string()"
+	}
+	if fieldType == "String" {
+		// The facets are checked on the value normalized by the whiteSpace
+		// facet.
+		if normalized := genRustWhiteSpaceExpr(value, restriction.WhiteSpace); normalized != value {
+			value, ref = normalized, "&"+normalized
+		}
+		length := fmt.Sprintf("%s.chars().count()", value)
+		if restriction.Octets {
+			length = fmt.Sprintf("%s.len() / 2", value)
+		}
+		code += genRustLengthChecks(check, length, restriction)
+		if restriction.Pattern != nil {
+			pattern := escapeRustString("^(?:" + restriction.Pattern.String() + ")$")
+			code += check(fmt.Sprintf("!xsd_pattern(\"%s\").is_match(%s)", pattern, ref), 1005, fmt.Sprintf("does not match the pattern %s", restriction.Pattern.String()))
+		}
+		if len(restriction.Enum) > 0 {
+			var quotedEnums []string
+			for _, enum := range restriction.Enum {
+				quotedEnums = append(quotedEnums, fmt.Sprintf("\"%s\"", escapeRustString(enum)))
+			}
+			code += check(fmt.Sprintf("![%s].contains(&%s.as_str())", strings.Join(quotedEnums, ", "), value), 1006, fmt.Sprintf("is not one of %s", strings.Join(restriction.Enum, ", ")))
+		}
+	}
+	if gen.isRustNumericType(fieldType) {
+		minValue, maxValue := restriction.minValue(), restriction.maxValue()
+		min, max := genRustNumberLiteral(minValue, fieldType, !restriction.MinExclusive), genRustNumberLiteral(maxValue, fieldType, restriction.MaxExclusive)
+		minText, maxText := minValue.String(), maxValue.String()
+		if restriction.HasMin && restriction.MinExclusive {
+			code += check(fmt.Sprintf("%s <= %s", number, min), 1003, fmt.Sprintf("is not greater than the exclusive minimum value of %s", minText))
+		} else if restriction.HasMin {
+			code += check(fmt.Sprintf("%s < %s", number, min), 1003, fmt.Sprintf("is less than the minimum value of %s", minText))
+		}
+		if restriction.HasMax && restriction.MaxExclusive {
+			code += check(fmt.Sprintf("%s >= %s", number, max), 1004, fmt.Sprintf("is not less than the exclusive maximum value of %s", maxText))
+		} else if restriction.HasMax {
+			code += check(fmt.Sprintf("%s > %s", number, max), 1004, fmt.Sprintf("exceeds the maximum value of %s", maxText))
+		}
+		if values, ok := restriction.numericEnum(); ok {
+			var literals []string
+			for _, value := range values {
+				// The values with fraction aren't values of the integer
+				// types.
+				if gen.isRustIntegerType(fieldType) && !isIntegralFacetValue(value) {
+					continue
+				}
+				literals = append(literals, genRustNumberLiteral(value, fieldType, false))
+			}
+			enumNumber := number
+			if isDecimalType(fieldType) {
+				enumNumber = fmt.Sprintf("f64::from(%s)", number)
+			}
+			condition := fmt.Sprintf("![%s].contains(&%s)", strings.Join(literals, ", "), enumNumber)
+			if len(literals) == 0 {
+				condition = "true"
+			}
+			code += check(condition, 1006, fmt.Sprintf("is not one of %s", strings.Join(restriction.Enum, ", ")))
+		}
+		digits := fmt.Sprintf("xsd_digits(&%s.to_string())", value)
+		if isDecimalType(fieldType) {
+			digits = fmt.Sprintf("xsd_digits(&f64::from(%s).to_string())", number)
+		}
+		if fieldType == rustDecimalType {
+			digits = fmt.Sprintf("xsd_digits(&%s.normalize().to_string())", value)
+		}
+		if restriction.TotalDigits > 0 {
+			code += check(fmt.Sprintf("%s.0 > %d", digits, restriction.TotalDigits), 1009, fmt.Sprintf("exceeds the total digits of %d", restriction.TotalDigits))
+		}
+		if restriction.FractionDigits > 0 {
+			code += check(fmt.Sprintf("%s.1 > %d", digits, restriction.FractionDigits), 1010, fmt.Sprintf("exceeds the fraction digits of %d", restriction.FractionDigits))
+		}
+	}
+	return
+}
+
+// genRustUniqueValidation generate validation code of the unique, key and
+// keyref identity constraints checked by the struct for Rust code. A
+// duplicated combination of the field values among the selected elements is
+// reported with the index of the duplicate, and elements without all the
+// fields are skipped, unless the key constraint requires them, which fails
+// with the error code 1014. The combination of the keyref constraint
+// matching none of the referred constraint fails with the error code 1015.
+// The elements selected by the steps nested in other elements are iterated
+// in document order, and the constraints which can't be checked are
+// recorded to be warned about.
+func (gen *CodeGenerator) genRustUniqueValidation(typeName string) (code string) {
+	if gen.Validation == ValidationNone {
+		return
+	}
+	indent, receiver := "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	for _, unique := range getUniqueConstraints(typeName, gen.ProtoTree) {
+		key, err := gen.getRustIdentityKey(typeName, unique, receiver)
+		if err != nil {
+			gen.addUnsupportedUnique(typeName, unique, err)
+			continue
+		}
+		if unique.Refer != "" {
+			keyref, err := gen.genRustKeyRefValidation(typeName, unique, key, indent, receiver)
+			if err != nil {
+				gen.addUnsupportedUnique(typeName, unique, err)
+			}
+			code += keyref
+			continue
+		}
+		seen := "seen_" + ToSnakeCase(genRustStructName(unique.Name, false))
+		code += fmt.Sprintf("%slet mut %s = std::collections::HashMap::new();\n", indent, seen)
+		code += fmt.Sprintf("%sfor (i, val) in %s.enumerate() {\n", indent, key.items)
+		code += fmt.Sprintf("%s\tif let %s = %s {\n", indent, key.pattern, key.value)
+		code += fmt.Sprintf("%s\t\tif let Some(j) = %s.insert(format!(\"{:?}\", %s), i) {\n", indent, seen, key.key)
+		code += fmt.Sprintf("%s\t\t\treturn Err(ValidationError::new(1007, format!(\"%s[{}] duplicates %s[{}] on %s constraint %s\", i, j)));\n", indent, key.itemName, key.itemName, unique.kind(), escapeRustString(unique.Name))
+		code += fmt.Sprintf("%s\t\t}\n%s\t}", indent, indent)
+		if unique.Key {
+			code += fmt.Sprintf(" else {\n%s\t\treturn Err(ValidationError::new(1014, format!(\"%s[{}] misses the fields of key constraint %s\", i)));\n%s\t}", indent, key.itemName, escapeRustString(unique.Name), indent)
+		}
+		code += fmt.Sprintf("\n%s}\n", indent)
+	}
+	return
+}
+
+// genRustKeyRefValidation generate validation code of the keyref identity
+// constraint for Rust code, collecting the combinations of the field values
+// of the referred constraint before checking the ones of the keyref
+// constraint.
+func (gen *CodeGenerator) genRustKeyRefValidation(typeName string, keyref *Unique, key rustIdentityKey, indent, receiver string) (code string, err error) {
+	referred := getReferredConstraint(keyref, gen.ProtoTree)
+	if referred == nil {
+		return "", fmt.Errorf("no key or unique constraint %s", keyref.Refer)
+	}
+	referredKey, err := gen.getRustIdentityKey(typeName, referred, receiver)
+	if err != nil {
+		return "", err
+	}
+	if referredKey.fields != key.fields {
+		return "", fmt.Errorf("the fields don't match the ones of %s", referred.Name)
+	}
+	keys := "keys_" + ToSnakeCase(genRustStructName(keyref.Name, false))
+	code += fmt.Sprintf("%slet mut %s = std::collections::HashSet::new();\n", indent, keys)
+	code += fmt.Sprintf("%sfor val in %s {\n", indent, referredKey.items)
+	code += fmt.Sprintf("%s\tif let %s = %s {\n%s\t\t%s.insert(format!(\"{:?}\", %s));\n%s\t}\n%s}\n", indent, referredKey.pattern, referredKey.value, indent, keys, referredKey.key, indent, indent)
+	code += fmt.Sprintf("%sfor (i, val) in %s.enumerate() {\n", indent, key.items)
+	code += fmt.Sprintf("%s\tif let %s = %s {\n", indent, key.pattern, key.value)
+	code += fmt.Sprintf("%s\t\tif !%s.contains(&format!(\"{:?}\", %s)) {\n", indent, keys, key.key)
+	code += fmt.Sprintf("%s\t\t\treturn Err(ValidationError::new(1015, format!(\"%s[{}] refers to no %s on keyref constraint %s\", i)));\n", indent, key.itemName, referredKey.itemName, escapeRustString(keyref.Name))
+	code += fmt.Sprintf("%s\t\t}\n%s\t}\n%s}\n", indent, indent, indent)
+	return
+}
+
+// rustIdentityKey holds the field of the elements selected by an identity
+// constraint for Rust code, named by the path of the fields if they're
+// nested, the iterator over the selected items, and the pattern binding the
+// fields of the item matched against the value of the optional references to
+// the fields, with the key of the bound fields and their number.
+type rustIdentityKey struct {
+	itemName, items     string
+	pattern, value, key string
+	fields              int
+}
+
+// getRustIdentityKey returns the selected elements of the receiver and the
+// fields of the identity constraint on the struct by given name for Rust
+// code. The selector may step through the nested elements, and the error
+// tells the selector or the field which can't be resolved.
+func (gen *CodeGenerator) getRustIdentityKey(typeName string, unique *Unique, receiver string) (key rustIdentityKey, err error) {
+	selector, err := resolveUniqueSelector(typeName, unique, gen.ProtoTree)
+	if err != nil {
+		return key, err
+	}
+	var names []string
+	for i, step := range selector {
+		field := genRustFieldName(gen.genRustPluralName(step.Name, step.Plural))
+		names = append(names, field)
+		if i == 0 {
+			key.items = genRustUniqueStep(receiver, field, step)
+			continue
+		}
+		if gen.isRustBuiltInType(gen.genRustFieldType(selector[i-1].Type)) {
+			return key, fmt.Errorf("unsupported selector %s", unique.Selector)
+		}
+		key.items += fmt.Sprintf(".flat_map(|x| %s)", genRustUniqueStep("x", field, step))
+	}
+	key.itemName = strings.Join(names, "/")
+	item := selector[len(selector)-1]
+	var patterns, values []string
+	for i, field := range unique.Fields {
+		value, ok := gen.genRustUniqueField(item, field)
+		if !ok {
+			return key, fmt.Errorf("unsupported field %s", field)
+		}
+		patterns = append(patterns, fmt.Sprintf("Some(f%d)", i))
+		values = append(values, value)
+	}
+	key.pattern, key.value, key.key, key.fields = patterns[0], values[0], "f0", len(values)
+	if len(values) > 1 {
+		key.pattern = "(" + strings.Join(patterns, ", ") + ")"
+		key.value = "(" + strings.Join(values, ", ") + ")"
+		key.key = strings.NewReplacer("Some(", "", ")", "").Replace(key.pattern) + ")"
+	}
+	return key, nil
+}
+
+// genRustUniqueStep generate the iterator expression over the references to
+// the elements of the step of an identity constraint selector on the value
+// for Rust code.
+func genRustUniqueStep(value, field string, step uniqueStep) string {
+	switch {
+	case step.Plural && step.Optional:
+		return fmt.Sprintf("%s.%s.iter().flatten()", value, field)
+	case step.Plural, step.Optional:
+		return fmt.Sprintf("%s.%s.iter()", value, field)
+	}
+	return fmt.Sprintf("std::iter::once(&%s.%s)", value, field)
+}
+
+// genRustUniqueField generate the optional reference expression of the field
+// of unique identity constraint on the selected item.
+func (gen *CodeGenerator) genRustUniqueField(item uniqueStep, field string) (string, bool) {
+	value := "Some(val)"
+	if strings.TrimSpace(field) == "." {
+		return value, gen.isRustBuiltInType(gen.genRustFieldType(item.Type))
+	}
+	steps, ok := resolveUniquePath(trimNSPrefix(item.Type), field, gen.ProtoTree)
+	if !ok {
+		return "", false
+	}
+	for i, step := range steps {
+		if step.Plural || gen.isRustBuiltInType(gen.genRustFieldType(step.Type)) != (i == len(steps)-1) {
+			return "", false
+		}
+		if step.Optional {
+			value += fmt.Sprintf(".and_then(|x| x.%s.as_ref())", genRustFieldName(step.Name))
+			continue
+		}
+		value += fmt.Sprintf(".map(|x| &x.%s)", genRustFieldName(step.Name))
+	}
+	return value, true
+}
+
+func (gen *CodeGenerator) isRustNumericType(typeName string) bool {
+	return gen.isRustBuiltInType(typeName) && (strings.ContainsAny(typeName[:1], "iuf") || isDecimalType(typeName) || typeName == rustDecimalType)
+}
+
+// isRustIntegerType returns true if the type by given name is an integer
+// type of Rust.
+func (gen *CodeGenerator) isRustIntegerType(typeName string) bool {
+	return gen.isRustBuiltInType(typeName) && typeName != "" && strings.ContainsAny(typeName[:1], "iu")
+}
+
+// genRustNumberLiteral generate literal of the numeric value for the given
+// Rust type, written exactly from the values of the integer and the decimal
+// types, so the bounds beyond the precision of the floats are kept. The
+// values of the integer types without fraction are rounded up if ceil is
+// true, or down otherwise, such as the minExclusive of 1.5 to 1 and its
+// minInclusive to 2, so the integers satisfying the bound are kept.
+func genRustNumberLiteral(value FacetValue, fieldType string, ceil bool) string {
+	if fieldType == rustDecimalType {
+		return genRustDecimalLiteral(value.Float64(), value.String())
+	}
+	if strings.HasPrefix(fieldType, "f") || isDecimalType(fieldType) {
+		if value.IsInf() {
+			infinity := "f64::INFINITY"
+			if fieldType == "f32" {
+				infinity = "f32::INFINITY"
+			}
+			if value.Float < 0 {
+				infinity = strings.Replace(infinity, "INFINITY", "NEG_INFINITY", 1)
+			}
+			return infinity
+		}
+		literal := value.String()
+		if value.Kind == FacetFloat {
+			literal = strconv.FormatFloat(value.Float, 'f', -1, 64)
+		}
+		if !strings.Contains(literal, ".") {
+			literal += ".0"
+		}
+		return literal
+	}
+	if value.IsInf() {
+		if value.Float > 0 {
+			return fieldType + "::MAX"
+		}
+		return fieldType + "::MIN"
+	}
+	exact, ok := value.Exact()
+	if !ok {
+		exact = new(big.Rat).SetFloat64(value.Float)
+	}
+	// The Euclidean division by the positive denominator is the floor.
+	integer, remainder := new(big.Int).DivMod(exact.Num(), exact.Denom(), new(big.Int))
+	if ceil && remainder.Sign() != 0 {
+		integer.Add(integer, big.NewInt(1))
+	}
+	return integer.String()
+}
+
+// isIntegralFacetValue returns true if the numeric value has no fraction.
+func isIntegralFacetValue(value FacetValue) bool {
+	if exact, ok := value.Exact(); ok {
+		return exact.IsInt()
+	}
+	return !value.IsInf() && value.Float == math.Trunc(value.Float)
+}
+
+// genRustDecimalLiteral generate literal of the Decimal value for Rust code,
+// constructed from the mantissa and the scale of the lexical value of the
+// facet, or of the numeric value if the lexical value isn't a decimal, so
+// that the bounds are compared without the rounding of the floats.
+func genRustDecimalLiteral(value float64, lexical string) string {
+	lexical = strings.TrimSpace(lexical)
+	if !rustDecimalLexicalPattern.MatchString(lexical) {
+		lexical = strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	sign := ""
+	if lexical[0] == '-' || lexical[0] == '+' {
+		sign, lexical = strings.TrimPrefix(lexical[:1], "+"), lexical[1:]
+	}
+	integer, fraction := lexical, ""
+	if i := strings.Index(lexical, "."); i != -1 {
+		integer, fraction = lexical[:i], strings.TrimRight(lexical[i+1:], "0")
+	}
+	if integer = strings.TrimLeft(integer, "0"); integer == "" {
+		integer = "0"
+	}
+	mantissa, err := strconv.ParseInt(sign+integer+fraction, 10, 64)
+	if err != nil {
+		if fraction != "" {
+			integer += "." + fraction
+		}
+		return fmt.Sprintf("Decimal::from_str_exact(\"%s%s\").unwrap()", sign, integer)
+	}
+	return fmt.Sprintf("Decimal::new(%d, %d)", mantissa, len(fraction))
+}
+
+// genRustNormalizerName generate normalize function name of the struct for
+// Rust code.
+func genRustNormalizerName(structName string) string {
+	return "normalize_" + ToSnakeCase(structName)
+}
+
+// genRustNormalizeCode generate normalize code for the struct with given
+// normalize body in Rust language syntax.
+func (gen *CodeGenerator) genRustNormalizeCode(structName, body string) {
+	if !gen.Normalize {
+		return
+	}
+	if gen.Validation == ValidationStandalone {
+		receiver := "v"
+		if body == "" {
+			receiver = "_v"
+		}
+		gen.ValidationCode += fmt.Sprintf("\npub fn %s(%s: &mut %s) {\n%s}\n", genRustNormalizerName(structName), receiver, structName, body)
+		return
+	}
+	gen.Field += fmt.Sprintf("\nimpl %s {\n\tpub fn normalize(&mut self) {\n%s\t}\n}\n", structName, body)
+}
+
+// rustHasNormalizer returns true if the normalize code is generated for the
+// struct by given type name.
+func (gen *CodeGenerator) rustHasNormalizer(name string) bool {
+	if gen.choiceEnums[name] {
+		return true
+	}
+	for _, ele := range gen.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			if v.Name == name && !v.List && !v.Union {
+				return genRustNormalizeExpr(gen.genRustFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)), "", &v.Restriction) != ""
+			}
+		case *ComplexType:
+			if v.Name == name {
+				return true
+			}
+		case *Group:
+			if v.Name == name {
+				return true
+			}
+		case *AttributeGroup:
+			if v.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// genRustFieldNormalize generate normalize code of the struct field for Rust
+// code. Fields with built-in type are normalized by their facets, and nested
+// types are normalized by their own normalize code.
+func (gen *CodeGenerator) genRustFieldNormalize(name, fieldType string, plural, optional bool, restriction *Restriction) string {
+	if !gen.Normalize {
+		return ""
+	}
+	fieldName, indent, receiver := genRustFieldName(name), "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	return gen.genRustValueNormalize(indent, receiver+"."+fieldName, fieldType, plural, optional, restriction)
+}
+
+// genRustValueNormalize generate normalize code of the value of the field
+// for Rust code, the value is given by the place expression.
+func (gen *CodeGenerator) genRustValueNormalize(indent, field, fieldType string, plural, optional bool, restriction *Restriction) string {
+	typeName := fieldType
+	fieldType = gen.genRustFieldType(fieldType)
+	normalize := func(indent, value, ref string) string {
+		if !gen.isRustBuiltInType(fieldType) {
+			if !gen.rustHasNormalizer(typeName) {
+				return ""
+			}
+			if gen.Validation == ValidationStandalone {
+				return fmt.Sprintf("%s%s(%s);\n", indent, genRustNormalizerName(fieldType), ref)
+			}
+			return fmt.Sprintf("%s%s.normalize();\n", indent, value)
+		}
+		if expr := genRustNormalizeExpr(fieldType, value, restriction); expr != "" {
+			if value == "val" {
+				return fmt.Sprintf("%s*val = %s;\n", indent, expr)
+			}
+			return fmt.Sprintf("%s%s = %s;\n", indent, value, expr)
+		}
+		return ""
+	}
+	if normalize("", field, "&mut "+field) == "" {
+		return ""
+	}
+	switch {
+	case optional && plural:
+		return fmt.Sprintf("%sif let Some(ref mut items) = %s {\n%s\tfor val in items.iter_mut() {\n%s%s\t}\n%s}\n", indent, field, indent, normalize(indent+"\t\t", "val", "val"), indent, indent)
+	case optional:
+		return fmt.Sprintf("%sif let Some(ref mut val) = %s {\n%s%s}\n", indent, field, normalize(indent+"\t", "val", "val"), indent)
+	case plural:
+		return fmt.Sprintf("%sfor val in %s.iter_mut() {\n%s%s}\n", indent, field, normalize(indent+"\t", "val", "val"), indent)
+	}
+	return normalize(indent, field, "&mut "+field)
+}
+
+// genRustWhiteSpaceExpr returns the Rust expression normalizing the string
+// value by given value of the whiteSpace facet.
+func genRustWhiteSpaceExpr(value, whiteSpace string) string {
+	switch whiteSpace {
+	case WhiteSpaceReplace:
+		return value + `.replace(|c| c == '\t' || c == '\n' || c == '\r', " ")`
+	case WhiteSpaceCollapse:
+		return value + `.split_whitespace().collect::<Vec<_>>().join(" ")`
+	}
+	return value
+}
+
+// genRustNormalizeExpr generate the expression which applies the
+// normalization implied by the facets on the value with built-in type for
+// Rust code, returns empty string if the value is left unchanged.
+func genRustNormalizeExpr(fieldType, value string, restriction *Restriction) string {
+	if fieldType != "String" || restriction == nil {
+		return ""
+	}
+	n := restriction.Normalization()
+	if n.IsEmpty() {
+		return ""
+	}
+	value = genRustWhiteSpaceExpr(value, n.WhiteSpace)
+	if n.Trim {
+		value += ".trim().to_string()"
+	}
+	switch n.Case {
+	case CaseUpper:
+		value += ".to_uppercase()"
+	case CaseLower:
+		value += ".to_lowercase()"
 	}
+	return value
 }
@@ -0,0 +1,366 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"math"
+	"math/big"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+// TestVectors defines the language-neutral set of valid and invalid lexical
+// values per simple type, derived from the facets of the schema.
+type TestVectors struct {
+	Schema string       `json:"schema"`
+	Types  []TypeVector `json:"types"`
+}
+
+// TypeVector holds the valid and invalid lexical values of a simple type.
+type TypeVector struct {
+	Name    string   `json:"name"`
+	Valid   []string `json:"valid"`
+	Invalid []string `json:"invalid"`
+}
+
+// GenTestVectors writes the JSON test vectors for the simple types of the
+// schema, and the test stubs which load them for the Go and Rust validation
+// code, so the outputs of each language can be checked to validate
+// identically. The TypeScript code has no validation code to check, so no
+// stub is written for it.
+func (gen *CodeGenerator) GenTestVectors() error {
+	vectors := TestVectors{Schema: filepath.Base(gen.File), Types: []TypeVector{}}
+	var simpleTypes []*SimpleType
+	for _, ele := range gen.ProtoTree {
+		v, ok := ele.(*SimpleType)
+		if !ok || v.List || v.Union || v.Restriction.IsEmpty() || gen.isPruned(v) {
+			continue
+		}
+		valid, invalid := genFacetVectors(v.Restriction)
+		vectors.Types = append(vectors.Types, TypeVector{Name: v.Name, Valid: valid, Invalid: invalid})
+		simpleTypes = append(simpleTypes, v)
+	}
+	data, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		return err
+	}
+	vectorsFile := gen.FileWithExtension(".vectors.json")
+	if err = gen.WriteFile(vectorsFile, append(data, '\n')); err != nil {
+		return err
+	}
+	if gen.Validation == ValidationNone || len(simpleTypes) == 0 {
+		return nil
+	}
+	switch gen.Lang {
+	case "Go":
+		return gen.genGoTestVectorsStub(filepath.Base(vectorsFile), simpleTypes)
+	case "Rust":
+		return gen.genRustTestVectorsStub(filepath.Base(vectorsFile), simpleTypes)
+	}
+	return nil
+}
+
+// genFacetVectors derives the valid and invalid lexical values from the
+// facets of the restriction. Every candidate value is checked by Evaluate,
+// so the vectors are consistent with the facet model.
+func genFacetVectors(r Restriction) (valid, invalid []string) {
+	var candidates []string
+	candidates = append(candidates, r.Enum...)
+	if r.Pattern != nil {
+		if sample, ok := genPatternSample(r.Pattern.String()); ok {
+			candidates = append(candidates, sample)
+		}
+	}
+	if r.HasLength {
+		candidates = append(candidates, strings.Repeat("a", r.Length), strings.Repeat("a", r.Length+1))
+		if r.Length > 0 {
+			candidates = append(candidates, strings.Repeat("a", r.Length-1))
+		}
+	}
+	if r.MinLength > 0 {
+		candidates = append(candidates, strings.Repeat("a", r.MinLength), strings.Repeat("a", r.MinLength-1))
+	}
+	if r.MaxLength > 0 {
+		candidates = append(candidates, strings.Repeat("a", r.MaxLength), strings.Repeat("a", r.MaxLength+1))
+	}
+	// The values which aren't numbers are only candidates of the string
+	// types, the ones of the numeric types aren't parsed by the stubs.
+	if _, numeric := facetKinds[r.base]; !numeric && (len(r.Enum) > 0 || r.Pattern != nil) {
+		candidates = append(candidates, "", "!")
+	}
+	if r.HasMin {
+		candidates = append(candidates, genBoundVectors(r.minValue(), -1)...)
+	}
+	if r.HasMax {
+		candidates = append(candidates, genBoundVectors(r.maxValue(), 1)...)
+	}
+	valid, invalid = []string{}, []string{}
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		if r.Evaluate(candidate) == nil {
+			valid = append(valid, candidate)
+			continue
+		}
+		invalid = append(invalid, candidate)
+	}
+	return
+}
+
+// genBoundVectors returns the value of the bound and the values beyond and
+// within the bound by one, beyond is the direction of the values out of the
+// bound. The bounds of the integer types are written exactly, and the other
+// bounds as the floats.
+func genBoundVectors(bound FacetValue, beyond int64) []string {
+	if bound.Kind == FacetInteger {
+		return []string{
+			bound.String(),
+			new(big.Int).Add(bound.Integer, big.NewInt(beyond)).String(),
+			new(big.Int).Sub(bound.Integer, big.NewInt(beyond)).String(),
+		}
+	}
+	value := bound.Float64()
+	if !bound.IsNumeric() || math.IsInf(value, 0) {
+		return nil
+	}
+	var vectors []string
+	if isExactBound(value, bound.Lexical) {
+		vectors = append(vectors, strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	return append(vectors, strconv.FormatFloat(value+float64(beyond), 'f', -1, 64), strconv.FormatFloat(value-float64(beyond), 'f', -1, 64))
+}
+
+// isExactBound returns true if the value of the bound is exactly the lexical
+// value of the facet. The bounds rounded by the floats are compared
+// differently by the floating-point and the decimal types, so they aren't
+// used as vectors.
+func isExactBound(value float64, lexical string) bool {
+	exact, ok := new(big.Rat).SetString(strings.TrimSpace(lexical))
+	if !ok || math.IsInf(value, 0) || math.IsNaN(value) {
+		return true
+	}
+	return exact.Cmp(new(big.Rat).SetFloat64(value)) == 0
+}
+
+// genPatternSample generates the shortest value matching the common regular
+// expression constructs of the pattern facet.
+func genPatternSample(pattern string) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var sample strings.Builder
+	var walk func(re *syntax.Regexp) bool
+	walk = func(re *syntax.Regexp) bool {
+		switch re.Op {
+		case syntax.OpLiteral:
+			sample.WriteString(string(re.Rune))
+		case syntax.OpCharClass:
+			if len(re.Rune) == 0 {
+				return false
+			}
+			sample.WriteRune(re.Rune[0])
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			sample.WriteRune('a')
+		case syntax.OpCapture:
+			return walk(re.Sub[0])
+		case syntax.OpPlus:
+			return walk(re.Sub[0])
+		case syntax.OpRepeat:
+			for i := 0; i < re.Min; i++ {
+				if !walk(re.Sub[0]) {
+					return false
+				}
+			}
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				if !walk(sub) {
+					return false
+				}
+			}
+		case syntax.OpAlternate:
+			return walk(re.Sub[0])
+		case syntax.OpStar, syntax.OpQuest, syntax.OpEmptyMatch,
+			syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		default:
+			return false
+		}
+		return true
+	}
+	if !walk(re) {
+		return "", false
+	}
+	return sample.String(), true
+}
+
+// genGoTestVectorsStub writes the Go test which loads the test vectors and
+// checks them against the generated validation code.
+func (gen *CodeGenerator) genGoTestVectorsStub(vectorsFile string, simpleTypes []*SimpleType) error {
+	packageName := gen.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	var cases string
+	var importStrconv bool
+	for _, v := range simpleTypes {
+		typeName := genGoFieldName(v.Name, false)
+		fieldType := gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+		// The values which aren't parsed as the numbers of the type are
+		// rejected, the integers are parsed exactly.
+		value := fmt.Sprintf("v := %s(value)\n", typeName)
+		if parse, ok := gen.genGoTestVectorParse(fieldType); ok {
+			importStrconv = true
+			value = fmt.Sprintf("n, err := %s\nif err != nil {\nreturn err\n}\nv := %s(n)\n", parse, typeName)
+		}
+		validate := "v.Validate()"
+		if gen.Validation == ValidationStandalone {
+			validate = fmt.Sprintf("Validate%s(&v)", typeName)
+		}
+		cases += fmt.Sprintf("\t\"%s\": func(value string) error {\n%sreturn %s\n},\n", v.Name, value, validate)
+	}
+	imports := "\t\"encoding/json\"\n\t\"io/ioutil\"\n\t\"testing\"\n"
+	if importStrconv {
+		imports = "\t\"encoding/json\"\n\t\"io/ioutil\"\n\t\"strconv\"\n\t\"testing\"\n"
+	}
+	source := fmt.Sprintf(`%s
+
+package %s
+
+import (
+%s)
+
+var testVectorValidators = map[string]func(value string) error{
+%s}
+
+func TestVectors(t *testing.T) {
+	data, err := ioutil.ReadFile(%q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors struct {
+		Types []struct {
+			Name    string   `+"`json:\"name\"`"+`
+			Valid   []string `+"`json:\"valid\"`"+`
+			Invalid []string `+"`json:\"invalid\"`"+`
+		} `+"`json:\"types\"`"+`
+	}
+	if err = json.Unmarshal(data, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	for _, vector := range vectors.Types {
+		validate, ok := testVectorValidators[vector.Name]
+		if !ok {
+			t.Errorf("missing validator for %%s", vector.Name)
+			continue
+		}
+		for _, value := range vector.Valid {
+			if err := validate(value); err != nil {
+				t.Errorf("%%s: expected %%q to be valid: %%v", vector.Name, value, err)
+			}
+		}
+		for _, value := range vector.Invalid {
+			if err := validate(value); err == nil {
+				t.Errorf("%%s: expected %%q to be invalid", vector.Name, value)
+			}
+		}
+	}
+}
+`, gen.fileHeader(), packageName, imports, cases, vectorsFile)
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(gen.FileWithExtension(".vectors_test.go"), formatted)
+}
+
+// goTestVectorIntegerRegexp matches the integer types of Go and their sizes.
+var goTestVectorIntegerRegexp = regexp.MustCompile(`^(u?)int(8|16|32|64)?$`)
+
+// genGoTestVectorParse returns the expression parsing the value of the test
+// vector as the number of the numeric type for the Go test stub, the
+// integers by their size, and false if the type isn't numeric.
+func (gen *CodeGenerator) genGoTestVectorParse(fieldType string) (string, bool) {
+	if match := goTestVectorIntegerRegexp.FindStringSubmatch(fieldType); match != nil {
+		bits := match[2]
+		if bits == "" {
+			bits = "0"
+		}
+		if match[1] == "u" {
+			return fmt.Sprintf("strconv.ParseUint(value, 10, %s)", bits), true
+		}
+		return fmt.Sprintf("strconv.ParseInt(value, 10, %s)", bits), true
+	}
+	if fieldType == "float32" {
+		return "strconv.ParseFloat(value, 32)", true
+	}
+	if gen.isGoNumericType(fieldType) {
+		return "strconv.ParseFloat(value, 64)", true
+	}
+	return "", false
+}
+
+// genRustTestVectorsStub writes the Rust test module which loads the test
+// vectors and checks them against the generated validation code. The module
+// should be declared as a child module of the generated types, with the
+// standalone validator functions in scope if used.
+func (gen *CodeGenerator) genRustTestVectorsStub(vectorsFile string, simpleTypes []*SimpleType) error {
+	var cases string
+	for _, v := range simpleTypes {
+		fieldType := gen.genRustFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree))
+		structName := genRustStructName(v.Name, false)
+		// The values which aren't parsed as the numbers of the type are
+		// rejected, the integers are parsed exactly.
+		value := "value.to_string()"
+		if gen.isRustNumericType(fieldType) || isRustChronoType(fieldType) {
+			value = fmt.Sprintf("match value.parse::<%s>() {\n\t\t\t\t\tOk(v) => v,\n\t\t\t\t\tErr(_) => return false,\n\t\t\t\t}", fieldType)
+			if isDecimalType(fieldType) {
+				value = "match value.parse::<f64>() {\n\t\t\t\t\tOk(v) => v.into(),\n\t\t\t\t\tErr(_) => return false,\n\t\t\t\t}"
+			}
+		}
+		validate := "v.validate()"
+		if gen.Validation == ValidationStandalone {
+			validate = fmt.Sprintf("%s(&v)", genRustValidatorName(structName))
+		}
+		cases += fmt.Sprintf("\t\t\t\"%s\" => {\n\t\t\t\tlet v = %s { %s: %s };\n\t\t\t\t%s.is_ok()\n\t\t\t}\n", v.Name, structName, genRustFieldName(v.Name), value, validate)
+	}
+	source := fmt.Sprintf(`%s
+
+use super::*;
+
+fn validate_vector(name: &str, value: &str) -> bool {
+	match name {
+%s		_ => panic!("missing validator for {}", name),
+	}
+}
+
+#[test]
+fn test_vectors() {
+	let vectors: serde_json::Value = serde_json::from_str(include_str!(%q)).unwrap();
+	for vector in vectors["types"].as_array().unwrap() {
+		let name = vector["name"].as_str().unwrap();
+		for value in vector["valid"].as_array().unwrap() {
+			assert!(validate_vector(name, value.as_str().unwrap()), "{}: expected {} to be valid", name, value);
+		}
+		for value in vector["invalid"].as_array().unwrap() {
+			assert!(!validate_vector(name, value.as_str().unwrap()), "{}: expected {} to be invalid", name, value);
+		}
+	}
+}
+`, gen.fileHeader(), cases, vectorsFile)
+	return gen.WriteFile(gen.FileWithExtension(".vectors_test.rs"), []byte(source))
+}
@@ -10,8 +10,6 @@ package xgen
 
 import (
 	"fmt"
-	"os"
-	"reflect"
 	"strings"
 )
 
@@ -30,23 +28,94 @@ var typeScriptBuildInType = map[string]bool{
 func (gen *CodeGenerator) GenTypeScript() error {
 	fieldNameCount = make(map[string]int)
 	for _, ele := range gen.ProtoTree {
-		if ele == nil {
+		if ele == nil || gen.isPruned(ele) {
 			continue
 		}
-		funcName := fmt.Sprintf("TypeScript%s", reflect.TypeOf(ele).String()[6:])
-		callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+		gen.genDeclaration("TypeScript", ele)
 	}
-	f, err := os.Create(gen.FileWithExtension(".ts"))
-	if err != nil {
-		return err
+	if strings.Contains(gen.Field, "parseXMLElement(") {
+		gen.Field += typeScriptXMLFunctions
 	}
-	defer f.Close()
-	source := []byte(fmt.Sprintf("%s\n%s", copyright, gen.Field))
-	f.Write(source)
-	return err
+	source := []byte(fmt.Sprintf("%s\n%s", gen.fileHeader(), gen.Field))
+	return gen.WriteFile(gen.FileWithExtension(".ts"), source)
 
 }
 
+// typeScriptXMLFunctions converts between the XML documents and the
+// generated classes. Child elements and attributes are mapped to the fields
+// named as the generated fields, repeated elements to arrays and the text of
+// the elements with attributes to the Value field.
+const typeScriptXMLFunctions = `
+function parseXMLElement(xml: string, name: string): any {
+	const root = new DOMParser().parseFromString(xml, 'application/xml').documentElement;
+	if (root.localName !== name) {
+		throw new Error(` + "`unexpected root element ${root.localName}, expected ${name}`" + `);
+	}
+	return fromXMLElement(root);
+}
+
+function fromXMLElement(element: Element): any {
+	const value: any = {};
+	for (const attr of Array.from(element.attributes)) {
+		if (attr.name !== 'xmlns' && attr.prefix !== 'xmlns') {
+			value[attr.localName.charAt(0).toUpperCase() + attr.localName.slice(1).replace(/-/g, '') + 'Attr'] = attr.value;
+		}
+	}
+	const children = Array.from(element.children);
+	if (children.length === 0) {
+		if (Object.keys(value).length === 0) {
+			return element.textContent;
+		}
+		value.Value = element.textContent;
+		return value;
+	}
+	for (const child of children) {
+		const key = child.localName.charAt(0).toUpperCase() + child.localName.slice(1).replace(/-/g, '');
+		const item = fromXMLElement(child);
+		value[key] = key in value ? [].concat(value[key], item) : item;
+	}
+	return value;
+}
+
+function toXMLElement(name: string, namespace: string, value: any): string {
+	const xmlns = namespace ? ` + "` xmlns=\"${escapeXML(namespace)}\"`" + ` : '';
+	const xml = toXMLContent(name, value);
+	return ` + "`<${name}${xmlns}${xml.slice(name.length + 1)}`" + `;
+}
+
+function toXMLContent(name: string, value: any): string {
+	if (value === null || value === undefined) {
+		return '';
+	}
+	if (Array.isArray(value)) {
+		return value.map((item) => toXMLContent(name, item)).join('');
+	}
+	if (typeof value !== 'object') {
+		return ` + "`<${name}>${escapeXML(String(value))}</${name}>`" + `;
+	}
+	let attrs = '';
+	let content = '';
+	for (const field of Object.keys(value)) {
+		// The private fields of the accessors are prefixed by an underscore.
+		const key = field.replace(/^_/, '');
+		if (key.endsWith('Attr')) {
+			if (value[field] !== null && value[field] !== undefined) {
+				attrs += ` + "` ${key.slice(0, -4)}=\"${escapeXML(String(value[field]))}\"`" + `;
+			}
+		} else if (key === 'Value') {
+			content += escapeXML(String(value[field]));
+		} else {
+			content += toXMLContent(key, value[field]);
+		}
+	}
+	return ` + "`<${name}${attrs}>${content}</${name}>`" + `;
+}
+
+function escapeXML(value: string): string {
+	return value.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+}
+`
+
 func genTypeScriptFieldName(name string, unique bool) (fieldName string) {
 	for _, str := range strings.Split(name, ":") {
 		fieldName += MakeFirstUpperCase(str)
@@ -66,8 +135,8 @@ func genTypeScriptFieldName(name string, unique bool) (fieldName string) {
 	return
 }
 
-func genTypeScriptFieldType(name string, plural bool) (fieldType string) {
-	if _, ok := typeScriptBuildInType[name]; ok {
+func (gen *CodeGenerator) genTypeScriptFieldType(name string, plural bool) (fieldType string) {
+	if _, ok := typeScriptBuildInType[name]; ok || gen.builtInTypes[name] {
 		fieldType = name
 		return
 	}
@@ -89,11 +158,12 @@ func genTypeScriptFieldType(name string, plural bool) (fieldType string) {
 func (gen *CodeGenerator) TypeScriptSimpleType(v *SimpleType) {
 	if v.List {
 		if _, ok := gen.StructAST[v.Name]; !ok {
-			fieldType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), true)
+			fieldType := gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), true)
 			content := fmt.Sprintf(" = %s;\n", fieldType)
 			gen.StructAST[v.Name] = content
 			fieldName := genTypeScriptFieldName(v.Name, true)
-			gen.Field += fmt.Sprintf("%sexport type %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			gen.addSymbol(v, fieldName)
+			gen.Field += fmt.Sprintf("%sexport type %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
 			return
 		}
 	}
@@ -107,18 +177,19 @@ func (gen *CodeGenerator) TypeScriptSimpleType(v *SimpleType) {
 				if memberType == "" { // fix order issue
 					memberType = getBasefromSimpleType(memberName, gen.ProtoTree)
 				}
-				content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(memberName, false), genTypeScriptFieldType(memberType, false))
+				content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(memberName, false), gen.genTypeScriptFieldType(memberType, false))
 			}
-			content += "}\n"
+			content = gen.genTypeScriptAccessors(content) + "}\n"
 			gen.StructAST[v.Name] = content
 			fieldName := genTypeScriptFieldName(v.Name, true)
-			gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+			gen.addSymbol(v, fieldName)
+			gen.Field += fmt.Sprintf("%sexport class %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
 		}
 		return
 	}
 	if len(v.Restriction.Enum) > 0 {
 		var content string
-		baseType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false)
+		baseType := gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false)
 		for _, enum := range v.Restriction.Enum {
 			switch baseType {
 			case "string":
@@ -130,14 +201,16 @@ func (gen *CodeGenerator) TypeScriptSimpleType(v *SimpleType) {
 			}
 		}
 		fieldName := genTypeScriptFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%sexport enum %s {\n%s}\n", genFieldComment(fieldName, v.Doc, "//"), fieldName, content)
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%sexport enum %s {\n%s}\n", gen.genComment(fieldName, v.Doc), fieldName, content)
 		return
 	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		content := fmt.Sprintf(" %s;\n", genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false))
+		content := fmt.Sprintf(" %s;\n", gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false))
 		gen.StructAST[v.Name] = content
 		fieldName := genTypeScriptFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%sexport type %s =%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%sexport type %s =%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
 	}
 }
 
@@ -145,10 +218,18 @@ func (gen *CodeGenerator) TypeScriptSimpleType(v *SimpleType) {
 // syntax.
 func (gen *CodeGenerator) TypeScriptComplexType(v *ComplexType) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
+		fieldName := genTypeScriptFieldName(v.Name, true)
+		gen.addSymbol(v, fieldName)
+		if shared := gen.getSharedAnonymousType(v, fieldName); shared != "" {
+			// Structurally identical anonymous types share the class.
+			gen.StructAST[v.Name] = fmt.Sprintf(" = %s;\n", shared)
+			gen.Field += fmt.Sprintf("%sexport type %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
+			return
+		}
 		content := " {\n"
 		for _, attrGroup := range v.AttributeGroup {
 			fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(attrGroup.Name, false), genTypeScriptFieldType(fieldType, false))
+			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(attrGroup.Name, false), gen.genTypeScriptFieldType(fieldType, false))
 		}
 
 		for _, attribute := range v.Attributes {
@@ -156,39 +237,38 @@ func (gen *CodeGenerator) TypeScriptComplexType(v *ComplexType) {
 			if attribute.Optional {
 				optional = ` | null`
 			}
-			fieldType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural)
-			content += fmt.Sprintf("\t%sAttr: %s%s;\n", genTypeScriptFieldName(attribute.Name, false), fieldType, optional)
+			fieldType := gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural)
+			content += genTypeScriptDeprecatedDoc(attribute.Deprecated, attribute.Deprecation) + fmt.Sprintf("\t%sAttr: %s%s;\n", genTypeScriptFieldName(attribute.Name, false), fieldType, optional)
 		}
 		for _, group := range v.Groups {
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(group.Name, false), genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural))
+			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(gen.genPluralName(group.Name, group.Plural), false), gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural))
 		}
 
 		for _, element := range v.Elements {
-			fieldType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural)
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(element.Name, false), fieldType)
+			fieldType := gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural)
+			content += genTypeScriptDeprecatedDoc(element.Deprecated, element.Deprecation) + fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(gen.genPluralName(element.Name, element.Plural), false), fieldType)
 		}
+		content += genTypeScriptWildcardField(v.Any || v.AnyAttribute)
 
-		if len(v.Base) > 0 && isBuiltInTypeScriptType(v.Base) {
-			fieldType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false)
+		if len(v.Base) > 0 && gen.isBuiltInTypeScriptType(v.Base) {
+			fieldType := gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false)
 			content += fmt.Sprintf("\tValue: %s;\n", fieldType)
 		}
-		content += "}\n"
+		content = gen.genTypeScriptAccessors(content) + "}\n"
 		gen.StructAST[v.Name] = content
-		fieldName := genTypeScriptFieldName(v.Name, true)
 		typeExtension := ""
-		if len(v.Base) > 0 && !isBuiltInTypeScriptType(v.Base) {
-			fieldType := genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false)
+		if len(v.Base) > 0 && !gen.isBuiltInTypeScriptType(v.Base) {
+			fieldType := gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree), false)
 			content += fmt.Sprintf("\tValue: %s;\n", fieldType)
 			typeExtension = fmt.Sprintf(" extends %s ", fieldType)
 		}
 
-		gen.Field += fmt.Sprintf("%sexport class %s%s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, typeExtension, gen.StructAST[v.Name])
+		gen.Field += fmt.Sprintf("%sexport class %s%s%s", gen.genComment(fieldName, v.Doc), fieldName, typeExtension, gen.StructAST[v.Name])
 	}
 }
 
-func isBuiltInTypeScriptType(typeName string) bool {
-	_, builtIn := typeScriptBuildInType[typeName]
-	return builtIn
+func (gen *CodeGenerator) isBuiltInTypeScriptType(typeName string) bool {
+	return typeScriptBuildInType[typeName] || gen.builtInTypes[typeName]
 }
 
 // TypeScriptGroup generates code for group XML schema in TypeScript language syntax.
@@ -196,17 +276,19 @@ func (gen *CodeGenerator) TypeScriptGroup(v *Group) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
 		content := " {\n"
 		for _, element := range v.Elements {
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(element.Name, false), genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural))
+			content += genTypeScriptDeprecatedDoc(element.Deprecated, element.Deprecation) + fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(gen.genPluralName(element.Name, element.Plural), false), gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(element.Type), gen.ProtoTree), element.Plural))
 		}
 
 		for _, group := range v.Groups {
-			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(group.Name, false), genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural))
+			content += fmt.Sprintf("\t%s: %s;\n", genTypeScriptFieldName(gen.genPluralName(group.Name, group.Plural), false), gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree), group.Plural))
 		}
+		content += genTypeScriptWildcardField(v.Any)
 
-		content += "}\n"
+		content = gen.genTypeScriptAccessors(content) + "}\n"
 		gen.StructAST[v.Name] = content
 		fieldName := genTypeScriptFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%sexport class %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
 	}
 }
 
@@ -220,29 +302,68 @@ func (gen *CodeGenerator) TypeScriptAttributeGroup(v *AttributeGroup) {
 			if attribute.Optional {
 				optional = ` | null`
 			}
-			content += fmt.Sprintf("\t%sAttr: %s%s;\n", genTypeScriptFieldName(attribute.Name, false), genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural), optional)
+			content += genTypeScriptDeprecatedDoc(attribute.Deprecated, attribute.Deprecation) + fmt.Sprintf("\t%sAttr: %s%s;\n", genTypeScriptFieldName(attribute.Name, false), gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(attribute.Type), gen.ProtoTree), attribute.Plural), optional)
 		}
-		content += "}\n"
+		content += genTypeScriptWildcardField(v.AnyAttribute)
+		content = gen.genTypeScriptAccessors(content) + "}\n"
 		gen.StructAST[v.Name] = content
 		fieldName := genTypeScriptFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%sexport class %s%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%sexport class %s%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
 	}
 }
 
 // TypeScriptElement generates code for element XML schema in TypeScript language syntax.
 func (gen *CodeGenerator) TypeScriptElement(v *Element) {
+	defer gen.genJSONSchemaConstant(v)
+	if gen.RootWrappers && isRootElement(v, gen.ProtoTree) {
+		gen.genTypeScriptRootWrapper(v)
+		return
+	}
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		gen.StructAST[v.Name] = fmt.Sprintf(" %s;\n", genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree), v.Plural))
+		gen.StructAST[v.Name] = fmt.Sprintf(" %s;\n", gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree), v.Plural))
 		fieldName := genTypeScriptFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%sexport type %s =%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%sexport type %s =%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
 	}
 }
 
+// genTypeScriptRootWrapper generates the typed wrapper of the root element,
+// with the parseXML and toXML methods for parsing and serializing the XML
+// documents by the DOM API.
+func (gen *CodeGenerator) genTypeScriptRootWrapper(v *Element) {
+	wrapperName := genTypeScriptFieldName(v.Name, false) + "Root"
+	if _, ok := gen.StructAST[wrapperName]; ok {
+		return
+	}
+	gen.addSymbol(v, wrapperName)
+	fieldName := genTypeScriptFieldName(v.Name, false)
+	fieldType := gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree), false)
+	gen.StructAST[wrapperName] = fmt.Sprintf(` {
+	static readonly elementName = '%s';
+	static readonly namespace = '%s';
+	%s: %s;
+
+	static parseXML(xml: string): %s {
+		const root = new %s();
+		root.%s = parseXMLElement(xml, %s.elementName);
+		return root;
+	}
+
+	toXML(): string {
+		return toXMLElement(%s.elementName, %s.namespace, this.%s);
+	}
+}
+`, v.Name, gen.TargetNamespace, fieldName, fieldType, wrapperName, wrapperName, fieldName, wrapperName, wrapperName, wrapperName, fieldName)
+	gen.Field += fmt.Sprintf("%sexport class %s%s", gen.genComment(wrapperName, v.Doc), wrapperName, gen.StructAST[wrapperName])
+}
+
 // TypeScriptAttribute generates code for attribute XML schema in TypeScript language syntax.
 func (gen *CodeGenerator) TypeScriptAttribute(v *Attribute) {
 	if _, ok := gen.StructAST[v.Name]; !ok {
-		gen.StructAST[v.Name] = fmt.Sprintf(" %s;\n", genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree), v.Plural))
+		gen.StructAST[v.Name] = fmt.Sprintf(" %s;\n", gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(v.Type), gen.ProtoTree), v.Plural))
 		fieldName := genTypeScriptFieldName(v.Name, true)
-		gen.Field += fmt.Sprintf("%sexport type %s =%s", genFieldComment(fieldName, v.Doc, "//"), fieldName, gen.StructAST[v.Name])
+		gen.addSymbol(v, fieldName)
+		gen.Field += fmt.Sprintf("%sexport type %s =%s", gen.genComment(fieldName, v.Doc), fieldName, gen.StructAST[v.Name])
 	}
 }
@@ -0,0 +1,90 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// getIncludedValueType returns the type of the value declared by the schemas
+// included by the schema of the options, and by the schemas they include in
+// turn.
+func (opt *Options) getIncludedValueType(value string) (valueType string, err error) {
+	name := trimNSPrefix(value)
+	valueType = name
+	err = opt.walkIncludedSchemas(func(protoTree []interface{}) bool {
+		if vt := getBasefromSimpleType(name, protoTree); vt != name {
+			valueType = vt
+			return true
+		}
+		return false
+	})
+	return
+}
+
+// walkIncludedSchemas calls the function with the proto tree of each schema
+// included by the schema of the options, and of the schemas they include in
+// turn, until the function returns true. The included schemas are walked in
+// the order of their locations, so the declaration found doesn't depend on
+// the order of the map, and each of them is parsed once, whether it's
+// included along several paths or by a cycle of the schemas including each
+// other.
+func (opt *Options) walkIncludedSchemas(fn func(protoTree []interface{}) bool) (err error) {
+	visited := map[string]bool{getSchemaID(opt.FilePath): true}
+	for found := true; found; {
+		found = false
+		// The parses add the includes of the included schemas to the map.
+		includes := make([]string, 0, len(opt.IncludeMap))
+		for include := range opt.IncludeMap {
+			includes = append(includes, include)
+		}
+		sort.Strings(includes)
+		for _, include := range includes {
+			var includeFile string
+			if includeFile, err = opt.resolveSchemaLocation(include); err != nil {
+				return
+			}
+			if includeFile == "" || visited[getSchemaID(includeFile)] {
+				continue
+			}
+			visited[getSchemaID(includeFile)], found = true, true
+			parser := NewParser(opt.subOptions(includeFile, true))
+			if err = parser.Parse(); err != nil {
+				return getLimitError(err)
+			}
+			opt.addWarnings(parser.Warnings)
+			if fn(parser.ProtoTree) {
+				return
+			}
+		}
+	}
+	return
+}
+
+// getSchemaID returns the absolute path of the schema file, which identifies
+// the schema however its location is written by the imports and includes.
+func getSchemaID(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		return abs
+	}
+	return filepath.Clean(file)
+}
+
+// isInInputDir returns true if the schema file is in the input directory of
+// the options, so its code is generated under the output directory as the
+// code of the input files.
+func (opt *Options) isInInputDir(file string) bool {
+	if opt.InputDir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(opt.InputDir, file)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
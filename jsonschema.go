@@ -0,0 +1,439 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonSchemaDialect is the dialect of the JSON Schema documents embedded in
+// the generated code.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// jsonSchemaLangs holds the languages the JSON Schema constants are
+// generated for.
+var jsonSchemaLangs = map[string]bool{"Go": true, "Rust": true, "TypeScript": true}
+
+// jsonSchemaBuildInTypes maps the built-in types of the XML schema to the
+// types of their JSON values, the other built-in types are strings.
+var jsonSchemaBuildInTypes = map[string]string{
+	"boolean":            "boolean",
+	"byte":               "integer",
+	"int":                "integer",
+	"integer":            "integer",
+	"long":               "integer",
+	"negativeInteger":    "integer",
+	"nonNegativeInteger": "integer",
+	"nonPositiveInteger": "integer",
+	"positiveInteger":    "integer",
+	"short":              "integer",
+	"unsignedByte":       "integer",
+	"unsignedInt":        "integer",
+	"unsignedLong":       "integer",
+	"unsignedShort":      "integer",
+	"decimal":            "number",
+	"double":             "number",
+	"float":              "number",
+	"ENTITIES":           "array",
+	"IDREFS":             "array",
+	"NMTOKENS":           "array",
+}
+
+// getJSONSchemaType returns the JSON Schema type of the values of the field
+// type in the language of the code generator, and false if the field type
+// isn't a built-in type. The type is empty if the built-in types of the
+// field type have different JSON types, which accepts any value.
+func (gen *CodeGenerator) getJSONSchemaType(fieldType string) (string, bool) {
+	if fieldType == booleanTypes[gen.Lang] {
+		return "boolean", true
+	}
+	types := map[string]bool{}
+	for name := range BuildInTypes {
+		if buildType, _ := getBuildInTypeByLang(name, gen.Lang); buildType == fieldType {
+			jsonType, ok := jsonSchemaBuildInTypes[name]
+			if !ok {
+				jsonType = "string"
+			}
+			types[jsonType] = true
+		}
+	}
+	switch {
+	case len(types) == 0:
+		return "", false
+	case len(types) == 1:
+		for jsonType := range types {
+			return jsonType, true
+		}
+	case len(types) == 2 && types["integer"] && types["number"]:
+		return "number", true
+	}
+	return "", true
+}
+
+// getJSONSchemaPropertyName returns the name of the member in the JSON
+// encoding of the generated type in the language of the code generator,
+// which is the name of the Go and TypeScript field, and the name the Rust
+// field is renamed to by serde.
+func (gen *CodeGenerator) getJSONSchemaPropertyName(name string, plural, attribute bool) string {
+	switch gen.Lang {
+	case "Go":
+		if attribute {
+			return genGoFieldName(name, false) + "Attr"
+		}
+		return genGoFieldName(gen.genPluralName(name, plural), false)
+	case "TypeScript":
+		if attribute {
+			return genTypeScriptFieldName(name, false) + "Attr"
+		}
+		return genTypeScriptFieldName(gen.genPluralName(name, plural), false)
+	}
+	return gen.genRustFieldRename(name)
+}
+
+// jsonSchemaObject holds the properties and the required properties of the
+// JSON Schema of a generated type, along with the schemas of the types it
+// embeds.
+type jsonSchemaObject struct {
+	properties map[string]interface{}
+	required   []string
+	embedded   []interface{}
+}
+
+// addProperty adds the property of the member to the object, the property
+// of the repeated member is an array, and the property of the optional
+// member may be null.
+func (o *jsonSchemaObject) addProperty(name string, schema map[string]interface{}, plural, optional bool) {
+	if plural {
+		schema = map[string]interface{}{"type": "array", "items": schema}
+	}
+	if optional {
+		schema = map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+	} else {
+		o.required = append(o.required, name)
+	}
+	o.properties[name] = schema
+}
+
+// schema returns the JSON Schema of the object with given description.
+func (o *jsonSchemaObject) schema(doc string) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object", "properties": o.properties}
+	if len(o.required) > 0 {
+		schema["required"] = o.required
+	}
+	if len(o.embedded) > 0 {
+		schema = map[string]interface{}{"allOf": append(o.embedded, schema)}
+	}
+	if doc = strings.TrimSpace(doc); doc != "" {
+		schema["description"] = doc
+	}
+	return schema
+}
+
+// genJSONSchemaValue returns the JSON Schema of the values of the type by
+// given name with the facets of the restriction. The declarations of the
+// schema are added to the definitions and referenced by name, the built-in
+// types are constrained by the facets, and the other types accept any value.
+func (gen *CodeGenerator) genJSONSchemaValue(name string, restriction Restriction, defs map[string]interface{}) map[string]interface{} {
+	if restriction.IsEmpty() {
+		restriction = getRestrictionFromSimpleType(trimNSPrefix(name), gen.ProtoTree)
+	}
+	fieldType := getBasefromSimpleType(trimNSPrefix(name), gen.ProtoTree)
+	if gen.addJSONSchemaDef(fieldType, defs) {
+		return map[string]interface{}{"$ref": "#/$defs/" + fieldType}
+	}
+	schema := map[string]interface{}{}
+	jsonType, ok := gen.getJSONSchemaType(fieldType)
+	if !ok || jsonType == "" {
+		return schema
+	}
+	schema["type"] = jsonType
+	switch jsonType {
+	case "array":
+		schema["items"] = map[string]interface{}{"type": "string"}
+	case "string":
+		if restriction.HasLength {
+			schema["minLength"], schema["maxLength"] = restriction.Length, restriction.Length
+		}
+		if restriction.MinLength > 0 {
+			schema["minLength"] = restriction.MinLength
+		}
+		if restriction.MaxLength > 0 {
+			schema["maxLength"] = restriction.MaxLength
+		}
+		if restriction.Pattern != nil {
+			schema["pattern"] = "^(?:" + restriction.Pattern.String() + ")$"
+		}
+		if len(restriction.Enum) > 0 {
+			schema["enum"] = restriction.Enum
+		}
+	case "integer", "number":
+		// The infinite bounds can't be written in JSON.
+		if min := restriction.minValue(); restriction.HasMin && !min.IsInf() {
+			if restriction.MinExclusive {
+				schema["exclusiveMinimum"] = genJSONSchemaNumber(min)
+			} else {
+				schema["minimum"] = genJSONSchemaNumber(min)
+			}
+		}
+		if max := restriction.maxValue(); restriction.HasMax && !max.IsInf() {
+			if restriction.MaxExclusive {
+				schema["exclusiveMaximum"] = genJSONSchemaNumber(max)
+			} else {
+				schema["maximum"] = genJSONSchemaNumber(max)
+			}
+		}
+		var values []interface{}
+		for _, enum := range restriction.Enum {
+			if value, err := newFacetValue(enum, restriction.base); err == nil && value.IsNumeric() && !value.IsInf() {
+				values = append(values, genJSONSchemaNumber(value))
+			}
+		}
+		if len(values) > 0 {
+			schema["enum"] = values
+		}
+	}
+	return schema
+}
+
+// genJSONSchemaNumber returns the JSON number of the facet value, written
+// exactly from the values of the integer and the decimal types.
+func genJSONSchemaNumber(value FacetValue) interface{} {
+	if _, ok := value.Exact(); ok {
+		return json.Number(value.String())
+	}
+	return value.Float
+}
+
+// addJSONSchemaDef adds the definition of the declaration by given name to
+// the definitions unless it was added, and returns false if there isn't a
+// declaration of a generated type by the name. The definition is added
+// before the schemas of the members, so the recursive types reference it.
+func (gen *CodeGenerator) addJSONSchemaDef(name string, defs map[string]interface{}) bool {
+	if _, ok := defs[name]; ok {
+		return true
+	}
+	for _, ele := range gen.ProtoTree {
+		switch v := ele.(type) {
+		case *ComplexType:
+			if v.Name == name {
+				defs[name] = nil
+				defs[name] = gen.genJSONSchemaComplexType(v, defs)
+				return true
+			}
+		case *Group:
+			if v.Name == name {
+				defs[name] = nil
+				o := &jsonSchemaObject{properties: map[string]interface{}{}}
+				gen.addJSONSchemaElements(o, v.Elements, v.Groups, defs)
+				defs[name] = o.schema(v.Doc)
+				return true
+			}
+		case *AttributeGroup:
+			if v.Name == name {
+				defs[name] = nil
+				o := &jsonSchemaObject{properties: map[string]interface{}{}}
+				gen.addJSONSchemaAttributes(o, v.Attributes, defs)
+				defs[name] = o.schema(v.Doc)
+				return true
+			}
+		case *SubstitutionGroup:
+			if v.Name == name {
+				defs[name] = nil
+				defs[name] = gen.genJSONSchemaAlternatives(v.Doc, v.Members, false, defs)
+				return true
+			}
+		case *DerivedTypes:
+			if v.Name == name {
+				defs[name] = nil
+				defs[name] = gen.genJSONSchemaAlternatives(v.Doc, v.Members, true, defs)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// genJSONSchemaComplexType returns the JSON Schema of the complex type. The
+// base types and the mixins are embedded, as their fields are flattened into
+// the JSON objects of the generated types.
+func (gen *CodeGenerator) genJSONSchemaComplexType(v *ComplexType, defs map[string]interface{}) map[string]interface{} {
+	o := &jsonSchemaObject{properties: map[string]interface{}{}}
+	for _, attrGroup := range v.AttributeGroup {
+		fieldType := getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), gen.ProtoTree)
+		if gen.Lang != "TypeScript" && gen.getMixinAttributeGroup(attrGroup.Ref) != nil {
+			o.embedded = append(o.embedded, gen.genJSONSchemaValue(fieldType, Restriction{}, defs))
+			continue
+		}
+		o.addProperty(gen.getJSONSchemaPropertyName(attrGroup.Name, false, false), gen.genJSONSchemaValue(fieldType, Restriction{}, defs), false, false)
+	}
+	gen.addJSONSchemaAttributes(o, v.Attributes, defs)
+	gen.addJSONSchemaMixedContent(o, v, defs)
+	if len(v.Base) > 0 {
+		fieldType := getBasefromSimpleType(trimNSPrefix(v.Base), gen.ProtoTree)
+		if _, ok := gen.getJSONSchemaType(fieldType); ok {
+			name := map[string]string{"Go": "Value", "TypeScript": "Value"}[gen.Lang]
+			if name == "" {
+				name = gen.genRustFieldRename("value")
+			}
+			o.addProperty(name, gen.genJSONSchemaValue(fieldType, Restriction{}, defs), false, false)
+		} else {
+			o.embedded = append(o.embedded, gen.genJSONSchemaValue(fieldType, Restriction{}, defs))
+		}
+	}
+	return o.schema(v.Doc)
+}
+
+// addJSONSchemaAttributes adds the properties of the attributes to the
+// object.
+func (gen *CodeGenerator) addJSONSchemaAttributes(o *jsonSchemaObject, attributes []Attribute, defs map[string]interface{}) {
+	for _, attribute := range attributes {
+		o.addProperty(gen.getJSONSchemaPropertyName(attribute.Name, attribute.Plural, true), gen.genJSONSchemaValue(attribute.Type, attribute.Restriction, defs), attribute.Plural, attribute.Optional)
+	}
+}
+
+// addJSONSchemaElements adds the properties of the groups and the elements
+// to the object, the groups generated as mixins are embedded.
+func (gen *CodeGenerator) addJSONSchemaElements(o *jsonSchemaObject, elements []Element, groups []Group, defs map[string]interface{}) {
+	for _, group := range groups {
+		fieldType := getBasefromSimpleType(trimNSPrefix(group.Ref), gen.ProtoTree)
+		if gen.Lang != "TypeScript" && gen.getMixinGroup(group) != nil {
+			o.embedded = append(o.embedded, gen.genJSONSchemaValue(fieldType, Restriction{}, defs))
+			continue
+		}
+		o.addProperty(gen.getJSONSchemaPropertyName(group.Name, group.Plural, false), gen.genJSONSchemaValue(fieldType, Restriction{}, defs), group.Plural, false)
+	}
+	for _, element := range elements {
+		o.addProperty(gen.getJSONSchemaPropertyName(element.Name, element.Plural, false), gen.genJSONSchemaValue(element.Type, element.Restriction, defs), element.Plural, element.Optional)
+	}
+}
+
+// addJSONSchemaMixedContent adds the properties of the elements and the
+// character data of the complex type to the object. The character data of
+// the mixed type is the Go field, and the content of the Rust field is the
+// sequence of the character data and the elements tagged by their names.
+func (gen *CodeGenerator) addJSONSchemaMixedContent(o *jsonSchemaObject, v *ComplexType, defs map[string]interface{}) {
+	if gen.Lang == "Go" {
+		if fieldName := gen.getGoMixedFieldName(v); fieldName != "" {
+			o.addProperty(fieldName, map[string]interface{}{"type": "string"}, false, false)
+		}
+	}
+	c := gen.getRustMixedContent(v)
+	if gen.Lang != "Rust" || c == nil {
+		gen.addJSONSchemaElements(o, v.Elements, v.Groups, defs)
+		return
+	}
+	gen.addJSONSchemaElements(o, nil, v.Groups, defs)
+	alternatives := []interface{}{map[string]interface{}{"type": "object", "properties": map[string]interface{}{rustMixedTextTag: map[string]interface{}{"type": "string"}}, "required": []string{rustMixedTextTag}}}
+	for _, element := range c.elements {
+		name := gen.genRustFieldRename(element.Name)
+		alternatives = append(alternatives, map[string]interface{}{"type": "object", "properties": map[string]interface{}{name: gen.genJSONSchemaValue(element.Type, element.Restriction, defs)}, "required": []string{name}})
+	}
+	o.properties["$value"] = map[string]interface{}{"type": "array", "items": map[string]interface{}{"oneOf": alternatives}}
+}
+
+// genJSONSchemaAlternatives returns the JSON Schema of the substitution
+// group or the derived types of an abstract type, which is one of the
+// members in the JSON encoding of the language: the value of the Go struct,
+// the Rust enum externally tagged by the element or internally tagged by the
+// xsi:type attribute, and the TypeScript union.
+func (gen *CodeGenerator) genJSONSchemaAlternatives(doc string, members []Element, derived bool, defs map[string]interface{}) map[string]interface{} {
+	var alternatives []interface{}
+	for _, member := range members {
+		schema := gen.genJSONSchemaValue(member.Type, member.Restriction, defs)
+		if gen.Lang == "Rust" {
+			tag := map[string]interface{}{"type": "object", "properties": map[string]interface{}{member.Name: schema}, "required": []string{member.Name}}
+			if derived {
+				tag = map[string]interface{}{"allOf": []interface{}{schema, map[string]interface{}{"type": "object", "properties": map[string]interface{}{"@xsi:type": map[string]interface{}{"const": member.Name}}, "required": []string{"@xsi:type"}}}}
+			}
+			schema = tag
+		}
+		alternatives = append(alternatives, schema)
+	}
+	schema := map[string]interface{}{"oneOf": alternatives}
+	if gen.Lang == "Go" {
+		schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{"Value": schema}, "required": []string{"Value"}}
+	}
+	if doc = strings.TrimSpace(doc); doc != "" {
+		schema["description"] = doc
+	}
+	return schema
+}
+
+// genJSONSchema returns the JSON Schema document of the JSON encoding of the
+// type of the root element, with the definitions of the types it consists
+// of.
+func (gen *CodeGenerator) genJSONSchema(v *Element) (string, error) {
+	defs := map[string]interface{}{}
+	schema := gen.genJSONSchemaValue(v.Type, v.Restriction, defs)
+	schema["$schema"], schema["title"] = jsonSchemaDialect, v.Name
+	if doc := strings.TrimSpace(v.Doc); doc != "" {
+		schema["description"] = doc
+	}
+	if len(defs) > 0 {
+		schema["$defs"] = defs
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(schema); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// genJSONSchemaConstant generates the constant of the JSON Schema of the
+// root element after the type of the element, so the services expose the
+// schemas of their payloads at runtime.
+func (gen *CodeGenerator) genJSONSchemaConstant(v *Element) {
+	if !gen.JSONSchemas || !jsonSchemaLangs[gen.Lang] || !isRootElement(v, gen.ProtoTree) {
+		return
+	}
+	schema, err := gen.genJSONSchema(v)
+	if err != nil {
+		return
+	}
+	doc := fmt.Sprintf("the JSON Schema of the JSON encoding of the %s root element.", v.Name)
+	switch gen.Lang {
+	case "Go":
+		name := genGoFieldName(v.Name, false) + "JSONSchema"
+		if _, ok := gen.StructAST[name]; ok {
+			return
+		}
+		gen.StructAST[name] = schema
+		literal := "`" + schema + "`"
+		if strings.Contains(schema, "`") {
+			literal = strconv.Quote(schema)
+		}
+		gen.Field += fmt.Sprintf("%sconst %s = %s\n", gen.genComment(name, doc), name, literal)
+	case "Rust":
+		name := strings.ToUpper(ToSnakeCase(v.Name)) + "_JSON_SCHEMA"
+		if _, ok := gen.StructAST[name]; ok {
+			return
+		}
+		gen.StructAST[name] = schema
+		hashes := "#"
+		for strings.Contains(schema, "\""+hashes) {
+			hashes += "#"
+		}
+		gen.Field += fmt.Sprintf("%spub const %s: &str = r%s\"%s\"%s;\n", gen.genComment(name, doc), name, hashes, schema, hashes)
+	case "TypeScript":
+		name := genTypeScriptFieldName(v.Name, false) + "JSONSchema"
+		if _, ok := gen.StructAST[name]; ok {
+			return
+		}
+		gen.StructAST[name] = schema
+		literal := strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(schema)
+		gen.Field += fmt.Sprintf("%sexport const %s = `%s`;\n", gen.genComment(name, doc), name, literal)
+	}
+}
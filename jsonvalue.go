@@ -0,0 +1,46 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// rustJSONValueFeature is the feature of the Rust code enabling the methods
+// converting the generated types to and from serde_json::Value.
+const rustJSONValueFeature = "xgen-json"
+
+// rustSerdeTypePattern matches the structs and enums of the Rust code which
+// derive the serde traits, and captures their names.
+var rustSerdeTypePattern = regexp.MustCompile(`#\[derive\([^)\n]*\bSerialize, Deserialize\)\]\n(?:#\[[^\n]*\]\n)*pub (?:struct|enum) (\w+)`)
+
+// genRustJSONValue generate the methods converting the structs and enums of
+// the code which derive the serde traits to and from serde_json::Value for
+// Rust code, enabled by the xgen-json feature, such as for the systems routing
+// the messages as dynamic JSON before binding them to the types.
+func genRustJSONValue(code string) (methods string) {
+	for _, match := range rustSerdeTypePattern.FindAllStringSubmatch(code, -1) {
+		methods += fmt.Sprintf(`
+#[cfg(feature = "%s")]
+impl %s {
+	/// Converts the %s to a serde_json::Value.
+	pub fn to_json_value(&self) -> Result<serde_json::Value, serde_json::Error> {
+		serde_json::to_value(self)
+	}
+
+	/// Converts a serde_json::Value to the %s.
+	pub fn from_json_value(value: serde_json::Value) -> Result<Self, serde_json::Error> {
+		serde_json::from_value(value)
+	}
+}
+`, rustJSONValueFeature, match[1], match[1], match[1])
+	}
+	return
+}
@@ -0,0 +1,223 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// LangDirs holds the names of the directories under the output directory the
+// code of each language is generated into, when many languages are generated
+// from one parse of the schemas.
+var LangDirs = map[string]string{
+	"Go":         "go",
+	"C":          "c",
+	"Java":       "java",
+	"Rust":       "rust",
+	"TypeScript": "typescript",
+}
+
+// ParseLangs returns the languages by given comma-separated list of names,
+// matched regardless of the case, the name ts is TypeScript. The duplicated
+// languages are ignored.
+func ParseLangs(value string) ([]string, error) {
+	var langs []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		lang := ""
+		for supported := range LangDirs {
+			if strings.EqualFold(name, supported) {
+				lang = supported
+			}
+		}
+		if strings.EqualFold(name, "ts") {
+			lang = "TypeScript"
+		}
+		if lang == "" {
+			return nil, fmt.Errorf("unsupport language %s", name)
+		}
+		if !seen[lang] {
+			seen[lang] = true
+			langs = append(langs, lang)
+		}
+	}
+	return langs, nil
+}
+
+// getBuildInType returns the built-in type of the language by given XSD type
+// name, with the any type fallback, the boolean, decimal, date and time types
+// of their forms. The schemas parsed for many languages keep the names of
+// the XSD types, which are mapped to the types of each language before the
+// resolve stage.
+func (opt *Options) getBuildInType(name string) (string, bool) {
+	if len(opt.Langs) > 0 {
+		_, ok := BuildInTypes[name]
+		return name, ok
+	}
+	if opt.AnyTypeFallback != "" && isAnyType(name) {
+		return opt.AnyTypeFallback, true
+	}
+	if booleanType, ok := opt.getBooleanType(); ok && name == "boolean" {
+		return booleanType, true
+	}
+	if decimalType, ok := opt.getDecimalType(); ok && name == "decimal" {
+		return decimalType, true
+	}
+	if chronoType, ok := opt.getChronoType(name); ok {
+		return chronoType, true
+	}
+	return getBuildInTypeByLang(name, opt.Lang)
+}
+
+// generateLangs runs the resolve, normalize and generate stages of the
+// pipeline for each language of the options on the proto tree parsed once,
+// generating the code of each language into its directory under the output
+// directory. The proto tree of the options keeps the names of the XSD types,
+// so the schemas importing it are parsed for all the languages as well.
+func (opt *Options) generateLangs() (err error) {
+	for _, lang := range opt.Langs {
+		sub := *opt
+		sub.Lang, sub.Langs, sub.Warnings = lang, nil, nil
+		sub.builtInTypes = nil
+		sub.ExternalTypes = opt.getCommonExternalTypes()
+		sub.OutputDir = filepath.Join(opt.OutputDir, LangDirs[lang])
+		// The code of the single schema file is generated into the
+		// directory of the language, instead of named by it.
+		if filepath.Clean(opt.InputDir) == filepath.Clean(opt.FilePath) {
+			sub.InputDir = filepath.Dir(opt.FilePath)
+		}
+		sub.ProtoTree = sub.retargetProtoTree(opt.ProtoTree)
+		if err = sub.ResolveTypes(); err != nil {
+			return
+		}
+		if err = sub.applyTransforms(StageResolve); err != nil {
+			return
+		}
+		sub.NormalizeNames()
+		if err = sub.applyTransforms(StageNormalize); err != nil {
+			return
+		}
+		if err = sub.GenerateCode(); err != nil {
+			return fmt.Errorf("generate %s code: %v", lang, err)
+		}
+		opt.langOptions = append(opt.langOptions, &sub)
+		opt.addWarnings(sub.Warnings)
+	}
+	opt.ParseFileList[opt.FilePath] = true
+	opt.ParseFileMap[opt.FilePath] = opt.ProtoTree
+	return
+}
+
+// retargetProtoTree returns a copy of the proto tree parsed with the names of
+// the XSD types, with the types mapped to the built-in types of the language
+// of the options. The declarations are copied, since the later stages change
+// them for the language.
+func (opt *Options) retargetProtoTree(protoTree []interface{}) []interface{} {
+	retarget := func(name string) string {
+		if externalType, ok := opt.getExternalTypeByQName(name); ok {
+			return externalType
+		}
+		if buildType, ok := opt.getBuildInType(name); ok {
+			return buildType
+		}
+		return name
+	}
+	elements := func(list []Element) []Element {
+		if list == nil {
+			return nil
+		}
+		copied := make([]Element, len(list))
+		for i, e := range list {
+			e.Type = retarget(e.Type)
+			e.Restriction.Enum = append([]string(nil), e.Restriction.Enum...)
+			copied[i] = e
+		}
+		return copied
+	}
+	attributes := func(list []Attribute) []Attribute {
+		if list == nil {
+			return nil
+		}
+		copied := make([]Attribute, len(list))
+		for i, a := range list {
+			a.Type = retarget(a.Type)
+			a.Restriction.Enum = append([]string(nil), a.Restriction.Enum...)
+			copied[i] = a
+		}
+		return copied
+	}
+	var groups func([]Group) []Group
+	groups = func(list []Group) []Group {
+		if list == nil {
+			return nil
+		}
+		copied := make([]Group, len(list))
+		for i, g := range list {
+			g.Ref = retarget(g.Ref)
+			g.Elements, g.Groups = elements(g.Elements), groups(g.Groups)
+			copied[i] = g
+		}
+		return copied
+	}
+	attributeGroups := func(list []AttributeGroup) []AttributeGroup {
+		if list == nil {
+			return nil
+		}
+		copied := make([]AttributeGroup, len(list))
+		for i, g := range list {
+			g.Ref = retarget(g.Ref)
+			g.Attributes = attributes(g.Attributes)
+			copied[i] = g
+		}
+		return copied
+	}
+	retargeted := make([]interface{}, 0, len(protoTree))
+	for _, ele := range protoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			simpleType := *v
+			simpleType.Base = retarget(v.Base)
+			if v.MemberTypes != nil {
+				simpleType.MemberTypes = make(map[string]string, len(v.MemberTypes))
+				for name, memberType := range v.MemberTypes {
+					simpleType.MemberTypes[name] = retarget(memberType)
+				}
+			}
+			simpleType.Restriction.Enum = append([]string(nil), v.Restriction.Enum...)
+			ele = &simpleType
+		case *Element:
+			ele = &elements([]Element{*v})[0]
+		case *Attribute:
+			ele = &attributes([]Attribute{*v})[0]
+		case *ComplexType:
+			complexType := *v
+			complexType.Base = retarget(v.Base)
+			complexType.Elements = elements(v.Elements)
+			complexType.Attributes = attributes(v.Attributes)
+			complexType.Groups = groups(v.Groups)
+			complexType.AttributeGroup = attributeGroups(v.AttributeGroup)
+			complexType.Choice = append([]Choice(nil), v.Choice...)
+			ele = &complexType
+		case *Group:
+			ele = &groups([]Group{*v})[0]
+		case *AttributeGroup:
+			ele = &attributeGroups([]AttributeGroup{*v})[0]
+		case *Unique:
+			unique := *v
+			ele = &unique
+		}
+		retargeted = append(retargeted, ele)
+	}
+	return retargeted
+}
@@ -0,0 +1,138 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The limits of the parser, which the schema documents from the untrusted
+// sources can't exceed.
+const (
+	LimitTokens          = "tokens"
+	LimitDepth           = "depth"
+	LimitImportDepth     = "import depth"
+	LimitDeclarations    = "declarations"
+	LimitEntityExpansion = "entity expansion"
+	LimitFileSize        = "file size"
+)
+
+// LimitError is the error of the schema document exceeding a limit of the
+// options, so the callers can tell the rejected documents apart from the
+// invalid ones.
+type LimitError struct {
+	// File is the path of the schema document.
+	File string
+	// Limit is the limit exceeded, such as LimitTokens.
+	Limit string
+	// Max is the maximum of the limit set by the options.
+	Max int64
+}
+
+// Error returns the message of the limit exceeded by the schema document.
+func (e *LimitError) Error() string {
+	var message string
+	switch e.Limit {
+	case LimitTokens:
+		message = fmt.Sprintf("the maximum of %d tokens", e.Max)
+	case LimitDepth:
+		message = fmt.Sprintf("the maximum depth of %d elements", e.Max)
+	case LimitImportDepth:
+		message = fmt.Sprintf("the maximum depth of %d imported schemas", e.Max)
+	case LimitDeclarations:
+		message = fmt.Sprintf("the maximum of %d declarations", e.Max)
+	case LimitEntityExpansion:
+		message = fmt.Sprintf("the maximum of %d bytes expanded from the entities", e.Max)
+	case LimitFileSize:
+		message = fmt.Sprintf("the maximum size of %d bytes", e.Max)
+	default:
+		message = fmt.Sprintf("the maximum %s of %d", e.Limit, e.Max)
+	}
+	if e.File == "" {
+		return "the document exceeds " + message
+	}
+	return fmt.Sprintf("%s: the document exceeds %s", e.File, message)
+}
+
+// getLimitError returns the LimitError the error is or wraps, or nil. The
+// errors of parsing the referenced schemas are ignored unless they exceed
+// the limits, which fail the parse of the referencing schema too.
+func getLimitError(err error) error {
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr
+	}
+	return nil
+}
+
+// limitReader reads the schema document up to the maximum size, and counts
+// the bytes the references of the custom entities expand to, which are
+// limited too. The errors of the limits are returned by the reads, so the
+// decoder stops at once.
+type limitReader struct {
+	source             io.Reader
+	entities           map[string]string
+	maxSize, maxExpand int64
+	size, expanded     int64
+	// reference holds the name of the entity reference being read, which
+	// may be split between the reads, and referencing is true after the
+	// ampersand of the reference.
+	reference   []byte
+	referencing bool
+}
+
+// newLimitReader returns the reader of the source limited by the options,
+// or the source itself if it's unlimited.
+func (opt *Options) newLimitReader(source io.Reader) io.Reader {
+	if opt.MaxFileSize <= 0 && (opt.MaxEntityExpansion <= 0 || len(opt.Entities) == 0) {
+		return source
+	}
+	return &limitReader{source: source, entities: opt.Entities, maxSize: opt.MaxFileSize, maxExpand: opt.MaxEntityExpansion}
+}
+
+// Read reads from the source, and returns the LimitError once the document
+// exceeds its limits.
+func (r *limitReader) Read(p []byte) (int, error) {
+	n, err := r.source.Read(p)
+	r.size += int64(n)
+	if r.maxSize > 0 && r.size > r.maxSize {
+		return 0, &LimitError{Limit: LimitFileSize, Max: r.maxSize}
+	}
+	if r.maxExpand > 0 {
+		for _, b := range p[:n] {
+			if r.count(b) {
+				return 0, &LimitError{Limit: LimitEntityExpansion, Max: r.maxExpand}
+			}
+		}
+	}
+	return n, err
+}
+
+// count scans the byte for the references of the custom entities, and
+// returns true if their expansions exceed the limit.
+func (r *limitReader) count(b byte) bool {
+	switch {
+	case b == '&':
+		r.reference, r.referencing = r.reference[:0], true
+	case !r.referencing:
+	case b == ';':
+		r.referencing = false
+		if value, ok := r.entities[string(r.reference)]; ok {
+			r.expanded += int64(len(value))
+		}
+	case len(r.reference) < 256:
+		r.reference = append(r.reference, b)
+	default:
+		// Too long to be the name of an entity.
+		r.referencing = false
+	}
+	return r.expanded > r.maxExpand
+}
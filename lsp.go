@@ -0,0 +1,758 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	// xsdNamespace is the namespace of the XML schema definition language.
+	xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+
+	// lspTextDocumentSyncFull is the text document sync kind of the server,
+	// the whole content of the document is sent on each change.
+	lspTextDocumentSyncFull = 1
+
+	// Severities of the diagnostics.
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+
+	// lspMethodNotFound is the error code of the requests of the methods the
+	// server doesn't implement.
+	lspMethodNotFound = -32601
+)
+
+// lspDeclarationSpaces maps the top-level schema components to the symbol
+// space of their names, the simple and complex types share the type
+// definitions.
+var lspDeclarationSpaces = map[string]string{
+	"simpleType":     "type",
+	"complexType":    "type",
+	"element":        KindElement,
+	"attribute":      KindAttribute,
+	"group":          KindGroup,
+	"attributeGroup": KindAttributeGroup,
+	"notation":       KindNotation,
+}
+
+// lspUnsupportedConstructs holds the schema components the code generation
+// ignores, which are reported by the diagnostics.
+var lspUnsupportedConstructs = map[string]bool{
+	"alternative":        true,
+	"any":                true,
+	"anyAttribute":       true,
+	"assertion":          true,
+	"defaultOpenContent": true,
+	"openContent":        true,
+}
+
+// lspMessage is a JSON-RPC request or notification of the client.
+type lspMessage struct {
+	ID     *json.RawMessage `json:"id"`
+	Method string           `json:"method"`
+	Params json.RawMessage  `json:"params"`
+}
+
+// lspResponse is a JSON-RPC response of the server, the result is null if
+// there is nothing to return.
+type lspResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+}
+
+// lspErrorResponse is a JSON-RPC error response of the server.
+type lspErrorResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Error   lspError         `json:"error"`
+}
+
+// lspError is the error of a JSON-RPC error response.
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// lspNotification is a JSON-RPC notification of the server.
+type lspNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// lspPosition is a zero-based position in a document, the character is
+// counted in UTF-16 code units.
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// lspRange is a range in a document.
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+// lspLocation is a range in the document of the URI.
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+// lspDiagnostic is a problem of a document.
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+// lspTextDocumentPositionParams holds the params of the hover and the
+// definition requests.
+type lspTextDocumentPositionParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Position lspPosition `json:"position"`
+}
+
+// lspSymbol is a name in a schema file, such as the name of a declaration or
+// the qualified name of a reference, with the offsets of the attribute value
+// holding it. Space holds the symbol space of the name.
+type lspSymbol struct {
+	space, name string
+	start, end  int
+}
+
+// lspSchema holds the names of a schema file with their offsets, which back
+// the positions of the language server.
+type lspSchema struct {
+	path            string
+	text            []byte
+	open            bool
+	targetNamespace string
+	prefixes        map[string]string
+	declarations    []lspSymbol
+	references      []lspSymbol
+	locations       []lspSymbol
+	unsupported     []lspSymbol
+	syntaxError     *lspSymbol
+}
+
+// lspServer holds the state of the language server, the documents opened by
+// the client are kept by their path.
+type lspServer struct {
+	out       io.Writer
+	documents map[string][]byte
+	shutdown  bool
+}
+
+// ServeLSP serves the language server protocol for editing the XML schema
+// files, reading the messages of the client from the reader and writing the
+// messages of the server to the writer until the exit notification. The
+// server provides the hover of the resolved type and facets of the
+// declarations, the definition of the references across the imported and
+// included schemas, and the diagnostics of the dangling references and the
+// constructs the code generation doesn't support.
+func ServeLSP(in io.Reader, out io.Writer) error {
+	s := &lspServer{out: out, documents: map[string][]byte{}}
+	reader := bufio.NewReader(in)
+	for {
+		content, err := readLSPMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var msg lspMessage
+		if err = json.Unmarshal(content, &msg); err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		if err = s.handle(&msg); err != nil {
+			return err
+		}
+	}
+}
+
+// readLSPMessage reads the content of a message framed by the Content-Length
+// header.
+func readLSPMessage(reader *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if i := strings.Index(line, ":"); i > 0 && strings.EqualFold(line[:i], "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(line[i+1:])); err != nil {
+				return nil, fmt.Errorf("invalid content length %s", line[i+1:])
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing content length")
+	}
+	content := make([]byte, length)
+	_, err := io.ReadFull(reader, content)
+	return content, err
+}
+
+// write writes the message framed by the Content-Length header.
+func (s *lspServer) write(msg interface{}) error {
+	content, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(content), content)
+	return err
+}
+
+// handle handles the message of the client, the requests are answered with
+// a response.
+func (s *lspServer) handle(msg *lspMessage) error {
+	var result interface{}
+	switch msg.Method {
+	case "initialize":
+		result = map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":   lspTextDocumentSyncFull,
+				"hoverProvider":      true,
+				"definitionProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "xgen", "version": Version},
+		}
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen", "textDocument/didChange", "textDocument/didSave", "textDocument/didClose":
+		return s.didChange(msg.Method, msg.Params)
+	case "textDocument/hover", "textDocument/definition":
+		var params lspTextDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return err
+		}
+		if msg.Method == "textDocument/hover" {
+			result = s.hover(&params)
+		} else {
+			result = s.definition(&params)
+		}
+	default:
+		if msg.ID == nil {
+			return nil
+		}
+		return s.write(lspErrorResponse{JSONRPC: "2.0", ID: msg.ID, Error: lspError{Code: lspMethodNotFound, Message: fmt.Sprintf("unsupported method %s", msg.Method)}})
+	}
+	if msg.ID == nil {
+		return nil
+	}
+	return s.write(lspResponse{JSONRPC: "2.0", ID: msg.ID, Result: result})
+}
+
+// didChange keeps the content of the opened document up to date, and
+// publishes the diagnostics of the document.
+func (s *lspServer) didChange(method string, data json.RawMessage) error {
+	var params struct {
+		TextDocument struct {
+			URI  string  `json:"uri"`
+			Text *string `json:"text"`
+		} `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+	}
+	if err := json.Unmarshal(data, &params); err != nil {
+		return err
+	}
+	uri := params.TextDocument.URI
+	path := lspURIToPath(uri)
+	diagnostics := []lspDiagnostic{}
+	switch method {
+	case "textDocument/didOpen":
+		if params.TextDocument.Text != nil {
+			s.documents[path] = []byte(*params.TextDocument.Text)
+		}
+	case "textDocument/didChange":
+		if l := len(params.ContentChanges); l > 0 {
+			s.documents[path] = []byte(params.ContentChanges[l-1].Text)
+		}
+	case "textDocument/didClose":
+		delete(s.documents, path)
+		return s.write(lspNotification{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: map[string]interface{}{"uri": uri, "diagnostics": diagnostics}})
+	}
+	if schemas := s.loadSchemas(path); len(schemas) > 0 {
+		diagnostics = s.diagnose(schemas)
+	}
+	return s.write(lspNotification{JSONRPC: "2.0", Method: "textDocument/publishDiagnostics", Params: map[string]interface{}{"uri": uri, "diagnostics": diagnostics}})
+}
+
+// hover returns the hover of the declaration named or referenced at the
+// position, with the resolved type and the facets of the declaration.
+func (s *lspServer) hover(params *lspTextDocumentPositionParams) interface{} {
+	schemas := s.loadSchemas(lspURIToPath(params.TextDocument.URI))
+	if len(schemas) == 0 {
+		return nil
+	}
+	offset := schemas[0].offset(params.Position)
+	var target *lspSchema
+	var decl *lspSymbol
+	for i := range schemas[0].declarations {
+		if d := &schemas[0].declarations[i]; d.start <= offset && offset <= d.end {
+			target, decl = schemas[0], d
+		}
+	}
+	for i := range schemas[0].references {
+		ref := &schemas[0].references[i]
+		if ref.start > offset || offset > ref.end {
+			continue
+		}
+		namespace, local, ok := schemas[0].resolveQName(ref.name)
+		if ok && (namespace == xsdNamespace || strings.HasPrefix(ref.name, "xml:")) {
+			return lspMarkdown(fmt.Sprintf("built-in type `%s`", ref.name), schemas[0].rangeOf(ref))
+		}
+		if target, decl = findLSPDeclaration(schemas, namespace, local, ref.space); decl == nil {
+			return nil
+		}
+		return lspMarkdown(s.describe(target, decl), schemas[0].rangeOf(ref))
+	}
+	if decl == nil {
+		return nil
+	}
+	return lspMarkdown(s.describe(target, decl), target.rangeOf(decl))
+}
+
+// definition returns the location of the declaration referenced at the
+// position, or the location of the schema file referenced by the schema
+// location of the import or the include at the position.
+func (s *lspServer) definition(params *lspTextDocumentPositionParams) interface{} {
+	schemas := s.loadSchemas(lspURIToPath(params.TextDocument.URI))
+	if len(schemas) == 0 {
+		return nil
+	}
+	offset := schemas[0].offset(params.Position)
+	for i := range schemas[0].references {
+		ref := &schemas[0].references[i]
+		if ref.start > offset || offset > ref.end {
+			continue
+		}
+		namespace, local, _ := schemas[0].resolveQName(ref.name)
+		target, decl := findLSPDeclaration(schemas, namespace, local, ref.space)
+		if decl == nil {
+			return nil
+		}
+		return lspLocation{URI: lspPathToURI(target.path), Range: target.rangeOf(decl)}
+	}
+	for _, location := range schemas[0].locations {
+		if location.start <= offset && offset <= location.end {
+			if path := schemas[0].resolveLocation(location.name); path != "" {
+				return lspLocation{URI: lspPathToURI(path)}
+			}
+		}
+	}
+	return nil
+}
+
+// describe returns the Markdown description of the declaration by the parsed
+// schema, with the resolved type, the facets and the members of the
+// declaration.
+func (s *lspServer) describe(schema *lspSchema, decl *lspSymbol) string {
+	title := fmt.Sprintf("**%s** `%s`", decl.space, decl.name)
+	opt := &Options{
+		FilePath:            schema.path,
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+		RemoteSchema:        make(map[string][]byte),
+		Source:              s.documents[schema.path],
+	}
+	dump, err := opt.Dump()
+	if err != nil {
+		return title
+	}
+	for _, d := range dump.Declarations {
+		if d.Name != decl.name || lspDeclarationSpaces[d.Kind] != decl.space {
+			continue
+		}
+		lines := []string{fmt.Sprintf("**%s** `%s`", d.Kind, d.Name)}
+		if d.Doc != "" {
+			lines = append(lines, d.Doc)
+		}
+		if d.Base != "" {
+			lines = append(lines, fmt.Sprintf("base: `%s`", d.Base))
+		}
+		if d.Type != "" {
+			lines = append(lines, fmt.Sprintf("type: `%s`", d.Type))
+		}
+		if facets := formatLSPFacets(d.Facets); facets != "" {
+			lines = append(lines, "facets: "+facets)
+		}
+		var members []string
+		for _, member := range append(append(append([]Declaration{}, d.Elements...), d.Attributes...), append(d.Groups, d.AttributeGroups...)...) {
+			item := fmt.Sprintf("- %s `%s`", member.Kind, member.Name)
+			if member.Type != "" {
+				item += fmt.Sprintf(": `%s`", member.Type)
+			}
+			if facets := formatLSPFacets(member.Facets); facets != "" {
+				item += " " + facets
+			}
+			members = append(members, item)
+		}
+		if len(members) > 0 {
+			lines = append(lines, strings.Join(members, "\n"))
+		}
+		return strings.Join(lines, "\n\n")
+	}
+	return title
+}
+
+// diagnose returns the diagnostics of the first schema: the syntax error,
+// the constructs the code generation doesn't support, the schema locations
+// which can't be found, the undeclared prefixes and the dangling references.
+func (s *lspServer) diagnose(schemas []*lspSchema) []lspDiagnostic {
+	schema := schemas[0]
+	diagnostics := []lspDiagnostic{}
+	add := func(symbol *lspSymbol, severity int, message string) {
+		diagnostics = append(diagnostics, lspDiagnostic{Range: schema.rangeOf(symbol), Severity: severity, Source: "xgen", Message: message})
+	}
+	if schema.syntaxError != nil {
+		add(schema.syntaxError, lspSeverityError, schema.syntaxError.name)
+	}
+	for i := range schema.unsupported {
+		add(&schema.unsupported[i], lspSeverityWarning, fmt.Sprintf("%s isn't supported by the code generation and is ignored", schema.unsupported[i].name))
+	}
+	for i := range schema.locations {
+		location := &schema.locations[i]
+		if !isValidURL(location.name) && schema.resolveLocation(location.name) == "" {
+			add(location, lspSeverityError, fmt.Sprintf("can't find the schema %s", location.name))
+		}
+	}
+	namespaces := map[string]bool{}
+	for _, schema := range schemas {
+		namespaces[schema.targetNamespace] = true
+	}
+	for i := range schema.references {
+		ref := &schema.references[i]
+		namespace, local, ok := schema.resolveQName(ref.name)
+		switch {
+		case !ok:
+			add(ref, lspSeverityError, fmt.Sprintf("undeclared namespace prefix of %s", ref.name))
+		case strings.HasPrefix(ref.name, "xml:"):
+		case namespace == xsdNamespace:
+			if _, ok := BuildInTypes[local]; !ok || ref.space != "type" {
+				add(ref, lspSeverityError, fmt.Sprintf("unknown built-in %s %s", ref.space, ref.name))
+			}
+		case namespaces[namespace]:
+			if _, decl := findLSPDeclaration(schemas, namespace, local, ref.space); decl == nil {
+				add(ref, lspSeverityError, fmt.Sprintf("dangling reference to %s %s", ref.space, ref.name))
+			}
+		}
+	}
+	return diagnostics
+}
+
+// loadSchemas indexes the schema file of the path, and the schema files
+// imported and included by it recursively. The first schema is the one of
+// the path, the included schemas without target namespace take the target
+// namespace of the including schema.
+func (s *lspServer) loadSchemas(path string) []*lspSchema {
+	var schemas []*lspSchema
+	visited := map[string]bool{}
+	var load func(path, namespace string)
+	load = func(path, namespace string) {
+		if visited[path] {
+			return
+		}
+		visited[path] = true
+		text, open := s.documents[path]
+		if !open {
+			var err error
+			if text, err = ioutil.ReadFile(path); err != nil {
+				return
+			}
+		}
+		schema := indexLSPSchema(path, text)
+		schema.open = open
+		if schema.targetNamespace == "" {
+			schema.targetNamespace = namespace
+		}
+		schemas = append(schemas, schema)
+		for _, location := range schema.locations {
+			if dep := schema.resolveLocation(location.name); dep != "" {
+				namespace := ""
+				if location.space == "include" {
+					namespace = schema.targetNamespace
+				}
+				load(dep, namespace)
+			}
+		}
+	}
+	load(path, "")
+	return schemas
+}
+
+// indexLSPSchema indexes the names of the schema file with their offsets.
+func indexLSPSchema(path string, text []byte) *lspSchema {
+	schema := &lspSchema{path: path, text: text, prefixes: map[string]string{}}
+	decoder := xml.NewDecoder(bytes.NewReader(text))
+	var depth int
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.RawToken()
+		if err != nil {
+			if err != io.EOF {
+				schema.syntaxError = &lspSymbol{name: err.Error(), start: offset, end: offset}
+			}
+			break
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			depth++
+			raw := text[offset:decoder.InputOffset()]
+			if depth == 1 {
+				for _, attr := range element.Attr {
+					if attr.Name.Space == "xmlns" {
+						schema.prefixes[attr.Name.Local] = attr.Value
+					}
+					if attr.Name.Space == "" && attr.Name.Local == "xmlns" {
+						schema.prefixes[""] = attr.Value
+					}
+					if attr.Name.Space == "" && attr.Name.Local == "targetNamespace" {
+						schema.targetNamespace = attr.Value
+					}
+				}
+			}
+			if schema.prefixes[element.Name.Space] != xsdNamespace {
+				continue
+			}
+			schema.indexElement(element, raw, offset, depth)
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return schema
+}
+
+// indexElement indexes the names of the attributes of the schema component
+// by given raw start element at the offset and the depth.
+func (schema *lspSchema) indexElement(element xml.StartElement, raw []byte, offset, depth int) {
+	local := element.Name.Local
+	if lspUnsupportedConstructs[local] {
+		name := element.Name.Space + ":" + local
+		if element.Name.Space == "" {
+			name = local
+		}
+		start := offset + bytes.Index(raw, []byte(name))
+		schema.unsupported = append(schema.unsupported, lspSymbol{name: name, start: start, end: start + len(name)})
+	}
+	for _, attr := range element.Attr {
+		if attr.Name.Space != "" {
+			continue
+		}
+		start, end, ok := findLSPAttrValue(raw, attr.Name.Local)
+		if !ok {
+			continue
+		}
+		start, end = start+offset, end+offset
+		switch attr.Name.Local {
+		case "name":
+			if space, ok := lspDeclarationSpaces[local]; ok && depth == 2 {
+				schema.declarations = append(schema.declarations, lspSymbol{space: space, name: attr.Value, start: start, end: end})
+			}
+		case "type", "base", "itemType":
+			schema.references = append(schema.references, lspSymbol{space: "type", name: attr.Value, start: start, end: end})
+		case "substitutionGroup":
+			schema.references = append(schema.references, lspSymbol{space: KindElement, name: attr.Value, start: start, end: end})
+		case "ref":
+			if space, ok := lspDeclarationSpaces[local]; ok {
+				schema.references = append(schema.references, lspSymbol{space: space, name: attr.Value, start: start, end: end})
+			}
+		case "memberTypes":
+			value := string(schema.text[start:end])
+			for _, member := range strings.Fields(value) {
+				i := strings.Index(value, member)
+				schema.references = append(schema.references, lspSymbol{space: "type", name: member, start: start + i, end: start + i + len(member)})
+				value = value[:i] + strings.Repeat(" ", len(member)) + value[i+len(member):]
+			}
+		case "schemaLocation":
+			if local == "import" || local == "include" || local == "redefine" || local == "override" {
+				schema.locations = append(schema.locations, lspSymbol{space: local, name: attr.Value, start: start, end: end})
+			}
+		}
+	}
+}
+
+// findLSPAttrValue returns the offsets of the value of the attribute by given
+// name in the raw start element.
+func findLSPAttrValue(raw []byte, name string) (start, end int, ok bool) {
+	loc := regexp.MustCompile(`\s` + regexp.QuoteMeta(name) + `\s*=\s*(?:"([^"]*)"|'([^']*)')`).FindSubmatchIndex(raw)
+	if loc == nil {
+		return
+	}
+	if loc[2] >= 0 {
+		return loc[2], loc[3], true
+	}
+	return loc[4], loc[5], true
+}
+
+// findLSPDeclaration returns the schema and the declaration by given
+// namespace, local name and symbol space.
+func findLSPDeclaration(schemas []*lspSchema, namespace, local, space string) (*lspSchema, *lspSymbol) {
+	for _, schema := range schemas {
+		if schema.targetNamespace != namespace {
+			continue
+		}
+		for i := range schema.declarations {
+			if d := &schema.declarations[i]; d.name == local && d.space == space {
+				return schema, d
+			}
+		}
+	}
+	return nil, nil
+}
+
+// resolveQName returns the namespace and the local name of the qualified
+// name, ok is false if the prefix isn't declared.
+func (schema *lspSchema) resolveQName(qname string) (namespace, local string, ok bool) {
+	prefix, local := "", qname
+	if i := strings.Index(qname, ":"); i >= 0 {
+		prefix, local = qname[:i], qname[i+1:]
+	}
+	if prefix == "xml" {
+		return "http://www.w3.org/XML/1998/namespace", local, true
+	}
+	namespace, ok = schema.prefixes[prefix]
+	return namespace, local, ok || prefix == ""
+}
+
+// resolveLocation returns the path of the local schema file by given schema
+// location, or the empty string if the file can't be found.
+func (schema *lspSchema) resolveLocation(location string) string {
+	if location == "" || isValidURL(location) {
+		return ""
+	}
+	path := filepath.Join(filepath.Dir(schema.path), filepath.FromSlash(location))
+	if _, err := ioutil.ReadFile(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// offset returns the offset of the position in the text of the schema.
+func (schema *lspSchema) offset(position lspPosition) int {
+	var line, offset int
+	for line < position.Line && offset < len(schema.text) {
+		if i := bytes.IndexByte(schema.text[offset:], '\n'); i >= 0 {
+			offset += i + 1
+			line++
+			continue
+		}
+		offset = len(schema.text)
+	}
+	for character := 0; character < position.Character && offset < len(schema.text) && schema.text[offset] != '\n'; {
+		r, size := utf8.DecodeRune(schema.text[offset:])
+		character += len(utf16.Encode([]rune{r}))
+		offset += size
+	}
+	return offset
+}
+
+// position returns the position of the offset in the text of the schema.
+func (schema *lspSchema) position(offset int) lspPosition {
+	var position lspPosition
+	text := schema.text[:offset]
+	if i := bytes.LastIndexByte(text, '\n'); i >= 0 {
+		position.Line = bytes.Count(text, []byte("\n"))
+		text = text[i+1:]
+	}
+	position.Character = len(utf16.Encode(bytes.Runes(text)))
+	return position
+}
+
+// rangeOf returns the range of the symbol in the text of the schema.
+func (schema *lspSchema) rangeOf(symbol *lspSymbol) lspRange {
+	return lspRange{Start: schema.position(symbol.start), End: schema.position(symbol.end)}
+}
+
+// lspMarkdown returns the hover of the Markdown content in the range.
+func lspMarkdown(content string, r lspRange) interface{} {
+	return map[string]interface{}{
+		"contents": map[string]string{"kind": "markdown", "value": content},
+		"range":    r,
+	}
+}
+
+// formatLSPFacets returns the facets declared in the facets as the list of
+// the name and the value of each facet.
+func formatLSPFacets(facets *Facets) string {
+	if facets == nil {
+		return ""
+	}
+	var items []string
+	v, t := reflect.ValueOf(facets).Elem(), reflect.TypeOf(facets).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.IsZero() {
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			field = field.Elem()
+		}
+		value := fmt.Sprint(field.Interface())
+		if field.Kind() == reflect.Slice {
+			value = strings.Join(field.Interface().([]string), ", ")
+		}
+		items = append(items, fmt.Sprintf("%s `%s`", strings.Split(t.Field(i).Tag.Get("json"), ",")[0], value))
+	}
+	return strings.Join(items, ", ")
+}
+
+// lspURIToPath returns the path of the file URI.
+func lspURIToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// lspPathToURI returns the file URI of the path.
+func lspPathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
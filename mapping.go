@@ -0,0 +1,384 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// mappingField is a field of the struct generated for a declaration, which
+// is matched by its key between the versions of the schema.
+type mappingField struct {
+	Key      string
+	Name     string
+	Type     string
+	Plural   bool
+	Optional bool
+}
+
+// mappingType is a declaration generated as a struct in both versions of the
+// schema, with the fields of the struct in each version.
+type mappingType struct {
+	Name      string
+	OldFields []mappingField
+	NewFields []mappingField
+}
+
+// GenerateMapping parses the old and the new version of an XML schema, and
+// returns the best-effort mapping code between the complex types, groups and
+// attribute groups declared in both versions, in the language of the options
+// of the new version. Go and Rust are supported.
+//
+// Fields with the same name and type are mapped directly, and fields of the
+// shared types are mapped by their own mapping code. A TODO marker is left
+// for the other fields. The code belongs to the package, or the module, of
+// the new version, and refers to the old version by the oldRef, which is the
+// import path of the old Go package or the path of the old Rust module.
+func GenerateMapping(oldOpt, newOpt *Options, oldRef string) ([]byte, error) {
+	if oldOpt.Lang != newOpt.Lang {
+		return nil, fmt.Errorf("mapping between %s and %s code is not supported", oldOpt.Lang, newOpt.Lang)
+	}
+	for _, opt := range []*Options{oldOpt, newOpt} {
+		opt.Extract = true
+		if err := opt.Parse(); err != nil {
+			return nil, err
+		}
+	}
+	label := "the old version"
+	if message, version, ok := getNamespaceVersion(oldOpt.TargetNamespace); ok {
+		label = message + "." + version
+	}
+	builtInTypes := map[string]bool{}
+	for _, opt := range []*Options{oldOpt, newOpt} {
+		for name := range opt.builtInTypes {
+			builtInTypes[name] = true
+		}
+	}
+	switch newOpt.Lang {
+	case "Go":
+		packageName := newOpt.Package
+		if packageName == "" {
+			packageName = getVersionPackage(newOpt.TargetNamespace)
+		}
+		if packageName == "" {
+			packageName = "schema"
+		}
+		alias := getVersionPackage(oldOpt.TargetNamespace)
+		if alias == "" {
+			alias = "old"
+		}
+		return genGoMapping(&CodeGenerator{Lang: "Go", builtInTypes: builtInTypes}, packageName, alias, oldRef, label, oldOpt.ProtoTree, newOpt.ProtoTree)
+	case "Rust":
+		return genRustMapping(&CodeGenerator{Lang: "Rust", builtInTypes: builtInTypes}, oldRef, label, oldOpt.ProtoTree, newOpt.ProtoTree), nil
+	}
+	return nil, fmt.Errorf("mapping code for %s is not supported", newOpt.Lang)
+}
+
+// getMappingTypes returns the declarations generated as a struct in both
+// versions of the schema in the order of the new version, with the fields
+// returned by given function.
+func getMappingTypes(oldTree, newTree []interface{}, fields func(ele interface{}, XSDSchema []interface{}) []mappingField) (types []mappingType) {
+	declarations := map[string]interface{}{}
+	for _, ele := range oldTree {
+		if name := getMappingTypeName(ele); name != "" {
+			if _, ok := declarations[name]; !ok {
+				declarations[name] = ele
+			}
+		}
+	}
+	seen := map[string]bool{}
+	for _, ele := range newTree {
+		name := getMappingTypeName(ele)
+		old, ok := declarations[name]
+		if name == "" || !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		types = append(types, mappingType{Name: name, OldFields: fields(old, oldTree), NewFields: fields(ele, newTree)})
+	}
+	return
+}
+
+// getMappingTypeName returns the name of the declaration generated as a
+// struct, or an empty string for the other declarations.
+func getMappingTypeName(ele interface{}) string {
+	switch v := ele.(type) {
+	case *ComplexType:
+		return v.Name
+	case *Group:
+		return v.Name
+	case *AttributeGroup:
+		return v.Name
+	}
+	return ""
+}
+
+// findMappingField returns the field by given key.
+func findMappingField(fields []mappingField, key string) (mappingField, bool) {
+	for _, field := range fields {
+		if field.Key == key {
+			return field, true
+		}
+	}
+	return mappingField{}, false
+}
+
+// getGoMappingFields returns the fields of the Go struct generated for the
+// declaration.
+func (gen *CodeGenerator) getGoMappingFields(ele interface{}, XSDSchema []interface{}) (fields []mappingField) {
+	elementFields := func(elements []Element) {
+		for _, element := range elements {
+			fields = append(fields, mappingField{Key: "element " + element.Name, Name: genGoFieldName(gen.genPluralName(element.Name, element.Plural), false), Type: getBasefromSimpleType(trimNSPrefix(element.Type), XSDSchema), Plural: element.Plural})
+		}
+	}
+	groupFields := func(groups []Group) {
+		for _, group := range groups {
+			fields = append(fields, mappingField{Key: "group " + group.Name, Name: genGoFieldName(gen.genPluralName(group.Name, group.Plural), false), Type: getBasefromSimpleType(trimNSPrefix(group.Ref), XSDSchema), Plural: group.Plural})
+		}
+	}
+	attributeFields := func(attributes []Attribute) {
+		for _, attribute := range attributes {
+			fields = append(fields, mappingField{Key: "attribute " + attribute.Name, Name: genGoFieldName(attribute.Name, false) + "Attr", Type: getBasefromSimpleType(trimNSPrefix(attribute.Type), XSDSchema)})
+		}
+	}
+	switch v := ele.(type) {
+	case *ComplexType:
+		for _, attrGroup := range v.AttributeGroup {
+			fields = append(fields, mappingField{Key: "attributeGroup " + attrGroup.Name, Name: genGoFieldName(attrGroup.Name, false), Type: getBasefromSimpleType(trimNSPrefix(attrGroup.Ref), XSDSchema)})
+		}
+		attributeFields(v.Attributes)
+		groupFields(v.Groups)
+		elementFields(v.Elements)
+		if len(v.Base) > 0 {
+			if gen.isGoBuiltInType(v.Base) {
+				fields = append(fields, mappingField{Key: "value", Name: "Value", Type: v.Base})
+			} else {
+				fields = append(fields, mappingField{Key: "base " + v.Base, Name: strings.TrimPrefix(gen.genGoFieldType(v.Base), "*"), Type: v.Base})
+			}
+		}
+	case *Group:
+		elementFields(v.Elements)
+		groupFields(v.Groups)
+	case *AttributeGroup:
+		attributeFields(v.Attributes)
+	}
+	return
+}
+
+// genGoMapping generate the mapping functions in both directions between the
+// Go structs shared by the versions, or returns nil if the versions don't
+// share any struct.
+func genGoMapping(gen *CodeGenerator, packageName, alias, importPath, label string, oldTree, newTree []interface{}) ([]byte, error) {
+	types := getMappingTypes(oldTree, newTree, gen.getGoMappingFields)
+	if len(types) == 0 {
+		return nil, nil
+	}
+	shared := map[string]bool{}
+	for _, t := range types {
+		shared[t.Name] = true
+	}
+	suffix := genGoFieldName(alias, false)
+	var code string
+	for _, t := range types {
+		typeName := genGoFieldName(t.Name, false)
+		oldType := alias + "." + typeName
+		code += fmt.Sprintf("\n// %sFrom%s converts the %s of %s into the %s of this version.\nfunc %sFrom%s(v *%s) *%s {\nif v == nil {\nreturn nil\n}\nout := &%s{}\n%sreturn out\n}\n",
+			typeName, suffix, typeName, label, typeName, typeName, suffix, oldType, typeName, typeName, gen.genGoMappingFields(t.OldFields, t.NewFields, "From"+suffix, label, shared))
+		code += fmt.Sprintf("\n// %sTo%s converts the %s of this version into the %s of %s.\nfunc %sTo%s(v *%s) *%s {\nif v == nil {\nreturn nil\n}\nout := &%s{}\n%sreturn out\n}\n",
+			typeName, suffix, typeName, typeName, label, typeName, suffix, typeName, oldType, oldType, gen.genGoMappingFields(t.NewFields, t.OldFields, "To"+suffix, "the new version", shared))
+	}
+	return format.Source([]byte(fmt.Sprintf("%s\n\npackage %s\n\nimport %s %q\n%s", gen.fileHeader(), packageName, alias, importPath, code)))
+}
+
+// genGoMappingFields generate the statements mapping the fields of the
+// source struct into the target struct for Go code. The fields of the shared
+// structs are converted by their mapping function with given suffix.
+func (gen *CodeGenerator) genGoMappingFields(from, to []mappingField, convert, label string, shared map[string]bool) (code string) {
+	for _, target := range to {
+		source, ok := findMappingField(from, target.Key)
+		fieldType := gen.genGoFieldType(target.Type)
+		switch {
+		case !ok:
+			code += fmt.Sprintf("// TODO: map the %s, which doesn't match a field in %s.\n", target.Name, label)
+		case source.Type != target.Type || source.Plural != target.Plural:
+			code += fmt.Sprintf("// TODO: map the %s, which has a different type in %s.\n", target.Name, label)
+		case gen.isGoBuiltInType(fieldType):
+			code += fmt.Sprintf("out.%s = v.%s\n", target.Name, source.Name)
+		case !shared[trimNSPrefix(target.Type)]:
+			code += fmt.Sprintf("// TODO: map the %s, which isn't a struct of both versions.\n", target.Name)
+		case target.Plural:
+			code += fmt.Sprintf("for _, item := range v.%s {\nout.%s = append(out.%s, %s%s(item))\n}\n", source.Name, target.Name, target.Name, strings.TrimPrefix(fieldType, "*"), convert)
+		default:
+			code += fmt.Sprintf("out.%s = %s%s(v.%s)\n", target.Name, strings.TrimPrefix(fieldType, "*"), convert, source.Name)
+		}
+	}
+	for _, source := range from {
+		if _, ok := findMappingField(to, source.Key); !ok {
+			code += fmt.Sprintf("// TODO: the %s isn't mapped, there is no such field in the target.\n", source.Name)
+		}
+	}
+	return
+}
+
+// getRustMappingFields returns the fields of the Rust struct generated for
+// the declaration.
+func (gen *CodeGenerator) getRustMappingFields(ele interface{}, XSDSchema []interface{}) (fields []mappingField) {
+	field := func(kind, name, typeName string, plural, optional bool) {
+		fieldName := genRustFieldName(name)
+		if kind == "element" || kind == "group" {
+			fieldName = genRustFieldName(gen.genRustPluralName(name, plural))
+		}
+		fields = append(fields, mappingField{Key: kind + " " + name, Name: fieldName, Type: getBasefromSimpleType(trimNSPrefix(typeName), XSDSchema), Plural: plural, Optional: optional})
+	}
+	switch v := ele.(type) {
+	case *ComplexType:
+		for _, attrGroup := range v.AttributeGroup {
+			field("attributeGroup", attrGroup.Name, attrGroup.Ref, false, false)
+		}
+		for _, attribute := range v.Attributes {
+			field("attribute", attribute.Name, attribute.Type, attribute.Plural, attribute.Optional)
+		}
+		for _, group := range v.Groups {
+			field("group", group.Name, group.Ref, group.Plural, false)
+		}
+		for _, element := range v.Elements {
+			field("element", element.Name, element.Type, element.Plural, element.Optional)
+		}
+		if len(v.Base) > 0 {
+			fieldType := getBasefromSimpleType(trimNSPrefix(v.Base), XSDSchema)
+			if gen.isRustBuiltInType(v.Base) {
+				fields = append(fields, mappingField{Key: "value", Name: genRustFieldName("value"), Type: fieldType})
+			} else {
+				fields = append(fields, mappingField{Key: "base " + v.Base, Name: genRustFieldName(fieldType), Type: fieldType})
+			}
+		}
+	case *Group:
+		for _, element := range v.Elements {
+			field("element", element.Name, element.Type, element.Plural, element.Optional)
+		}
+		for _, group := range v.Groups {
+			field("group", group.Name, group.Ref, group.Plural, false)
+		}
+	case *AttributeGroup:
+		for _, attribute := range v.Attributes {
+			field("attribute", attribute.Name, attribute.Type, attribute.Plural, attribute.Optional)
+		}
+	}
+	return
+}
+
+// getRustAliasTypes returns the names of the anonymous complex types which
+// are generated as an alias of a structurally identical struct in Rust code.
+func getRustAliasTypes(XSDSchema []interface{}) map[string]bool {
+	gen := &CodeGenerator{ProtoTree: XSDSchema}
+	aliases := map[string]bool{}
+	for _, ele := range XSDSchema {
+		if v, ok := ele.(*ComplexType); ok && gen.getSharedAnonymousType(v, v.Name) != "" {
+			aliases[v.Name] = true
+		}
+	}
+	return aliases
+}
+
+// genRustMapping generate the From implementations in both directions
+// between the Rust structs shared by the versions, or returns nil if the
+// versions don't share any struct. The code should be declared as a child
+// module of the new version.
+func genRustMapping(gen *CodeGenerator, oldRef, label string, oldTree, newTree []interface{}) []byte {
+	types := getMappingTypes(oldTree, newTree, gen.getRustMappingFields)
+	if len(types) == 0 {
+		return nil
+	}
+	shared := map[string]bool{}
+	for _, t := range types {
+		shared[t.Name] = true
+	}
+	oldAliases, newAliases := getRustAliasTypes(oldTree), getRustAliasTypes(newTree)
+	var code string
+	for _, t := range types {
+		// The aliases share the implementations of the aliased structs.
+		if oldAliases[t.Name] || newAliases[t.Name] {
+			continue
+		}
+		structName := gen.genRustFieldType(t.Name)
+		code += gen.genRustMappingImpl("old::"+structName, structName, t.OldFields, t.NewFields, label, shared)
+		code += gen.genRustMappingImpl(structName, "old::"+structName, t.NewFields, t.OldFields, "the new version", shared)
+	}
+	return []byte(fmt.Sprintf("%s\n\nuse super::*;\nuse %s as old;\n%s", gen.fileHeader(), oldRef, code))
+}
+
+// genRustMappingImpl generate the From implementation mapping the fields of
+// the source struct into the target struct for Rust code. The fields of the
+// shared structs are converted by their own From implementations.
+func (gen *CodeGenerator) genRustMappingImpl(from, to string, fromFields, toFields []mappingField, label string, shared map[string]bool) string {
+	var fields, todo string
+	var missing bool
+	receiver := "_v"
+	for _, target := range toFields {
+		source, ok := findMappingField(fromFields, target.Key)
+		fieldType := gen.genRustFieldType(target.Type)
+		var value string
+		switch {
+		case !ok:
+			todo += fmt.Sprintf("\t\t\t// TODO: map the %s, which doesn't match a field in %s.\n", target.Name, label)
+			missing = true
+			continue
+		case source.Type != target.Type || source.Plural != target.Plural:
+			todo += fmt.Sprintf("\t\t\t// TODO: map the %s, which has a different type in %s.\n", target.Name, label)
+			missing = true
+			continue
+		case gen.isRustBuiltInType(fieldType):
+			value = "v." + source.Name
+			if source.Optional && !target.Optional {
+				value += ".unwrap_or_default()"
+			}
+			if !source.Optional && target.Optional {
+				value = fmt.Sprintf("Some(%s)", value)
+			}
+		case !shared[target.Type]:
+			todo += fmt.Sprintf("\t\t\t// TODO: map the %s, which isn't a struct of both versions.\n", target.Name)
+			missing = true
+			continue
+		case target.Plural:
+			value = fmt.Sprintf("v.%s.into_iter().map(Into::into).collect()", source.Name)
+			if source.Optional {
+				value = fmt.Sprintf("v.%s.map(|items| items.into_iter().map(Into::into).collect())", source.Name)
+				if !target.Optional {
+					value += ".unwrap_or_default()"
+				}
+			} else if target.Optional {
+				value = fmt.Sprintf("Some(%s)", value)
+			}
+		default:
+			value = fmt.Sprintf("v.%s.into()", source.Name)
+			if source.Optional {
+				value = fmt.Sprintf("v.%s.map(Into::into)", source.Name)
+				if !target.Optional {
+					value += ".unwrap_or_default()"
+				}
+			} else if target.Optional {
+				value = fmt.Sprintf("Some(%s)", value)
+			}
+		}
+		receiver = "v"
+		fields += fmt.Sprintf("\t\t\t%s: %s,\n", target.Name, value)
+	}
+	for _, source := range fromFields {
+		if _, ok := findMappingField(toFields, source.Key); !ok {
+			todo += fmt.Sprintf("\t\t\t// TODO: the %s isn't mapped, there is no such field in the target.\n", source.Name)
+		}
+	}
+	fields += todo
+	if missing {
+		fields += "\t\t\t..Default::default()\n"
+	}
+	return fmt.Sprintf("\nimpl From<%s> for %s {\n\tfn from(%s: %s) -> Self {\n\t\tSelf {\n%s\t\t}\n\t}\n}\n", from, to, receiver, from, fields)
+}
@@ -0,0 +1,275 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// mergeSymbolSpaces maps the top-level schema components to the symbol space
+// of their names, the simple and complex types share the type definitions.
+var mergeSymbolSpaces = map[string]string{
+	"simpleType":     "type",
+	"complexType":    "type",
+	"element":        "element",
+	"attribute":      "attribute",
+	"group":          "group",
+	"attributeGroup": "attributeGroup",
+	"notation":       "notation",
+}
+
+var schemaLocationRegexp = regexp.MustCompile(`schemaLocation\s*=\s*("[^"]*"|'[^']*')`)
+
+// schemaMerger holds the state of MergeSchemas.
+type schemaMerger struct {
+	outputDir       string
+	visited         map[string]bool
+	rootName        string
+	targetNamespace string
+	attrs           []xml.Attr
+	namespaces      map[string]string
+	headers         []string
+	headerSeen      map[string]bool
+	decls           []string
+	declFiles       map[string]string
+	declContent     map[string]string
+}
+
+// MergeSchemas combines the XML schema files sharing a target namespace into
+// a single schema. Local includes are inlined, the schema locations of the
+// imports are rewritten relative to the output directory, and duplicate
+// declarations are kept once. Declarations with the same name and different
+// content, or schemas with different target namespaces, are reported as
+// errors.
+func MergeSchemas(files []string, outputDir string) ([]byte, error) {
+	m := &schemaMerger{
+		outputDir:   outputDir,
+		visited:     map[string]bool{},
+		namespaces:  map[string]string{},
+		headerSeen:  map[string]bool{},
+		declFiles:   map[string]string{},
+		declContent: map[string]string{},
+	}
+	for i, file := range files {
+		if err := m.mergeFile(file, i == 0); err != nil {
+			return nil, err
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString("<" + m.rootName)
+	for _, attr := range m.attrs {
+		name := attr.Name.Local
+		if attr.Name.Space != "" {
+			name = attr.Name.Space + ":" + name
+		}
+		buf.WriteString(" " + name + `="`)
+		xml.EscapeText(&buf, []byte(attr.Value))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">\n")
+	for _, raw := range append(m.headers, m.decls...) {
+		buf.WriteString("  " + raw + "\n")
+	}
+	buf.WriteString("</" + m.rootName + ">\n")
+	return buf.Bytes(), nil
+}
+
+// mergeFile merges the top-level components of the schema file, and the
+// schema files included by it.
+func (m *schemaMerger) mergeFile(file string, first bool) error {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	if m.visited[abs] {
+		return nil
+	}
+	m.visited[abs] = true
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var depth int
+	var start int64
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.RawToken()
+		if token == nil || err != nil {
+			break
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 1 {
+				if err = m.mergeRoot(file, element, first); err != nil {
+					return err
+				}
+			}
+			if depth == 2 {
+				start = offset
+			}
+		case xml.EndElement:
+			if depth == 2 {
+				if err = m.mergeComponent(file, data[start:decoder.InputOffset()]); err != nil {
+					return err
+				}
+			}
+			depth--
+		}
+	}
+	return nil
+}
+
+// mergeRoot merges the namespace declarations and the attributes of the
+// schema element, which must match the schemas merged before.
+func (m *schemaMerger) mergeRoot(file string, root xml.StartElement, first bool) error {
+	if first {
+		m.rootName = root.Name.Local
+		if root.Name.Space != "" {
+			m.rootName = root.Name.Space + ":" + root.Name.Local
+		}
+	}
+	var targetNamespace string
+	for _, attr := range root.Attr {
+		if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+			prefix := attr.Name.Local
+			if attr.Name.Space == "" {
+				prefix = ""
+			}
+			if namespace, ok := m.namespaces[prefix]; ok {
+				if namespace != attr.Value {
+					return fmt.Errorf("conflicting namespace prefix %q in %s: %s and %s", prefix, file, namespace, attr.Value)
+				}
+				continue
+			}
+			m.namespaces[prefix] = attr.Value
+			m.attrs = append(m.attrs, attr)
+			continue
+		}
+		if attr.Name.Local == "targetNamespace" {
+			targetNamespace = attr.Value
+		}
+		var declared bool
+		for _, merged := range m.attrs {
+			if merged.Name == attr.Name {
+				declared = true
+				if merged.Value != attr.Value {
+					return fmt.Errorf("conflicting schema attribute %s in %s: %s and %s", attr.Name.Local, file, merged.Value, attr.Value)
+				}
+			}
+		}
+		if !declared && first {
+			m.attrs = append(m.attrs, attr)
+		}
+	}
+	if first {
+		m.targetNamespace = targetNamespace
+		return nil
+	}
+	// Schemas without target namespace included by another schema take the
+	// target namespace of the including schema.
+	if targetNamespace != "" && targetNamespace != m.targetNamespace {
+		return fmt.Errorf("schema %s has target namespace %s, expected %s", file, targetNamespace, m.targetNamespace)
+	}
+	return nil
+}
+
+// mergeComponent merges the raw top-level component of the schema file.
+func (m *schemaMerger) mergeComponent(file string, raw []byte) error {
+	var component struct {
+		XMLName        xml.Name
+		Name           string `xml:"name,attr"`
+		SchemaLocation string `xml:"schemaLocation,attr"`
+	}
+	if err := xml.Unmarshal(raw, &component); err != nil {
+		return err
+	}
+	location := component.SchemaLocation
+	if location != "" && !isValidURL(location) && !filepath.IsAbs(location) {
+		location = filepath.Join(filepath.Dir(file), location)
+	}
+	switch component.XMLName.Local {
+	case "include":
+		if !isValidURL(location) {
+			return m.mergeFile(location, false)
+		}
+		fallthrough
+	case "import", "redefine", "override":
+		if location != "" && !isValidURL(location) {
+			if rel, err := filepath.Rel(m.outputDir, location); err == nil {
+				location = filepath.ToSlash(rel)
+			}
+			raw = schemaLocationRegexp.ReplaceAll(raw, []byte(`schemaLocation="`+location+`"`))
+		}
+		if !m.headerSeen[string(raw)] {
+			m.headerSeen[string(raw)] = true
+			m.headers = append(m.headers, string(raw))
+		}
+		return nil
+	}
+	content := canonicalComponent(raw)
+	space, ok := mergeSymbolSpaces[component.XMLName.Local]
+	if !ok {
+		// Annotations are kept once per content.
+		if _, ok = m.declContent[content]; !ok {
+			m.declContent[content] = content
+			m.decls = append(m.decls, string(raw))
+		}
+		return nil
+	}
+	key := space + " " + component.Name
+	if merged, ok := m.declContent[key]; ok {
+		if merged != content {
+			return fmt.Errorf("conflicting declarations of %s %s in %s and %s", component.XMLName.Local, component.Name, m.declFiles[key], file)
+		}
+		return nil
+	}
+	m.declContent[key], m.declFiles[key] = content, file
+	m.decls = append(m.decls, string(raw))
+	return nil
+}
+
+// canonicalComponent returns the content of the raw component for comparing
+// the declarations, which ignores the white space between the elements and
+// the order of the attributes.
+func canonicalComponent(raw []byte) string {
+	var content strings.Builder
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	for {
+		token, err := decoder.RawToken()
+		if token == nil || err != nil {
+			break
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			attrs := make([]string, 0, len(element.Attr))
+			for _, attr := range element.Attr {
+				attrs = append(attrs, fmt.Sprintf("%s:%s=%q", attr.Name.Space, attr.Name.Local, attr.Value))
+			}
+			sort.Strings(attrs)
+			fmt.Fprintf(&content, "<%s:%s %s>", element.Name.Space, element.Name.Local, strings.Join(attrs, " "))
+		case xml.EndElement:
+			fmt.Fprintf(&content, "</%s:%s>", element.Name.Space, element.Name.Local)
+		case xml.CharData:
+			if text := strings.TrimSpace(string(element)); text != "" {
+				content.WriteString(text)
+			}
+		}
+	}
+	return content.String()
+}
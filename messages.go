@@ -0,0 +1,427 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// commonModule is the module of the schemas which aren't messages in the
+// group-by-message mode, such as the imported data type schemas, shared by
+// the modules of the messages.
+const commonModule = "common"
+
+// The indexes of the modules in the group-by-message mode: the index of the
+// messages of the Go packages maps the target namespaces of the messages to
+// the import paths of their packages, the Rust modules and the TypeScript
+// barrels export the modules and the files of each module.
+var (
+	goMessageIndexRegexp   = regexp.MustCompile(`(?m)^\t"(.+)": "(.+)",$`)
+	rustModuleIndexRegexp  = regexp.MustCompile(`(?m)^pub mod (\w+);$`)
+	rustModuleFileRegexp   = regexp.MustCompile(`(?m)^#\[path = "(.+)"\]$`)
+	typeScriptExportRegexp = regexp.MustCompile(`(?m)^export (?:declare )?(?:abstract )?(?:interface|type|class|enum|const|function) (\w+)`)
+)
+
+// getMessageModule returns the module of the schema of the options in the
+// group-by-message mode, named by the version of its target namespace as the
+// versioned Go packages, such as pacs_008_001_08, or the common module if
+// the target namespace isn't versioned.
+func (opt *Options) getMessageModule() string {
+	if pkg := getVersionPackage(opt.TargetNamespace); pkg != "" {
+		return pkg
+	}
+	return commonModule
+}
+
+// checkGroupByMessage returns an error if the group-by-message mode of the
+// options isn't supported by the language. The Go packages of the messages
+// import the common package by the import path of the versioned packages.
+func (opt *Options) checkGroupByMessage() error {
+	if !opt.GroupByMessage {
+		return nil
+	}
+	switch opt.Lang {
+	case "Go":
+		if opt.VersionedPackages == "" {
+			return errors.New("the group-by-message mode of Go requires the import path of the versioned packages")
+		}
+	case "Rust":
+	case "TypeScript":
+		if opt.NPMPackage != "" {
+			return errors.New("the group-by-message mode can't be used with the npm package")
+		}
+	default:
+		return fmt.Errorf("the group-by-message mode isn't supported for %s", opt.Lang)
+	}
+	return nil
+}
+
+// genMessageModule imports the common module into the code of the message
+// generated by the generator, and adds the code to the indexes of its module
+// and of the modules of the output directory, which are merged with the
+// indexes written for the schemas generated before.
+func (opt *Options) genMessageModule(gen *CodeGenerator) error {
+	module := opt.getMessageModule()
+	switch opt.Lang {
+	case "Go":
+		if module == commonModule {
+			return nil
+		}
+		for _, file := range []string{gen.FileWithExtension(".go"), gen.FileWithExtension(".validator.go")} {
+			if err := opt.importGoCommonModule(gen, file); err != nil {
+				return err
+			}
+		}
+		return opt.genGoMessageIndex(gen, module)
+	case "Rust":
+		if module != commonModule {
+			if err := opt.importRustCommonModule(gen); err != nil {
+				return err
+			}
+		}
+		return opt.genRustModuleIndex(gen, module)
+	case "TypeScript":
+		if module != commonModule {
+			if err := opt.importTypeScriptCommonModule(gen); err != nil {
+				return err
+			}
+		}
+		return opt.genTypeScriptModuleIndex(gen, module)
+	}
+	return nil
+}
+
+// readOutput returns the content of the output file, or nil if it doesn't
+// exist.
+func (opt *Options) readOutput(name string) ([]byte, error) {
+	if opt.Artifacts != nil {
+		return opt.Artifacts[name], nil
+	}
+	data, err := ioutil.ReadFile(name)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// listOutputs returns the sorted paths of the output files in the directory
+// with the extension.
+func (opt *Options) listOutputs(dir, extension string) ([]string, error) {
+	var files []string
+	if opt.Artifacts != nil {
+		for name := range opt.Artifacts {
+			if filepath.Dir(name) == dir && strings.HasSuffix(name, extension) {
+				files = append(files, name)
+			}
+		}
+		sort.Strings(files)
+		return files, nil
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), extension) {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, err
+}
+
+// getGoDeclNames returns the names of the exported package-level
+// declarations of the Go files in the directory, except the given file.
+func (opt *Options) getGoDeclNames(dir, except string) (map[string]bool, error) {
+	files, err := opt.listOutputs(dir, ".go")
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, name := range files {
+		if name == except {
+			continue
+		}
+		data, err := opt.readOutput(name)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), name, data, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					names[decl.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						names[spec.Name.Name] = true
+					case *ast.ValueSpec:
+						for _, ident := range spec.Names {
+							names[ident.Name] = true
+						}
+					}
+				}
+			}
+		}
+	}
+	for name := range names {
+		if !ast.IsExported(name) {
+			delete(names, name)
+		}
+	}
+	return names, nil
+}
+
+// importGoCommonModule qualifies the references of the Go file of the
+// message to the declarations of the common package, which are the
+// identifiers the file doesn't resolve, and the other files of the package
+// don't declare, and imports the common package if there are any.
+func (opt *Options) importGoCommonModule(gen *CodeGenerator, name string) error {
+	source, err := opt.readOutput(name)
+	if source == nil || err != nil {
+		return err
+	}
+	names, err := opt.getGoDeclNames(filepath.Join(opt.OutputDir, commonModule), "")
+	if err != nil || len(names) == 0 {
+		return err
+	}
+	local, err := opt.getGoDeclNames(filepath.Dir(name), name)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, source, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	var qualified bool
+	for _, ident := range file.Unresolved {
+		if names[ident.Name] && !local[ident.Name] {
+			ident.Name, qualified = commonModule+"."+ident.Name, true
+		}
+	}
+	if !qualified {
+		return nil
+	}
+	var buf bytes.Buffer
+	if err = format.Node(&buf, fset, file); err != nil {
+		return err
+	}
+	importPath := fmt.Sprintf("%q", strings.TrimSuffix(opt.VersionedPackages, "/")+"/"+commonModule)
+	code := buf.String()
+	if strings.Contains(code, "\nimport (\n") {
+		code = strings.Replace(code, "\nimport (\n", "\nimport (\n\t"+importPath+"\n", 1)
+	} else {
+		code = strings.Replace(code, "\npackage "+file.Name.Name+"\n", "\npackage "+file.Name.Name+"\n\nimport "+importPath+"\n", 1)
+	}
+	data, err := format.Source([]byte(code))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(name, data)
+}
+
+// genGoMessageIndex adds the package of the message to the index of the
+// messages in the output directory, which maps the target namespaces of the
+// messages to the import paths of their packages.
+func (opt *Options) genGoMessageIndex(gen *CodeGenerator, module string) error {
+	name := filepath.Join(opt.OutputDir, "messages.go")
+	data, err := opt.readOutput(name)
+	if err != nil {
+		return err
+	}
+	messages := map[string]string{opt.TargetNamespace: strings.TrimSuffix(opt.VersionedPackages, "/") + "/" + module}
+	for _, match := range goMessageIndexRegexp.FindAllStringSubmatch(string(data), -1) {
+		if _, ok := messages[match[1]]; !ok {
+			messages[match[1]] = match[2]
+		}
+	}
+	var namespaces []string
+	for namespace := range messages {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	packageName := opt.Package
+	if packageName == "" {
+		packageName = "schema"
+	}
+	index := fmt.Sprintf("%s\n\npackage %s\n\n// Messages maps the target namespaces of the messages to the import paths of\n// their packages.\nvar Messages = map[string]string{\n", copyright, packageName)
+	for _, namespace := range namespaces {
+		index += fmt.Sprintf("\t%q: %q,\n", namespace, messages[namespace])
+	}
+	source, err := format.Source([]byte(index + "}\n"))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(name, source)
+}
+
+// importRustCommonModule imports the declarations of the common module into
+// the Rust module of the message, if the common module is generated.
+func (opt *Options) importRustCommonModule(gen *CodeGenerator) error {
+	files, err := opt.listOutputs(filepath.Join(opt.OutputDir, commonModule), ".rs")
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	name := gen.FileWithExtension(".rs")
+	source, err := opt.readOutput(name)
+	if source == nil || err != nil {
+		return err
+	}
+	// The file is a module of the module of the message.
+	code := strings.Replace(string(source), "\nuse serde::", "\n#[allow(unused_imports)]\nuse super::super::"+commonModule+"::*;\nuse serde::", 1)
+	return gen.WriteFile(name, []byte(code))
+}
+
+// genRustModuleName returns the name of the Rust module of the file, such
+// as pacs_008_001_08_xsd of the file pacs.008.001.08.xsd.rs.
+func genRustModuleName(file string) string {
+	name := strings.ToLower(regexp.MustCompile(`[^A-Za-z0-9_]+`).ReplaceAllString(strings.TrimSuffix(filepath.Base(file), ".rs"), "_"))
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// genRustModuleIndex adds the Rust file to the mod.rs of its module, which
+// reexports the files of the module, and the module to the mod.rs of the
+// output directory.
+func (opt *Options) genRustModuleIndex(gen *CodeGenerator, module string) error {
+	name := filepath.Join(opt.OutputDir, module, "mod.rs")
+	data, err := opt.readOutput(name)
+	if err != nil {
+		return err
+	}
+	files := map[string]bool{filepath.Base(gen.FileWithExtension(".rs")): true}
+	for _, match := range rustModuleFileRegexp.FindAllStringSubmatch(string(data), -1) {
+		files[match[1]] = true
+	}
+	index := copyright + "\n"
+	for _, file := range sortedKeys(files) {
+		index += fmt.Sprintf("\n#[path = \"%s\"]\nmod %s;\npub use %s::*;\n", file, genRustModuleName(file), genRustModuleName(file))
+	}
+	if err = gen.WriteFile(name, []byte(index)); err != nil {
+		return err
+	}
+	name = filepath.Join(opt.OutputDir, "mod.rs")
+	if data, err = opt.readOutput(name); err != nil {
+		return err
+	}
+	modules := map[string]bool{module: true}
+	for _, match := range rustModuleIndexRegexp.FindAllStringSubmatch(string(data), -1) {
+		modules[match[1]] = true
+	}
+	index = copyright + "\n\n"
+	for _, module := range sortedKeys(modules) {
+		index += fmt.Sprintf("pub mod %s;\n", module)
+	}
+	return gen.WriteFile(name, []byte(index))
+}
+
+// importTypeScriptCommonModule imports the declarations of the common module
+// referenced by the TypeScript module of the message, which doesn't declare
+// them itself.
+func (opt *Options) importTypeScriptCommonModule(gen *CodeGenerator) error {
+	files, err := opt.listOutputs(filepath.Join(opt.OutputDir, commonModule), ".ts")
+	if err != nil || len(files) == 0 {
+		return err
+	}
+	name := gen.FileWithExtension(".ts")
+	source, err := opt.readOutput(name)
+	if source == nil || err != nil {
+		return err
+	}
+	local := map[string]bool{}
+	for _, match := range typeScriptExportRegexp.FindAllStringSubmatch(string(source), -1) {
+		local[match[1]] = true
+	}
+	imports := map[string]bool{}
+	for _, file := range files {
+		data, err := opt.readOutput(file)
+		if err != nil {
+			return err
+		}
+		for _, match := range typeScriptExportRegexp.FindAllStringSubmatch(string(data), -1) {
+			if !local[match[1]] && regexp.MustCompile(`\b`+match[1]+`\b`).Match(source) {
+				imports[match[1]] = true
+			}
+		}
+	}
+	if len(imports) == 0 {
+		return nil
+	}
+	code := string(source)
+	statement := fmt.Sprintf("import { %s } from '../%s';\n\n", strings.Join(sortedKeys(imports), ", "), commonModule)
+	if i := strings.Index(code, "\nexport "); i != -1 {
+		code = code[:i+1] + statement + code[i+1:]
+	}
+	return gen.WriteFile(name, []byte(code))
+}
+
+// genTypeScriptModuleIndex adds the TypeScript file to the index.ts barrel of
+// its module, and the module to the index.ts barrel of the output directory,
+// which exports the modules as the namespaces named by them.
+func (opt *Options) genTypeScriptModuleIndex(gen *CodeGenerator, module string) error {
+	name := filepath.Join(opt.OutputDir, module, "index.ts")
+	data, err := opt.readOutput(name)
+	if err != nil {
+		return err
+	}
+	files := map[string]bool{strings.TrimSuffix(filepath.Base(gen.FileWithExtension(".ts")), ".ts"): true}
+	for _, match := range typeScriptModuleRegexp.FindAllStringSubmatch(string(data), -1) {
+		files[match[1]] = true
+	}
+	index := copyright + "\n\n"
+	for _, file := range sortedKeys(files) {
+		index += fmt.Sprintf("export * from './%s';\n", file)
+	}
+	if err = gen.WriteFile(name, []byte(index)); err != nil {
+		return err
+	}
+	name = filepath.Join(opt.OutputDir, "index.ts")
+	if data, err = opt.readOutput(name); err != nil {
+		return err
+	}
+	modules := map[string]bool{module: true}
+	for _, match := range typeScriptModuleRegexp.FindAllStringSubmatch(string(data), -1) {
+		modules[match[1]] = true
+	}
+	index = copyright + "\n\n"
+	for _, module := range sortedKeys(modules) {
+		index += fmt.Sprintf("export * as %s from './%s';\n", module, module)
+	}
+	return gen.WriteFile(name, []byte(index))
+}
+
+// sortedKeys returns the sorted keys of the set.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
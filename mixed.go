@@ -0,0 +1,88 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "fmt"
+
+// rustMixedTextTag is the serde tag of the character data for quick-xml,
+// which is the name of the variant of the character data in the content of
+// the mixed type.
+const rustMixedTextTag = "$text"
+
+// hasMixedContent reports whether the character data of the complex type is
+// held by a member of its own, which is the case for the mixed types except
+// the ones with a value, or derived from a mixed type holding it.
+func hasMixedContent(v *ComplexType, XSDSchema []interface{}) bool {
+	if !v.Mixed {
+		return false
+	}
+	if len(v.Base) == 0 {
+		return true
+	}
+	base := getExtensionBase(v, XSDSchema)
+	return base != nil && !base.Mixed
+}
+
+// getGoMixedFieldName returns the name of the field of the character data of
+// the mixed complex type for Go code, which is Text unless a member of the
+// type has the name, or an empty string if the type doesn't hold the
+// character data.
+func (gen *CodeGenerator) getGoMixedFieldName(v *ComplexType) string {
+	if !hasMixedContent(v, gen.ProtoTree) {
+		return ""
+	}
+	for _, element := range v.Elements {
+		if genGoFieldName(element.Name, false) == "Text" {
+			return "CharData"
+		}
+	}
+	for _, group := range v.Groups {
+		if genGoFieldName(group.Name, false) == "Text" {
+			return "CharData"
+		}
+	}
+	return "Text"
+}
+
+// genGoMixedField generate the field of the character data of the mixed
+// complex type for Go code, which holds the character data between the
+// elements of the content.
+func (gen *CodeGenerator) genGoMixedField(v *ComplexType) string {
+	fieldName := gen.getGoMixedFieldName(v)
+	if fieldName == "" {
+		return ""
+	}
+	return fmt.Sprintf("\t%s\tstring\t`xml:\",chardata\"`\n", fieldName)
+}
+
+// getRustMixedContent returns the content of the mixed complex type which is
+// generated as an enum for Rust code, with the variant of the character data
+// and a variant per occurrence of an element, so the order of the character
+// data and the elements is kept. Returns nil if the type doesn't hold the
+// character data.
+func (gen *CodeGenerator) getRustMixedContent(v *ComplexType) *rustChoice {
+	if !hasMixedContent(v, gen.ProtoTree) {
+		return nil
+	}
+	c := &rustChoice{mixed: true}
+	for _, element := range v.Elements {
+		element.Plural, element.Optional = false, false
+		c.elements = append(c.elements, element)
+	}
+	c.variants = genRustElementVariants(c.elements, []string{"Text"})
+	return c
+}
+
+// genRustMixedField generate the field of the content of the mixed complex
+// type by given struct name for Rust code, which is the sequence of the
+// variants of the enum of the content, named after the struct.
+func (gen *CodeGenerator) genRustMixedField(structName string, c *rustChoice) string {
+	c.enumName, c.fieldName = genRustStructName(structName+"Content", true), "content"
+	return fmt.Sprintf("\t#[serde(rename = \"$value\", default)]\n\tpub %s: Vec<%s>,\n", c.fieldName, c.enumName)
+}
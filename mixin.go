@@ -0,0 +1,39 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+// getMixinAttributeGroup returns the attribute group declaration referenced
+// by given name, which is inlined in the referencing type in mixin mode, or
+// nil if the mixin mode is off or the attribute group isn't declared.
+func (gen *CodeGenerator) getMixinAttributeGroup(ref string) *AttributeGroup {
+	if !gen.Mixins {
+		return nil
+	}
+	for _, ele := range gen.ProtoTree {
+		if v, ok := ele.(*AttributeGroup); ok && v.Name == trimNSPrefix(ref) {
+			return v
+		}
+	}
+	return nil
+}
+
+// getMixinGroup returns the group declaration referenced by the group, which
+// is inlined in the referencing type in mixin mode, or nil if the mixin mode
+// is off, the group occurs more than once, or it isn't declared.
+func (gen *CodeGenerator) getMixinGroup(group Group) *Group {
+	if !gen.Mixins || group.Plural {
+		return nil
+	}
+	for _, ele := range gen.ProtoTree {
+		if v, ok := ele.(*Group); ok && v.Name == trimNSPrefix(group.Ref) {
+			return v
+		}
+	}
+	return nil
+}
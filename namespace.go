@@ -0,0 +1,57 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseNamespacePrefixes parses the comma-separated prefix=namespace pairs
+// into the namespace prefixes option. The empty prefix writes the namespace
+// as the default namespace.
+func ParseNamespacePrefixes(value string) (map[string]string, error) {
+	prefixes := map[string]string{}
+	if value == "" {
+		return prefixes, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		idx := strings.Index(pair, "=")
+		if idx == -1 || pair[idx+1:] == "" || strings.ContainsAny(pair[:idx], ": ") {
+			return nil, fmt.Errorf("invalid namespace prefix %s, expected <prefix>=<namespace>", pair)
+		}
+		prefixes[pair[idx+1:]] = pair[:idx]
+	}
+	return prefixes, nil
+}
+
+// formatNamespacePrefixes returns the namespace prefixes option as the
+// comma-separated prefix=namespace pairs ordered by namespace.
+func formatNamespacePrefixes(prefixes map[string]string) string {
+	var namespaces, pairs []string
+	for namespace := range prefixes {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		pairs = append(pairs, prefixes[namespace]+"="+namespace)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// getNamespacePrefix returns the prefix the root elements of the target
+// namespace are serialized with, or an empty string if the target namespace
+// is serialized as the default namespace.
+func (gen *CodeGenerator) getNamespacePrefix() string {
+	if gen.TargetNamespace == "" {
+		return ""
+	}
+	return gen.NamespacePrefixes[gen.TargetNamespace]
+}
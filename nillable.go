@@ -0,0 +1,177 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rustNillableType is the prefix of the type of the fields of the nillable
+// elements for Rust code, whose values are either nil or the values of the
+// elements.
+const rustNillableType = "Nillable<"
+
+// rustNillableSomeAttr is the serde attribute of the optional fields of the
+// nillable elements for Rust code, deserializing the present elements as
+// some, whether they are nil or not.
+const rustNillableSomeAttr = "\t#[serde(default, deserialize_with = \"Nillable::deserialize_some\")]\n"
+
+// isRustNillable returns true if the field of the element for Rust code
+// holds the Nillable value, that is the element is nillable and has simple
+// content of the built-in type. The elements of the complex types are
+// generated as before, since their content can't be told apart from the
+// xsi:nil attribute without buffering.
+func (gen *CodeGenerator) isRustNillable(element Element, fieldType string) bool {
+	return element.Nillable && gen.isRustBuiltInType(gen.genRustFieldType(fieldType))
+}
+
+// trimRustNillable returns the type wrapped by Nillable for Rust code, or
+// the type itself and false if it isn't nillable.
+func trimRustNillable(fieldType string) (string, bool) {
+	if strings.HasPrefix(fieldType, rustNillableType) && strings.HasSuffix(fieldType, ">") {
+		return fieldType[len(rustNillableType) : len(fieldType)-1], true
+	}
+	return fieldType, false
+}
+
+// genRustElementFields generate the field of the element for Rust code,
+// along with its validation and normalize code. The field of the nillable
+// element holds the Nillable values, which are validated and normalized
+// unless they are nil.
+func (gen *CodeGenerator) genRustElementFields(element Element, fieldType string) (content, validation, normalize string) {
+	name := gen.genRustPluralName(element.Name, element.Plural)
+	content = gen.genRustLenientAttr(element) + genRustValueDoc(element.Default, element.Fixed) + genRustDeprecatedAttr(element.Deprecated, element.Deprecation) + genRustCustomAttrs(element.Custom) + genRustSensitiveDoc(element.Sensitive)
+	if !gen.isRustNillable(element, fieldType) {
+		content += gen.genRustMemberCode(element.Name, fieldType, element.Plural, element.Optional)
+		validation = gen.genRustRequiredElementValidation(name, fieldType, element)
+		validation += gen.genRustFieldValidation(name, fieldType, element.Plural, element.Optional, &element.Restriction)
+		normalize = gen.genRustFieldNormalize(name, fieldType, element.Plural, element.Optional, &element.Restriction)
+		return
+	}
+	declType := rustNillableType + gen.genRustFieldType(fieldType) + ">"
+	if element.Plural {
+		declType = "Vec<" + declType + ">"
+	}
+	if element.Optional {
+		declType = "Option<" + declType + ">"
+		if !element.Plural {
+			// The nil element is empty, which serde would deserialize as
+			// None otherwise.
+			content = rustNillableSomeAttr + content
+		}
+	}
+	content += fmt.Sprintf("\t#[serde(rename = \"%s\")]\n\tpub %s: %s,\n", gen.genRustFieldRename(element.Name), genRustFieldName(name), declType)
+	fieldName, indent, receiver := genRustFieldName(name), "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	field := receiver + "." + fieldName
+	if gen.Validation != ValidationNone {
+		if checks := gen.genRustValueValidation(indent+"\t", fieldName, "(*val)", fieldType, false, false, &element.Restriction); checks != "" {
+			validation = genRustNillableLoop(indent, field, checks, element.Plural, element.Optional, false)
+		}
+	}
+	if gen.Normalize {
+		if norm := gen.genRustValueNormalize(indent+"\t", "(*val)", fieldType, false, false, &element.Restriction); norm != "" {
+			normalize = genRustNillableLoop(indent, field, norm, element.Plural, element.Optional, true)
+		}
+	}
+	return
+}
+
+// genRustNillableLoop generate the code running the body on the values of
+// the field of the nillable element which aren't nil for Rust code, the
+// value is bound to val.
+func genRustNillableLoop(indent, field, body string, plural, optional, mutable bool) string {
+	ref, iter, value := "ref", ".iter()", "Nillable::value"
+	if mutable {
+		ref, iter, value = "ref mut", ".iter_mut()", "Nillable::value_mut"
+	}
+	switch {
+	case optional && plural:
+		return fmt.Sprintf("%sfor val in %s%s.flatten().filter_map(%s) {\n%s%s}\n", indent, field, iter, value, body, indent)
+	case plural:
+		return fmt.Sprintf("%sfor val in %s%s.filter_map(%s) {\n%s%s}\n", indent, field, iter, value, body, indent)
+	case optional:
+		return fmt.Sprintf("%sif let Some(Nillable::Value(%s val)) = %s {\n%s%s}\n", indent, ref, field, body, indent)
+	}
+	return fmt.Sprintf("%sif let Nillable::Value(%s val) = %s {\n%s%s}\n", indent, ref, field, body, indent)
+}
+
+// genRustNillable generate the type of the values of the nillable elements
+// for Rust code, which are either nil, marked by the xsi:nil attribute, or
+// the values of the elements, so the elements present without values are
+// told apart from the absent ones.
+func (gen *CodeGenerator) genRustNillable() string {
+	return fmt.Sprintf(`
+%s#[derive(Debug, Default, PartialEq, Clone)]
+pub enum Nillable<T> {
+	#[default]
+	Nil,
+	Value(T),
+}
+
+impl<T> Nillable<T> {
+	/// Returns the value of the element, or None if it's nil.
+	pub fn value(&self) -> Option<&T> {
+		match self {
+			Nillable::Nil => None,
+			Nillable::Value(value) => Some(value),
+		}
+	}
+
+	/// Returns the mutable value of the element, or None if it's nil.
+	pub fn value_mut(&mut self) -> Option<&mut T> {
+		match self {
+			Nillable::Nil => None,
+			Nillable::Value(value) => Some(value),
+		}
+	}
+
+	/// Deserializes the optional value of the element present, whether it's
+	/// nil or not, as some.
+	pub fn deserialize_some<'de, D: serde::Deserializer<'de>>(deserializer: D) -> Result<Option<Self>, D::Error>
+	where
+		T: Deserialize<'de>,
+	{
+		Self::deserialize(deserializer).map(Some)
+	}
+}
+
+#[derive(Serialize, Deserialize)]
+struct NillableRepr<T> {
+	#[serde(rename = "@xsi:nil", alias = "@nil", default, skip_serializing_if = "Option::is_none")]
+	nil: Option<String>,
+	#[serde(rename = "$text", default = "Option::default", skip_serializing_if = "Option::is_none")]
+	value: Option<T>,
+}
+
+impl<T: Serialize> Serialize for Nillable<T> {
+	fn serialize<S: serde::Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+		match self {
+			Nillable::Nil => NillableRepr::<&T> { nil: Some("true".to_string()), value: None }.serialize(serializer),
+			Nillable::Value(value) => NillableRepr { nil: None, value: Some(value) }.serialize(serializer),
+		}
+	}
+}
+
+impl<'de, T: Deserialize<'de>> Deserialize<'de> for Nillable<T> {
+	fn deserialize<D: serde::Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+		let repr = NillableRepr::<T>::deserialize(deserializer)?;
+		match (repr.nil.as_deref().map(str::trim), repr.value) {
+			(Some("true" | "1"), _) => Ok(Nillable::Nil),
+			(_, Some(value)) => Ok(Nillable::Value(value)),
+			// The element without content has the empty value.
+			(_, None) => T::deserialize(serde::de::value::StrDeserializer::<D::Error>::new("")).map(Nillable::Value),
+		}
+	}
+}
+`, gen.genComment("Nillable", "the value of a nillable element, which is either nil, marked by the xsi:nil attribute, or the value of the element, so the element present without a value is told apart from the absent one."))
+}
@@ -0,0 +1,107 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// notationConstant holds the name suffix, the description and the value of
+// a constant of the notation declaration.
+type notationConstant struct {
+	suffix, doc, value string
+}
+
+// getNotationConstants returns the constants of the notation declaration,
+// which are the name of the notation, and its public and system identifiers
+// if declared, so the values of the attributes of the NOTATION types can be
+// compared with them.
+func getNotationConstants(v *Notation) (constants []notationConstant) {
+	doc := v.Doc
+	if doc == "" {
+		doc = fmt.Sprintf("the name of the %s notation.", v.Name)
+	}
+	constants = append(constants, notationConstant{"", doc, v.Name})
+	if v.Public != "" {
+		constants = append(constants, notationConstant{"PublicID", fmt.Sprintf("the public identifier of the %s notation.", v.Name), v.Public})
+	}
+	if v.System != "" {
+		constants = append(constants, notationConstant{"SystemID", fmt.Sprintf("the system identifier of the %s notation.", v.Name), v.System})
+	}
+	return
+}
+
+// genNotationConstantName generate the name of the constant of the notation
+// declaration, named after the notation, such as GifNotation for Go code and
+// GIF_NOTATION for the other languages.
+func genNotationConstantName(v *Notation, suffix string, upper bool) string {
+	name := genGoFieldName(v.Name, false) + "Notation" + suffix
+	if upper {
+		name = strings.ToUpper(ToSnakeCase(name))
+	}
+	return name
+}
+
+// GoNotation generates code for notation XML schema in Go language syntax.
+func (gen *CodeGenerator) GoNotation(v *Notation) {
+	name := genNotationConstantName(v, "", false)
+	gen.addSymbol(v, name)
+	for _, c := range getNotationConstants(v) {
+		name := genNotationConstantName(v, c.suffix, false)
+		gen.Field += fmt.Sprintf("%sconst %s = %s\n", gen.genComment(name, c.doc), name, strconv.Quote(c.value))
+	}
+}
+
+// RustNotation generates code for notation XML schema in Rust language
+// syntax.
+func (gen *CodeGenerator) RustNotation(v *Notation) {
+	gen.addSymbol(v, genNotationConstantName(v, "", true))
+	gen.Field += "\n"
+	for _, c := range getNotationConstants(v) {
+		name := genNotationConstantName(v, c.suffix, true)
+		gen.Field += fmt.Sprintf("%spub const %s: &str = \"%s\";\n", gen.genComment(name, c.doc), name, escapeRustString(c.value))
+	}
+}
+
+// TypeScriptNotation generates code for notation XML schema in TypeScript
+// language syntax.
+func (gen *CodeGenerator) TypeScriptNotation(v *Notation) {
+	gen.addSymbol(v, genNotationConstantName(v, "", true))
+	for _, c := range getNotationConstants(v) {
+		name := genNotationConstantName(v, c.suffix, true)
+		gen.Field += fmt.Sprintf("%sexport const %s = %s;\n", gen.genComment(name, c.doc), name, strconv.Quote(c.value))
+	}
+}
+
+// CNotation generates code for notation XML schema in C language syntax.
+func (gen *CodeGenerator) CNotation(v *Notation) {
+	gen.addSymbol(v, genNotationConstantName(v, "", true))
+	for _, c := range getNotationConstants(v) {
+		name := genNotationConstantName(v, c.suffix, true)
+		gen.Field += fmt.Sprintf("%s#define %s %s\n", gen.genComment(name, c.doc), name, strconv.Quote(c.value))
+	}
+}
+
+// JavaNotation generates code for notation XML schema in Java language
+// syntax, as the final class of the constants of the notation.
+func (gen *CodeGenerator) JavaNotation(v *Notation) {
+	className := genNotationConstantName(v, "", false)
+	gen.addSymbol(v, className)
+	var members string
+	for _, c := range getNotationConstants(v) {
+		name := "NAME"
+		if c.suffix != "" {
+			name = strings.ToUpper(ToSnakeCase(c.suffix))
+		}
+		members += fmt.Sprintf("\tpublic static final String %s = %s;\n", name, strconv.Quote(c.value))
+	}
+	gen.Field += fmt.Sprintf("%spublic final class %s {\n%s\n\tprivate %s() {\n\t}\n}\n", gen.genComment(className, fmt.Sprintf("the class of the constants of the %s notation.", v.Name)), className, members, className)
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseOptionalOverrides parses the comma-separated name=optional and
+// name=required pairs into the optional overrides option, which forces the
+// elements and attributes by name to be optional or required regardless of
+// the schema. The name is the name of the element or attribute in any type,
+// or qualified by the name of the complex type, the group or the attribute
+// group declaring it, such as Dbtr.Nm.
+func ParseOptionalOverrides(value string) (map[string]bool, error) {
+	overrides := map[string]bool{}
+	if value == "" {
+		return overrides, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		idx := strings.Index(pair, "=")
+		if idx <= 0 || (pair[idx+1:] != "optional" && pair[idx+1:] != "required") {
+			return nil, fmt.Errorf("invalid optional override %s, expected <name>=optional or <name>=required", pair)
+		}
+		overrides[pair[:idx]] = pair[idx+1:] == "optional"
+	}
+	return overrides, nil
+}
+
+// formatOptionalOverrides returns the optional overrides option as the
+// comma-separated name=optional and name=required pairs ordered by name.
+func formatOptionalOverrides(overrides map[string]bool) string {
+	var names, pairs []string
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		occurrence := "required"
+		if overrides[name] {
+			occurrence = "optional"
+		}
+		pairs = append(pairs, name+"="+occurrence)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// resolveOptionalOverrides forces the elements and attributes of the
+// optional overrides option to be optional or required, so the generated
+// types and the validation code accept the documents of the senders
+// violating the minOccurs and the use of the schema. The override qualified
+// by the name of the declaring type takes precedence over the one of the
+// name only.
+func (opt *Options) resolveOptionalOverrides() {
+	if len(opt.OptionalOverrides) == 0 {
+		return
+	}
+	override := func(typeName, name string, optional *bool) {
+		if value, ok := opt.OptionalOverrides[typeName+"."+name]; ok {
+			*optional = value
+		} else if value, ok := opt.OptionalOverrides[name]; ok {
+			*optional = value
+		}
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *ComplexType:
+			for i := range v.Elements {
+				override(v.Name, v.Elements[i].Name, &v.Elements[i].Optional)
+			}
+			for i := range v.Attributes {
+				override(v.Name, v.Attributes[i].Name, &v.Attributes[i].Optional)
+			}
+		case *Group:
+			for i := range v.Elements {
+				override(v.Name, v.Elements[i].Name, &v.Elements[i].Optional)
+			}
+		case *AttributeGroup:
+			for i := range v.Attributes {
+				override(v.Name, v.Attributes[i].Name, &v.Attributes[i].Optional)
+			}
+		}
+	}
+}
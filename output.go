@@ -0,0 +1,102 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// OutputNamer computes the paths of the files generated from the schemas, so
+// the embedders route the generated files into their own layouts, such as
+// internal/gen/<namespace>/<version>.rs, instead of the layout of the input
+// directory.
+type OutputNamer interface {
+	// OutputPath returns the path of the output of the schema of the
+	// options, which the files generated from the schema are named by.
+	OutputPath(opt *Options) string
+	// FileWithExtension returns the path of the file generated with the
+	// extension, such as .go or .validator.go, for the output path.
+	FileWithExtension(path, extension string) string
+}
+
+// DefaultOutputNamer is the OutputNamer used when the options have none. It
+// mirrors the schema files of the input directory under the output
+// directory, and the custom namers embed it to override one of its methods.
+type DefaultOutputNamer struct{}
+
+// OutputPath returns the path of the schema file under the output directory,
+// relative to the input directory. The schemas of the versioned packages and
+// of the messages are generated into the directories of their packages and
+// modules, and the code of the Java project into the source directory of the
+// project.
+func (DefaultOutputNamer) OutputPath(opt *Options) string {
+	path := filepath.Join(opt.OutputDir, strings.TrimPrefix(opt.FilePath, opt.InputDir))
+	if pkg := getVersionPackage(opt.TargetNamespace); opt.Lang == "Go" && opt.VersionedPackages != "" && pkg != "" {
+		path = filepath.Join(opt.OutputDir, pkg, filepath.Base(opt.FilePath))
+	}
+	if opt.GroupByMessage {
+		path = filepath.Join(opt.OutputDir, opt.getMessageModule(), filepath.Base(opt.FilePath))
+	}
+	if opt.Lang == "Java" && opt.JavaProject != "" {
+		path = opt.getJavaSourcePath(opt.getOutputPackage())
+	}
+	// The TypeScript code of the single schema file of the npm package is
+	// generated into the package directory, instead of named by it.
+	if opt.Lang == "TypeScript" && opt.NPMPackage != "" && path == filepath.Clean(opt.OutputDir) {
+		path = filepath.Join(opt.OutputDir, filepath.Base(opt.FilePath))
+	}
+	return path
+}
+
+// FileWithExtension returns the path with the extension, unless the path has
+// the extension already.
+func (DefaultOutputNamer) FileWithExtension(path, extension string) string {
+	if !strings.HasPrefix(extension, ".") {
+		extension = "." + extension
+	}
+	if strings.HasSuffix(path, extension) {
+		return path
+	}
+	return path + extension
+}
+
+// getOutputNamer returns the output namer of the options, or the default
+// one.
+func (opt *Options) getOutputNamer() OutputNamer {
+	if opt.OutputNamer != nil {
+		return opt.OutputNamer
+	}
+	return DefaultOutputNamer{}
+}
+
+// getOutputPackage returns the package of the code generated from the schema
+// of the options. The schemas of each namespace version are generated into
+// a Go package named by the version, and the schemas of each message into
+// the module named by the message, the other schemas into the common module.
+func (opt *Options) getOutputPackage() string {
+	packageName := opt.Package
+	if pkg := getVersionPackage(opt.TargetNamespace); opt.Lang == "Go" && opt.VersionedPackages != "" && pkg != "" {
+		packageName = pkg
+	}
+	if opt.GroupByMessage {
+		packageName = opt.getMessageModule()
+	}
+	return packageName
+}
+
+// FileWithExtension returns the path of the file generated with the
+// extension by the output namer of the code generator, or by the default
+// one.
+func (gen *CodeGenerator) FileWithExtension(extension string) string {
+	if gen.OutputNamer != nil {
+		return gen.OutputNamer.FileWithExtension(gen.File, extension)
+	}
+	return DefaultOutputNamer{}.FileWithExtension(gen.File, extension)
+}
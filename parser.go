@@ -9,14 +9,16 @@
 package xgen
 
 import (
+	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
-
-	"golang.org/x/net/html/charset"
 )
 
 // Options holds user-defined overrides and runtime data that are used when
@@ -28,6 +30,7 @@ type Options struct {
 	OutputDir           string
 	Extract             bool
 	Lang                string
+	Langs               []string
 	Package             string
 	IncludeMap          map[string]bool
 	LocalNameNSMap      map[string]string
@@ -36,12 +39,148 @@ type Options struct {
 	ParseFileMap        map[string][]interface{}
 	ProtoTree           []interface{}
 	RemoteSchema        map[string][]byte
+	Validation          string
+	TestVectors         bool
+	Normalize           bool
+	ValidationMaxDepth  int
+	Lenient             bool
+	Artifacts           map[string][]byte
+	Provenance          bool
+	ProvenanceTimestamp bool
+	RootWrappers        bool
+	PruneUnused         bool
+	TypeAliases         bool
+	VersionedPackages   string
+	GroupByMessage      bool
+	CommentStyle        string
+	CommentWidth        int
+	AnyTypeFallback     string
+	Mixins              bool
+	BooleanForm         string
+	DecimalForm         string
+	NamespacePrefixes   map[string]string
+	Constants           bool
+	Accessors           bool
+	PatchTypes          bool
+	Visitor             bool
+	Equality            bool
+	PrettyXML           bool
+	SymbolMap           bool
+	SizeReport          bool
+	ValidationTracing   bool
+	RenameCase          string
+	PluralNames         bool
+	PluralOverrides     map[string]string
+	OptionalOverrides   map[string]bool
+	Deprecations        map[string]string
+	Customizations      map[string][]string
+	ExternalTypes       ExternalTypes
+	DocLang             string
+	PatternFallback     string
+	AssertFallback      string
+	ChoiceEnums         bool
+	ChoiceRepr          string
+	ChoiceTag           string
+	JSONValue           bool
+	RustDecimal         bool
+	RustChrono          bool
+	RustErrorType       string
+	RustUses            []string
+	RustDerives         []RustDerive
+	RustModules         bool
+	ValidateTags        bool
+	SQLMethods          bool
+	JavaProject         string
+	ProjectVersion      string
+	NPMPackage          string
+	JSONSchemas         bool
+	Warnings            []Warning
+	// Charset decodes the schema documents regardless of the charset of
+	// their XML declaration, such as ISO-8859-1, if it isn't empty.
+	Charset string
+	// CharsetReader decodes the schema documents declaring other charsets
+	// than UTF-8, the charsets are known by their labels if it's nil.
+	CharsetReader func(charset string, input io.Reader) (io.Reader, error)
+	// Entities holds the custom entities the schema documents reference by
+	// name, in addition to the predefined entities of XML.
+	Entities map[string]string
+	// NonStrict accepts the schema documents which aren't well-formed, such
+	// as the unquoted attribute values and the unknown entities.
+	NonStrict bool
+	// MaxTokens and MaxDepth limit the number of the tokens and the nesting
+	// depth of the elements of each schema document, 0 is unlimited.
+	MaxTokens int
+	MaxDepth  int
+	// MaxImportDepth limits the depth of the chains of the schemas imported
+	// and included by the schema documents, MaxDeclarations limits the
+	// number of the top-level declarations of each schema document,
+	// MaxEntityExpansion limits the number of the bytes the references of
+	// the custom entities of each schema document expand to, and MaxFileSize
+	// limits the size in bytes of each schema document, 0 is unlimited. The
+	// parse returns the LimitError once a schema document exceeds a limit.
+	MaxImportDepth     int
+	MaxDeclarations    int
+	MaxEntityExpansion int64
+	MaxFileSize        int64
+	// RemoteSchemaDir caches the schemas imported and included from the
+	// URLs, which are fetched into the directory unless they are cached,
+	// and the remote schemas are skipped if it's empty. Offline fails on the
+	// remote schemas which aren't cached instead of fetching them.
+	RemoteSchemaDir string
+	Offline         bool
+	// Events is called with the events of parsing the file, if it isn't
+	// nil.
+	Events func(Event)
+	// Source holds the content of the schema file, such as the unsaved
+	// content of an editor, the file is read if it's nil.
+	Source []byte
+	// Cache holds the parsed schemas shared by the files of a batch, the
+	// schemas are cached if it isn't nil.
+	Cache *SchemaCache
+	// Transforms are applied to the proto tree after each stage of the
+	// pipeline before the generate stage, in order.
+	Transforms []Transform
+	// OutputNamer computes the paths of the generated files, the files are
+	// named by the DefaultOutputNamer if it's nil.
+	OutputNamer OutputNamer
+	// sourceOnly resolves none of the schemas the schema imports, includes
+	// and redefines, so the parse reads nothing but the source, as the fuzz
+	// entry functions parse the documents.
+	sourceOnly bool
+	// langOptions holds the options of each language generated from the
+	// proto tree of the options, when the options have many languages.
+	langOptions []*Options
+	// importDepth is the depth of the schema of the options in the chain of
+	// the imported and included schemas, 0 for the schema parsed first.
+	importDepth int
+	// redefine holds the state of the redefine element being parsed, and
+	// redefinitions holds the redefinitions applied to the declarations of
+	// the redefined schema.
+	redefine      *redefinition
+	redefinitions *redefinitions
+	// path holds the names of the elements being parsed, from the schema
+	// element, and appinfoOwner the name of the element the appinfo element
+	// being parsed annotates.
+	path         []string
+	appinfoOwner string
+	// inputLine returns the line of the element being parsed, or 0 if it
+	// isn't known, while the schema of the options is decoded.
+	inputLine func() int
+	// builtInTypes holds the types of the language used as is in the
+	// generated code besides its built-in types, such as the external types
+	// and the decimal type, registered by the resolve stage.
+	builtInTypes map[string]bool
 
 	InElement        string
 	CurrentEle       string
 	InGroup          int
 	InUnion          bool
+	InSimpleType     int
 	InAttributeGroup bool
+	InAppinfo        bool
+	InDocumentation  string
+	TargetNamespace  string
+	SchemaVersion    string
 
 	SimpleType     *Stack
 	ComplexType    *Stack
@@ -50,6 +189,9 @@ type Options struct {
 	Group          *Stack
 	AttributeGroup *Stack
 	Choice         *Stack
+	Particle       *Stack
+	Unique         *Stack
+	Notation       *Stack
 }
 
 // NewParser creates a new parser options for the Parse. Useful for XML schema
@@ -61,22 +203,94 @@ func NewParser(options *Options) *Options {
 // Parse reads XML documents and return proto tree for every element in the
 // documents by given options. If value of the property extract is false,
 // parse will fetch schema used in <import> or <include> statements.
+//
+// Parse runs the stages of the pipeline in order: ParseSchema, ResolveTypes,
+// NormalizeNames, and GenerateCode unless the property extract is true. The
+// transforms of the options are applied to the proto tree after each stage
+// before the generate stage.
+//
+// With the languages of the Langs instead of the Lang, the schema is parsed
+// once, and the later stages run for each language, generating its code into
+// the directory of the LangDirs under the output directory.
 func (opt *Options) Parse() (err error) {
 	opt.FileDir = filepath.Dir(opt.FilePath)
-	var fi os.FileInfo
-	fi, err = os.Stat(opt.FilePath)
-	if err != nil {
+	if opt.MaxImportDepth > 0 && opt.importDepth > opt.MaxImportDepth {
+		return &LimitError{File: opt.FilePath, Limit: LimitImportDepth, Max: int64(opt.MaxImportDepth)}
+	}
+	source, err := opt.openSource()
+	if source == nil || err != nil {
 		return
 	}
-	if fi.IsDir() {
+	defer source.Close()
+	// The in-memory outputs and the provenance belong to the options, the
+	// parses writing them aren't cached.
+	if opt.Cache != nil && (opt.Extract || opt.Artifacts == nil && !opt.Provenance) {
+		var data []byte
+		if data, err = ioutil.ReadAll(opt.newLimitReader(source)); err != nil {
+			return opt.decodeError(err)
+		}
+		key := opt.schemaCacheKey(data)
+		if opt.Cache.load(key, opt) {
+			return
+		}
+		return opt.Cache.parse(key, opt, func() error {
+			return opt.parse(bytes.NewReader(data))
+		})
+	}
+	return opt.parse(source)
+}
+
+// parse runs the stages of the pipeline on the XML document from the source.
+func (opt *Options) parse(source io.Reader) (err error) {
+	if err = checkPatternFallback(opt.PatternFallback); err != nil {
 		return
 	}
-	var xmlFile *os.File
-	xmlFile, err = os.Open(opt.FilePath)
-	if err != nil {
+	if err = checkAssertFallback(opt.AssertFallback); err != nil {
+		return
+	}
+	if err = opt.decode(source); err != nil {
+		return
+	}
+	if err = opt.applyTransforms(StageParse); err != nil {
+		return
+	}
+	if len(opt.Langs) > 0 {
+		if !opt.Extract {
+			err = opt.generateLangs()
+		}
+		return
+	}
+	if err = opt.ResolveTypes(); err != nil {
+		return
+	}
+	if err = opt.applyTransforms(StageResolve); err != nil {
+		return
+	}
+	opt.NormalizeNames()
+	if err = opt.applyTransforms(StageNormalize); err != nil {
 		return
 	}
-	defer xmlFile.Close()
+	if !opt.Extract {
+		err = opt.GenerateCode()
+	}
+	return
+}
+
+// openSource opens the schema of the options, the source is nil if the file
+// path is a directory.
+func (opt *Options) openSource() (io.ReadCloser, error) {
+	if opt.Source != nil {
+		return ioutil.NopCloser(bytes.NewReader(opt.Source)), nil
+	}
+	fi, err := os.Stat(opt.FilePath)
+	if err != nil || fi.IsDir() {
+		return nil, err
+	}
+	return os.Open(opt.FilePath)
+}
+
+// decode reads the XML document from the source into the proto tree.
+func (opt *Options) decode(source io.Reader) (err error) {
 	if !opt.Extract {
 		opt.ParseFileList[opt.FilePath] = true
 		opt.ParseFileMap[opt.FilePath] = opt.ProtoTree
@@ -87,7 +301,15 @@ func (opt *Options) Parse() (err error) {
 	opt.CurrentEle = ""
 	opt.InGroup = 0
 	opt.InUnion = false
+	opt.InSimpleType = 0
 	opt.InAttributeGroup = false
+	opt.InAppinfo = false
+	opt.InDocumentation = ""
+	opt.TargetNamespace = ""
+	opt.SchemaVersion = ""
+	opt.redefine = nil
+	opt.path = nil
+	opt.appinfoOwner = ""
 
 	opt.SimpleType = NewStack()
 	opt.ComplexType = NewStack()
@@ -96,29 +318,70 @@ func (opt *Options) Parse() (err error) {
 	opt.Group = NewStack()
 	opt.AttributeGroup = NewStack()
 	opt.Choice = NewStack()
+	opt.Particle = NewStack()
+	opt.Unique = NewStack()
+	opt.Notation = NewStack()
 
-	decoder := xml.NewDecoder(xmlFile)
-	decoder.CharsetReader = charset.NewReaderLabel
+	lines := &lineReader{reader: opt.newLimitReader(source)}
+	decoder, err := opt.newDecoder(lines)
+	if err != nil {
+		return opt.decodeError(err)
+	}
+	charsetReader := decoder.CharsetReader
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		// The offsets of the decoder count the bytes of the document decoded
+		// from its charset after the XML declaration, not the bytes read.
+		lines = nil
+		return charsetReader(label, input)
+	}
+	opt.inputLine = func() int {
+		if lines == nil {
+			return 0
+		}
+		return lines.line(decoder.InputOffset())
+	}
+	defer func() { opt.inputLine = nil }()
+	limits := decoderLimits{maxTokens: opt.MaxTokens, maxDepth: opt.MaxDepth}
+	var root bool
 	for {
-		token, _ := decoder.Token()
+		token, tokenErr := decoder.Token()
+		if tokenErr != nil && tokenErr != io.EOF {
+			return opt.decodeError(tokenErr)
+		}
 		if token == nil {
 			break
 		}
+		if err = limits.check(token); err != nil {
+			return opt.decodeError(err)
+		}
 
 		switch element := token.(type) {
 		case xml.StartElement:
-
+			if !root && element.Name.Local != "schema" {
+				return opt.decodeError(fmt.Errorf("expected element type <schema> but have <%s>", element.Name.Local))
+			}
+			root = true
 			opt.InElement = element.Name.Local
+			opt.path = append(opt.path, element.Name.Local)
 			funcName := fmt.Sprintf("On%s", MakeFirstUpperCase(opt.InElement))
 			if err = callFuncByName(opt, funcName, []reflect.Value{reflect.ValueOf(element), reflect.ValueOf(opt.ProtoTree)}); err != nil {
 				return
 			}
 
 		case xml.EndElement:
+			parsed := len(opt.ProtoTree)
 			funcName := fmt.Sprintf("End%s", MakeFirstUpperCase(element.Name.Local))
 			if err = callFuncByName(opt, funcName, []reflect.Value{reflect.ValueOf(element), reflect.ValueOf(opt.ProtoTree)}); err != nil {
 				return
 			}
+			if len(opt.path) > 0 {
+				opt.path = opt.path[:len(opt.path)-1]
+			}
+			opt.applyRedefinitions(parsed)
+			opt.emitTypesParsed(parsed)
+			if opt.MaxDeclarations > 0 && len(opt.ProtoTree) > opt.MaxDeclarations {
+				return &LimitError{File: opt.FilePath, Limit: LimitDeclarations, Max: int64(opt.MaxDeclarations)}
+			}
 		case xml.CharData:
 			if err = opt.OnCharData(string(element), opt.ProtoTree); err != nil {
 				return
@@ -127,37 +390,220 @@ func (opt *Options) Parse() (err error) {
 		}
 
 	}
+	return
+}
 
-	if !opt.Extract {
-		opt.ParseFileList[opt.FilePath] = true
-		opt.ParseFileMap[opt.FilePath] = opt.ProtoTree
-		path := filepath.Join(opt.OutputDir, strings.TrimPrefix(opt.FilePath, opt.InputDir))
+// unexpectedElement returns the error of the element by given local name out
+// of its place in the schema, such as the anonymous complex type out of the
+// elements, which the handlers return instead of asserting the declarations
+// of the empty stacks.
+func (opt *Options) unexpectedElement(name string) error {
+	return opt.decodeError(fmt.Errorf("unexpected element <%s>", name))
+}
+
+// decodeError returns the error of decoding the schema document of the
+// options, the LimitError is returned with the path of the document.
+func (opt *Options) decodeError(err error) error {
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		if limitErr.File == "" {
+			limitErr.File = opt.FilePath
+		}
+		return limitErr
+	}
+	return fmt.Errorf("%s: %v", opt.FilePath, err)
+}
+
+// GenerateCode is the generate stage of the pipeline, it generates the code
+// of the proto tree in the language of the options.
+func (opt *Options) GenerateCode() (err error) {
+	opt.ParseFileList[opt.FilePath] = true
+	opt.ParseFileMap[opt.FilePath] = opt.ProtoTree
+	if opt.isExternalNamespace(opt.TargetNamespace) {
+		return
+	}
+	path, packageName := opt.getOutputNamer().OutputPath(opt), opt.getOutputPackage()
+	if opt.Artifacts == nil {
 		if err := PrepareOutputDir(filepath.Dir(path)); err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
-		generator := &CodeGenerator{
-			Lang:      opt.Lang,
-			Package:   opt.Package,
-			File:      path,
-			ProtoTree: opt.ProtoTree,
-			StructAST: map[string]string{},
+	}
+	if err = checkCommentStyle(opt.CommentStyle); err != nil {
+		return
+	}
+	if err = checkBooleanForm(opt.BooleanForm); err != nil {
+		return
+	}
+	if err = checkDecimalForm(opt.DecimalForm); err != nil {
+		return
+	}
+	if err = checkRenameCase(opt.RenameCase); err != nil {
+		return
+	}
+	if err = checkChoiceRepr(opt.ChoiceRepr); err != nil {
+		return
+	}
+	if err = checkJavaProject(opt.JavaProject); err != nil {
+		return
+	}
+	if err = opt.checkGroupByMessage(); err != nil {
+		return
+	}
+	if err = checkRustErrorType(opt.RustErrorType); err != nil {
+		return
+	}
+	if err = checkRustUses(opt.RustUses); err != nil {
+		return
+	}
+	if err = checkRustDerives(opt.RustDerives); err != nil {
+		return
+	}
+	if err = opt.checkRustModules(); err != nil {
+		return
+	}
+	generator := &CodeGenerator{
+		Lang:               opt.Lang,
+		Package:            packageName,
+		File:               path,
+		OutputNamer:        opt.OutputNamer,
+		ProtoTree:          opt.ProtoTree,
+		StructAST:          map[string]string{},
+		Validation:         opt.Validation,
+		Normalize:          opt.Normalize,
+		ValidationMaxDepth: opt.ValidationMaxDepth,
+		Lenient:            opt.Lenient,
+		AssertFallback:     opt.AssertFallback,
+		Artifacts:          opt.Artifacts,
+		RootWrappers:       opt.RootWrappers,
+		PruneUnused:        opt.PruneUnused,
+		TypeAliases:        opt.TypeAliases,
+		TargetNamespace:    opt.TargetNamespace,
+		SchemaVersion:      opt.SchemaVersion,
+		CommentStyle:       opt.CommentStyle,
+		CommentWidth:       opt.CommentWidth,
+		AnyTypeFallback:    opt.AnyTypeFallback,
+		Mixins:             opt.Mixins,
+		BooleanForm:        opt.BooleanForm,
+		DecimalForm:        opt.DecimalForm,
+		NamespacePrefixes:  opt.NamespacePrefixes,
+		Accessors:          opt.Accessors,
+		PatchTypes:         opt.PatchTypes,
+		Visitor:            opt.Visitor,
+		Equality:           opt.Equality,
+		PrettyXML:          opt.PrettyXML,
+		SymbolMap:          opt.SymbolMap,
+		SizeReport:         opt.SizeReport,
+		ValidationTracing:  opt.ValidationTracing,
+		RenameCase:         opt.RenameCase,
+		PluralNames:        opt.PluralNames,
+		PluralOverrides:    opt.PluralOverrides,
+		ChoiceEnums:        opt.ChoiceEnums,
+		ChoiceRepr:         opt.ChoiceRepr,
+		ChoiceTag:          opt.ChoiceTag,
+		JSONValue:          opt.JSONValue,
+		RustDecimal:        opt.RustDecimal,
+		RustChrono:         opt.RustChrono,
+		RustErrorType:      opt.RustErrorType,
+		RustUses:           opt.RustUses,
+		RustDerives:        opt.RustDerives,
+		RustModules:        opt.RustModules,
+		ExternalTypes:      opt.ExternalTypes,
+		ValidateTags:       opt.ValidateTags,
+		SQLMethods:         opt.SQLMethods,
+		JavaProject:        opt.JavaProject,
+		JSONSchemas:        opt.JSONSchemas,
+		builtInTypes:       opt.builtInTypes,
+	}
+	if opt.Provenance {
+		if generator.Provenance, err = opt.newProvenance(); err != nil {
+			return
+		}
+	}
+	funcName := fmt.Sprintf("Gen%s", MakeFirstUpperCase(opt.Lang))
+	if err = callFuncByName(generator, funcName, []reflect.Value{}); err != nil {
+		return
+	}
+	for _, assert := range generator.unsupportedAsserts {
+		opt.warn("skipped the validation of the unsupported assertion " + assert)
+	}
+	for _, unique := range generator.unsupportedUniques {
+		opt.warn("skipped the validation of the unsupported identity constraint " + unique)
+	}
+	if opt.Lang == "Java" && opt.JavaProject != "" {
+		if err = opt.genJavaProject(generator); err != nil {
+			return
 		}
-		funcName := fmt.Sprintf("Gen%s", MakeFirstUpperCase(opt.Lang))
-		if err = callFuncByName(generator, funcName, []reflect.Value{}); err != nil {
+	}
+	if opt.Lang == "TypeScript" && opt.NPMPackage != "" {
+		if err = opt.genTypeScriptPackage(generator); err != nil {
 			return
 		}
 	}
+	if opt.GroupByMessage {
+		if err = opt.genMessageModule(generator); err != nil {
+			return
+		}
+	}
+	if opt.TestVectors {
+		if err = generator.GenTestVectors(); err != nil {
+			return
+		}
+	}
+	if opt.Constants {
+		if err = generator.GenConstants(); err != nil {
+			return
+		}
+	}
+	if opt.SymbolMap {
+		if err = generator.GenSymbolMap(); err != nil {
+			return
+		}
+	}
+	if opt.SizeReport {
+		if err = generator.GenSizeReport(); err != nil {
+			return
+		}
+	}
+	if opt.Provenance {
+		err = opt.writeProvenance(generator.Provenance)
+	}
 	return
 }
 
+// Generate reads XML documents and returns the generated code keyed by the
+// slash-separated file path relative to the output directory, without writing
+// to disk, so embedding tools can post-process or package the outputs
+// themselves.
+func (opt *Options) Generate() (map[string][]byte, error) {
+	opt.Artifacts = make(map[string][]byte)
+	if err := opt.Parse(); err != nil {
+		return nil, err
+	}
+	artifacts := make(map[string][]byte, len(opt.Artifacts))
+	for name, data := range opt.Artifacts {
+		if opt.OutputDir != "" {
+			if rel, err := filepath.Rel(opt.OutputDir, name); err == nil {
+				name = rel
+			}
+		}
+		artifacts[strings.TrimPrefix(filepath.ToSlash(name), "/")] = data
+	}
+	return artifacts, nil
+}
+
 // GetValueType convert XSD schema value type to the build-in type for the
 // given value and proto tree.
 func (opt *Options) GetValueType(value string, XSDSchema []interface{}) (valueType string, err error) {
-	if buildType, ok := getBuildInTypeByLang(trimNSPrefix(value), opt.Lang); ok {
+	if buildType, ok := opt.getBuildInType(trimNSPrefix(value)); ok {
 		valueType = buildType
 		return
 	}
+	// The declarations of the external namespaces aren't generated, nor
+	// their schemas parsed.
+	if externalType, ok, err := opt.getExternalType(value); ok {
+		return externalType, err
+	}
 	valueType = getBasefromSimpleType(trimNSPrefix(value), XSDSchema)
 	if valueType != trimNSPrefix(value) && valueType != "" {
 		return
@@ -165,11 +611,10 @@ func (opt *Options) GetValueType(value string, XSDSchema []interface{}) (valueTy
 	if opt.Extract {
 		return
 	}
-	schemaLocation := opt.NSSchemaLocationMap[opt.parseNS(value)]
-	if isValidURL(schemaLocation) {
+	xsdFile, err := opt.resolveSchemaLocation(opt.NSSchemaLocationMap[opt.parseNS(value)])
+	if xsdFile == "" || err != nil {
 		return
 	}
-	xsdFile := filepath.Join(opt.FileDir, schemaLocation)
 	var fi os.FileInfo
 	fi, err = os.Stat(xsdFile)
 	if err != nil {
@@ -177,71 +622,56 @@ func (opt *Options) GetValueType(value string, XSDSchema []interface{}) (valueTy
 	}
 	if fi.IsDir() {
 		// extract type of value from include schema.
-		valueType = ""
-		for include := range opt.IncludeMap {
-			parser := NewParser(&Options{
-				FilePath:            filepath.Join(opt.FileDir, include),
-				OutputDir:           opt.OutputDir,
-				Extract:             true,
-				Lang:                opt.Lang,
-				IncludeMap:          opt.IncludeMap,
-				LocalNameNSMap:      opt.LocalNameNSMap,
-				NSSchemaLocationMap: opt.NSSchemaLocationMap,
-				ParseFileList:       opt.ParseFileList,
-				ParseFileMap:        opt.ParseFileMap,
-				ProtoTree:           make([]interface{}, 0),
-			})
-			if parser.Parse() != nil {
-				return
-			}
-			if vt := getBasefromSimpleType(trimNSPrefix(value), parser.ProtoTree); vt != trimNSPrefix(value) {
-				valueType = vt
-			}
-		}
-		if valueType == "" {
-			valueType = trimNSPrefix(value)
-		}
+		valueType, err = opt.getIncludedValueType(value)
 		return
 	}
 
 	depXSDSchema, ok := opt.ParseFileMap[xsdFile]
 	if !ok {
-		parser := NewParser(&Options{
-			FilePath:            xsdFile,
-			OutputDir:           opt.OutputDir,
-			Extract:             false,
-			Lang:                opt.Lang,
-			IncludeMap:          opt.IncludeMap,
-			LocalNameNSMap:      opt.LocalNameNSMap,
-			NSSchemaLocationMap: opt.NSSchemaLocationMap,
-			ParseFileList:       opt.ParseFileList,
-			ParseFileMap:        opt.ParseFileMap,
-			ProtoTree:           make([]interface{}, 0),
-		})
-		if parser.Parse() != nil {
+		parser := NewParser(opt.subOptions(xsdFile, false))
+		if err = parser.Parse(); err != nil {
+			err = getLimitError(err)
 			return
 		}
+		opt.addWarnings(parser.Warnings)
 		depXSDSchema = parser.ProtoTree
 	}
 	valueType = getBasefromSimpleType(trimNSPrefix(value), depXSDSchema)
 	if valueType != trimNSPrefix(value) && valueType != "" {
 		return
 	}
-	parser := NewParser(&Options{
-		FilePath:            xsdFile,
-		OutputDir:           opt.OutputDir,
-		Extract:             true,
-		Lang:                opt.Lang,
-		IncludeMap:          opt.IncludeMap,
-		LocalNameNSMap:      opt.LocalNameNSMap,
-		NSSchemaLocationMap: opt.NSSchemaLocationMap,
-		ParseFileList:       opt.ParseFileList,
-		ParseFileMap:        opt.ParseFileMap,
-		ProtoTree:           make([]interface{}, 0),
-	})
-	if parser.Parse() != nil {
+	parser := NewParser(opt.subOptions(xsdFile, true))
+	if err = parser.Parse(); err != nil {
+		err = getLimitError(err)
 		return
 	}
+	opt.addWarnings(parser.Warnings)
 	valueType = getBasefromSimpleType(trimNSPrefix(value), parser.ProtoTree)
 	return
 }
+
+// subOptions returns the options used for parsing the schema in the given
+// file referenced by the current schema. The sub parser inherits the
+// user-defined overrides and shares the runtime data of the current parser.
+// The code of the schema in the input directory is generated as the code of
+// the input file, which the parse of the file reuses from the cache instead
+// of generating the types again.
+func (opt *Options) subOptions(filePath string, extract bool) *Options {
+	sub := *opt
+	sub.FilePath = filePath
+	if !opt.isInInputDir(filePath) {
+		sub.InputDir = ""
+		sub.Package = ""
+	}
+	sub.Extract = extract
+	sub.RemoteSchema = nil
+	sub.ProtoTree = make([]interface{}, 0)
+	// The events are reported for the files of the batch only.
+	sub.Events = nil
+	sub.Source = nil
+	sub.importDepth = opt.importDepth + 1
+	sub.redefinitions = nil
+	sub.builtInTypes = nil
+	sub.Warnings, sub.inputLine = nil, nil
+	return &sub
+}
@@ -9,12 +9,28 @@
 package xgen
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -136,3 +152,5700 @@ func TestParseRust(t *testing.T) {
 func TestParseRustExternal(t *testing.T) {
 	testParseForSource(t, "Rust", "rs", "rs", externalFixtureDir, true)
 }
+
+// generateFromSource writes the given XSD source into a temporary directory,
+// generates code for it with the options adjusted by the given function and
+// returns the path of the generated file without its extension.
+func generateFromSource(t *testing.T, source string, lang string, adjust func(opt *Options)) string {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(source), 0644))
+	opt := &Options{
+		FilePath:            file,
+		InputDir:            dir,
+		OutputDir:           filepath.Join(dir, "output"),
+		Lang:                lang,
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+	}
+	if adjust != nil {
+		adjust(opt)
+	}
+	require.NoError(t, NewParser(opt).Parse())
+	return filepath.Join(dir, "output", "schema.xsd")
+}
+
+// getWarningMessages returns the messages of the warnings.
+func getWarningMessages(warnings []Warning) []string {
+	messages := make([]string, 0, len(warnings))
+	for _, warning := range warnings {
+		messages = append(messages, warning.Message)
+	}
+	return messages
+}
+
+const validationTestSchema = `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="1"/>
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="Max35Text"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+
+func TestGenerateValidation(t *testing.T) {
+	testCases := []struct {
+		lang       string
+		validation string
+		ext        string
+		expected   []string
+		validator  []string
+	}{
+		{
+			lang:       "Rust",
+			validation: ValidationMethod,
+			ext:        ".rs",
+			expected:   []string{"impl Party {", "pub fn validate(&self) -> Result<(), ValidationError> {", "if self.nm.chars().count() > 35 {"},
+		},
+		{
+			lang:       "Rust",
+			validation: ValidationStandalone,
+			ext:        ".rs",
+			validator:  []string{"use super::*;", "pub fn validate_party(v: &Party) -> Result<(), ValidationError> {", "if v.nm.chars().count() < 1 {"},
+		},
+		{
+			lang:       "Go",
+			validation: ValidationMethod,
+			ext:        ".go",
+			expected:   []string{"func (v *Party) Validate() error {", "if utf8.RuneCountInString(v.Nm) > 35 {"},
+		},
+		{
+			lang:       "Go",
+			validation: ValidationStandalone,
+			ext:        ".go",
+			validator:  []string{"func ValidateParty(v *Party) error {", "func ValidateMax35Text(v *Max35Text) error {"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.lang+"/"+tc.validation, func(t *testing.T) {
+			file := generateFromSource(t, validationTestSchema, tc.lang, func(opt *Options) {
+				opt.Validation = tc.validation
+			})
+			generated, err := ioutil.ReadFile(file + tc.ext)
+			require.NoError(t, err)
+			for _, expected := range tc.expected {
+				assert.Contains(t, string(generated), expected)
+			}
+			validator, err := ioutil.ReadFile(file + ".validator" + tc.ext)
+			if tc.validation == ValidationMethod {
+				assert.True(t, os.IsNotExist(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.NotContains(t, string(generated), "Validat")
+			for _, expected := range tc.validator {
+				assert.Contains(t, string(validator), expected)
+			}
+		})
+	}
+}
+
+func TestGenerateLengthValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="CurrencyCode">
+    <xs:restriction base="xs:string">
+      <xs:length value="3"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Digest">
+    <xs:restriction base="xs:hexBinary">
+      <xs:length value="32"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="CodeList">
+    <xs:list itemType="xs:string"/>
+  </xs:simpleType>
+  <xs:simpleType name="CodePair">
+    <xs:restriction base="CodeList">
+      <xs:length value="2"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="Ccy" type="CurrencyCode"/>
+      <xs:element name="Dgst" type="Digest"/>
+      <xs:element name="Cds" type="CodePair"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		opt = o
+		opt.Validation = ValidationMethod
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"\tif utf8.RuneCountInString(string(*v)) != 3 {\n\t\treturn errors.New(\"CurrencyCode does not have the length of 3\")\n\t}\n",
+		"\tif len(v.Dgst)/2 != 32 {\n\t\treturn errors.New(\"Dgst does not have the length of 32\")\n\t}\n",
+		"\tif v.Cds != nil {\n\t\tif len(*v.Cds) != 2 {\n\t\t\treturn errors.New(\"Cds does not have the length of 2\")\n\t\t}\n\t}\n",
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.Validation = ValidationMethod
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"\t\tif self.ccy.chars().count() != 3 {\n\t\t\treturn Err(ValidationError::new(1016, \"ccy does not have the length of 3\".to_string()));\n\t\t}\n",
+		"\t\tif self.dgst.len() / 2 != 32 {\n",
+		"\t\tif self.cds.code_list.len() != 2 {\n",
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+
+	restriction := getRestrictionFromSimpleType("Digest", opt.ProtoTree)
+	assert.True(t, restriction.HasLength)
+	assert.True(t, restriction.Octets)
+	assert.NoError(t, restriction.Evaluate(strings.Repeat("ab", 32)))
+	assert.EqualError(t, restriction.Evaluate("abcd"), "value \"abcd\" violates length facet: length 2 is not 32")
+}
+
+func TestEvaluateFacets(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="a|ab"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="ISODate">
+    <xs:restriction base="xs:date">
+      <xs:minInclusive value="2024-01-01"/>
+      <xs:maxExclusive value="2025-01-01"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="ISODateTime">
+    <xs:restriction base="xs:dateTime">
+      <xs:minExclusive value="2024-01-01T00:00:00Z"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Delay">
+    <xs:restriction base="xs:duration">
+      <xs:maxInclusive value="P1D"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+	var opt *Options
+	generateFromSource(t, source, "Go", func(o *Options) {
+		opt = o
+	})
+
+	restriction := getRestrictionFromSimpleType("Code", opt.ProtoTree)
+	assert.NoError(t, restriction.Evaluate("a"))
+	assert.NoError(t, restriction.Evaluate("ab"))
+	assert.EqualError(t, restriction.Evaluate("abc"), "value \"abc\" violates pattern facet: does not match a|ab")
+
+	restriction = getRestrictionFromSimpleType("ISODate", opt.ProtoTree)
+	assert.NoError(t, restriction.Evaluate("2024-01-01"))
+	assert.NoError(t, restriction.Evaluate("2024-02-01"))
+	assert.NoError(t, restriction.Evaluate("2024-12-31Z"))
+	assert.EqualError(t, restriction.Evaluate("2023-12-31"), "value \"2023-12-31\" violates minInclusive facet: is less than 2024-01-01")
+	assert.EqualError(t, restriction.Evaluate("2025-01-01"), "value \"2025-01-01\" violates maxExclusive facet: is not less than 2025-01-01")
+	assert.EqualError(t, restriction.Evaluate("tomorrow"), "value \"tomorrow\" violates value facet: is not comparable with 2024-01-01")
+	facets := dumpFacets(restriction)
+	assert.Equal(t, "2024-01-01", facets.MinInclusive)
+	assert.Equal(t, "2025-01-01", facets.MaxExclusive)
+
+	restriction = getRestrictionFromSimpleType("ISODateTime", opt.ProtoTree)
+	assert.NoError(t, restriction.Evaluate("2024-01-01T00:00:01"))
+	assert.EqualError(t, restriction.Evaluate("2024-01-01T02:00:00+02:00"), "value \"2024-01-01T02:00:00+02:00\" violates minExclusive facet: is not greater than 2024-01-01T00:00:00Z")
+
+	// The durations are partially ordered, so their bounds aren't checked.
+	restriction = getRestrictionFromSimpleType("Delay", opt.ProtoTree)
+	assert.NoError(t, restriction.Evaluate("PT12H"))
+}
+
+func TestGenerateForwardReferencedFacets(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:c="urn:common">
+  <xs:import namespace="urn:common" schemaLocation="common.xsd"/>
+  <xs:include schemaLocation="included.xsd"/>
+  <xs:complexType name="Header">
+    <xs:sequence>
+      <xs:element name="MsgId" type="Max35Text"/>
+      <xs:element name="Ref" type="c:Max16Text"/>
+      <xs:element name="Cd" type="Code"/>
+    </xs:sequence>
+    <xs:attribute name="Ccy" type="CurrencyCode"/>
+  </xs:complexType>
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="1"/>
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="CurrencyCode">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]{3,3}"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+	common := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:common">
+  <xs:simpleType name="Max16Text">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="16"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+	included := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:length value="4"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		opt, o.Validation = o, ValidationMethod
+		require.NoError(t, ioutil.WriteFile(filepath.Join(o.InputDir, "common.xsd"), []byte(common), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(o.InputDir, "included.xsd"), []byte(included), 0644))
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"\tif utf8.RuneCountInString(v.MsgId) > 35 {\n\t\treturn errors.New(\"MsgId exceeds the maximum length of 35\")\n\t}\n",
+		"\tif utf8.RuneCountInString(v.Ref) > 16 {\n\t\treturn errors.New(\"Ref exceeds the maximum length of 16\")\n\t}\n",
+		"\tif utf8.RuneCountInString(v.Cd) != 4 {\n\t\treturn errors.New(\"Cd does not have the length of 4\")\n\t}\n",
+		"MatchString(v.CcyAttr) {\n\t\t\treturn errors.New(\"CcyAttr does not match the pattern [A-Z]{3,3}\")\n",
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+	header, ok := opt.ProtoTree[0].(*ComplexType)
+	require.True(t, ok)
+	assert.Equal(t, "Max35Text", header.Elements[0].TypeRef)
+	assert.Equal(t, "{urn:common}Max16Text", header.Elements[1].TypeRef)
+	assert.Equal(t, 35, header.Elements[0].Restriction.MaxLength)
+	assert.Equal(t, 16, header.Elements[1].Restriction.MaxLength)
+	assert.Equal(t, 4, header.Elements[2].Restriction.Length)
+	assert.NotNil(t, header.Attributes[0].Restriction.Pattern)
+}
+
+func TestGenerateWhiteSpaceValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:token">
+      <xs:maxLength value="5"/>
+      <xs:pattern value="[A-Z]+( [A-Z]+)?"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Line">
+    <xs:restriction base="xs:string">
+      <xs:whiteSpace value="replace"/>
+      <xs:enumeration value="A B"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Cd" type="Code"/>
+      <xs:element name="Ln" type="Line"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		opt = o
+		opt.Validation = ValidationMethod
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"\tif utf8.RuneCountInString(strings.Join(strings.Fields(v.Cd), \" \")) > 5 {\n",
+		"\tif !xsdPatterns[\"^(?:[A-Z]+( [A-Z]+)?)$\"].MatchString(strings.Join(strings.Fields(v.Cd), \" \")) {\n",
+		"\tswitch strings.NewReplacer(\"\\t\", \" \", \"\\n\", \" \", \"\\r\", \" \").Replace(v.Ln) {\n",
+		"\nfunc init() {\n\txsdPatterns[\"^(?:[A-Z]+( [A-Z]+)?)$\"] = regexp.MustCompile(\"^(?:[A-Z]+( [A-Z]+)?)$\")\n}\n",
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+	assert.Equal(t, 1, strings.Count(string(generated), "regexp.MustCompile("))
+	generated, err = ioutil.ReadFile(filepath.Join(filepath.Dir(file), "xsd_pattern.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "var xsdPatterns = map[string]*regexp.Regexp{}\n")
+	runGoPackage(t, filepath.Dir(file), "vet")
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.Validation = ValidationMethod
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"\t\tif self.cd.split_whitespace().collect::<Vec<_>>().join(\" \").chars().count() > 5 {\n",
+		".is_match(&self.cd.split_whitespace().collect::<Vec<_>>().join(\" \")) {\n",
+		"\t\tif ![\"A B\"].contains(&self.ln.replace(|c| c == '\\t' || c == '\\n' || c == '\\r', \" \").as_str()) {\n",
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+	// The patterns are compiled once, instead of on each validation.
+	assert.Equal(t, 1, strings.Count(string(generated), "Regex::new("))
+	runRustCrate(t, file+".rs", `
+	#[test]
+	fn validate() {
+		let mut party = Party { cd: "AB  CD".to_string(), ln: "A\tB".to_string() };
+		assert_eq!(party.validate(), Ok(()));
+		party.cd = "ab".to_string();
+		assert_eq!(party.validate().unwrap_err().code, 1005);
+	}
+`)
+
+	restriction := getRestrictionFromSimpleType("Code", opt.ProtoTree)
+	assert.NoError(t, restriction.Evaluate("  AB \n CD "))
+	assert.EqualError(t, restriction.Evaluate(" ABC DEF "), "value \"ABC DEF\" violates maxLength facet: length 7 is greater than 5")
+	assert.NoError(t, getRestrictionFromSimpleType("Line", opt.ProtoTree).Evaluate("A\tB"))
+}
+
+func TestGenerateListValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="4"/>
+      <xs:pattern value="[A-Z]+"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Codes">
+    <xs:list itemType="Code"/>
+  </xs:simpleType>
+  <xs:simpleType name="Scores">
+    <xs:list>
+      <xs:simpleType>
+        <xs:restriction base="xs:int">
+          <xs:minInclusive value="0"/>
+          <xs:maxInclusive value="100"/>
+        </xs:restriction>
+      </xs:simpleType>
+    </xs:list>
+  </xs:simpleType>
+  <xs:simpleType name="ThreeCodes">
+    <xs:restriction base="Codes">
+      <xs:length value="3"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="FewCodes">
+    <xs:restriction>
+      <xs:simpleType>
+        <xs:list itemType="Code"/>
+      </xs:simpleType>
+      <xs:maxLength value="5"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:element name="Document">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Cds" type="Codes"/>
+        <xs:element name="Scs" type="Scores"/>
+        <xs:element name="Tcs" type="ThreeCodes"/>
+        <xs:element name="Fcs" type="FewCodes"/>
+      </xs:sequence>
+      <xs:attribute name="tags" type="Codes"/>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		o.Validation, opt = ValidationMethod, o
+	})
+	for _, ele := range opt.ProtoTree {
+		if v, ok := ele.(*SimpleType); ok && v.Name == "Scores" {
+			assert.True(t, v.List)
+			assert.True(t, v.Restriction.IsEmpty())
+			assert.True(t, v.ItemRestriction.HasMin && v.ItemRestriction.HasMax)
+		}
+		if v, ok := ele.(*SimpleType); ok && v.Name == "FewCodes" {
+			assert.True(t, v.List)
+			assert.Equal(t, "Code", v.ItemType)
+			assert.Equal(t, 5, v.Restriction.MaxLength)
+		}
+	}
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"func (v *Codes) Validate() error {\n\tfor _, item := range *v {\n\t\tif utf8.RuneCountInString(item) > 4 {\n\t\t\treturn errors.New(\"Codes exceeds the maximum length of 4\")\n",
+		"\t\tif item > 100 {\n\t\t\treturn errors.New(\"Scores exceeds the maximum value of 100\")\n",
+		"func (v *FewCodes) Validate() error {\n\tif len(*v) > 5 {\n",
+		"\tif v.Tcs != nil {\n\t\tif len(*v.Tcs) != 3 {\n\t\t\treturn errors.New(\"Tcs does not have the length of 3\")\n\t\t}\n\t\tif err := v.Tcs.Validate(); err != nil {\n",
+		"\tif v.TagsAttr != nil {\n\t\tif err := v.TagsAttr.Validate(); err != nil {\n",
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+
+	file = generateFromSource(t, source, "Rust", func(o *Options) {
+		o.Validation = ValidationMethod
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"\tpub codes: Vec<String>,\n",
+		"\t\tfor val in &self.codes {\n\t\t\tif val.chars().count() > 4 {\n",
+		"\t\tfor val in &self.scores {\n\t\t\tif *val < 0 {\n",
+		"\t\tif self.few_codes.len() > 5 {\n\t\t\treturn Err(ValidationError::new(1002, \"few_codes exceeds the maximum length of 5\".to_string()));\n",
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+}
+
+func TestGenerateNumericEnumValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Priority">
+    <xs:restriction base="xs:int">
+      <xs:enumeration value="1"/>
+      <xs:enumeration value="01"/>
+      <xs:enumeration value="-3"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Rate">
+    <xs:restriction base="xs:decimal">
+      <xs:enumeration value="0.5"/>
+      <xs:enumeration value="1.25"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Order">
+    <xs:sequence>
+      <xs:element name="Prty" type="Priority"/>
+      <xs:element name="Rate" type="Rate" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.Validation = ValidationMethod
+		opt.ValidateTags = true
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"\tswitch v.Prty {\n\tcase 1, -3:\n\tdefault:\n\t\treturn errors.New(\"Prty is not one of 1, 01, -3\")\n\t}\n",
+		"\tif v.Rate != nil {\n\t\tswitch *v.Rate {\n\t\tcase 0.5, 1.25:\n",
+		"`xml:\"Prty\" validate:\"oneof=1 -3\"`",
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.Validation = ValidationMethod
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"\t\tif ![1, -3].contains(&self.prty) {\n\t\t\treturn Err(ValidationError::new(1006, \"prty is not one of 1, 01, -3\".to_string()));\n\t\t}\n",
+		"\t\t\tif ![0.5, 1.25].contains(&*val) {\n",
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.Validation = ValidationMethod
+		opt.RustDecimal = true
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "if ![Decimal::new(5, 1), Decimal::new(125, 2)].contains(&*val) {\n")
+}
+
+func TestParseMalformedSchema(t *testing.T) {
+	for source, expected := range map[string]string{
+		`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:simpleType name="Code"><xs:restriction base="xs:string"><xs:maxLength value="ten"/></xs:restriction></xs:simpleType></xs:schema>`: `invalid value "ten" of the maxLength facet`,
+		`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:simpleType name="Code"><xs:restriction base="xs:string"><xs:length value="-1"/></xs:restriction></xs:simpleType></xs:schema>`:     `invalid value "-1" of the length facet`,
+		`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:complexType/></xs:schema>`:                                                                                                        "malformed.xsd: unexpected element <complexType>",
+		`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema"><xs:complexType name="Party"><xs:sequence><xs:complexType/></xs:sequence></xs:complexType></xs:schema>`:                               "malformed.xsd: unexpected element <complexType>",
+		`<xs:complexType xmlns:xs="http://www.w3.org/2001/XMLSchema"/>`:                                                                                                                               "malformed.xsd: expected element type <schema> but have <complexType>",
+	} {
+		opt := &Options{
+			FilePath:            "malformed.xsd",
+			Extract:             true,
+			Source:              []byte(source),
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}
+		assert.EqualError(t, NewParser(opt).Parse(), expected)
+	}
+}
+
+func TestParseBoundFacets(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Rate">
+    <xs:restriction base="xs:decimal">
+      <xs:minInclusive value="1e-2"/>
+      <xs:maxInclusive value="100"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Ratio">
+    <xs:restriction base="xs:double">
+      <xs:minExclusive value="-INF"/>
+      <xs:maxInclusive value="1.5E2"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Score">
+    <xs:restriction base="xs:float">
+      <xs:maxExclusive value="NaN"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Serial">
+    <xs:restriction base="xs:long">
+      <xs:minInclusive value="+0001"/>
+      <xs:maxInclusive value="9223372036854775807"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Level">
+    <xs:restriction base="Serial">
+      <xs:maxExclusive value="1.5"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Since">
+    <xs:restriction base="xs:date">
+      <xs:minInclusive value="2024-01-01"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:element name="Document">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Rt" type="Rate"/>
+        <xs:element name="Ro" type="Ratio"/>
+        <xs:element name="Nb" type="Serial"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		o.Validation, opt = ValidationMethod, o
+	})
+	assert.Equal(t, []Warning{
+		{File: opt.FilePath, Line: 4, Message: `ignored the minInclusive facet of Rate: invalid xs:decimal value "1e-2"`},
+		{File: opt.FilePath, Line: 16, Message: "ignored the maxExclusive facet of Score: the NaN value isn't comparable"},
+		{File: opt.FilePath, Line: 27, Message: `ignored the maxExclusive facet of Level: invalid xs:long value "1.5"`},
+	}, opt.Warnings)
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"\t\"math\"\n",
+		"if float64(v.Ro) <= math.Inf(-1) {",
+		"if v.Ro > 150 {",
+		"if v.Nb > 9223372036854775807 {",
+		`errors.New("Nb is less than the minimum value of 1")`,
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+	assert.NotContains(t, string(generated), "if v.Rt < ")
+
+	file = generateFromSource(t, source, "Rust", func(o *Options) {
+		o.Validation = ValidationMethod
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "if self.ro <= f64::NEG_INFINITY {")
+	assert.Contains(t, string(generated), "if self.nb > 9223372036854775807 {")
+
+	for _, ele := range opt.ProtoTree {
+		if v, ok := ele.(*SimpleType); ok && v.Name == "Serial" {
+			assert.NoError(t, v.Restriction.Evaluate("9223372036854775807"))
+			assert.EqualError(t, v.Restriction.Evaluate("9223372036854775808"), `value "9223372036854775808" violates maxInclusive facet: is greater than 9223372036854775807`)
+		}
+		if v, ok := ele.(*SimpleType); ok && v.Name == "Since" {
+			assert.True(t, v.Restriction.HasMin)
+			assert.Equal(t, FacetValue{Lexical: "2024-01-01"}, v.Restriction.MinValue)
+		}
+	}
+}
+
+func TestParseTypedFacetValues(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal">
+      <xs:minExclusive value="-12345678901234567890.5"/>
+      <xs:maxInclusive value="12345678901234567890.50"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Counter">
+    <xs:restriction base="xs:unsignedLong">
+      <xs:maxInclusive value="18446744073709551615"/>
+      <xs:enumeration value="9007199254740993"/>
+      <xs:enumeration value="18446744073709551615"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:element name="Document">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Amt" type="Amount"/>
+        <xs:element name="Ctr" type="Counter"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		o.Validation, o.JSONSchemas, opt = ValidationMethod, true, o
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, expected := range []string{
+		"if v.Amt <= -12345678901234567890.5 {",
+		`errors.New("Amt exceeds the maximum value of 12345678901234567890.5")`,
+		"if v.Ctr > 18446744073709551615 {",
+		"case 9007199254740993, 18446744073709551615:",
+		`"maximum": 18446744073709551615`,
+		`"exclusiveMinimum": -12345678901234567890.5`,
+	} {
+		assert.Contains(t, string(generated), expected)
+	}
+
+	file = generateFromSource(t, source, "Rust", func(o *Options) {
+		o.Validation, o.RustDecimal = ValidationMethod, true
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), `if self.amt > Decimal::from_str_exact("12345678901234567890.5").unwrap() {`)
+	assert.Contains(t, string(generated), "if ![9007199254740993, 18446744073709551615].contains(&self.ctr) {")
+
+	amount := getRestrictionFromSimpleType("Amount", opt.ProtoTree)
+	assert.Equal(t, FacetDecimal, amount.MaxValue.Kind)
+	assert.NoError(t, amount.Evaluate("12345678901234567890.5"))
+	assert.EqualError(t, amount.Evaluate("12345678901234567890.6"), `value "12345678901234567890.6" violates maxInclusive facet: is greater than 12345678901234567890.5`)
+	counter := getRestrictionFromSimpleType("Counter", opt.ProtoTree)
+	assert.Equal(t, FacetInteger, counter.MaxValue.Kind)
+	assert.Equal(t, "18446744073709551615", counter.MaxValue.String())
+	valid, invalid := genFacetVectors(counter)
+	assert.Contains(t, valid, "18446744073709551615")
+	assert.Contains(t, invalid, "18446744073709551616")
+}
+
+func TestGenerateNormalize(t *testing.T) {
+	source := strings.Replace(validationTestSchema, `<xs:maxLength value="35"/>`, `<xs:maxLength value="35"/>
+      <xs:whiteSpace value="collapse"/>`, 1)
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.Normalize = true
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "func (v *Max35Text) Normalize() {\n\t*v = Max35Text(strings.Join(strings.Fields(string(*v)), \" \"))\n}")
+	assert.Contains(t, string(generated), "v.Nm = strings.Join(strings.Fields(v.Nm), \" \")")
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.Normalize = true
+		opt.Validation = ValidationStandalone
+	})
+	validator, err := ioutil.ReadFile(file + ".validator.rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(validator), "pub fn normalize_party(v: &mut Party) {")
+	assert.Contains(t, string(validator), "v.max35_text = v.max35_text.split_whitespace().collect::<Vec<_>>().join(\" \");")
+}
+
+func TestGenerateUniqueValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="PaymentIdentification">
+    <xs:sequence>
+      <xs:element name="EndToEndId" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="CreditTransferTransaction">
+    <xs:sequence>
+      <xs:element name="PmtId" type="PaymentIdentification"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="PaymentInstruction">
+    <xs:sequence>
+      <xs:element name="CdtTrfTxInf" type="CreditTransferTransaction" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="PmtInf" type="PaymentInstruction" maxOccurs="unbounded">
+        <xs:unique name="UniqueEndToEndId">
+          <xs:selector xpath="CdtTrfTxInf"/>
+          <xs:field xpath="PmtId/EndToEndId"/>
+        </xs:unique>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		o.Validation = ValidationMethod
+		opt = o
+	})
+	assert.Equal(t, []*Unique{{Name: "UniqueEndToEndId", Type: "PaymentInstruction", Selector: "CdtTrfTxInf", Fields: []string{"PmtId/EndToEndId"}}}, getUniqueConstraints("PaymentInstruction", opt.ProtoTree))
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "if item == nil || item.PmtId == nil {")
+	assert.Contains(t, string(generated), "key := [1]interface{}{item.PmtId.EndToEndId}")
+	assert.Contains(t, string(generated), `return fmt.Errorf("CdtTrfTxInf[%d] duplicates CdtTrfTxInf[%d] on unique constraint UniqueEndToEndId", i, j)`)
+
+	file = generateFromSource(t, source, "Rust", func(o *Options) {
+		o.Validation = ValidationMethod
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "for (i, val) in self.cdt_trf_tx_inf.iter().enumerate() {")
+	assert.Contains(t, string(generated), "if let Some(f0) = Some(val).map(|x| &x.pmt_id).map(|x| &x.end_to_end_id) {")
+}
+
+func TestGenerateOptionalZeroValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Count">
+    <xs:restriction base="xs:int">
+      <xs:minInclusive value="1"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="NbOfTxs" type="Count" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="Seq" type="Count"/>
+  </xs:complexType>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="Pty" type="Party" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="Document" type="Document">
+    <xs:unique name="UniqueSeq">
+      <xs:selector xpath="Pty"/>
+      <xs:field xpath="@Seq"/>
+    </xs:unique>
+  </xs:element>
+</xs:schema>`
+	// The optional numeric fields are pointers, so the explicit zero is
+	// checked against the facets and is a value of the unique constraint.
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.Validation = ValidationMethod
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tSeqAttr *int `xml:\"Seq,attr,omitempty\"`\n")
+	assert.Contains(t, string(generated), "\tNbOfTxs *int `xml:\"NbOfTxs\"`\n")
+	assert.Contains(t, string(generated), "\tif v.NbOfTxs != nil {\n\t\tif *v.NbOfTxs < 1 {\n")
+	assert.Contains(t, string(generated), "if item == nil || item.SeqAttr == nil {")
+	assert.Contains(t, string(generated), "key := [1]interface{}{*item.SeqAttr}")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(filepath.Dir(file), "schema_test.go"), []byte(`package schema
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestOptionalZero(t *testing.T) {
+	var party Party
+	if err := xml.Unmarshal([]byte("<Party/>"), &party); err != nil || party.Validate() != nil {
+		t.Error("expected the absent fields to be valid")
+	}
+	if err := xml.Unmarshal([]byte("<Party Seq=\"0\"/>"), &party); err != nil || party.Validate() == nil {
+		t.Error("expected the explicit zero attribute to be invalid")
+	}
+	if err := xml.Unmarshal([]byte("<Party><NbOfTxs>0</NbOfTxs></Party>"), &party); err != nil || party.Validate() == nil {
+		t.Error("expected the explicit zero element to be invalid")
+	}
+	one := 1
+	doc := Document{Pty: []*Party{{}, {}, {SeqAttr: &one}}}
+	if err := doc.Validate(); err != nil {
+		t.Error(err)
+	}
+	doc.Pty = append(doc.Pty, &Party{SeqAttr: &one})
+	if err := doc.Validate(); err == nil || err.Error() != "Pty[3] duplicates Pty[2] on unique constraint UniqueSeq" {
+		t.Error(err)
+	}
+}
+`), 0644))
+	runGoPackage(t, filepath.Dir(file), "test")
+}
+
+func TestGenerateKeyValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Id" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Transaction">
+    <xs:sequence>
+      <xs:element name="PtyId" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Ledger">
+    <xs:sequence>
+      <xs:element name="Pty" type="Party" maxOccurs="unbounded"/>
+      <xs:element name="Tx" type="Transaction" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="Ldgr" type="Ledger">
+        <xs:key name="PartyKey">
+          <xs:selector xpath="Pty"/>
+          <xs:field xpath="Id"/>
+        </xs:key>
+        <xs:keyref name="PartyRef" refer="PartyKey">
+          <xs:selector xpath="Tx"/>
+          <xs:field xpath="PtyId"/>
+        </xs:keyref>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		o.Validation = ValidationMethod
+		opt = o
+	})
+	assert.Equal(t, []*Unique{
+		{Name: "PartyKey", Type: "Ledger", Selector: "Pty", Fields: []string{"Id"}, Key: true},
+		{Name: "PartyRef", Type: "Ledger", Selector: "Tx", Fields: []string{"PtyId"}, Refer: "PartyKey"},
+	}, getUniqueConstraints("Ledger", opt.ProtoTree))
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"\t\tif item == nil {\n\t\t\tcontinue\n\t\t}\n\t\tif item.Id == \"\" {\n\t\t\treturn fmt.Errorf(\"Pty[%d] misses the fields of key constraint PartyKey\", i)\n\t\t}\n",
+		"return fmt.Errorf(\"Pty[%d] duplicates Pty[%d] on key constraint PartyKey\", i, j)",
+		"\tkeysPartyRef := make(map[[1]interface{}]bool)\n\tfor _, item := range v.Pty {\n\t\tif item == nil || item.Id == \"\" {\n\t\t\tcontinue\n\t\t}\n\t\tkeysPartyRef[[1]interface{}{item.Id}] = true\n\t}\n",
+		"\t\tif !keysPartyRef[[1]interface{}{item.PtyId}] {\n\t\t\treturn fmt.Errorf(\"Tx[%d] refers to no Pty on keyref constraint PartyRef\", i)\n\t\t}\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+
+	file = generateFromSource(t, source, "Rust", func(o *Options) {
+		o.Validation = ValidationMethod
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"\t\t\t} else {\n\t\t\t\treturn Err(ValidationError::new(1014, format!(\"pty[{}] misses the fields of key constraint PartyKey\", i)));\n\t\t\t}\n",
+		"\t\tlet mut keys_party_ref = std::collections::HashSet::new();\n\t\tfor val in self.pty.iter() {\n",
+		"\t\t\t\tif !keys_party_ref.contains(&format!(\"{:?}\", f0)) {\n\t\t\t\t\treturn Err(ValidationError::new(1015, format!(\"tx[{}] refers to no pty on keyref constraint PartyRef\", i)));\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+}
+
+func TestGenerateNestedKeyValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="PaymentIdentification">
+    <xs:sequence>
+      <xs:element name="EndToEndId" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Transaction">
+    <xs:sequence>
+      <xs:element name="PmtId" type="PaymentIdentification"/>
+      <xs:element name="Ref" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="Seq" type="xs:string"/>
+  </xs:complexType>
+  <xs:complexType name="Header">
+    <xs:sequence>
+      <xs:element name="Id" type="xs:string" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="GroupHeader">
+    <xs:sequence>
+      <xs:element name="Hdr" type="Header" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="PaymentInstruction">
+    <xs:sequence>
+      <xs:element name="Tx" type="Transaction" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="GrpHdr" type="GroupHeader"/>
+      <xs:element name="PmtInf" type="PaymentInstruction" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="Document" type="Document">
+    <xs:unique name="UniqueEndToEndId">
+      <xs:selector xpath="PmtInf/Tx"/>
+      <xs:field xpath="PmtId/EndToEndId"/>
+    </xs:unique>
+    <xs:key name="HeaderKey">
+      <xs:selector xpath="GrpHdr/Hdr/Id"/>
+      <xs:field xpath="."/>
+    </xs:key>
+    <xs:keyref name="HeaderRef" refer="HeaderKey">
+      <xs:selector xpath="PmtInf/Tx"/>
+      <xs:field xpath="Ref"/>
+    </xs:keyref>
+    <xs:unique name="UniqueRef">
+      <xs:selector xpath=".//Tx"/>
+      <xs:field xpath="Ref"/>
+    </xs:unique>
+    <xs:unique name="UniqueSeq">
+      <xs:selector xpath="PmtInf/Tx/@Seq"/>
+      <xs:field xpath="."/>
+    </xs:unique>
+  </xs:element>
+</xs:schema>`
+	// The selectors stepping through the nested elements select them in
+	// document order, and the unsupported selectors are warned about.
+	warnings := []string{
+		"skipped the validation of the unsupported identity constraint unique UniqueRef of Document: unsupported selector .//Tx",
+		"skipped the validation of the unsupported identity constraint unique UniqueSeq of Document: unsupported selector PmtInf/Tx/@Seq",
+	}
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		o.Validation = ValidationMethod
+		opt = o
+	})
+	assert.Equal(t, warnings, getWarningMessages(opt.Warnings))
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"\tvar selectedUniqueEndToEndId []*Transaction\n\tfor _, item := range v.PmtInf {\n\t\tif item != nil {\n\t\t\tselectedUniqueEndToEndId = append(selectedUniqueEndToEndId, item.Tx...)\n\t\t}\n\t}\n",
+		"\tvar selectedHeaderKey []string\n\tif item := v.GrpHdr; item != nil {\n\t\tif item := item.Hdr; item != nil {\n",
+		"return fmt.Errorf(\"PmtInf/Tx[%d] duplicates PmtInf/Tx[%d] on unique constraint UniqueEndToEndId\", i, j)",
+		"\tvar referredHeaderRef []string\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+	assert.NotContains(t, string(generated), "UniqueRef")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(filepath.Dir(file), "schema_test.go"), []byte(`package schema
+
+import (
+	"encoding/xml"
+	"testing"
+)
+
+func TestNestedKey(t *testing.T) {
+	for source, valid := range map[string]bool{
+		"<Document><GrpHdr><Hdr><Id>A</Id></Hdr></GrpHdr><PmtInf><Tx><PmtId><EndToEndId>1</EndToEndId></PmtId><Ref>A</Ref></Tx></PmtInf><PmtInf><Tx><PmtId><EndToEndId>2</EndToEndId></PmtId></Tx></PmtInf></Document>": true,
+		"<Document><GrpHdr/><PmtInf><Tx><PmtId><EndToEndId>1</EndToEndId></PmtId></Tx></PmtInf><PmtInf><Tx><PmtId><EndToEndId>1</EndToEndId></PmtId></Tx></PmtInf></Document>":                                   false,
+		"<Document><GrpHdr><Hdr><Id>A</Id><Id>A</Id></Hdr></GrpHdr></Document>":                                                                                                                                     false,
+		"<Document><GrpHdr><Hdr><Id>A</Id></Hdr></GrpHdr><PmtInf><Tx><PmtId><EndToEndId>1</EndToEndId></PmtId><Ref>B</Ref></Tx></PmtInf></Document>":                                                             false,
+	} {
+		var doc Document
+		if err := xml.Unmarshal([]byte(source), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := doc.Validate(); (err == nil) != valid {
+			t.Errorf("unexpected validation of %s: %v", source, err)
+		}
+	}
+}
+`), 0644))
+	runGoPackage(t, filepath.Dir(file), "test")
+
+	file = generateFromSource(t, source, "Rust", func(o *Options) {
+		o.Validation = ValidationMethod
+		opt = o
+	})
+	assert.Equal(t, warnings, getWarningMessages(opt.Warnings))
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"\t\tfor (i, val) in self.pmt_inf.iter().flat_map(|x| x.tx.iter()).enumerate() {\n",
+		"\t\tfor (i, val) in std::iter::once(&self.grp_hdr).flat_map(|x| x.hdr.iter()).flat_map(|x| x.id.iter()).enumerate() {\n",
+		"format!(\"pmt_inf/tx[{}] refers to no grp_hdr/hdr/id on keyref constraint HeaderRef\", i)",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+	runRustCrate(t, file+".rs", `
+	#[test]
+	fn validate_nested_key() {
+		for (source, valid) in [
+			("<Document><GrpHdr><Hdr><Id>A</Id></Hdr></GrpHdr><PmtInf><Tx><PmtId><EndToEndId>1</EndToEndId></PmtId><Ref>A</Ref></Tx></PmtInf><PmtInf><Tx><PmtId><EndToEndId>2</EndToEndId></PmtId></Tx></PmtInf></Document>", true),
+			("<Document><GrpHdr/><PmtInf><Tx><PmtId><EndToEndId>1</EndToEndId></PmtId></Tx></PmtInf><PmtInf><Tx><PmtId><EndToEndId>1</EndToEndId></PmtId></Tx></PmtInf></Document>", false),
+			("<Document><GrpHdr><Hdr><Id>A</Id></Hdr></GrpHdr><PmtInf><Tx><PmtId><EndToEndId>1</EndToEndId></PmtId><Ref>B</Ref></Tx></PmtInf></Document>", false),
+		] {
+			let doc: Document = quick_xml::de::from_str(source).unwrap();
+			assert_eq!(doc.validate().is_ok(), valid, "{}", source);
+		}
+	}
+`)
+}
+
+func TestGenerateDepthLimitedValidation(t *testing.T) {
+	file := generateFromSource(t, validationTestSchema, "Go", func(opt *Options) {
+		opt.Validation = ValidationMethod
+		opt.ValidationMaxDepth = 16
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "func (v *Party) Validate() error {\n\treturn v.validate(0)\n}")
+	assert.Contains(t, string(generated), "func (v *Party) validate(depth int) error {\n\tif depth > 16 {\n\t\treturn ErrValidationDepth\n\t}")
+	depthError, err := ioutil.ReadFile(filepath.Join(filepath.Dir(file), "validation_depth.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(depthError), "var ErrValidationDepth = errors.New(")
+
+	file = generateFromSource(t, validationTestSchema, "Rust", func(opt *Options) {
+		opt.Validation = ValidationStandalone
+		opt.ValidationMaxDepth = 16
+	})
+	validator, err := ioutil.ReadFile(file + ".validator.rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(validator), "pub fn validate_party(v: &Party) -> Result<(), ValidationError> {\n\tvalidate_party_depth(v, 0)\n}")
+	assert.Contains(t, string(validator), "return Err(ValidationError::new(1008, \"party exceeds the maximum validation depth of 16\".to_string()));")
+}
+
+func TestGenerateValidationTracing(t *testing.T) {
+	file := generateFromSource(t, validationTestSchema, "Go", func(opt *Options) {
+		opt.Validation = ValidationMethod
+		opt.ValidationTracing = true
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "func (v *Party) Validate() (err error) {\n\tdefer traceValidation(\"Party\")(&err)\n")
+	trace, err := ioutil.ReadFile(filepath.Join(filepath.Dir(file), "validation_trace.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(trace), "//go:build xgen_trace\n// +build xgen_trace\n")
+	assert.Contains(t, string(trace), "func SetValidationHook(hook ValidationHook) {")
+	traceOff, err := ioutil.ReadFile(filepath.Join(filepath.Dir(file), "validation_trace_off.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(traceOff), "//go:build !xgen_trace\n// +build !xgen_trace\n")
+
+	file = generateFromSource(t, validationTestSchema, "Rust", func(opt *Options) {
+		opt.Validation = ValidationStandalone
+		opt.ValidationMaxDepth = 16
+		opt.ValidationTracing = true
+	})
+	validator, err := ioutil.ReadFile(file + ".validator.rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(validator), "fn validate_party_depth(v: &Party, depth: usize) -> Result<(), ValidationError> {\n\ttrace_validation(\"Party\", || {\n\t\tif depth > 16 {\n")
+	assert.Contains(t, string(validator), "#[cfg(feature = \"xgen-trace\")]\npub fn set_validation_hook(hook: ValidationHook) -> Result<(), ValidationHook> {")
+	assert.Contains(t, string(validator), "#[cfg(not(feature = \"xgen-trace\"))]\n#[inline(always)]\npub(crate) fn trace_validation(")
+}
+
+func TestGenerateRenameCase(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="GrpHdr" type="xs:string"/>
+      <xs:element name="BICFI" type="xs:string"/>
+      <xs:element name="IBANNumber" type="xs:string"/>
+      <xs:element name="ctry-sub-dvsn" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="Ccy" type="xs:string"/>
+  </xs:complexType>
+</xs:schema>`
+	for renameCase, renames := range map[string][]string{
+		RenameCaseSchema: {"GrpHdr", "BICFI", "IBANNumber", "ctry-sub-dvsn", "Ccy"},
+		RenameCaseCamel:  {"grpHdr", "bicfi", "ibanNumber", "ctrySubDvsn", "ccy"},
+		RenameCasePascal: {"GrpHdr", "BICFI", "IBANNumber", "CtrySubDvsn", "Ccy"},
+		RenameCaseSnake:  {"grp_hdr", "bicfi", "iban_number", "ctry_sub_dvsn", "ccy"},
+	} {
+		file := generateFromSource(t, source, "Rust", func(opt *Options) {
+			opt.RenameCase = renameCase
+		})
+		generated, err := ioutil.ReadFile(file + ".rs")
+		require.NoError(t, err)
+		for _, rename := range renames {
+			assert.Contains(t, string(generated), fmt.Sprintf("#[serde(rename = \"%s\")]", rename), renameCase)
+		}
+	}
+	assert.EqualError(t, checkRenameCase("kebab"), "unsupport rename case kebab, expected camel, pascal or snake")
+}
+
+func TestGeneratePluralNames(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Statement">
+    <xs:sequence>
+      <xs:element name="CdtTrfTxInf" type="xs:string" maxOccurs="unbounded"/>
+      <xs:element name="Ntry" type="xs:string" maxOccurs="unbounded"/>
+      <xs:element name="Child" type="xs:string" maxOccurs="unbounded"/>
+      <xs:element name="BIC" type="xs:string" maxOccurs="2"/>
+      <xs:element name="Ustrd" type="xs:string" maxOccurs="unbounded"/>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	overrides, err := ParsePluralOverrides("Ustrd=Unstructured")
+	require.NoError(t, err)
+	adjust := func(opt *Options) {
+		opt.PluralNames = true
+		opt.PluralOverrides = overrides
+	}
+	file := generateFromSource(t, source, "Go", adjust)
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, field := range []string{"CdtTrfTxInfs []string `xml:\"CdtTrfTxInf\"`", "Ntries       []string `xml:\"Ntry\"`", "Children     []string `xml:\"Child\"`", "BICs         []string `xml:\"BIC\"`", "Unstructured []string `xml:\"Ustrd\"`", "Nm           string   `xml:\"Nm\"`"} {
+		assert.Contains(t, string(generated), field)
+	}
+
+	file = generateFromSource(t, source, "Rust", adjust)
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, field := range []string{"#[serde(rename = \"CdtTrfTxInf\")]\n\tpub cdt_trf_tx_infs: Vec<String>,", "#[serde(rename = \"BIC\")]\n\tpub bics: Vec<String>,", "#[serde(rename = \"Ustrd\")]\n\tpub unstructured: Vec<String>,", "#[serde(rename = \"Nm\")]\n\tpub nm: String,"} {
+		assert.Contains(t, string(generated), field)
+	}
+
+	for name, plural := range map[string]string{"Tx": "Txs", "Amounts": "Amounts", "Address": "Addresses", "PstlAdr": "PstlAdrs", "Index": "Indices", "ctry-sub-dvsn": "ctry-sub-dvsns"} {
+		assert.Equal(t, plural, pluralize(name))
+	}
+	_, err = ParsePluralOverrides("Ustrd")
+	assert.EqualError(t, err, "invalid plural override Ustrd, expected <name>=<plural>")
+}
+
+func TestGenerateOptionalOverrides(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:element name="Ctry" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:string"/>
+  </xs:complexType>
+  <xs:complexType name="Agent">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:group name="Addr">
+    <xs:sequence>
+      <xs:element name="TwnNm" type="xs:string"/>
+    </xs:sequence>
+  </xs:group>
+</xs:schema>`
+	overrides, err := ParseOptionalOverrides("Nm=optional,Agent.Nm=required,Ctry=required,Party.id=required,Addr.TwnNm=optional")
+	require.NoError(t, err)
+	adjust := func(opt *Options) { opt.OptionalOverrides = overrides }
+
+	generated, err := ioutil.ReadFile(generateFromSource(t, source, "Rust", adjust) + ".rs")
+	require.NoError(t, err)
+	for _, field := range []string{
+		"pub struct Party {\n\t#[serde(rename = \"id\")]\n\tpub id: String,\n\t#[serde(rename = \"Nm\")]\n\tpub nm: Option<String>,\n\t#[serde(rename = \"Ctry\")]\n\tpub ctry: String,\n}",
+		"pub struct Agent {\n\t#[serde(rename = \"Nm\")]\n\tpub nm: String,\n}",
+		"pub struct Addr {\n\t#[serde(rename = \"TwnNm\")]\n\tpub twn_nm: Option<String>,\n}",
+	} {
+		assert.Contains(t, string(generated), field)
+	}
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "Go", adjust) + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "IdAttr string `xml:\"id,attr\"`")
+
+	assert.Equal(t, "Agent.Nm=required,Nm=optional", formatOptionalOverrides(map[string]bool{"Nm": true, "Agent.Nm": false}))
+	_, err = ParseOptionalOverrides("Nm=maybe")
+	assert.EqualError(t, err, "invalid optional override Nm=maybe, expected <name>=optional or <name>=required")
+}
+
+func TestGenerateDefaults(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Price">
+    <xs:sequence>
+      <xs:element name="Amt" type="xs:decimal" default="1"/>
+      <xs:element name="Qty" type="xs:int" default="3" minOccurs="0"/>
+      <xs:element name="Lbl" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="ccy" type="xs:string" fixed="EUR"/>
+    <xs:attribute name="taxed" type="xs:boolean" default="true" use="required"/>
+  </xs:complexType>
+  <xs:complexType name="Note">
+    <xs:sequence>
+      <xs:element name="Txt" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	generated, err := ioutil.ReadFile(generateFromSource(t, source, "Rust", nil) + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"#[derive(Debug, PartialEq, Clone, Serialize, Deserialize)]\npub struct Price {\n\t/// Fixed to \"EUR\" by the schema.\n\t#[serde(rename = \"ccy\")]\n\tpub ccy: Option<String>,\n\t/// Defaults to \"true\" by the schema.\n",
+		"impl Default for Price {\n\tfn default() -> Self {\n\t\tSelf {\n\t\t\tccy: Some(\"EUR\".to_string()),\n\t\t\ttaxed: true,\n\t\t\tamt: 1.0,\n\t\t\tqty: Some(3),\n\t\t\tlbl: Default::default(),\n\t\t}\n\t}\n}",
+		"impl Price {\n\tpub const FIXED_CCY: &'static str = \"EUR\";\n}",
+		"#[derive(Debug, Default, PartialEq, Clone, Serialize, Deserialize)]\npub struct Note {",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+
+	value, ok := genRustValueLiteral("Option<f32>", `"-INF"`, false)
+	assert.True(t, ok)
+	assert.Equal(t, "Some(f32::NEG_INFINITY)", value)
+	_, ok = genRustValueLiteral("u8", `"-1"`, false)
+	assert.False(t, ok)
+}
+
+func TestGenerateNotations(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:notation name="gif" public="image/gif" system="viewer.exe">
+    <xs:annotation>
+      <xs:documentation>the GIF image format.</xs:documentation>
+    </xs:annotation>
+  </xs:notation>
+  <xs:notation name="jpeg" public="image/jpeg"/>
+  <xs:notation name="png" public="image/png"/>
+  <xs:simpleType name="ImageFormat">
+    <xs:restriction base="xs:NOTATION">
+      <xs:enumeration value="gif"/>
+      <xs:enumeration value="jpeg"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Picture">
+    <xs:attribute name="format" type="ImageFormat" use="required"/>
+  </xs:complexType>
+  <xs:element name="Picture" type="Picture"/>
+</xs:schema>`
+	prune := func(opt *Options) { opt.PruneUnused = true }
+	generated, err := ioutil.ReadFile(generateFromSource(t, source, "Go", prune) + ".go")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"// GifNotation is the GIF image format.\nconst GifNotation = \"gif\"",
+		"const GifNotationPublicID = \"image/gif\"",
+		"const GifNotationSystemID = \"viewer.exe\"",
+		"const JpegNotation = \"jpeg\"",
+		"FormatAttr string `xml:\"format,attr\"`",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+	assert.NotContains(t, string(generated), "PngNotation")
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "Rust", nil) + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"pub const GIF_NOTATION: &str = \"gif\";",
+		"pub const JPEG_NOTATION_PUBLIC_ID: &str = \"image/jpeg\";",
+		"pub const PNG_NOTATION: &str = \"png\";",
+		"pub format: String,",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "Java", nil) + ".java")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "public final class GifNotation {\n\tpublic static final String NAME = \"gif\";\n\tpublic static final String PUBLIC_ID = \"image/gif\";\n\tpublic static final String SYSTEM_ID = \"viewer.exe\";\n\n\tprivate GifNotation() {\n\t}\n}")
+
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(source), 0644))
+	dump, err := NewParser(&Options{
+		FilePath:            file,
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+	}).Dump()
+	require.NoError(t, err)
+	assert.Equal(t, Declaration{Kind: KindNotation, Name: "jpeg", Public: "image/jpeg"}, dump.Declarations[1])
+}
+
+func TestParseDecoderOptions(t *testing.T) {
+	source := `<?xml version="1.0"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Nm">
+    <xs:annotation>
+      <xs:documentation>Nom du cr` + "\xe9" + `ancier, version &ver;.</xs:documentation>
+    </xs:annotation>
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+</xs:schema>`
+	generated, err := ioutil.ReadFile(generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.Charset, opt.Entities = "ISO-8859-1", map[string]string{"ver": "2"}
+	}) + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "// Nm is Nom du cr\u00e9ancier, version 2.\ntype Nm string")
+
+	parse := func(adjust func(opt *Options)) error {
+		dir, err := ioutil.TempDir("", "xgen-*")
+		require.NoError(t, err)
+		defer os.RemoveAll(dir)
+		file := filepath.Join(dir, "schema.xsd")
+		require.NoError(t, ioutil.WriteFile(file, []byte(source), 0644))
+		opt := &Options{
+			FilePath:            file,
+			Extract:             true,
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}
+		adjust(opt)
+		return NewParser(opt).Parse()
+	}
+	assert.Contains(t, fmt.Sprint(parse(func(opt *Options) { opt.Charset = "ISO-8859-1" })), "invalid character entity &ver;")
+	assert.NoError(t, parse(func(opt *Options) { opt.Charset, opt.NonStrict = "ISO-8859-1", true }))
+	assert.Contains(t, fmt.Sprint(parse(func(opt *Options) { opt.NonStrict, opt.MaxTokens = true, 8 })), "the document exceeds the maximum of 8 tokens")
+	assert.Contains(t, fmt.Sprint(parse(func(opt *Options) { opt.NonStrict, opt.MaxDepth = true, 3 })), "the document exceeds the maximum depth of 3 elements")
+	assert.Contains(t, fmt.Sprint(parse(func(opt *Options) { opt.Charset = "EBCDIC-X" })), "unsupported charset")
+
+	entities, err := ParseEntities("ver=2,copy=\u00a9")
+	require.NoError(t, err)
+	assert.Equal(t, "copy=\u00a9,ver=2", formatEntities(entities))
+	_, err = ParseEntities("ver")
+	assert.EqualError(t, err, "invalid entity ver, expected <name>=<value>")
+}
+
+func TestParseRemoteSchemas(t *testing.T) {
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = append(fetched, r.URL.Path)
+		switch r.URL.Path {
+		case "/schemas/b.xsd":
+			fmt.Fprint(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:c="urn:c" targetNamespace="urn:b">
+  <xs:import namespace="urn:c" schemaLocation="common/c.xsd"/>
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+  <xs:element name="Ccy" type="c:Code"/>
+</xs:schema>`)
+		case "/schemas/common/c.xsd":
+			fmt.Fprint(w, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:c">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+</xs:schema>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "a.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(fmt.Sprintf(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:b="urn:b" targetNamespace="urn:a">
+  <xs:import namespace="urn:b" schemaLocation="%s/schemas/b.xsd"/>
+  <xs:complexType name="Price">
+    <xs:sequence>
+      <xs:element name="Amt" type="b:Amount"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`, server.URL)), 0644))
+	generate := func(adjust func(opt *Options)) (map[string][]byte, error) {
+		opt := &Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "out"),
+			Lang:                "Go",
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}
+		adjust(opt)
+		return opt.Generate()
+	}
+	cacheDir := filepath.Join(dir, "cache")
+	artifacts, err := generate(func(opt *Options) { opt.RemoteSchemaDir = cacheDir })
+	require.NoError(t, err)
+	assert.Contains(t, string(artifacts["a.xsd.go"]), "\tAmt float64 `xml:\"Amt\"`\n")
+	assert.Equal(t, []string{"/schemas/b.xsd", "/schemas/common/c.xsd"}, fetched)
+	host := strings.TrimPrefix(server.URL, "http://")
+	for _, name := range []string{"b.xsd", "common/c.xsd"} {
+		_, err = os.Stat(filepath.Join(cacheDir, "http", host, "schemas", filepath.FromSlash(name)))
+		assert.NoError(t, err)
+	}
+
+	server.Close()
+	artifacts, err = generate(func(opt *Options) { opt.RemoteSchemaDir, opt.Offline = cacheDir, true })
+	require.NoError(t, err)
+	assert.Contains(t, string(artifacts["a.xsd.go"]), "\tAmt float64 `xml:\"Amt\"`\n")
+	assert.Len(t, fetched, 2)
+	_, err = generate(func(opt *Options) { opt.RemoteSchemaDir, opt.Offline = filepath.Join(dir, "empty"), true })
+	assert.EqualError(t, err, fmt.Sprintf("the remote schema %s/schemas/b.xsd isn't cached in %s and can't be fetched offline", server.URL, filepath.Join(dir, "empty")))
+	artifacts, err = generate(func(opt *Options) {})
+	require.NoError(t, err)
+	assert.Contains(t, string(artifacts["a.xsd.go"]), "\tAmt *Amount `xml:\"Amt\"`\n")
+}
+
+func TestGetRemoteSchemaPath(t *testing.T) {
+	opt := &Options{RemoteSchemaDir: "cache"}
+	for URL, expected := range map[string]string{
+		"http://example.com/a/../../../b.xsd": "cache/http/example.com/b.xsd",
+		"https://example.com/schemas/":        "cache/https/example.com/schemas/index.xsd",
+		"http://example.com:8080":             "cache/http/example.com:8080/index.xsd",
+	} {
+		u, err := url.Parse(URL)
+		require.NoError(t, err)
+		file, err := opt.getRemoteSchemaPath(u)
+		assert.NoError(t, err, URL)
+		assert.Equal(t, filepath.FromSlash(expected), file, URL)
+	}
+	for _, u := range []*url.URL{
+		{Scheme: "http", Host: "..", Path: "/b.xsd"},
+		{Scheme: "http", Host: ".", Path: "/b.xsd"},
+		{Scheme: "http", Path: "/b.xsd"},
+	} {
+		_, err := opt.getRemoteSchemaPath(u)
+		assert.EqualError(t, err, fmt.Sprintf("the remote schema %s can't be cached in cache", u))
+	}
+}
+
+func TestGenerateAttributeUse(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="1"/>
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Acct">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="Max35Text" use="required"/>
+    <xs:attribute name="rank" type="xs:int" use="optional"/>
+    <xs:attribute name="legacy" type="xs:string" use="prohibited"/>
+    <xs:attribute name="ccy" type="xs:string" use="required" default="EUR"/>
+    <xs:attribute name="alias" type="xs:string" use="required"/>
+  </xs:complexType>
+</xs:schema>`
+	adjust := func(opt *Options) { opt.Validation = ValidationMethod }
+	generated, err := ioutil.ReadFile(generateFromSource(t, source, "Rust", adjust) + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"\t#[serde(rename = \"id\")]\n\tpub id: String,\n\t#[serde(rename = \"rank\")]\n\tpub rank: Option<i32>,\n",
+		"\t\tif self.id.is_empty() {\n\t\t\treturn Err(ValidationError::new(1011, \"required attribute id is missing\".to_string()));\n\t\t}\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+	assert.NotContains(t, string(generated), "legacy")
+	assert.NotContains(t, string(generated), "required attribute ccy")
+	// The empty value is a valid value of the unrestricted string.
+	assert.NotContains(t, string(generated), "required attribute alias")
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "Go", adjust) + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tif v.IdAttr == \"\" {\n\t\treturn errors.New(\"required attribute id is missing\")\n\t}\n")
+	assert.NotContains(t, string(generated), "LegacyAttr")
+	assert.NotContains(t, string(generated), "required attribute alias")
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "TypeScript", nil) + ".ts")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tIdAttr: string;\n\tRankAttr: number | null;\n")
+}
+
+func TestGenerateLenient(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="1"/>
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Amount">
+    <xs:simpleContent>
+      <xs:extension base="xs:decimal">
+        <xs:attribute name="Ccy" type="xs:string" use="required"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="MsgId" type="Max35Text"/>
+      <xs:element name="NbOfTxs" type="xs:int"/>
+      <xs:element name="Amt" type="Amount"/>
+      <xs:element name="Ref" type="xs:string" maxOccurs="unbounded"/>
+      <xs:element name="AddtlInf" type="xs:string"/>
+      <xs:element name="Note" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	adjust := func(opt *Options) { opt.Validation, opt.Lenient = ValidationMethod, true }
+	generated, err := ioutil.ReadFile(generateFromSource(t, source, "Rust", adjust) + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"\t#[serde(default)]\n\t#[serde(rename = \"MsgId\")]\n\tpub msg_id: String,\n\t#[serde(default)]\n\t#[serde(rename = \"NbOfTxs\")]\n\tpub nb_of_txs: i32,\n\t#[serde(default)]\n\t#[serde(rename = \"Amt\")]\n\tpub amt: Amount,\n",
+		"\t#[serde(rename = \"Note\")]\n\tpub note: Option<String>,\n",
+		"\t\tif self.msg_id.is_empty() {\n\t\t\treturn Err(ValidationError::new(1012, \"required element MsgId is missing\".to_string()));\n\t\t}\n",
+		"\t\tif self.ref_attr.is_empty() {\n\t\t\treturn Err(ValidationError::new(1012, \"required element Ref is missing\".to_string()));\n\t\t}\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+	assert.NotContains(t, string(generated), "required element Note")
+	assert.NotContains(t, string(generated), "required element AddtlInf")
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "Go", adjust) + ".go")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"\tif v.MsgId == \"\" {\n\t\treturn errors.New(\"required element MsgId is missing\")\n\t}\n",
+		"\tif v.Amt == nil {\n\t\treturn errors.New(\"required element Amt is missing\")\n\t}\n",
+		"\tif len(v.Ref) == 0 {\n\t\treturn errors.New(\"required element Ref is missing\")\n\t}\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+	assert.NotContains(t, string(generated), "required element NbOfTxs")
+	assert.NotContains(t, string(generated), "required element AddtlInf")
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "Rust", func(opt *Options) { opt.Validation = ValidationMethod }) + ".rs")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "#[serde(default)]")
+	assert.NotContains(t, string(generated), "required element")
+}
+
+func TestGenerateAsserts(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Range">
+    <xs:sequence>
+      <xs:element name="Min" type="xs:int"/>
+      <xs:element name="Max" type="xs:int"/>
+      <xs:element name="Label" type="xs:string" minOccurs="0"/>
+      <xs:element name="Item" type="xs:string" maxOccurs="unbounded"/>
+    </xs:sequence>
+    <xs:attribute name="Code" type="xs:string" use="required"/>
+    <xs:assert test="Min le Max"/>
+    <xs:assert test="not(exists(Label)) or string-length(Label) &gt; 2"/>
+    <xs:assert test="count(./Item) &lt;= Max - Min + 1 and starts-with(@Code, 'R')"/>
+    <xs:assert test="every $i in Item satisfies $i != ''"/>
+  </xs:complexType>
+</xs:schema>`
+	var opt *Options
+	adjust := func(o *Options) { o.Validation, opt = ValidationMethod, o }
+	generated, err := ioutil.ReadFile(generateFromSource(t, source, "Go", adjust) + ".go")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"\tif !(float64(v.Min) <= float64(v.Max)) {\n\t\treturn errors.New(\"Range does not satisfy the assertion Min le Max\")\n\t}\n",
+		"\tif !(!(v.Label != \"\") || (float64(utf8.RuneCountInString(v.Label)) > 2)) {\n",
+		"\tif !((float64(len(v.Item)) <= ((float64(v.Max) - float64(v.Min)) + 1)) && strings.HasPrefix(v.CodeAttr, \"R\")) {\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+	assert.Equal(t, []Warning{{File: opt.FilePath, Message: "skipped the validation of the unsupported assertion every $i in Item satisfies $i != '' of Range: unexpected $"}}, opt.Warnings)
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "Rust", adjust) + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"\t\tif !((self.min as f64) <= (self.max as f64)) {\n\t\t\treturn Err(ValidationError::new(1013, \"Range does not satisfy the assertion Min le Max\".to_string()));\n\t\t}\n",
+		"\t\tif !(!(self.label.is_some()) || ((self.label.as_deref().unwrap_or_default().chars().count() as f64) > 2.0)) {\n",
+		"\t\tif !(((self.item.len() as f64) <= (((self.max as f64) - (self.min as f64)) + 1.0)) && self.code.as_str().starts_with(\"R\")) {\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "Go", nil) + ".go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "assertion")
+
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "schema.xsd"), []byte(source), 0644))
+	for fallback, expected := range map[string]string{
+		AssertFallbackFail: "unsupported assertion every $i in Item satisfies $i != '' of Range: unexpected $",
+		"panic":            "unsupport assert fallback panic, expected fail",
+	} {
+		err = NewParser(&Options{
+			FilePath:            filepath.Join(dir, "schema.xsd"),
+			OutputDir:           dir,
+			Lang:                "Rust",
+			Validation:          ValidationMethod,
+			AssertFallback:      fallback,
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}).Parse()
+		assert.EqualError(t, err, expected)
+	}
+	for test, expected := range map[string]string{
+		"Min/Max > 0":            "unsupported path of several steps from Min",
+		"Item[1] = 'a'":          "unsupported predicate of Item",
+		"Min = 'a'":              "can't compare the number value of Min with the string value of \"a\"",
+		"matches(Label, 'a')":    "unsupported function matches",
+		"string-length(Min) > 1": "the number value of Min isn't a string",
+		"Label = 'a":             "unterminated string literal 'a",
+	} {
+		_, err := (&assertTranslator{gen: &CodeGenerator{Lang: "Go"}, v: &ComplexType{Elements: []Element{{Name: "Min", Type: "int"}, {Name: "Label", Type: "string"}, {Name: "Item", Type: "string", Plural: true}}}}).translate(test)
+		assert.EqualError(t, err, expected, test)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for name, source := range map[string]string{
+		"a.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:b="urn:b" targetNamespace="urn:a">
+  <xs:import namespace="urn:b" schemaLocation="b.xsd"/>
+  <xs:element name="Amt" type="b:Amount"/>
+</xs:schema>`,
+		"b.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:c="urn:c" targetNamespace="urn:b">
+  <xs:import namespace="urn:c" schemaLocation="c.xsd"/>
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+  <xs:element name="Ccy" type="c:Code"/>
+</xs:schema>`,
+		"c.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:c">
+  <xs:simpleType name="Code">
+    <xs:annotation>
+      <xs:documentation>&ver; &ver; &ver;</xs:documentation>
+    </xs:annotation>
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+  <xs:simpleType name="Text">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+</xs:schema>`,
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(source), 0644))
+	}
+	parse := func(name string, adjust func(opt *Options)) *LimitError {
+		opt := &Options{
+			FilePath:            filepath.Join(dir, name),
+			OutputDir:           dir,
+			Lang:                "Go",
+			Extract:             true,
+			Entities:            map[string]string{"ver": "1.0.0"},
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+			Artifacts:           make(map[string][]byte),
+		}
+		adjust(opt)
+		err := NewParser(opt).Parse()
+		var limitErr *LimitError
+		if err == nil || !errors.As(err, &limitErr) {
+			return nil
+		}
+		return limitErr
+	}
+	assert.Nil(t, parse("a.xsd", func(opt *Options) { opt.Extract, opt.MaxImportDepth = false, 2 }))
+	assert.Equal(t, &LimitError{File: filepath.Join(dir, "c.xsd"), Limit: LimitImportDepth, Max: 1}, parse("a.xsd", func(opt *Options) { opt.Extract, opt.MaxImportDepth = false, 1 }))
+	assert.Equal(t, &LimitError{File: filepath.Join(dir, "c.xsd"), Limit: LimitDeclarations, Max: 1}, parse("c.xsd", func(opt *Options) { opt.MaxDeclarations = 1 }))
+	assert.Equal(t, &LimitError{File: filepath.Join(dir, "c.xsd"), Limit: LimitEntityExpansion, Max: 12}, parse("c.xsd", func(opt *Options) { opt.MaxEntityExpansion = 12 }))
+	assert.Equal(t, &LimitError{File: filepath.Join(dir, "c.xsd"), Limit: LimitFileSize, Max: 64}, parse("c.xsd", func(opt *Options) { opt.MaxFileSize = 64 }))
+	assert.Nil(t, parse("c.xsd", func(opt *Options) { opt.MaxDeclarations, opt.MaxEntityExpansion, opt.MaxFileSize = 2, 15, 1024 }))
+	assert.Equal(t, &LimitError{File: filepath.Join(dir, "c.xsd"), Limit: LimitFileSize, Max: 64}, parse("c.xsd", func(opt *Options) { opt.MaxFileSize, opt.Cache = 64, NewSchemaCache() }))
+	assert.EqualError(t, &LimitError{File: "c.xsd", Limit: LimitEntityExpansion, Max: 12}, "c.xsd: the document exceeds the maximum of 12 bytes expanded from the entities")
+}
+
+func TestGenerateNillable(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max5Text">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="5"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Agent">
+    <xs:sequence>
+      <xs:element name="Nm" type="Max5Text" nillable="true"/>
+      <xs:element name="Age" type="xs:int" nillable="true" minOccurs="0" default="3"/>
+      <xs:element name="Tag" type="Max5Text" nillable="true" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:element name="Addr" type="Agent" nillable="true" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	generated, err := ioutil.ReadFile(generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.Validation = ValidationMethod
+	}) + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"pub enum Nillable<T> {\n\t#[default]\n\tNil,\n\tValue(T),\n}",
+		"\t#[serde(rename = \"Nm\")]\n\tpub nm: Nillable<String>,\n",
+		"\t#[serde(default, deserialize_with = \"Nillable::deserialize_some\")]\n\t/// Defaults to \"3\" by the schema.\n\t#[serde(rename = \"Age\")]\n\tpub age: Option<Nillable<i32>>,\n",
+		"\t#[serde(rename = \"Tag\")]\n\tpub tag: Option<Vec<Nillable<String>>>,\n",
+		"\t#[serde(rename = \"Addr\")]\n\tpub addr: Option<Box<Agent>>,\n",
+		"\t\t\tage: Some(Nillable::Value(3)),\n",
+		"\t\tif let Nillable::Value(ref val) = self.nm {\n\t\t\tif (*val).chars().count() > 5 {",
+		"\t\tfor val in self.tag.iter().flatten().filter_map(Nillable::value) {\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+
+	generated, err = ioutil.ReadFile(generateFromSource(t, source, "Go", nil) + ".go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "Nillable")
+}
+
+func TestParseParticleCardinality(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Doc">
+    <xs:sequence>
+      <xs:element name="Hdr" type="xs:string"/>
+      <xs:sequence minOccurs="0" maxOccurs="unbounded">
+        <xs:choice>
+          <xs:element name="Ntry">
+            <xs:complexType>
+              <xs:sequence>
+                <xs:element name="Amt" type="xs:string"/>
+                <xs:choice maxOccurs="3">
+                  <xs:element name="Ref" type="xs:string"/>
+                </xs:choice>
+              </xs:sequence>
+            </xs:complexType>
+          </xs:element>
+          <xs:element name="Note" type="xs:string"/>
+        </xs:choice>
+      </xs:sequence>
+      <xs:sequence minOccurs="0">
+        <xs:element name="Ftr" type="xs:string"/>
+      </xs:sequence>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", nil)
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, field := range []string{"pub hdr: String,", "pub ntry: Option<Vec<Ntry>>,", "pub note: Option<Vec<String>>,", "pub ftr: Option<String>,", "pub amt: String,", "pub ref_attr: Option<Vec<String>>,"} {
+		assert.Contains(t, string(generated), field)
+	}
+}
+
+func TestParseDocumentationLang(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max35Text">
+    <xs:annotation>
+      <xs:documentation xml:lang="en">Text of 35 characters.</xs:documentation>
+      <xs:documentation xml:lang="fr-CA">Texte de 35 caractères.</xs:documentation>
+      <xs:documentation xml:lang="de">Text mit 35 Zeichen.</xs:documentation>
+    </xs:annotation>
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+  <xs:element name="Nm" type="xs:string">
+    <xs:annotation>
+      <xs:documentation>Name.</xs:documentation>
+    </xs:annotation>
+  </xs:element>
+</xs:schema>`
+	for docLang, expected := range map[string]string{"": "Text mit 35 Zeichen.", "EN": "Text of 35 characters.", "fr": "Texte de 35 caractères.", "es": "Text mit 35 Zeichen."} {
+		docLang := docLang
+		file := generateFromSource(t, source, "Go", func(opt *Options) { opt.DocLang = docLang })
+		generated, err := ioutil.ReadFile(file + ".go")
+		require.NoError(t, err)
+		assert.Contains(t, string(generated), "// Max35Text is "+expected)
+		assert.Contains(t, string(generated), "// Nm is Name.")
+	}
+
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(source), 0644))
+	dump, err := NewParser(&Options{
+		FilePath:            file,
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+	}).Dump()
+	require.NoError(t, err)
+	assert.Equal(t, []DocEntry{{Lang: "en", Text: "Text of 35 characters."}, {Lang: "fr-CA", Text: "Texte de 35 caractères."}, {Lang: "de", Text: "Text mit 35 Zeichen."}}, dump.Declarations[0].Docs)
+	assert.Equal(t, []DocEntry{{Text: "Name."}}, dump.Declarations[1].Docs)
+}
+
+func TestTranslatePattern(t *testing.T) {
+	for pattern, expected := range map[string]string{
+		`[A-Z]{3}[0-9]?`:    `[A-Z]{3}[0-9]?`,
+		`\i\c*`:             `[\p{L}_:][\p{L}\p{Nd}\p{Mn}\p{Mc}._:\-\x{B7}]*`,
+		`[a-z-[aeiou]]+`:    `[b-df-hj-np-tv-z]+`,
+		`[^a-z-[0-9]]`:      `[\x{0}-\x{2F}\x{3A}-\x{60}\x{7B}-\x{10FFFF}]`,
+		`\p{IsBasicLatin}+`: `[\x{0}-\x{7F}]+`,
+		`\P{IsBasicLatin}`:  `[^\x{0}-\x{7F}]`,
+		`[\p{IsGreek}0-9]`:  `[0-9\x{370}-\x{3FF}]`,
+		`\p{Lu}[\-\]]`:      `\p{Lu}[\-\]]`,
+		`\$[0-9]+`:          `\$[0-9]+`,
+		`^[A-Z]+$`:          `\^[A-Z]+\$`,
+		`[$^]a.b`:           `[$^]a[^\n\r]b`,
+	} {
+		translated, err := translatePattern(pattern)
+		assert.NoError(t, err, pattern)
+		assert.Equal(t, expected, translated, pattern)
+		_, err = regexp.Compile(translated)
+		assert.NoError(t, err, pattern)
+	}
+	translated, err := translatePattern(`[\i-[:]][\c-[:]]*`)
+	assert.NoError(t, err)
+	re := regexp.MustCompile("^(?:" + translated + ")$")
+	assert.True(t, re.MatchString("xs.Name-1"))
+	assert.False(t, re.MatchString("xs:Name"))
+	assert.False(t, re.MatchString("1Name"))
+	for pattern, matches := range map[string]map[string]bool{
+		`$[0-9]+`:  {"$100": true, "100": false},
+		`^[A-Z]+$`: {"^ABC$": true, "ABC": false},
+		`a.c`:      {"abc": true, "a\nc": false, "a\rc": false},
+	} {
+		translated, err := translatePattern(pattern)
+		assert.NoError(t, err, pattern)
+		re := regexp.MustCompile("^(?:" + translated + ")$")
+		for value, expected := range matches {
+			assert.Equal(t, expected, re.MatchString(value), pattern+" "+value)
+		}
+	}
+	for pattern, expected := range map[string]string{
+		`\p{IsKlingon}`: "unsupported Unicode block Klingon",
+		`[a-\d]`:        `invalid character class range a-\d`,
+		`[a-z`:          "missing closing ] of [a-z",
+		`[a-[b]c]`:      "subtraction isn't the last part of [a-[b]c]",
+	} {
+		_, err := translatePattern(pattern)
+		assert.EqualError(t, err, expected, pattern)
+	}
+}
+
+func TestParsePatternFallback(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[a-z-[aeiou]]{3}"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Name">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="\p{IsKlingon}+"/>
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Go", func(o *Options) {
+		o.Validation, opt = ValidationMethod, o
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), `regexp.MustCompile("^(?:[b-df-hj-np-tv-z]{3})$")`)
+	assert.Equal(t, 1, strings.Count(string(generated), "regexp.MustCompile("))
+	assert.Contains(t, string(generated), "exceeds the maximum length of 35")
+	assert.Equal(t, []Warning{{File: opt.FilePath, Line: 9, Message: `skipped the validation of the unsupported pattern \p{IsKlingon}+: unsupported Unicode block Klingon`}}, opt.Warnings)
+
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "schema.xsd"), []byte(source), 0644))
+	for fallback, expected := range map[string]string{
+		PatternFallbackFail: `unsupported pattern \p{IsKlingon}+: unsupported Unicode block Klingon`,
+		"panic":             "unsupport pattern fallback panic, expected fail",
+	} {
+		err = NewParser(&Options{
+			FilePath:            filepath.Join(dir, "schema.xsd"),
+			OutputDir:           dir,
+			Lang:                "Go",
+			PatternFallback:     fallback,
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}).Parse()
+		assert.EqualError(t, err, expected)
+	}
+}
+
+func TestGenerateChoiceEnums(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string"><xs:maxLength value="35"/></xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="AccountIdentification4Choice">
+    <xs:choice>
+      <xs:element name="IBAN" type="xs:string"/>
+      <xs:element name="Othr" type="Max35Text"/>
+    </xs:choice>
+  </xs:complexType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:choice minOccurs="0">
+        <xs:element name="Cd" type="xs:string"/>
+        <xs:element name="Prtry" type="xs:string"/>
+      </xs:choice>
+      <xs:choice maxOccurs="unbounded">
+        <xs:element name="Ref" type="xs:string"/>
+      </xs:choice>
+      <xs:choice>
+        <xs:sequence><xs:element name="A" type="xs:string"/></xs:sequence>
+        <xs:element name="B" type="xs:string"/>
+      </xs:choice>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.ChoiceEnums, opt.Validation = true, ValidationMethod
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"pub enum AccountIdentification4Choice {\n\tIBAN(String),\n\tOthr(String),\n}\n",
+		"\t\tAccountIdentification4Choice::IBAN(Default::default())\n",
+		"\t\t\tAccountIdentification4Choice::Othr(value) => map.serialize_entry(\"Othr\", value)?,\n",
+		"\t\t\t\t\t\"Othr\" => AccountIdentification4Choice::Othr(map.next_value()?),\n",
+		"\t\t\tAccountIdentification4Choice::Othr(value) => {\n\t\t\t\tif (*value).chars().count() > 35 {\n",
+		"\t#[serde(rename = \"Cd\", skip_serializing_if = \"Option::is_none\")]\n\tpub cd: Option<String>,\n",
+		"pub enum PartyChoice {\n\tCd(String),\n",
+		"\tpub fn choice(&self) -> Option<PartyChoice> {\n\t\tif let Some(value) = &self.cd {\n\t\t\treturn Some(PartyChoice::Cd(<String>::clone(value).into()));\n",
+		"\t\t\tPartyChoice::Prtry(value) => self.prtry = Some(value.into()),\n",
+		"\tpub ref_attr: Option<Vec<String>>,\n",
+		"\tpub a: Option<String>,\n",
+		"\tpub b: Option<String>,\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+
+	file = generateFromSource(t, source, "Rust", nil)
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "pub enum")
+	assert.Contains(t, string(generated), "\tpub iban: Option<String>,\n")
+
+	// The choices are read from and written to the child elements by
+	// quick-xml, and to the objects of the elements by serde_json.
+	source = `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="GenericAccountIdentification1">
+    <xs:sequence><xs:element name="Id" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="AccountIdentification4Choice">
+    <xs:choice>
+      <xs:element name="IBAN" type="xs:string"/>
+      <xs:element name="Othr" type="GenericAccountIdentification1"/>
+    </xs:choice>
+  </xs:complexType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:element name="Acct" type="AccountIdentification4Choice" maxOccurs="unbounded"/>
+      <xs:choice>
+        <xs:element name="Cd" type="xs:string"/>
+        <xs:element name="Prtry" type="AccountIdentification4Choice"/>
+      </xs:choice>
+      <xs:element name="Ctry" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.ChoiceEnums, opt.Validation = true, ValidationMethod
+	})
+	runRustCrate(t, file+".rs", `
+	#[test]
+	fn round_trip_choices() {
+		let xml = "<Party><Nm>A</Nm><Acct><IBAN>FR76</IBAN></Acct><Acct><Othr><Id>1</Id></Othr></Acct><Prtry><IBAN>DE89</IBAN></Prtry><Ctry>FR</Ctry></Party>";
+		let mut party: Party = quick_xml::de::from_str(xml).unwrap();
+		assert_eq!(party.acct, vec![
+			AccountIdentification4Choice::IBAN("FR76".to_string()),
+			AccountIdentification4Choice::Othr(GenericAccountIdentification1 { id: "1".to_string() }),
+		]);
+		assert_eq!(party.choice(), Some(PartyChoice::Prtry(AccountIdentification4Choice::IBAN("DE89".to_string()))));
+		assert_eq!(quick_xml::se::to_string(&party).unwrap(), xml);
+		let json = serde_json::to_string(&party).unwrap();
+		assert_eq!(serde_json::from_str::<Party>(&json).unwrap(), party);
+
+		party.set_choice(PartyChoice::Cd("C".to_string()));
+		assert_eq!(party.prtry, None);
+		let xml = xml.replace("<Prtry><IBAN>DE89</IBAN></Prtry>", "<Cd>C</Cd>");
+		assert_eq!(quick_xml::se::to_string(&party).unwrap(), xml);
+		assert_eq!(quick_xml::de::from_str::<Party>(&xml).unwrap(), party);
+
+		assert!(quick_xml::de::from_str::<Party>("<Party><Nm>A</Nm><Acct><IBAN>FR76</IBAN><Othr><Id>1</Id></Othr></Acct><Cd>C</Cd><Ctry>FR</Ctry></Party>").is_err());
+		assert!(quick_xml::de::from_str::<Party>("<Party><Nm>A</Nm><Acct><BBAN>1</BBAN></Acct><Cd>C</Cd><Ctry>FR</Ctry></Party>").is_err());
+	}
+`)
+}
+
+func TestGenerateRecursiveTypes(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Tree">
+    <xs:sequence>
+      <xs:element name="Root" type="Node"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Node">
+    <xs:sequence>
+      <xs:element name="Parent" type="Node" minOccurs="0"/>
+      <xs:element name="Children" type="Node" maxOccurs="unbounded"/>
+      <xs:element name="Link" type="Link"/>
+      <xs:element name="Expr" type="Expr" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Link">
+    <xs:sequence>
+      <xs:element name="Target" type="Node" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Expr">
+    <xs:choice>
+      <xs:element name="Not" type="Expr"/>
+      <xs:element name="And" type="Expr" maxOccurs="unbounded"/>
+      <xs:element name="Lit" type="xs:string"/>
+    </xs:choice>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.ChoiceEnums, opt.PatchTypes = true, true
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tpub root: Node,\n")
+	assert.Contains(t, string(generated), "\tpub parent: Option<Box<Node>>,\n")
+	assert.Contains(t, string(generated), "\tpub children: Vec<Node>,\n")
+	assert.Contains(t, string(generated), "\tpub link: Box<Link>,\n")
+	assert.Contains(t, string(generated), "\tpub target: Option<Box<Node>>,\n")
+	assert.Contains(t, string(generated), "\tpub expr: Option<Expr>,\n")
+	assert.Contains(t, string(generated), "\tNot(Box<Expr>),\n")
+	assert.Contains(t, string(generated), "\tAnd(Vec<Expr>),\n")
+	assert.Contains(t, string(generated), "pub struct NodePatch {\n\t#[serde(rename = \"Parent\", skip_serializing_if = \"Option::is_none\")]\n\tpub parent: Option<Box<Node>>,\n")
+
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "d": 0}, getStronglyConnectedComponents(map[string][]string{
+		"a": {"b"}, "b": {"a", "c"}, "c": {"d"}, "d": {"d"},
+	}))
+}
+
+func TestGenerateChoiceRepr(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="GenericAccountIdentification1">
+    <xs:sequence><xs:element name="Id" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="AccountIdentification4Choice">
+    <xs:choice>
+      <xs:element name="IBAN" type="xs:string"/>
+      <xs:element name="Othr" type="GenericAccountIdentification1"/>
+    </xs:choice>
+  </xs:complexType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:choice>
+        <xs:element name="Cd" type="xs:string"/>
+        <xs:element name="Prtry" type="xs:string"/>
+      </xs:choice>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.ChoiceEnums, opt.ChoiceRepr, opt.ChoiceTag = true, ChoiceReprInternal, "kind"
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"#[serde(tag = \"kind\")]\npub enum AccountIdentification4Choice {\n\t#[serde(rename = \"IBAN\")]\n\tIBAN { value: String },\n\t#[serde(rename = \"Othr\")]\n\tOthr(GenericAccountIdentification1),\n}\n",
+		"\t\tAccountIdentification4Choice::IBAN { value: Default::default() }\n",
+		"\t#[serde(rename = \"choice\")]\n\tpub choice: PartyChoice,\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.ChoiceEnums, opt.ChoiceRepr = true, ChoiceReprUntagged
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "#[serde(untagged)]\npub enum AccountIdentification4Choice {\n\t#[serde(rename = \"IBAN\")]\n\tIBAN(String),\n")
+	assert.EqualError(t, checkChoiceRepr("adjacent"), "unsupport choice representation adjacent, expected internal or untagged")
+}
+
+func TestGenerateExclusiveFacets(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="PositiveRate">
+    <xs:restriction base="xs:int">
+      <xs:minExclusive value="0"/>
+      <xs:maxExclusive value="100"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Rate" type="PositiveRate"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Rust", func(o *Options) {
+		opt, o.Validation = o, ValidationMethod
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "if self.rate <= 0 {\n")
+	assert.Contains(t, string(generated), "is not greater than the exclusive minimum value of 0")
+	assert.Contains(t, string(generated), "if self.rate >= 100 {\n")
+	assert.Contains(t, string(generated), "is not less than the exclusive maximum value of 100")
+
+	restriction := getRestrictionFromSimpleType("PositiveRate", opt.ProtoTree)
+	assert.True(t, restriction.MinExclusive && restriction.MaxExclusive)
+	value, err := NewFaker(opt.ProtoTree, 1).Value("PositiveRate")
+	require.NoError(t, err)
+	assert.NoError(t, restriction.Evaluate(value.(string)))
+	assert.Equal(t, "0", dumpFacets(restriction).MinExclusive)
+	assert.Empty(t, dumpFacets(restriction).MinInclusive)
+
+	// The bounds with fraction of the integer types are rounded to the
+	// integers satisfying them.
+	gen := &CodeGenerator{Lang: "Rust"}
+	for _, testCase := range []struct {
+		restriction Restriction
+		expected    string
+	}{
+		{Restriction{HasMin: true, Min: 1.5, MinExclusive: true}, "if value <= 1 {\n"},
+		{Restriction{HasMin: true, Min: 1.5}, "if value < 2 {\n"},
+		{Restriction{HasMax: true, Max: 1.5, MaxExclusive: true}, "if value >= 2 {\n"},
+		{Restriction{HasMax: true, Max: 1.5}, "if value > 1 {\n"},
+		{Restriction{HasMin: true, Min: -1.5}, "if value < -1 {\n"},
+		{Restriction{HasMax: true, Max: -1.5}, "if value > -2 {\n"},
+		{Restriction{HasMin: true, MinValue: FacetValue{Kind: FacetDecimal, Lexical: "2.25", Decimal: big.NewRat(9, 4)}, MinExclusive: true}, "if value <= 2 {\n"},
+	} {
+		assert.Contains(t, gen.genRustFacetChecks("", "value", "i32", "value", "&value", "value", &testCase.restriction), testCase.expected)
+	}
+	assert.Equal(t, "1", genRustNumberLiteral(FacetValue{Kind: FacetFloat, Float: 1.5}, "i64", false))
+	assert.Equal(t, "2", genRustNumberLiteral(FacetValue{Kind: FacetFloat, Float: 1.5}, "i64", true))
+	assert.Equal(t, "i64::MAX", genRustNumberLiteral(FacetValue{Kind: FacetFloat, Float: math.Inf(1)}, "i64", false))
+	assert.Equal(t, "1.5", genRustNumberLiteral(FacetValue{Kind: FacetFloat, Float: 1.5}, "f64", false))
+}
+
+func TestGenerateDigitsFacets(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="ActiveCurrencyAndAmount_SimpleType">
+    <xs:restriction base="xs:decimal">
+      <xs:fractionDigits value="5"/>
+      <xs:totalDigits value="18"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Payment">
+    <xs:sequence>
+      <xs:element name="Amt" type="ActiveCurrencyAndAmount_SimpleType"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Rust", func(o *Options) {
+		opt, o.Validation = o, ValidationMethod
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "fn xsd_digits(value: &str) -> (usize, usize) {\n")
+	assert.Contains(t, string(generated), "if xsd_digits(&self.amt.to_string()).0 > 18 {\n")
+	assert.Contains(t, string(generated), "if xsd_digits(&self.amt.to_string()).1 > 5 {\n")
+	restriction := getRestrictionFromSimpleType("ActiveCurrencyAndAmount_SimpleType", opt.ProtoTree)
+	assert.Equal(t, 18, restriction.TotalDigits)
+	assert.Equal(t, 18, dumpFacets(restriction).TotalDigits)
+	value, err := NewFaker(opt.ProtoTree, 1).Value("ActiveCurrencyAndAmount_SimpleType")
+	require.NoError(t, err)
+	assert.NoError(t, restriction.Evaluate(value.(string)))
+
+	file = generateFromSource(t, source, "Go", func(o *Options) {
+		o.Validation, o.DecimalForm = ValidationStandalone, DecimalFormCanonical
+	})
+	generated, err = ioutil.ReadFile(file + ".validator.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "if xsdTotalDigits(float64(v.Amt)) > 18 {\n")
+	assert.Contains(t, string(generated), "if xsdFractionDigits(float64(v.Amt)) > 5 {\n")
+	generated, err = ioutil.ReadFile(filepath.Join(filepath.Dir(file), "xsd_digits.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "func xsdDigits(f float64) (total, fraction int) {\n")
+}
+
+// runGoPackage runs the go command, such as go vet, which type-checks the
+// package as go build does, or go test, on the generated Go package of the
+// given directory, as a module of its own. It skips the test without the go
+// command.
+func runGoPackage(t *testing.T, dir, command string) {
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/generated\n\ngo 1.18\n"), 0644))
+	cmd := exec.Command(goCmd, command, ".")
+	cmd.Dir, cmd.Env = dir, append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	output, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(output))
+}
+
+// runRustCrate runs cargo test on the generated Rust code of the given file
+// as the schema module of a crate of its own, with the given tests in the
+// tests module of the crate. It skips the test without the cargo command, or
+// without the crates of the dependencies in the cache of cargo, as it runs
+// offline.
+func runRustCrate(t *testing.T, file, tests string) {
+	cargo, err := exec.LookPath("cargo")
+	if err != nil {
+		t.Skip("cargo command not found")
+	}
+	dir := filepath.Join(filepath.Dir(file), "crate")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte(`[package]
+name = "generated"
+version = "0.1.0"
+edition = "2021"
+
+[dependencies]
+quick-xml = { version = "0.37", features = ["serialize"] }
+serde = { version = "1", features = ["derive"] }
+serde_json = "1"
+regex = "1"
+`), 0644))
+	code, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "src", "schema.rs"), code, 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "src", "lib.rs"), []byte("#![allow(dead_code, unused_imports)]\nmod schema;\n\n#[cfg(test)]\nmod tests {\n\tuse super::schema::*;\n"+tests+"}\n"), 0644))
+	cmd := exec.Command(cargo, "test", "--offline", "--quiet")
+	// The dependencies are built once for the tests.
+	cmd.Dir, cmd.Env = dir, append(os.Environ(), "CARGO_TARGET_DIR="+filepath.Join(os.TempDir(), "xgen-cargo-target"))
+	output, err := cmd.CombinedOutput()
+	if err != nil && strings.Contains(string(output), "offline") {
+		t.Skip("dependencies not cached: " + string(output))
+	}
+	assert.NoError(t, err, string(output))
+}
+
+func TestGenerateGoBuild(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="ActiveCurrencyAndAmount_SimpleType">
+    <xs:restriction base="xs:decimal">
+      <xs:minInclusive value="0"/>
+      <xs:fractionDigits value="5"/>
+      <xs:totalDigits value="18"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="1"/>
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="BICFIDec2014Identifier">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z0-9]{4,4}[A-Z]{2,2}[A-Z0-9]{2,2}([A-Z0-9]{3,3}){0,1}"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Payment">
+    <xs:sequence>
+      <xs:element name="Amt" type="ActiveCurrencyAndAmount_SimpleType"/>
+      <xs:element name="Fee" type="xs:decimal" minOccurs="0"/>
+      <xs:element name="Nm" type="Max35Text"/>
+      <xs:element name="BIC" type="BICFIDec2014Identifier" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	for _, validation := range []string{ValidationMethod, ValidationStandalone} {
+		for _, form := range []string{DecimalFormNative, DecimalFormCanonical, DecimalFormFixedScale} {
+			name := form
+			if name == DecimalFormNative {
+				name = "native"
+			}
+			t.Run(validation+"/"+name, func(t *testing.T) {
+				file := generateFromSource(t, source, "Go", func(opt *Options) {
+					opt.Validation, opt.DecimalForm = validation, form
+				})
+				runGoPackage(t, filepath.Dir(file), "vet")
+			})
+		}
+	}
+}
+
+func TestGenerateStandaloneValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="4"/>
+      <xs:pattern value="[A-Z]+"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Cd" type="Code"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	// The standalone validator is generated into the package of the types,
+	// so its functions reach the unexported helpers of the generated code.
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.Validation = ValidationStandalone
+	})
+	types, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	validator, err := ioutil.ReadFile(file + ".validator.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(types), "\npackage schema\n")
+	assert.Contains(t, string(validator), "\npackage schema\n")
+	assert.NotContains(t, string(types), "func (v *Party) Validate() error")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(filepath.Dir(file), "validator_test.go"), []byte(`package schema
+
+import "testing"
+
+func TestValidateParty(t *testing.T) {
+	if err := ValidateParty(&Party{Cd: "ABC"}); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateParty(&Party{Cd: "abc"}); err == nil {
+		t.Error("expected the pattern error")
+	}
+}
+`), 0644))
+	runGoPackage(t, filepath.Dir(file), "test")
+
+	// The Rust validator is the child module of the types, which imports
+	// them by use super::*.
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.Validation = ValidationStandalone
+	})
+	types, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	validator, err = ioutil.ReadFile(file + ".validator.rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(validator), "use super::*;\n")
+	require.NoError(t, ioutil.WriteFile(file+".rs", []byte(string(types)+"\npub mod validator {\n"+string(validator)+"}\n"), 0644))
+	runRustCrate(t, file+".rs", `	use super::schema::validator::*;
+
+	#[test]
+	fn validate_party_code() {
+		assert!(validate_party(&Party { cd: "ABC".to_string() }).is_ok());
+		assert_eq!(validate_party(&Party { cd: "abc".to_string() }).unwrap_err().code, 1005);
+	}
+`)
+}
+
+func TestGenerateRustDecimal(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal">
+      <xs:minExclusive value="0"/>
+      <xs:maxInclusive value="999999999999.99999"/>
+      <xs:fractionDigits value="5"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Payment">
+    <xs:sequence>
+      <xs:element name="Amt" type="Amount"/>
+      <xs:element name="Fee" type="xs:decimal" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.Validation, opt.RustDecimal = ValidationMethod, true
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "use rust_decimal::Decimal;\n")
+	assert.Contains(t, string(generated), "\tpub amt: Decimal,\n")
+	assert.Contains(t, string(generated), "\tpub fee: Option<Decimal>,\n")
+	assert.Contains(t, string(generated), "if self.amt <= Decimal::new(0, 0) {\n")
+	assert.Contains(t, string(generated), "if self.amt > Decimal::new(99999999999999999, 5) {\n")
+	assert.Contains(t, string(generated), "amt exceeds the maximum value of 999999999999.99999")
+	assert.Contains(t, string(generated), "if xsd_digits(&self.amt.normalize().to_string()).1 > 5 {\n")
+
+	assert.Equal(t, "Decimal::new(-25, 2)", genRustDecimalLiteral(-0.25, "-000.250"))
+	assert.Equal(t, "Decimal::new(15, 1)", genRustDecimalLiteral(1.5, ""))
+	assert.Equal(t, "Decimal::from_str_exact(\"12345678901234567890.5\").unwrap()", genRustDecimalLiteral(0, "+12345678901234567890.50"))
+
+	file = generateFromSource(t, source, "Rust", nil)
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tpub amt: f64,\n")
+	assert.NotContains(t, string(generated), "rust_decimal")
+}
+
+func TestGenerateRustChrono(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="ISODate">
+    <xs:restriction base="xs:date"/>
+  </xs:simpleType>
+  <xs:complexType name="Entry">
+    <xs:sequence>
+      <xs:element name="BookgDt" type="ISODate"/>
+      <xs:element name="CreDtTm" type="xs:dateTime"/>
+      <xs:element name="Tm" type="xs:time" minOccurs="0"/>
+      <xs:element name="Prd" type="xs:gYearMonth" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.RustChrono = true
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "pub struct XsdDate(pub chrono::NaiveDate);\n")
+	assert.Contains(t, string(generated), "pub struct XsdDateTime(pub chrono::DateTime<chrono::Utc>);\n")
+	assert.Contains(t, string(generated), "pub struct XsdTime(pub chrono::NaiveTime);\n")
+	assert.Contains(t, string(generated), "pub struct XsdGYearMonth(pub chrono::NaiveDate);\n")
+	assert.Contains(t, string(generated), "\tpub iso_date: XsdDate,\n")
+	assert.Contains(t, string(generated), "\tpub cre_dt_tm: XsdDateTime,\n")
+	assert.Contains(t, string(generated), "\tpub tm: Option<XsdTime>,\n")
+	assert.Contains(t, string(generated), "\tpub prd: Vec<XsdGYearMonth>,\n")
+
+	file = generateFromSource(t, source, "Rust", nil)
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tpub bookg_dt: String,\n")
+	assert.NotContains(t, string(generated), "chrono")
+}
+
+func TestGenerateRustErrorType(t *testing.T) {
+	file := generateFromSource(t, validationTestSchema, "Rust", func(opt *Options) {
+		opt.Validation = ValidationMethod
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "pub struct ValidationError {\n\tpub code: u32,\n\tpub message: String,\n}\n")
+	assert.Contains(t, string(generated), "\tpub fn new(code: u32, message: String) -> Self {\n\t\tValidationError { code, message }\n\t}\n")
+	assert.Contains(t, string(generated), "impl std::error::Error for ValidationError {}\n")
+	assert.NotContains(t, string(generated), "open_payments_common")
+
+	file = generateFromSource(t, validationTestSchema, "Rust", func(opt *Options) {
+		opt.Validation = ValidationStandalone
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "ValidationError")
+	validator, err := ioutil.ReadFile(file + ".validator.rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(validator), "pub struct ValidationError {\n")
+
+	file = generateFromSource(t, validationTestSchema, "Rust", func(opt *Options) {
+		opt.Validation = ValidationStandalone
+		opt.RustErrorType = "open_payments_common::ValidationError"
+		opt.RustUses = []string{"crate::common::*"}
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "use serde::{Deserialize, Serialize};\nuse crate::common::*;\n")
+	validator, err = ioutil.ReadFile(file + ".validator.rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(validator), "use super::*;\nuse open_payments_common::ValidationError;\n")
+	assert.NotContains(t, string(validator), "pub struct ValidationError")
+
+	file = generateFromSource(t, validationTestSchema, "Rust", func(opt *Options) {
+		opt.Validation = ValidationMethod
+		opt.RustErrorType = "crate::errors::Error"
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "use crate::errors::Error as ValidationError;\n")
+
+	uses, err := ParseRustUses(" crate::common::*, serde_json::{Map, Value},")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"crate::common::*", "serde_json::{Map, Value}"}, uses)
+	_, err = ParseRustUses("crate::common;\nfn main() {}")
+	assert.EqualError(t, err, "invalid Rust use path crate::common;\nfn main() {}")
+	assert.EqualError(t, checkRustErrorType("errors::*"), "invalid Rust error type path errors::*")
+}
+
+func TestGenerateRustDerives(t *testing.T) {
+	derives, err := ParseRustDerives(" Hash, Eq ,Debug, schemars::JsonSchema = schema, PartialOrd=schema,")
+	require.NoError(t, err)
+	assert.Equal(t, []RustDerive{{Path: "Hash"}, {Path: "Eq"}, {Path: "Debug"}, {Path: "schemars::JsonSchema", Feature: "schema"}, {Path: "PartialOrd", Feature: "schema"}}, derives)
+	assert.Equal(t, "Hash,Eq,Debug,schemars::JsonSchema=schema,PartialOrd=schema", formatRustDerives(derives))
+	file := generateFromSource(t, validationTestSchema, "Rust", func(opt *Options) {
+		opt.Validation = ValidationMethod
+		opt.RustDerives = derives
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "#[derive(Debug, Default, PartialEq, Clone, Serialize, Deserialize, Hash, Eq)]\n#[cfg_attr(feature = \"schema\", derive(schemars::JsonSchema, PartialOrd))]\npub struct ")
+	assert.Contains(t, string(generated), "#[derive(Debug, Clone, PartialEq, Eq, Hash)]\n#[cfg_attr(feature = \"schema\", derive(schemars::JsonSchema, PartialOrd))]\npub struct ValidationError {\n")
+	assert.NotContains(t, string(generated), "#[derive(Debug, Default, PartialEq, Clone, Serialize, Deserialize)]\n")
+
+	_, err = ParseRustDerives("Hash)]\nfn main() {}")
+	assert.EqualError(t, err, "invalid Rust derive Hash)]\nfn main() {}")
+	_, err = ParseRustDerives("Hash=\"schema\"")
+	assert.EqualError(t, err, "invalid feature \"schema\" of the Rust derive Hash")
+}
+
+func TestGenerateRustModules(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Payment">
+    <xs:sequence>
+      <xs:element name="Dbtr" type="Party"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Status">
+    <xs:sequence>
+      <xs:element name="Pty" type="Party"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="Document" type="Payment"/>
+  <xs:element name="Report" type="Status"/>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.RustModules = true
+		opt.Validation = ValidationStandalone
+	})
+	_, err := os.Stat(file + ".rs")
+	assert.True(t, os.IsNotExist(err))
+	dir := filepath.Join(filepath.Dir(file), "schema_xsd")
+	index, err := ioutil.ReadFile(filepath.Join(dir, "mod.rs"))
+	require.NoError(t, err)
+	assert.Contains(t, string(index), "use serde::{Deserialize, Serialize};\n\npub mod document_module;\npub use document_module::*;\npub mod report_module;\npub use report_module::*;\npub mod validator;\n")
+	assert.Contains(t, string(index), "pub struct Party {\n")
+	for module, expected := range map[string][]string{
+		"document_module": {"pub struct Payment {\n", "pub struct document {\n"},
+		"report_module":   {"pub struct Status {\n", "pub struct report {\n"},
+		"validator":       {"pub fn validate_payment(v: &Payment)", "pub fn validate_report(v: &report)"},
+	} {
+		generated, err := ioutil.ReadFile(filepath.Join(dir, module+".rs"))
+		require.NoError(t, err)
+		assert.Contains(t, string(generated), "\nuse super::*;\n", module)
+		assert.NotContains(t, string(generated), "pub struct Party {\n", module)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, module)
+		}
+	}
+
+	assert.EqualError(t, (&Options{Lang: "Go", RustModules: true}).checkRustModules(), "the Rust modules mode isn't supported for Go")
+	assert.EqualError(t, (&Options{Lang: "Rust", RustModules: true, GroupByMessage: true}).checkRustModules(), "the Rust modules mode can't be used with the group-by-message mode")
+	assert.NoError(t, (&Options{Lang: "Go"}).checkRustModules())
+}
+
+func TestGenerateJSONValue(t *testing.T) {
+	file := generateFromSource(t, validationTestSchema, "Rust", func(opt *Options) {
+		opt.JSONValue = true
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "#[cfg(feature = \"xgen-json\")]\nimpl Party {\n")
+	assert.Contains(t, string(generated), "\tpub fn to_json_value(&self) -> Result<serde_json::Value, serde_json::Error> {\n\t\tserde_json::to_value(self)\n\t}\n")
+	assert.Contains(t, string(generated), "\tpub fn from_json_value(value: serde_json::Value) -> Result<Self, serde_json::Error> {\n\t\tserde_json::from_value(value)\n\t}\n")
+
+	file = generateFromSource(t, validationTestSchema, "Rust", nil)
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "to_json_value")
+}
+
+func TestGenerateSQLMethods(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"><xs:enumeration value="ADDR"/><xs:enumeration value="CASH"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Count">
+    <xs:restriction base="xs:unsignedLong"><xs:minInclusive value="1"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Rate">
+    <xs:restriction base="xs:float"/>
+  </xs:simpleType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.SQLMethods = true
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\t\"database/sql/driver\"\n")
+	assert.Contains(t, string(generated), "func (v *Code) Scan(src interface{}) error {\n\tvalue, err := scanXSDString(src)\n")
+	assert.Contains(t, string(generated), "func (v Code) Value() (driver.Value, error) {\n\treturn string(v), nil\n}\n")
+	assert.Contains(t, string(generated), "\tvalue, err := scanXSDUint(src, 64)\n")
+	assert.Contains(t, string(generated), "func (v Count) Value() (driver.Value, error) {\n\treturn valueXSDUint(uint64(v))\n}\n")
+	assert.Contains(t, string(generated), "\tvalue, err := scanXSDFloat(src, 32)\n")
+	assert.Contains(t, string(generated), "func (v Rate) Value() (driver.Value, error) {\n\treturn float64(v), nil\n}\n")
+	generated, err = ioutil.ReadFile(filepath.Join(filepath.Dir(file), "xsd_sql.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "func scanXSDString(src interface{}) (string, error) {\n")
+
+	file = generateFromSource(t, source, "Go", nil)
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "Scan(")
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(file), "xsd_sql.go"))
+}
+
+func TestGenerateSubstitutionGroups(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Drawing" type="DrawingType"/>
+  <xs:complexType name="DrawingType">
+    <xs:sequence>
+      <xs:element ref="Shape" maxOccurs="unbounded"/>
+      <xs:element ref="Label" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="Shape" type="ShapeType" abstract="true"/>
+  <xs:complexType name="ShapeType">
+    <xs:sequence><xs:element name="Color" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+  <xs:element name="Circle" type="CircleType" substitutionGroup="Shape"/>
+  <xs:complexType name="CircleType">
+    <xs:sequence><xs:element name="Radius" type="xs:int"/></xs:sequence>
+  </xs:complexType>
+  <xs:element name="Ellipse" substitutionGroup="Circle"/>
+  <xs:element name="Label" type="xs:string"/>
+  <xs:element name="ShortLabel" type="xs:string" substitutionGroup="Label"/>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", nil)
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tShape []*ShapeSubstitution `xml:\",any\"`\n")
+	assert.Contains(t, string(generated), "\tLabel *LabelSubstitution   `xml:\"Label\"`\n")
+	assert.Contains(t, string(generated), "type ShapeSubstitutionMember interface {\n\tisShapeSubstitution()\n}\n")
+	assert.Contains(t, string(generated), "func (*CircleType) isShapeSubstitution() {}\n")
+	assert.NotContains(t, string(generated), "func (*ShapeType) isShapeSubstitution() {}\n")
+	assert.Contains(t, string(generated), "\tcase \"Circle\":\n\t\tvalue = new(CircleType)\n\tcase \"Ellipse\":\n\t\tvalue = new(CircleType)\n")
+	assert.Contains(t, string(generated), "func (*Label) isLabelSubstitution() {}\n\nfunc (*ShortLabel) isLabelSubstitution() {}\n")
+
+	file = generateFromSource(t, source, "Rust", nil)
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tpub shape: Vec<ShapeSubstitution>,\n")
+	assert.Contains(t, string(generated), "pub enum ShapeSubstitution {\n\t#[serde(rename = \"Circle\")]\n\tCircle(CircleType),\n\t#[serde(rename = \"Ellipse\")]\n\tEllipse(CircleType),\n}\n")
+	assert.Contains(t, string(generated), "pub enum LabelSubstitution {\n\t#[serde(rename = \"Label\")]\n\tLabel(String),\n")
+
+	file = generateFromSource(t, source, "TypeScript", nil)
+	generated, err = ioutil.ReadFile(file + ".ts")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tShape: Array<ShapeSubstitution>;\n")
+	assert.Contains(t, string(generated), "export type ShapeSubstitution = CircleType;\n")
+
+	file = generateFromSource(t, source, "Java", nil)
+	generated, err = ioutil.ReadFile(file + ".java")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "Substitution")
+}
+
+func TestGenerateAbstractTypes(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Drawing" type="DrawingType"/>
+  <xs:complexType name="DrawingType">
+    <xs:sequence><xs:element name="Shape" type="ShapeType" maxOccurs="unbounded"/></xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="ShapeType" abstract="true">
+    <xs:sequence><xs:element name="Color" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="CircleType">
+    <xs:complexContent><xs:extension base="ShapeType">
+      <xs:sequence><xs:element name="Radius" type="xs:int"/></xs:sequence>
+    </xs:extension></xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="RoundedSquareType">
+    <xs:complexContent><xs:extension base="SquareType">
+      <xs:sequence><xs:element name="Corner" type="xs:int"/></xs:sequence>
+    </xs:extension></xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="SquareType">
+    <xs:complexContent><xs:extension base="ShapeType">
+      <xs:sequence><xs:element name="Side" type="xs:int"/></xs:sequence>
+    </xs:extension></xs:complexContent>
+  </xs:complexType>
+  <xs:element name="AnyShape" type="ShapeType" abstract="true"/>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) { opt.RootWrappers = true })
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tShape []*ShapeTypeDerived `xml:\"Shape\"`\n")
+	assert.Contains(t, string(generated), "type ShapeTypeDerivedMember interface {\n\tisShapeTypeDerived()\n}\n")
+	assert.Contains(t, string(generated), "func (*RoundedSquareType) isShapeTypeDerived() {}\n")
+	assert.NotContains(t, string(generated), "func (*ShapeType) isShapeTypeDerived() {}\n")
+	assert.Contains(t, string(generated), "\tcase \"CircleType\":\n\t\tvalue = new(CircleType)\n")
+	assert.Contains(t, string(generated), "type DrawingRoot struct {")
+	assert.NotContains(t, string(generated), "AnyShapeRoot")
+
+	file = generateFromSource(t, source, "Rust", nil)
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tpub shape: Vec<ShapeTypeDerived>,\n")
+	assert.Contains(t, string(generated), "#[serde(tag = \"@xsi:type\")]\npub enum ShapeTypeDerived {\n\t#[serde(rename = \"CircleType\")]\n\tCircleType(CircleType),\n\t#[serde(rename = \"RoundedSquareType\")]\n\tRoundedSquareType(RoundedSquareType),\n\t#[serde(rename = \"SquareType\")]\n\tSquareType(SquareType),\n}\n")
+
+	file = generateFromSource(t, source, "TypeScript", nil)
+	generated, err = ioutil.ReadFile(file + ".ts")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "export type ShapeTypeDerived = CircleType | RoundedSquareType | SquareType;\n")
+}
+
+func TestGenerateJSONSchemas(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Payment" type="PaymentType"/>
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string"><xs:minLength value="1"/><xs:maxLength value="35"/></xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Amount">
+    <xs:simpleContent>
+      <xs:extension base="xs:decimal"><xs:attribute name="Ccy" type="xs:string" use="required"/></xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:complexType name="PaymentType">
+    <xs:sequence>
+      <xs:element name="Id" type="Max35Text"/>
+      <xs:element name="Amt" type="Amount" maxOccurs="unbounded"/>
+      <xs:element name="Next" type="PaymentType" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	for lang, ext := range map[string]string{"Go": ".go", "Rust": ".rs", "TypeScript": ".ts"} {
+		file := generateFromSource(t, source, lang, func(opt *Options) { opt.JSONSchemas = true })
+		generated, err := ioutil.ReadFile(file + ext)
+		require.NoError(t, err)
+		var schema string
+		switch lang {
+		case "Go":
+			schema = regexp.MustCompile("(?s)const PaymentJSONSchema = `(.*?)`\n").FindStringSubmatch(string(generated))[1]
+		case "Rust":
+			schema = regexp.MustCompile(`(?s)pub const PAYMENT_JSON_SCHEMA: &str = r##"(.*?)"##;`).FindStringSubmatch(string(generated))[1]
+		case "TypeScript":
+			schema = regexp.MustCompile("(?s)export const PaymentJSONSchema = `(.*?)`;").FindStringSubmatch(string(generated))[1]
+		}
+		var document map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(schema), &document), lang)
+		assert.Equal(t, "#/$defs/PaymentType", document["$ref"], lang)
+		defs := document["$defs"].(map[string]interface{})
+		payment := defs["PaymentType"].(map[string]interface{})["properties"].(map[string]interface{})
+		id, amount := "Id", "Amt"
+		if lang == "Rust" {
+			assert.Contains(t, defs["Amount"].(map[string]interface{})["properties"], "$value")
+		} else {
+			assert.Contains(t, defs["Amount"].(map[string]interface{})["properties"], "CcyAttr", lang)
+		}
+		assert.Equal(t, map[string]interface{}{"type": "string", "minLength": 1.0, "maxLength": 35.0}, payment[id], lang)
+		assert.Equal(t, map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/Amount"}}, payment[amount], lang)
+		assert.Equal(t, []interface{}{"Id", "Amt"}, defs["PaymentType"].(map[string]interface{})["required"], lang)
+	}
+
+	file := generateFromSource(t, source, "Go", nil)
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "JSONSchema")
+}
+
+func TestGenerateJavaProject(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Document" type="Document"/>
+  <xs:complexType name="Document">
+    <xs:sequence><xs:element name="Amount" type="Amount"/></xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Amount">
+    <xs:sequence><xs:element name="Value" type="xs:decimal"/></xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Java", func(opt *Options) {
+		opt.Package, opt.JavaProject, opt.ProjectVersion = "com.example.payments", JavaProjectMaven, "2.1.0"
+	})
+	output := filepath.Dir(file)
+	sourceDir := filepath.Join(output, "src", "main", "java")
+	generated, err := ioutil.ReadFile(filepath.Join(sourceDir, "com", "example", "payments", "Amount.java"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\npackage com.example.payments;\n")
+	assert.Contains(t, string(generated), "public class Amount {\n")
+	assert.NotContains(t, string(generated), "public class Document {")
+	generated, err = ioutil.ReadFile(filepath.Join(sourceDir, "com", "example", "payments", "Document.java"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "public class Document {\n")
+	generated, err = ioutil.ReadFile(filepath.Join(sourceDir, "module-info.java"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "module com.example.payments {\n\trequires transitive java.xml.bind;\n\n\texports com.example.payments;\n\n\topens com.example.payments to java.xml.bind;\n}\n")
+	generated, err = ioutil.ReadFile(filepath.Join(output, "pom.xml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\t<groupId>com.example</groupId>\n\t<artifactId>payments</artifactId>\n\t<version>2.1.0</version>\n")
+	assert.NoFileExists(t, file+".java")
+
+	file = generateFromSource(t, source, "Java", func(opt *Options) {
+		opt.JavaProject = JavaProjectGradle
+	})
+	generated, err = ioutil.ReadFile(filepath.Join(filepath.Dir(file), "build.gradle"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "group = 'schema'\nversion = '"+ProjectVersionDefault+"'\n")
+	assert.FileExists(t, filepath.Join(filepath.Dir(file), "src", "main", "java", "schema", "Document.java"))
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(file), "pom.xml"))
+
+	assert.EqualError(t, checkJavaProject("ant"), "unsupport Java project ant, expected maven or gradle")
+}
+
+func TestGenerateNPMPackage(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+  <xs:element name="Document" type="Document"/>
+  <xs:complexType name="Document">
+    <xs:sequence><xs:element name="Nm" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "TypeScript", func(opt *Options) {
+		opt.NPMPackage, opt.ProjectVersion, opt.Constants = "@example/payments", "2.1.0", true
+	})
+	output := filepath.Dir(file)
+	generated, err := ioutil.ReadFile(filepath.Join(output, "index.ts"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\nexport * from './schema.xsd';\nexport * from './schema.xsd.constants';\n")
+	generated, err = ioutil.ReadFile(filepath.Join(output, "package.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "  \"name\": \"@example/payments\",\n  \"version\": \"2.1.0\",\n")
+	assert.Contains(t, string(generated), "  \"types\": \"dist/index.d.ts\",\n")
+	generated, err = ioutil.ReadFile(filepath.Join(output, "tsconfig.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\"declaration\": true,\n")
+
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"pain.001.001.09.xsd", "pain.001.001.11.xsd"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(source), 0644))
+	}
+	files, err := GetFileList(dir)
+	require.NoError(t, err)
+	require.NoError(t, ParseFiles(files, func(file string) *Options {
+		return &Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                "TypeScript",
+			NPMPackage:          "payments",
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}
+	}, nil))
+	generated, err = ioutil.ReadFile(filepath.Join(dir, "output", "index.ts"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\nexport * as pain_001_001_09 from './pain.001.001.09.xsd';\nexport * as pain_001_001_11 from './pain.001.001.11.xsd';\n")
+	assert.NotContains(t, string(generated), "export * from")
+	generated, err = ioutil.ReadFile(filepath.Join(dir, "output", "package.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "  \"version\": \""+ProjectVersionDefault+"\",\n")
+}
+
+func TestGenerateValidateTags(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="CurrencyCode">
+    <xs:restriction base="xs:string"><xs:pattern value="[A-Z]{3,3}"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="CRDT"/>
+      <xs:enumeration value="A,B"/>
+      <xs:enumeration value="two words"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Rate">
+    <xs:restriction base="xs:decimal">
+      <xs:minExclusive value="0"/>
+      <xs:maxInclusive value="100.5"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:group name="Grp">
+    <xs:sequence><xs:element name="Cd" type="Code"/></xs:sequence>
+  </xs:group>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Ccy" type="CurrencyCode" minOccurs="0"/>
+      <xs:element name="Cd" type="Code" maxOccurs="unbounded"/>
+      <xs:element name="Rate" type="Rate"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.ValidateTags = true
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	for _, code := range []string{
+		"`xml:\"Ccy\" validate:\"omitempty,xsd_pattern_b8b9cc7f\"`",
+		"`xml:\"Cd\" validate:\"dive,oneof=CRDT A0x2CB 'two words'\"`",
+		"`xml:\"Rate\" validate:\"gt=0,max=100.5\"`",
+		"\tCd string `validate:\"oneof=CRDT A0x2CB 'two words'\"`\n",
+		"\tvalidatePatterns[\"xsd_pattern_b8b9cc7f\"] = \"^(?:[A-Z]{3,3})$\"\n",
+	} {
+		assert.Contains(t, string(generated), code)
+	}
+	generated, err = ioutil.ReadFile(filepath.Join(filepath.Dir(file), "xsd_validate.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "func RegisterValidations(validate *validator.Validate) error {\n")
+
+	file = generateFromSource(t, source, "Go", nil)
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "validate:")
+}
+
+func TestGenerateArtifacts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(validationTestSchema), 0644))
+	artifacts, err := NewParser(&Options{
+		FilePath:            file,
+		InputDir:            dir,
+		OutputDir:           filepath.Join(dir, "output"),
+		Lang:                "Go",
+		Validation:          ValidationStandalone,
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+	}).Generate()
+	require.NoError(t, err)
+	assert.Len(t, artifacts, 2)
+	assert.Contains(t, string(artifacts["schema.xsd.go"]), "type Party struct {")
+	assert.Contains(t, string(artifacts["schema.xsd.validator.go"]), "func ValidateParty(v *Party) error {")
+	_, err = os.Stat(filepath.Join(dir, "output"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestParseRedefine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "base.xsd"), []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"><xs:maxLength value="10"/></xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Account">
+    <xs:sequence>
+      <xs:element name="Id" type="Code"/>
+    </xs:sequence>
+    <xs:attributeGroup ref="Audit"/>
+  </xs:complexType>
+  <xs:group name="Names">
+    <xs:sequence>
+      <xs:element name="First" type="xs:string"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:attributeGroup name="Audit">
+    <xs:attribute name="created" type="xs:string"/>
+  </xs:attributeGroup>
+</xs:schema>`), 0644))
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:redefine schemaLocation="base.xsd">
+    <xs:simpleType name="Code">
+      <xs:restriction base="Code"><xs:enumeration value="A"/><xs:enumeration value="B"/></xs:restriction>
+    </xs:simpleType>
+    <xs:complexType name="Party">
+      <xs:complexContent>
+        <xs:extension base="Party">
+          <xs:sequence>
+            <xs:element name="Ctry" type="xs:string"/>
+          </xs:sequence>
+        </xs:extension>
+      </xs:complexContent>
+    </xs:complexType>
+    <xs:group name="Names">
+      <xs:sequence>
+        <xs:group ref="Names"/>
+        <xs:element name="Last" type="xs:string"/>
+      </xs:sequence>
+    </xs:group>
+    <xs:attributeGroup name="Audit">
+      <xs:attributeGroup ref="Audit"/>
+      <xs:attribute name="modified" type="xs:string"/>
+    </xs:attributeGroup>
+  </xs:redefine>
+  <xs:element name="Document" type="Party"/>
+</xs:schema>`), 0644))
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"type Code string\n",
+			"\tif utf8.RuneCountInString(string(*v)) > 10 {\n\t\treturn errors.New(\"Code exceeds the maximum length of 10\")\n\t}\n\tswitch string(*v) {\n\tcase \"A\", \"B\":\n",
+			"type Party struct {\n\tNm   string `xml:\"Nm\"`\n\tCtry string `xml:\"Ctry\"`\n}\n",
+			"\tswitch v.Id {\n\tcase \"A\", \"B\":\n",
+			"type Names struct {\n\tFirst string\n\tLast  string\n}\n",
+			"type Audit struct {\n\tCreatedAttr  string `xml:\"created,attr,omitempty\"`\n\tModifiedAttr string `xml:\"modified,attr,omitempty\"`\n}\n",
+		},
+		"TypeScript": {
+			"export class Party {\n\tNm: string;\n\tCtry: string;\n}\n",
+		},
+	} {
+		artifacts, err := NewParser(&Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                lang,
+			Validation:          ValidationMethod,
+			Artifacts:           make(map[string][]byte),
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}).Generate()
+		require.NoError(t, err)
+		data := string(artifacts["schema.xsd."+map[string]string{"Go": "go", "TypeScript": "ts"}[lang]])
+		for _, code := range expected {
+			assert.Contains(t, data, code)
+		}
+		assert.NotContains(t, data, "Party\n\t")
+	}
+}
+
+func TestParseOverride(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "base.xsd"), []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"><xs:maxLength value="10"/></xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="Document">
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Pty" type="Party"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`), 0644))
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:override schemaLocation="base.xsd">
+    <xs:simpleType name="Code">
+      <xs:restriction base="xs:string"><xs:maxLength value="8"/></xs:restriction>
+    </xs:simpleType>
+    <xs:complexType name="Party">
+      <xs:sequence>
+        <xs:element name="Nm" type="xs:string"/>
+        <xs:element name="Ctry" type="Code"/>
+      </xs:sequence>
+    </xs:complexType>
+    <xs:element name="Document">
+      <xs:complexType>
+        <xs:sequence>
+          <xs:element name="Pty" type="Party" maxOccurs="unbounded"/>
+        </xs:sequence>
+      </xs:complexType>
+    </xs:element>
+    <xs:complexType name="Unused">
+      <xs:sequence>
+        <xs:element name="Id" type="xs:string"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:override>
+</xs:schema>`), 0644))
+	artifacts, err := NewParser(&Options{
+		FilePath:            file,
+		InputDir:            dir,
+		OutputDir:           dir,
+		Lang:                "Go",
+		Validation:          ValidationMethod,
+		Artifacts:           make(map[string][]byte),
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+	}).Generate()
+	require.NoError(t, err)
+	data := string(artifacts["schema.xsd.go"])
+	for _, code := range []string{
+		"\tif utf8.RuneCountInString(string(*v)) > 8 {\n\t\treturn errors.New(\"Code exceeds the maximum length of 8\")\n\t}\n",
+		"type Party struct {\n\tNm   string `xml:\"Nm\"`\n\tCtry string `xml:\"Ctry\"`\n}\n",
+		"type Document struct {\n\tPty []*Party `xml:\"Pty\"`\n}\n",
+	} {
+		assert.Contains(t, data, code)
+	}
+	assert.NotContains(t, data, "maximum length of 10")
+	assert.NotContains(t, data, "Unused")
+}
+
+// namespaceOutputNamer routes the generated files into the directory of the
+// namespace named by its version.
+type namespaceOutputNamer struct {
+	DefaultOutputNamer
+}
+
+func (namespaceOutputNamer) OutputPath(opt *Options) string {
+	parts := strings.Split(opt.TargetNamespace, ":")
+	return filepath.Join(opt.OutputDir, "internal", "gen", parts[len(parts)-2], parts[len(parts)-1])
+}
+
+func TestGenerateOutputNamer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:example:payments:v2">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string"><xs:maxLength value="35"/></xs:restriction>
+  </xs:simpleType>
+</xs:schema>`), 0644))
+	for _, lang := range []string{"Go", "Rust"} {
+		artifacts, err := NewParser(&Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                lang,
+			Validation:          ValidationStandalone,
+			OutputNamer:         namespaceOutputNamer{},
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}).Generate()
+		require.NoError(t, err)
+		ext := map[string]string{"Go": "go", "Rust": "rs"}[lang]
+		assert.Len(t, artifacts, 2)
+		assert.Contains(t, string(artifacts[filepath.Join("internal", "gen", "payments", "v2."+ext)]), "Max35Text")
+		assert.Contains(t, string(artifacts[filepath.Join("internal", "gen", "payments", "v2.validator."+ext)]), "Max35Text")
+	}
+}
+
+func TestGenerateLangs(t *testing.T) {
+	source := `<?xml version="1.0" encoding="UTF-8"?>
+<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Document" type="DocumentType"/>
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string"><xs:maxLength value="35"/></xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Codes">
+    <xs:list itemType="xs:token"/>
+  </xs:simpleType>
+  <xs:simpleType name="Amount">
+    <xs:union memberTypes="xs:decimal xs:int"/>
+  </xs:simpleType>
+  <xs:attributeGroup name="Audit">
+    <xs:attribute name="Created" type="xs:dateTime"/>
+    <xs:attribute name="Active" type="xs:boolean"/>
+  </xs:attributeGroup>
+  <xs:group name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="Max35Text"/>
+      <xs:element name="Uri" type="xs:anyURI" minOccurs="0"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:complexType name="Base" abstract="true">
+    <xs:sequence><xs:element name="Id" type="xs:unsignedInt"/></xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Derived">
+    <xs:complexContent>
+      <xs:extension base="Base">
+        <xs:sequence><xs:element name="Dt" type="xs:date"/></xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="DocumentType">
+    <xs:sequence>
+      <xs:group ref="Party"/>
+      <xs:element name="Amt" type="Amount" maxOccurs="unbounded"/>
+      <xs:element name="Cds" type="Codes"/>
+      <xs:element name="Item" type="Base"/>
+      <xs:element name="Any" type="xs:anyType"/>
+    </xs:sequence>
+    <xs:attributeGroup ref="Audit"/>
+  </xs:complexType>
+</xs:schema>`
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(source), 0644))
+	generate := func(lang string, langs []string) map[string][]byte {
+		artifacts, err := NewParser(&Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                lang,
+			Langs:               langs,
+			Validation:          ValidationMethod,
+			BooleanForm:         BooleanFormLiteral,
+			RustChrono:          true,
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}).Generate()
+		require.NoError(t, err)
+		return artifacts
+	}
+	langs, err := ParseLangs("rust,go,ts,Java,c,GO")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Rust", "Go", "TypeScript", "Java", "C"}, langs)
+	artifacts := generate("", langs)
+	var count int
+	for _, lang := range langs {
+		for name, data := range generate(lang, nil) {
+			assert.Equal(t, string(data), string(artifacts[LangDirs[lang]+"/"+name]), name)
+			count++
+		}
+	}
+	assert.Len(t, artifacts, count)
+
+	_, err = ParseLangs("go,cobol")
+	assert.EqualError(t, err, "unsupport language cobol")
+}
+
+func TestGenerateWildcards(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:group name="Ext">
+    <xs:sequence>
+      <xs:element name="Note" type="xs:string"/>
+      <xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:group name="MoreExt">
+    <xs:sequence>
+      <xs:any namespace="##other" processContents="lax" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:attributeGroup name="ExtAttrs">
+    <xs:attribute name="id" type="xs:string"/>
+    <xs:anyAttribute namespace="##other" processContents="lax"/>
+  </xs:attributeGroup>
+  <xs:complexType name="Doc">
+    <xs:sequence>
+      <xs:element name="Title" type="xs:string"/>
+      <xs:any processContents="lax" minOccurs="0" maxOccurs="unbounded"/>
+    </xs:sequence>
+    <xs:anyAttribute processContents="skip"/>
+  </xs:complexType>
+  <xs:complexType name="Extended">
+    <xs:sequence>
+      <xs:group ref="Ext"/>
+      <xs:group ref="MoreExt"/>
+    </xs:sequence>
+    <xs:attributeGroup ref="ExtAttrs"/>
+  </xs:complexType>
+</xs:schema>`
+
+	output := generateFromSource(t, source, "Go", nil)
+	data, err := ioutil.ReadFile(output + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Any     []XSDAnyElement `xml:\",any\"`")
+	assert.Contains(t, string(data), "AnyAttr []xml.Attr      `xml:\",any,attr\"`")
+	assert.Contains(t, string(data), "Any  []XSDAnyElement `xml:\",any\"`")
+	data, err = ioutil.ReadFile(filepath.Join(filepath.Dir(output), "xsd_any.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "type XSDAnyElement struct {")
+
+	data, err = ioutil.ReadFile(generateFromSource(t, source, "Rust", func(opt *Options) { opt.Mixins = true }) + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "pub struct Doc {\n\t#[serde(rename = \"Title\")]\n\tpub title: String,\n\t#[serde(flatten)]\n\tpub any: XsdAnyContent,\n}")
+	assert.Contains(t, string(data), "pub enum XsdAny {")
+	assert.Contains(t, string(data), "pub type XsdAnyContent = std::collections::BTreeMap<String, XsdAny>;")
+	extended := string(data)[strings.Index(string(data), "pub struct Extended {"):]
+	assert.Equal(t, 1, strings.Count(extended[:strings.Index(extended, "}")], "pub any: XsdAnyContent"))
+
+	data, err = ioutil.ReadFile(generateFromSource(t, source, "TypeScript", nil) + ".ts")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "export class Doc {\n\tTitle: string;\n\t[name: string]: unknown;\n}")
+	assert.Contains(t, string(data), "export class ExtAttrs {\n\tIdAttr: string | null;\n\t[name: string]: unknown;\n}")
+
+	data, err = ioutil.ReadFile(generateFromSource(t, source, "Java", func(opt *Options) { opt.Mixins = true }) + ".java")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "import javax.xml.bind.annotation.XmlAnyElement;")
+	assert.Contains(t, string(data), "import javax.xml.namespace.QName;")
+	assert.Contains(t, string(data), "\t@XmlAnyElement(lax = true)\n\tprotected List<Object> any;\n\t@XmlAnyAttribute\n\tprotected Map<QName, String> anyAttr;\n")
+	extended = string(data)[strings.Index(string(data), "public class Extended "):]
+	assert.Equal(t, 1, strings.Count(extended[:strings.Index(extended, "\n}")], "protected List<Object> any;"))
+}
+
+func TestGenerateAttributeWildcardNamespaces(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:doc">
+  <xs:attributeGroup name="ExtAttrs">
+    <xs:attribute name="id" type="xs:string"/>
+    <xs:anyAttribute namespace="##other" processContents="lax"/>
+  </xs:attributeGroup>
+  <xs:complexType name="Doc">
+    <xs:sequence>
+      <xs:element name="Title" type="xs:string"/>
+    </xs:sequence>
+    <xs:anyAttribute namespace="##targetNamespace ##local urn:ext urn:ext" processContents="skip"/>
+  </xs:complexType>
+  <xs:complexType name="Open">
+    <xs:anyAttribute namespace="##any"/>
+  </xs:complexType>
+</xs:schema>`
+
+	output := generateFromSource(t, source, "Go", func(opt *Options) { opt.Validation = ValidationMethod })
+	data, err := ioutil.ReadFile(output + ".go")
+	require.NoError(t, err)
+	skip := "\t\tif attr.Name.Space == \"xmlns\" || attr.Name.Space == \"\" && attr.Name.Local == \"xmlns\" || attr.Name.Space == \"http://www.w3.org/2001/XMLSchema-instance\" {\n\t\t\tcontinue\n\t\t}\n"
+	failure := "\t\t\treturn fmt.Errorf(\"AnyAttr %s in namespace %q is not allowed by the attribute wildcard\", attr.Name.Local, attr.Name.Space)\n"
+	assert.Contains(t, string(data), "func (v *ExtAttrs) Validate() error {\n\tfor _, attr := range v.AnyAttr {\n"+skip+"\t\tswitch attr.Name.Space {\n\t\tcase \"urn:doc\", \"\":\n"+failure+"\t\t}\n\t}\n\treturn nil\n}\n")
+	assert.Contains(t, string(data), "func (v *Doc) Validate() error {\n\tfor _, attr := range v.AnyAttr {\n"+skip+"\t\tswitch attr.Name.Space {\n\t\tcase \"urn:doc\", \"\", \"urn:ext\":\n\t\tdefault:\n"+failure+"\t\t}\n\t}\n\treturn nil\n}\n")
+	assert.Contains(t, string(data), "func (v *Open) Validate() error {\n\treturn nil\n}\n")
+
+	output = generateFromSource(t, source, "Go", nil)
+	data, err = ioutil.ReadFile(output + ".go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "range v.AnyAttr")
+}
+func TestGenerateMixed(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string"><xs:maxLength value="35"/></xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Para" mixed="true">
+    <xs:sequence>
+      <xs:element name="B" type="Max35Text" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:element name="Text" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:string"/>
+  </xs:complexType>
+  <xs:complexType name="Note">
+    <xs:complexContent mixed="true">
+      <xs:restriction base="xs:anyType">
+        <xs:sequence>
+          <xs:element name="I" type="xs:string" minOccurs="0" maxOccurs="unbounded"/>
+        </xs:sequence>
+      </xs:restriction>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="Remark">
+    <xs:complexContent mixed="true">
+      <xs:extension base="Para"/>
+    </xs:complexContent>
+  </xs:complexType>
+</xs:schema>`
+
+	data, err := ioutil.ReadFile(generateFromSource(t, source, "Go", nil) + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\tText     string   `xml:\"Text\"`\n\tCharData string   `xml:\",chardata\"`\n}")
+	assert.Contains(t, string(data), "type Note struct {\n\tI    []string `xml:\"I\"`\n\tText string   `xml:\",chardata\"`\n}")
+	assert.Contains(t, string(data), "type Remark struct {\n\t*Para\n}")
+
+	data, err = ioutil.ReadFile(generateFromSource(t, source, "Rust", func(opt *Options) { opt.Validation = ValidationMethod }) + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "pub struct Para {\n\t#[serde(rename = \"id\")]\n\tpub id: Option<String>,\n\t#[serde(rename = \"$value\", default)]\n\tpub content: Vec<ParaContent>,\n}")
+	assert.Contains(t, string(data), "pub enum ParaContent {\n\t#[serde(rename = \"$text\")]\n\tText(String),\n\t#[serde(rename = \"B\")]\n\tB(String),\n\t#[serde(rename = \"Text\")]\n\tText2(String),\n}")
+	assert.Contains(t, string(data), "\t\tfor val in &self.content {\n\t\t\tval.validate()?;\n\t\t}\n")
+	assert.Contains(t, string(data), "\t\t\tParaContent::B(value) => {\n")
+	assert.Contains(t, string(data), "pub enum NoteContent {\n\t#[serde(rename = \"$text\")]\n\tText(String),\n\t#[serde(rename = \"I\")]\n\tI(String),\n}")
+	assert.NotContains(t, string(data), "RemarkContent")
+}
+
+func TestGenerateProvenance(t *testing.T) {
+	file := generateFromSource(t, validationTestSchema, "Rust", func(opt *Options) {
+		opt.Provenance = true
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte(validationTestSchema))
+	assert.Contains(t, string(generated), "// Code generated by xgen "+Version+". DO NOT EDIT.\n")
+	assert.Contains(t, string(generated), "// Source: schema.xsd (sha256: "+hex.EncodeToString(sum[:])+")\n")
+	assert.NotContains(t, string(generated), "// Generated at:")
+	data, err := ioutil.ReadFile(filepath.Join(filepath.Dir(file), "provenance.json"))
+	require.NoError(t, err)
+	var provenance Provenance
+	require.NoError(t, json.Unmarshal(data, &provenance))
+	assert.Equal(t, "Rust", provenance.Options["lang"])
+	assert.Empty(t, provenance.Timestamp)
+	assert.Equal(t, []SourceProvenance{{File: "schema.xsd", SHA256: hex.EncodeToString(sum[:]), Outputs: []string{"schema.xsd.rs"}}}, provenance.Sources)
+}
+
+func TestGenerateRootWrappers(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:iso:std:iso:20022:tech:xsd:pacs.008.001.08">
+  <xs:element name="Document" type="Document"/>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"type DocumentRoot struct {\n\tXMLName xml.Name `xml:\"urn:iso:std:iso:20022:tech:xsd:pacs.008.001.08 Document\"`\n\tDocument\n}",
+			"func (v *DocumentRoot) ParseXML(data []byte) error {",
+			"func (v *DocumentRoot) ToXML() ([]byte, error) {",
+			"type Document struct {",
+		},
+		"Rust": {
+			"pub struct DocumentRoot {\n\tpub document: Document,\n}",
+			"pub fn parse_xml(xml: &str) -> Result<Self, Box<dyn std::error::Error>> {",
+			"pub fn to_xml(&self) -> Result<String, Box<dyn std::error::Error>> {",
+			"pub struct Document {",
+		},
+		"TypeScript": {
+			"export class DocumentRoot {",
+			"static parseXML(xml: string): DocumentRoot {",
+			"function parseXMLElement(xml: string, name: string): any {",
+			"export class Document {",
+		},
+		"Java": {
+			"@XmlRootElement(name = \"Document\", namespace = \"urn:iso:std:iso:20022:tech:xsd:pacs.008.001.08\")\npublic class DocumentRoot extends Document {",
+			"import javax.xml.bind.JAXBContext;",
+			"public class Document {",
+		},
+		"C": {
+			"#define DOCUMENT_ROOT_ELEMENT_NAME \"Document\"",
+			"int document_root_parse_xml(const char *xml, DocumentRoot *root);",
+			"} Document;",
+		},
+	} {
+		file := generateFromSource(t, source, lang, func(opt *Options) {
+			opt.RootWrappers = true
+		})
+		extension := map[string]string{"Go": ".go", "Rust": ".rs", "TypeScript": ".ts", "Java": ".java", "C": ".h"}[lang]
+		generated, err := ioutil.ReadFile(file + extension)
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+	}
+}
+
+func TestGenerateRootWrapperValidation(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Document" type="Document">
+    <xs:unique name="UniqueId">
+      <xs:selector xpath="Tx"/>
+      <xs:field xpath="Id"/>
+    </xs:unique>
+  </xs:element>
+  <xs:complexType name="Transaction">
+    <xs:sequence>
+      <xs:element name="Id" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="Tx" type="Transaction" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	// The validation of the root element wrapper validates the element,
+	// along with the identity constraints of the document.
+	for validation, expected := range map[string]string{
+		ValidationMethod:     "func (v *DocumentRoot) Validate() error {\n\tif err := v.Document.Validate(); err != nil {\n",
+		ValidationStandalone: "func ValidateDocumentRoot(v *DocumentRoot) error {\n\tif err := ValidateDocument(&v.Document); err != nil {\n",
+	} {
+		file := generateFromSource(t, source, "Go", func(opt *Options) {
+			opt.RootWrappers, opt.Validation = true, validation
+		})
+		generated, err := ioutil.ReadFile(file + ".go")
+		require.NoError(t, err)
+		if validation == ValidationStandalone {
+			generated, err = ioutil.ReadFile(file + ".validator.go")
+			require.NoError(t, err)
+		}
+		assert.Contains(t, string(generated), expected, validation)
+	}
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.RootWrappers, opt.Validation, opt.ValidationMaxDepth = true, ValidationMethod, 8
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "func (v *DocumentRoot) validate(depth int) error {")
+	assert.Contains(t, string(generated), "\tif err := v.Document.validate(depth); err != nil {\n")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(filepath.Dir(file), "schema_test.go"), []byte(`package schema
+
+import "testing"
+
+func TestValidateDocumentRoot(t *testing.T) {
+	var root DocumentRoot
+	if err := root.ParseXML([]byte("<Document><Tx><Id>A</Id></Tx><Tx><Id>A</Id></Tx></Document>")); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.Validate(); err == nil || err.Error() != "Tx[1] duplicates Tx[0] on unique constraint UniqueId" {
+		t.Error(err)
+	}
+}
+`), 0644))
+	runGoPackage(t, filepath.Dir(file), "test")
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.RootWrappers, opt.Validation = true, ValidationMethod
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "impl DocumentRoot {\n\tpub fn validate(&self) -> Result<(), ValidationError> {\n\t\tself.document.validate()?;\n\t\tOk(())\n\t}\n}\n")
+	runRustCrate(t, file+".rs", `
+	#[test]
+	fn validate_document_root() {
+		let root = DocumentRoot::parse_xml("<Document><Tx><Id>A</Id></Tx><Tx><Id>A</Id></Tx></Document>").unwrap();
+		assert!(root.validate().is_err());
+	}
+`)
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.RootWrappers, opt.Validation = true, ValidationStandalone
+	})
+	generated, err = ioutil.ReadFile(file + ".validator.rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "pub fn validate_document_root(v: &DocumentRoot) -> Result<(), ValidationError> {\n\tvalidate_document(&v.document)?;\n")
+}
+
+func TestGenerateNamespacePrefixes(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:test:doc">
+  <xs:element name="Document" type="Document"/>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	prefixes, err := ParseNamespacePrefixes("doc=urn:test:doc")
+	require.NoError(t, err)
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.RootWrappers = true
+		opt.NamespacePrefixes = prefixes
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\treturn prefixXMLNamespace(data, \"doc\", \"urn:test:doc\")\n")
+	namespace, err := ioutil.ReadFile(filepath.Join(filepath.Dir(file), "xml_namespace.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(namespace), "func prefixXMLNamespace(data []byte, prefix, namespace string) ([]byte, error) {")
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.RootWrappers = true
+		opt.NamespacePrefixes = prefixes
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\t\tOk(prefix_xml_namespace(&xml, \"doc\", Self::NAMESPACE)?)\n")
+	assert.Contains(t, string(generated), "fn prefix_xml_namespace(xml: &str, prefix: &str, namespace: &str) -> Result<String, Box<dyn std::error::Error>> {")
+
+	prefixes, err = ParseNamespacePrefixes("=urn:test:doc")
+	require.NoError(t, err)
+	file = generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.RootWrappers = true
+		opt.NamespacePrefixes = prefixes
+	})
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\treturn xml.Marshal(v)\n")
+	_, err = os.Stat(filepath.Join(filepath.Dir(file), "xml_namespace.go"))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = ParseNamespacePrefixes("doc")
+	assert.EqualError(t, err, "invalid namespace prefix doc, expected <prefix>=<namespace>")
+}
+
+func TestGeneratePrettyXML(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:test:doc">
+  <xs:element name="Document" type="Document"/>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.RootWrappers = true
+		opt.PrettyXML = true
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "func (v *DocumentRoot) ToPrettyXML() ([]byte, error) {\n\tdata, err := v.ToXML()\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn prettyXML(data)\n}\n")
+	pretty, err := ioutil.ReadFile(filepath.Join(filepath.Dir(file), "xml_pretty.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(pretty), "func prettyXML(data []byte) ([]byte, error) {")
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.RootWrappers = true
+		opt.PrettyXML = true
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tpub fn to_pretty_xml(&self) -> Result<String, Box<dyn std::error::Error>> {\n\t\tpretty_xml(&self.to_xml()?)\n\t}\n}\n")
+	assert.Equal(t, 1, strings.Count(string(generated), "fn pretty_xml(xml: &str) -> Result<String, Box<dyn std::error::Error>> {"))
+
+	file = generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.RootWrappers = true
+	})
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "ToPrettyXML")
+	_, err = os.Stat(filepath.Join(filepath.Dir(file), "xml_pretty.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateConstants(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:iso:std:iso:20022:tech:xsd:pacs.008.001.08" version="1.2">
+  <xs:element name="Document" type="Document"/>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"const TargetNamespace = \"urn:iso:std:iso:20022:tech:xsd:pacs.008.001.08\"\n",
+			"const SchemaVersion = \"1.2\"\n",
+			"const MessageIdentifier = \"pacs.008.001.08\"\n",
+			"const DocumentElementName = \"Document\"\n",
+			"var RootElementNames = []string{\"Document\"}\n",
+		},
+		"Rust": {
+			"pub const MESSAGE_IDENTIFIER: &str = \"pacs.008.001.08\";\n",
+			"pub const ROOT_ELEMENT_NAMES: &[&str] = &[\"Document\"];\n",
+		},
+		"TypeScript": {
+			"export const SCHEMA_VERSION = \"1.2\";\n",
+			"export const ROOT_ELEMENT_NAMES: readonly string[] = [\"Document\"];\n",
+		},
+		"C": {
+			"#define DOCUMENT_ELEMENT_NAME \"Document\"\n",
+		},
+		"Java": {
+			"public final class SchemaConstants {",
+			"\tpublic static final String TARGET_NAMESPACE = \"urn:iso:std:iso:20022:tech:xsd:pacs.008.001.08\";\n",
+		},
+	} {
+		file := generateFromSource(t, source, lang, func(opt *Options) {
+			opt.Constants = true
+		})
+		extension := map[string]string{"Go": ".go", "Rust": ".rs", "TypeScript": ".ts", "Java": ".java", "C": ".h"}[lang]
+		generated, err := ioutil.ReadFile(file + ".constants" + extension)
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+	}
+}
+
+func TestGenerateSymbolMap(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:example">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="party-name" type="xs:string"/>
+      <xs:element name="type" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="Id" type="xs:string"/>
+  </xs:complexType>
+  <xs:simpleType name="party">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+</xs:schema>`
+	for lang, expected := range map[string][]string{
+		"Go":   {"Party", "IdAttr", "Partyname", "Type", "Party2"},
+		"Rust": {"Party", "id", "partyname", "type_attr", "Party2"},
+		"C":    {"Party", "IdAttr", "Partyname", "Type", "Party"},
+	} {
+		file := generateFromSource(t, source, lang, func(opt *Options) {
+			opt.SymbolMap = true
+		})
+		data, err := ioutil.ReadFile(file + ".symbols.json")
+		require.NoError(t, err)
+		var symbols SymbolMap
+		require.NoError(t, json.Unmarshal(data, &symbols))
+		assert.Equal(t, lang, symbols.Language)
+		assert.Equal(t, "urn:example", symbols.TargetNamespace)
+		require.Len(t, symbols.Symbols, 2, lang)
+		party, simpleType := symbols.Symbols[0], symbols.Symbols[1]
+		assert.Equal(t, Symbol{Kind: KindComplexType, QName: "{urn:example}Party", Identifier: expected[0], Members: []SymbolMember{
+			{Kind: KindAttribute, Name: "Id", Identifier: expected[1]},
+			{Kind: KindElement, Name: "party-name", Identifier: expected[2]},
+			{Kind: KindElement, Name: "type", Identifier: expected[3]},
+		}}, party, lang)
+		assert.Equal(t, Symbol{Kind: KindSimpleType, QName: "{urn:example}party", Identifier: expected[4]}, simpleType, lang)
+	}
+}
+
+func TestGenerateSizeReport(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:example">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:element name="Id" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="A"/>
+      <xs:enumeration value="B"/>
+      <xs:enumeration value="C"/>
+      <xs:enumeration value="D"/>
+      <xs:enumeration value="E"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+	for lang, extension := range map[string]string{"Go": ".go", "Rust": ".rs", "TypeScript": ".ts"} {
+		file := generateFromSource(t, source, lang, func(opt *Options) {
+			opt.SizeReport = true
+			opt.Validation = ValidationStandalone
+		})
+		data, err := ioutil.ReadFile(file + ".sizes.json")
+		require.NoError(t, err)
+		var report SizeReport
+		require.NoError(t, json.Unmarshal(data, &report))
+		assert.Equal(t, lang, report.Language)
+		generated, err := ioutil.ReadFile(file + extension)
+		require.NoError(t, err)
+		require.NotEmpty(t, report.Files, lang)
+		assert.Equal(t, FileSize{Path: file + extension, Lines: strings.Count(string(generated), "\n"), Bytes: len(generated)}, report.Files[0], lang)
+		require.Len(t, report.Types, 2, lang)
+		assert.Equal(t, "{urn:example}Code", report.Types[0].QName, lang)
+		assert.Equal(t, KindComplexType, report.Types[1].Kind, lang)
+		assert.Equal(t, "{urn:example}Party", report.Types[1].QName, lang)
+		assert.True(t, report.Types[0].Bytes >= report.Types[1].Bytes && report.Types[1].Lines > 0, lang)
+	}
+}
+
+func TestGenerateAccessors(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="Id" type="xs:string"/>
+  </xs:complexType>
+</xs:schema>`
+	for lang, expected := range map[string][]string{
+		"Rust": {
+			"\t#[serde(rename = \"Nm\")]\n\tnm: String,\n",
+			"impl Party {\n\tpub fn id(&self) -> &Option<String> {\n\t\t&self.id\n\t}\n",
+			"\tpub fn nm_mut(&mut self) -> &mut String {\n\t\t&mut self.nm\n\t}\n",
+			"\tpub fn set_nm(&mut self, value: String) {\n\t\tself.nm = value;\n\t}\n",
+		},
+		"Java": {
+			"@XmlAccessorType(XmlAccessType.FIELD)\npublic class Party {",
+			"\tprivate String Nm;\n",
+			"\tpublic String getIdAttr() {\n\t\treturn IdAttr;\n\t}\n",
+			"\tpublic void setNm(String value) {\n\t\tthis.Nm = value;\n\t}\n",
+		},
+		"TypeScript": {
+			"\tprivate _IdAttr: string | null;\n\tprivate _Nm: string;\n",
+			"\tget Nm(): string {\n\t\treturn this._Nm;\n\t}\n",
+			"\tset Nm(value: string) {\n\t\tthis._Nm = value;\n\t}\n",
+		},
+	} {
+		file := generateFromSource(t, source, lang, func(opt *Options) {
+			opt.Accessors = true
+		})
+		extension := map[string]string{"Rust": ".rs", "TypeScript": ".ts", "Java": ".java"}[lang]
+		generated, err := ioutil.ReadFile(file + extension)
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+	}
+
+	file := generateFromSource(t, source, "Rust", nil)
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tpub nm: String,\n")
+	assert.NotContains(t, string(generated), "impl Party {")
+}
+
+func TestGeneratePatchTypes(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:element name="Tag" type="xs:string" maxOccurs="unbounded"/>
+    </xs:sequence>
+    <xs:attribute name="Id" type="xs:string"/>
+  </xs:complexType>
+</xs:schema>`
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"type PartyPatch struct {\n\tIdAttr *string  `xml:\"Id,attr,omitempty\"`\n\tNm     *string  `xml:\"Nm,omitempty\"`\n\tTag    []string `xml:\"Tag,omitempty\"`\n}\n",
+			"func (p *PartyPatch) Apply(v *Party) {\n",
+			"\tif p.Nm != nil {\n\t\tv.Nm = *p.Nm\n\t}\n",
+			"\tif p.Tag != nil {\n\t\tv.Tag = p.Tag\n\t}\n",
+		},
+		"Rust": {
+			"pub struct PartyPatch {\n",
+			"\t#[serde(rename = \"Nm\", skip_serializing_if = \"Option::is_none\")]\n\tpub nm: Option<String>,\n",
+			"\tpub fn apply(&self, target: &mut Party) {\n",
+			"\t\tif let Some(v) = &self.id {\n\t\t\ttarget.id = Some(v.clone());\n\t\t}\n",
+			"\t\tif let Some(v) = &self.nm {\n\t\t\ttarget.nm = v.clone();\n\t\t}\n",
+		},
+	} {
+		file := generateFromSource(t, source, lang, func(opt *Options) {
+			opt.PatchTypes = true
+		})
+		extension := map[string]string{"Go": ".go", "Rust": ".rs"}[lang]
+		generated, err := ioutil.ReadFile(file + extension)
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+	}
+
+	file := generateFromSource(t, source, "Go", nil)
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "PartyPatch")
+}
+
+func TestGenerateVisitor(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Leaf">
+    <xs:attribute name="Id" type="xs:string"/>
+  </xs:complexType>
+  <xs:complexType name="Node">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:element name="Child" type="Node" minOccurs="0" maxOccurs="unbounded"/>
+      <xs:element name="Leaf" type="Leaf" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"type Visitor interface {\n\tVisitLeaf(v *Leaf)\n\tVisitNode(v *Node)\n}\n",
+			"func (BaseVisitor) VisitNode(*Node) {}\n",
+			"func (v *Node) Accept(visitor Visitor) {\n\tif v == nil {\n\t\treturn\n\t}\n\tvisitor.VisitNode(v)\n\tfor i := range v.Child {\n\t\tv.Child[i].Accept(visitor)\n\t}\n\tv.Leaf.Accept(visitor)\n}\n",
+		},
+		"Rust": {
+			"pub trait Visitor {\n\tfn visit_leaf(&mut self, _v: &Leaf) {}\n\tfn visit_node(&mut self, _v: &Node) {}\n}\n",
+			"pub trait VisitorMut {\n\tfn visit_leaf_mut(&mut self, _v: &mut Leaf) {}\n",
+			"impl<T: Accept> Accept for Vec<T> {\n",
+			"impl Accept for Node {\n\tfn accept<V: Visitor + ?Sized>(&self, visitor: &mut V) {\n\t\tvisitor.visit_node(self);\n\t\tself.child.accept(visitor);\n\t\tself.leaf.accept(visitor);\n\t}\n}\n",
+			"impl AcceptMut for Node {\n\tfn accept_mut<V: VisitorMut + ?Sized>(&mut self, visitor: &mut V) {\n\t\tvisitor.visit_node_mut(self);\n",
+		},
+	} {
+		file := generateFromSource(t, source, lang, func(opt *Options) {
+			opt.Visitor = true
+		})
+		extension := map[string]string{"Go": ".go", "Rust": ".rs"}[lang]
+		generated, err := ioutil.ReadFile(file + extension)
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+	}
+
+	file := generateFromSource(t, source, "Rust", nil)
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "Visitor")
+}
+
+func TestGenerateEquality(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="CurrencyCode">
+    <xs:restriction base="xs:string"><xs:pattern value="[A-Z]{3}"/></xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Amount">
+    <xs:simpleContent>
+      <xs:extension base="xs:decimal">
+        <xs:attribute name="Ccy" type="CurrencyCode" use="required"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:token"><xs:maxLength value="35"/></xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="Max35Text"/>
+      <xs:element name="Amt" type="Amount" minOccurs="0"/>
+      <xs:element name="Hist" type="Amount" maxOccurs="unbounded"/>
+      <xs:element name="Nb" type="xs:int"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"func (v *Amount) Equal(o *Amount) bool {\n\tif v == nil || o == nil {\n\t\treturn v == o\n\t}\n\tif strings.ToUpper(strings.TrimSpace(v.CcyAttr)) != strings.ToUpper(strings.TrimSpace(o.CcyAttr)) {\n\t\treturn false\n\t}\n\tif v.Value != o.Value {\n\t\treturn false\n\t}\n\treturn true\n}\n",
+			"\tif strings.Join(strings.Fields(v.Nm), \" \") != strings.Join(strings.Fields(o.Nm), \" \") {\n",
+			"\tif !v.Amt.Equal(o.Amt) {\n",
+			"\tfor i := range v.Hist {\n\t\tif !v.Hist[i].Equal(o.Hist[i]) {\n",
+			"\tif v.Nb != o.Nb {\n",
+		},
+		"Rust": {
+			"impl Amount {\n\t/// Returns whether the values of the Amount are the same as the values of\n\t/// the other one in the canonical form implied by the facets of the schema.\n\tpub fn canonical_eq(&self, other: &Self) -> bool {\n\t\tif self.ccy.trim().to_string().to_uppercase() != other.ccy.trim().to_string().to_uppercase() {\n\t\t\treturn false;\n\t\t}\n\t\tif self.value != other.value {\n",
+			"\t\tif self.amt.is_some() != other.amt.is_some() || self.amt.iter().zip(other.amt.iter()).any(|(a, b)| !a.canonical_eq(b)) {\n",
+			"\t\tif self.hist.len() != other.hist.len() || self.hist.iter().zip(other.hist.iter()).any(|(a, b)| !a.canonical_eq(b)) {\n",
+			"\t\tif self.nb != other.nb {\n",
+		},
+	} {
+		file := generateFromSource(t, source, lang, func(opt *Options) {
+			opt.Equality = true
+		})
+		extension := map[string]string{"Go": ".go", "Rust": ".rs"}[lang]
+		generated, err := ioutil.ReadFile(file + extension)
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+	}
+
+	file := generateFromSource(t, source, "Go", nil)
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.NotContains(t, string(generated), "Equal(")
+}
+
+func TestGenerateSensitiveFields(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string">
+        <xs:annotation>
+          <xs:appinfo>sensitive</xs:appinfo>
+        </xs:annotation>
+      </xs:element>
+      <xs:element name="Ctry" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="Id" type="xs:string">
+      <xs:annotation>
+        <xs:appinfo>sensitive</xs:appinfo>
+      </xs:annotation>
+    </xs:attribute>
+  </xs:complexType>
+</xs:schema>`
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"\tIdAttr string `xml:\"Id,attr,omitempty\" sensitive:\"true\"`\n",
+			"\tNm     string `xml:\"Nm\" sensitive:\"true\"`\n",
+			"\tCtry   string `xml:\"Ctry\"`\n",
+		},
+		"Rust": {
+			"\t/// The value is sensitive, it's blanked by the redact method.\n\t#[serde(rename = \"Nm\")]\n\tpub nm: String,\n\t#[serde(rename = \"Ctry\")]\n",
+			"impl Party {\n\tpub const SENSITIVE_FIELDS: &'static [&'static str] = &[\"Id\", \"Nm\"];\n\n\tpub fn redact(&mut self) {\n\t\tself.id = Default::default();\n\t\tself.nm = Default::default();\n\t}\n}\n",
+		},
+	} {
+		file := generateFromSource(t, source, lang, nil)
+		extension := map[string]string{"Go": ".go", "Rust": ".rs"}[lang]
+		generated, err := ioutil.ReadFile(file + extension)
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+	}
+}
+
+func TestGenerateDeprecations(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string">
+        <xs:annotation>
+          <xs:appinfo>deprecated: use the
+            Id instead</xs:appinfo>
+        </xs:annotation>
+      </xs:element>
+      <xs:element name="Ctry" type="xs:string"/>
+      <xs:element name="AdrLine" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="Id" type="xs:string">
+      <xs:annotation>
+        <xs:appinfo>deprecated</xs:appinfo>
+      </xs:annotation>
+    </xs:attribute>
+  </xs:complexType>
+</xs:schema>`
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "deprecations.txt")
+	require.NoError(t, ioutil.WriteFile(file, []byte("# The country moved.\n\nParty.Ctry = use the PstlAdr instead\nAdrLine\n"), 0644))
+	deprecations, err := ReadDeprecations(file)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Party.Ctry": "use the PstlAdr instead", "AdrLine": ""}, deprecations)
+	adjust := func(opt *Options) { opt.Deprecations = deprecations }
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"\t// Deprecated: The member is deprecated by the schema.\n\tIdAttr string `xml:\"Id,attr,omitempty\"`\n",
+			"\t// Deprecated: use the Id instead\n\tNm string `xml:\"Nm\"`\n",
+			"\t// Deprecated: use the PstlAdr instead\n\tCtry string `xml:\"Ctry\"`\n",
+		},
+		"Rust": {
+			"#![allow(deprecated)]\n\nuse serde::",
+			"\t#[deprecated]\n\t#[serde(rename = \"Id\")]\n",
+			"\t#[deprecated(note = \"use the Id instead\")]\n\t#[serde(rename = \"Nm\")]\n",
+			"\t#[deprecated]\n\t#[serde(rename = \"AdrLine\")]\n",
+		},
+		"Java": {
+			"\t@Deprecated\n\t@XmlAttribute(name = \"Id\")\n",
+			"\t/** @deprecated use the Id instead */\n\t@Deprecated\n\t@XmlElement(required = true, name = \"Nm\")\n",
+		},
+		"TypeScript": {
+			"\t/** @deprecated */\n\tIdAttr: string | null;\n",
+			"\t/** @deprecated use the PstlAdr instead */\n\tCtry: string;\n",
+		},
+	} {
+		extension := map[string]string{"Go": ".go", "Rust": ".rs", "Java": ".java", "TypeScript": ".ts"}[lang]
+		generated, err := ioutil.ReadFile(generateFromSource(t, source, lang, adjust) + extension)
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+	}
+
+	assert.Equal(t, "AdrLine=,Party.Ctry=use the PstlAdr instead", formatDeprecations(deprecations))
+	require.NoError(t, ioutil.WriteFile(file, []byte("=reason\n"), 0644))
+	_, err = ReadDeprecations(file)
+	assert.EqualError(t, err, "invalid deprecation at "+file+":1, expected <name> or <name>=<reason>")
+}
+
+func TestGenerateCustomizations(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:annotation>
+      <xs:appinfo>rust-derive: Eq, Hash</xs:appinfo>
+      <xs:appinfo>rust-attr: #[serde(deny_unknown_fields)]</xs:appinfo>
+    </xs:annotation>
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string">
+        <xs:annotation>
+          <xs:appinfo>go-tag: json:"name" xml:"Name"
+            rust-attr: #[serde(alias = "Name")]</xs:appinfo>
+        </xs:annotation>
+      </xs:element>
+      <xs:element name="Ctry" type="xs:string"/>
+    </xs:sequence>
+    <xs:attribute name="Id" type="xs:string">
+      <xs:annotation>
+        <xs:appinfo>go-tag: json id</xs:appinfo>
+      </xs:annotation>
+    </xs:attribute>
+  </xs:complexType>
+  <xs:element name="Doc">
+    <xs:annotation>
+      <xs:appinfo>rust-derive: Eq</xs:appinfo>
+    </xs:annotation>
+    <xs:complexType>
+      <xs:sequence>
+        <xs:element name="Pty" type="Party"/>
+      </xs:sequence>
+    </xs:complexType>
+  </xs:element>
+</xs:schema>`
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "customizations.txt")
+	require.NoError(t, ioutil.WriteFile(file, []byte("# The country is a code.\n\nParty.Ctry = go-tag: json:\"country\"\n*.Ctry=rust-attr: #[serde(default)]\nParty=rust-derive: Eq, PartialOrd\n"), 0644))
+	customizations, err := ReadCustomizations(file)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"Party.Ctry": {`go-tag: json:"country"`}, "*.Ctry": {"rust-attr: #[serde(default)]"}, "Party": {"rust-derive: Eq, PartialOrd"}}, customizations)
+	var opt *Options
+	adjust := func(o *Options) { o.Customizations, opt = customizations, o }
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"\tIdAttr string `xml:\"Id,attr,omitempty\"`\n",
+			"\tNm     string `xml:\"Name\" json:\"name\"`\n",
+			"\tCtry   string `xml:\"Ctry\" json:\"country\"`\n",
+		},
+		"Rust": {
+			"#[derive(Debug, Default, PartialEq, Clone, Serialize, Deserialize, Eq, Hash, PartialOrd)]\n#[serde(deny_unknown_fields)]\npub struct Party {\n",
+			"\t#[serde(alias = \"Name\")]\n\t#[serde(rename = \"Nm\")]\n",
+			"\t#[serde(default)]\n\t#[serde(rename = \"Ctry\")]\n",
+			"#[derive(Debug, Default, PartialEq, Clone, Serialize, Deserialize, Eq)]\npub struct Doc {\n",
+		},
+	} {
+		generated, err := ioutil.ReadFile(generateFromSource(t, source, lang, adjust) + map[string]string{"Go": ".go", "Rust": ".rs"}[lang])
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+		assert.Equal(t, []string{`ignored the customization of the attribute: invalid Go struct tag json id, expected key:"value" pairs`}, getWarningMessages(opt.Warnings), lang)
+	}
+
+	assert.Equal(t, `*.Ctry=rust-attr: #[serde(default)],Party=rust-derive: Eq, PartialOrd,Party.Ctry=go-tag: json:"country"`, formatCustomizations(customizations))
+	require.NoError(t, ioutil.WriteFile(file, []byte("Party=rust-attr: serde(default)\n"), 0644))
+	_, err = ReadCustomizations(file)
+	assert.EqualError(t, err, "invalid customization at "+file+":1: invalid Rust attribute serde(default), expected #[...]")
+	require.NoError(t, ioutil.WriteFile(file, []byte("Party=derive: Eq\n"), 0644))
+	_, err = ReadCustomizations(file)
+	assert.EqualError(t, err, "invalid customization at "+file+":1, expected the rust-derive, rust-attr or go-tag keyword")
+}
+
+func TestGenerateExternalTypes(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:ds="http://www.w3.org/2000/09/xmldsig#">
+  <xs:import namespace="http://www.w3.org/2000/09/xmldsig#" schemaLocation="xmldsig-core-schema.xsd"/>
+  <xs:complexType name="Doc">
+    <xs:sequence>
+      <xs:element name="Id" type="xs:string"/>
+      <xs:element name="Sgntr" type="ds:SignatureType" minOccurs="0"/>
+      <xs:element name="Key" type="ds:KeyInfoType" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "external-types.txt")
+	require.NoError(t, ioutil.WriteFile(file, []byte("# XML Signature\n\nRust {http://www.w3.org/2000/09/xmldsig#}SignatureType=xmldsig::Signature\nRust {http://www.w3.org/2000/09/xmldsig#}KeyInfoType=xmldsig::KeyInfo\nGo {http://www.w3.org/2000/09/xmldsig#}SignatureType=github.com/russellhaering/goxmldsig/types.Signature\nGo {http://www.w3.org/2000/09/xmldsig#}KeyInfoType=github.com/russellhaering/goxmldsig/types.KeyInfo\n"), 0644))
+	externalTypes, err := ReadExternalTypes(file)
+	require.NoError(t, err)
+	assert.Equal(t, "xmldsig::Signature", externalTypes["Rust"]["http://www.w3.org/2000/09/xmldsig#"]["SignatureType"])
+	for lang, expected := range map[string][]string{
+		"Go": {
+			"import (\n\t\"github.com/russellhaering/goxmldsig/types\"\n)\n",
+			"\tSgntr types.Signature `xml:\"Sgntr\"`\n",
+			"\tKey   []types.KeyInfo `xml:\"Key\"`\n",
+		},
+		"Rust": {
+			"\tpub sgntr: Option<xmldsig::Signature>,\n",
+			"\tpub key: Vec<xmldsig::KeyInfo>,\n",
+		},
+	} {
+		generated, err := ioutil.ReadFile(generateFromSource(t, source, lang, func(opt *Options) {
+			opt.ExternalTypes = externalTypes
+		}) + map[string]string{"Go": ".go", "Rust": ".rs"}[lang])
+		require.NoError(t, err)
+		for _, code := range expected {
+			assert.Contains(t, string(generated), code, lang)
+		}
+	}
+
+	// The schemas of the external namespaces aren't generated.
+	output := generateFromSource(t, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="http://www.w3.org/2000/09/xmldsig#">
+  <xs:complexType name="KeyInfoType"/>
+</xs:schema>`, "Rust", func(opt *Options) {
+		opt.ExternalTypes = externalTypes
+	})
+	_, err = os.Stat(output + ".rs")
+	assert.True(t, os.IsNotExist(err))
+	// The external types are kept by the options of each parse.
+	assert.False(t, isBuiltInTypeByLang("Rust", "xmldsig::Signature"))
+	assert.False(t, isBuiltInTypeByLang("Go", "types.Signature"))
+
+	externalTypes = ExternalTypes{}
+	require.NoError(t, externalTypes.Register("Rust", "http://www.w3.org/2000/09/xmldsig#", "SignatureType", "xmldsig::Signature"))
+	opt := &Options{Lang: "Rust", ExternalTypes: externalTypes, LocalNameNSMap: map[string]string{"ds": "http://www.w3.org/2000/09/xmldsig#"}}
+	_, err = opt.GetValueType("ds:KeyInfoType", nil)
+	assert.EqualError(t, err, "no Rust type registered for {http://www.w3.org/2000/09/xmldsig#}KeyInfoType of the external namespace")
+	assert.EqualError(t, externalTypes.Register("Kotlin", "urn:x", "Type", "Type"), "unknown language Kotlin of the external type Type")
+	assert.EqualError(t, externalTypes.Register("Rust", "http://www.w3.org/2001/XMLSchema", "string", "String"), `invalid namespace "http://www.w3.org/2001/XMLSchema" of the external type String`)
+	assert.Equal(t, "Rust {http://www.w3.org/2000/09/xmldsig#}SignatureType=xmldsig::Signature", formatExternalTypes(externalTypes))
+	require.NoError(t, ioutil.WriteFile(file, []byte("Rust SignatureType=xmldsig::Signature\n"), 0644))
+	_, err = ReadExternalTypes(file)
+	assert.EqualError(t, err, "invalid external type at "+file+":1, expected <lang> {<namespace>}<name>=<type>")
+}
+
+func TestGeneratePruneUnused(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:element name="Pty" type="Party"/>
+  <xs:complexType name="Party">
+    <xs:group ref="PartyGroup"/>
+  </xs:complexType>
+  <xs:group name="PartyGroup">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:complexType name="Unused">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.PruneUnused = true
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "type Party struct {")
+	assert.Contains(t, string(generated), "type PartyGroup struct {")
+	assert.NotContains(t, string(generated), "Unused")
+
+	file = generateFromSource(t, validationTestSchema, "Go", func(opt *Options) {
+		opt.PruneUnused = true
+	})
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "type Party struct {")
+}
+
+func TestGenerateSharedAnonymousTypes(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Debtor">
+    <xs:sequence>
+      <xs:element name="Cd">
+        <xs:complexType>
+          <xs:sequence><xs:element name="Val" type="xs:string"/></xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Creditor">
+    <xs:sequence>
+      <xs:element name="Code">
+        <xs:complexType>
+          <xs:sequence><xs:element name="Val" type="xs:string"/></xs:sequence>
+        </xs:complexType>
+      </xs:element>
+      <xs:element name="Prtry">
+        <xs:complexType>
+          <xs:sequence><xs:element name="Id" type="xs:string"/></xs:sequence>
+        </xs:complexType>
+      </xs:element>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.Validation = ValidationStandalone
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "type Cd struct {")
+	assert.Contains(t, string(generated), "type Code = Cd\n")
+	assert.Contains(t, string(generated), "type Prtry struct {")
+	validator, err := ioutil.ReadFile(file + ".validator.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(validator), "func ValidateCode(v *Code) error {\n\treturn ValidateCd(v)\n}")
+
+	for lang, expected := range map[string]string{
+		"Rust":       "pub type Code = Cd;\n",
+		"TypeScript": "export type Code = Cd;\n",
+		"C":          "typedef Cd Code;\n",
+		"Java":       "public class Code extends Cd {\n}\n",
+	} {
+		file = generateFromSource(t, source, lang, nil)
+		generated, err = ioutil.ReadFile(file + map[string]string{"Rust": ".rs", "TypeScript": ".ts", "C": ".h", "Java": ".java"}[lang])
+		require.NoError(t, err)
+		assert.Contains(t, string(generated), expected, lang)
+	}
+}
+
+func TestGenerateComments(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:annotation>
+      <xs:documentation>Identification of a person or an organisation
+        taking part in the payment.
+
+        Either the name or the identification must be present.</xs:documentation>
+    </xs:annotation>
+    <xs:sequence><xs:element name="Nm" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.CommentStyle = "triple-slash"
+		opt.CommentWidth = 40
+	})
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "/// Party is Identification of a person\r\n/// or an organisation taking part in\r\n/// the payment.\r\n///\r\n/// Either the name or the\r\n/// identification must be present.\r\n#[derive(")
+
+	file = generateFromSource(t, source, "Java", func(opt *Options) {
+		opt.CommentStyle = "block"
+	})
+	generated, err = ioutil.ReadFile(file + ".java")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "/**\r\n * Party is Identification of a person or an organisation\r\n")
+	assert.Contains(t, string(generated), " */\r\npublic class Party {")
+
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "schema.xsd"), []byte(source), 0644))
+	err = NewParser(&Options{
+		FilePath:            filepath.Join(dir, "schema.xsd"),
+		OutputDir:           dir,
+		Lang:                "Go",
+		CommentStyle:        "semicolon",
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+	}).Parse()
+	assert.EqualError(t, err, "unsupport comment style semicolon, expected one of block docstring hash slash triple-slash")
+}
+
+func TestGenerateUntypedDeclarations(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:element name="Extra"/>
+      <xs:element name="Any" type="xs:anySimpleType"/>
+      <xs:element name="Adr">
+        <xs:complexType><xs:sequence><xs:element name="Line" type="xs:string"/></xs:sequence></xs:complexType>
+      </xs:element>
+    </xs:sequence>
+    <xs:attribute name="flag"/>
+  </xs:complexType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Rust", func(o *Options) { opt = o })
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tpub extra: String,\n")
+	assert.Contains(t, string(generated), "\tpub any: String,\n")
+	assert.Contains(t, string(generated), "\tpub flag: Option<String>,\n")
+	assert.Contains(t, string(generated), "\tpub adr: Adr,\n")
+	assert.Equal(t, []string{"declarations without type mapped to String: Party/Extra, Party/@flag"}, getWarningMessages(opt.Warnings))
+
+	file = generateFromSource(t, source, "Go", func(o *Options) { o.AnyTypeFallback = "json.RawMessage" })
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\t\"encoding/json\"\n")
+	assert.Contains(t, string(generated), "\tExtra    json.RawMessage `xml:\"Extra\"`\n")
+	assert.Contains(t, string(generated), "\tAny      json.RawMessage `xml:\"Any\"`\n")
+	assert.Contains(t, string(generated), "\tAdr      *Adr            `xml:\"Adr\"`\n")
+	// The fallback is kept by the options, instead of the built-in types of
+	// the language shared by the other parses.
+	assert.False(t, isBuiltInTypeByLang("Go", "json.RawMessage"))
+
+	file = generateFromSource(t, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="json.RawMessage">
+    <xs:sequence><xs:element name="Nm" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+</xs:schema>`, "Go", nil)
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "type JsonRawMessage struct {\n")
+}
+
+func TestGenerateReservedTypeRenames(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Result">
+    <xs:sequence>
+      <xs:element name="Cd" type="xs:string"/>
+      <xs:element name="Box" type="Box" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Box">
+    <xs:sequence><xs:element name="Nm" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="BoxType">
+    <xs:sequence><xs:element name="Rslt" type="Result"/></xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	var opt *Options
+	file := generateFromSource(t, source, "Rust", func(o *Options) { opt = o })
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "pub struct ResultType {\n\t#[serde(rename = \"Cd\")]\n\tpub cd: String,\n\t#[serde(rename = \"Box\")]\n\tpub box_attr: Vec<BoxType2>,\n}\n")
+	assert.Contains(t, string(generated), "pub struct BoxType2 {\n")
+	assert.Contains(t, string(generated), "\tpub rslt: ResultType,\n")
+	assert.NotContains(t, string(generated), "pub struct Result ")
+	assert.Equal(t, []string{"renamed types colliding with reserved Rust identifiers: Result to ResultType, Box to BoxType2"}, getWarningMessages(opt.Warnings))
+
+	file = generateFromSource(t, source, "Java", nil)
+	generated, err = ioutil.ReadFile(file + ".java")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "public class Result {")
+}
+
+func TestGenerateMixins(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:attributeGroup name="common-attrs">
+    <xs:attribute name="id" type="xs:string" use="required"/>
+  </xs:attributeGroup>
+  <xs:group name="Contact">
+    <xs:sequence>
+      <xs:element name="Email" type="xs:string"/>
+    </xs:sequence>
+  </xs:group>
+  <xs:complexType name="Person">
+    <xs:sequence>
+      <xs:element name="Name" type="xs:string"/>
+      <xs:group ref="Contact"/>
+    </xs:sequence>
+    <xs:attributeGroup ref="common-attrs"/>
+  </xs:complexType>
+</xs:schema>`
+	mixins := func(opt *Options) { opt.Mixins = true }
+	file := generateFromSource(t, source, "Go", mixins)
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "type Person struct {\n\t*Commonattrs\n\t*Contact\n\tName string `xml:\"Name\"`\n}\n")
+	assert.NotContains(t, string(generated), "XMLName")
+
+	file = generateFromSource(t, source, "Rust", mixins)
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "pub trait CommonattrsMixin {\n\tfn id(&self) -> &String;\n\tfn id_mut(&mut self) -> &mut String;\n}\n")
+	assert.Contains(t, string(generated), "macro_rules! impl_commonattrs_mixin {\n\t($t:ty) => {\n\t\timpl CommonattrsMixin for $t {\n\t\t\tfn id(&self) -> &String {\n\t\t\t\t&self.id\n")
+	assert.Contains(t, string(generated), "pub struct Person {\n\t#[serde(rename = \"id\")]\n\tpub id: String,\n\t#[serde(rename = \"Email\")]\n\tpub email: String,\n")
+	assert.Contains(t, string(generated), "\nimpl_commonattrs_mixin!(Person);\n\nimpl_contact_mixin!(Person);\n")
+	assert.Less(t, strings.Index(string(generated), "macro_rules! impl_contact_mixin"), strings.Index(string(generated), "impl_contact_mixin!(Contact)"))
+
+	file = generateFromSource(t, source, "Java", mixins)
+	generated, err = ioutil.ReadFile(file + ".java")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "public interface CommonattrsMixin {\n\tString getId();\n\tvoid setId(String value);\n}\n")
+	assert.Contains(t, string(generated), "public class Person implements CommonattrsMixin, ContactMixin {\n\t@XmlAttribute(name = \"id\", required = true)\n\tprotected String IdAttr;\n")
+	assert.Contains(t, string(generated), "\tpublic String getId() {\n\t\treturn IdAttr;\n\t}\n")
+
+	file = generateFromSource(t, source, "Go", nil)
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tCommonattrs *Commonattrs\n")
+}
+
+func TestGenerateBooleanForms(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Opts">
+    <xs:sequence>
+      <xs:element name="Active" type="xs:boolean"/>
+      <xs:element name="Bits" type="xs:boolean" maxOccurs="unbounded"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) { opt.BooleanForm = BooleanFormNumeric })
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tActive XSDBoolean   `xml:\"Active\"`\n\tBits   []XSDBoolean `xml:\"Bits\"`\n")
+	boolean, err := ioutil.ReadFile(filepath.Join(filepath.Dir(file), "xsd_boolean.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(boolean), "\tcase \"true\", \"1\":\n\t\t*b = true\n")
+	assert.Contains(t, string(boolean), "\tif b {\n\t\treturn []byte(\"1\"), nil\n\t}\n\treturn []byte(\"0\"), nil\n")
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) { opt.BooleanForm = BooleanFormLiteral })
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "pub struct XsdBoolean(pub bool);\n")
+	assert.Contains(t, string(generated), "serializer.serialize_str(if self.0 { \"true\" } else { \"false\" })")
+	assert.Contains(t, string(generated), "\tpub bits: Vec<XsdBoolean>,\n")
+
+	file = generateFromSource(t, source, "Go", nil)
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tActive bool   `xml:\"Active\"`\n")
+	_, err = os.Stat(filepath.Join(filepath.Dir(file), "xsd_boolean.go"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.EqualError(t, checkBooleanForm("yes"), "unsupport boolean form yes, expected literal or numeric")
+}
+
+func TestGenerateDecimalForms(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal">
+      <xs:fractionDigits value="2"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Payment">
+    <xs:sequence>
+      <xs:element name="Amt" type="Amount"/>
+      <xs:element name="Rate" type="xs:decimal"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) { opt.DecimalForm = DecimalFormFixedScale })
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tAmt  XSDDecimalScale2 `xml:\"Amt\"`\n\tRate XSDDecimal       `xml:\"Rate\"`\n")
+	decimal, err := ioutil.ReadFile(filepath.Join(filepath.Dir(file), "xsd_decimal.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(decimal), "type XSDDecimal float64\n")
+	assert.Contains(t, string(decimal), "\treturn formatXSDDecimal(float64(d), 2)\n")
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) { opt.DecimalForm = DecimalFormCanonical })
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "pub struct XsdDecimal(pub f64);\n")
+	assert.Contains(t, string(generated), "\tpub amt: XsdDecimal,\n")
+	assert.NotContains(t, string(generated), "XsdDecimalScale2")
+
+	file = generateFromSource(t, source, "Go", nil)
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "\tRate float64 `xml:\"Rate\"`\n")
+	_, err = os.Stat(filepath.Join(filepath.Dir(file), "xsd_decimal.go"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.EqualError(t, checkDecimalForm("exact"), "unsupport decimal form exact, expected canonical or fixed-scale")
+}
+
+func TestGenerateTypeAliases(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.TypeAliases = true
+		opt.Validation = ValidationMethod
+	})
+	generated, err := ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "type Code = string\n")
+	assert.Contains(t, string(generated), "type Max35Text string\n")
+	assert.NotContains(t, string(generated), "func (v *Code) Validate() error {")
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.TypeAliases = true
+	})
+	generated, err = ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "pub type Code = String;\n")
+	assert.Contains(t, string(generated), "pub struct Max35Text {")
+
+	file = generateFromSource(t, source, "Go", nil)
+	generated, err = ioutil.ReadFile(file + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "type Code string\n")
+}
+
+func TestGenerateRustUnionEnum(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="ExternalCode">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="ACCP"/>
+      <xs:enumeration value="RJCT"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="ProprietaryCode">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="RJCT"/>
+      <xs:enumeration value="x-pending"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="StatusCode">
+    <xs:union memberTypes="ExternalCode ProprietaryCode"/>
+  </xs:simpleType>
+  <xs:simpleType name="StatusText">
+    <xs:union memberTypes="ExternalCode Max35Text"/>
+  </xs:simpleType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", nil)
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "pub enum StatusCode {\n\t#[default]\n")
+	assert.Equal(t, 1, strings.Count(string(generated), `#[serde(rename = "RJCT")]`))
+	assert.Contains(t, string(generated), "\t#[serde(rename = \"x-pending\")]\n\tXPending,\n")
+	assert.Contains(t, string(generated), "pub enum StatusText {\n\tExternalCode(String),\n\tMax35Text(String),\n}")
+	assert.Equal(t, "Value1st", genRustEnumVariantName("1st"))
+	assert.Equal(t, "SelfValue", genRustEnumVariantName("self"))
+}
+
+func TestGenerateRustUnion(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Flag">
+    <xs:union memberTypes="xs:date xs:int xs:boolean xs:string"/>
+  </xs:simpleType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", nil)
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	code := string(generated)
+	assert.Contains(t, code, "use regex::Regex;")
+	assert.Contains(t, code, "#[derive(Debug, PartialEq, Clone)]\npub enum Flag {\n\tDate(String),\n\tInt(i32),\n\tBoolean(bool),\n\tString(String),\n}")
+	assert.Contains(t, code, "\t\tFlag::Date(Default::default())\n")
+	assert.Contains(t, code, "\t\t\tFlag::Int(v) => v.serialize(serializer),\n")
+	assert.NotContains(t, code, "pub struct Flag {")
+
+	// The members are tried in the order of the union.
+	date := strings.Index(code, "return Ok(Flag::Date(v));")
+	number := strings.Index(code, "if let Some(v) = value.trim().parse::<i32>().ok() {\n\t\t\treturn Ok(Flag::Int(v));")
+	boolean := strings.Index(code, "return Ok(Flag::Boolean(v));")
+	text := strings.Index(code, "if let Some(v) = value.parse::<String>().ok() {\n\t\t\treturn Ok(Flag::String(v));")
+	assert.True(t, date > 0 && date < number && number < boolean && boolean < text)
+	assert.Contains(t, code, `Err(serde::de::Error::custom(format!("invalid Flag value {:?}", value)))`)
+}
+
+func TestGenerateRustExtensionConversions(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence><xs:element name="Nm" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="PartyV2">
+    <xs:complexContent>
+      <xs:extension base="Party">
+        <xs:sequence><xs:element name="Id" type="xs:string"/></xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+  <xs:complexType name="PartyV3">
+    <xs:complexContent>
+      <xs:extension base="PartyV2">
+        <xs:sequence><xs:element name="Ctry" type="xs:string"/></xs:sequence>
+      </xs:extension>
+    </xs:complexContent>
+  </xs:complexType>
+</xs:schema>`
+	file := generateFromSource(t, source, "Rust", nil)
+	generated, err := ioutil.ReadFile(file + ".rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "impl From<PartyV2> for Party {\n\tfn from(v: PartyV2) -> Self {\n\t\tv.party\n\t}\n}\n")
+	assert.Contains(t, string(generated), "impl From<PartyV3> for PartyV2 {\n\tfn from(v: PartyV3) -> Self {\n\t\tv.party_v2\n\t}\n}\n")
+	assert.Contains(t, string(generated), "impl From<PartyV3> for Party {\n\tfn from(v: PartyV3) -> Self {\n\t\tv.party_v2.party\n\t}\n}\n")
+	assert.Contains(t, string(generated), "\tpub fn as_party_v2(&self) -> &PartyV2 {\n")
+	assert.Contains(t, string(generated), "\tpub fn from_party(v: Party) -> Self {\n")
+	assert.NotContains(t, string(generated), "for PartyV3 {")
+}
+
+func TestGenerateVersionedPackages(t *testing.T) {
+	assert.Equal(t, "pain_001_001_09", getVersionPackage("urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"))
+	assert.Equal(t, "", getVersionPackage("http://www.example.com/schema"))
+
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, version := range []string{"09", "11"} {
+		source := fmt.Sprintf(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:iso:std:iso:20022:tech:xsd:pain.001.001.%s">
+  <xs:complexType name="Party">
+    <xs:sequence><xs:element name="Nm" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Only%s">
+    <xs:sequence><xs:element name="Nm" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+</xs:schema>`, version, version)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "pain.001.001."+version+".xsd"), []byte(source), 0644))
+	}
+	files, err := GetFileList(dir)
+	require.NoError(t, err)
+	require.NoError(t, ParseFiles(files, func(file string) *Options {
+		return &Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                "Go",
+			VersionedPackages:   "example.com/iso",
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}
+	}, nil))
+	generated, err := ioutil.ReadFile(filepath.Join(dir, "output", "pain_001_001_09", "pain.001.001.09.xsd.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "package pain_001_001_09\n")
+	stubs, err := ioutil.ReadFile(filepath.Join(dir, "output", "pain_001_001_11", "convert_pain_001_001_09.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(stubs), "package pain_001_001_11\n")
+	assert.Contains(t, string(stubs), `import pain_001_001_09 "example.com/iso/pain_001_001_09"`)
+	assert.Contains(t, string(stubs), "func PartyFromPain00100109(v *pain_001_001_09.Party) *Party {")
+	assert.Contains(t, string(stubs), "func PartyToPain00100109(v *Party) *pain_001_001_09.Party {")
+	assert.NotContains(t, string(stubs), "Only")
+	_, err = os.Stat(filepath.Join(dir, "output", "pain_001_001_09", "convert_pain_001_001_11.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for version, fields := range map[string]string{
+		"09": `<xs:element name="Id" type="xs:int"/><xs:element name="Old" type="xs:string"/>`,
+		"11": `<xs:element name="Id" type="xs:string"/><xs:element name="New" type="xs:string"/>`,
+	} {
+		source := fmt.Sprintf(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:iso:std:iso:20022:tech:xsd:pain.001.001.%s">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:element name="Adr" type="Address" minOccurs="0"/>
+      <xs:element name="Ctct" type="Address" maxOccurs="unbounded"/>
+      %s
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Address">
+    <xs:sequence><xs:element name="Line" type="xs:string"/></xs:sequence>
+  </xs:complexType>
+</xs:schema>`, version, fields)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, version+".xsd"), []byte(source), 0644))
+	}
+	options := func(file, lang string) *Options {
+		return &Options{
+			FilePath:            filepath.Join(dir, file),
+			Lang:                lang,
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}
+	}
+
+	generated, err := GenerateMapping(options("09.xsd", "Go"), options("11.xsd", "Go"), "example.com/iso/pain_001_001_09")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "package pain_001_001_11\n")
+	assert.Contains(t, string(generated), "func PartyFromPain00100109(v *pain_001_001_09.Party) *Party {")
+	assert.Contains(t, string(generated), "\tout.Nm = v.Nm\n")
+	assert.Contains(t, string(generated), "\tout.Adr = AddressFromPain00100109(v.Adr)\n")
+	assert.Contains(t, string(generated), "\t\tout.Ctct = append(out.Ctct, AddressToPain00100109(item))\n")
+	assert.Contains(t, string(generated), "// TODO: map the Id, which has a different type in pain.001.001.09.")
+	assert.Contains(t, string(generated), "// TODO: map the New, which doesn't match a field in pain.001.001.09.")
+	assert.Contains(t, string(generated), "// TODO: map the Old, which doesn't match a field in the new version.")
+
+	generated, err = GenerateMapping(options("09.xsd", "Rust"), options("11.xsd", "Rust"), "crate::pain_001_001_09")
+	require.NoError(t, err)
+	assert.Contains(t, string(generated), "use crate::pain_001_001_09 as old;\n")
+	assert.Contains(t, string(generated), "impl From<old::Party> for Party {\n")
+	assert.Contains(t, string(generated), "\t\t\tadr: v.adr.map(Into::into),\n")
+	assert.Contains(t, string(generated), "\t\t\tctct: v.ctct.into_iter().map(Into::into).collect(),\n")
+	assert.Contains(t, string(generated), "\t\t\t// TODO: map the id, which has a different type in pain.001.001.09.\n")
+	assert.Contains(t, string(generated), "\t\t\t..Default::default()\n")
+	assert.Contains(t, string(generated), "impl From<old::Address> for Address {\n\tfn from(v: old::Address) -> Self {\n\t\tSelf {\n\t\t\tline: v.line,\n\t\t}\n")
+
+	_, err = GenerateMapping(options("09.xsd", "TypeScript"), options("11.xsd", "TypeScript"), "")
+	assert.EqualError(t, err, "mapping code for TypeScript is not supported")
+}
+
+func TestParseFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"a.xsd", "b.xsd"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(validationTestSchema), 0644))
+	}
+	files, err := GetFileList(dir)
+	require.NoError(t, err)
+	var progress []Progress
+	require.NoError(t, ParseFiles(files, func(file string) *Options {
+		return &Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                "Go",
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+		}
+	}, func(p Progress) {
+		progress = append(progress, p)
+	}))
+	require.Len(t, progress, 2)
+	for i, p := range progress {
+		assert.Equal(t, i+1, p.Completed)
+		assert.Equal(t, 2, p.Total)
+	}
+	assert.Equal(t, filepath.Join(dir, "b.xsd"), progress[1].File)
+	assert.Equal(t, time.Duration(0), progress[1].Remaining)
+	assert.FileExists(t, filepath.Join(dir, "output", "a.xsd.go"))
+
+	assert.Error(t, ParseFiles([]string{filepath.Join(dir, "missing.xsd")}, nil, nil))
+}
+
+func TestParseCircularSchemas(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	inputDir := filepath.Join(dir, "xsd")
+	require.NoError(t, os.Mkdir(inputDir, 0755))
+	for name, source := range map[string]string{
+		"a.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:b="urn:b" targetNamespace="urn:a">
+  <xs:import namespace="urn:b" schemaLocation="b.xsd"/>
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string"/>
+  </xs:simpleType>
+  <xs:complexType name="A">
+    <xs:sequence>
+      <xs:element name="Amt" type="b:Amount"/>
+      <xs:element name="Ref" type="b:B"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`,
+		"b.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:a="urn:a" targetNamespace="urn:b">
+  <xs:import namespace="urn:a" schemaLocation="./a.xsd"/>
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+  <xs:complexType name="B">
+    <xs:sequence>
+      <xs:element name="Cd" type="a:Code"/>
+      <xs:element name="Back" type="a:A" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`,
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(inputDir, name), []byte(source), 0644))
+	}
+	files, err := GetFileList(inputDir)
+	require.NoError(t, err)
+	cache := NewSchemaCache()
+	require.NoError(t, ParseFiles(files, func(file string) *Options {
+		return &Options{
+			FilePath:            file,
+			InputDir:            inputDir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                "Go",
+			Package:             "payments",
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+			Cache:               cache,
+		}
+	}, nil))
+	// Each schema is generated once, as the input file, and its parse as
+	// the input file reuses the parse as the imported schema.
+	var generated []string
+	require.NoError(t, filepath.Walk(filepath.Join(dir, "output"), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			generated = append(generated, strings.TrimPrefix(path, filepath.Join(dir, "output")))
+		}
+		return err
+	}))
+	assert.Equal(t, []string{string(filepath.Separator) + "a.xsd.go", string(filepath.Separator) + "b.xsd.go"}, generated)
+	hits, _ := cache.Stats()
+	assert.Equal(t, 2, hits)
+	data, err := ioutil.ReadFile(filepath.Join(dir, "output", "b.xsd.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "package payments\n")
+	assert.Contains(t, string(data), "\tCd   string `xml:\"Cd\"`\n\tBack *A     `xml:\"Back\"`\n")
+
+	// The schemas including each other resolve the types of the schemas
+	// included in turn, whatever the order of the includes.
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:include schemaLocation="b.xsd"/>
+  <xs:complexType name="A">
+    <xs:sequence>
+      <xs:element name="Qty" type="Quantity"/>
+      <xs:element name="Amt" type="Amount"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	for i := 0; i < 10; i++ {
+		output := generateFromSource(t, source, "Go", func(opt *Options) {
+			for name, source := range map[string]string{
+				"b.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:include schemaLocation="schema.xsd"/>
+  <xs:include schemaLocation="d.xsd"/>
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+</xs:schema>`,
+				"d.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:include schemaLocation="b.xsd"/>
+  <xs:simpleType name="Quantity">
+    <xs:restriction base="xs:int"/>
+  </xs:simpleType>
+</xs:schema>`,
+			} {
+				require.NoError(t, ioutil.WriteFile(filepath.Join(opt.InputDir, name), []byte(source), 0644))
+			}
+		})
+		data, err := ioutil.ReadFile(output + ".go")
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "\tQty int     `xml:\"Qty\"`\n\tAmt float64 `xml:\"Amt\"`\n")
+	}
+}
+
+func TestGenerateGroupByMessage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	inputDir := filepath.Join(dir, "xsd")
+	require.NoError(t, os.Mkdir(inputDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(inputDir, "datatypes.xsd"), []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:example:datatypes">
+  <xs:complexType name="ActiveCurrencyAndAmount">
+    <xs:simpleContent>
+      <xs:extension base="xs:decimal">
+        <xs:attribute name="Ccy" type="xs:string" use="required"/>
+      </xs:extension>
+    </xs:simpleContent>
+  </xs:complexType>
+  <xs:complexType name="PartyIdentification">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string" minOccurs="0"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`), 0644))
+	for _, message := range []string{"pacs.008.001.08", "pain.001.001.09"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(inputDir, message+".xsd"), []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:dt="urn:example:datatypes" targetNamespace="urn:iso:std:iso:20022:tech:xsd:`+message+`">
+  <xs:import namespace="urn:example:datatypes" schemaLocation="datatypes.xsd"/>
+  <xs:complexType name="Document">
+    <xs:sequence>
+      <xs:element name="MsgId" type="xs:string"/>
+      <xs:element name="Amt" type="dt:ActiveCurrencyAndAmount"/>
+      <xs:element name="Dbtr" type="dt:PartyIdentification"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`), 0644))
+	}
+	files, err := GetFileList(inputDir)
+	require.NoError(t, err)
+	generate := func(lang string) string {
+		outputDir := filepath.Join(dir, lang)
+		require.NoError(t, ParseFiles(files, func(file string) *Options {
+			return &Options{
+				FilePath:            file,
+				InputDir:            inputDir,
+				OutputDir:           outputDir,
+				Lang:                lang,
+				VersionedPackages:   "example.com/payments",
+				GroupByMessage:      true,
+				IncludeMap:          make(map[string]bool),
+				LocalNameNSMap:      make(map[string]string),
+				NSSchemaLocationMap: make(map[string]string),
+				ParseFileList:       make(map[string]bool),
+				ParseFileMap:        make(map[string][]interface{}),
+				ProtoTree:           make([]interface{}, 0),
+			}
+		}, nil))
+		return outputDir
+	}
+	read := func(name string) string {
+		data, err := ioutil.ReadFile(name)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	output := generate("Go")
+	assert.Contains(t, read(filepath.Join(output, "common", "datatypes.xsd.go")), "package common\n")
+	data := read(filepath.Join(output, "pacs_008_001_08", "pacs.008.001.08.xsd.go"))
+	assert.Contains(t, data, "package pacs_008_001_08\n\nimport \"example.com/payments/common\"\n")
+	assert.Contains(t, data, "\tMsgId string                          `xml:\"MsgId\"`\n\tAmt   *common.ActiveCurrencyAndAmount `xml:\"Amt\"`\n\tDbtr  *common.PartyIdentification     `xml:\"Dbtr\"`\n")
+	assert.Contains(t, read(filepath.Join(output, "messages.go")), "var Messages = map[string]string{\n\t\"urn:iso:std:iso:20022:tech:xsd:pacs.008.001.08\": \"example.com/payments/pacs_008_001_08\",\n\t\"urn:iso:std:iso:20022:tech:xsd:pain.001.001.09\": \"example.com/payments/pain_001_001_09\",\n}\n")
+
+	output = generate("Rust")
+	assert.Contains(t, read(filepath.Join(output, "mod.rs")), "\n\npub mod common;\npub mod pacs_008_001_08;\npub mod pain_001_001_09;\n")
+	assert.Contains(t, read(filepath.Join(output, "common", "mod.rs")), "\n#[path = \"datatypes.xsd.rs\"]\nmod datatypes_xsd;\npub use datatypes_xsd::*;\n")
+	assert.Contains(t, read(filepath.Join(output, "pain_001_001_09", "pain.001.001.09.xsd.rs")), "\n#[allow(unused_imports)]\nuse super::super::common::*;\nuse serde::{Deserialize, Serialize};\n")
+	assert.NotContains(t, read(filepath.Join(output, "common", "datatypes.xsd.rs")), "use super::super::common::*;")
+
+	output = generate("TypeScript")
+	assert.Contains(t, read(filepath.Join(output, "index.ts")), "\n\nexport * as common from './common';\nexport * as pacs_008_001_08 from './pacs_008_001_08';\nexport * as pain_001_001_09 from './pain_001_001_09';\n")
+	assert.Contains(t, read(filepath.Join(output, "pacs_008_001_08", "index.ts")), "\n\nexport * from './pacs.008.001.08.xsd';\n")
+	assert.Contains(t, read(filepath.Join(output, "pacs_008_001_08", "pacs.008.001.08.xsd.ts")), "import { ActiveCurrencyAndAmount, PartyIdentification } from '../common';\n\nexport class Document {\n")
+
+	opt := &Options{FilePath: filepath.Join(inputDir, "datatypes.xsd"), OutputDir: filepath.Join(dir, "Java"), Lang: "Java", GroupByMessage: true, IncludeMap: make(map[string]bool), LocalNameNSMap: make(map[string]string), NSSchemaLocationMap: make(map[string]string), ParseFileList: make(map[string]bool), ParseFileMap: make(map[string][]interface{})}
+	assert.EqualError(t, NewParser(opt).Parse(), "the group-by-message mode isn't supported for Java")
+}
+func TestParseFilesEvents(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:element name="Doc"/>
+</xs:schema>`), 0644))
+	var events []Event
+	require.NoError(t, ParseFiles([]string{file}, func(file string) *Options {
+		return &Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                "Go",
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+			Events: func(e Event) {
+				e.ElapsedMs = 0
+				events = append(events, e)
+			},
+		}
+	}, nil))
+	assert.Equal(t, []Event{
+		{Event: EventFileStarted, File: file, Total: 1},
+		{Event: EventTypeParsed, File: file, Kind: KindComplexType, Name: "Party"},
+		{Event: EventTypeParsed, File: file, Kind: KindElement, Name: "Doc"},
+		{Event: EventWarning, File: file, Message: file + ": declarations without type mapped to string: Doc"},
+		{Event: EventFileFinished, File: file, Completed: 1, Total: 1},
+	}, events)
+}
+
+func TestParseFilesWarnings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "lib"), 0755))
+	common := filepath.Join(dir, "lib", "common.xsd")
+	require.NoError(t, ioutil.WriteFile(common, []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:common">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="\p{IsKlingon}+"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`), 0644))
+	var files []string
+	for _, name := range []string{"a", "b"} {
+		file := filepath.Join(dir, name+".xsd")
+		require.NoError(t, ioutil.WriteFile(file, []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:c="urn:common">
+  <xs:import namespace="urn:common" schemaLocation="lib/common.xsd"/>
+  <xs:element name="Cd" type="c:Code"/>
+</xs:schema>`), 0644))
+		files = append(files, file)
+	}
+	options := map[string]*Options{}
+	var events []Event
+	var reported []Warning
+	require.NoError(t, ParseFiles(files, func(file string) *Options {
+		options[file] = &Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                "Go",
+			Validation:          ValidationMethod,
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+			Events: func(e Event) {
+				if e.Event == EventWarning {
+					events = append(events, e)
+				}
+			},
+		}
+		return options[file]
+	}, func(p Progress) {
+		reported = append(reported, p.Warnings...)
+	}))
+	// Both importers hold the warning of the imported schema, which is
+	// reported for the first of them.
+	warning := Warning{File: common, Line: 4, Message: `skipped the validation of the unsupported pattern \p{IsKlingon}+: unsupported Unicode block Klingon`}
+	for _, file := range files {
+		assert.Equal(t, []Warning{warning}, options[file].Warnings, file)
+	}
+	assert.Equal(t, []Warning{warning}, reported)
+	assert.Equal(t, []Event{{Event: EventWarning, File: files[0], Message: common + ":4: " + warning.Message}}, events)
+}
+
+func TestServeLSP(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "b.xsd"), []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:b">
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal">
+      <xs:fractionDigits value="2"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`), 0644))
+	file := filepath.Join(dir, "a.xsd")
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:b="urn:b" xmlns="urn:a" targetNamespace="urn:a">
+  <xs:import namespace="urn:b" schemaLocation="b.xsd"/>
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="1"/>
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="Max35Text"/>
+      <xs:element name="Amt" type="b:Amount"/>
+      <xs:element name="Cd" type="Code"/>
+      <xs:any/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	uri := lspPathToURI(file)
+	var in bytes.Buffer
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":%q,"languageId":"xml","version":1,"text":%q}}}`, uri, source),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{"textDocument":{"uri":%q},"position":{"line":10,"character":36}}}`, uri),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":3,"method":"textDocument/definition","params":{"textDocument":{"uri":%q},"position":{"line":11,"character":38}}}`, uri),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":4,"method":"textDocument/hover","params":{"textDocument":{"uri":%q},"position":{"line":3,"character":32}}}`, uri),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":5,"method":"textDocument/hover","params":{"textDocument":{"uri":%q},"position":{"line":8,"character":28}}}`, uri),
+		`{"jsonrpc":"2.0","id":6,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+	var out bytes.Buffer
+	require.NoError(t, ServeLSP(&in, &out))
+	var messages []map[string]interface{}
+	reader := bufio.NewReader(&out)
+	for {
+		content, err := readLSPMessage(reader)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		var msg map[string]interface{}
+		require.NoError(t, json.Unmarshal(content, &msg))
+		messages = append(messages, msg)
+	}
+	require.Len(t, messages, 7)
+	assert.Equal(t, map[string]interface{}{"definitionProvider": true, "hoverProvider": true, "textDocumentSync": float64(1)}, messages[0]["result"].(map[string]interface{})["capabilities"])
+	assert.Equal(t, "textDocument/publishDiagnostics", messages[1]["method"])
+	diagnostics, err := json.Marshal(messages[1]["params"].(map[string]interface{})["diagnostics"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+  {"range": {"start": {"line": 13, "character": 7}, "end": {"line": 13, "character": 13}}, "severity": 2, "source": "xgen", "message": "xs:any isn't supported by the code generation and is ignored"},
+  {"range": {"start": {"line": 12, "character": 34}, "end": {"line": 12, "character": 38}}, "severity": 1, "source": "xgen", "message": "dangling reference to type Code"}
+]`, string(diagnostics))
+	hover := func(msg map[string]interface{}) string {
+		return msg["result"].(map[string]interface{})["contents"].(map[string]interface{})["value"].(string)
+	}
+	assert.Equal(t, "**simpleType** `Max35Text`\n\nbase: `string`\n\nfacets: minLength `1`, maxLength `35`", hover(messages[2]))
+	assert.Equal(t, map[string]interface{}{
+		"uri":   lspPathToURI(filepath.Join(dir, "b.xsd")),
+		"range": map[string]interface{}{"start": map[string]interface{}{"line": float64(1), "character": float64(23)}, "end": map[string]interface{}{"line": float64(1), "character": float64(29)}},
+	}, messages[3]["result"])
+	assert.Equal(t, "built-in type `xs:string`", hover(messages[4]))
+	assert.Equal(t, "**complexType** `Party`\n\n- element `Nm`: `string` minLength `1`, maxLength `35`\n- element `Amt`: `Amount`\n- element `Cd`: `Code`", hover(messages[5]))
+	assert.Nil(t, messages[6]["result"])
+}
+
+func TestVerify(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns="urn:a" targetNamespace="urn:a" elementFormDefault="qualified">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="1"/>
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="Max35Text"/>
+      <xs:element name="Cnt" type="xs:int"/>
+    </xs:sequence>
+    <xs:attribute name="id" type="xs:string" use="required"/>
+  </xs:complexType>
+  <xs:element name="Doc" type="Party"/>
+</xs:schema>`), 0644))
+	report, err := NewParser(&Options{
+		FilePath:            file,
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+	}).Verify(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Doc"}, report.Roots)
+	assert.Empty(t, report.Issues)
+
+	sample := []byte(`<Doc xmlns="urn:a" id="a"><Nm>Name</Nm><Cnt>1</Cnt><Cnt>2.0</Cnt></Doc>`)
+	assert.Equal(t, []VerifyIssue{
+		{Root: "Doc", Message: "the sample fails the validation: Nm is too long"},
+		{Root: "Doc", Path: "/Doc", Message: "element {urn:a}Doc is encoded as Doc"},
+		{Root: "Doc", Path: "/Doc", Message: "attribute id is lost"},
+		{Root: "Doc", Path: "/Doc/Nm[1]", Message: "element {urn:a}Nm is encoded as Nm"},
+		{Root: "Doc", Path: "/Doc/Nm[1]", Message: `value is encoded as "Other" instead of "Name"`},
+		{Root: "Doc", Path: "/Doc/Cnt[1]", Message: "element {urn:a}Cnt is encoded as Cnt"},
+		{Root: "Doc", Path: "/Doc/Cnt[2]", Message: "element {urn:a}Cnt is lost"},
+		{Root: "Doc", Path: "/Doc", Message: "unexpected element Name"},
+	}, verifyRoundTrip(verifyResult{Root: "Doc", Output: `<Doc><Nm>Other</Nm><Cnt>1.0</Cnt><Name/></Doc>`, Validation: "Nm is too long"}, sample))
+	assert.Equal(t, []VerifyIssue{{Root: "Doc", Message: "expected element type <Doc> but have <Document>"}}, verifyRoundTrip(verifyResult{Root: "Doc", Error: "expected element type <Doc> but have <Document>"}, sample))
+}
+
+func TestSchemaCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	inputDir := filepath.Join(dir, "input")
+	require.NoError(t, os.MkdirAll(inputDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(inputDir, "common.xsd"), []byte(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:common">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Amount">
+    <xs:restriction base="xs:decimal"/>
+  </xs:simpleType>
+</xs:schema>`), 0644))
+	var files []string
+	for _, message := range []string{"a", "b", "c"} {
+		file := filepath.Join(inputDir, message+".xsd")
+		require.NoError(t, ioutil.WriteFile(file, []byte(fmt.Sprintf(`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:c="urn:common" targetNamespace="urn:%s">
+  <xs:import namespace="urn:common" schemaLocation="common.xsd"/>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="c:Max35Text"/>
+      <xs:element name="Amt" type="c:Amount"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`, message)), 0644))
+		files = append(files, file)
+	}
+	options := func(outputDir string, cache *SchemaCache) func(file string) *Options {
+		return func(file string) *Options {
+			return &Options{
+				FilePath:            file,
+				InputDir:            inputDir,
+				OutputDir:           outputDir,
+				Lang:                "Go",
+				IncludeMap:          make(map[string]bool),
+				LocalNameNSMap:      make(map[string]string),
+				NSSchemaLocationMap: make(map[string]string),
+				ParseFileList:       make(map[string]bool),
+				ParseFileMap:        make(map[string][]interface{}),
+				ProtoTree:           make([]interface{}, 0),
+				RemoteSchema:        make(map[string][]byte),
+				Cache:               cache,
+			}
+		}
+	}
+	cache := NewSchemaCache()
+	require.NoError(t, ParseFiles(files, options(filepath.Join(dir, "cached"), cache), nil))
+	hits, misses := cache.Stats()
+	assert.Equal(t, 2, hits)
+	assert.Equal(t, 4, misses)
+	for _, file := range files {
+		require.NoError(t, NewParser(options(filepath.Join(dir, "parsed"), nil)(file)).Parse())
+	}
+	for _, message := range []string{"a", "b", "c"} {
+		cached, err := ioutil.ReadFile(filepath.Join(dir, "cached", message+".xsd.go"))
+		require.NoError(t, err)
+		parsed, err := ioutil.ReadFile(filepath.Join(dir, "parsed", message+".xsd.go"))
+		require.NoError(t, err)
+		assert.Equal(t, string(parsed), string(cached))
+		assert.Contains(t, string(cached), "\tNm  string  `xml:\"Nm\"`\n\tAmt float64 `xml:\"Amt\"`\n")
+	}
+}
+
+func TestPipelineStages(t *testing.T) {
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Internal">
+    <xs:sequence>
+      <xs:element name="Id" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+  <xs:complexType name="Doc">
+    <xs:sequence>
+      <xs:element name="Pty" type="Party"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`
+	var stages []string
+	path := generateFromSource(t, source, "Go", func(opt *Options) {
+		opt.Transforms = []Transform{
+			func(stage string, protoTree []interface{}) ([]interface{}, error) {
+				stages = append(stages, stage)
+				return protoTree, nil
+			},
+			func(stage string, protoTree []interface{}) ([]interface{}, error) {
+				if stage != StageParse {
+					return protoTree, nil
+				}
+				for _, ele := range protoTree {
+					if v, ok := ele.(*ComplexType); ok {
+						for i := range v.Elements {
+							if v.Elements[i].Type == "Party" {
+								v.Elements[i].Type = "Customer"
+							}
+						}
+						if v.Name == "Party" {
+							v.Name = "Customer"
+						}
+					}
+				}
+				return protoTree, nil
+			},
+			func(stage string, protoTree []interface{}) ([]interface{}, error) {
+				if stage != StageNormalize {
+					return protoTree, nil
+				}
+				var pruned []interface{}
+				for _, ele := range protoTree {
+					if v, ok := ele.(*ComplexType); !ok || v.Name != "Internal" {
+						pruned = append(pruned, ele)
+					}
+				}
+				return pruned, nil
+			},
+		}
+	})
+	assert.Equal(t, []string{StageParse, StageResolve, StageNormalize}, stages)
+	code, err := ioutil.ReadFile(path + ".go")
+	require.NoError(t, err)
+	assert.Contains(t, string(code), "type Customer struct {\n\tNm string `xml:\"Nm\"`\n}\n")
+	assert.Contains(t, string(code), "\tPty *Customer `xml:\"Pty\"`\n")
+	assert.NotContains(t, string(code), "Party")
+	assert.NotContains(t, string(code), "Internal")
+
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(source), 0644))
+	newOptions := func() *Options {
+		return &Options{
+			FilePath:            file,
+			InputDir:            dir,
+			OutputDir:           filepath.Join(dir, "output"),
+			Lang:                "Rust",
+			IncludeMap:          make(map[string]bool),
+			LocalNameNSMap:      make(map[string]string),
+			NSSchemaLocationMap: make(map[string]string),
+			ParseFileList:       make(map[string]bool),
+			ParseFileMap:        make(map[string][]interface{}),
+			ProtoTree:           make([]interface{}, 0),
+			Artifacts:           make(map[string][]byte),
+		}
+	}
+	opt := newOptions()
+	opt.Transforms = []Transform{func(stage string, protoTree []interface{}) ([]interface{}, error) {
+		return nil, fmt.Errorf("unsupported declaration")
+	}}
+	assert.EqualError(t, NewParser(opt).Parse(), "transform after the parse stage: unsupported declaration")
+	assert.Empty(t, opt.Artifacts)
+
+	opt = NewParser(newOptions())
+	require.NoError(t, opt.ParseSchema())
+	require.Len(t, opt.ProtoTree, 3)
+	opt.ProtoTree = opt.ProtoTree[:1]
+	require.NoError(t, opt.ResolveTypes())
+	opt.NormalizeNames()
+	require.NoError(t, opt.GenerateCode())
+	code = opt.Artifacts[filepath.Join(dir, "output", "schema.xsd.rs")]
+	assert.Contains(t, string(code), "pub struct Party {")
+	assert.NotContains(t, string(code), "pub struct Doc {")
+}
+
+func TestDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "schema.xsd")
+	require.NoError(t, ioutil.WriteFile(file, []byte(validationTestSchema), 0644))
+	dump, err := NewParser(&Options{
+		FilePath:            file,
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+	}).Dump()
+	require.NoError(t, err)
+	assert.Equal(t, DumpVersion, dump.Version)
+	assert.Equal(t, map[string]string{"xs": "http://www.w3.org/2001/XMLSchema"}, dump.Namespaces)
+	require.Len(t, dump.Declarations, 2)
+	assert.Equal(t, Declaration{Kind: KindSimpleType, Name: "Max35Text", Base: "string", Facets: &Facets{MinLength: 1, MaxLength: 35}}, dump.Declarations[0])
+	assert.Equal(t, KindComplexType, dump.Declarations[1].Kind)
+	assert.Equal(t, []Declaration{{Kind: KindElement, Name: "Nm", Type: "string", Facets: &Facets{MinLength: 1, MaxLength: 35}}}, dump.Declarations[1].Elements)
+	data, err := json.Marshal(dump.Declarations[0])
+	require.NoError(t, err)
+	assert.Equal(t, `{"kind":"simpleType","name":"Max35Text","base":"string","facets":{"minLength":1,"maxLength":35}}`, string(data))
+	_, err = os.Stat(filepath.Join(dir, "schema.xsd.go"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMergeSchemas(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xgen-*")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "common"), 0755))
+	for name, source := range map[string]string{
+		"a.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:x">
+  <xs:import namespace="urn:ext" schemaLocation="common/ext.xsd"/>
+  <xs:include schemaLocation="common/types.xsd"/>
+  <xs:element name="Nm" type="Max35Text"/>
+</xs:schema>`,
+		"b.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:x">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string"><xs:maxLength value="35"/></xs:restriction>
+  </xs:simpleType>
+</xs:schema>`,
+		"c.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:x">
+  <xs:complexType name="Max35Text"/>
+</xs:schema>`,
+		"d.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:y"/>`,
+		"common/types.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`,
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(source), 0644))
+	}
+	merged, err := MergeSchemas([]string{filepath.Join(dir, "a.xsd"), filepath.Join(dir, "b.xsd")}, filepath.Join(dir, "out"))
+	require.NoError(t, err)
+	assert.Equal(t, xml.Header+`<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" targetNamespace="urn:x">
+  <xs:import namespace="urn:ext" schemaLocation="../common/ext.xsd"/>
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:element name="Nm" type="Max35Text"/>
+</xs:schema>
+`, string(merged))
+
+	_, err = MergeSchemas([]string{filepath.Join(dir, "a.xsd"), filepath.Join(dir, "c.xsd")}, dir)
+	assert.EqualError(t, err, fmt.Sprintf("conflicting declarations of complexType Max35Text in %s and %s", filepath.Join(dir, "common", "types.xsd"), filepath.Join(dir, "c.xsd")))
+	_, err = MergeSchemas([]string{filepath.Join(dir, "a.xsd"), filepath.Join(dir, "d.xsd")}, dir)
+	assert.EqualError(t, err, fmt.Sprintf("conflicting schema attribute targetNamespace in %s: urn:x and urn:y", filepath.Join(dir, "d.xsd")))
+}
+
+func TestGenerateTestVectors(t *testing.T) {
+	file := generateFromSource(t, validationTestSchema, "Go", func(opt *Options) {
+		opt.Validation = ValidationMethod
+		opt.TestVectors = true
+	})
+	data, err := ioutil.ReadFile(file + ".vectors.json")
+	require.NoError(t, err)
+	var vectors TestVectors
+	require.NoError(t, json.Unmarshal(data, &vectors))
+	assert.Equal(t, []TypeVector{{
+		Name:    "Max35Text",
+		Valid:   []string{"a", strings.Repeat("a", 35)},
+		Invalid: []string{"", strings.Repeat("a", 36)},
+	}}, vectors.Types)
+	stub, err := ioutil.ReadFile(file + ".vectors_test.go")
+	require.NoError(t, err)
+	assert.Contains(t, string(stub), `"Max35Text": func(value string) error {`)
+
+	source := `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:enumeration value="CRDT"/>
+      <xs:enumeration value="DBIT"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Priority">
+    <xs:restriction base="xs:int">
+      <xs:enumeration value="1"/>
+      <xs:enumeration value="2"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Digits">
+    <xs:restriction base="xs:decimal">
+      <xs:pattern value="[0-9]+"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Counter">
+    <xs:restriction base="xs:unsignedLong">
+      <xs:maxInclusive value="18446744073709551614"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Offset">
+    <xs:restriction base="xs:long">
+      <xs:minInclusive value="-9223372036854775807"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`
+	for _, validation := range []string{ValidationMethod, ValidationStandalone} {
+		file = generateFromSource(t, source, "Go", func(opt *Options) {
+			opt.Validation, opt.TestVectors = validation, true
+		})
+		data, err = ioutil.ReadFile(file + ".vectors.json")
+		require.NoError(t, err)
+		vectors = TestVectors{}
+		require.NoError(t, json.Unmarshal(data, &vectors))
+		assert.Equal(t, []TypeVector{
+			{Name: "Code", Valid: []string{"CRDT", "DBIT"}, Invalid: []string{"", "!"}},
+			{Name: "Priority", Valid: []string{"1", "2"}, Invalid: []string{}},
+			{Name: "Digits", Valid: []string{"0"}, Invalid: []string{}},
+			{Name: "Counter", Valid: []string{"18446744073709551614", "18446744073709551613"}, Invalid: []string{"18446744073709551615"}},
+			{Name: "Offset", Valid: []string{"-9223372036854775807", "-9223372036854775806"}, Invalid: []string{"-9223372036854775808"}},
+		}, vectors.Types)
+		stub, err = ioutil.ReadFile(file + ".vectors_test.go")
+		require.NoError(t, err)
+		assert.Contains(t, string(stub), "n, err := strconv.ParseUint(value, 10, 64)\n")
+		assert.Contains(t, string(stub), "n, err := strconv.ParseInt(value, 10, 0)\n")
+		runGoPackage(t, filepath.Dir(file), "test")
+	}
+
+	file = generateFromSource(t, source, "Rust", func(opt *Options) {
+		opt.Validation, opt.TestVectors = ValidationMethod, true
+	})
+	stub, err = ioutil.ReadFile(file + ".vectors_test.rs")
+	require.NoError(t, err)
+	assert.Contains(t, string(stub), "let v = Counter { counter: match value.parse::<u64>() {\n\t\t\t\t\tOk(v) => v,\n\t\t\t\t\tErr(_) => return false,\n\t\t\t\t} };\n")
+	assert.NotContains(t, string(stub), "unwrap() as")
+}
+
+func TestGenPatternSample(t *testing.T) {
+	for pattern, expected := range map[string]string{
+		"[A-Z]{3,3}":                 "AAA",
+		"[A-Z]{2}[0-9]{2}[a-zA-Z]+":  "AA00A",
+		"(CRDT|DBIT)":                "CRDT",
+		"\\+[0-9]{1,3}-[0-9()+\\-]*": "+0-",
+	} {
+		sample, ok := genPatternSample(pattern)
+		assert.True(t, ok, pattern)
+		assert.Equal(t, expected, sample, pattern)
+	}
+}
+
+func TestFaker(t *testing.T) {
+	var opt *Options
+	generateFromSource(t, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Max35Text">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="1"/>
+      <xs:maxLength value="35"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="CurrencyCode">
+    <xs:restriction base="xs:string">
+      <xs:pattern value="[A-Z]{3,3}"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Percentage">
+    <xs:restriction base="xs:int">
+      <xs:minInclusive value="0"/>
+      <xs:maxInclusive value="100"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="Max35Text"/>
+      <xs:element name="Ccy" type="CurrencyCode"/>
+      <xs:element name="Rate" type="Percentage" maxOccurs="unbounded"/>
+    </xs:sequence>
+    <xs:attribute name="Id" type="xs:string" use="required"/>
+  </xs:complexType>
+  <xs:element name="Pty" type="Party"/>
+</xs:schema>`, "Go", func(o *Options) { opt = o })
+
+	value, err := NewFaker(opt.ProtoTree, 1).Value("Pty")
+	require.NoError(t, err)
+	party, ok := value.(map[string]interface{})
+	require.True(t, ok)
+	assert.Contains(t, party, "Id")
+	assert.NoError(t, getRestrictionFromSimpleType("Max35Text", opt.ProtoTree).Evaluate(party["Nm"].(string)))
+	assert.Regexp(t, "^[A-Z]{3}$", party["Ccy"])
+	rates, ok := party["Rate"].([]interface{})
+	if !ok {
+		rates = []interface{}{party["Rate"]}
+	}
+	for _, rate := range rates {
+		assert.NoError(t, getRestrictionFromSimpleType("Percentage", opt.ProtoTree).Evaluate(rate.(string)))
+	}
+
+	first, err := NewFaker(opt.ProtoTree, 42).XML("Pty")
+	require.NoError(t, err)
+	second, err := NewFaker(opt.ProtoTree, 42).XML("Pty")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.True(t, strings.HasPrefix(string(first), "<Pty Id="))
+
+	data, err := NewFaker(opt.ProtoTree, 42).JSON("Party")
+	require.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &value))
+
+	_, err = NewFaker(opt.ProtoTree, 1).Value("Unknown")
+	assert.EqualError(t, err, "declaration Unknown not found")
+
+	// Exactly one element of the choice is picked, unless the choice is
+	// optional.
+	generateFromSource(t, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Account">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+      <xs:choice>
+        <xs:element name="IBAN" type="xs:string"/>
+        <xs:element name="BBAN" type="xs:string"/>
+        <xs:element name="Othr" type="xs:string"/>
+      </xs:choice>
+      <xs:choice minOccurs="0">
+        <xs:element name="Ccy" type="xs:string"/>
+        <xs:element name="Cur" type="xs:string"/>
+      </xs:choice>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`, "Go", func(o *Options) { opt = o })
+	for seed := int64(0); seed < 32; seed++ {
+		value, err := NewFaker(opt.ProtoTree, seed).Value("Account")
+		require.NoError(t, err)
+		account := value.(map[string]interface{})
+		var ids, currencies int
+		for _, name := range []string{"IBAN", "BBAN", "Othr"} {
+			if _, ok := account[name]; ok {
+				ids++
+			}
+		}
+		for _, name := range []string{"Ccy", "Cur"} {
+			if _, ok := account[name]; ok {
+				currencies++
+			}
+		}
+		assert.Contains(t, account, "Nm")
+		assert.Equal(t, 1, ids)
+		assert.LessOrEqual(t, currencies, 1)
+	}
+
+	// The cycles of the derivation are stopped at the visited types.
+	value, err = NewFaker([]interface{}{
+		&ComplexType{Name: "A", Base: "B", Elements: []Element{{Name: "X", Type: "string"}}},
+		&ComplexType{Name: "B", Base: "A", Elements: []Element{{Name: "Y", Type: "string"}}},
+	}, 1).Value("A")
+	require.NoError(t, err)
+	assert.Contains(t, value, "X")
+	assert.Contains(t, value, "Y")
+
+	// The bounds of the dates are kept lexical, and the integers span the
+	// full range of xs:long.
+	generateFromSource(t, `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="ISODate">
+    <xs:restriction base="xs:date">
+      <xs:minInclusive value="2024-01-01"/>
+      <xs:maxInclusive value="2024-12-31"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="ISODateTime">
+    <xs:restriction base="xs:dateTime">
+      <xs:minExclusive value="2024-01-01T00:00:00Z"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Delay">
+    <xs:restriction base="xs:duration">
+      <xs:maxInclusive value="P1D"/>
+    </xs:restriction>
+  </xs:simpleType>
+  <xs:simpleType name="Long">
+    <xs:restriction base="xs:long">
+      <xs:minInclusive value="-9223372036854775808"/>
+      <xs:maxInclusive value="9223372036854775807"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`, "Go", func(o *Options) { opt = o })
+	for seed := int64(0); seed < 32; seed++ {
+		faker := NewFaker(opt.ProtoTree, seed)
+		for name, pattern := range map[string]string{
+			"ISODate":     `^2024-\d{2}-\d{2}$`,
+			"ISODateTime": `^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z$`,
+			"Delay":       `^P1D$`,
+			"Long":        `^-?\d+$`,
+		} {
+			value, err := faker.Value(name)
+			require.NoError(t, err)
+			assert.Regexp(t, pattern, value)
+			assert.NoError(t, getRestrictionFromSimpleType(name, opt.ProtoTree).Evaluate(value.(string)))
+		}
+	}
+
+	// The negated character classes are sampled from the characters allowed
+	// in the XML documents.
+	restriction := Restriction{Pattern: regexp.MustCompile(`[^a]{64}`)}
+	faker := NewFaker(nil, 1)
+	for i := 0; i < 32; i++ {
+		value := faker.genBuildInValue("string", restriction)
+		assert.NoError(t, restriction.Evaluate(value))
+		for _, r := range value {
+			assert.True(t, r == 0x9 || r == 0xA || r == 0xD || r >= 0x20 && r <= 0xD7FF || r >= 0xE000 && r <= 0xFFFD || r >= 0x10000, "%U", r)
+		}
+	}
+}
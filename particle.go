@@ -0,0 +1,97 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"strconv"
+)
+
+// particle holds the effective cardinality of the sequence, choice or all
+// model group being parsed. The model groups nested in a repeated or optional
+// one are repeated or optional too, and the contents of a choice are
+// optional, so the elements and groups of the model group are given the
+// cardinality of the particles they are nested in within the type, on top of
+// their own occurs.
+type particle struct {
+	plural, optional bool
+	// choice is the choice the model group is, if any.
+	choice *Choice
+}
+
+// getOccurs returns whether the particle by given start element may occur
+// more than once, and whether it may be absent.
+func getOccurs(ele xml.StartElement) (plural, optional bool, err error) {
+	for _, attr := range ele.Attr {
+		switch attr.Name.Local {
+		case "maxOccurs":
+			if attr.Value == "unbounded" {
+				plural = true
+				continue
+			}
+			var maxOccurs int
+			if maxOccurs, err = strconv.Atoi(attr.Value); err != nil {
+				return
+			}
+			plural = maxOccurs > 1
+		case "minOccurs":
+			var minOccurs int
+			if minOccurs, err = strconv.Atoi(attr.Value); err != nil {
+				return
+			}
+			optional = minOccurs == 0
+		}
+	}
+	return
+}
+
+// pushParticle pushes the model group by given start element onto the
+// particles being parsed, with the cardinality combined with the enclosing
+// particle.
+func (opt *Options) pushParticle(ele xml.StartElement, choice bool) (p *particle, err error) {
+	p = &particle{optional: choice}
+	// The choice containing a model group isn't a choice of elements.
+	if c := opt.getChoice(); c != nil {
+		c.Nested = true
+	}
+	var plural, optional bool
+	if plural, optional, err = getOccurs(ele); err != nil {
+		return
+	}
+	parentPlural, parentOptional := opt.getParticle()
+	p.plural, p.optional = plural || parentPlural, p.optional || optional || parentOptional
+	opt.Particle.Push(p)
+	return
+}
+
+// getParticle returns the effective cardinality of the model group being
+// parsed within the type, the types parsed outside of any model group and the
+// anonymous types start over with the particle pushed by the type.
+func (opt *Options) getParticle() (plural, optional bool) {
+	if p, ok := opt.Particle.Peek().(*particle); ok {
+		return p.plural, p.optional
+	}
+	return
+}
+
+// getChoice returns the choice being parsed if it directly contains the
+// particles being parsed, or nil otherwise.
+func (opt *Options) getChoice() *Choice {
+	if p, ok := opt.Particle.Peek().(*particle); ok {
+		return p.choice
+	}
+	return nil
+}
+
+// setChoiceNested marks the choice by given ID of the complex type as nested.
+func setChoiceNested(complexType *ComplexType, id string) {
+	if i, err := strconv.Atoi(id); err == nil && i > 0 && i <= len(complexType.Choice) {
+		complexType.Choice[i-1].Nested = true
+	}
+}
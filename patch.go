@@ -0,0 +1,95 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	goStructFieldRegexp   = regexp.MustCompile("(?m)^\\t(\\*?\\w+)(?:\\t(\\S+)(?:\\t`(?:xml:\"([^\"]*)\")? ?([^`]*)`)?)?$")
+	rustStructFieldRegexp = regexp.MustCompile(`(?m)^\t#\[serde\((.*)\)\]\n\tpub ((?:r#)?\w+): (.+),$`)
+)
+
+// genGoPatch generate the patch struct of the struct by given name and field
+// content for Go code, with every member optional, and the Apply method
+// setting the members of the patch which aren't nil on the struct. The
+// pointer and slice members are kept as they are, and the others are made
+// pointers.
+func (gen *CodeGenerator) genGoPatch(structName, content string) {
+	if !gen.PatchTypes {
+		return
+	}
+	var fields, apply string
+	for _, match := range goStructFieldRegexp.FindAllStringSubmatch(content, -1) {
+		fieldName, fieldType, tag, otherTags := match[1], match[2], match[3], match[4]
+		if fieldName == "XMLName" {
+			continue
+		}
+		if fieldType == "" {
+			// The embedded base types and mixins are pointers already.
+			fieldName, fieldType = strings.TrimPrefix(fieldName, "*"), fieldName
+			fields += fmt.Sprintf("\t%s\n", fieldType)
+			apply += fmt.Sprintf("\tif p.%s != nil {\n\t\tv.%s = p.%s\n\t}\n", fieldName, fieldName, fieldName)
+			continue
+		}
+		value := "p." + fieldName
+		if !strings.HasPrefix(fieldType, "*") && !strings.HasPrefix(fieldType, "[]") {
+			fieldType, value = "*"+fieldType, "*p."+fieldName
+		}
+		if tag != "" && !strings.HasPrefix(tag, ",") && !strings.Contains(tag, ",omitempty") {
+			tag += ",omitempty"
+		}
+		var tags []string
+		if tag != "" {
+			tags = append(tags, fmt.Sprintf("xml:\"%s\"", tag))
+		}
+		if otherTags != "" {
+			tags = append(tags, otherTags)
+		}
+		tag = ""
+		if len(tags) > 0 {
+			tag = fmt.Sprintf("\t`%s`", strings.Join(tags, " "))
+		}
+		fields += fmt.Sprintf("\t%s\t%s%s\n", fieldName, fieldType, tag)
+		apply += fmt.Sprintf("\tif p.%s != nil {\n\t\tv.%s = %s\n\t}\n", fieldName, fieldName, value)
+	}
+	patchName := structName + "Patch"
+	gen.Field += fmt.Sprintf("%stype %s struct {\n%s}\n", gen.genComment(patchName, fmt.Sprintf("the patch of the %s, whose members are set on the %s unless they are nil.", structName, structName)), patchName, fields)
+	gen.Field += fmt.Sprintf("\n// Apply sets the members of the patch which aren't nil on the %s.\nfunc (p *%s) Apply(v *%s) {\n%s}\n", structName, patchName, structName, apply)
+}
+
+// genRustPatch generate the patch struct of the struct by given name and
+// field content for Rust code, with every member optional, and the apply
+// method setting the members of the patch which are some on the struct. The
+// optional members are set to some.
+func (gen *CodeGenerator) genRustPatch(structName, content string) {
+	if !gen.PatchTypes {
+		return
+	}
+	var fields, apply string
+	content = gen.genRustBoxedFields(structName, content)
+	for _, match := range rustStructFieldRegexp.FindAllStringSubmatch(content, -1) {
+		attr, fieldName, fieldType := match[1], match[2], match[3]
+		value := "v.clone()"
+		if strings.HasPrefix(fieldType, "Option<") {
+			fieldType, value = strings.TrimSuffix(strings.TrimPrefix(fieldType, "Option<"), ">"), "Some(v.clone())"
+		}
+		if attr != "flatten" && !strings.Contains(attr, "skip_serializing_if") {
+			attr += ", skip_serializing_if = \"Option::is_none\""
+		}
+		fields += fmt.Sprintf("\t#[serde(%s)]\n\tpub %s: Option<%s>,\n", attr, fieldName, fieldType)
+		apply += fmt.Sprintf("\t\tif let Some(v) = &self.%s {\n\t\t\ttarget.%s = %s;\n\t\t}\n", fieldName, fieldName, value)
+	}
+	patchName := structName + "Patch"
+	gen.Field += fmt.Sprintf("\n%s#[derive(Debug, Default, PartialEq, Clone, Serialize, Deserialize)]\npub struct %s {\n%s}\n", gen.genComment(patchName, fmt.Sprintf("the patch of the %s, whose members are set on the %s unless they are none.", structName, structName)), patchName, fields)
+	gen.Field += fmt.Sprintf("\nimpl %s {\n\tpub fn apply(&self, target: &mut %s) {\n%s\t}\n}\n", patchName, structName, apply)
+}
@@ -0,0 +1,393 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Pattern fallbacks of the parser, which apply to each pattern facet using
+// the constructs of the XML schema regular expressions which can't be
+// translated to the regular expressions of Go and Rust. With the skip
+// fallback the validation of the pattern is skipped with a warning, and with
+// the fail fallback the parsing fails.
+const (
+	PatternFallbackSkip = ""
+	PatternFallbackFail = "fail"
+)
+
+// checkPatternFallback returns an error if the pattern fallback isn't
+// supported.
+func checkPatternFallback(fallback string) error {
+	switch fallback {
+	case PatternFallbackSkip, PatternFallbackFail:
+		return nil
+	}
+	return fmt.Errorf("unsupport pattern fallback %s, expected %s", fallback, PatternFallbackFail)
+}
+
+// The classes of the \i and \c multi-character escapes, which match the
+// initial name characters and the name characters of XML.
+const (
+	patternNameStartChars = `\p{L}_:`
+	patternNameChars      = `\p{L}\p{Nd}\p{Mn}\p{Mc}._:\-\x{B7}`
+)
+
+// patternBlocks holds the ranges of the Unicode blocks the \p{IsBlock}
+// escapes of the XML schema regular expressions refer to.
+var patternBlocks = map[string][2]rune{
+	"BasicLatin":                  {0x0000, 0x007F},
+	"Latin-1Supplement":           {0x0080, 0x00FF},
+	"LatinExtended-A":             {0x0100, 0x017F},
+	"LatinExtended-B":             {0x0180, 0x024F},
+	"IPAExtensions":               {0x0250, 0x02AF},
+	"SpacingModifierLetters":      {0x02B0, 0x02FF},
+	"CombiningDiacriticalMarks":   {0x0300, 0x036F},
+	"Greek":                       {0x0370, 0x03FF},
+	"Cyrillic":                    {0x0400, 0x04FF},
+	"Armenian":                    {0x0530, 0x058F},
+	"Hebrew":                      {0x0590, 0x05FF},
+	"Arabic":                      {0x0600, 0x06FF},
+	"Devanagari":                  {0x0900, 0x097F},
+	"Thai":                        {0x0E00, 0x0E7F},
+	"HangulJamo":                  {0x1100, 0x11FF},
+	"LatinExtendedAdditional":     {0x1E00, 0x1EFF},
+	"GreekExtended":               {0x1F00, 0x1FFF},
+	"GeneralPunctuation":          {0x2000, 0x206F},
+	"SuperscriptsandSubscripts":   {0x2070, 0x209F},
+	"CurrencySymbols":             {0x20A0, 0x20CF},
+	"LetterlikeSymbols":           {0x2100, 0x214F},
+	"NumberForms":                 {0x2150, 0x218F},
+	"Arrows":                      {0x2190, 0x21FF},
+	"MathematicalOperators":       {0x2200, 0x22FF},
+	"BoxDrawing":                  {0x2500, 0x257F},
+	"CJKSymbolsandPunctuation":    {0x3000, 0x303F},
+	"Hiragana":                    {0x3040, 0x309F},
+	"Katakana":                    {0x30A0, 0x30FF},
+	"CJKUnifiedIdeographs":        {0x4E00, 0x9FFF},
+	"HangulSyllables":             {0xAC00, 0xD7A3},
+	"PrivateUse":                  {0xE000, 0xF8FF},
+	"AlphabeticPresentationForms": {0xFB00, 0xFB4F},
+	"HalfwidthandFullwidthForms":  {0xFF00, 0xFFEF},
+}
+
+// patternTranslator translates the XML schema regular expressions to the
+// regular expressions of Go and Rust.
+type patternTranslator struct {
+	runes []rune
+	pos   int
+}
+
+// translatePattern returns the regular expression of Go and Rust the XML
+// schema regular expression translates to. The \i and \c escapes, the
+// \p{IsBlock} escapes of the Unicode blocks and the character class
+// subtractions are translated, and the character classes using them are
+// written as the ranges of their characters. The ^ and $ characters outside
+// the character classes are escaped, and the . wildcard, which matches any
+// character but the line breaks, is written as [^\n\r]. The other constructs
+// are kept.
+func translatePattern(pattern string) (string, error) {
+	t := &patternTranslator{runes: []rune(pattern)}
+	var b strings.Builder
+	for t.pos < len(t.runes) {
+		switch t.runes[t.pos] {
+		case '\\':
+			text, err := t.escape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(text)
+		case '[':
+			text, _, err := t.class()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(text)
+		case '^', '$':
+			// The XML schema regular expressions have no anchors, the ^ and
+			// $ characters match themselves.
+			b.WriteString(`\` + string(t.runes[t.pos]))
+			t.pos++
+		case '.':
+			b.WriteString(`[^\n\r]`)
+			t.pos++
+		default:
+			b.WriteRune(t.runes[t.pos])
+			t.pos++
+		}
+	}
+	return b.String(), nil
+}
+
+// escape translates the escape at the position outside the character
+// classes.
+func (t *patternTranslator) escape() (string, error) {
+	text, err := t.escapeText()
+	if err != nil {
+		return "", err
+	}
+	switch text {
+	case `\i`:
+		return "[" + patternNameStartChars + "]", nil
+	case `\I`:
+		return "[^" + patternNameStartChars + "]", nil
+	case `\c`:
+		return "[" + patternNameChars + "]", nil
+	case `\C`:
+		return "[^" + patternNameChars + "]", nil
+	}
+	if block, negated, ok, err := getPatternBlock(text); ok || err != nil {
+		if err != nil {
+			return "", err
+		}
+		if negated {
+			return "[^" + formatPatternRanges(block) + "]", nil
+		}
+		return "[" + formatPatternRanges(block) + "]", nil
+	}
+	return text, nil
+}
+
+// escapeText returns the text of the escape at the position, including the
+// braces of the \p and \P escapes, and moves past it.
+func (t *patternTranslator) escapeText() (string, error) {
+	start := t.pos
+	t.pos++
+	if t.pos >= len(t.runes) {
+		return "", fmt.Errorf("trailing backslash in pattern")
+	}
+	if c := t.runes[t.pos]; (c == 'p' || c == 'P') && t.pos+1 < len(t.runes) && t.runes[t.pos+1] == '{' {
+		end := t.pos + 1
+		for end < len(t.runes) && t.runes[end] != '}' {
+			end++
+		}
+		if end == len(t.runes) {
+			return "", fmt.Errorf("missing closing } of %s", string(t.runes[start:]))
+		}
+		t.pos = end
+	}
+	t.pos++
+	return string(t.runes[start:t.pos]), nil
+}
+
+// getPatternBlock returns the ranges of the Unicode block the \p{IsBlock}
+// or the \P{IsBlock} escape refers to, and whether the escape is negated.
+func getPatternBlock(text string) ([]rune, bool, bool, error) {
+	if len(text) < 6 || !strings.HasPrefix(text[2:], "{Is") {
+		return nil, false, false, nil
+	}
+	name := strings.TrimSuffix(text[5:], "}")
+	block, ok := patternBlocks[name]
+	if !ok {
+		return nil, false, false, fmt.Errorf("unsupported Unicode block %s", name)
+	}
+	return []rune{block[0], block[1]}, text[1] == 'P', true, nil
+}
+
+// class translates the character class at the position, and returns the
+// ranges of its characters. The classes without the constructs of the XML
+// schema regular expressions are kept as they are, the others are written as
+// the ranges of their characters.
+func (t *patternTranslator) class() (string, []rune, error) {
+	start := t.pos
+	t.pos++
+	negated := t.pos < len(t.runes) && t.runes[t.pos] == '^'
+	if negated {
+		t.pos++
+	}
+	var ranges, subtracted []rune
+	translated := false
+	for {
+		if t.pos >= len(t.runes) {
+			return "", nil, fmt.Errorf("missing closing ] of %s", string(t.runes[start:]))
+		}
+		if t.runes[t.pos] == ']' {
+			t.pos++
+			break
+		}
+		if t.runes[t.pos] == '-' && t.pos+1 < len(t.runes) && t.runes[t.pos+1] == '[' {
+			// The character class subtraction, such as [a-z-[aeiou]].
+			t.pos++
+			_, classRanges, err := t.class()
+			if err != nil {
+				return "", nil, err
+			}
+			if t.pos >= len(t.runes) || t.runes[t.pos] != ']' {
+				return "", nil, fmt.Errorf("subtraction isn't the last part of %s", string(t.runes[start:]))
+			}
+			t.pos++
+			subtracted, translated = classRanges, true
+			break
+		}
+		itemRanges, itemTranslated, err := t.classItem()
+		if err != nil {
+			return "", nil, err
+		}
+		ranges = append(ranges, itemRanges...)
+		translated = translated || itemTranslated
+	}
+	ranges = normalizePatternRanges(ranges)
+	if negated {
+		ranges = negatePatternRanges(ranges)
+	}
+	if subtracted != nil {
+		ranges = negatePatternRanges(normalizePatternRanges(append(negatePatternRanges(ranges), subtracted...)))
+	}
+	if !translated {
+		return string(t.runes[start:t.pos]), ranges, nil
+	}
+	if len(ranges) == 0 {
+		return `[^\x{0}-\x{10FFFF}]`, ranges, nil
+	}
+	return "[" + formatPatternRanges(ranges) + "]", ranges, nil
+}
+
+// classItem returns the ranges of the characters of the character, the
+// range of characters or the escape at the position in a character class,
+// and whether it's a construct of the XML schema regular expressions.
+func (t *patternTranslator) classItem() ([]rune, bool, error) {
+	start := t.pos
+	lo, ranges, translated, err := t.classAtom()
+	if err != nil || ranges != nil {
+		return ranges, translated, err
+	}
+	if t.pos+1 < len(t.runes) && t.runes[t.pos] == '-' && t.runes[t.pos+1] != ']' && t.runes[t.pos+1] != '[' {
+		t.pos++
+		hi, hiRanges, _, err := t.classAtom()
+		if err != nil {
+			return nil, false, err
+		}
+		if hiRanges != nil || hi < lo {
+			return nil, false, fmt.Errorf("invalid character class range %s", string(t.runes[start:t.pos]))
+		}
+		return []rune{lo, hi}, false, nil
+	}
+	return []rune{lo, lo}, false, nil
+}
+
+// classAtom returns the character at the position in a character class, or
+// the ranges of the characters of the escape at the position if it isn't a
+// single character.
+func (t *patternTranslator) classAtom() (rune, []rune, bool, error) {
+	if t.runes[t.pos] != '\\' {
+		t.pos++
+		return t.runes[t.pos-1], nil, false, nil
+	}
+	text, err := t.escapeText()
+	if err != nil {
+		return 0, nil, false, err
+	}
+	switch text {
+	case `\i`, `\I`, `\c`, `\C`:
+		chars := patternNameStartChars
+		if strings.ToLower(text) == `\c` {
+			chars = patternNameChars
+		}
+		ranges, err := getPatternRanges("[" + chars + "]")
+		if text == `\I` || text == `\C` {
+			ranges = negatePatternRanges(ranges)
+		}
+		return 0, ranges, true, err
+	}
+	if block, negated, ok, err := getPatternBlock(text); ok || err != nil {
+		if negated {
+			block = negatePatternRanges(block)
+		}
+		return 0, block, true, err
+	}
+	ranges, err := getPatternRanges("[" + text + "]")
+	if err == nil && len(ranges) == 2 && ranges[0] == ranges[1] {
+		return ranges[0], nil, false, nil
+	}
+	return 0, ranges, false, err
+}
+
+// getPatternRanges returns the ranges of the characters of the character
+// class of the regular expressions of Go.
+func getPatternRanges(class string) ([]rune, error) {
+	re, err := syntax.Parse(class, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	switch re.Op {
+	case syntax.OpCharClass:
+		return append([]rune{}, re.Rune...), nil
+	case syntax.OpLiteral:
+		return []rune{re.Rune[0], re.Rune[0]}, nil
+	case syntax.OpAnyChar:
+		return []rune{0, unicode.MaxRune}, nil
+	case syntax.OpAnyCharNotNL:
+		return []rune{0, '\n' - 1, '\n' + 1, unicode.MaxRune}, nil
+	case syntax.OpNoMatch:
+		return []rune{}, nil
+	}
+	return nil, fmt.Errorf("unsupported character class %s", class)
+}
+
+// normalizePatternRanges returns the ranges sorted, with the overlapping and
+// the adjacent ranges merged.
+func normalizePatternRanges(ranges []rune) []rune {
+	pairs := make([][2]rune, 0, len(ranges)/2)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		pairs = append(pairs, [2]rune{ranges[i], ranges[i+1]})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i][0] < pairs[j][0] })
+	normalized := []rune{}
+	for _, pair := range pairs {
+		if n := len(normalized); n > 0 && pair[0] <= normalized[n-1]+1 {
+			if pair[1] > normalized[n-1] {
+				normalized[n-1] = pair[1]
+			}
+			continue
+		}
+		normalized = append(normalized, pair[0], pair[1])
+	}
+	return normalized
+}
+
+// negatePatternRanges returns the ranges of the characters which aren't in
+// the normalized ranges.
+func negatePatternRanges(ranges []rune) []rune {
+	negated := []rune{}
+	next := rune(0)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i] > next {
+			negated = append(negated, next, ranges[i]-1)
+		}
+		next = ranges[i+1] + 1
+	}
+	if next <= unicode.MaxRune {
+		negated = append(negated, next, unicode.MaxRune)
+	}
+	return negated
+}
+
+// formatPatternRanges returns the ranges as the content of a character
+// class, the ASCII letters and digits are written as they are and the other
+// characters as the \x{...} escapes.
+func formatPatternRanges(ranges []rune) string {
+	format := func(r rune) string {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return string(r)
+		}
+		return fmt.Sprintf(`\x{%X}`, r)
+	}
+	var b strings.Builder
+	for i := 0; i+1 < len(ranges); i += 2 {
+		b.WriteString(format(ranges[i]))
+		if ranges[i+1] != ranges[i] {
+			b.WriteString("-" + format(ranges[i+1]))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Stages of the pipeline run by the Parse, the proto tree of the
+// declarations, such as *SimpleType and *ComplexType, is the intermediate
+// representation passed between the stages.
+const (
+	StageParse     = "parse"
+	StageResolve   = "resolve"
+	StageNormalize = "normalize"
+	StageGenerate  = "generate"
+)
+
+// Transform changes the proto tree between the stages of the pipeline, such
+// as renaming, pruning or merging the declarations. It's called with the
+// stage which has finished and the proto tree of the stage, and returns the
+// proto tree for the next stage.
+type Transform func(stage string, protoTree []interface{}) ([]interface{}, error)
+
+// ParseSchema is the parse stage of the pipeline, it reads the XML schema of
+// the options into the proto tree, with the types of the declarations
+// resolved to the built-in types of the language, without the other stages.
+func (opt *Options) ParseSchema() error {
+	opt.FileDir = filepath.Dir(opt.FilePath)
+	source, err := opt.openSource()
+	if source == nil || err != nil {
+		return err
+	}
+	defer source.Close()
+	return opt.decode(source)
+}
+
+// ResolveTypes is the resolve stage of the pipeline, it sets the facets of
+// the simple types referenced by the elements and attributes, forces the
+// elements and attributes of the optional overrides to be optional or
+// required, marks the ones of the deprecations as deprecated, adds the
+// customizations, adds the substitution groups and the derived types of the
+// abstract types, maps the declarations without type to the any type
+// fallback, and the boolean, decimal, date and time types to the types of
+// their forms in the language of the options, whose types include the
+// external types.
+func (opt *Options) ResolveTypes() error {
+	if err := opt.resolveRestrictions(); err != nil {
+		return err
+	}
+	if opt.Lang == "" {
+		return nil
+	}
+	opt.resolveOptionalOverrides()
+	opt.resolveDeprecations()
+	opt.resolveCustomizations()
+	opt.resolveSubstitutionGroups()
+	opt.resolveDerivedTypes()
+	opt.resolveUntypedDeclarations()
+	opt.registerExternalTypes()
+	if opt.AnyTypeFallback != "" {
+		opt.registerBuiltInType(opt.AnyTypeFallback)
+	}
+	if booleanType, ok := opt.getBooleanType(); ok {
+		opt.registerBuiltInType(booleanType)
+	}
+	if decimalType, ok := opt.getDecimalType(); ok {
+		opt.registerBuiltInType(decimalType)
+		opt.resolveDecimalScales()
+	}
+	for _, chronoType := range rustChronoTypes {
+		if name, ok := opt.getChronoType(chronoType.xsd); ok {
+			opt.registerBuiltInType(name)
+		}
+	}
+	return nil
+}
+
+// NormalizeNames is the normalize stage of the pipeline, it renames the types
+// whose identifier collides with the reserved identifiers of the language of
+// the options.
+func (opt *Options) NormalizeNames() {
+	if opt.Lang != "" {
+		opt.renameReservedTypes()
+	}
+}
+
+// applyTransforms applies the transforms of the options to the proto tree
+// after given stage.
+func (opt *Options) applyTransforms(stage string) (err error) {
+	for _, transform := range opt.Transforms {
+		if opt.ProtoTree, err = transform(stage, opt.ProtoTree); err != nil {
+			return fmt.Errorf("transform after the %s stage: %v", stage, err)
+		}
+	}
+	return
+}
@@ -0,0 +1,158 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// pluralIrregulars holds the plurals of the words which don't follow the
+// suffix rules, the uncountable words are their own plurals.
+var pluralIrregulars = map[string]string{
+	"analysis": "analyses", "appendix": "appendices", "basis": "bases",
+	"child": "children", "criterion": "criteria", "crisis": "crises",
+	"datum": "data", "foot": "feet", "goose": "geese", "half": "halves",
+	"index": "indices", "knife": "knives", "leaf": "leaves", "life": "lives",
+	"man": "men", "matrix": "matrices", "mouse": "mice", "ox": "oxen",
+	"person": "people", "phenomenon": "phenomena", "shelf": "shelves",
+	"thesis": "theses", "tooth": "teeth", "vertex": "vertices", "woman": "women",
+	"data": "data", "equipment": "equipment", "information": "information",
+	"metadata": "metadata", "news": "news", "series": "series", "software": "software",
+	"species": "species",
+}
+
+// ParsePluralOverrides parses the comma-separated name=plural pairs into the
+// plural overrides option, which holds the plurals of the repeated elements
+// and groups by name.
+func ParsePluralOverrides(value string) (map[string]string, error) {
+	overrides := map[string]string{}
+	if value == "" {
+		return overrides, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		idx := strings.Index(pair, "=")
+		if idx <= 0 || pair[idx+1:] == "" {
+			return nil, fmt.Errorf("invalid plural override %s, expected <name>=<plural>", pair)
+		}
+		overrides[pair[:idx]] = pair[idx+1:]
+	}
+	return overrides, nil
+}
+
+// formatPluralOverrides returns the plural overrides option as the
+// comma-separated name=plural pairs ordered by name.
+func formatPluralOverrides(overrides map[string]string) string {
+	var names, pairs []string
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pairs = append(pairs, name+"="+overrides[name])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// genPluralName returns the name the field of the repeated element or group
+// by given name is generated from, which is the plural of the name in plural
+// names mode, unless the plural is overridden.
+func (gen *CodeGenerator) genPluralName(name string, plural bool) string {
+	if !gen.PluralNames || !plural {
+		return name
+	}
+	if override, ok := gen.PluralOverrides[name]; ok {
+		return override
+	}
+	return pluralize(name)
+}
+
+// genRustPluralName returns the name the Rust field of the repeated element
+// or group by given name is generated from, the last word of the snake case
+// name is pluralized unless the plural is overridden, such as BIC to bics.
+func (gen *CodeGenerator) genRustPluralName(name string, plural bool) string {
+	pluralName := gen.genPluralName(name, plural)
+	if _, ok := gen.PluralOverrides[name]; ok || pluralName == name {
+		return pluralName
+	}
+	return pluralize(strings.TrimSuffix(genRustFieldName(name), "_attr"))
+}
+
+// pluralize returns the plural of the last word of the name, the words are
+// separated by the hyphens, the underscores and the case changes. The
+// uppercase acronyms and the abbreviations without vowels get the s suffix,
+// such as BIC to BICs and Tx to Txs, and the words ending with s which aren't
+// singular, such as Amounts, are kept.
+func pluralize(name string) string {
+	runes := []rune(name)
+	start := 0
+	for i := len(runes) - 1; i > 0; i-- {
+		if runes[i-1] == '-' || runes[i-1] == '_' || runes[i-1] == '.' ||
+			unicode.IsUpper(runes[i]) && !unicode.IsUpper(runes[i-1]) {
+			start = i
+			break
+		}
+	}
+	prefix, word := string(runes[:start]), string(runes[start:])
+	lower := strings.ToLower(word)
+	if lower == "" || !unicode.IsLetter([]rune(lower)[len([]rune(lower))-1]) {
+		return name
+	}
+	if irregular, ok := pluralIrregulars[lower]; ok {
+		switch {
+		case word == strings.ToUpper(word) && len(word) > 1:
+			return prefix + strings.ToUpper(irregular)
+		case unicode.IsUpper([]rune(word)[0]):
+			return prefix + MakeFirstUpperCase(irregular)
+		}
+		return prefix + irregular
+	}
+	if word == strings.ToUpper(word) && len(word) > 1 {
+		return name + "s"
+	}
+	switch {
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return name[:len(name)-1] + "ies"
+	case !strings.ContainsAny(lower, "aeiouy"):
+		// The abbreviations without vowels, such as Tx.
+		return name + "s"
+	case strings.HasSuffix(lower, "s") && !strings.HasSuffix(lower, "ss") && !strings.HasSuffix(lower, "us") && !strings.HasSuffix(lower, "is"):
+		// The words which are plural already.
+		return name
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// genGoPluralTag generate the struct tag of the field without XML struct tag
+// for Go code with the given struct tags, which is given the XML struct tag
+// with the name of the element or group if the field name is pluralized.
+func genGoPluralTag(fieldName, name, tags string) string {
+	if fieldName == genGoFieldName(name, false) {
+		if tags == "" {
+			return ""
+		}
+		return fmt.Sprintf("\t`%s`", strings.TrimPrefix(tags, " "))
+	}
+	return fmt.Sprintf("\t`xml:\"%s\"%s`", name, tags)
+}
+
+// genJavaPluralAnnotation generate the annotation of the field without
+// annotation for Java code, which is given the name of the group if the field
+// name is pluralized.
+func genJavaPluralAnnotation(pluralName, name string) string {
+	if pluralName == name {
+		return ""
+	}
+	return fmt.Sprintf("\t@XmlElement(name = \"%s\")\n", name)
+}
@@ -0,0 +1,310 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+)
+
+// genGoPrettyMethod generate the ToPrettyXML method of the root element
+// wrapper for Go code, if the pretty XML option is set.
+func (gen *CodeGenerator) genGoPrettyMethod(wrapperName, elementName string) string {
+	if !gen.PrettyXML {
+		return ""
+	}
+	return fmt.Sprintf("\n// ToPrettyXML encodes the XML document with the %s root element as ToXML,\n// in the canonical pretty form for the diffs of the documents.\nfunc (v *%s) ToPrettyXML() ([]byte, error) {\n\tdata, err := v.ToXML()\n\tif err != nil {\n\t\treturn nil, err\n\t}\n\treturn prettyXML(data)\n}\n", elementName, wrapperName)
+}
+
+// genGoPrettyXML writes the function of the root element wrappers rewriting
+// the encoded XML documents in the canonical pretty form.
+func (gen *CodeGenerator) genGoPrettyXML(packageName string) error {
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+package %s
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+)
+
+// prettyXMLNode is an element of the XML document being rewritten by
+// prettyXML, or the text, the comment or the processing instruction written
+// as is.
+type prettyXMLNode struct {
+	name     string
+	attrs    []xml.Attr
+	raw      string
+	text     bool
+	children []*prettyXMLNode
+}
+
+// prettyXMLText escapes the character data of the text nodes.
+var prettyXMLText = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// prettyXML rewrites the encoded XML document in the canonical pretty form,
+// with each element of element-only content on its line indented by two
+// spaces per level, and the attributes ordered by name after the namespace
+// declarations. The elements of simple and mixed content are kept on a line
+// with their text, whose whitespace is significant.
+func prettyXML(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	stack := []*prettyXMLNode{{}}
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &prettyXMLNode{name: prettyXMLName(t.Name), attrs: append([]xml.Attr(nil), t.Attr...)}
+			sort.SliceStable(node.attrs, func(i, j int) bool {
+				return prettyXMLAttrKey(node.attrs[i]) < prettyXMLAttrKey(node.attrs[j])
+			})
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) < 2 {
+				return nil, &xml.SyntaxError{Msg: "unexpected end element </" + prettyXMLName(t.Name) + ">"}
+			}
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.children = append(parent.children, &prettyXMLNode{raw: prettyXMLText.Replace(string(t)), text: true})
+		case xml.Comment:
+			parent.children = append(parent.children, &prettyXMLNode{raw: "<!--" + string(t) + "-->"})
+		case xml.ProcInst:
+			inst := t.Target
+			if len(t.Inst) > 0 {
+				inst += " " + string(t.Inst)
+			}
+			parent.children = append(parent.children, &prettyXMLNode{raw: "<?" + inst + "?>"})
+		case xml.Directive:
+			parent.children = append(parent.children, &prettyXMLNode{raw: "<!" + string(t) + ">"})
+		}
+	}
+	var buf bytes.Buffer
+	for _, node := range stack[0].children {
+		if !node.text {
+			node.write(&buf, "", false)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// prettyXMLName returns the qualified name of the element or the attribute
+// as written in the document.
+func prettyXMLName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// prettyXMLAttrKey returns the key ordering the attributes, the default
+// namespace declaration first, the prefixed ones next and the attributes
+// last, each by name.
+func prettyXMLAttrKey(attr xml.Attr) string {
+	switch {
+	case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+		return "0"
+	case attr.Name.Space == "xmlns":
+		return "1" + attr.Name.Local
+	}
+	return "2" + prettyXMLName(attr.Name)
+}
+
+// write writes the node indented by given indent, the children of the
+// elements of simple and mixed content are written inline.
+func (node *prettyXMLNode) write(buf *bytes.Buffer, indent string, inline bool) {
+	if node.name == "" {
+		buf.WriteString(node.raw)
+		return
+	}
+	buf.WriteString("<" + node.name)
+	for _, attr := range node.attrs {
+		buf.WriteString(" " + prettyXMLName(attr.Name) + "=\"")
+		xml.EscapeText(buf, []byte(attr.Value))
+		buf.WriteString("\"")
+	}
+	buf.WriteString(">")
+	for _, child := range node.children {
+		inline = inline || child.text && strings.TrimSpace(child.raw) != ""
+	}
+	var nested bool
+	for _, child := range node.children {
+		if inline {
+			child.write(buf, "", true)
+			continue
+		}
+		if child.text {
+			continue
+		}
+		buf.WriteString("\n" + indent + "  ")
+		child.write(buf, indent+"  ", false)
+		nested = true
+	}
+	if nested {
+		buf.WriteString("\n" + indent)
+	}
+	buf.WriteString("</" + node.name + ">")
+}
+`, gen.fileHeader(), packageName)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "xml_pretty.go"), source)
+}
+
+// genRustPrettyMethod generate the to_pretty_xml function of the root element
+// wrapper for Rust code, if the pretty XML option is set.
+func (gen *CodeGenerator) genRustPrettyMethod() string {
+	if !gen.PrettyXML {
+		return ""
+	}
+	if !strings.Contains(gen.mixinCode, "fn pretty_xml(") {
+		gen.mixinCode += genRustPrettyXML()
+	}
+	return "\n\tpub fn to_pretty_xml(&self) -> Result<String, Box<dyn std::error::Error>> {\n\t\tpretty_xml(&self.to_xml()?)\n\t}\n"
+}
+
+// genRustPrettyXML generate the function of the root element wrappers for
+// Rust code, which rewrites the serialized XML document in the canonical
+// pretty form, with each element of element-only content on its line
+// indented by two spaces per level, and the attributes ordered by name after
+// the namespace declarations. The elements of simple and mixed content are
+// kept on a line with their text.
+func genRustPrettyXML() string {
+	return `
+struct PrettyXmlNode {
+	name: String,
+	attrs: Vec<(String, String)>,
+	raw: String,
+	text: bool,
+	children: Vec<PrettyXmlNode>,
+}
+
+impl PrettyXmlNode {
+	fn raw(raw: String, text: bool) -> Self {
+		PrettyXmlNode { name: String::new(), attrs: Vec::new(), raw, text, children: Vec::new() }
+	}
+
+	fn write(&self, out: &mut String, indent: &str, inline: bool) {
+		if self.name.is_empty() {
+			out.push_str(&self.raw);
+			return;
+		}
+		out.push_str(&format!("<{}", self.name));
+		for (name, value) in &self.attrs {
+			out.push_str(&format!(" {}=\"{}\"", name, pretty_xml_escape(value, true)));
+		}
+		out.push('>');
+		let inline = inline || self.children.iter().any(|child| child.text && !child.raw.trim().is_empty());
+		let child_indent = format!("{}  ", indent);
+		let mut nested = false;
+		for child in &self.children {
+			if inline {
+				child.write(out, "", true);
+				continue;
+			}
+			if child.text {
+				continue;
+			}
+			out.push_str(&format!("\n{}", child_indent));
+			child.write(out, &child_indent, false);
+			nested = true;
+		}
+		if nested {
+			out.push_str(&format!("\n{}", indent));
+		}
+		out.push_str(&format!("</{}>", self.name));
+	}
+}
+
+fn pretty_xml_escape(text: &str, attr: bool) -> String {
+	let mut escaped = String::with_capacity(text.len());
+	for c in text.chars() {
+		match c {
+			'&' => escaped.push_str("&amp;"),
+			'<' => escaped.push_str("&lt;"),
+			'>' => escaped.push_str("&gt;"),
+			'"' if attr => escaped.push_str("&#34;"),
+			'\'' if attr => escaped.push_str("&#39;"),
+			'\t' if attr => escaped.push_str("&#x9;"),
+			'\n' if attr => escaped.push_str("&#xA;"),
+			'\r' if attr => escaped.push_str("&#xD;"),
+			_ => escaped.push(c),
+		}
+	}
+	escaped
+}
+
+fn pretty_xml(xml: &str) -> Result<String, Box<dyn std::error::Error>> {
+	use quick_xml::events::{BytesStart, Event};
+	let element = |e: &BytesStart| -> Result<PrettyXmlNode, Box<dyn std::error::Error>> {
+		let mut attrs = Vec::new();
+		for attr in e.attributes() {
+			let attr = attr?;
+			attrs.push((String::from_utf8(attr.key.as_ref().to_vec())?, attr.unescape_value()?.into_owned()));
+		}
+		attrs.sort_by_key(|(name, _)| match name.as_str() {
+			"xmlns" => (0, name.clone()),
+			_ if name.starts_with("xmlns:") => (1, name.clone()),
+			_ => (2, name.clone()),
+		});
+		let mut node = PrettyXmlNode::raw(String::new(), false);
+		node.name = String::from_utf8(e.name().as_ref().to_vec())?;
+		node.attrs = attrs;
+		Ok(node)
+	};
+	let mut reader = quick_xml::Reader::from_str(xml);
+	let mut stack = vec![PrettyXmlNode::raw(String::new(), false)];
+	loop {
+		let node = match reader.read_event()? {
+			Event::Start(e) => {
+				stack.push(element(&e)?);
+				continue;
+			}
+			Event::End(e) => {
+				if stack.len() < 2 {
+					return Err(format!("unexpected end element </{}>", String::from_utf8_lossy(e.name().as_ref())).into());
+				}
+				stack.pop().unwrap()
+			}
+			Event::Empty(e) => element(&e)?,
+			Event::Text(e) => PrettyXmlNode::raw(pretty_xml_escape(&e.unescape()?, false), true),
+			Event::CData(e) => PrettyXmlNode::raw(pretty_xml_escape(&String::from_utf8(e.into_inner().into_owned())?, false), true),
+			Event::Comment(e) => PrettyXmlNode::raw(format!("<!--{}-->", String::from_utf8(e.to_vec())?), false),
+			Event::Decl(e) => PrettyXmlNode::raw(format!("<?{}?>", String::from_utf8(e.to_vec())?), false),
+			Event::PI(e) => PrettyXmlNode::raw(format!("<?{}?>", String::from_utf8(e.to_vec())?), false),
+			Event::DocType(e) => PrettyXmlNode::raw(format!("<!DOCTYPE {}>", String::from_utf8(e.to_vec())?), false),
+			Event::Eof => break,
+		};
+		stack.last_mut().unwrap().children.push(node);
+	}
+	let mut out = String::new();
+	for node in &stack[0].children {
+		if !node.text {
+			node.write(&mut out, "", false);
+			out.push('\n');
+		}
+	}
+	Ok(out)
+}
+`
+}
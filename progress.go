@@ -0,0 +1,92 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Progress describes the progress of a batch of XML schema files, which is
+// reported after each file has been processed.
+type Progress struct {
+	File      string
+	Completed int
+	Total     int
+	Elapsed   time.Duration
+	// Remaining is the estimated time to process the rest of the files, by
+	// the average time per file so far.
+	Remaining time.Duration
+	// Warnings holds the warnings of the file, such as the declarations
+	// without type, and of the schemas it imports and includes, except the
+	// ones reported for the files before.
+	Warnings []Warning
+}
+
+// ParseFiles parses the XML schema files by the options returned by given
+// function for each file, and reports the progress to the progress callback
+// if it isn't nil, and the events of the files to the Events callback of the
+// options if it isn't nil. The directories in the file list are skipped. The
+// files share a schema cache unless the options hold their own, so the
+// schemas imported by many files are parsed once. With the versioned packages
+// option, the conversion helper stubs between the versions of the messages
+// are generated after all the files.
+func ParseFiles(files []string, options func(file string) *Options, progress func(Progress)) error {
+	var schemas []string
+	for _, file := range files {
+		fi, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() {
+			schemas = append(schemas, file)
+		}
+	}
+	start := time.Now()
+	cache := NewSchemaCache()
+	parsed := make([]*Options, 0, len(schemas))
+	// The warnings of the schemas imported and included by many files are
+	// reported for the first of them.
+	reported := map[Warning]bool{}
+	for i, file := range schemas {
+		opt := options(file)
+		if opt.Cache == nil {
+			opt.Cache = cache
+		}
+		opt.emitEvent(Event{Event: EventFileStarted, Completed: i, Total: len(schemas)})
+		err := NewParser(opt).Parse()
+		var warnings []Warning
+		for _, warning := range opt.Warnings {
+			if !reported[warning] {
+				reported[warning] = true
+				warnings = append(warnings, warning)
+			}
+		}
+		if err != nil {
+			opt.emitFileFinished(warnings, err, i, len(schemas), start)
+			return fmt.Errorf("process error on %s: %s", file, err.Error())
+		}
+		opt.emitFileFinished(warnings, nil, i+1, len(schemas), start)
+		parsed = append(parsed, opt)
+		parsed = append(parsed, opt.langOptions...)
+		if progress != nil {
+			elapsed := time.Since(start)
+			progress(Progress{
+				File:      file,
+				Completed: i + 1,
+				Total:     len(schemas),
+				Elapsed:   elapsed,
+				Remaining: elapsed / time.Duration(i+1) * time.Duration(len(schemas)-i-1),
+				Warnings:  warnings,
+			})
+		}
+	}
+	return genGoVersionConversions(parsed)
+}
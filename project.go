@@ -0,0 +1,336 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// JavaProjectNone, JavaProjectMaven and JavaProjectGradle are the build tools
+// of the Java project generated around the Java code. Without the project the
+// Java code is generated into a single file by schema file.
+const (
+	JavaProjectNone   = ""
+	JavaProjectMaven  = "maven"
+	JavaProjectGradle = "gradle"
+)
+
+// ProjectVersionDefault is the version of the generated Java project and npm
+// package if not specified.
+const ProjectVersionDefault = "1.0.0"
+
+// javaSourceDir is the directory of the Java sources in the standard layout
+// of the Maven and Gradle projects.
+const javaSourceDir = "src/main/java"
+
+// javaTypeRegexp matches the declaration of the public top level type of a
+// block of the Java code, and captures the name of the type.
+var javaTypeRegexp = regexp.MustCompile(`(?m)^public (?:(?:abstract|final) )*(?:class|interface|enum) (\w+)`)
+
+// checkJavaProject returns an error if the build tool of the Java project
+// isn't supported.
+func checkJavaProject(project string) error {
+	switch project {
+	case JavaProjectNone, JavaProjectMaven, JavaProjectGradle:
+		return nil
+	}
+	return fmt.Errorf("unsupport Java project %s, expected %s or %s", project, JavaProjectMaven, JavaProjectGradle)
+}
+
+// getJavaPackage returns the package of the generated Java code by given
+// package name, defaults to schema.
+func getJavaPackage(packageName string) string {
+	if packageName == "" {
+		return "schema"
+	}
+	return packageName
+}
+
+// getJavaSourcePath returns the path the Java code of the schema file is
+// generated to in the Java project, that is the directory of the package
+// under the source directory of the project.
+func (opt *Options) getJavaSourcePath(packageName string) string {
+	return filepath.Join(opt.OutputDir, filepath.FromSlash(javaSourceDir), filepath.FromSlash(strings.Replace(getJavaPackage(packageName), ".", "/", -1)), filepath.Base(opt.FilePath))
+}
+
+// splitJavaTypes splits the Java code into the blocks of the public top level
+// types by the name of the type, and returns the names in the order of the
+// code. Each block ends with the closing brace of the type, and the code
+// between the types, such as the comments, goes to the block of the next
+// type.
+func splitJavaTypes(code string) (names []string, blocks map[string]string) {
+	blocks = map[string]string{}
+	var block string
+	for _, line := range strings.SplitAfter(code, "\n") {
+		block += line
+		if strings.TrimRight(line, "\n") != "}" {
+			continue
+		}
+		if match := javaTypeRegexp.FindStringSubmatch(block); match != nil {
+			if _, ok := blocks[match[1]]; !ok {
+				names = append(names, match[1])
+			}
+			blocks[match[1]] += block
+			block = ""
+		}
+	}
+	if strings.TrimSpace(block) != "" && len(names) > 0 {
+		blocks[names[len(names)-1]] += block
+	}
+	return
+}
+
+// genJavaProjectSources writes each public top level type of the Java code
+// to its own file named by the type in the package directory, as required by
+// the Java compiler.
+func (gen *CodeGenerator) genJavaProjectSources(packageName, importPackage string) error {
+	names, blocks := splitJavaTypes(gen.Field)
+	for _, name := range names {
+		if err := gen.WriteFile(filepath.Join(filepath.Dir(gen.File), name+".java"), []byte(fmt.Sprintf("%s\n\npackage %s;\n\n%s\n%s", gen.fileHeader(), packageName, importPackage, blocks[name]))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// genJavaProject writes the build file of the Java project and the
+// module-info.java of the package in the output directory, so the generated
+// Java code is compiled and published as a versioned library by Maven or
+// Gradle. The group of the project is the parent of the package, and the
+// artifact is the last name of the package. The JAXB API is required by the
+// module, and the JAXB runtime is a runtime dependency of the project.
+func (opt *Options) genJavaProject(gen *CodeGenerator) error {
+	packageName := getJavaPackage(gen.Package)
+	group, artifact := packageName, packageName
+	if i := strings.LastIndex(packageName, "."); i != -1 {
+		group, artifact = packageName[:i], packageName[i+1:]
+	}
+	version := opt.ProjectVersion
+	if version == "" {
+		version = ProjectVersionDefault
+	}
+	moduleInfo := fmt.Sprintf("%s\n\nmodule %s {\n\trequires transitive java.xml.bind;\n\n\texports %s;\n\n\topens %s to java.xml.bind;\n}\n", gen.fileHeader(), packageName, packageName, packageName)
+	if err := gen.WriteFile(filepath.Join(opt.OutputDir, filepath.FromSlash(javaSourceDir), "module-info.java"), []byte(moduleInfo)); err != nil {
+		return err
+	}
+	if opt.JavaProject == JavaProjectGradle {
+		return gen.WriteFile(filepath.Join(opt.OutputDir, "build.gradle"), []byte(fmt.Sprintf(`plugins {
+	id 'java-library'
+	id 'maven-publish'
+}
+
+group = '%s'
+version = '%s'
+
+java {
+	toolchain {
+		languageVersion = JavaLanguageVersion.of(11)
+	}
+	withSourcesJar()
+}
+
+repositories {
+	mavenCentral()
+}
+
+dependencies {
+	api 'jakarta.xml.bind:jakarta.xml.bind-api:2.3.3'
+	runtimeOnly 'org.glassfish.jaxb:jaxb-runtime:2.3.9'
+}
+
+publishing {
+	publications {
+		maven(MavenPublication) {
+			artifactId = '%s'
+			from components.java
+		}
+	}
+}
+`, group, version, artifact)))
+	}
+	return gen.WriteFile(filepath.Join(opt.OutputDir, "pom.xml"), []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 https://maven.apache.org/xsd/maven-4.0.0.xsd">
+	<modelVersion>4.0.0</modelVersion>
+
+	<groupId>%s</groupId>
+	<artifactId>%s</artifactId>
+	<version>%s</version>
+	<packaging>jar</packaging>
+
+	<properties>
+		<maven.compiler.release>11</maven.compiler.release>
+		<project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>
+	</properties>
+
+	<dependencies>
+		<dependency>
+			<groupId>jakarta.xml.bind</groupId>
+			<artifactId>jakarta.xml.bind-api</artifactId>
+			<version>2.3.3</version>
+		</dependency>
+		<dependency>
+			<groupId>org.glassfish.jaxb</groupId>
+			<artifactId>jaxb-runtime</artifactId>
+			<version>2.3.9</version>
+			<scope>runtime</scope>
+		</dependency>
+	</dependencies>
+
+	<build>
+		<plugins>
+			<plugin>
+				<groupId>org.apache.maven.plugins</groupId>
+				<artifactId>maven-compiler-plugin</artifactId>
+				<version>3.11.0</version>
+			</plugin>
+		</plugins>
+	</build>
+</project>
+`, escapeXML(group), escapeXML(artifact), escapeXML(version))))
+}
+
+// typeScriptModuleRegexp matches the exports of the index.ts barrel of the
+// npm package, and captures the path of the exported module.
+var typeScriptModuleRegexp = regexp.MustCompile(`(?m)^export \* (?:as \w+ )?from '\./(.+)';$`)
+
+// typeScriptPackageJSON is the package.json of the npm package generated
+// around the TypeScript code.
+type typeScriptPackageJSON struct {
+	Name        string            `json:"name"`
+	Version     string            `json:"version"`
+	Description string            `json:"description"`
+	Main        string            `json:"main"`
+	Types       string            `json:"types"`
+	Files       []string          `json:"files"`
+	SideEffects bool              `json:"sideEffects"`
+	Scripts     map[string]string `json:"scripts"`
+	DevDeps     map[string]string `json:"devDependencies"`
+}
+
+// typeScriptConfig is the tsconfig.json of the npm package, which compiles
+// the TypeScript code to the CommonJS modules and the declaration files in
+// the dist directory.
+const typeScriptConfig = `{
+  "compilerOptions": {
+    "target": "ES2019",
+    "module": "commonjs",
+    "lib": ["ES2019", "DOM"],
+    "declaration": true,
+    "sourceMap": true,
+    "outDir": "dist",
+    "rootDir": ".",
+    "strict": true,
+    "strictPropertyInitialization": false,
+    "skipLibCheck": true
+  },
+  "include": ["**/*.ts"],
+  "exclude": ["dist", "node_modules"]
+}
+`
+
+// genTypeScriptPackage merges the modules of the TypeScript code into the
+// index.ts barrel in the output directory, and writes the package.json and
+// the tsconfig.json, so the output is published as an npm package. The
+// modules of a single schema file are exported by the barrel directly, and
+// the modules of several schema files are exported as the namespaces named by
+// the modules, since the schema files declare the types of the same name.
+func (opt *Options) genTypeScriptPackage(gen *CodeGenerator) error {
+	exports := map[string]bool{}
+	files := []string{gen.FileWithExtension(".ts")}
+	if opt.Constants {
+		files = append(files, gen.FileWithExtension(".constants.ts"))
+	}
+	for _, file := range files {
+		if rel, err := filepath.Rel(opt.OutputDir, file); err == nil {
+			exports[strings.TrimSuffix(filepath.ToSlash(rel), ".ts")] = true
+		}
+	}
+	name := filepath.Join(opt.OutputDir, "index.ts")
+	var data []byte
+	if opt.Artifacts != nil {
+		data = opt.Artifacts[name]
+	} else if existing, err := ioutil.ReadFile(name); err == nil {
+		data = existing
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	for _, match := range typeScriptModuleRegexp.FindAllStringSubmatch(string(data), -1) {
+		exports[match[1]] = true
+	}
+	var modules []string
+	for module := range exports {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	var schemas int
+	for _, module := range modules {
+		if !strings.HasSuffix(module, ".constants") {
+			schemas++
+		}
+	}
+	index := copyright + "\n\n"
+	for _, module := range modules {
+		if schemas > 1 {
+			index += fmt.Sprintf("export * as %s from './%s';\n", genTypeScriptModuleName(module), module)
+			continue
+		}
+		index += fmt.Sprintf("export * from './%s';\n", module)
+	}
+	if err := gen.WriteFile(name, []byte(index)); err != nil {
+		return err
+	}
+	version := opt.ProjectVersion
+	if version == "" {
+		version = ProjectVersionDefault
+	}
+	packageJSON, err := json.MarshalIndent(typeScriptPackageJSON{
+		Name:        opt.NPMPackage,
+		Version:     version,
+		Description: "The types of the XML schema definitions generated by xgen.",
+		Main:        "dist/index.js",
+		Types:       "dist/index.d.ts",
+		Files:       []string{"dist"},
+		Scripts:     map[string]string{"build": "tsc", "prepublishOnly": "tsc"},
+		DevDeps:     map[string]string{"typescript": "^5.0.0"},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = gen.WriteFile(filepath.Join(opt.OutputDir, "package.json"), append(packageJSON, '\n')); err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(opt.OutputDir, "tsconfig.json"), []byte(typeScriptConfig))
+}
+
+// genTypeScriptModuleName returns the name of the namespace the module by
+// given path is exported as by the index.ts barrel, such as pacs_008_001_08
+// of the module pacs.008.001.08.xsd.
+func genTypeScriptModuleName(module string) string {
+	name := regexp.MustCompile(`[^A-Za-z0-9_]+`).ReplaceAllString(strings.Replace(module, ".xsd", "", -1), "_")
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// escapeXML returns the text escaped as the character data of the XML.
+func escapeXML(text string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
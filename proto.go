@@ -10,18 +10,39 @@ package xgen
 
 import "regexp"
 
+// Documentation holds the content of a documentation element of the
+// declaration, along with the language given by the xml:lang attribute.
+type Documentation struct {
+	Lang string
+	Text string
+}
+
 // SimpleType definitions provide for constraining character information item
-// [children] of element and attribute information items.
+// [children] of element and attribute information items. MemberTypes maps
+// the member types of the union by name to their types, and Members holds
+// their names in the order of the memberTypes attribute, which is the order
+// the values are validated against them. ItemType holds the name of the
+// item type of the list, and ItemRestriction the facets of its anonymous
+// item type, which each item of the list is validated against.
 // https://www.w3.org/TR/xmlschema-1/#Simple_Type_Definitions
 type SimpleType struct {
 	Doc         string
+	Docs        []Documentation
 	Name        string
 	Base        string
 	Anonymous   bool
 	List        bool
 	Union       bool
 	MemberTypes map[string]string
+	Members     []string
+	ItemType    string
 	Restriction Restriction
+	// ItemRestriction holds the facets of the anonymous item type of the
+	// list, the facets of Restriction restrict the list itself.
+	ItemRestriction Restriction
+	// Custom holds the derives and the attributes added to the generated
+	// type.
+	Custom Customization
 }
 
 // Element declarations provide for: Local validation of element information
@@ -32,16 +53,55 @@ type SimpleType struct {
 // mechanism of element substitution groups.
 // https://www.w3.org/TR/xmlschema-1/#cElement_Declarations
 type Element struct {
-	Doc         string
-	Name        string
-	Wildcard    bool
-	Type        string
-	Abstract    bool
-	Plural      bool
-	Optional    bool
-	Nillable    bool
-	Default     string
-	Restriction Restriction
+	Doc               string
+	Docs              []Documentation
+	Name              string
+	Wildcard          bool
+	Type              string
+	TypeRef           string
+	Ref               bool
+	Abstract          bool
+	SubstitutionGroup string
+	Plural            bool
+	Optional          bool
+	Choice            string
+	Nillable          bool
+	Default           string
+	Fixed             string
+	Untyped           bool
+	Sensitive         bool
+	Deprecated        bool
+	Deprecation       string
+	Restriction       Restriction
+	Custom            Customization
+}
+
+// SubstitutionGroup is the group of the element declarations which can be
+// substituted for the head element wherever the head element is referenced,
+// that is the head element unless it's abstract, and the elements declared
+// with the head element or another member of the group as their
+// substitutionGroup. The references to the head element are given the type
+// of the group by name.
+// https://www.w3.org/TR/xmlschema-1/#Element_Equivalence_Class
+type SubstitutionGroup struct {
+	Doc     string
+	Name    string
+	Head    string
+	Members []Element
+}
+
+// DerivedTypes is the type substitution of the abstract complex type, which
+// can't be used in the instances but by a type derived from it, given by the
+// xsi:type attribute of the element. The members are the elements named by
+// the concrete types derived from the abstract type by extension. The
+// references to the abstract type are given the type of the derived types by
+// name.
+// https://www.w3.org/TR/xmlschema-1/#xsi_type
+type DerivedTypes struct {
+	Doc      string
+	Name     string
+	Abstract string
+	Members  []Element
 }
 
 // Attribute declarations provide for: Local validation of attribute
@@ -51,11 +111,19 @@ type Element struct {
 type Attribute struct {
 	Name        string
 	Doc         string
+	Docs        []Documentation
 	Type        string
+	TypeRef     string
 	Plural      bool
 	Default     string
+	Fixed       string
 	Optional    bool
+	Prohibited  bool
+	Sensitive   bool
+	Deprecated  bool
+	Deprecation string
 	Restriction Restriction
+	Custom      Customization
 }
 
 // ComplexType definitions are identified by their {name} and {target
@@ -67,33 +135,56 @@ type Attribute struct {
 // namespace}s are provided for reference from instances, and for use in the
 // XML representation of schema components (specifically in <element>). See
 // References to schema components across namespaces for the use of component
-// identifiers when importing one schema into another.
+// identifiers when importing one schema into another. Any and AnyAttribute
+// report whether the complex type has the element and the attribute
+// wildcards, given by the any and anyAttribute elements, and
+// AnyAttributeNamespace holds the namespaces of the attribute wildcard. Mixed
+// reports whether the character data may appear between the elements of the
+// content.
 // https://www.w3.org/TR/xmlschema-1/structures.html#element-complexType
 type ComplexType struct {
-	Doc            string
-	Name           string
-	Base           string
-	Anonymous      bool
-	Elements       []Element
-	Attributes     []Attribute
-	Groups         []Group
-	Choice         []Choice
-	AttributeGroup []AttributeGroup
-	Mixed          bool
+	Doc                   string
+	Docs                  []Documentation
+	Name                  string
+	Base                  string
+	Anonymous             bool
+	Abstract              bool
+	Elements              []Element
+	Attributes            []Attribute
+	Groups                []Group
+	Choice                []Choice
+	AttributeGroup        []AttributeGroup
+	Mixed                 bool
+	Any                   bool
+	AnyAttribute          bool
+	AnyAttributeNamespace NamespaceConstraint
+	Asserts               []Assert
+	Custom                Customization
+}
+
+// Assert (assertion) components of XSD 1.1 constrain the existence and the
+// values of the child elements and the attributes of the complex types, by
+// the XPath expression of the test, which is translated to the validation
+// code of the type.
+// https://www.w3.org/TR/xmlschema11-1/#cAssertions
+type Assert struct {
+	Test string
 }
 
 // Group (model group) definitions are provided primarily for reference from
 // the XML Representation of Complex Type Definitions. Thus, model group
 // definitions provide a replacement for some uses of XML's parameter entity
-// facility.
+// facility. Any reports whether the group has the element wildcard.
 // https://www.w3.org/TR/xmlschema-1/structures.html#cModel_Group_Definitions
 type Group struct {
 	Doc      string
+	Docs     []Documentation
 	Name     string
 	Elements []Element
 	Groups   []Group
 	Plural   bool
 	Ref      string
+	Any      bool
 }
 
 // Choice definitions are provided primarily for reference from
@@ -102,11 +193,17 @@ type Group struct {
 // present in the containing element. Generated code does not enforce the "one
 // and only one" constraint but the choice container is parsed in order to effectively
 // define if the elements it contains should be plural or not (as defined by the maxOccurs).
+// The choices of a complex type are numbered by ID in the order of the
+// schema, and the elements directly contained by a choice refer to it by ID,
+// so the choice may be generated as a Rust enum unless it's nested, which is
+// containing model groups, group references or wildcards.
 // https://www.w3.org/TR/xmlschema-1/#Complex_Type_Definition_details
 type Choice struct {
-	ID     string
-	Choice []Choice
-	Plural bool
+	ID       string
+	Choice   []Choice
+	Plural   bool
+	Optional bool
+	Nested   bool
 }
 
 // AttributeGroup definitions do not participate in ·validation· as such, but
@@ -116,34 +213,120 @@ type Choice struct {
 // for some uses of XML's parameter entity facility. Attribute group
 // definitions are provided primarily for reference from the XML
 // representation of schema components (see <complexType> and
-// <attributeGroup>).
+// <attributeGroup>). AnyAttribute reports whether the attribute group has the
+// attribute wildcard, and AnyAttributeNamespace holds its namespaces.
 // https://www.w3.org/TR/xmlschema-1/structures.html#Attribute_Group_Definition
 type AttributeGroup struct {
-	Doc        string
-	Name       string
-	Ref        string
-	Attributes []Attribute
+	Doc                   string
+	Docs                  []Documentation
+	Name                  string
+	Ref                   string
+	Attributes            []Attribute
+	AnyAttribute          bool
+	AnyAttributeNamespace NamespaceConstraint
+}
+
+// NamespaceConstraint is the namespace constraint of a wildcard, given by its
+// namespace attribute. The wildcard matches the Namespaces, the empty one
+// standing for no namespace (##local), or the namespaces other than them if
+// Not is true (##other). The zero value matches any namespace (##any).
+// https://www.w3.org/TR/xmlschema-1/#Wildcards
+type NamespaceConstraint struct {
+	Namespaces []string
+	Not        bool
+}
+
+// Unique identity-constraint definitions provide for uniqueness of the
+// combination of field values among the elements selected by the selector,
+// within the scope of the element holding the constraint. Type holds the type
+// of that element, and the generated validation of the type checks the
+// selected elements. The key constraints, marked by Key, also require each
+// selected element to have all the fields, and the keyref constraints, named
+// by Refer the key or unique constraint they refer to, require the
+// combinations of their fields to match the ones of the referred constraint.
+// https://www.w3.org/TR/xmlschema-1/#Identity-constraint_Definitions
+type Unique struct {
+	Name     string
+	Type     string
+	Selector string
+	Fields   []string
+	Key      bool
+	Refer    string
+}
+
+// Notation declarations reconcile the name of a notation with the public and
+// the system identifiers of the external format it stands for, such as an
+// image format, which the attributes of the NOTATION types reference by
+// name.
+// https://www.w3.org/TR/xmlschema-1/#cNotation_Declarations
+type Notation struct {
+	Doc    string
+	Docs   []Documentation
+	Name   string
+	Public string
+	System string
 }
 
 // Restriction are used to define acceptable values for XML elements or
-// attributes. Restriction on XML elements are called facets.
+// attributes. Restriction on XML elements are called facets. The facet model
+// is exported so applications can validate individual values against the
+// schema at runtime by Evaluate, without generating code.
 // https://www.w3.org/TR/xmlschema-1/structures.html#element-restriction
 type Restriction struct {
-	Doc                  string
-	Precision            int
-	Enum                 []string
-	Min, Max             float64
+	Doc       string
+	Precision int
+	// Enum holds the values of the enumeration facets.
+	Enum []string
+	// Min and Max hold the values of the minInclusive and maxInclusive
+	// facets, or of the minExclusive and maxExclusive facets if MinExclusive
+	// and MaxExclusive are set. HasMin and HasMax report whether they were
+	// declared, so zero bounds are honored. Min and Max are the nearest
+	// floats of the bounds.
+	Min, Max                   float64
+	HasMin, HasMax             bool
+	MinExclusive, MaxExclusive bool
+	// MinValue and MaxValue hold the values of the bounds typed by the base
+	// type, which keep the bounds of the integer and the decimal types
+	// exactly.
+	MinValue, MaxValue FacetValue
+	// MinLength and MaxLength hold the values of the minLength and maxLength
+	// facets, counted in characters.
 	MinLength, MaxLength int
-	Pattern              *regexp.Regexp
+	// Length holds the value of the length facet, counted in characters, in
+	// octets of the hexBinary values or in items of the lists. HasLength is
+	// set if the facet is declared, so the zero length is honored.
+	Length    int
+	HasLength bool
+	// Octets is set if the restriction derives from the hexBinary type,
+	// whose length facets are counted in octets.
+	Octets bool
+	// Pattern holds the compiled pattern facet.
+	Pattern *regexp.Regexp
+	// WhiteSpace holds the value of the whiteSpace facet, or the value fixed
+	// by the built-in base type.
+	WhiteSpace string
+	// TotalDigits and FractionDigits hold the values of the totalDigits and
+	// fractionDigits facets, counted in the canonical form of the value.
+	TotalDigits, FractionDigits int
+	// base holds the built-in type the restriction derives from, whose
+	// lexical space the values of the bound facets are parsed in.
+	base string
 }
 
+// IsEmpty returns true if the restriction doesn't declare any facet.
 func (r Restriction) IsEmpty() bool {
 	return r.MinLength == 0 &&
 		r.MaxLength == 0 &&
+		!r.HasLength &&
 		r.Pattern == nil &&
 		len(r.Enum) == 0 &&
+		!r.HasMin &&
+		!r.HasMax &&
 		r.Min == 0.0 &&
 		r.Max == 0.0 &&
-		r.Precision == 0
+		r.Precision == 0 &&
+		r.TotalDigits == 0 &&
+		r.FractionDigits == 0 &&
+		r.WhiteSpace == ""
 	// Include checks for other fields as necessary
 }
@@ -0,0 +1,201 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the version of xgen recorded in the provenance of the generated
+// code.
+const Version = "0.1.0"
+
+// Provenance records the origin of the generated code, so it can be traced
+// back to the exact schema versions during audits. The provenance of all
+// generated files in the output directory is kept in provenance.json.
+type Provenance struct {
+	Generator string             `json:"generator"`
+	Version   string             `json:"version"`
+	Options   map[string]string  `json:"options"`
+	Timestamp string             `json:"timestamp,omitempty"`
+	Sources   []SourceProvenance `json:"sources"`
+}
+
+// SourceProvenance records the XML schema definition file with its SHA-256
+// hash, and the files generated from it.
+type SourceProvenance struct {
+	File    string   `json:"file"`
+	SHA256  string   `json:"sha256"`
+	Outputs []string `json:"outputs"`
+}
+
+// newProvenance creates the provenance of the code generated from the XML
+// schema definition file of the options.
+func (opt *Options) newProvenance() (*Provenance, error) {
+	data, err := ioutil.ReadFile(opt.FilePath)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	source := strings.TrimPrefix(filepath.ToSlash(strings.TrimPrefix(opt.FilePath, opt.InputDir)), "/")
+	if source == "" {
+		source = filepath.Base(opt.FilePath)
+	}
+	provenance := &Provenance{
+		Generator: "xgen",
+		Version:   Version,
+		Options:   opt.getGenerationOptions(),
+		Sources:   []SourceProvenance{{File: source, SHA256: hex.EncodeToString(sum[:]), Outputs: []string{}}},
+	}
+	if opt.ProvenanceTimestamp {
+		provenance.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	return provenance, nil
+}
+
+// getGenerationOptions returns the options affecting the generated code,
+// keyed by the name of the flag of the xgen command.
+func (opt *Options) getGenerationOptions() map[string]string {
+	return map[string]string{
+		"lang":                 opt.Lang,
+		"package":              opt.Package,
+		"validation":           opt.Validation,
+		"validation-max-depth": strconv.Itoa(opt.ValidationMaxDepth),
+		"normalize":            strconv.FormatBool(opt.Normalize),
+		"test-vectors":         strconv.FormatBool(opt.TestVectors),
+		"root-wrappers":        strconv.FormatBool(opt.RootWrappers),
+		"prune-unused":         strconv.FormatBool(opt.PruneUnused),
+		"type-aliases":         strconv.FormatBool(opt.TypeAliases),
+		"versioned-packages":   opt.VersionedPackages,
+		"comment-style":        opt.CommentStyle,
+		"comment-width":        strconv.Itoa(opt.CommentWidth),
+		"any-type-fallback":    opt.AnyTypeFallback,
+		"mixins":               strconv.FormatBool(opt.Mixins),
+		"boolean-form":         opt.BooleanForm,
+		"decimal-form":         opt.DecimalForm,
+		"namespace-prefixes":   formatNamespacePrefixes(opt.NamespacePrefixes),
+		"constants":            strconv.FormatBool(opt.Constants),
+		"accessors":            strconv.FormatBool(opt.Accessors),
+		"patch-types":          strconv.FormatBool(opt.PatchTypes),
+		"visitor":              strconv.FormatBool(opt.Visitor),
+		"equality":             strconv.FormatBool(opt.Equality),
+		"pretty-xml":           strconv.FormatBool(opt.PrettyXML),
+		"symbol-map":           strconv.FormatBool(opt.SymbolMap),
+		"size-report":          strconv.FormatBool(opt.SizeReport),
+		"validation-tracing":   strconv.FormatBool(opt.ValidationTracing),
+		"rename-case":          opt.RenameCase,
+		"plural-names":         strconv.FormatBool(opt.PluralNames),
+		"plural-overrides":     formatPluralOverrides(opt.PluralOverrides),
+		"optional-overrides":   formatOptionalOverrides(opt.OptionalOverrides),
+		"deprecations":         formatDeprecations(opt.Deprecations),
+		"customizations":       formatCustomizations(opt.Customizations),
+		"external-types":       formatExternalTypes(opt.ExternalTypes),
+		"charset":              opt.Charset,
+		"entities":             formatEntities(opt.Entities),
+		"non-strict":           strconv.FormatBool(opt.NonStrict),
+		"doc-lang":             opt.DocLang,
+		"pattern-fallback":     opt.PatternFallback,
+		"assert-fallback":      opt.AssertFallback,
+		"choice-enums":         strconv.FormatBool(opt.ChoiceEnums),
+		"choice-repr":          opt.ChoiceRepr,
+		"choice-tag":           opt.ChoiceTag,
+		"json-value":           strconv.FormatBool(opt.JSONValue),
+		"rust-decimal":         strconv.FormatBool(opt.RustDecimal),
+		"rust-chrono":          strconv.FormatBool(opt.RustChrono),
+		"rust-error-type":      opt.RustErrorType,
+		"rust-uses":            strings.Join(opt.RustUses, ","),
+		"rust-derives":         formatRustDerives(opt.RustDerives),
+		"rust-modules":         strconv.FormatBool(opt.RustModules),
+		"validate-tags":        strconv.FormatBool(opt.ValidateTags),
+		"sql-methods":          strconv.FormatBool(opt.SQLMethods),
+		"java-project":         opt.JavaProject,
+		"project-version":      opt.ProjectVersion,
+		"npm-package":          opt.NPMPackage,
+		"json-schemas":         strconv.FormatBool(opt.JSONSchemas),
+	}
+}
+
+// fileHeader returns the header of the generated files, which includes the
+// provenance of the generated code if enabled.
+func (gen *CodeGenerator) fileHeader() string {
+	if gen.Provenance == nil {
+		return copyright
+	}
+	var options []string
+	for key, value := range gen.Provenance.Options {
+		options = append(options, key+"="+value)
+	}
+	sort.Strings(options)
+	header := fmt.Sprintf("%s\n//\n// Code generated by %s %s. DO NOT EDIT.\n", copyright, gen.Provenance.Generator, gen.Provenance.Version)
+	for _, source := range gen.Provenance.Sources {
+		header += fmt.Sprintf("// Source: %s (sha256: %s)\n", source.File, source.SHA256)
+	}
+	header += fmt.Sprintf("// Options: %s", strings.Join(options, ", "))
+	if gen.Provenance.Timestamp != "" {
+		header += fmt.Sprintf("\n// Generated at: %s", gen.Provenance.Timestamp)
+	}
+	return header
+}
+
+// writeProvenance merges the provenance of the generated code into the
+// provenance.json in the output directory, the sources generated again are
+// replaced.
+func (opt *Options) writeProvenance(provenance *Provenance) error {
+	for i, output := range provenance.Sources[0].Outputs {
+		if rel, err := filepath.Rel(opt.OutputDir, output); err == nil && opt.OutputDir != "" {
+			output = rel
+		}
+		provenance.Sources[0].Outputs[i] = strings.TrimPrefix(filepath.ToSlash(output), "/")
+	}
+	sort.Strings(provenance.Sources[0].Outputs)
+	name := filepath.Join(opt.OutputDir, "provenance.json")
+	var data []byte
+	if opt.Artifacts != nil {
+		data = opt.Artifacts[name]
+	} else if existing, err := ioutil.ReadFile(name); err == nil {
+		data = existing
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	var merged Provenance
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &merged); err != nil {
+			return err
+		}
+	}
+	sources := provenance.Sources
+	for _, source := range merged.Sources {
+		if source.File != provenance.Sources[0].File {
+			sources = append(sources, source)
+		}
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].File < sources[j].File })
+	merged = *provenance
+	merged.Sources = sources
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if opt.Artifacts != nil {
+		opt.Artifacts[name] = data
+		return nil
+	}
+	return ioutil.WriteFile(name, data, 0644)
+}
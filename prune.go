@@ -0,0 +1,189 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+// isPruned returns true if the declaration is omitted from the generated
+// code by the prune unused option, since it isn't reachable from any root
+// element.
+func (gen *CodeGenerator) isPruned(ele interface{}) bool {
+	if !gen.PruneUnused {
+		return false
+	}
+	if gen.reachable == nil {
+		gen.reachable = getReachableDeclarations(gen.ProtoTree)
+	}
+	return !gen.reachable[ele]
+}
+
+// getReachableDeclarations builds the reference graph of the declarations
+// by their names, and returns the declarations reachable from the top-level
+// elements. Every declaration is reachable if the schema doesn't declare any
+// top-level element, as in the schemas of shared type libraries.
+func getReachableDeclarations(XSDSchema []interface{}) map[interface{}]bool {
+	reachable := map[interface{}]bool{}
+	declarations, queue := getDeclarationsByName(XSDSchema)
+	if len(queue) == 0 {
+		for _, ele := range XSDSchema {
+			reachable[ele] = true
+		}
+		return reachable
+	}
+	visited := walkReferences(declarations, queue, reachable)
+	// The notations are referenced by the enumeration values of the
+	// NOTATION types.
+	notations := map[string]bool{}
+	for _, ele := range XSDSchema {
+		if reachable[ele] {
+			for _, value := range getEnumValues(ele) {
+				notations[trimNSPrefix(value)] = true
+			}
+		}
+	}
+	for _, ele := range XSDSchema {
+		if v, ok := ele.(*Unique); ok && visited[v.Type] {
+			reachable[ele] = true
+		}
+		if v, ok := ele.(*Notation); ok && notations[v.Name] {
+			reachable[ele] = true
+		}
+	}
+	return reachable
+}
+
+// getDeclarationsByName returns the declarations of the schema by their
+// names, along with the names of the top-level elements.
+func getDeclarationsByName(XSDSchema []interface{}) (map[string][]interface{}, []string) {
+	declarations := map[string][]interface{}{}
+	var elements []string
+	for _, ele := range XSDSchema {
+		var name string
+		switch v := ele.(type) {
+		case *SimpleType:
+			name = v.Name
+		case *ComplexType:
+			name = v.Name
+		case *Group:
+			name = v.Name
+		case *AttributeGroup:
+			name = v.Name
+		case *Attribute:
+			name = v.Name
+		case *Element:
+			name = v.Name
+			elements = append(elements, v.Name)
+		case *SubstitutionGroup:
+			name = v.Name
+		case *DerivedTypes:
+			name = v.Name
+		}
+		if name != "" {
+			declarations[name] = append(declarations[name], ele)
+		}
+	}
+	return declarations, elements
+}
+
+// walkReferences marks the declarations reachable from the declarations of
+// the given names by their references, and returns the names visited.
+func walkReferences(declarations map[string][]interface{}, queue []string, reachable map[interface{}]bool) map[string]bool {
+	visited := map[string]bool{}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if name == "" || visited[name] {
+			continue
+		}
+		visited[name] = true
+		for _, ele := range declarations[name] {
+			reachable[ele] = true
+			queue = append(queue, getReferencedNames(ele)...)
+		}
+	}
+	return visited
+}
+
+// getReferencedNames returns the names of the declarations referenced by the
+// declaration.
+func getReferencedNames(ele interface{}) (names []string) {
+	switch v := ele.(type) {
+	case *SimpleType:
+		names = append(names, trimNSPrefix(v.Base), v.ItemType)
+		for memberType := range v.MemberTypes {
+			names = append(names, trimNSPrefix(memberType))
+		}
+	case *ComplexType:
+		names = append(names, trimNSPrefix(v.Base))
+		for _, element := range v.Elements {
+			names = append(names, trimNSPrefix(element.Type))
+		}
+		for _, attribute := range v.Attributes {
+			names = append(names, trimNSPrefix(attribute.Type))
+		}
+		for _, group := range v.Groups {
+			names = append(names, trimNSPrefix(group.Ref))
+		}
+		for _, attrGroup := range v.AttributeGroup {
+			names = append(names, trimNSPrefix(attrGroup.Ref))
+		}
+	case *Group:
+		for _, element := range v.Elements {
+			names = append(names, trimNSPrefix(element.Type))
+		}
+		for _, group := range v.Groups {
+			names = append(names, trimNSPrefix(group.Ref))
+		}
+	case *AttributeGroup:
+		names = append(names, trimNSPrefix(v.Ref))
+		for _, attribute := range v.Attributes {
+			names = append(names, trimNSPrefix(attribute.Type))
+		}
+	case *Element:
+		names = append(names, trimNSPrefix(v.Type))
+	case *Attribute:
+		names = append(names, trimNSPrefix(v.Type))
+	case *SubstitutionGroup:
+		for _, member := range v.Members {
+			names = append(names, trimNSPrefix(member.Type))
+		}
+	case *DerivedTypes:
+		for _, member := range v.Members {
+			names = append(names, trimNSPrefix(member.Type))
+		}
+	}
+	return
+}
+
+// getEnumValues returns the enumeration values of the declaration and the
+// elements and attributes it declares.
+func getEnumValues(ele interface{}) (values []string) {
+	switch v := ele.(type) {
+	case *SimpleType:
+		values = v.Restriction.Enum
+	case *ComplexType:
+		for _, element := range v.Elements {
+			values = append(values, element.Restriction.Enum...)
+		}
+		for _, attribute := range v.Attributes {
+			values = append(values, attribute.Restriction.Enum...)
+		}
+	case *Group:
+		for _, element := range v.Elements {
+			values = append(values, element.Restriction.Enum...)
+		}
+	case *AttributeGroup:
+		for _, attribute := range v.Attributes {
+			values = append(values, attribute.Restriction.Enum...)
+		}
+	case *Element:
+		values = v.Restriction.Enum
+	case *Attribute:
+		values = v.Restriction.Enum
+	}
+	return
+}
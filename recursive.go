@@ -0,0 +1,158 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+)
+
+// rustDirectFieldRegexp matches the fields of the Rust structs which hold
+// the value of their type inline, that is the fields which aren't repeated,
+// and captures the type of the field.
+var rustDirectFieldRegexp = regexp.MustCompile(`(?m)^(\tpub (?:r#)?\w+: )(?:Option<(\w+)>|(\w+)),$`)
+
+// resolveRustRecursiveFields finds the recursive fields of the Rust structs
+// and enums, which hold a value of a type holding a value of their own type
+// inline, so the types would have infinite size. The types are generated
+// once without output to collect the types of their fields, and the fields
+// between the types of a cycle are boxed by the generation.
+func (gen *CodeGenerator) resolveRustRecursiveFields() {
+	dry := *gen
+	dry.Field, dry.ValidationCode, dry.mixinCode = "", "", ""
+	dry.StructAST, dry.anonymousTypes, dry.choiceEnums = map[string]string{}, nil, nil
+	dry.visitorTypes, dry.symbols, dry.rustFieldTypes, dry.rustBoxedFields = nil, nil, map[string][]string{}, nil
+	fieldNameCount = make(map[string]int)
+	for _, ele := range gen.ProtoTree {
+		if ele == nil || gen.isPruned(ele) {
+			continue
+		}
+		callFuncByName(&dry, "Rust"+reflect.TypeOf(ele).String()[6:], []reflect.Value{reflect.ValueOf(ele)})
+	}
+	fieldNameCount = make(map[string]int)
+	gen.rustBoxedFields = nil
+	components := getStronglyConnectedComponents(dry.rustFieldTypes)
+	for name, fieldTypes := range dry.rustFieldTypes {
+		for _, fieldType := range fieldTypes {
+			component, ok := components[name]
+			if fieldComponent, fieldOK := components[fieldType]; ok && fieldOK && component == fieldComponent {
+				if gen.rustBoxedFields == nil {
+					gen.rustBoxedFields = map[string]map[string]bool{}
+				}
+				if gen.rustBoxedFields[name] == nil {
+					gen.rustBoxedFields[name] = map[string]bool{}
+				}
+				gen.rustBoxedFields[name][fieldType] = true
+			}
+		}
+	}
+}
+
+// addRustFieldType records the type of the field held inline by the Rust
+// struct or enum by given name, while the recursive fields are resolved.
+func (gen *CodeGenerator) addRustFieldType(name, fieldType string) {
+	if gen.rustFieldTypes != nil {
+		gen.rustFieldTypes[name] = append(gen.rustFieldTypes[name], fieldType)
+	}
+}
+
+// isRustBoxedField returns true if the field of the Rust struct or enum by
+// given name holding the value of the field type is recursive.
+func (gen *CodeGenerator) isRustBoxedField(name, fieldType string) bool {
+	return gen.rustBoxedFields[name][fieldType]
+}
+
+// genRustBoxedFields records the types of the fields of the struct by given
+// name and field content while the recursive fields are resolved, and wraps
+// the types of the recursive fields by Box.
+func (gen *CodeGenerator) genRustBoxedFields(name, fieldContent string) string {
+	for _, match := range rustDirectFieldRegexp.FindAllStringSubmatch(fieldContent, -1) {
+		gen.addRustFieldType(name, match[2]+match[3])
+	}
+	if gen.rustBoxedFields[name] == nil {
+		return fieldContent
+	}
+	return rustDirectFieldRegexp.ReplaceAllStringFunc(fieldContent, func(field string) string {
+		match := rustDirectFieldRegexp.FindStringSubmatch(field)
+		switch {
+		case match[2] != "" && gen.isRustBoxedField(name, match[2]):
+			return match[1] + "Option<Box<" + match[2] + ">>,"
+		case match[3] != "" && gen.isRustBoxedField(name, match[3]):
+			return match[1] + "Box<" + match[3] + ">,"
+		}
+		return field
+	})
+}
+
+// getStronglyConnectedComponents returns the index of the strongly connected
+// component of the graph by node, with the Tarjan's algorithm. The nodes
+// without edges to themselves which aren't on a cycle are left out, so the
+// nodes of the same component are on a cycle.
+func getStronglyConnectedComponents(edges map[string][]string) map[string]int {
+	var nodes []string
+	for node := range edges {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	index, lowLink, onStack := map[string]int{}, map[string]int{}, map[string]bool{}
+	components := map[string]int{}
+	var stack []string
+	var count int
+	var connect func(node string)
+	connect = func(node string) {
+		index[node], lowLink[node] = len(index), len(index)
+		stack, onStack[node] = append(stack, node), true
+		for _, next := range edges[node] {
+			if _, ok := index[next]; !ok {
+				connect(next)
+				if lowLink[next] < lowLink[node] {
+					lowLink[node] = lowLink[next]
+				}
+			} else if onStack[next] && index[next] < lowLink[node] {
+				lowLink[node] = index[next]
+			}
+		}
+		if lowLink[node] != index[node] {
+			return
+		}
+		var component []string
+		for {
+			last := stack[len(stack)-1]
+			stack, onStack[last] = stack[:len(stack)-1], false
+			component = append(component, last)
+			if last == node {
+				break
+			}
+		}
+		if len(component) == 1 && !hasEdge(edges, node, node) {
+			return
+		}
+		for _, member := range component {
+			components[member] = count
+		}
+		count++
+	}
+	for _, node := range nodes {
+		if _, ok := index[node]; !ok {
+			connect(node)
+		}
+	}
+	return components
+}
+
+// hasEdge returns true if the graph has the edge between the nodes.
+func hasEdge(edges map[string][]string, from, to string) bool {
+	for _, next := range edges[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteSchemaClient fetches the remote schemas, the schemas not fetched in
+// time fail the parse instead of blocking it.
+var remoteSchemaClient = &http.Client{Timeout: 30 * time.Second}
+
+// resolvesRemoteSchemas returns true if the schemas at the URLs are resolved
+// by the options, from the remote schema directory or failing offline,
+// instead of being skipped.
+func (opt *Options) resolvesRemoteSchemas() bool {
+	return opt.RemoteSchemaDir != "" || opt.Offline
+}
+
+// resolveSchemaLocation returns the path of the schema file at the schema
+// location of the import or the include of the schema of the options. The
+// locations are relative to the directory of the schema, or to its URL if
+// it's a remote schema, and the schemas at the URLs are fetched into the
+// remote schema directory unless they are cached there already. The path is
+// empty if the remote schemas aren't resolved by the options, or the options
+// parse nothing but the source.
+func (opt *Options) resolveSchemaLocation(location string) (string, error) {
+	if opt.sourceOnly {
+		return "", nil
+	}
+	base := opt.getRemoteSchemaURL(opt.FilePath)
+	if location == "" || !isValidURL(location) && base == nil {
+		return filepath.Join(opt.FileDir, location), nil
+	}
+	if !opt.resolvesRemoteSchemas() {
+		return "", nil
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", err
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	return opt.fetchRemoteSchema(ref)
+}
+
+// getRemoteSchemaPath returns the path of the schema at the URL in the
+// remote schema directory, under the directories of the scheme and the host
+// of the URL, so the URL of the cached schema is known by its path. The URLs
+// whose path would fall outside the directory of their host, such as the
+// hosts of the .. name and the paths of the backslashes on Windows, are
+// rejected.
+func (opt *Options) getRemoteSchemaPath(u *url.URL) (string, error) {
+	name := strings.TrimPrefix(path.Clean("/"+u.Path), "/")
+	if name == "" || strings.HasSuffix(u.Path, "/") {
+		name = path.Join(name, "index.xsd")
+	}
+	dir := filepath.Join(opt.RemoteSchemaDir, u.Scheme, u.Host)
+	file := filepath.Join(dir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dir, file)
+	if u.Host == "" || u.Host == "." || u.Host == ".." || strings.ContainsAny(u.Host, `/\`) ||
+		err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the remote schema %s can't be cached in %s", u, opt.RemoteSchemaDir)
+	}
+	return file, nil
+}
+
+// getRemoteSchemaURL returns the URL of the remote schema cached at the path
+// in the remote schema directory, or nil if the path isn't a remote schema.
+func (opt *Options) getRemoteSchemaURL(file string) *url.URL {
+	if opt.RemoteSchemaDir == "" {
+		return nil
+	}
+	rel, err := filepath.Rel(opt.RemoteSchemaDir, file)
+	if err != nil {
+		return nil
+	}
+	parts := strings.SplitN(filepath.ToSlash(rel), "/", 3)
+	if len(parts) != 3 || parts[0] != "http" && parts[0] != "https" {
+		return nil
+	}
+	return &url.URL{Scheme: parts[0], Host: parts[1], Path: "/" + parts[2]}
+}
+
+// fetchRemoteSchema returns the path of the schema at the URL in the remote
+// schema directory, fetching the schema unless it's cached. The offline
+// options fail on the schemas which aren't cached instead of fetching them.
+func (opt *Options) fetchRemoteSchema(u *url.URL) (string, error) {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme of the remote schema %s", u)
+	}
+	if opt.RemoteSchemaDir == "" {
+		return "", fmt.Errorf("the remote schema %s can't be fetched offline", u)
+	}
+	file, err := opt.getRemoteSchemaPath(u)
+	if err != nil {
+		return "", err
+	}
+	if _, err = os.Stat(file); err == nil {
+		return file, nil
+	}
+	if opt.Offline {
+		return "", fmt.Errorf("the remote schema %s isn't cached in %s and can't be fetched offline", u, opt.RemoteSchemaDir)
+	}
+	data, err := fetchSchema(u.String(), opt.MaxFileSize)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", err
+	}
+	// The schema is written under a temporary name first, so the parses
+	// sharing the directory never read a partial schema.
+	tmp, err := ioutil.TempFile(filepath.Dir(file), ".fetch-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+	return file, os.Rename(tmp.Name(), file)
+}
+
+// fetchSchema returns the content of the schema at the URL, up to the
+// maximum size in bytes unless it's 0.
+func fetchSchema(URL string, maxSize int64) ([]byte, error) {
+	resp, err := remoteSchemaClient.Get(URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the remote schema %s: %s", URL, resp.Status)
+	}
+	if maxSize <= 0 {
+		return ioutil.ReadAll(resp.Body)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err == nil && int64(len(data)) > maxSize {
+		err = &LimitError{File: URL, Limit: LimitFileSize, Max: maxSize}
+	}
+	return data, err
+}
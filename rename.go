@@ -0,0 +1,73 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Rename cases of the code generator, which transform the names of the
+// elements and attributes the Rust fields are renamed to by serde. With the
+// schema case the names are kept as declared in the schema, such as the
+// PascalCase names of the ISO 20022 JSON mapping. With the camel, the pascal
+// and the snake case the names are converted to camelCase, PascalCase and
+// snake_case respectively, for the JSON mappings using them.
+const (
+	RenameCaseSchema = ""
+	RenameCaseCamel  = "camel"
+	RenameCasePascal = "pascal"
+	RenameCaseSnake  = "snake"
+)
+
+// checkRenameCase returns an error if the rename case isn't supported.
+func checkRenameCase(renameCase string) error {
+	switch renameCase {
+	case RenameCaseSchema, RenameCaseCamel, RenameCasePascal, RenameCaseSnake:
+		return nil
+	}
+	return fmt.Errorf("unsupport rename case %s, expected %s, %s or %s", renameCase, RenameCaseCamel, RenameCasePascal, RenameCaseSnake)
+}
+
+// applyRenameCase converts the name to the rename case. The words of the name
+// are separated by the hyphens, the underscores and the case changes, and the
+// leading acronym is lowercased as a whole in camel case, such as BICFI to
+// bicfi and IBANNumber to ibanNumber.
+func applyRenameCase(name, renameCase string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' || r == '.' })
+	switch renameCase {
+	case RenameCaseSnake:
+		return ToSnakeCase(strings.Join(words, "_"))
+	case RenameCasePascal:
+		for i, word := range words {
+			words[i] = MakeFirstUpperCase(word)
+		}
+		return strings.Join(words, "")
+	case RenameCaseCamel:
+		for i, word := range words {
+			if i > 0 {
+				words[i] = MakeFirstUpperCase(word)
+				continue
+			}
+			runes := []rune(word)
+			upper := 0
+			for upper < len(runes) && unicode.IsUpper(runes[upper]) {
+				upper++
+			}
+			// The last capital of the leading acronym starts the next word.
+			if upper > 1 && upper < len(runes) {
+				upper--
+			}
+			words[i] = strings.ToLower(string(runes[:upper])) + string(runes[upper:])
+		}
+		return strings.Join(words, "")
+	}
+	return name
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isRequiredAttribute returns true if the attribute is required without the
+// default or the fixed value, so its absence is an error.
+func isRequiredAttribute(attribute Attribute) bool {
+	return !attribute.Optional && attribute.Default == "" && attribute.Fixed == ""
+}
+
+// isEmptyForbidden returns true if the facets of the restriction forbid the
+// empty value of the string types, or the empty list of the list types, so
+// the empty value of the required attribute or element tells it's absent.
+func isEmptyForbidden(restriction Restriction, list bool) bool {
+	if list {
+		return restriction.MinLength > 0 || restriction.HasLength && restriction.Length > 0
+	}
+	return restriction.Evaluate("") != nil
+}
+
+// genGoRequiredValidation generate validation code of the required attribute
+// for Go code. The absent attribute of the string and list types is decoded
+// as the empty value, which is reported as the missing attribute if the
+// facets of the type forbid it, and is a valid value otherwise. The absent
+// attribute of the other types is decoded as the zero value, which the
+// facets are checked on.
+func (gen *CodeGenerator) genGoRequiredValidation(fieldName, typeName string, attribute Attribute) string {
+	if gen.Validation == ValidationNone || !isRequiredAttribute(attribute) {
+		return ""
+	}
+	var condition string
+	switch fieldType := gen.genGoFieldType(typeName); {
+	case attribute.Plural || strings.HasPrefix(fieldType, "[]"):
+		if !isEmptyForbidden(attribute.Restriction, true) {
+			return ""
+		}
+		condition = fmt.Sprintf("len(v.%s) == 0", fieldName)
+	case fieldType == "string":
+		if !isEmptyForbidden(attribute.Restriction, false) {
+			return ""
+		}
+		condition = fmt.Sprintf("v.%s == \"\"", fieldName)
+	default:
+		return ""
+	}
+	return fmt.Sprintf("if %s {\nreturn errors.New(%q)\n}\n", condition, "required attribute "+attribute.Name+" is missing")
+}
+
+// genRustRequiredValidation generate validation code of the required
+// attribute for Rust code. The absent attribute fails the deserialization,
+// and the empty value of the string and list types built by the Default impl
+// is reported as the missing attribute if the facets of the type forbid it.
+func (gen *CodeGenerator) genRustRequiredValidation(typeName string, attribute Attribute) string {
+	if gen.Validation == ValidationNone || !isRequiredAttribute(attribute) {
+		return ""
+	}
+	switch fieldType := gen.genRustFieldDeclType(typeName, attribute.Plural, false); {
+	case strings.HasPrefix(fieldType, "Vec<"):
+		if !isEmptyForbidden(attribute.Restriction, true) {
+			return ""
+		}
+	case fieldType != "String" || !isEmptyForbidden(attribute.Restriction, false):
+		return ""
+	}
+	indent, receiver := "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	return fmt.Sprintf("%sif %s.%s.is_empty() {\n%s\treturn Err(ValidationError::new(1011, \"required attribute %s is missing\".to_string()));\n%s}\n", indent, receiver, genRustFieldName(attribute.Name), indent, escapeRustString(attribute.Name), indent)
+}
+
+// rustLenientAttr is the serde attribute of the fields of the required
+// elements in lenient mode, the absent element is deserialized as the
+// default value instead of failing the deserialization.
+const rustLenientAttr = "\t#[serde(default)]\n"
+
+// genRustLenientAttr generate the serde attribute of the field of the
+// element for Rust code, which is empty unless the element is required in
+// lenient mode.
+func (gen *CodeGenerator) genRustLenientAttr(element Element) string {
+	if !gen.Lenient || element.Optional {
+		return ""
+	}
+	return rustLenientAttr
+}
+
+// genGoRequiredElementValidation generate validation code of the required
+// element for Go code in lenient mode. The absent element is decoded as the
+// empty list of the repeated elements and the nil pointer of the complex
+// types, which fails the validation, and as the empty value of the string
+// types, which fails the validation if the facets of the type forbid it. The
+// absent element of the other types is decoded as the zero value, which the
+// facets are checked on.
+func (gen *CodeGenerator) genGoRequiredElementValidation(fieldName, typeName string, element Element) string {
+	if !gen.Lenient || gen.Validation == ValidationNone || element.Optional {
+		return ""
+	}
+	var condition string
+	switch fieldType := gen.genGoFieldType(typeName); {
+	case element.Plural:
+		condition = fmt.Sprintf("len(v.%s) == 0", fieldName)
+	case strings.HasPrefix(fieldType, "[]"):
+		if !isEmptyForbidden(element.Restriction, true) {
+			return ""
+		}
+		condition = fmt.Sprintf("len(v.%s) == 0", fieldName)
+	case fieldType == "string":
+		if !isEmptyForbidden(element.Restriction, false) {
+			return ""
+		}
+		condition = fmt.Sprintf("v.%s == \"\"", fieldName)
+	case strings.HasPrefix(fieldType, "*"):
+		condition = fmt.Sprintf("v.%s == nil", fieldName)
+	default:
+		return ""
+	}
+	return fmt.Sprintf("if %s {\nreturn errors.New(%q)\n}\n", condition, "required element "+element.Name+" is missing")
+}
+
+// genRustRequiredElementValidation generate validation code of the required
+// element for Rust code in lenient mode. The absent element is deserialized
+// as the default value, the empty list of the repeated elements fails the
+// validation, and the empty value of the string types fails it if the facets
+// of the type forbid it.
+func (gen *CodeGenerator) genRustRequiredElementValidation(name, fieldType string, element Element) string {
+	if !gen.Lenient || gen.Validation == ValidationNone || element.Optional {
+		return ""
+	}
+	switch declType := gen.genRustFieldDeclType(fieldType, element.Plural, false); {
+	case element.Plural:
+	case strings.HasPrefix(declType, "Vec<"):
+		if !isEmptyForbidden(element.Restriction, true) {
+			return ""
+		}
+	case declType != "String" || !isEmptyForbidden(element.Restriction, false):
+		return ""
+	}
+	indent, receiver := "\t\t", "self"
+	if gen.Validation == ValidationStandalone {
+		indent, receiver = "\t", "v"
+	}
+	return fmt.Sprintf("%sif %s.%s.is_empty() {\n%s\treturn Err(ValidationError::new(1012, \"required element %s is missing\".to_string()));\n%s}\n", indent, receiver, genRustFieldName(name), indent, escapeRustString(element.Name), indent)
+}
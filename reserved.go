@@ -0,0 +1,209 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reservedIdentifiers holds the identifiers by language which the generated
+// types must not use, since they collide with the prelude and the built-in
+// types of the language, or with the identifiers of the generated code.
+var reservedIdentifiers = map[string]map[string]bool{
+	"Go": {
+		"ErrValidationDepth": true, "XSDBoolean": true, "XSDDecimal": true,
+	},
+	"TypeScript": {
+		"Array": true, "Boolean": true, "Date": true, "Error": true, "Function": true,
+		"JSON": true, "Map": true, "Math": true, "Number": true, "Object": true,
+		"Promise": true, "Record": true, "RegExp": true, "Set": true, "String": true,
+		"Symbol": true, "Uint8Array": true,
+	},
+	"C": {
+		"FILE": true, "NULL": true,
+	},
+	"Java": {
+		"Boolean": true, "Byte": true, "Character": true, "Class": true, "Double": true,
+		"Error": true, "Exception": true, "Float": true, "Integer": true, "List": true,
+		"Long": true, "Math": true, "Number": true, "Object": true, "Override": true,
+		"QName": true, "Short": true, "String": true, "System": true, "Thread": true,
+		"Void": true, "XmlAccessType": true, "XmlAccessorType": true, "XmlAttribute": true,
+		"XmlElement": true, "XmlRootElement": true,
+	},
+	"Rust": {
+		"Box": true, "Clone": true, "Debug": true, "Default": true, "Deserialize": true,
+		"Err": true, "From": true, "Into": true, "None": true, "Ok": true,
+		"Option": true, "PartialEq": true, "Regex": true, "Result": true, "Self": true,
+		"Serialize": true, "Some": true, "String": true, "ToString": true,
+		"ValidationError": true, "Vec": true, "XsdBoolean": true, "XsdDecimal": true,
+	},
+}
+
+// getTypeIdentifier returns the identifier generated for the type by given
+// name in the language.
+func getTypeIdentifier(lang, name string) string {
+	switch lang {
+	case "Go":
+		return genGoFieldName(name, false)
+	case "TypeScript":
+		return genTypeScriptFieldName(name, false)
+	case "C":
+		return genCFieldName(name, false)
+	case "Java":
+		return genJavaFieldName(name, false)
+	case "Rust":
+		return genRustStructName(name, false)
+	}
+	return name
+}
+
+// isBuiltInTypeByLang returns true if the type by given name is a built-in
+// type of the language.
+func isBuiltInTypeByLang(lang, name string) bool {
+	switch lang {
+	case "Go":
+		return goBuildinType[name]
+	case "TypeScript":
+		return typeScriptBuildInType[name]
+	case "C":
+		return cBuildInType[name]
+	case "Java":
+		return javaBuildInType[name]
+	case "Rust":
+		return rustBuildinType[name]
+	}
+	return false
+}
+
+// getDeclarationName returns the name of the type, group or attribute group
+// declaration, or an empty string for the other declarations.
+func getDeclarationName(ele interface{}) string {
+	switch v := ele.(type) {
+	case *SimpleType:
+		return v.Name
+	case *ComplexType:
+		return v.Name
+	case *Group:
+		return v.Name
+	case *AttributeGroup:
+		return v.Name
+	}
+	return ""
+}
+
+// renameReservedTypes renames the types declared in the schema, whose
+// identifier collides with the reserved identifiers of the language, by
+// appending "Type" to the name, and a number if the name is declared too.
+// The references to the types are renamed along, except the ones spelled as
+// a built-in type of the language, which can't be told apart from the
+// built-in type. A warning lists the renames applied.
+func (opt *Options) renameReservedTypes() {
+	reserved := reservedIdentifiers[opt.Lang]
+	declared := map[string]bool{}
+	for _, ele := range opt.ProtoTree {
+		if name := getDeclarationName(ele); name != "" {
+			declared[name] = true
+		}
+	}
+	renames := map[string]string{}
+	var report []string
+	for _, ele := range opt.ProtoTree {
+		name := getDeclarationName(ele)
+		if _, ok := renames[name]; ok || name == "" || !reserved[getTypeIdentifier(opt.Lang, name)] {
+			continue
+		}
+		renamed := name + "Type"
+		for i := 2; declared[renamed]; i++ {
+			renamed = fmt.Sprintf("%sType%d", name, i)
+		}
+		declared[renamed], renames[name] = true, renamed
+		report = append(report, fmt.Sprintf("%s to %s", name, renamed))
+	}
+	if len(renames) == 0 {
+		return
+	}
+	declaration := func(name *string) {
+		if renamed, ok := renames[*name]; ok {
+			*name = renamed
+		}
+	}
+	rename := func(name *string) {
+		if !isBuiltInTypeByLang(opt.Lang, *name) && !opt.builtInTypes[*name] {
+			declaration(name)
+		}
+	}
+	elements := func(elements []Element) {
+		for i := range elements {
+			rename(&elements[i].Type)
+		}
+	}
+	attributes := func(attributes []Attribute) {
+		for i := range attributes {
+			rename(&attributes[i].Type)
+		}
+	}
+	var groups func(refs []Group)
+	groups = func(refs []Group) {
+		for i := range refs {
+			rename(&refs[i].Ref)
+			elements(refs[i].Elements)
+			groups(refs[i].Groups)
+		}
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			declaration(&v.Name)
+			rename(&v.Base)
+			rename(&v.ItemType)
+			memberTypes := make(map[string]string, len(v.MemberTypes))
+			for memberName, memberType := range v.MemberTypes {
+				rename(&memberName)
+				rename(&memberType)
+				memberTypes[memberName] = memberType
+			}
+			if v.MemberTypes != nil {
+				v.MemberTypes = memberTypes
+			}
+			members := make([]string, len(v.Members))
+			for i, member := range v.Members {
+				rename(&member)
+				members[i] = member
+			}
+			if v.Members != nil {
+				v.Members = members
+			}
+		case *ComplexType:
+			declaration(&v.Name)
+			rename(&v.Base)
+			elements(v.Elements)
+			attributes(v.Attributes)
+			groups(v.Groups)
+			for i := range v.AttributeGroup {
+				rename(&v.AttributeGroup[i].Ref)
+			}
+		case *Group:
+			declaration(&v.Name)
+			elements(v.Elements)
+			groups(v.Groups)
+		case *AttributeGroup:
+			declaration(&v.Name)
+			rename(&v.Ref)
+			attributes(v.Attributes)
+		case *Element:
+			rename(&v.Type)
+		case *Attribute:
+			rename(&v.Type)
+		case *Unique:
+			rename(&v.Type)
+		}
+	}
+	opt.warn(fmt.Sprintf("renamed types colliding with reserved %s identifiers: %s", opt.Lang, strings.Join(report, ", ")))
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"os"
+	"strings"
+)
+
+// getTypeRef returns the qualified name of the type referenced by the given
+// prefixed name in the {namespace}local form, the namespace is resolved when
+// parsed, since the prefixes are shared by the schemas parsed later.
+func (opt *Options) getTypeRef(value string) string {
+	return formatVerifyName(xml.Name{Space: opt.parseNS(value), Local: trimNSPrefix(value)})
+}
+
+// resolveRestrictions sets the facets of the simple types referenced by the
+// elements and attributes, which are looked up once all the schemas are
+// parsed, so the simple types declared after their use, or by the imported
+// and the included schemas, keep their facets as the ones declared before.
+// The facets don't depend on the language, so they are resolved in the
+// extract mode as well.
+func (opt *Options) resolveRestrictions() error {
+	resolved := map[string]Restriction{}
+	var included [][]interface{}
+	var walked bool
+	resolve := func(typeRef string, restriction *Restriction) error {
+		if typeRef == "" {
+			return nil
+		}
+		r, ok := resolved[typeRef]
+		if !ok {
+			namespace, name := parseTypeRef(typeRef)
+			if namespace != "" && namespace != opt.TargetNamespace {
+				var err error
+				if r, err = opt.getImportedRestriction(namespace, name); err != nil {
+					return err
+				}
+			} else if r, ok = lookupRestriction(name, opt.ProtoTree); !ok && !opt.Extract && len(opt.IncludeMap) > 0 {
+				// The included schemas are parsed once for all the
+				// references, the extracted schemas only resolve their own
+				// declarations as their value types.
+				if !walked {
+					walked = true
+					if err := opt.walkIncludedSchemas(func(protoTree []interface{}) bool {
+						included = append(included, protoTree)
+						return false
+					}); err != nil {
+						return err
+					}
+				}
+				for _, protoTree := range included {
+					if r, ok = lookupRestriction(name, protoTree); ok {
+						break
+					}
+				}
+			}
+			resolved[typeRef] = r
+		}
+		*restriction = r
+		return nil
+	}
+	elements := func(elements []Element) error {
+		for i := range elements {
+			if err := resolve(elements[i].TypeRef, &elements[i].Restriction); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	attributes := func(attributes []Attribute) error {
+		for i := range attributes {
+			if err := resolve(attributes[i].TypeRef, &attributes[i].Restriction); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, ele := range opt.ProtoTree {
+		var err error
+		switch v := ele.(type) {
+		case *ComplexType:
+			if err = elements(v.Elements); err == nil {
+				err = attributes(v.Attributes)
+			}
+		case *Group:
+			err = elements(v.Elements)
+		case *AttributeGroup:
+			err = attributes(v.Attributes)
+		case *Element:
+			err = resolve(v.TypeRef, &v.Restriction)
+		case *Attribute:
+			err = resolve(v.TypeRef, &v.Restriction)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTypeRef returns the namespace and the local name of the qualified
+// name of the type in the {namespace}local form.
+func parseTypeRef(typeRef string) (namespace, name string) {
+	if end := strings.LastIndex(typeRef, "}"); strings.HasPrefix(typeRef, "{") && end != -1 {
+		return typeRef[1:end], typeRef[end+1:]
+	}
+	return "", typeRef
+}
+
+// getImportedRestriction returns the facets of the simple type by given name
+// declared by the schema imported for the namespace, which are empty for the
+// built-in types and the types which aren't simple types.
+func (opt *Options) getImportedRestriction(namespace, name string) (restriction Restriction, err error) {
+	location, ok := opt.NSSchemaLocationMap[namespace]
+	if namespace == xsdNamespace || !ok || opt.Extract {
+		return
+	}
+	xsdFile, err := opt.resolveSchemaLocation(location)
+	if xsdFile == "" || err != nil {
+		return
+	}
+	if fi, err := os.Stat(xsdFile); err != nil || fi.IsDir() {
+		return restriction, nil
+	}
+	protoTree, ok := opt.ParseFileMap[xsdFile]
+	if !ok {
+		parser := NewParser(opt.subOptions(xsdFile, true))
+		if err = parser.Parse(); err != nil {
+			err = getLimitError(err)
+			return
+		}
+		opt.addWarnings(parser.Warnings)
+		protoTree = parser.ProtoTree
+	}
+	restriction = getRestrictionFromSimpleType(name, protoTree)
+	return
+}
@@ -0,0 +1,111 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// rustFeaturePattern matches the names of the Cargo features.
+	rustFeaturePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_-]*$`)
+	// rustDerivePattern matches the derive attributes of the generated Rust
+	// types.
+	rustDerivePattern = regexp.MustCompile(`(?m)^#\[derive\(([^)]*)\)\]\n`)
+)
+
+// RustDerive is a derive added to the generated Rust types, given by the
+// path of the derive macro, such as Hash or schemars::JsonSchema, which is
+// gated by the Cargo feature of the Feature unless it's empty.
+type RustDerive struct {
+	Path    string
+	Feature string
+}
+
+// ParseRustDerives parses the comma-separated derives added to the generated
+// Rust types, each optionally followed by an equals sign and the feature
+// gating it, such as Hash,schemars::JsonSchema=schema.
+func ParseRustDerives(value string) ([]RustDerive, error) {
+	var derives []RustDerive
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		derive := RustDerive{Path: pair}
+		if idx := strings.Index(pair, "="); idx != -1 {
+			derive = RustDerive{Path: strings.TrimSpace(pair[:idx]), Feature: strings.TrimSpace(pair[idx+1:])}
+		}
+		derives = append(derives, derive)
+	}
+	return derives, checkRustDerives(derives)
+}
+
+// checkRustDerives returns an error if the path of a derive isn't a path of
+// Rust, or its feature isn't a name of the Cargo features.
+func checkRustDerives(derives []RustDerive) error {
+	for _, derive := range derives {
+		if !rustTypePathPattern.MatchString(derive.Path) {
+			return fmt.Errorf("invalid Rust derive %s", derive.Path)
+		}
+		if derive.Feature != "" && !rustFeaturePattern.MatchString(derive.Feature) {
+			return fmt.Errorf("invalid feature %s of the Rust derive %s", derive.Feature, derive.Path)
+		}
+	}
+	return nil
+}
+
+// formatRustDerives returns the derives as the comma-separated paths,
+// followed by the features gating them.
+func formatRustDerives(derives []RustDerive) string {
+	var pairs []string
+	for _, derive := range derives {
+		if derive.Feature == "" {
+			pairs = append(pairs, derive.Path)
+			continue
+		}
+		pairs = append(pairs, derive.Path+"="+derive.Feature)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// genRustDerives returns the code with the derives of the options added to
+// the derive attributes of the types for Rust code. The derives without
+// feature are added to the attributes, and the ones gated by features are
+// added by the cfg_attr attributes of the features following them. The
+// derives the types already have are kept as generated.
+func (gen *CodeGenerator) genRustDerives(code string) string {
+	if len(gen.RustDerives) == 0 {
+		return code
+	}
+	return rustDerivePattern.ReplaceAllStringFunc(code, func(attr string) string {
+		derives := strings.Split(rustDerivePattern.FindStringSubmatch(attr)[1], ", ")
+		var features []string
+		gated := map[string][]string{}
+		for _, derive := range gen.RustDerives {
+			if inStringSlice(derive.Path, derives) {
+				continue
+			}
+			if derive.Feature == "" {
+				derives = append(derives, derive.Path)
+				continue
+			}
+			if _, ok := gated[derive.Feature]; !ok {
+				features = append(features, derive.Feature)
+			}
+			gated[derive.Feature] = append(gated[derive.Feature], derive.Path)
+		}
+		attr = fmt.Sprintf("#[derive(%s)]\n", strings.Join(derives, ", "))
+		for _, feature := range features {
+			attr += fmt.Sprintf("#[cfg_attr(feature = \"%s\", derive(%s))]\n", feature, strings.Join(gated[feature], ", "))
+		}
+		return attr
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// rustUsePathPattern matches the paths of the use declarations, such as
+	// crate::common::*, serde_json::{Map, Value} and std::fmt as stdfmt.
+	rustUsePathPattern = regexp.MustCompile(`^(::)?[A-Za-z_]\w*(::[A-Za-z_]\w*)*(::(\*|\{[\w:, *]*\}))?( as [A-Za-z_]\w*)?$`)
+	// rustTypePathPattern matches the paths of the validation error type,
+	// such as open_payments_common::ValidationError.
+	rustTypePathPattern = regexp.MustCompile(`^(::)?[A-Za-z_]\w*(::[A-Za-z_]\w*)*$`)
+)
+
+// ParseRustUses parses the comma-separated paths of the use declarations
+// added to the generated Rust code, such as crate::common::*. The commas of
+// the braces, such as serde_json::{Map, Value}, don't separate the paths.
+func ParseRustUses(value string) ([]string, error) {
+	var uses []string
+	var depth, start int
+	for i := 0; i <= len(value); i++ {
+		if i < len(value) {
+			switch value[i] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+			if value[i] != ',' || depth > 0 {
+				continue
+			}
+		}
+		if use := strings.TrimSpace(value[start:i]); use != "" {
+			uses = append(uses, use)
+		}
+		start = i + 1
+	}
+	return uses, checkRustUses(uses)
+}
+
+// checkRustUses returns an error if a path of the use declarations isn't a
+// path of Rust.
+func checkRustUses(uses []string) error {
+	for _, use := range uses {
+		if !rustUsePathPattern.MatchString(use) {
+			return fmt.Errorf("invalid Rust use path %s", use)
+		}
+	}
+	return nil
+}
+
+// checkRustErrorType returns an error if the path of the validation error
+// type isn't a path of a Rust type.
+func checkRustErrorType(path string) error {
+	if path != "" && !rustTypePathPattern.MatchString(path) {
+		return fmt.Errorf("invalid Rust error type path %s", path)
+	}
+	return nil
+}
+
+// genRustUses returns the use declarations of the extra use paths for Rust
+// code.
+func (gen *CodeGenerator) genRustUses() (uses string) {
+	for _, use := range gen.RustUses {
+		uses += fmt.Sprintf("use %s;\n", use)
+	}
+	return
+}
+
+// genRustValidationImports returns the use declarations required by the
+// given validation code. The validation error type is imported by its path,
+// under the ValidationError name if the type is named otherwise, or is
+// declared by the code of genRustErrorTypeCode without the path.
+func (gen *CodeGenerator) genRustValidationImports(code string) (imports string) {
+	if path := gen.RustErrorType; path != "" {
+		if path[strings.LastIndex(path, ":")+1:] == "ValidationError" {
+			imports += fmt.Sprintf("use %s;\n", path)
+		} else {
+			imports += fmt.Sprintf("use %s as ValidationError;\n", path)
+		}
+	}
+	if strings.Contains(code, "xsd_pattern(") {
+		imports += "use regex::Regex;\n"
+	}
+	return
+}
+
+// genRustErrorTypeCode generate the validation error type of the
+// validation code for Rust code, unless the path of the type is given, so
+// the generated code doesn't depend on another crate.
+func (gen *CodeGenerator) genRustErrorTypeCode() string {
+	if gen.RustErrorType != "" {
+		return ""
+	}
+	return fmt.Sprintf(`
+%s#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ValidationError {
+	pub code: u32,
+	pub message: String,
+}
+
+impl ValidationError {
+	pub fn new(code: u32, message: String) -> Self {
+		ValidationError { code, message }
+	}
+}
+
+impl std::fmt::Display for ValidationError {
+	fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+		write!(f, "{}", self.message)
+	}
+}
+
+impl std::error::Error for ValidationError {}
+`, gen.genComment("ValidationError", "the error of the validation code, with the code of the violated constraint and the message."))
+}
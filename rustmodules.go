@@ -0,0 +1,144 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+)
+
+// rustModuleSuffix is the suffix of the names of the modules of the
+// top-level elements in the Rust modules mode, which tells them apart from
+// the types of the elements named by the elements as well.
+const rustModuleSuffix = "_module"
+
+// rustValidatorModule is the module of the standalone validator functions
+// in the Rust modules mode.
+const rustValidatorModule = "validator"
+
+// rustItemRegexp matches the names of the top-level items of Rust code,
+// which the names of the modules can't clash with.
+var rustItemRegexp = regexp.MustCompile(`(?m)^(?:pub )?(?:struct|enum|type|trait|const|static|fn) (\w+)`)
+
+// rustDeclaration is the code generated for the top-level declaration for
+// Rust code, kept in the Rust modules mode to be written to the module of
+// the declaration.
+type rustDeclaration struct {
+	ele  interface{}
+	code string
+}
+
+// checkRustModules returns an error if the Rust modules mode of the options
+// can't be used with the other options. The visitor and the equality code
+// are written to the mod.rs, which can't access the private fields of the
+// accessors declared by the modules.
+func (opt *Options) checkRustModules() error {
+	if !opt.RustModules {
+		return nil
+	}
+	if opt.Lang != "Rust" {
+		return fmt.Errorf("the Rust modules mode isn't supported for %s", opt.Lang)
+	}
+	if opt.GroupByMessage {
+		return errors.New("the Rust modules mode can't be used with the group-by-message mode")
+	}
+	if opt.Accessors && (opt.Visitor || opt.Equality) {
+		return errors.New("the Rust modules mode can't be used with the accessors of the visitor and the equality code")
+	}
+	return nil
+}
+
+// getRustModuleOwners returns the names of the top-level elements owning the
+// declarations of the schema in the Rust modules mode, that is the only
+// element each declaration is reachable from. The declarations reachable
+// from several elements or from none of them aren't owned.
+func getRustModuleOwners(XSDSchema []interface{}) map[interface{}]string {
+	owners := map[interface{}]string{}
+	shared := map[interface{}]bool{}
+	declarations, elements := getDeclarationsByName(XSDSchema)
+	for _, element := range elements {
+		reachable := map[interface{}]bool{}
+		walkReferences(declarations, []string{element}, reachable)
+		for ele := range reachable {
+			if owner, ok := owners[ele]; ok && owner != element {
+				shared[ele] = true
+			}
+			owners[ele] = element
+		}
+	}
+	for ele := range shared {
+		delete(owners, ele)
+	}
+	return owners
+}
+
+// getRustModulesDir returns the directory of the Rust modules of the schema,
+// named by the module of the file of the schema, such as pacs_008_001_08_xsd
+// of the file pacs.008.001.08.xsd.rs.
+func (gen *CodeGenerator) getRustModulesDir() string {
+	file := gen.FileWithExtension(".rs")
+	return filepath.Join(filepath.Dir(file), genRustModuleName(file))
+}
+
+// genRustModules writes the code of the schema to the directory of its Rust
+// modules, instead of a single file: a module per top-level element holding
+// the code of the declarations owned by the element, named after the
+// element with the suffix unless the name is taken by an item of the code,
+// and the mod.rs declaring and reexporting the modules, which holds the code
+// of the shared declarations and the code following the declarations, from
+// the given offset of the fields. The modules import the declarations of
+// each other and the use declarations of the extern by the mod.rs.
+func (gen *CodeGenerator) genRustModules(dir, extern string, declared int) error {
+	if gen.Artifacts == nil {
+		if err := PrepareOutputDir(dir); err != nil {
+			return err
+		}
+	}
+	owners := getRustModuleOwners(gen.ProtoTree)
+	codes := map[string]string{}
+	var elements []string
+	var shared string
+	for _, decl := range gen.rustDeclarations {
+		element, ok := owners[decl.ele]
+		if !ok {
+			shared += decl.code
+			continue
+		}
+		if _, ok = codes[element]; !ok {
+			elements = append(elements, element)
+		}
+		codes[element] += decl.code
+	}
+	modules := map[string]bool{rustValidatorModule: true}
+	for _, match := range rustItemRegexp.FindAllStringSubmatch(gen.mixinCode+gen.Field, -1) {
+		modules[match[1]] = true
+	}
+	var index string
+	for _, element := range elements {
+		module := genRustFieldName(element) + rustModuleSuffix
+		for i := 1; modules[module]; i++ {
+			module = fmt.Sprintf("%s%s_%d", genRustFieldName(element), rustModuleSuffix, i)
+		}
+		modules[module] = true
+		code := gen.genRustDerives(codes[element])
+		source := []byte(fmt.Sprintf("%s\n\n%suse super::*;\n%s", gen.fileHeader(), genRustAllowDeprecated(code), code))
+		if err := gen.WriteFile(filepath.Join(dir, module+".rs"), source); err != nil {
+			return err
+		}
+		index += fmt.Sprintf("pub mod %s;\npub use %s::*;\n", module, module)
+	}
+	if gen.Validation == ValidationStandalone {
+		index += fmt.Sprintf("pub mod %s;\n", rustValidatorModule)
+	}
+	shared = gen.genRustDerives(shared + gen.Field[declared:])
+	source := []byte(fmt.Sprintf("%s\n\n%s%s\n%s%s%s", gen.fileHeader(), genRustAllowDeprecated(gen.Field), extern, index, gen.mixinCode, shared))
+	return gen.WriteFile(filepath.Join(dir, "mod.rs"), source)
+}
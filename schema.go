@@ -28,13 +28,10 @@ func (opt *Options) prepareNSSchemaLocationMap(element xml.StartElement) {
 			if _, ok := opt.NSSchemaLocationMap[currentNS]; ok {
 				continue
 			}
-			if isValidURL(ele.Value) {
+			// The remote schemas are skipped unless the options resolve
+			// them.
+			if isValidURL(ele.Value) && !opt.resolvesRemoteSchemas() {
 				continue
-				// TODO: fetch remote schema
-				// var err error
-				// if opt.RemoteSchema[ele.Value], err = fetchSchema(ele.Value); err != nil {
-				// 	continue
-				// }
 			}
 			opt.NSSchemaLocationMap[currentNS] = ele.Value
 		}
@@ -0,0 +1,61 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// goSensitiveTag is the struct tag of the sensitive fields for Go code.
+	goSensitiveTag = `sensitive:"true"`
+	// rustSensitiveDoc is the doc comment of the sensitive fields for Rust
+	// code.
+	rustSensitiveDoc = "\t/// The value is sensitive, it's blanked by the redact method.\n"
+)
+
+var rustSensitiveFieldRegexp = regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(rustSensitiveDoc) + `\t#\[serde\(rename = "([^"]*)"\)\]\n\tpub ((?:r#)?\w+): `)
+
+// genGoSensitiveTag generate the struct tag appended to the XML struct tag of
+// the field for Go code, if the field is sensitive.
+func genGoSensitiveTag(sensitive bool) string {
+	if !sensitive {
+		return ""
+	}
+	return " " + goSensitiveTag
+}
+
+// genRustSensitiveDoc generate the doc comment of the field for Rust code, if
+// the field is sensitive.
+func genRustSensitiveDoc(sensitive bool) string {
+	if !sensitive {
+		return ""
+	}
+	return rustSensitiveDoc
+}
+
+// genRustRedact generate the impl block of the struct for Rust code with the
+// SENSITIVE_FIELDS constant holding the XML names of the sensitive fields,
+// and the redact method blanking them, so the logging layers can mask the
+// personal data. The nested structs are redacted by their own redact method.
+func genRustRedact(structName, fieldContent string) string {
+	matches := rustSensitiveFieldRegexp.FindAllStringSubmatch(fieldContent, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	var names []string
+	var blank string
+	for _, match := range matches {
+		names = append(names, fmt.Sprintf("\"%s\"", match[1]))
+		blank += fmt.Sprintf("\t\tself.%s = Default::default();\n", match[2])
+	}
+	return fmt.Sprintf("\nimpl %s {\n\tpub const SENSITIVE_FIELDS: &'static [&'static str] = &[%s];\n\n\tpub fn redact(&mut self) {\n%s\t}\n}\n", structName, strings.Join(names, ", "), blank)
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// SizeReport is the size of the generated code, written next to the code,
+// so the pathological types, such as the enums of thousands of variants, can
+// be spotted and pruned or aliased. Files holds the size of each generated
+// file in the order of writing, and Types the size of the code generated for
+// each top-level declaration, the largest first.
+type SizeReport struct {
+	Language string     `json:"language"`
+	Files    []FileSize `json:"files"`
+	Types    []TypeSize `json:"types"`
+}
+
+// FileSize is the size of the generated file by the path in lines and bytes.
+type FileSize struct {
+	Path  string `json:"path"`
+	Lines int    `json:"lines"`
+	Bytes int    `json:"bytes"`
+}
+
+// TypeSize is the size of the code generated for the top-level declaration
+// by the kind and the qualified name in the {namespace}local form, in lines
+// and bytes, including its validation code.
+type TypeSize struct {
+	Kind  string `json:"kind"`
+	QName string `json:"qname"`
+	Lines int    `json:"lines"`
+	Bytes int    `json:"bytes"`
+}
+
+// genDeclaration generates the code of the top-level declaration by the
+// function of the language prefix and the kind of the declaration, and
+// records the code appended for it in the Rust modules mode, and its size if
+// the size report is generated.
+func (gen *CodeGenerator) genDeclaration(prefix string, ele interface{}) {
+	funcName := fmt.Sprintf("%s%s", prefix, reflect.TypeOf(ele).String()[6:])
+	field, validation := len(gen.Field), len(gen.ValidationCode)
+	callFuncByName(gen, funcName, []reflect.Value{reflect.ValueOf(ele)})
+	if gen.RustModules {
+		gen.rustDeclarations = append(gen.rustDeclarations, rustDeclaration{ele: ele, code: gen.Field[field:]})
+	}
+	if !gen.SizeReport {
+		return
+	}
+	var size TypeSize
+	for _, code := range []struct {
+		text string
+		from int
+	}{{gen.Field, field}, {gen.ValidationCode, validation}} {
+		if len(code.text) > code.from {
+			size.Lines += strings.Count(code.text[code.from:], "\n")
+			size.Bytes += len(code.text) - code.from
+		}
+	}
+	if size.Bytes == 0 {
+		return
+	}
+	kind, name := getDeclarationKind(ele)
+	size.Kind, size.QName = kind, formatVerifyName(xml.Name{Space: gen.TargetNamespace, Local: name})
+	gen.typeSizes = append(gen.typeSizes, size)
+}
+
+// addFileSize records the size of the generated file if the size report is
+// generated.
+func (gen *CodeGenerator) addFileSize(name string, data []byte) {
+	if gen.SizeReport {
+		gen.fileSizes = append(gen.fileSizes, FileSize{Path: name, Lines: strings.Count(string(data), "\n"), Bytes: len(data)})
+	}
+}
+
+// GenSizeReport writes the size report of the generated code as JSON.
+func (gen *CodeGenerator) GenSizeReport() error {
+	report := SizeReport{Language: gen.Lang, Files: gen.fileSizes, Types: gen.typeSizes}
+	if report.Files == nil {
+		report.Files = []FileSize{}
+	}
+	if report.Types == nil {
+		report.Types = []TypeSize{}
+	}
+	sort.SliceStable(report.Types, func(i, j int) bool {
+		return report.Types[i].Bytes > report.Types[j].Bytes
+	})
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(gen.FileWithExtension(".sizes.json"), append(data, '\n'))
+}
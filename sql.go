@@ -0,0 +1,179 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+)
+
+// goSQLScanFuncs holds the functions of the shared SQL helpers scanning the
+// values read from the database by the kind of the Go built-in type, and the
+// bit size the values are parsed with.
+var goSQLScanFuncs = map[string]struct {
+	scan    string
+	bitSize int
+}{
+	"string":  {"scanXSDString", 0},
+	"bool":    {"scanXSDBool", 0},
+	"float32": {"scanXSDFloat", 32},
+	"float64": {"scanXSDFloat", 64},
+	"int":     {"scanXSDInt", 0},
+	"int8":    {"scanXSDInt", 8},
+	"int16":   {"scanXSDInt", 16},
+	"int32":   {"scanXSDInt", 32},
+	"int64":   {"scanXSDInt", 64},
+	"byte":    {"scanXSDUint", 8},
+	"uint":    {"scanXSDUint", 0},
+	"uint8":   {"scanXSDUint", 8},
+	"uint16":  {"scanXSDUint", 16},
+	"uint32":  {"scanXSDUint", 32},
+	"uint64":  {"scanXSDUint", 64},
+}
+
+// genGoSQLMethods generate the Scan and Value methods of the simple type
+// implementing the sql.Scanner and driver.Valuer interfaces for Go code in SQL
+// methods mode, so the enumerations and the restricted scalar types are stored
+// in and read from the databases directly. The simple types of the other
+// built-in types have no methods.
+func (gen *CodeGenerator) genGoSQLMethods(typeName, fieldType string) string {
+	scanFunc, ok := goSQLScanFuncs[fieldType]
+	if !gen.SQLMethods || !ok {
+		return ""
+	}
+	scan := fmt.Sprintf("%s(src)", scanFunc.scan)
+	if scanFunc.scan != "scanXSDString" && scanFunc.scan != "scanXSDBool" {
+		scan = fmt.Sprintf("%s(src, %d)", scanFunc.scan, scanFunc.bitSize)
+	}
+	value := fmt.Sprintf("%s(v), nil", fieldType)
+	switch {
+	case fieldType == "float32":
+		value = "float64(v), nil"
+	case fieldType == "uint" || fieldType == "uint64":
+		value = "valueXSDUint(uint64(v))"
+	case scanFunc.scan == "scanXSDInt" || scanFunc.scan == "scanXSDUint":
+		value = "int64(v), nil"
+	}
+	return fmt.Sprintf(`
+// Scan implements the sql.Scanner interface, reading the %s from the value
+// of the database.
+func (v *%s) Scan(src interface{}) error {
+	value, err := %s
+	if err != nil {
+		return err
+	}
+	*v = %s(value)
+	return nil
+}
+
+// Value implements the driver.Valuer interface, writing the %s as the value
+// of the database.
+func (v %s) Value() (driver.Value, error) {
+	return %s
+}
+`, typeName, typeName, scan, typeName, typeName, typeName, value)
+}
+
+// genGoSQL writes the functions shared by the Scan and Value methods of the
+// simple types of the package, which convert the values of the database
+// drivers to and from the Go built-in types.
+func (gen *CodeGenerator) genGoSQL(packageName string) error {
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+package %s
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// scanXSDString converts the value read from the database to the lexical
+// form of the simple types.
+func scanXSDString(src interface{}) (string, error) {
+	switch src := src.(type) {
+	case string:
+		return src, nil
+	case []byte:
+		return string(src), nil
+	case int64:
+		return strconv.FormatInt(src, 10), nil
+	case float64:
+		return strconv.FormatFloat(src, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(src), nil
+	case nil:
+		return "", fmt.Errorf("unsupported scan of NULL into the simple type")
+	}
+	return "", fmt.Errorf("unsupported scan of %%T into the simple type", src)
+}
+
+// scanXSDBool converts the value read from the database to a bool.
+func scanXSDBool(src interface{}) (bool, error) {
+	text, err := scanXSDString(src)
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(strings.TrimSpace(text))
+}
+
+// scanXSDFloat converts the value read from the database to a float of the
+// bit size.
+func scanXSDFloat(src interface{}, bitSize int) (float64, error) {
+	text, err := scanXSDString(src)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(text), bitSize)
+}
+
+// scanXSDInt converts the value read from the database to an integer of the
+// bit size.
+func scanXSDInt(src interface{}, bitSize int) (int64, error) {
+	text, err := scanXSDString(src)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(text), 10, bitSize)
+}
+
+// scanXSDUint converts the value read from the database to an unsigned
+// integer of the bit size.
+func scanXSDUint(src interface{}, bitSize int) (uint64, error) {
+	text, err := scanXSDString(src)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(text), 10, bitSize)
+}
+
+// valueXSDUint converts the unsigned integer to the int64 value of the
+// database drivers, which can't hold the values overflowing it.
+func valueXSDUint(value uint64) (driver.Value, error) {
+	if value > math.MaxInt64 {
+		return nil, fmt.Errorf("value %%d overflows the int64 of the database driver", value)
+	}
+	return int64(value), nil
+}
+`, gen.fileHeader(), packageName)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "xsd_sql.go"), source)
+}
+
+// hasGoSQLMethods returns true if the code declares the Scan and Value
+// methods of the simple types.
+func hasGoSQLMethods(code string) bool {
+	return strings.Contains(code, ") Value() (driver.Value, error) {")
+}
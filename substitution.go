@@ -0,0 +1,244 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// substitutionGroupLangs holds the languages the substitution groups are
+// generated for, as a Go interface, a Rust enum and a TypeScript union type.
+// The references to the head elements are generated as the head elements in
+// the other languages.
+var substitutionGroupLangs = map[string]bool{"Go": true, "Rust": true, "TypeScript": true}
+
+// resolveSubstitutionGroups gives the elements declared without type in a
+// substitution group the type of their head element, and adds the
+// substitution group of each head element to the proto tree after the head
+// element. The references to the head elements in the complex types and the
+// groups are given the type of the substitution group.
+func (opt *Options) resolveSubstitutionGroups() {
+	heads, members := map[string]*Element{}, map[string][]*Element{}
+	for _, ele := range opt.ProtoTree {
+		if v, ok := ele.(*Element); ok {
+			heads[v.Name] = v
+		}
+	}
+	for _, ele := range opt.ProtoTree {
+		v, ok := ele.(*Element)
+		if !ok || v.SubstitutionGroup == "" || heads[v.SubstitutionGroup] == nil {
+			continue
+		}
+		if head := heads[v.SubstitutionGroup]; v.Untyped && trimNSPrefix(v.Type) == trimNSPrefix(v.Name) {
+			v.Type, v.Untyped = head.Type, head.Untyped
+		}
+		members[v.SubstitutionGroup] = append(members[v.SubstitutionGroup], v)
+	}
+	if !substitutionGroupLangs[opt.Lang] || len(members) == 0 {
+		return
+	}
+	var collect func(group *SubstitutionGroup, v *Element, visited map[string]bool)
+	collect = func(group *SubstitutionGroup, v *Element, visited map[string]bool) {
+		if visited[v.Name] {
+			return
+		}
+		visited[v.Name] = true
+		if !v.Abstract {
+			group.Members = append(group.Members, *v)
+		}
+		for _, member := range members[v.Name] {
+			collect(group, member, visited)
+		}
+	}
+	groups := map[string]string{}
+	protoTree := make([]interface{}, 0, len(opt.ProtoTree))
+	for _, ele := range opt.ProtoTree {
+		protoTree = append(protoTree, ele)
+		v, ok := ele.(*Element)
+		if !ok || len(members[v.Name]) == 0 {
+			continue
+		}
+		group := &SubstitutionGroup{Doc: v.Doc, Name: v.Name + "Substitution", Head: v.Name}
+		collect(group, v, map[string]bool{})
+		if len(group.Members) == 0 {
+			continue
+		}
+		groups[v.Name] = group.Name
+		protoTree = append(protoTree, group)
+	}
+	opt.ProtoTree = protoTree
+	substitute := func(elements []Element) {
+		for i, element := range elements {
+			if group, ok := groups[trimNSPrefix(element.Name)]; ok && element.Ref {
+				elements[i].Type = group
+			}
+		}
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *ComplexType:
+			substitute(v.Elements)
+		case *Group:
+			substitute(v.Elements)
+		}
+	}
+}
+
+// isSubstitutionGroup returns true if the type by given name is the type of
+// a substitution group.
+func isSubstitutionGroup(name string, XSDSchema []interface{}) bool {
+	for _, ele := range XSDSchema {
+		if v, ok := ele.(*SubstitutionGroup); ok && v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getGoElementTagName returns the name of the XML tag of the Go field of the
+// element of given elements, which is the ",any" of the first reference to
+// the head element of a substitution group, as a struct decodes the elements
+// of one field by any name.
+func (gen *CodeGenerator) getGoElementTagName(element Element, elements []Element) string {
+	for _, e := range elements {
+		if isSubstitutionGroup(e.Type, gen.ProtoTree) {
+			if e.Name == element.Name {
+				return ",any"
+			}
+			break
+		}
+	}
+	return element.Name
+}
+
+// genSubstitutionGroupDoc returns the documentation of the type of the
+// substitution group, listing the elements of the group.
+func genSubstitutionGroupDoc(v *SubstitutionGroup) string {
+	var names []string
+	for _, member := range v.Members {
+		names = append(names, member.Name)
+	}
+	list := names[len(names)-1]
+	if len(names) > 1 {
+		list = strings.Join(names[:len(names)-1], ", ") + " and " + list
+	}
+	return fmt.Sprintf("an element of the %s substitution group, one of the %s elements.", v.Head, list)
+}
+
+// GoSubstitutionGroup generates code for the substitution group in Go
+// language syntax, as an interface implemented by the types of the elements
+// of the group, and the struct holding the element which is decoded and
+// encoded by the name of the element. The struct is the type of the fields
+// referencing the head element. As the name of the element varies, the first
+// of the fields of a struct is given the ",any" tag, and the other ones only
+// decode the head element.
+func (gen *CodeGenerator) GoSubstitutionGroup(v *SubstitutionGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	gen.ImportEncodingXML = true
+	typeName := genGoFieldName(v.Name, false)
+	gen.addSymbol(v, typeName)
+	gen.StructAST[v.Name] = fmt.Sprintf("\tXMLName\txml.Name\n\tValue\t%sMember\n", typeName)
+	gen.Field += fmt.Sprintf("%stype %s struct {\n%s}\n", gen.genComment(typeName, genSubstitutionGroupDoc(v)), typeName, gen.StructAST[v.Name])
+	gen.Field += fmt.Sprintf("\n// %sMember is the type of an element of the %s substitution\n// group.\ntype %sMember interface {\n\tis%s()\n}\n", typeName, v.Head, typeName, typeName)
+	var decode, encode string
+	implemented := map[string]bool{}
+	for _, member := range v.Members {
+		// The types of the elements of the built-in types are the named
+		// types of the elements, which have methods.
+		memberType := strings.TrimPrefix(gen.genGoFieldType(getBasefromSimpleType(trimNSPrefix(member.Type), gen.ProtoTree)), "*")
+		if gen.isGoBuiltInType(memberType) {
+			memberType = genGoFieldName(member.Name, false)
+		}
+		decode += fmt.Sprintf("\tcase \"%s\":\n\t\tvalue = new(%s)\n", member.Name, memberType)
+		if !implemented[memberType] {
+			implemented[memberType] = true
+			encode += fmt.Sprintf("\tcase *%s:\n\t\tstart.Name.Local = \"%s\"\n", memberType, member.Name)
+			gen.Field += fmt.Sprintf("\nfunc (*%s) is%s() {}\n", memberType, typeName)
+		}
+	}
+	gen.Field += fmt.Sprintf(`
+// UnmarshalXML decodes the element of the %s substitution group by the name
+// of the element.
+func (v *%s) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var value %sMember
+	switch start.Name.Local {
+%s	default:
+		return xml.UnmarshalError("unexpected element " + start.Name.Local + " of the %s substitution group")
+	}
+	if err := d.DecodeElement(value, &start); err != nil {
+		return err
+	}
+	v.XMLName, v.Value = start.Name, value
+	return nil
+}
+
+// MarshalXML encodes the element of the %s substitution group named by the
+// XMLName, or by the type of the value if the XMLName isn't set.
+func (v %s) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if v.XMLName.Local != "" {
+		return e.EncodeElement(v.Value, xml.StartElement{Name: v.XMLName, Attr: start.Attr})
+	}
+	switch v.Value.(type) {
+%s	}
+	return e.EncodeElement(v.Value, start)
+}
+`, v.Head, typeName, typeName, decode, v.Head, v.Head, typeName, encode)
+}
+
+// RustSubstitutionGroup generates code for the substitution group in Rust
+// language syntax, as an enum with a variant per element of the group like
+// the enums of the choices.
+func (gen *CodeGenerator) RustSubstitutionGroup(v *SubstitutionGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	enumName := genRustStructName(v.Name, true)
+	gen.addSymbol(v, enumName)
+	gen.StructAST[v.Name] = enumName
+	c := &rustChoice{elements: v.Members, enumName: enumName}
+	variantNameCount := map[string]int{}
+	for _, member := range v.Members {
+		variantName := genRustEnumVariantName(member.Name)
+		variantNameCount[variantName]++
+		if count := variantNameCount[variantName]; count != 1 {
+			variantName = fmt.Sprintf("%s%d", variantName, count)
+		}
+		c.variants = append(c.variants, variantName)
+	}
+	if gen.choiceEnums == nil {
+		gen.choiceEnums = map[string]bool{}
+	}
+	gen.choiceEnums[enumName] = true
+	gen.genRustChoiceEnum(enumName, genSubstitutionGroupDoc(v), c)
+}
+
+// TypeScriptSubstitutionGroup generates code for the substitution group in
+// TypeScript language syntax, as the union of the types of the elements of
+// the group.
+func (gen *CodeGenerator) TypeScriptSubstitutionGroup(v *SubstitutionGroup) {
+	if _, ok := gen.StructAST[v.Name]; ok {
+		return
+	}
+	typeName := genTypeScriptFieldName(v.Name, true)
+	gen.addSymbol(v, typeName)
+	var memberTypes []string
+	implemented := map[string]bool{}
+	for _, member := range v.Members {
+		memberType := gen.genTypeScriptFieldType(getBasefromSimpleType(trimNSPrefix(member.Type), gen.ProtoTree), false)
+		if !implemented[memberType] {
+			implemented[memberType] = true
+			memberTypes = append(memberTypes, memberType)
+		}
+	}
+	gen.StructAST[v.Name] = strings.Join(memberTypes, " | ")
+	gen.Field += fmt.Sprintf("%sexport type %s = %s;\n", gen.genComment(typeName, genSubstitutionGroupDoc(v)), typeName, gen.StructAST[v.Name])
+}
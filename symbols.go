@@ -0,0 +1,156 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/json"
+	"encoding/xml"
+)
+
+// SymbolMap is the machine-readable mapping between the declarations of the
+// schema and the identifiers generated for them, written next to the
+// generated code, so the tooling can correlate a generated type or field
+// back to the schema declaration, such as when triaging the validation
+// errors reported by the counterparties.
+type SymbolMap struct {
+	Language        string   `json:"language"`
+	TargetNamespace string   `json:"targetNamespace,omitempty"`
+	Symbols         []Symbol `json:"symbols"`
+}
+
+// Symbol maps the top-level declaration by the kind and the qualified name
+// in the {namespace}local form to the identifier of the generated type.
+// Members holds the fields generated for the elements, attributes, groups
+// and attribute groups of the complex types, groups and attribute groups.
+type Symbol struct {
+	Kind       string         `json:"kind"`
+	QName      string         `json:"qname"`
+	Identifier string         `json:"identifier"`
+	Members    []SymbolMember `json:"members,omitempty"`
+}
+
+// SymbolMember maps the member of a declaration by the kind and the name to
+// the identifier of the generated field, which is the name of its accessors
+// in the accessors mode. The members of the attribute groups and groups
+// inlined in mixin mode are mapped to the fields of the type inlining them.
+type SymbolMember struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Identifier string `json:"identifier"`
+}
+
+// addSymbol records the identifier of the type generated for the
+// declaration, if the symbol map is generated.
+func (gen *CodeGenerator) addSymbol(ele interface{}, identifier string) {
+	if !gen.SymbolMap {
+		return
+	}
+	kind, name := getDeclarationKind(ele)
+	gen.symbols = append(gen.symbols, Symbol{
+		Kind:       kind,
+		QName:      formatVerifyName(xml.Name{Space: gen.TargetNamespace, Local: name}),
+		Identifier: identifier,
+		Members:    gen.getSymbolMembers(ele),
+	})
+}
+
+// getSymbolMembers returns the members of the declaration in the order of
+// the generated fields.
+func (gen *CodeGenerator) getSymbolMembers(ele interface{}) (members []SymbolMember) {
+	mixins := gen.Lang == "Go" || gen.Lang == "Java" || gen.Lang == "Rust"
+	switch v := ele.(type) {
+	case *ComplexType:
+		for _, attrGroup := range v.AttributeGroup {
+			if mixin := gen.getMixinAttributeGroup(attrGroup.Ref); mixin != nil && mixins {
+				members = append(members, gen.getSymbolMembers(mixin)...)
+				continue
+			}
+			members = append(members, gen.getSymbolMember(KindAttributeGroup, attrGroup.Name, false))
+		}
+		for _, attribute := range v.Attributes {
+			members = append(members, gen.getSymbolMember(KindAttribute, attribute.Name, false))
+		}
+		for _, group := range v.Groups {
+			if mixin := gen.getMixinGroup(group); mixin != nil && mixins {
+				members = append(members, gen.getSymbolMembers(mixin)...)
+				continue
+			}
+			members = append(members, gen.getSymbolMember(KindGroup, group.Name, group.Plural))
+		}
+		for _, element := range v.Elements {
+			member := gen.getSymbolMember(KindElement, element.Name, element.Plural)
+			// The elements of the choices generated as Rust enums are
+			// variants.
+			if gen.Lang == "Rust" {
+				if variant := gen.getRustChoiceVariant(v, element); variant != "" {
+					member.Identifier = variant
+				}
+			}
+			members = append(members, member)
+		}
+	case *Group:
+		for _, element := range v.Elements {
+			members = append(members, gen.getSymbolMember(KindElement, element.Name, element.Plural))
+		}
+		for _, group := range v.Groups {
+			if mixin := gen.getMixinGroup(group); mixin != nil && mixins {
+				members = append(members, gen.getSymbolMembers(mixin)...)
+				continue
+			}
+			members = append(members, gen.getSymbolMember(KindGroup, group.Name, group.Plural))
+		}
+	case *AttributeGroup:
+		for _, attribute := range v.Attributes {
+			member := gen.getSymbolMember(KindAttribute, attribute.Name, false)
+			// The fields of the attribute group classes aren't suffixed.
+			if gen.Lang == "Java" && !gen.Mixins {
+				member.Identifier = genJavaFieldName(attribute.Name, false)
+			}
+			members = append(members, member)
+		}
+	}
+	return
+}
+
+// getSymbolMember returns the member by given kind and name, with the
+// identifier of the field generated for it in the language of the code, the
+// identifiers of the repeated members are pluralized in plural names mode.
+func (gen *CodeGenerator) getSymbolMember(kind, name string, plural bool) SymbolMember {
+	var identifier string
+	fieldName := gen.genPluralName(name, plural)
+	switch gen.Lang {
+	case "Go":
+		identifier = genGoFieldName(fieldName, false)
+	case "Rust":
+		return SymbolMember{Kind: kind, Name: name, Identifier: genRustFieldName(gen.genRustPluralName(name, plural))}
+	case "TypeScript":
+		identifier = genTypeScriptFieldName(fieldName, false)
+	case "Java":
+		identifier = genJavaFieldName(fieldName, false)
+	case "C":
+		identifier = genCFieldName(fieldName, false)
+	}
+	if kind == KindAttribute {
+		identifier += "Attr"
+	}
+	return SymbolMember{Kind: kind, Name: name, Identifier: identifier}
+}
+
+// GenSymbolMap writes the symbol map of the generated code as JSON.
+func (gen *CodeGenerator) GenSymbolMap() error {
+	symbols := SymbolMap{Language: gen.Lang, TargetNamespace: gen.TargetNamespace, Symbols: gen.symbols}
+	if symbols.Symbols == nil {
+		symbols.Symbols = []Symbol{}
+	}
+	data, err := json.MarshalIndent(symbols, "", "  ")
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(gen.FileWithExtension(".symbols.json"), append(data, '\n'))
+}
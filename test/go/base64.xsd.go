@@ -12,14 +12,14 @@ type MyType1 string
 // MyType2 ...
 type MyType2 struct {
 	XMLName    xml.Name `xml:"myType2"`
-	LengthAttr int      `xml:"length,attr,omitempty"`
+	LengthAttr *int     `xml:"length,attr,omitempty"`
 	Value      string   `xml:",chardata"`
 }
 
 // MyType3 ...
 type MyType3 struct {
 	XMLName    xml.Name `xml:"myType3"`
-	LengthAttr int      `xml:"length,attr,omitempty"`
+	LengthAttr *int     `xml:"length,attr,omitempty"`
 	Value      string   `xml:",chardata"`
 }
 
@@ -37,7 +37,7 @@ type MyType5 string
 // MyType6 ...
 type MyType6 struct {
 	CodeAttr       string `xml:"code,attr,omitempty"`
-	IdentifierAttr int    `xml:"identifier,attr,omitempty"`
+	IdentifierAttr *int   `xml:"identifier,attr,omitempty"`
 }
 
 // MyType7 ...
@@ -48,7 +48,7 @@ type MyType7 struct {
 
 // TopLevel ...
 type TopLevel struct {
-	CostAttr        float64    `xml:"cost,attr,omitempty"`
+	CostAttr        *float64   `xml:"cost,attr,omitempty"`
 	LastUpdatedAttr string     `xml:"LastUpdated,attr,omitempty"`
 	Nested          *MyType7   `xml:"nested"`
 	MyType1         []string   `xml:"myType1"`
@@ -0,0 +1,160 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+)
+
+// Build tag of the Go code and feature of the Rust code enabling the tracing
+// of the generated validation code.
+const (
+	goValidationTraceTag       = "xgen_trace"
+	rustValidationTraceFeature = "xgen-trace"
+)
+
+// genGoValidationTrace returns the result of the validation function of the
+// type and the statement tracing the validation in validation tracing mode,
+// the hook is called with the error returned by the function.
+func (gen *CodeGenerator) genGoValidationTrace(typeName string) (result, trace string) {
+	if !gen.ValidationTracing {
+		return "error", ""
+	}
+	return "(err error)", fmt.Sprintf("defer traceValidation(%q)(&err)\n", typeName)
+}
+
+// genGoValidationTraceFiles writes the validation hook shared by the
+// generated files in the package, built with the xgen_trace build tag, and
+// the function doing nothing built without it, which keeps the tracing free
+// of cost unless enabled.
+func (gen *CodeGenerator) genGoValidationTraceFiles(packageName string) error {
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+//go:build %s
+// +build %s
+
+package %s
+
+import "sync/atomic"
+
+// ValidationHook is called when the validation of the type by given name
+// starts, and the returned function is called with the result of the
+// validation once it finishes, such as to end a tracing span or to record
+// the duration of the validation as a metric.
+type ValidationHook func(typeName string) func(err error)
+
+var validationHook atomic.Value
+
+// SetValidationHook sets the hook called on the validation of each type.
+func SetValidationHook(hook ValidationHook) {
+	validationHook.Store(hook)
+}
+
+// traceValidation calls the validation hook on the validation of the type,
+// and returns the function reporting the result of the validation.
+func traceValidation(typeName string) func(err *error) {
+	hook, _ := validationHook.Load().(ValidationHook)
+	if hook == nil {
+		return skipValidationTrace
+	}
+	done := hook(typeName)
+	return func(err *error) {
+		done(*err)
+	}
+}
+
+func skipValidationTrace(*error) {}
+`, gen.fileHeader(), goValidationTraceTag, goValidationTraceTag, packageName)))
+	if err != nil {
+		return err
+	}
+	if err = gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "validation_trace.go"), source); err != nil {
+		return err
+	}
+	if source, err = format.Source([]byte(fmt.Sprintf(`%s
+
+//go:build !%s
+// +build !%s
+
+package %s
+
+// traceValidation does nothing without the %s build tag.
+func traceValidation(string) func(*error) {
+	return skipValidationTrace
+}
+
+func skipValidationTrace(*error) {}
+`, gen.fileHeader(), goValidationTraceTag, goValidationTraceTag, packageName, goValidationTraceTag))); err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "validation_trace_off.go"), source)
+}
+
+// genRustValidationTrace returns the validation body of the struct ending
+// with the result, which is wrapped by the closure traced by the validation
+// hook in validation tracing mode.
+func (gen *CodeGenerator) genRustValidationTrace(structName, indent, body string) string {
+	if !gen.ValidationTracing {
+		return body + indent + "Ok(())\n"
+	}
+	var traced string
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if line != "" {
+			traced += "\t" + line
+		}
+	}
+	return fmt.Sprintf("%strace_validation(\"%s\", || {\n%s%s\tOk(())\n%s})\n", indent, structName, traced, indent, indent)
+}
+
+// genRustValidationTraceCode generate the validation hook for Rust code,
+// enabled by the xgen-trace feature, and the function calling the validation
+// directly without the feature, which keeps the tracing free of cost unless
+// enabled.
+func genRustValidationTraceCode() string {
+	return fmt.Sprintf(`
+/// ValidationHook is called when the validation of the struct by given name
+/// starts, and the returned function is called with the result of the
+/// validation once it finishes, such as to end a tracing span or to record
+/// the duration of the validation as a metric.
+#[cfg(feature = "%s")]
+pub type ValidationHook = fn(&'static str) -> Box<dyn FnOnce(&Result<(), ValidationError>)>;
+
+#[cfg(feature = "%s")]
+static VALIDATION_HOOK: std::sync::OnceLock<ValidationHook> = std::sync::OnceLock::new();
+
+/// Sets the hook called on the validation of each struct, the hook can be
+/// set once.
+#[cfg(feature = "%s")]
+pub fn set_validation_hook(hook: ValidationHook) -> Result<(), ValidationHook> {
+	VALIDATION_HOOK.set(hook)
+}
+
+#[cfg(feature = "%s")]
+pub(crate) fn trace_validation(name: &'static str, validate: impl FnOnce() -> Result<(), ValidationError>) -> Result<(), ValidationError> {
+	match VALIDATION_HOOK.get() {
+		Some(hook) => {
+			let done = hook(name);
+			let result = validate();
+			done(&result);
+			result
+		}
+		None => validate(),
+	}
+}
+
+#[cfg(not(feature = "%s"))]
+#[inline(always)]
+pub(crate) fn trace_validation(_name: &'static str, validate: impl FnOnce() -> Result<(), ValidationError>) -> Result<(), ValidationError> {
+	validate()
+}
+`, rustValidationTraceFeature, rustValidationTraceFeature, rustValidationTraceFeature, rustValidationTraceFeature, rustValidationTraceFeature)
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isAnyType returns true if the type by given name is xs:anyType or
+// xs:anySimpleType, which don't restrict the value.
+func isAnyType(name string) bool {
+	name = trimNSPrefix(name)
+	return name == "anyType" || name == "anySimpleType"
+}
+
+// getAnyTypeFallback returns the type of the elements and attributes without
+// type, and of the xs:anyType and xs:anySimpleType, which is the string type
+// of the language unless the any type fallback option is set.
+func (opt *Options) getAnyTypeFallback() string {
+	if opt.AnyTypeFallback != "" {
+		return opt.AnyTypeFallback
+	}
+	fallback, _ := getBuildInTypeByLang("anyType", opt.Lang)
+	return fallback
+}
+
+// registerBuiltInType adds the type by given name to the types of the
+// language of the options used as is in the generated code, which are kept
+// by the options instead of the built-in types of the language shared by all
+// of them.
+func (opt *Options) registerBuiltInType(name string) {
+	if opt.builtInTypes == nil {
+		opt.builtInTypes = map[string]bool{}
+	}
+	opt.builtInTypes[name] = true
+}
+
+// resolveUntypedDeclarations maps the elements and attributes declared
+// without type, which aren't given an anonymous type either, to the any type
+// fallback, and adds a warning listing them.
+func (opt *Options) resolveUntypedDeclarations() {
+	fallback := opt.getAnyTypeFallback()
+	types := map[string]bool{}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *SimpleType:
+			types[v.Name] = true
+		case *ComplexType:
+			types[v.Name] = true
+		}
+	}
+	var names []string
+	element := func(parent string, e *Element) {
+		if !e.Untyped || trimNSPrefix(e.Type) != trimNSPrefix(e.Name) || types[trimNSPrefix(e.Name)] {
+			return
+		}
+		e.Type = fallback
+		names = append(names, parent+e.Name)
+	}
+	attribute := func(parent string, a *Attribute) {
+		if a.Type != "" {
+			return
+		}
+		a.Type = fallback
+		names = append(names, parent+"@"+a.Name)
+	}
+	for _, ele := range opt.ProtoTree {
+		switch v := ele.(type) {
+		case *Element:
+			element("", v)
+		case *Attribute:
+			attribute("", v)
+		case *ComplexType:
+			for i := range v.Elements {
+				element(v.Name+"/", &v.Elements[i])
+			}
+			for i := range v.Attributes {
+				attribute(v.Name+"/", &v.Attributes[i])
+			}
+		case *Group:
+			for i := range v.Elements {
+				element(v.Name+"/", &v.Elements[i])
+			}
+		case *AttributeGroup:
+			for i := range v.Attributes {
+				attribute(v.Name+"/", &v.Attributes[i])
+			}
+		}
+	}
+	if len(names) > 0 {
+		opt.warn(fmt.Sprintf("declarations without type mapped to %s: %s", fallback, strings.Join(names, ", ")))
+	}
+}
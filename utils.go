@@ -9,15 +9,15 @@
 package xgen
 
 import (
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -100,6 +100,7 @@ func PrepareOutputDir(path string) error {
 // https://www.w3.org/TR/xmlschema-2/#datatype
 var BuildInTypes = map[string][]string{
 	"anyType":            {"string", "string", "char", "String", "String"},
+	"anySimpleType":      {"string", "string", "char", "String", "String"},
 	"ENTITIES":           {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>"},
 	"ENTITY":             {"string", "string", "char", "String", "String"},
 	"ID":                 {"string", "string", "char", "String", "String"},
@@ -108,7 +109,7 @@ var BuildInTypes = map[string][]string{
 	"NCName":             {"string", "string", "char", "String", "String"},
 	"NMTOKEN":            {"string", "string", "char", "String", "String"},
 	"NMTOKENS":           {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>"},
-	"NOTATION":           {"[]string", "Array<string>", "char[]", "List<String>", "Vec<String>"},
+	"NOTATION":           {"string", "string", "char", "String", "String"},
 	"Name":               {"string", "string", "char", "String", "String"},
 	"QName":              {"xml.Name", "any", "char", "String", "String"},
 	"anyURI":             {"string", "string", "char", "QName", "String"},
@@ -186,6 +187,28 @@ func getBasefromSimpleType(name string, XSDSchema []interface{}) string {
 	return name
 }
 
+// getRestrictionFromSimpleType returns the facets of the simple type by
+// given name, the facets are kept on elements and attributes referencing
+// the simple type after it resolved to the build-in type.
+func getRestrictionFromSimpleType(name string, XSDSchema []interface{}) Restriction {
+	restriction, _ := lookupRestriction(name, XSDSchema)
+	return restriction
+}
+
+// lookupRestriction returns the facets of the simple type by given name, and
+// true if the simple type is declared by the schema.
+func lookupRestriction(name string, XSDSchema []interface{}) (Restriction, bool) {
+	for _, ele := range XSDSchema {
+		if v, ok := ele.(*SimpleType); ok && v.Name == name {
+			if v.List || v.Union {
+				return Restriction{}, true
+			}
+			return v.Restriction, true
+		}
+	}
+	return Restriction{}, false
+}
+
 func getNSPrefix(str string) (ns string) {
 	split := strings.Split(str, ":")
 	if len(split) == 2 {
@@ -257,33 +280,6 @@ func isValidURL(toTest string) bool {
 	return true
 }
 
-func fetchSchema(URL string) ([]byte, error) {
-	var body []byte
-	var client http.Client
-	var err error
-	resp, err := client.Get(URL)
-	if err != nil {
-		return body, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusOK {
-		body, err = ioutil.ReadAll(resp.Body)
-		if err != nil {
-			return body, err
-		}
-	}
-	return body, err
-}
-
-func genFieldComment(name, doc, prefix string) string {
-	docReplacer := strings.NewReplacer("\n", fmt.Sprintf("\r\n%s ", prefix), "\t", "")
-	if doc == "" {
-		return fmt.Sprintf("\r\n%s %s ...\r\n", prefix, name)
-	}
-	return fmt.Sprintf("\r\n%s %s is %s\r\n", prefix, name, docReplacer.Replace(doc))
-}
-
 type kvPair struct {
 	key   string
 	value string
@@ -309,3 +305,255 @@ func toSortedPairs(toSort map[string]string) kvPairList {
 	sort.Sort(pl)
 	return pl
 }
+
+// getUnionMembers returns the member types of the union by name and type, in
+// the order of the memberTypes attribute, or by name if the order isn't
+// known.
+func getUnionMembers(v *SimpleType) kvPairList {
+	if len(v.Members) != len(v.MemberTypes) {
+		return toSortedPairs(v.MemberTypes)
+	}
+	members := make(kvPairList, 0, len(v.Members))
+	for _, member := range v.Members {
+		memberType, ok := v.MemberTypes[member]
+		if !ok {
+			return toSortedPairs(v.MemberTypes)
+		}
+		members = append(members, kvPair{member, memberType})
+	}
+	return members
+}
+
+// uniqueStep is the element or attribute of the complex type referred by a
+// step of the XPath expression of an identity constraint.
+type uniqueStep struct {
+	Name      string
+	Type      string
+	Plural    bool
+	Optional  bool
+	Attribute bool
+}
+
+// isListType returns true if the simple type by given name is a list.
+func isListType(name string, XSDSchema []interface{}) bool {
+	for _, ele := range XSDSchema {
+		if v, ok := ele.(*SimpleType); ok && v.Name == name {
+			return v.List
+		}
+	}
+	return false
+}
+
+// getListItemRestriction returns the facets each item of the list type is
+// validated against, the facets of its item type by name, or of its
+// anonymous item type.
+func getListItemRestriction(v *SimpleType, XSDSchema []interface{}) Restriction {
+	if v.ItemType != "" {
+		return getRestrictionFromSimpleType(v.ItemType, XSDSchema)
+	}
+	return v.ItemRestriction
+}
+
+// parseFacetValue returns the value of the facet counting the characters,
+// the items or the digits, which is a non-negative integer.
+func parseFacetValue(facet, value string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid value %q of the %s facet", value, facet)
+	}
+	return n, nil
+}
+
+// getBuildInBase returns the built-in type the simple type by given name
+// derives from, or empty if the simple type isn't declared before.
+func getBuildInBase(name string, XSDSchema []interface{}) string {
+	name = trimNSPrefix(name)
+	if _, ok := BuildInTypes[name]; ok {
+		return name
+	}
+	return getRestrictionFromSimpleType(name, XSDSchema).base
+}
+
+// setBoundFacet sets the lower or the upper bound of the simple type on top
+// of the stack by the value of the bound facet. The invalid values are
+// reported by the warnings and left out instead of bounding the values.
+func (opt *Options) setBoundFacet(facet, value string, upper, exclusive bool) {
+	simpleType := opt.SimpleType.Peek().(*SimpleType)
+	bound, err := newFacetValue(value, simpleType.Restriction.base)
+	if err != nil {
+		opt.warn(fmt.Sprintf("ignored the %s facet of %s: %v", facet, simpleType.Name, err))
+		return
+	}
+	if upper {
+		simpleType.Restriction.Max, simpleType.Restriction.HasMax, simpleType.Restriction.MaxValue, simpleType.Restriction.MaxExclusive = bound.Float64(), true, bound, exclusive
+		return
+	}
+	simpleType.Restriction.Min, simpleType.Restriction.HasMin, simpleType.Restriction.MinValue, simpleType.Restriction.MinExclusive = bound.Float64(), true, bound, exclusive
+}
+
+// isRootElement returns true if the top-level element is declared with a
+// complex type of the schema, which makes it a root of the XML documents.
+// The abstract elements can't appear in the documents.
+func isRootElement(v *Element, XSDSchema []interface{}) bool {
+	if v.Abstract {
+		return false
+	}
+	typeName := trimNSPrefix(v.Type)
+	for _, ele := range XSDSchema {
+		if complexType, ok := ele.(*ComplexType); ok && complexType.Name == typeName {
+			return true
+		}
+	}
+	return false
+}
+
+// getUnionEnumValues returns the enumerated values of the member types of the
+// union in the order of the members, with the duplicate values removed. It
+// returns nil unless each member type is an enumerated simple type.
+func getUnionEnumValues(v *SimpleType, XSDSchema []interface{}) (values []string) {
+	seen := map[string]bool{}
+	for _, member := range toSortedPairs(v.MemberTypes) {
+		enum := getRestrictionFromSimpleType(member.key, XSDSchema).Enum
+		if len(enum) == 0 {
+			return nil
+		}
+		for _, value := range enum {
+			if !seen[value] {
+				seen[value] = true
+				values = append(values, value)
+			}
+		}
+	}
+	return
+}
+
+// getExtensionBase returns the complex type extended by the complex type, or
+// nil if the type doesn't extend a complex type of the schema.
+func getExtensionBase(v *ComplexType, XSDSchema []interface{}) *ComplexType {
+	if v.Base == "" {
+		return nil
+	}
+	baseName := trimNSPrefix(v.Base)
+	for _, ele := range XSDSchema {
+		if base, ok := ele.(*ComplexType); ok && base != v && base.Name == baseName {
+			return base
+		}
+	}
+	return nil
+}
+
+// getUniqueConstraints returns the unique, key and keyref identity
+// constraints checked by the type by given name.
+func getUniqueConstraints(name string, XSDSchema []interface{}) (uniques []*Unique) {
+	for _, ele := range XSDSchema {
+		if v, ok := ele.(*Unique); ok && v.Type == name {
+			uniques = append(uniques, v)
+		}
+	}
+	return
+}
+
+// kind returns the kind of the identity constraint in the SchemaDump.
+func (v *Unique) kind() string {
+	switch {
+	case v.Refer != "":
+		return KindKeyRef
+	case v.Key:
+		return KindKey
+	}
+	return KindUnique
+}
+
+// getReferredConstraint returns the key or unique identity constraint the
+// keyref constraint refers to, declared in the same scope, or nil if there's
+// none.
+func getReferredConstraint(keyref *Unique, XSDSchema []interface{}) *Unique {
+	for _, unique := range getUniqueConstraints(keyref.Type, XSDSchema) {
+		if unique.Refer == "" && unique.Name == trimNSPrefix(keyref.Refer) {
+			return unique
+		}
+	}
+	return nil
+}
+
+// resolveUniqueSelector resolves the selector of the identity constraint on
+// the complex type by given name into the steps to the selected elements,
+// which may be nested in the elements of the previous steps, or returns the
+// reason the constraint isn't supported.
+func resolveUniqueSelector(typeName string, unique *Unique, XSDSchema []interface{}) ([]uniqueStep, error) {
+	if len(unique.Fields) == 0 {
+		return nil, errors.New("no field")
+	}
+	steps, ok := resolveUniquePath(typeName, unique.Selector, XSDSchema)
+	if !ok || steps[len(steps)-1].Attribute {
+		return nil, fmt.Errorf("unsupported selector %s", unique.Selector)
+	}
+	return steps, nil
+}
+
+// resolveUniquePath resolves the restricted XPath expression of the selector
+// or field of an identity constraint into the steps from the complex type by
+// given name. Only the child and attribute axes are supported, and false is
+// returned if the path can't be resolved.
+func resolveUniquePath(typeName, path string, XSDSchema []interface{}) (steps []uniqueStep, ok bool) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "./")
+	if path == "" || strings.ContainsAny(path, "|*") || strings.Contains(path, "//") || strings.Contains(path, "..") {
+		return nil, false
+	}
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		part = strings.TrimPrefix(part, "child::")
+		if part == "." {
+			continue
+		}
+		var attribute bool
+		if strings.HasPrefix(part, "@") || strings.HasPrefix(part, "attribute::") {
+			if i != len(parts)-1 {
+				return nil, false
+			}
+			attribute = true
+			part = strings.TrimPrefix(strings.TrimPrefix(part, "@"), "attribute::")
+		}
+		step, found := findUniqueStep(typeName, trimNSPrefix(part), attribute, XSDSchema)
+		if !found {
+			return nil, false
+		}
+		steps = append(steps, step)
+		typeName = trimNSPrefix(step.Type)
+	}
+	return steps, len(steps) > 0
+}
+
+// findUniqueStep finds the element or attribute by given name in the complex
+// type or its referenced groups.
+func findUniqueStep(typeName, name string, attribute bool, XSDSchema []interface{}) (uniqueStep, bool) {
+	var elements []Element
+	var attributes []Attribute
+	for _, ele := range XSDSchema {
+		if v, ok := ele.(*ComplexType); ok && v.Name == typeName {
+			elements, attributes = v.Elements, v.Attributes
+			for _, group := range v.Groups {
+				for _, g := range XSDSchema {
+					if v, ok := g.(*Group); ok && v.Name == trimNSPrefix(group.Ref) {
+						elements = append(elements, v.Elements...)
+					}
+				}
+			}
+			break
+		}
+	}
+	if attribute {
+		for _, v := range attributes {
+			if trimNSPrefix(v.Name) == name {
+				return uniqueStep{Name: v.Name, Type: getBasefromSimpleType(trimNSPrefix(v.Type), XSDSchema), Plural: v.Plural, Optional: v.Optional, Attribute: true}, true
+			}
+		}
+		return uniqueStep{}, false
+	}
+	for _, v := range elements {
+		if trimNSPrefix(v.Name) == name {
+			return uniqueStep{Name: v.Name, Type: getBasefromSimpleType(trimNSPrefix(v.Type), XSDSchema), Plural: v.Plural, Optional: v.Optional}, true
+		}
+	}
+	return uniqueStep{}, false
+}
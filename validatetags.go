@@ -0,0 +1,184 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// goValidatePatternTag is the prefix of the tags of the custom validators
+// checking the pattern facets, which are suffixed by the hash of the pattern.
+const goValidatePatternTag = "xsd_pattern_"
+
+// genGoValidateTag generate the validate struct tag appended to the XML
+// struct tag of the field for Go code in validate tags mode, translated from
+// the facets of the restriction to the tags of go-playground/validator. The
+// length facets are translated to len, min and max, the range facets to min,
+// max, gt and lt, the enumeration facets to oneof, and the pattern facets to
+// the custom validators registered by RegisterValidations. The optional
+// fields are skipped if empty, and the repeated fields dive into the items.
+func (gen *CodeGenerator) genGoValidateTag(fieldType string, plural, optional bool, restriction *Restriction) string {
+	if !gen.ValidateTags || restriction == nil {
+		return ""
+	}
+	var tags []string
+	if fieldType == "string" {
+		if restriction.HasLength {
+			tags = append(tags, fmt.Sprintf("len=%d", restriction.Length))
+		} else if restriction.MinLength > 0 && restriction.MinLength == restriction.MaxLength {
+			tags = append(tags, fmt.Sprintf("len=%d", restriction.MinLength))
+		} else {
+			if restriction.MinLength > 0 {
+				tags = append(tags, fmt.Sprintf("min=%d", restriction.MinLength))
+			}
+			if restriction.MaxLength > 0 {
+				tags = append(tags, fmt.Sprintf("max=%d", restriction.MaxLength))
+			}
+		}
+		if oneOf := genGoValidateOneOf(restriction.Enum); oneOf != "" {
+			tags = append(tags, oneOf)
+		}
+		if restriction.Pattern != nil {
+			tags = append(tags, gen.addGoValidatePattern("^(?:"+restriction.Pattern.String()+")$"))
+		}
+	}
+	if gen.isGoNumericType(fieldType) {
+		// The validator doesn't parse the infinite bounds.
+		if min := restriction.minValue(); restriction.HasMin && !min.IsInf() {
+			tag := "min="
+			if restriction.MinExclusive {
+				tag = "gt="
+			}
+			tags = append(tags, tag+genGoNumberLiteral(min))
+		}
+		if max := restriction.maxValue(); restriction.HasMax && !max.IsInf() {
+			tag := "max="
+			if restriction.MaxExclusive {
+				tag = "lt="
+			}
+			tags = append(tags, tag+genGoNumberLiteral(max))
+		}
+		// The oneof validator checks the integers, but not the floats.
+		if values, ok := restriction.numericEnum(); ok && (strings.HasPrefix(fieldType, "int") || strings.HasPrefix(fieldType, "uint")) {
+			var enum []string
+			for _, value := range values {
+				enum = append(enum, genGoNumberLiteral(value))
+			}
+			tags = append(tags, genGoValidateOneOf(enum))
+		}
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+	if plural {
+		tags = append([]string{"dive"}, tags...)
+	}
+	if optional {
+		tags = append([]string{"omitempty"}, tags...)
+	}
+	return fmt.Sprintf(" validate:\"%s\"", strings.Join(tags, ","))
+}
+
+// genGoValidateOneOf generate the oneof tag of the enumeration facets. The
+// values with spaces are quoted, and the commas and the vertical bars
+// separating the tags are escaped. The enumeration with the values which
+// can't be quoted in the struct tag isn't translated.
+func genGoValidateOneOf(enum []string) string {
+	if len(enum) == 0 {
+		return ""
+	}
+	values := make([]string, len(enum))
+	for i, value := range enum {
+		if strings.ContainsAny(value, "'\"`\\") {
+			return ""
+		}
+		value = strings.NewReplacer(",", "0x2C", "|", "0x7C").Replace(value)
+		if value == "" || strings.ContainsAny(value, " \t\n") {
+			value = "'" + value + "'"
+		}
+		values[i] = value
+	}
+	return "oneof=" + strings.Join(values, " ")
+}
+
+// addGoValidatePattern records the pattern checked by a custom validator,
+// and returns the tag of the validator.
+func (gen *CodeGenerator) addGoValidatePattern(pattern string) string {
+	sum := sha256.Sum256([]byte(pattern))
+	tag := goValidatePatternTag + hex.EncodeToString(sum[:4])
+	if gen.validatePatterns == nil {
+		gen.validatePatterns = map[string]string{}
+	}
+	gen.validatePatterns[tag] = pattern
+	return tag
+}
+
+// genGoValidatePatterns generate the init function adding the patterns of
+// the custom validators of the file to the ones registered by
+// RegisterValidations for Go code.
+func (gen *CodeGenerator) genGoValidatePatterns() string {
+	if len(gen.validatePatterns) == 0 {
+		return ""
+	}
+	var tags []string
+	for tag := range gen.validatePatterns {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	code := "\nfunc init() {\n"
+	for _, tag := range tags {
+		code += fmt.Sprintf("\tvalidatePatterns[%q] = %q\n", tag, gen.validatePatterns[tag])
+	}
+	return code + "}\n"
+}
+
+// genGoValidateRegistration writes the RegisterValidations function shared
+// by the generated types of the package, which registers the custom
+// validators of the pattern facets on the validate of go-playground/validator.
+func (gen *CodeGenerator) genGoValidateRegistration(packageName string) error {
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+package %s
+
+import (
+	"regexp"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// validatePatterns holds the patterns of the pattern facets by the tags of
+// their custom validators, which are added by the generated files of the
+// package.
+var validatePatterns = map[string]string{}
+
+// RegisterValidations registers the custom validators of the pattern facets
+// the validate tags of the generated types refer to on the validate.
+func RegisterValidations(validate *validator.Validate) error {
+	for tag, pattern := range validatePatterns {
+		re := regexp.MustCompile(pattern)
+		if err := validate.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			return re.MatchString(fl.Field().String())
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`, gen.fileHeader(), packageName)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "xsd_validate.go"), source)
+}
@@ -0,0 +1,348 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// verifyHarness is the program which decodes the sample of each root element
+// with the generated Go code, validates it and encodes it again.
+const verifyHarness = `package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"xgenverify/schema"
+)
+
+type result struct {
+	Root       string ` + "`json:\"root\"`" + `
+	Output     string ` + "`json:\"output\"`" + `
+	Error      string ` + "`json:\"error\"`" + `
+	Validation string ` + "`json:\"validation\"`" + `
+}
+
+type root interface {
+	ParseXML(data []byte) error
+	ToXML() ([]byte, error)
+}
+
+func roundTrip(name string, v root) (r result) {
+	r.Root = name
+	data, err := ioutil.ReadFile(name + ".xml")
+	if err == nil {
+		err = v.ParseXML(data)
+	}
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+	if validator, ok := v.(interface{ Validate() error }); ok {
+		if err = validator.Validate(); err != nil {
+			r.Validation = err.Error()
+		}
+	}
+	if data, err = v.ToXML(); err != nil {
+		r.Error = err.Error()
+		return
+	}
+	r.Output = string(data)
+	return
+}
+
+func main() {
+	results := []result{
+%s	}
+	if err := json.NewEncoder(os.Stdout).Encode(results); err != nil {
+		os.Exit(1)
+	}
+}
+`
+
+// VerifyReport is the result of the round trip check of the schema, Roots
+// holds the names of the checked root elements, and Issues holds the
+// problems found.
+type VerifyReport struct {
+	Roots  []string      `json:"roots"`
+	Issues []VerifyIssue `json:"issues"`
+}
+
+// VerifyIssue is a problem of the round trip of the sample instance of a
+// root element, Path holds the path of the element in the sample.
+type VerifyIssue struct {
+	Root    string `json:"root"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// verifyNode is the element of a document compared by the round trip check,
+// the attributes declaring the namespaces are left out.
+type verifyNode struct {
+	name     xml.Name
+	attrs    map[xml.Name]string
+	children []*verifyNode
+	text     string
+}
+
+// verifyResult is the output of the harness for a root element.
+type verifyResult struct {
+	Root       string `json:"root"`
+	Output     string `json:"output"`
+	Error      string `json:"error"`
+	Validation string `json:"validation"`
+}
+
+// Verify checks the round trip of the Go code generated for the schema. For
+// each root element, a sample instance respecting the facets is generated by
+// the faker with given seed, decoded by the generated root wrapper, validated
+// by the generated validation code and encoded again. The differences between
+// the sample and the encoded document, such as the elements written with the
+// wrong names or without their namespace, and the values which don't pass the
+// validation are reported as issues, which flag the bugs of the generator.
+// The go command is required to build the generated code.
+func (opt *Options) Verify(seed int64) (*VerifyReport, error) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return nil, fmt.Errorf("the go command is required to verify the generated code: %v", err)
+	}
+	dir, err := ioutil.TempDir("", "xgen-verify-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	opt.Lang, opt.Package = "Go", "schema"
+	opt.InputDir, opt.OutputDir = filepath.Dir(opt.FilePath), filepath.Join(dir, "schema")
+	opt.RootWrappers = true
+	if opt.Validation == "" {
+		opt.Validation = ValidationMethod
+	}
+	artifacts, err := opt.Generate()
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(opt.OutputDir, 0755); err != nil {
+		return nil, err
+	}
+	for name, data := range artifacts {
+		if strings.HasSuffix(name, ".go") {
+			if err = ioutil.WriteFile(filepath.Join(opt.OutputDir, filepath.Base(name)), data, 0644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	report := &VerifyReport{Roots: []string{}, Issues: []VerifyIssue{}}
+	samples := map[string][]byte{}
+	faker := NewFaker(opt.ProtoTree, seed)
+	var roots string
+	for _, ele := range opt.ProtoTree {
+		v, ok := ele.(*Element)
+		if !ok || !isRootElement(v, opt.ProtoTree) || samples[v.Name] != nil {
+			continue
+		}
+		node, err := faker.node(v.Name)
+		if err != nil {
+			return nil, err
+		}
+		if opt.TargetNamespace != "" {
+			node.attrs = append([]xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: opt.TargetNamespace}}, node.attrs...)
+		}
+		if samples[v.Name], err = node.xml(); err != nil {
+			return nil, err
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, v.Name+".xml"), samples[v.Name], 0644); err != nil {
+			return nil, err
+		}
+		report.Roots = append(report.Roots, v.Name)
+		roots += fmt.Sprintf("\t\troundTrip(%q, &schema.%sRoot{}),\n", v.Name, genGoFieldName(v.Name, false))
+	}
+	if len(report.Roots) == 0 {
+		return report, nil
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module xgenverify\n\ngo 1.15\n"), 0644); err != nil {
+		return nil, err
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(fmt.Sprintf(verifyHarness, roots)), 0644); err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir, cmd.Stdout, cmd.Stderr = dir, &stdout, &stderr
+	cmd.Env = append(os.Environ(), "GOWORK=off")
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("build the generated code: %v\n%s", err, stderr.String())
+	}
+	var results []verifyResult
+	if err = json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		report.Issues = append(report.Issues, verifyRoundTrip(result, samples[result.Root])...)
+	}
+	return report, nil
+}
+
+// verifyRoundTrip returns the issues of the round trip of the sample by given
+// output of the harness.
+func verifyRoundTrip(result verifyResult, sample []byte) (issues []VerifyIssue) {
+	if result.Error != "" {
+		return []VerifyIssue{{Root: result.Root, Message: result.Error}}
+	}
+	if result.Validation != "" {
+		issues = append(issues, VerifyIssue{Root: result.Root, Message: fmt.Sprintf("the sample fails the validation: %s", result.Validation)})
+	}
+	want, err := parseVerifyNode(sample)
+	if err != nil {
+		return append(issues, VerifyIssue{Root: result.Root, Message: err.Error()})
+	}
+	got, err := parseVerifyNode([]byte(result.Output))
+	if err != nil {
+		return append(issues, VerifyIssue{Root: result.Root, Message: fmt.Sprintf("the encoded document isn't well-formed: %v", err)})
+	}
+	return append(issues, compareVerifyNode(result.Root, "/"+want.name.Local, want, got)...)
+}
+
+// parseVerifyNode parses the root element of the XML document.
+func parseVerifyNode(data []byte) (*verifyNode, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*verifyNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("the document has no root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			node := &verifyNode{name: element.Name, attrs: map[xml.Name]string{}}
+			for _, attr := range element.Attr {
+				if attr.Name.Space != "xmlns" && !(attr.Name.Space == "" && attr.Name.Local == "xmlns") {
+					node.attrs[attr.Name] = attr.Value
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, node)
+			}
+			stack = append(stack, node)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(element)
+			}
+		case xml.EndElement:
+			node := stack[len(stack)-1]
+			node.text = strings.TrimSpace(node.text)
+			if stack = stack[:len(stack)-1]; len(stack) == 0 {
+				return node, nil
+			}
+		}
+	}
+}
+
+// compareVerifyNode returns the differences of the encoded element from the
+// element of the sample at the path. The children are matched in order by
+// their local name.
+func compareVerifyNode(root, path string, want, got *verifyNode) (issues []VerifyIssue) {
+	issue := func(format string, a ...interface{}) {
+		issues = append(issues, VerifyIssue{Root: root, Path: path, Message: fmt.Sprintf(format, a...)})
+	}
+	if want.name != got.name {
+		issue("element %s is encoded as %s", formatVerifyName(want.name), formatVerifyName(got.name))
+	}
+	var names []xml.Name
+	for name := range want.attrs {
+		names = append(names, name)
+	}
+	for name := range got.attrs {
+		if _, ok := want.attrs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return formatVerifyName(names[i]) < formatVerifyName(names[j]) })
+	for _, name := range names {
+		wantValue, wanted := want.attrs[name]
+		gotValue, ok := got.attrs[name]
+		switch {
+		case !ok:
+			issue("attribute %s is lost", formatVerifyName(name))
+		case !wanted:
+			issue("unexpected attribute %s", formatVerifyName(name))
+		case !equalVerifyValue(wantValue, gotValue):
+			issue("attribute %s is encoded as %q instead of %q", formatVerifyName(name), gotValue, wantValue)
+		}
+	}
+	if !equalVerifyValue(want.text, got.text) {
+		issue("value is encoded as %q instead of %q", got.text, want.text)
+	}
+	matched := make([]bool, len(got.children))
+	counts := map[string]int{}
+	for _, child := range want.children {
+		counts[child.name.Local]++
+		childPath := fmt.Sprintf("%s/%s[%d]", path, child.name.Local, counts[child.name.Local])
+		found := false
+		for i, candidate := range got.children {
+			if !matched[i] && candidate.name.Local == child.name.Local {
+				matched[i], found = true, true
+				issues = append(issues, compareVerifyNode(root, childPath, child, candidate)...)
+				break
+			}
+		}
+		if !found {
+			issues = append(issues, VerifyIssue{Root: root, Path: childPath, Message: fmt.Sprintf("element %s is lost", formatVerifyName(child.name))})
+		}
+	}
+	for i, child := range got.children {
+		if !matched[i] {
+			issue("unexpected element %s", formatVerifyName(child.name))
+		}
+	}
+	return
+}
+
+// equalVerifyValue returns if the lexical values are equal, the numbers and
+// the booleans are compared by their value, as the encoding may change their
+// lexical form.
+func equalVerifyValue(want, got string) bool {
+	want, got = strings.TrimSpace(want), strings.TrimSpace(got)
+	if want == got {
+		return true
+	}
+	if a, err := strconv.ParseFloat(want, 64); err == nil {
+		b, err := strconv.ParseFloat(got, 64)
+		return err == nil && a == b
+	}
+	if a, err := strconv.ParseBool(want); err == nil {
+		b, err := strconv.ParseBool(got)
+		return err == nil && a == b
+	}
+	return false
+}
+
+// formatVerifyName returns the name in the {namespace}local form.
+func formatVerifyName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return "{" + name.Space + "}" + name.Local
+}
@@ -0,0 +1,126 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// namespaceVersionRegexp matches the last segment of the versioned target
+// namespaces, such as the pain.001.001.09 of the ISO 20022 messages, by the
+// message identifier and the version.
+var namespaceVersionRegexp = regexp.MustCompile(`(?:^|[:/])([A-Za-z][A-Za-z0-9]*(?:\.[0-9]+)*)\.([0-9]+)$`)
+
+// getNamespaceVersion returns the message identifier and the version of the
+// versioned target namespace, ok is false if the namespace isn't versioned.
+func getNamespaceVersion(namespace string) (message, version string, ok bool) {
+	matches := namespaceVersionRegexp.FindStringSubmatch(namespace)
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1], matches[2], true
+}
+
+// getVersionPackage returns the Go package name for the version of the
+// target namespace, such as pain_001_001_09, or an empty string if the
+// namespace isn't versioned.
+func getVersionPackage(namespace string) string {
+	message, version, ok := getNamespaceVersion(namespace)
+	if !ok {
+		return ""
+	}
+	return strings.ToLower(strings.Replace(message, ".", "_", -1) + "_" + version)
+}
+
+// versionedPackage holds the declarations generated in the Go package of a
+// namespace version.
+type versionedPackage struct {
+	Name       string
+	Package    string
+	ImportPath string
+	Dir        string
+	Version    int
+	ProtoTree  []interface{}
+	Artifacts  map[string][]byte
+
+	builtInTypes map[string]bool
+}
+
+// genGoVersionConversions generate the conversion helpers between the
+// structs shared by the consecutive versions of the messages, among the
+// schemas generated into the versioned Go packages. The helpers are
+// generated into the package of the newer version for both directions, and
+// leave TODO markers for the fields which don't match between the versions.
+func genGoVersionConversions(schemas []*Options) error {
+	packages := map[string]*versionedPackage{}
+	messages := map[string][]*versionedPackage{}
+	for _, opt := range schemas {
+		if opt.Lang != "Go" || opt.VersionedPackages == "" {
+			continue
+		}
+		pkgName := getVersionPackage(opt.TargetNamespace)
+		if pkgName == "" {
+			continue
+		}
+		pkg, ok := packages[pkgName]
+		if !ok {
+			message, version, _ := getNamespaceVersion(opt.TargetNamespace)
+			number, _ := strconv.Atoi(version)
+			pkg = &versionedPackage{
+				Name:       message + "." + version,
+				Package:    pkgName,
+				ImportPath: strings.TrimSuffix(opt.VersionedPackages, "/") + "/" + pkgName,
+				Dir:        filepath.Join(opt.OutputDir, pkgName),
+				Version:    number,
+				Artifacts:  opt.Artifacts,
+
+				builtInTypes: map[string]bool{},
+			}
+			packages[pkgName] = pkg
+			messages[message] = append(messages[message], pkg)
+		}
+		pkg.ProtoTree = append(pkg.ProtoTree, opt.ProtoTree...)
+		for name := range opt.builtInTypes {
+			pkg.builtInTypes[name] = true
+		}
+	}
+	for _, versions := range messages {
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+		for i := 1; i < len(versions); i++ {
+			if err := genGoVersionConversionStubs(versions[i-1], versions[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// genGoVersionConversionStubs generate the conversion helpers between the
+// structs shared by the older and the newer version into the package of the
+// newer version. The existing helpers file is kept, since the TODO markers
+// in it are completed by hand.
+func genGoVersionConversionStubs(older, newer *versionedPackage) error {
+	file := filepath.Join(newer.Dir, "convert_"+older.Package+".go")
+	if newer.Artifacts == nil {
+		if _, err := os.Stat(file); err == nil {
+			return nil
+		}
+	}
+	gen := &CodeGenerator{Lang: "Go", Package: newer.Package, Artifacts: newer.Artifacts, builtInTypes: newer.builtInTypes}
+	source, err := genGoMapping(gen, newer.Package, older.Package, older.ImportPath, older.Name, older.ProtoTree, newer.ProtoTree)
+	if err != nil || source == nil {
+		return err
+	}
+	return gen.WriteFile(file, source)
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// visitorType holds the name and the field content of a struct visited by
+// the generated visitor.
+type visitorType struct {
+	name, content string
+}
+
+// addVisitorType records the struct by given name and field content, so the
+// visitor and the accept methods are generated once all the structs are
+// known.
+func (gen *CodeGenerator) addVisitorType(name, content string) {
+	if gen.Visitor {
+		gen.visitorTypes = append(gen.visitorTypes, visitorType{name, content})
+	}
+}
+
+// isVisitorType returns whether a struct by given name is visited by the
+// generated visitor.
+func (gen *CodeGenerator) isVisitorType(name string) bool {
+	for _, t := range gen.visitorTypes {
+		if t.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// genGoVisitor generate the Visitor interface with a visit method per struct
+// for Go code, the BaseVisitor doing nothing for the visitors which visit
+// some of the structs only, and the Accept method of each struct calling the
+// visitor on the struct and on the members of the struct.
+func (gen *CodeGenerator) genGoVisitor() {
+	if len(gen.visitorTypes) == 0 {
+		return
+	}
+	var methods, base, accept string
+	for _, t := range gen.visitorTypes {
+		methods += fmt.Sprintf("\tVisit%s(v *%s)\n", t.name, t.name)
+		base += fmt.Sprintf("\n// Visit%s does nothing.\nfunc (BaseVisitor) Visit%s(*%s) {}\n", t.name, t.name, t.name)
+		var members string
+		for _, match := range goStructFieldRegexp.FindAllStringSubmatch(t.content, -1) {
+			fieldName, fieldType := match[1], match[2]
+			if fieldType == "" {
+				// The embedded struct is named after its type.
+				fieldName, fieldType = strings.TrimPrefix(fieldName, "*"), fieldName
+			}
+			if !gen.isVisitorType(strings.TrimLeft(fieldType, "[]*")) {
+				continue
+			}
+			if strings.HasPrefix(fieldType, "[]") {
+				members += fmt.Sprintf("\tfor i := range v.%s {\n\t\tv.%s[i].Accept(visitor)\n\t}\n", fieldName, fieldName)
+				continue
+			}
+			members += fmt.Sprintf("\tv.%s.Accept(visitor)\n", fieldName)
+		}
+		accept += fmt.Sprintf("\n// Accept calls the visitor on the %s and on its members.\nfunc (v *%s) Accept(visitor Visitor) {\n\tif v == nil {\n\t\treturn\n\t}\n\tvisitor.Visit%s(v)\n%s}\n", t.name, t.name, t.name, members)
+	}
+	gen.Field += fmt.Sprintf("%stype Visitor interface {\n%s}\n", gen.genComment("Visitor", "the visitor of the structs of the schema, the Accept method of each struct calls the visitor on the struct and on the members of the struct."), methods)
+	gen.Field += fmt.Sprintf("%stype BaseVisitor struct{}\n%s%s", gen.genComment("BaseVisitor", "the visitor doing nothing, which is embedded by the visitors implementing the visit methods of some of the structs only."), base, accept)
+}
+
+// genRustVisitor generate the Visitor and VisitorMut traits with a visit
+// method per struct for Rust code, whose default implementations do nothing,
+// and the Accept and AcceptMut traits implemented by each struct calling the
+// visitor on the struct and on the members of the struct. The options,
+// vectors and boxes of the structs accept the visitor too.
+func (gen *CodeGenerator) genRustVisitor() {
+	if len(gen.visitorTypes) == 0 {
+		return
+	}
+	var methods, methodsMut, accept string
+	for _, t := range gen.visitorTypes {
+		name := ToSnakeCase(t.name)
+		methods += fmt.Sprintf("\tfn visit_%s(&mut self, _v: &%s) {}\n", name, t.name)
+		methodsMut += fmt.Sprintf("\tfn visit_%s_mut(&mut self, _v: &mut %s) {}\n", name, t.name)
+		var members, membersMut string
+		for _, match := range rustPublicFieldRegexp.FindAllStringSubmatch(t.content, -1) {
+			fieldName, fieldType := match[1], match[2]
+			for _, wrapper := range []string{"Option<", "Vec<", "Box<"} {
+				for strings.HasPrefix(fieldType, wrapper) {
+					fieldType = strings.TrimSuffix(strings.TrimPrefix(fieldType, wrapper), ">")
+				}
+			}
+			if !gen.isVisitorType(fieldType) {
+				continue
+			}
+			members += fmt.Sprintf("\t\tself.%s.accept(visitor);\n", fieldName)
+			membersMut += fmt.Sprintf("\t\tself.%s.accept_mut(visitor);\n", fieldName)
+		}
+		accept += fmt.Sprintf("\nimpl Accept for %s {\n\tfn accept<V: Visitor + ?Sized>(&self, visitor: &mut V) {\n\t\tvisitor.visit_%s(self);\n%s\t}\n}\n", t.name, name, members)
+		accept += fmt.Sprintf("\nimpl AcceptMut for %s {\n\tfn accept_mut<V: VisitorMut + ?Sized>(&mut self, visitor: &mut V) {\n\t\tvisitor.visit_%s_mut(self);\n%s\t}\n}\n", t.name, name, membersMut)
+	}
+	gen.Field += fmt.Sprintf("\n%spub trait Visitor {\n%s}\n", gen.genComment("Visitor", "the visitor of the structs of the schema, the visit methods do nothing unless they are implemented."), methods)
+	gen.Field += fmt.Sprintf("\n%spub trait VisitorMut {\n%s}\n", gen.genComment("VisitorMut", "the visitor of the structs of the schema, which may modify the structs."), methodsMut)
+	gen.Field += fmt.Sprintf("\n%spub trait Accept {\n\tfn accept<V: Visitor + ?Sized>(&self, visitor: &mut V);\n}\n", gen.genComment("Accept", "the trait of the structs calling the visitor on the struct and on the members of the struct."))
+	gen.Field += fmt.Sprintf("\n%spub trait AcceptMut {\n\tfn accept_mut<V: VisitorMut + ?Sized>(&mut self, visitor: &mut V);\n}\n", gen.genComment("AcceptMut", "the trait of the structs calling the mutable visitor on the struct and on the members of the struct."))
+	for _, wrapper := range []struct{ typ, each, eachMut string }{
+		{"Option<T>", "if let Some(v) = self {\n\t\t\tv.accept(visitor);\n\t\t}", "if let Some(v) = self {\n\t\t\tv.accept_mut(visitor);\n\t\t}"},
+		{"Vec<T>", "for v in self {\n\t\t\tv.accept(visitor);\n\t\t}", "for v in self {\n\t\t\tv.accept_mut(visitor);\n\t\t}"},
+		{"Box<T>", "(**self).accept(visitor);", "(**self).accept_mut(visitor);"},
+	} {
+		gen.Field += fmt.Sprintf("\nimpl<T: Accept> Accept for %s {\n\tfn accept<V: Visitor + ?Sized>(&self, visitor: &mut V) {\n\t\t%s\n\t}\n}\n", wrapper.typ, wrapper.each)
+		gen.Field += fmt.Sprintf("\nimpl<T: AcceptMut> AcceptMut for %s {\n\tfn accept_mut<V: VisitorMut + ?Sized>(&mut self, visitor: &mut V) {\n\t\t%s\n\t}\n}\n", wrapper.typ, wrapper.eachMut)
+	}
+	gen.Field += accept
+}
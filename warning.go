@@ -0,0 +1,60 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "fmt"
+
+// Warning describes a warning of the parse, such as the pattern facet whose
+// validation is skipped, by the schema file and the line of the declaration
+// it's about. The line is 0 if it isn't known, such as for the warnings of
+// the generated code.
+type Warning struct {
+	File    string
+	Line    int
+	Message string
+}
+
+// String returns the message of the warning prefixed by its location.
+func (w Warning) String() string {
+	if w.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", w.File, w.Line, w.Message)
+	}
+	return fmt.Sprintf("%s: %s", w.File, w.Message)
+}
+
+// warn adds the warning of the schema of the options, at the line of the
+// element being parsed if any.
+func (opt *Options) warn(message string) {
+	warning := Warning{File: opt.FilePath, Message: message}
+	if opt.inputLine != nil {
+		warning.Line = opt.inputLine()
+	}
+	opt.addWarnings([]Warning{warning})
+}
+
+// addWarnings adds the warnings to the options, skipping the ones added
+// already, so the warnings of a schema imported or included by many schemas
+// are reported once.
+func (opt *Options) addWarnings(warnings []Warning) {
+	for _, warning := range warnings {
+		if !hasWarning(opt.Warnings, warning) {
+			opt.Warnings = append(opt.Warnings, warning)
+		}
+	}
+}
+
+// hasWarning returns true if the warnings hold given warning.
+func hasWarning(warnings []Warning, warning Warning) bool {
+	for _, w := range warnings {
+		if w == warning {
+			return true
+		}
+	}
+	return false
+}
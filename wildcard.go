@@ -0,0 +1,152 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+)
+
+// genGoWildcardFields generate the catch-all fields of the element and the
+// attribute wildcards for Go code, which keep the elements and attributes
+// not declared by the schema as is, so they survive the round trip.
+func (gen *CodeGenerator) genGoWildcardFields(anyElement, anyAttribute bool) (content string) {
+	if anyElement {
+		content += "\tAny\t[]XSDAnyElement\t`xml:\",any\"`\n"
+	}
+	if anyAttribute {
+		gen.ImportEncodingXML = true
+		content += "\tAnyAttr\t[]xml.Attr\t`xml:\",any,attr\"`\n"
+	}
+	return
+}
+
+// xmlnsNamespace is the namespace of the namespace declarations decoded by
+// encoding/xml, which the attribute wildcards don't restrict, as the
+// attributes of the schema instance namespace.
+const xmlnsNamespace = "xmlns"
+
+// genGoWildcardValidation generate validation code of the namespaces of the
+// attributes matched by the attribute wildcard for Go code, which fails on
+// the attributes outside the namespace constraint of the wildcard.
+func (gen *CodeGenerator) genGoWildcardValidation(anyAttribute bool, namespace NamespaceConstraint) string {
+	if gen.Validation == ValidationNone || !anyAttribute || len(namespace.Namespaces) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(namespace.Namespaces))
+	for i, ns := range namespace.Namespaces {
+		quoted[i] = fmt.Sprintf("%q", ns)
+	}
+	check := fmt.Sprintf("case %s:\ndefault:\n", strings.Join(quoted, ", "))
+	if namespace.Not {
+		check = fmt.Sprintf("case %s:\n", strings.Join(quoted, ", "))
+	}
+	return fmt.Sprintf("for _, attr := range v.AnyAttr {\nif attr.Name.Space == %q || attr.Name.Space == \"\" && attr.Name.Local == %q || attr.Name.Space == %q {\ncontinue\n}\nswitch attr.Name.Space {\n%sreturn fmt.Errorf(\"AnyAttr %%s in namespace %%q is not allowed by the attribute wildcard\", attr.Name.Local, attr.Name.Space)\n}\n}\n",
+		xmlnsNamespace, xmlnsNamespace, xsiNamespace, check)
+}
+
+// genGoAnyElement writes the type of the elements matched by the element
+// wildcards shared by the generated types of the package.
+func (gen *CodeGenerator) genGoAnyElement(packageName string) error {
+	source, err := format.Source([]byte(fmt.Sprintf(`%s
+
+package %s
+
+import "encoding/xml"
+
+// XSDAnyElement is an element matched by the xs:any wildcard, which isn't
+// declared by the schema. The name, the attributes and the inner XML of the
+// element are kept as is, so it's written back unchanged.
+type XSDAnyElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `+"`xml:\",any,attr\"`"+`
+	InnerXML string     `+"`xml:\",innerxml\"`"+`
+}
+`, gen.fileHeader(), packageName)))
+	if err != nil {
+		return err
+	}
+	return gen.WriteFile(filepath.Join(filepath.Dir(gen.File), "xsd_any.go"), source)
+}
+
+// rustWildcardField is the catch-all field of the element and the attribute
+// wildcards for Rust code, flattened by serde to hold the elements and the
+// attributes which aren't fields of the struct.
+const rustWildcardField = "\t#[serde(flatten)]\n\tpub any: XsdAnyContent,\n"
+
+// genRustWildcardField generate the catch-all field of the wildcards for
+// Rust code, unless the fields of the struct have one already, such as the
+// fields of the group mixins inlined in the struct.
+func genRustWildcardField(content string, wildcard bool) string {
+	if !wildcard || strings.Contains(content, rustWildcardField) {
+		return ""
+	}
+	return rustWildcardField
+}
+
+// genRustAny generate the type of the content matched by the wildcards for
+// Rust code, which is deserialized from and serialized to any content as
+// is.
+func (gen *CodeGenerator) genRustAny() string {
+	return fmt.Sprintf(`
+%s#[derive(Debug, PartialEq, Clone, Serialize, Deserialize)]
+#[serde(untagged)]
+pub enum XsdAny {
+	Text(String),
+	List(Vec<XsdAny>),
+	Element(std::collections::BTreeMap<String, XsdAny>),
+}
+%spub type XsdAnyContent = std::collections::BTreeMap<String, XsdAny>;
+`, gen.genComment("XsdAny", "the content of an element or an attribute matched by the xs:any and xs:anyAttribute wildcards, which isn't declared by the schema, and is kept as is, so it's written back unchanged."), gen.genComment("XsdAnyContent", "the elements and the attributes matched by the wildcards of the struct, keyed by their names."))
+}
+
+// genTypeScriptWildcardField generate the index signature of the wildcards
+// for TypeScript code, typing the elements and the attributes not declared
+// by the schema, which are parsed and serialized as the other members.
+func genTypeScriptWildcardField(wildcard bool) string {
+	if !wildcard {
+		return ""
+	}
+	return "\t[name: string]: unknown;\n"
+}
+
+// The catch-all fields of the element and the attribute wildcards for Java
+// code, bound by JAXB to the elements and the attributes which aren't fields
+// of the class.
+const (
+	javaAnyElementField   = "\t@XmlAnyElement(lax = true)\n\tprotected List<Object> any;\n"
+	javaAnyAttributeField = "\t@XmlAnyAttribute\n\tprotected Map<QName, String> anyAttr;\n"
+)
+
+// genJavaWildcardFields generate the catch-all fields of the element and the
+// attribute wildcards for Java code, unless the fields of the class have
+// them already.
+func genJavaWildcardFields(content string, anyElement, anyAttribute bool) (fields string) {
+	if anyElement && !strings.Contains(content, javaAnyElementField) {
+		fields += javaAnyElementField
+	}
+	if anyAttribute && !strings.Contains(content, javaAnyAttributeField) {
+		fields += javaAnyAttributeField
+	}
+	return
+}
+
+// joinWildcardFields joins the fields of the mixin inlined in the struct or
+// the class to its fields, dropping the catch-all fields of the wildcards it
+// has already, since the wildcards of several mixins share one field.
+func joinWildcardFields(content, fields string, wildcards ...string) string {
+	for _, wildcard := range wildcards {
+		if strings.Contains(content, wildcard) {
+			fields = strings.Replace(fields, wildcard, "", 1)
+		}
+	}
+	return content + fields
+}
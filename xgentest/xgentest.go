@@ -0,0 +1,286 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+// Package xgentest runs the parser of xgen over the test suites in the layout
+// of the W3C XML Schema Test Suite, and reports the results of the schema
+// tests by the constructs of XML schema their schema documents use, so the
+// coverage of the standard can be tracked over time.
+//
+// The suite is given by its testSuite file, such as suite.xml, referencing
+// the testSet files, or by a testSet file. The schema test passes if the
+// schema documents are parsed, and the code is generated in the language of
+// the runner, exactly when the test expects the schema to be valid. The
+// instance tests are left out, since xgen doesn't validate the instances.
+package xgentest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/xuri/xgen"
+)
+
+// xsdNamespace is the namespace of the elements of XML schema.
+const xsdNamespace = "http://www.w3.org/2001/XMLSchema"
+
+// The expected validity of the schema tests.
+const (
+	Valid         = "valid"
+	Invalid       = "invalid"
+	Indeterminate = "indeterminate"
+)
+
+// Runner runs the schema tests of the test suites.
+type Runner struct {
+	// Lang is the language of the code generated for the schema of each
+	// test, the schemas are only parsed if it is empty.
+	Lang string
+	// Version is the version of XML schema the expected validity is taken
+	// for, such as 1.0 or 1.1, the expected validity without version
+	// applies to any.
+	Version string
+}
+
+// Result is the result of a schema test.
+type Result struct {
+	Set        string   `json:"set"`
+	Group      string   `json:"group"`
+	Name       string   `json:"name"`
+	Schemas    []string `json:"schemas"`
+	Constructs []string `json:"constructs"`
+	Expected   string   `json:"expected"`
+	Actual     string   `json:"actual"`
+	Passed     bool     `json:"passed"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Coverage holds the numbers of the passed and failed schema tests using a
+// construct of XML schema, named as the element of the construct, such as
+// complexType or key.
+type Coverage struct {
+	Construct string `json:"construct"`
+	Passed    int    `json:"passed"`
+	Failed    int    `json:"failed"`
+}
+
+// Report holds the results of the schema tests of a test suite, with the
+// tests skipped by their indeterminate validity left out.
+type Report struct {
+	Suite   string   `json:"suite"`
+	Results []Result `json:"results"`
+}
+
+// testSuite is the testSuite file of the test suite layout.
+type testSuite struct {
+	XMLName     xml.Name `xml:"testSuite"`
+	TestSetRefs []struct {
+		Href string `xml:"http://www.w3.org/1999/xlink href,attr"`
+	} `xml:"testSetRef"`
+}
+
+// testSet is the testSet file of the test suite layout.
+type testSet struct {
+	XMLName    xml.Name `xml:"testSet"`
+	Name       string   `xml:"name,attr"`
+	TestGroups []struct {
+		Name        string `xml:"name,attr"`
+		SchemaTests []struct {
+			Name            string `xml:"name,attr"`
+			SchemaDocuments []struct {
+				Href string `xml:"http://www.w3.org/1999/xlink href,attr"`
+			} `xml:"schemaDocument"`
+			Expected []struct {
+				Validity string `xml:"validity,attr"`
+				Version  string `xml:"version,attr"`
+			} `xml:"expected"`
+		} `xml:"schemaTest"`
+	} `xml:"testGroup"`
+}
+
+// Run runs the schema tests of the test suite by given testSuite or testSet
+// file, and returns the report of their results in the order of the suite.
+func (r *Runner) Run(suite string) (*Report, error) {
+	data, err := ioutil.ReadFile(suite)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Suite: suite}
+	var root struct{ XMLName xml.Name }
+	if err = xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local == "testSet" {
+		return report, r.runTestSet(report, suite, data)
+	}
+	var ts testSuite
+	if err = xml.Unmarshal(data, &ts); err != nil {
+		return nil, fmt.Errorf("%s: %v", suite, err)
+	}
+	for _, ref := range ts.TestSetRefs {
+		name := filepath.Join(filepath.Dir(suite), filepath.FromSlash(ref.Href))
+		if data, err = ioutil.ReadFile(name); err != nil {
+			return nil, err
+		}
+		if err = r.runTestSet(report, name, data); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// runTestSet runs the schema tests of the testSet file by given name and
+// content, and adds their results to the report.
+func (r *Runner) runTestSet(report *Report, name string, data []byte) error {
+	var set testSet
+	if err := xml.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	for _, group := range set.TestGroups {
+		for _, test := range group.SchemaTests {
+			result := Result{Set: set.Name, Group: group.Name, Name: test.Name}
+			for _, expected := range test.Expected {
+				if expected.Version == "" || expected.Version == r.Version {
+					result.Expected = expected.Validity
+				}
+			}
+			if result.Expected != Valid && result.Expected != Invalid {
+				continue
+			}
+			for _, doc := range test.SchemaDocuments {
+				result.Schemas = append(result.Schemas, filepath.Join(filepath.Dir(name), filepath.FromSlash(doc.Href)))
+			}
+			r.runSchemaTest(&result)
+			report.Results = append(report.Results, result)
+		}
+	}
+	return nil
+}
+
+// runSchemaTest parses the schema documents of the test, and records the
+// constructs they use and the validity xgen finds.
+func (r *Runner) runSchemaTest(result *Result) {
+	constructs := map[string]bool{}
+	result.Actual = Valid
+	for _, schema := range result.Schemas {
+		err := getConstructs(schema, constructs)
+		if err == nil {
+			err = r.parse(schema)
+		}
+		if err != nil {
+			result.Actual, result.Error = Invalid, err.Error()
+			break
+		}
+	}
+	for construct := range constructs {
+		result.Constructs = append(result.Constructs, construct)
+	}
+	sort.Strings(result.Constructs)
+	result.Passed = result.Actual == result.Expected
+}
+
+// parse parses the schema document by given name, and generates the code in
+// the language of the runner into a temporary directory. The panics of the
+// parser are returned as the errors.
+func (r *Runner) parse(schema string) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	opt := &xgen.Options{
+		FilePath:            schema,
+		InputDir:            filepath.Dir(schema),
+		Lang:                r.Lang,
+		Extract:             r.Lang == "",
+		IncludeMap:          make(map[string]bool),
+		LocalNameNSMap:      make(map[string]string),
+		NSSchemaLocationMap: make(map[string]string),
+		ParseFileList:       make(map[string]bool),
+		ParseFileMap:        make(map[string][]interface{}),
+		ProtoTree:           make([]interface{}, 0),
+		RemoteSchema:        make(map[string][]byte),
+	}
+	if !opt.Extract {
+		if opt.OutputDir, err = ioutil.TempDir("", "xgentest-*"); err != nil {
+			return
+		}
+		defer os.RemoveAll(opt.OutputDir)
+	}
+	return xgen.NewParser(opt).Parse()
+}
+
+// getConstructs adds the local names of the elements of XML schema in the
+// schema document by given name to the constructs.
+func getConstructs(schema string, constructs map[string]bool) error {
+	f, err := os.Open(schema)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	decoder := xml.NewDecoder(f)
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if ele, ok := token.(xml.StartElement); ok && ele.Name.Space == xsdNamespace {
+			constructs[ele.Name.Local] = true
+		}
+	}
+}
+
+// Passed returns the number of the passed schema tests.
+func (r *Report) Passed() (passed int) {
+	for _, result := range r.Results {
+		if result.Passed {
+			passed++
+		}
+	}
+	return
+}
+
+// Failed returns the results of the failed schema tests.
+func (r *Report) Failed() (failed []Result) {
+	for _, result := range r.Results {
+		if !result.Passed {
+			failed = append(failed, result)
+		}
+	}
+	return
+}
+
+// Coverage returns the numbers of the passed and failed schema tests by the
+// constructs of XML schema they use, ordered by construct.
+func (r *Report) Coverage() []Coverage {
+	byConstruct := map[string]*Coverage{}
+	for _, result := range r.Results {
+		for _, construct := range result.Constructs {
+			coverage, ok := byConstruct[construct]
+			if !ok {
+				coverage = &Coverage{Construct: construct}
+				byConstruct[construct] = coverage
+			}
+			if result.Passed {
+				coverage.Passed++
+			} else {
+				coverage.Failed++
+			}
+		}
+	}
+	coverages := make([]Coverage, 0, len(byConstruct))
+	for _, coverage := range byConstruct {
+		coverages = append(coverages, *coverage)
+	}
+	sort.Slice(coverages, func(i, j int) bool { return coverages[i].Construct < coverages[j].Construct })
+	return coverages
+}
@@ -0,0 +1,157 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package xgentest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSuite writes the files of a test suite into a temporary directory,
+// and returns the path of the directory.
+func writeSuite(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "xgentest-*")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for name, content := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.NoError(t, ioutil.WriteFile(name, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestRun(t *testing.T) {
+	dir := writeSuite(t, map[string]string{
+		"suite.xml": `<testSuite xmlns="http://www.w3.org/XML/2004/xml-schema-test-suite/" xmlns:xlink="http://www.w3.org/1999/xlink" name="xgen">
+  <testSetRef xlink:type="locator" xlink:href="meta/types.testSet"/>
+</testSuite>`,
+		"meta/types.testSet": `<testSet xmlns="http://www.w3.org/XML/2004/xml-schema-test-suite/" xmlns:xlink="http://www.w3.org/1999/xlink" name="types">
+  <testGroup name="ctA001">
+    <schemaTest name="ctA001.v">
+      <schemaDocument xlink:href="../data/ctA001.xsd"/>
+      <expected validity="valid"/>
+    </schemaTest>
+    <instanceTest name="ctA001.i">
+      <instanceDocument xlink:href="../data/ctA001.xml"/>
+      <expected validity="valid"/>
+    </instanceTest>
+  </testGroup>
+  <testGroup name="stA001">
+    <schemaTest name="stA001.i">
+      <schemaDocument xlink:href="../data/stA001.xsd"/>
+      <expected validity="invalid"/>
+    </schemaTest>
+  </testGroup>
+  <testGroup name="stA002">
+    <schemaTest name="stA002.i">
+      <schemaDocument xlink:href="../data/stA002.xsd"/>
+      <expected validity="invalid"/>
+    </schemaTest>
+  </testGroup>
+  <testGroup name="stA003">
+    <schemaTest name="stA003.v">
+      <schemaDocument xlink:href="../data/stA002.xsd"/>
+      <expected validity="indeterminate"/>
+    </schemaTest>
+  </testGroup>
+</testSet>`,
+		"data/ctA001.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Party">
+    <xs:sequence>
+      <xs:element name="Nm" type="xs:string"/>
+    </xs:sequence>
+  </xs:complexType>
+</xs:schema>`,
+		"data/stA001.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+    <xs:restriction base="xs:string">
+      <xs:minLength value="5"/>
+      <xs:maxLength value="2"/>
+    </xs:restriction>
+  </xs:simpleType>
+</xs:schema>`,
+		"data/stA002.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:simpleType name="Code">
+</xs:schema>`,
+	})
+	report, err := (&Runner{}).Run(filepath.Join(dir, "suite.xml"))
+	require.NoError(t, err)
+	require.Len(t, report.Results, 3)
+
+	assert.Equal(t, "types", report.Results[0].Set)
+	assert.Equal(t, "ctA001", report.Results[0].Group)
+	assert.Equal(t, "ctA001.v", report.Results[0].Name)
+	assert.Equal(t, []string{filepath.Join(dir, "data", "ctA001.xsd")}, report.Results[0].Schemas)
+	assert.Equal(t, []string{"complexType", "element", "schema", "sequence"}, report.Results[0].Constructs)
+	assert.True(t, report.Results[0].Passed)
+
+	// The conflicting facets aren't rejected by the parser.
+	assert.Equal(t, Valid, report.Results[1].Actual)
+	assert.False(t, report.Results[1].Passed)
+
+	assert.Equal(t, Invalid, report.Results[2].Actual)
+	assert.NotEmpty(t, report.Results[2].Error)
+	assert.True(t, report.Results[2].Passed)
+
+	assert.Equal(t, 2, report.Passed())
+	assert.Equal(t, []Result{report.Results[1]}, report.Failed())
+	assert.Equal(t, []Coverage{
+		{Construct: "complexType", Passed: 1},
+		{Construct: "element", Passed: 1},
+		{Construct: "maxLength", Failed: 1},
+		{Construct: "minLength", Failed: 1},
+		{Construct: "restriction", Failed: 1},
+		{Construct: "schema", Passed: 2, Failed: 1},
+		{Construct: "sequence", Passed: 1},
+		{Construct: "simpleType", Passed: 1, Failed: 1},
+	}, report.Coverage())
+
+	report, err = (&Runner{Lang: "Go"}).Run(filepath.Join(dir, "meta", "types.testSet"))
+	require.NoError(t, err)
+	require.Len(t, report.Results, 3)
+	assert.True(t, report.Results[0].Passed)
+
+	_, err = (&Runner{}).Run(filepath.Join(dir, "data", "ctA001.xsd"))
+	assert.EqualError(t, err, filepath.Join(dir, "data", "ctA001.xsd")+": expected element type <testSuite> but have <schema>")
+}
+
+func TestRunVersion(t *testing.T) {
+	dir := writeSuite(t, map[string]string{
+		"assert.testSet": `<testSet xmlns="http://www.w3.org/XML/2004/xml-schema-test-suite/" xmlns:xlink="http://www.w3.org/1999/xlink" name="assertions">
+  <testGroup name="asA001">
+    <schemaTest name="asA001.v">
+      <schemaDocument xlink:href="asA001.xsd"/>
+      <expected validity="invalid" version="1.0"/>
+      <expected validity="valid" version="1.1"/>
+    </schemaTest>
+  </testGroup>
+</testSet>`,
+		"asA001.xsd": `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema">
+  <xs:complexType name="Range">
+    <xs:sequence>
+      <xs:element name="Min" type="xs:int"/>
+      <xs:element name="Max" type="xs:int"/>
+    </xs:sequence>
+    <xs:assert test="Min le Max"/>
+  </xs:complexType>
+</xs:schema>`,
+	})
+	report, err := (&Runner{Version: "1.1"}).Run(filepath.Join(dir, "assert.testSet"))
+	require.NoError(t, err)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, Valid, report.Results[0].Expected)
+	assert.True(t, report.Results[0].Passed)
+	assert.Contains(t, report.Results[0].Constructs, "assert")
+
+	report, err = (&Runner{}).Run(filepath.Join(dir, "assert.testSet"))
+	require.NoError(t, err)
+	assert.Empty(t, report.Results)
+}
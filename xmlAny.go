@@ -0,0 +1,29 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnAny handles parsing event on the any start elements. The any element
+// enables the elements which aren't declared by the schema to appear in the
+// complex type or the group, which are kept by the catch-all field of the
+// generated type, and the choice containing one isn't a choice of elements.
+func (opt *Options) OnAny(ele xml.StartElement, protoTree []interface{}) (err error) {
+	if c := opt.getChoice(); c != nil {
+		c.Nested = true
+	}
+	if opt.ComplexType.Len() > 0 {
+		opt.ComplexType.Peek().(*ComplexType).Any = true
+		return
+	}
+	if opt.InGroup > 0 && opt.Group.Len() > 0 {
+		opt.Group.Peek().(*Group).Any = true
+	}
+	return
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// OnAnyAttribute handles parsing event on the anyAttribute start elements.
+// The anyAttribute element enables the attributes which aren't declared by
+// the schema to appear in the complex type or the attribute group, which are
+// kept by the catch-all field of the generated type. The namespace attribute
+// restricts the namespaces of the attributes.
+func (opt *Options) OnAnyAttribute(ele xml.StartElement, protoTree []interface{}) (err error) {
+	var namespace NamespaceConstraint
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "namespace" {
+			namespace = opt.parseNamespaceConstraint(attr.Value)
+		}
+	}
+	if opt.AttributeGroup.Len() > 0 {
+		attributeGroup := opt.AttributeGroup.Peek().(*AttributeGroup)
+		attributeGroup.AnyAttribute, attributeGroup.AnyAttributeNamespace = true, namespace
+		return
+	}
+	if opt.ComplexType.Len() > 0 {
+		complexType := opt.ComplexType.Peek().(*ComplexType)
+		complexType.AnyAttribute, complexType.AnyAttributeNamespace = true, namespace
+	}
+	return
+}
+
+// parseNamespaceConstraint parses the namespace attribute of the wildcard,
+// which is ##any, ##other, or the list of the namespaces, ##targetNamespace
+// and ##local, resolved against the target namespace of the schema.
+func (opt *Options) parseNamespaceConstraint(value string) (namespace NamespaceConstraint) {
+	fields := strings.Fields(value)
+	if len(fields) == 1 && fields[0] == "##other" {
+		// The other namespaces are neither the target namespace nor no
+		// namespace.
+		namespace.Not = true
+		fields = []string{"##targetNamespace", "##local"}
+	}
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		switch field {
+		case "##any":
+			return NamespaceConstraint{}
+		case "##targetNamespace":
+			field = opt.TargetNamespace
+		case "##local":
+			field = ""
+		}
+		if !seen[field] {
+			seen[field] = true
+			namespace.Namespaces = append(namespace.Namespaces, field)
+		}
+	}
+	return
+}
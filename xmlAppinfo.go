@@ -0,0 +1,110 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// AppinfoSensitive is the content of the appinfo element marking the element
+// or the attribute declaring it as sensitive, e.g. personal data, whose
+// values are masked by the generated code.
+const AppinfoSensitive = "sensitive"
+
+// OnAppinfo handles parsing event on the appinfo start elements. The appinfo
+// element specifies information to be used by applications within an
+// annotation element.
+func (opt *Options) OnAppinfo(ele xml.StartElement, protoTree []interface{}) (err error) {
+	opt.InAppinfo = true
+	opt.appinfoOwner = ""
+	if n := len(opt.path); n > 2 && opt.path[n-2] == "annotation" {
+		opt.appinfoOwner = opt.path[n-3]
+	}
+	return
+}
+
+// EndAppinfo handles parsing event on the appinfo end elements.
+func (opt *Options) EndAppinfo(ele xml.EndElement, protoTree []interface{}) (err error) {
+	opt.InAppinfo = false
+	return
+}
+
+// onAppinfoCharData marks the element or the attribute annotated by the
+// appinfo element as sensitive or deprecated, if the content of the appinfo
+// element is the sensitive or the deprecated marker, or adds the
+// customizations of its lines to the annotated declaration.
+func (opt *Options) onAppinfoCharData(ele string) {
+	if strings.EqualFold(strings.TrimSpace(ele), AppinfoSensitive) {
+		if attribute, element := opt.getAppinfoDeclaration(); attribute != nil {
+			attribute.Sensitive = true
+		} else if element != nil {
+			element.Sensitive = true
+		}
+		return
+	}
+	if reason, ok := parseAppinfoDeprecation(ele); ok {
+		if attribute, element := opt.getAppinfoDeclaration(); attribute != nil {
+			attribute.Deprecated, attribute.Deprecation = true, reason
+		} else if element != nil {
+			element.Deprecated, element.Deprecation = true, reason
+		}
+		return
+	}
+	if custom := opt.getAppinfoCustomization(); custom != nil {
+		if err := custom.parse(ele); err != nil {
+			opt.warn(fmt.Sprintf("ignored the customization of the %s: %v", opt.appinfoOwner, err))
+		}
+	}
+}
+
+// getAppinfoCustomization returns the customization of the type, the
+// element or the attribute annotated by the appinfo element being parsed, or
+// nil if none is.
+func (opt *Options) getAppinfoCustomization() *Customization {
+	switch opt.appinfoOwner {
+	case "complexType":
+		if opt.ComplexType.Len() > 0 {
+			return &opt.ComplexType.Peek().(*ComplexType).Custom
+		}
+	case "simpleType":
+		if opt.SimpleType.Len() > 0 {
+			return &opt.SimpleType.Peek().(*SimpleType).Custom
+		}
+	case "element", "attribute":
+		if attribute, element := opt.getAppinfoDeclaration(); attribute != nil {
+			return &attribute.Custom
+		} else if element != nil {
+			return &element.Custom
+		}
+	}
+	return nil
+}
+
+// getAppinfoDeclaration returns the attribute or the element annotated by
+// the appinfo element being parsed, or nil if neither is.
+func (opt *Options) getAppinfoDeclaration() (*Attribute, *Element) {
+	if opt.Attribute.Len() > 0 {
+		return opt.Attribute.Peek().(*Attribute), nil
+	}
+	var elements []Element
+	if opt.ComplexType.Len() > 0 {
+		elements = opt.ComplexType.Peek().(*ComplexType).Elements
+	} else if opt.InGroup > 0 && opt.Group.Len() > 0 {
+		elements = opt.Group.Peek().(*Group).Elements
+	}
+	if len(elements) > 0 {
+		return nil, &elements[len(elements)-1]
+	}
+	if opt.Element.Len() > 0 {
+		return nil, opt.Element.Peek().(*Element)
+	}
+	return nil, nil
+}
@@ -0,0 +1,27 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnAssert handles parsing event on the assert start elements. The assert
+// element of XSD 1.1 constrains the complex type holding it by the XPath
+// expression of the test attribute.
+func (opt *Options) OnAssert(ele xml.StartElement, protoTree []interface{}) (err error) {
+	if opt.ComplexType.Len() == 0 {
+		return
+	}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "test" {
+			complexType := opt.ComplexType.Peek().(*ComplexType)
+			complexType.Asserts = append(complexType.Asserts, Assert{Test: attr.Value})
+		}
+	}
+	return
+}
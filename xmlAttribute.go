@@ -32,11 +32,17 @@ func (opt *Options) OnAttribute(ele xml.StartElement, protoTree []interface{}) (
 			if err != nil {
 				return
 			}
+			attribute.TypeRef = opt.getTypeRef(attr.Value)
+		}
+		if attr.Name.Local == "default" {
+			attribute.Default = attr.Value
+		}
+		if attr.Name.Local == "fixed" {
+			attribute.Fixed = attr.Value
 		}
 		if attr.Name.Local == "use" {
-			if attr.Value == "required" {
-				attribute.Optional = false
-			}
+			attribute.Optional = attr.Value != "required"
+			attribute.Prohibited = attr.Value == "prohibited"
 		}
 	}
 	opt.Attribute.Push(&attribute)
@@ -48,6 +54,11 @@ func (opt *Options) EndAttribute(ele xml.EndElement, protoTree []interface{}) (e
 	if opt.Attribute.Len() == 0 {
 		return
 	}
+	// The prohibited attributes aren't members of their types.
+	if opt.Attribute.Peek().(*Attribute).Prohibited {
+		opt.Attribute.Pop()
+		return
+	}
 	if opt.AttributeGroup.Len() > 0 {
 		opt.AttributeGroup.Peek().(*AttributeGroup).Attributes = append(opt.AttributeGroup.Peek().(*AttributeGroup).Attributes, *opt.Attribute.Pop().(*Attribute))
 		return
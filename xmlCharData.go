@@ -18,40 +18,57 @@ func (opt *Options) OnCharData(ele string, protoTree []interface{}) (err error)
 		return
 	}
 	ele = strings.TrimSpace(ele)
+	// The appinfo content is for the applications, not the documentation.
+	if opt.InAppinfo {
+		opt.onAppinfoCharData(ele)
+		return
+	}
 	if opt.InAttributeGroup {
 		if opt.AttributeGroup.Peek() != nil {
-			opt.AttributeGroup.Peek().(*AttributeGroup).Doc = ele
+			v := opt.AttributeGroup.Peek().(*AttributeGroup)
+			opt.setDoc(&v.Doc, &v.Docs, ele)
 			return
 		}
 	}
 	if opt.InElement != "" {
 		if opt.Element.Peek() != nil {
-			opt.Element.Peek().(*Element).Doc = ele
+			v := opt.Element.Peek().(*Element)
+			opt.setDoc(&v.Doc, &v.Docs, ele)
 			return
 		}
 	}
 	if opt.Attribute.Len() > 0 {
-		opt.Attribute.Peek().(*Attribute).Doc = ele
+		v := opt.Attribute.Peek().(*Attribute)
+		opt.setDoc(&v.Doc, &v.Docs, ele)
+		return
+	}
+	if opt.Notation.Len() > 0 {
+		v := opt.Notation.Peek().(*Notation)
+		opt.setDoc(&v.Doc, &v.Docs, ele)
 		return
 	}
 	switch opt.CurrentEle {
 	case "simpleType":
 		if opt.SimpleType.Peek() != nil {
-			opt.SimpleType.Peek().(*SimpleType).Doc = ele
+			v := opt.SimpleType.Peek().(*SimpleType)
+			opt.setDoc(&v.Doc, &v.Docs, ele)
 			return
 		}
 	case "complexType":
 		if opt.Attribute.Len() > 0 {
-			opt.Attribute.Peek().(*Attribute).Doc = ele
+			v := opt.Attribute.Peek().(*Attribute)
+			opt.setDoc(&v.Doc, &v.Docs, ele)
 			return
 		}
 		if opt.ComplexType.Peek() != nil {
 			l := len(opt.ComplexType.Peek().(*ComplexType).Attributes)
 			if l > 0 {
-				opt.ComplexType.Peek().(*ComplexType).Attributes[l-1].Doc = ele
+				v := &opt.ComplexType.Peek().(*ComplexType).Attributes[l-1]
+				opt.setDoc(&v.Doc, &v.Docs, ele)
 				return
 			}
-			opt.ComplexType.Peek().(*ComplexType).Doc = ele
+			v := opt.ComplexType.Peek().(*ComplexType)
+			opt.setDoc(&v.Doc, &v.Docs, ele)
 			return
 		}
 	default:
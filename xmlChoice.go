@@ -17,33 +17,35 @@ import (
 // choice element defines that one and only one of the contained element can be present within
 // the contained element.
 func (opt *Options) OnChoice(ele xml.StartElement, protoTree []interface{}) (err error) {
-	choice := Choice{}
-	for _, attr := range ele.Attr {
-		if attr.Name.Local == "maxOccurs" {
-			var maxOccurs int
-			if maxOccurs, err = strconv.Atoi(attr.Value); attr.Value != "unbounded" && err != nil {
-				return
-			}
-			if attr.Value == "unbounded" || maxOccurs > 1 {
-				choice.Plural, err = true, nil
-			} else {
-				choice.Plural, err = false, nil
-			}
-		}
+	// The choice inherits the plurality of the enclosing particles, and the
+	// contained elements are optional.
+	_, parentOptional := opt.getParticle()
+	p, err := opt.pushParticle(ele, true)
+	if err != nil {
+		return
 	}
-	// Handle a case of a parent choice having plurality that children should inherit
-	if opt.Choice.Len() > 0 {
-		choice.Plural = choice.Plural || opt.Choice.Peek().(*Choice).Plural
+	_, optional, _ := getOccurs(ele)
+	c := &Choice{Plural: p.plural, Optional: optional || parentOptional}
+	if complexType, ok := opt.ComplexType.Peek().(*ComplexType); ok {
+		complexType.Choice = append(complexType.Choice, Choice{})
+		c.ID = strconv.Itoa(len(complexType.Choice))
 	}
-
-	opt.Choice.Push(&choice)
+	p.choice = c
+	opt.Choice.Push(c)
 
 	return
 }
 
 // EndChoice handles parsing event on the choice end elements.
 func (opt *Options) EndChoice(ele xml.EndElement, protoTree []interface{}) (err error) {
-	opt.Choice.Pop()
+	c := opt.Choice.Pop().(*Choice)
+	if complexType, ok := opt.ComplexType.Peek().(*ComplexType); ok && c.ID != "" {
+		i, _ := strconv.Atoi(c.ID)
+		// The choice may be marked as nested by the duplicate elements.
+		c.Nested = c.Nested || complexType.Choice[i-1].Nested
+		complexType.Choice[i-1] = *c
+	}
+	opt.Particle.Pop()
 
 	return
 }
\ No newline at end of file
@@ -14,9 +14,13 @@ import "encoding/xml"
 // complex element contains other elements and/or attributes.
 func (opt *Options) OnComplexType(ele xml.StartElement, protoTree []interface{}) (err error) {
 	if opt.ComplexType.Len() > 0 {
-		e := opt.Element.Pop().(*Element)
+		e, ok := opt.Element.Pop().(*Element)
+		if !ok {
+			return opt.unexpectedElement(ele.Name.Local)
+		}
 		opt.ComplexType.Push(&ComplexType{
-			Name: e.Name,
+			Name:      e.Name,
+			Anonymous: true,
 		})
 	}
 
@@ -27,19 +31,36 @@ func (opt *Options) OnComplexType(ele xml.StartElement, protoTree []interface{})
 			if attr.Name.Local == "name" {
 				c.Name = attr.Value
 			}
+			if attr.Name.Local == "abstract" {
+				c.Abstract = attr.Value == "true" || attr.Value == "1"
+			}
 		}
 		if c.Name == "" {
-			e := opt.Element.Pop().(*Element)
-			c.Name = e.Name
+			// The type of the element is given the customizations of
+			// the element.
+			e, ok := opt.Element.Pop().(*Element)
+			if !ok {
+				return opt.unexpectedElement(ele.Name.Local)
+			}
+			c.Name, c.Anonymous, c.Custom = e.Name, true, e.Custom
 		}
 		opt.ComplexType.Push(&c)
 	}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "mixed" {
+			opt.ComplexType.Peek().(*ComplexType).Mixed = attr.Value == "true" || attr.Value == "1"
+		}
+	}
+	// The particles of the type don't inherit the cardinality of the
+	// particles the anonymous type is nested in.
+	opt.Particle.Push(&particle{})
 	return
 }
 
 // EndComplexType handles parsing event on the complex end elements.
 func (opt *Options) EndComplexType(ele xml.EndElement, protoTree []interface{}) (err error) {
 	opt.ProtoTree = append(opt.ProtoTree, opt.ComplexType.Pop())
+	opt.Particle.Pop()
 	opt.CurrentEle = ""
 	return
 }
@@ -0,0 +1,55 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// OnDocumentation handles parsing event on the documentation start elements.
+// The xml:lang attribute of the documentation element specifies the language
+// of the documentation, which is held by InDocumentation.
+func (opt *Options) OnDocumentation(ele xml.StartElement, protoTree []interface{}) (err error) {
+	opt.InDocumentation = ""
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "lang" {
+			opt.InDocumentation = attr.Value
+		}
+	}
+	return
+}
+
+// EndDocumentation handles parsing event on the documentation end elements.
+func (opt *Options) EndDocumentation(ele xml.EndElement, protoTree []interface{}) (err error) {
+	opt.InDocumentation = ""
+	return
+}
+
+// setDoc records the documentation of the declaration in the language of the
+// documentation element being parsed. The documentation in the language of
+// the options, or the last one if the declaration isn't documented in the
+// language, is the documentation of the declaration used in the comments.
+func (opt *Options) setDoc(doc *string, docs *[]Documentation, text string) {
+	*docs = append(*docs, Documentation{Lang: opt.InDocumentation, Text: text})
+	if opt.DocLang != "" && !matchDocLang(opt.InDocumentation, opt.DocLang) {
+		for _, d := range (*docs)[:len(*docs)-1] {
+			if matchDocLang(d.Lang, opt.DocLang) {
+				return
+			}
+		}
+	}
+	*doc = text
+}
+
+// matchDocLang returns whether the language of the documentation matches the
+// language by given tag, the tag matches its subtags too, such as en to en-GB.
+func matchDocLang(lang, tag string) bool {
+	return strings.EqualFold(lang, tag) || len(lang) > len(tag) && strings.EqualFold(lang[:len(tag)+1], tag+"-")
+}
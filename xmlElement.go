@@ -18,7 +18,7 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 	e := Element{}
 	for _, attr := range ele.Attr {
 		if attr.Name.Local == "ref" {
-			e.Name = attr.Value
+			e.Name, e.Ref = attr.Value, true
 			e.Type, err = opt.GetValueType(attr.Value, protoTree)
 			if err != nil {
 				return
@@ -28,11 +28,27 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 		if attr.Name.Local == "name" {
 			e.Name = attr.Value
 		}
+		if attr.Name.Local == "abstract" {
+			e.Abstract = attr.Value == "true" || attr.Value == "1"
+		}
+		if attr.Name.Local == "default" {
+			e.Default = attr.Value
+		}
+		if attr.Name.Local == "fixed" {
+			e.Fixed = attr.Value
+		}
+		if attr.Name.Local == "nillable" {
+			e.Nillable = attr.Value == "true" || attr.Value == "1"
+		}
+		if attr.Name.Local == "substitutionGroup" {
+			e.SubstitutionGroup = trimNSPrefix(attr.Value)
+		}
 		if attr.Name.Local == "type" {
 			e.Type, err = opt.GetValueType(attr.Value, protoTree)
 			if err != nil {
 				return
 			}
+			e.TypeRef = opt.getTypeRef(attr.Value)
 		}
 		if attr.Name.Local == "maxOccurs" {
 			var maxOccurs int
@@ -60,6 +76,9 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 	}
 
 	if e.Type == "" {
+		// The element declared without type may be given an anonymous type,
+		// the other ones are resolved after parsing.
+		e.Untyped = true
 		e.Type, err = opt.GetValueType(e.Name, protoTree)
 		if err != nil {
 			return
@@ -67,10 +86,10 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 		opt.Element.Push(&e)
 	}
 
-	if opt.Choice.Len() > 0 {
-		e.Plural = e.Plural || opt.Choice.Peek().(*Choice).Plural
-		e.Optional = true
-		// fmt.Printf("OnElement: %+v\n", e)
+	plural, optional := opt.getParticle()
+	e.Plural, e.Optional = e.Plural || plural, e.Optional || optional
+	if c := opt.getChoice(); c != nil {
+		e.Choice = c.ID
 	}
 
 	if opt.ComplexType.Len() > 0 {
@@ -83,6 +102,10 @@ func (opt *Options) OnElement(ele xml.StartElement, protoTree []interface{}) (er
 		// since generated code for an array of a type should be compatible to unmarshal/marshal arrays of a single
 		// element
 		if element != nil && element.Type == e.Type {
+			// The choices of the duplicate elements aren't choices of
+			// distinct elements.
+			setChoiceNested(opt.ComplexType.Peek().(*ComplexType), element.Choice)
+			setChoiceNested(opt.ComplexType.Peek().(*ComplexType), e.Choice)
 			element.Plural = element.Plural || e.Plural
 			opt.ComplexType.Peek().(*ComplexType).Elements[i] = *element
 		} else {
@@ -0,0 +1,26 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnField handles parsing event on the field start elements. The field
+// element specifies an XPath expression that specifies the value used to
+// define an identity constraint.
+func (opt *Options) OnField(ele xml.StartElement, protoTree []interface{}) (err error) {
+	if opt.Unique.Len() == 0 {
+		return
+	}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "xpath" {
+			opt.Unique.Peek().(*Unique).Fields = append(opt.Unique.Peek().(*Unique).Fields, attr.Value)
+		}
+	}
+	return
+}
@@ -10,6 +10,22 @@ package xgen
 
 import "encoding/xml"
 
+// OnFractionDigits handles parsing event on the fractionDigits start
+// elements, and keeps the maximum number of decimal places on the
+// restriction.
+func (opt *Options) OnFractionDigits(ele xml.StartElement, protoTree []interface{}) (err error) {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "value" {
+			if opt.SimpleType.Peek() != nil {
+				if opt.SimpleType.Peek().(*SimpleType).Restriction.FractionDigits, err = parseFacetValue("fractionDigits", attr.Value); err != nil {
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
 // EndFractionDigits handles parsing event on the fractionDigits end elements.
 // Enumeration Defines a list of acceptable values. FractionDigits specifies
 // the maximum number of decimal places allowed. Must be equal to or greater
@@ -26,14 +26,14 @@ func (opt *Options) OnGroup(ele xml.StartElement, protoTree []interface{}) (err
 				return
 			}
 		}
-		if attr.Name.Local == "maxOccurs" {
-			if attr.Value != "0" {
-				group.Plural = true
-			}
-		}
 	}
-	if opt.Choice.Len() > 0 {
-		group.Plural = group.Plural || opt.Choice.Peek().(*Choice).Plural
+	if group.Plural, _, err = getOccurs(ele); err != nil {
+		return
+	}
+	plural, _ := opt.getParticle()
+	group.Plural = group.Plural || plural
+	if c := opt.getChoice(); c != nil {
+		c.Nested = true
 	}
 
 	if opt.ComplexType.Len() == 0 {
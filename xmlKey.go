@@ -0,0 +1,25 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnKey handles parsing event on the key start elements. The key element
+// specifies that an attribute or element value (or set of values) must be a
+// key within the specified scope, which is unique and present.
+func (opt *Options) OnKey(ele xml.StartElement, protoTree []interface{}) (err error) {
+	opt.startIdentityConstraint(ele, Unique{Key: true})
+	return
+}
+
+// EndKey handles parsing event on the key end elements.
+func (opt *Options) EndKey(ele xml.EndElement, protoTree []interface{}) (err error) {
+	opt.endIdentityConstraint()
+	return
+}
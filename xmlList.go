@@ -20,6 +20,7 @@ func (opt *Options) OnList(ele xml.StartElement, protoTree []interface{}) (err e
 	opt.SimpleType.Peek().(*SimpleType).List = true
 	for _, attr := range ele.Attr {
 		if attr.Name.Local == "itemType" {
+			opt.SimpleType.Peek().(*SimpleType).ItemType = trimNSPrefix(attr.Value)
 			if opt.SimpleType.Peek().(*SimpleType).Base, err = opt.GetValueType(attr.Value, protoTree); err != nil {
 				return
 			}
@@ -27,3 +28,16 @@ func (opt *Options) OnList(ele xml.StartElement, protoTree []interface{}) (err e
 	}
 	return
 }
+
+// EndList handles parsing event on the list end elements. The facets of the
+// anonymous item type are moved to the item restriction of the list, so the
+// facets following the list restrict the list itself.
+func (opt *Options) EndList(ele xml.EndElement, protoTree []interface{}) (err error) {
+	if opt.SimpleType.Peek() == nil {
+		return
+	}
+	if simpleType := opt.SimpleType.Peek().(*SimpleType); simpleType.List && simpleType.ItemType == "" {
+		simpleType.ItemRestriction, simpleType.Restriction = simpleType.Restriction, Restriction{}
+	}
+	return
+}
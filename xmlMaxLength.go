@@ -8,16 +8,15 @@
 
 package xgen
 
-import (
-	"encoding/xml"
-	"strconv"
-)
+import "encoding/xml"
 
 func (opt *Options) OnMaxLength(ele xml.StartElement, protoTree []interface{}) (err error) {
 	for _, attr := range ele.Attr {
 		if attr.Name.Local == "value" {
 			if opt.SimpleType.Peek() != nil {
-				opt.SimpleType.Peek().(*SimpleType).Restriction.MaxLength, _ = strconv.Atoi(attr.Value)
+				if opt.SimpleType.Peek().(*SimpleType).Restriction.MaxLength, err = parseFacetValue("maxLength", attr.Value); err != nil {
+					return
+				}
 			}
 		}
 	}
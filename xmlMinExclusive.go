@@ -8,16 +8,13 @@
 
 package xgen
 
-import (
-	"encoding/xml"
-	"strconv"
-)
+import "encoding/xml"
 
 func (opt *Options) OnMinExclusive(ele xml.StartElement, protoTree []interface{}) (err error) {
 	for _, attr := range ele.Attr {
 		if attr.Name.Local == "value" {
 			if opt.SimpleType.Peek() != nil {
-				opt.SimpleType.Peek().(*SimpleType).Restriction.Min, _ = strconv.ParseFloat(attr.Value, 64)
+				opt.setBoundFacet("minExclusive", attr.Value, false, true)
 			}
 		}
 	}
@@ -8,16 +8,15 @@
 
 package xgen
 
-import (
-	"encoding/xml"
-	"strconv"
-)
+import "encoding/xml"
 
 func (opt *Options) OnMinLength(ele xml.StartElement, protoTree []interface{}) (err error) {
 	for _, attr := range ele.Attr {
 		if attr.Name.Local == "value" {
 			if opt.SimpleType.Peek() != nil {
-				opt.SimpleType.Peek().(*SimpleType).Restriction.MinLength, _ = strconv.Atoi(attr.Value)
+				if opt.SimpleType.Peek().(*SimpleType).Restriction.MinLength, err = parseFacetValue("minLength", attr.Value); err != nil {
+					return
+				}
 			}
 		}
 	}
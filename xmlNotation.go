@@ -0,0 +1,39 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnNotation handles parsing event on the notation start elements. The
+// notation element declares the name of an external format by its public and
+// system identifiers.
+func (opt *Options) OnNotation(ele xml.StartElement, protoTree []interface{}) (err error) {
+	notation := Notation{}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "name" {
+			notation.Name = attr.Value
+		}
+		if attr.Name.Local == "public" {
+			notation.Public = attr.Value
+		}
+		if attr.Name.Local == "system" {
+			notation.System = attr.Value
+		}
+	}
+	opt.Notation.Push(&notation)
+	return
+}
+
+// EndNotation handles parsing event on the notation end elements.
+func (opt *Options) EndNotation(ele xml.EndElement, protoTree []interface{}) (err error) {
+	if opt.Notation.Len() > 0 {
+		opt.ProtoTree = append(opt.ProtoTree, opt.Notation.Pop())
+	}
+	return
+}
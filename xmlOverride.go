@@ -0,0 +1,48 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnOverride handles parsing event on the override start elements. The
+// override element of XSD 1.1 includes the schema of the location, with the
+// top-level declarations it contains replacing the components of the same
+// name of the overridden schema.
+func (opt *Options) OnOverride(ele xml.StartElement, protoTree []interface{}) (err error) {
+	opt.startRedefinition(ele, true)
+	return
+}
+
+// EndOverride handles parsing event on the override end elements. The
+// declarations of the overridden schema are added to the proto tree, with
+// the overriding declarations in place of the original ones, and the
+// overriding declarations of the components the overridden schema doesn't
+// declare are dropped.
+func (opt *Options) EndOverride(ele xml.EndElement, protoTree []interface{}) (err error) {
+	return opt.endRedefinition()
+}
+
+// getOverrideKey returns the key of the symbol space and the name of the
+// declaration which can be overridden, or an empty key. The anonymous
+// complex types are overridden along with the elements declaring them.
+func getOverrideKey(decl interface{}) string {
+	switch v := decl.(type) {
+	case *ComplexType:
+		if v.Anonymous {
+			return "anonymousType:" + v.Name
+		}
+	case *Element:
+		return "element:" + v.Name
+	case *Attribute:
+		return "attribute:" + v.Name
+	case *Notation:
+		return "notation:" + v.Name
+	}
+	return getRedefinitionKey(decl)
+}
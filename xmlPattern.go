@@ -10,14 +10,31 @@ package xgen
 
 import (
 	"encoding/xml"
+	"fmt"
 	"regexp"
 )
 
+// OnPattern handles parsing event on the pattern start elements. The XML
+// schema regular expression of the pattern is translated to the regular
+// expression of Go and Rust, and the pattern which can't be translated is
+// handled by the pattern fallback.
 func (opt *Options) OnPattern(ele xml.StartElement, protoTree []interface{}) (err error) {
 	for _, attr := range ele.Attr {
 		if attr.Name.Local == "value" {
 			if opt.SimpleType.Peek() != nil {
-				opt.SimpleType.Peek().(*SimpleType).Restriction.Pattern, _ = regexp.Compile(attr.Value)
+				pattern, err := translatePattern(attr.Value)
+				var re *regexp.Regexp
+				if err == nil {
+					re, err = regexp.Compile(pattern)
+				}
+				if err != nil {
+					if opt.PatternFallback == PatternFallbackFail {
+						return fmt.Errorf("unsupported pattern %s: %v", attr.Value, err)
+					}
+					opt.warn(fmt.Sprintf("skipped the validation of the unsupported pattern %s: %v", attr.Value, err))
+					continue
+				}
+				opt.SimpleType.Peek().(*SimpleType).Restriction.Pattern = re
 			}
 		}
 	}
@@ -29,9 +46,11 @@ func (opt *Options) OnPattern(ele xml.StartElement, protoTree []interface{}) (er
 // defines the exact sequence of characters that are acceptable.
 func (opt *Options) EndPattern(ele xml.EndElement, protoTree []interface{}) (err error) {
 	if opt.Attribute.Len() > 0 && opt.SimpleType.Peek() != nil {
-		if opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree); err != nil {
+		simpleType := opt.SimpleType.Pop().(*SimpleType)
+		if opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(simpleType.Base, opt.ProtoTree); err != nil {
 			return
 		}
+		opt.Attribute.Peek().(*Attribute).Restriction = simpleType.Restriction
 		opt.CurrentEle = ""
 	}
 	if opt.SimpleType.Len() > 0 && opt.Element.Len() > 0 {
@@ -0,0 +1,293 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// redefinition holds the state of the redefine or the override element
+// being parsed, the location of the redefined schema and the index of the
+// first redefined declaration in the proto tree.
+type redefinition struct {
+	location string
+	start    int
+	override bool
+}
+
+// redefinitions holds the redefinitions of the components of the redefined
+// schema, applied to the declarations of the schema as they are parsed, so
+// the declarations of the schema referencing the redefined components see
+// their redefinitions. The overrides replace the components as they are.
+type redefinitions struct {
+	decls    []interface{}
+	keys     map[string]interface{}
+	applied  map[string]bool
+	selfRefs map[string]bool
+	override bool
+}
+
+// newRedefinitions returns the redefinitions of the declarations parsed in
+// the redefine or the override element.
+func newRedefinitions(decls []interface{}, override bool) *redefinitions {
+	r := &redefinitions{keys: map[string]interface{}{}, applied: map[string]bool{}, selfRefs: map[string]bool{}, override: override}
+	for _, decl := range decls {
+		// The attribute group referencing itself by the attribute group
+		// it redefines is parsed as a declaration of the reference.
+		if attributeGroup, ok := decl.(*AttributeGroup); ok && attributeGroup.Ref != "" {
+			r.selfRefs[trimNSPrefix(attributeGroup.Ref)] = true
+			continue
+		}
+		if key := r.getKey(decl); key != "" {
+			r.decls = append(r.decls, decl)
+			r.keys[key] = decl
+		}
+	}
+	return r
+}
+
+// apply returns the redefinition of the declaration, or the declaration if
+// it isn't redefined.
+func (r *redefinitions) apply(decl interface{}) interface{} {
+	key := r.getKey(decl)
+	redefinition, ok := r.keys[key]
+	if !ok || key == "" || r.applied[key] {
+		return decl
+	}
+	r.applied[key] = true
+	if r.override {
+		return redefinition
+	}
+	return redefineDecl(decl, redefinition, r.selfRefs)
+}
+
+// getKey returns the key of the declaration which can be redefined or
+// overridden, or an empty key.
+func (r *redefinitions) getKey(decl interface{}) string {
+	if r.override {
+		return getOverrideKey(decl)
+	}
+	return getRedefinitionKey(decl)
+}
+
+// remaining returns the redefinitions of the components the redefined schema
+// doesn't declare, in order. The overrides of the components the overridden
+// schema doesn't declare have no effect.
+func (r *redefinitions) remaining() (decls []interface{}) {
+	if r.override {
+		return
+	}
+	for _, decl := range r.decls {
+		if !r.applied[r.getKey(decl)] {
+			decls = append(decls, decl)
+		}
+	}
+	return
+}
+
+// OnRedefine handles parsing event on the redefine start elements. The
+// redefine element includes the schema of the location, with the simple and
+// complex types, groups and attribute groups it contains redefining the
+// components of the included schema.
+func (opt *Options) OnRedefine(ele xml.StartElement, protoTree []interface{}) (err error) {
+	opt.startRedefinition(ele, false)
+	return
+}
+
+// startRedefinition starts the redefine or the override element, the
+// declarations parsed from the index of the proto tree redefine the
+// components of the schema of the location.
+func (opt *Options) startRedefinition(ele xml.StartElement, override bool) {
+	opt.redefine = &redefinition{start: len(opt.ProtoTree), override: override}
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "schemaLocation" {
+			opt.redefine.location = attr.Value
+		}
+	}
+}
+
+// EndRedefine handles parsing event on the redefine end elements. The
+// declarations of the redefined schema are added to the proto tree, with the
+// redefinitions in place of the original declarations, so the code of the
+// redefined components is generated once from their redefinitions. The
+// redefined schema which can't be parsed is skipped as the included ones.
+func (opt *Options) EndRedefine(ele xml.EndElement, protoTree []interface{}) (err error) {
+	return opt.endRedefinition()
+}
+
+// endRedefinition ends the redefine or the override element, by parsing the
+// schema of the location with the redefinitions applied to its declarations.
+func (opt *Options) endRedefinition() (err error) {
+	redefine := opt.redefine
+	opt.redefine = nil
+	if redefine == nil || redefine.location == "" {
+		return
+	}
+	var file string
+	if file, err = opt.resolveSchemaLocation(redefine.location); err != nil || file == "" {
+		return
+	}
+	if getSchemaID(file) == getSchemaID(opt.FilePath) {
+		return
+	}
+	parser := NewParser(opt.subOptions(file, true))
+	// The redefined schema isn't cached, its declarations depend on the
+	// redefinitions.
+	parser.Cache = nil
+	parser.redefinitions = newRedefinitions(opt.ProtoTree[redefine.start:], redefine.override)
+	if err = parser.Parse(); err != nil {
+		err = getLimitError(err)
+		return
+	}
+	opt.addWarnings(parser.Warnings)
+	opt.ProtoTree = append(append(opt.ProtoTree[:redefine.start:redefine.start], parser.ProtoTree...), parser.redefinitions.remaining()...)
+	return
+}
+
+// applyRedefinitions replaces the declarations parsed from the index of the
+// proto tree by their redefinitions, if the schema is redefined.
+func (opt *Options) applyRedefinitions(parsed int) {
+	if opt.redefinitions == nil {
+		return
+	}
+	for i := parsed; i < len(opt.ProtoTree); i++ {
+		opt.ProtoTree[i] = opt.redefinitions.apply(opt.ProtoTree[i])
+	}
+}
+
+// getRedefinitionKey returns the key of the symbol space and the name of the
+// declaration which can be redefined, or an empty key.
+func getRedefinitionKey(decl interface{}) string {
+	switch v := decl.(type) {
+	case *SimpleType:
+		return "type:" + v.Name
+	case *ComplexType:
+		return "type:" + v.Name
+	case *Group:
+		return "group:" + v.Name
+	case *AttributeGroup:
+		return "attributeGroup:" + v.Name
+	}
+	return ""
+}
+
+// redefineDecl returns the redefinition of the original declaration. The
+// simple type restricting itself restricts the base of the original with the
+// facets of both, the complex type extending itself has the content of the
+// original followed by its own, and the groups and attribute groups
+// referencing themselves have the content of the original in place of the
+// reference. The other redefinitions replace the original.
+func redefineDecl(original, redefinition interface{}, selfRefs map[string]bool) interface{} {
+	switch r := redefinition.(type) {
+	case *SimpleType:
+		o, ok := original.(*SimpleType)
+		if !ok || trimNSPrefix(r.Base) != r.Name {
+			return r
+		}
+		simpleType := *r
+		simpleType.Base, simpleType.List, simpleType.Union, simpleType.MemberTypes, simpleType.Members = o.Base, o.List, o.Union, o.MemberTypes, o.Members
+		simpleType.ItemType, simpleType.ItemRestriction = o.ItemType, o.ItemRestriction
+		simpleType.Restriction = restrictFacets(o.Restriction, r.Restriction)
+		if simpleType.Doc == "" {
+			simpleType.Doc, simpleType.Docs = o.Doc, o.Docs
+		}
+		return &simpleType
+	case *ComplexType:
+		o, ok := original.(*ComplexType)
+		if !ok || trimNSPrefix(r.Base) != r.Name {
+			return r
+		}
+		complexType := *r
+		complexType.Base = o.Base
+		complexType.Elements = append(append([]Element{}, o.Elements...), r.Elements...)
+		complexType.Attributes = append(append([]Attribute{}, o.Attributes...), r.Attributes...)
+		complexType.Groups = append(append([]Group{}, o.Groups...), r.Groups...)
+		complexType.Choice = append(append([]Choice{}, o.Choice...), r.Choice...)
+		complexType.AttributeGroup = append(append([]AttributeGroup{}, o.AttributeGroup...), r.AttributeGroup...)
+		complexType.Mixed = o.Mixed || r.Mixed
+		complexType.Any = o.Any || r.Any
+		if !r.AnyAttribute {
+			complexType.AnyAttribute, complexType.AnyAttributeNamespace = o.AnyAttribute, o.AnyAttributeNamespace
+		}
+		if complexType.Doc == "" {
+			complexType.Doc, complexType.Docs = o.Doc, o.Docs
+		}
+		return &complexType
+	case *Group:
+		o, ok := original.(*Group)
+		if !ok {
+			return r
+		}
+		group := *r
+		group.Groups = nil
+		for _, g := range r.Groups {
+			if trimNSPrefix(g.Ref) == r.Name {
+				group.Elements = append(append([]Element{}, o.Elements...), group.Elements...)
+				group.Groups = append(group.Groups, o.Groups...)
+				group.Any = group.Any || o.Any
+				continue
+			}
+			group.Groups = append(group.Groups, g)
+		}
+		return &group
+	case *AttributeGroup:
+		o, ok := original.(*AttributeGroup)
+		if !ok || !selfRefs[r.Name] {
+			return r
+		}
+		attributeGroup := *r
+		attributeGroup.Attributes = append(append([]Attribute{}, o.Attributes...), r.Attributes...)
+		if !r.AnyAttribute {
+			attributeGroup.AnyAttribute, attributeGroup.AnyAttributeNamespace = o.AnyAttribute, o.AnyAttributeNamespace
+		}
+		return &attributeGroup
+	}
+	return redefinition
+}
+
+// restrictFacets returns the facets of the base restricted by the facets of
+// the restriction, which override the facets of the base they declare.
+func restrictFacets(base, restriction Restriction) Restriction {
+	facets := base
+	if restriction.Doc != "" {
+		facets.Doc = restriction.Doc
+	}
+	if restriction.Precision != 0 {
+		facets.Precision = restriction.Precision
+	}
+	if len(restriction.Enum) > 0 {
+		facets.Enum = restriction.Enum
+	}
+	if restriction.HasMin {
+		facets.Min, facets.HasMin, facets.MinExclusive, facets.MinValue = restriction.Min, true, restriction.MinExclusive, restriction.MinValue
+	}
+	if restriction.HasMax {
+		facets.Max, facets.HasMax, facets.MaxExclusive, facets.MaxValue = restriction.Max, true, restriction.MaxExclusive, restriction.MaxValue
+	}
+	if restriction.MinLength != 0 {
+		facets.MinLength = restriction.MinLength
+	}
+	if restriction.MaxLength != 0 {
+		facets.MaxLength = restriction.MaxLength
+	}
+	if restriction.HasLength {
+		facets.Length, facets.HasLength = restriction.Length, true
+	}
+	if restriction.Pattern != nil {
+		facets.Pattern = restriction.Pattern
+	}
+	if restriction.WhiteSpace != "" {
+		facets.WhiteSpace = restriction.WhiteSpace
+	}
+	if restriction.TotalDigits != 0 {
+		facets.TotalDigits = restriction.TotalDigits
+	}
+	if restriction.FractionDigits != 0 {
+		facets.FractionDigits = restriction.FractionDigits
+	}
+	return facets
+}
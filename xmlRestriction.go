@@ -26,6 +26,11 @@ func (opt *Options) OnRestriction(ele xml.StartElement, protoTree []interface{})
 				if err != nil {
 					return
 				}
+				if whiteSpace, ok := whiteSpaceBuildInTypes[trimNSPrefix(attr.Value)]; ok {
+					opt.SimpleType.Peek().(*SimpleType).Restriction.WhiteSpace = whiteSpace
+				}
+				opt.SimpleType.Peek().(*SimpleType).Restriction.Octets = trimNSPrefix(attr.Value) == "hexBinary" || getRestrictionFromSimpleType(trimNSPrefix(attr.Value), protoTree).Octets
+				opt.SimpleType.Peek().(*SimpleType).Restriction.base = getBuildInBase(attr.Value, protoTree)
 				if opt.SimpleType.Peek().(*SimpleType).Name == "" {
 					opt.SimpleType.Peek().(*SimpleType).Name = attr.Value
 				}
@@ -38,10 +43,12 @@ func (opt *Options) OnRestriction(ele xml.StartElement, protoTree []interface{})
 // EndRestriction handles parsing event on the restriction end elements.
 func (opt *Options) EndRestriction(ele xml.EndElement, protoTree []interface{}) (err error) {
 	if opt.Attribute.Len() > 0 && opt.SimpleType.Peek() != nil {
-		opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(opt.SimpleType.Pop().(*SimpleType).Base, opt.ProtoTree)
+		simpleType := opt.SimpleType.Pop().(*SimpleType)
+		opt.Attribute.Peek().(*Attribute).Type, err = opt.GetValueType(simpleType.Base, opt.ProtoTree)
 		if err != nil {
 			return
 		}
+		opt.Attribute.Peek().(*Attribute).Restriction = simpleType.Restriction
 		opt.CurrentEle = ""
 	}
 	if !opt.Element.Empty() {
@@ -0,0 +1,39 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnSequence handles parsing event on the sequence start elements. The
+// sequence element defines that the contained elements appear in order, the
+// occurs of the sequence apply to each of the contained elements.
+func (opt *Options) OnSequence(ele xml.StartElement, protoTree []interface{}) (err error) {
+	_, err = opt.pushParticle(ele, false)
+	return
+}
+
+// EndSequence handles parsing event on the sequence end elements.
+func (opt *Options) EndSequence(ele xml.EndElement, protoTree []interface{}) (err error) {
+	opt.Particle.Pop()
+	return
+}
+
+// OnAll handles parsing event on the all start elements. The all element
+// defines that the contained elements appear in any order, the minOccurs of
+// the all applies to each of the contained elements.
+func (opt *Options) OnAll(ele xml.StartElement, protoTree []interface{}) (err error) {
+	_, err = opt.pushParticle(ele, false)
+	return
+}
+
+// EndAll handles parsing event on the all end elements.
+func (opt *Options) EndAll(ele xml.EndElement, protoTree []interface{}) (err error) {
+	opt.Particle.Pop()
+	return
+}
@@ -16,6 +16,8 @@ import "encoding/xml"
 func (opt *Options) OnSimpleType(ele xml.StartElement, protoTree []interface{}) (err error) {
 	if opt.SimpleType.Len() == 0 {
 		opt.SimpleType.Push(&SimpleType{})
+	} else if !opt.InUnion {
+		opt.InSimpleType++
 	}
 	if opt.CurrentEle == "attributeGroup" {
 		// return
@@ -31,8 +33,16 @@ func (opt *Options) OnSimpleType(ele xml.StartElement, protoTree []interface{})
 
 // EndSimpleType handles parsing event on the simpleType end elements.
 func (opt *Options) EndSimpleType(ele xml.EndElement, protoTree []interface{}) (err error) {
+	// The nested simple types are part of the simple type on top of the
+	// stack.
+	if opt.InSimpleType > 0 {
+		opt.InSimpleType--
+		return
+	}
 	if opt.SimpleType.Len() > 0 && opt.Attribute.Len() > 0 {
-		opt.Attribute.Peek().(*Attribute).Type = opt.SimpleType.Pop().(*SimpleType).Base
+		simpleType := opt.SimpleType.Pop().(*SimpleType)
+		opt.Attribute.Peek().(*Attribute).Type = simpleType.Base
+		opt.Attribute.Peek().(*Attribute).Restriction = simpleType.Restriction
 		return
 	}
 	if ele.Name.Local == opt.CurrentEle && opt.ComplexType.Len() == 1 {
@@ -10,6 +10,19 @@ package xgen
 
 import "encoding/xml"
 
+func (opt *Options) OnTotalDigits(ele xml.StartElement, protoTree []interface{}) (err error) {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "value" {
+			if opt.SimpleType.Peek() != nil {
+				if opt.SimpleType.Peek().(*SimpleType).Restriction.TotalDigits, err = parseFacetValue("totalDigits", attr.Value); err != nil {
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
 // EndTotalDigits handles parsing event on the totalDigits end elements.
 // TotalDigits specifies the exact number of digits allowed. Must be greater
 // than zero.
@@ -23,11 +23,15 @@ func (opt *Options) OnUnion(ele xml.StartElement, protoTree []interface{}) (err
 	}
 	opt.SimpleType.Peek().(*SimpleType).Union = true
 	opt.SimpleType.Peek().(*SimpleType).MemberTypes = make(map[string]string)
+	opt.SimpleType.Peek().(*SimpleType).Members = nil
 	for _, attr := range ele.Attr {
 		if attr.Name.Local == "memberTypes" {
-			memberTypes := strings.Split(attr.Value, " ")
-			for _, memberType := range memberTypes {
-				opt.SimpleType.Peek().(*SimpleType).MemberTypes[trimNSPrefix(memberType)], err = opt.GetValueType(memberType, protoTree)
+			simpleType := opt.SimpleType.Peek().(*SimpleType)
+			for _, memberType := range strings.Fields(attr.Value) {
+				if _, ok := simpleType.MemberTypes[trimNSPrefix(memberType)]; !ok {
+					simpleType.Members = append(simpleType.Members, trimNSPrefix(memberType))
+				}
+				simpleType.MemberTypes[trimNSPrefix(memberType)], err = opt.GetValueType(memberType, protoTree)
 				if err != nil {
 					return
 				}
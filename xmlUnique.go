@@ -0,0 +1,62 @@
+// Copyright 2020 - 2024 The xgen Authors. All rights reserved. Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+//
+// Package xgen written in pure Go providing a set of functions that allow you
+// to parse XSD (XML schema files). This library needs Go version 1.10 or
+// later.
+
+package xgen
+
+import "encoding/xml"
+
+// OnUnique handles parsing event on the unique start elements. The unique
+// element specifies that an attribute or element value (or a combination of
+// attribute or element values) must be unique within the specified scope.
+func (opt *Options) OnUnique(ele xml.StartElement, protoTree []interface{}) (err error) {
+	opt.startIdentityConstraint(ele, Unique{})
+	return
+}
+
+// EndUnique handles parsing event on the unique end elements.
+func (opt *Options) EndUnique(ele xml.EndElement, protoTree []interface{}) (err error) {
+	opt.endIdentityConstraint()
+	return
+}
+
+// startIdentityConstraint pushes the identity constraint of the start element
+// held by the element being parsed, whose type is the scope of the
+// constraint.
+func (opt *Options) startIdentityConstraint(ele xml.StartElement, unique Unique) {
+	for _, attr := range ele.Attr {
+		switch attr.Name.Local {
+		case "name":
+			unique.Name = attr.Value
+		case "refer":
+			unique.Refer = attr.Value
+		}
+	}
+	var host *Element
+	if opt.ComplexType.Len() > 0 {
+		if elements := opt.ComplexType.Peek().(*ComplexType).Elements; len(elements) > 0 {
+			host = &elements[len(elements)-1]
+		}
+	} else if opt.Element.Len() > 0 {
+		host = opt.Element.Peek().(*Element)
+	}
+	if host == nil {
+		return
+	}
+	if unique.Type = trimNSPrefix(host.Type); unique.Type == "" {
+		unique.Type = trimNSPrefix(host.Name)
+	}
+	opt.Unique.Push(&unique)
+}
+
+// endIdentityConstraint adds the identity constraint being parsed to the
+// proto tree.
+func (opt *Options) endIdentityConstraint() {
+	if opt.Unique.Len() > 0 {
+		opt.ProtoTree = append(opt.ProtoTree, opt.Unique.Pop())
+	}
+}
@@ -10,6 +10,18 @@ package xgen
 
 import "encoding/xml"
 
+// OnWhiteSpace handles parsing event on the whiteSpace start elements.
+func (opt *Options) OnWhiteSpace(ele xml.StartElement, protoTree []interface{}) (err error) {
+	for _, attr := range ele.Attr {
+		if attr.Name.Local == "value" {
+			if opt.SimpleType.Peek() != nil {
+				opt.SimpleType.Peek().(*SimpleType).Restriction.WhiteSpace = attr.Value
+			}
+		}
+	}
+	return
+}
+
 // EndWhiteSpace handles parsing event on the whiteSpace end elements.
 // WhiteSpace specifies how white space (line feeds, tabs, spaces, and
 // carriage returns) is handled.
@@ -2,8 +2,10 @@ package xgen
 
 import (
 	"encoding/xml"
+	"errors"
 	"io/ioutil"
 	"path/filepath"
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -110,3 +112,168 @@ func TestCodeGeneratorFileWithExtension(t *testing.T) {
 		})
 	}
 }
+
+func TestRestrictionEvaluate(t *testing.T) {
+	testCases := []struct {
+		description string
+		restriction Restriction
+		value       string
+		facet       string
+	}{
+		{
+			description: "empty restriction accepts any value",
+			restriction: Restriction{},
+			value:       "anything",
+		},
+		{
+			description: "value within length bounds is valid",
+			restriction: Restriction{MinLength: 1, MaxLength: 4},
+			value:       "abc",
+		},
+		{
+			description: "value shorter than minLength is invalid",
+			restriction: Restriction{MinLength: 1},
+			value:       "",
+			facet:       "minLength",
+		},
+		{
+			description: "length is counted in characters",
+			restriction: Restriction{MaxLength: 2},
+			value:       "привет",
+			facet:       "maxLength",
+		},
+		{
+			description: "value outside enumeration is invalid",
+			restriction: Restriction{Enum: []string{"CRDT", "DBIT"}},
+			value:       "OTHR",
+			facet:       "enumeration",
+		},
+		{
+			description: "pattern must match the whole value",
+			restriction: Restriction{Pattern: regexp.MustCompile("[A-Z]{3}")},
+			value:       "EURO",
+			facet:       "pattern",
+		},
+		{
+			description: "zero minimum is honored",
+			restriction: Restriction{HasMin: true},
+			value:       "-1",
+			facet:       "minInclusive",
+		},
+		{
+			description: "value above maximum is invalid",
+			restriction: Restriction{Max: 10, HasMax: true},
+			value:       "10.5",
+			facet:       "maxInclusive",
+		},
+		{
+			description: "value equal to exclusive minimum is invalid",
+			restriction: Restriction{HasMin: true, MinExclusive: true},
+			value:       "0",
+			facet:       "minExclusive",
+		},
+		{
+			description: "value below exclusive maximum is valid",
+			restriction: Restriction{Max: 10, HasMax: true, MaxExclusive: true},
+			value:       "9.99",
+		},
+		{
+			description: "value equal to exclusive maximum is invalid",
+			restriction: Restriction{Max: 10, HasMax: true, MaxExclusive: true},
+			value:       "10",
+			facet:       "maxExclusive",
+		},
+		{
+			description: "leading and trailing zeros are not counted in totalDigits",
+			restriction: Restriction{TotalDigits: 3, FractionDigits: 2},
+			value:       "-012.50",
+		},
+		{
+			description: "value with more digits than totalDigits is invalid",
+			restriction: Restriction{TotalDigits: 3},
+			value:       "1234",
+			facet:       "totalDigits",
+		},
+		{
+			description: "value with more fraction digits than fractionDigits is invalid",
+			restriction: Restriction{FractionDigits: 2},
+			value:       "0.005",
+			facet:       "fractionDigits",
+		},
+		{
+			description: "non numeric value with range facets is invalid",
+			restriction: Restriction{Min: 1, HasMin: true},
+			value:       "one",
+			facet:       "value",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := tc.restriction.Evaluate(tc.value)
+			if tc.facet == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var facetErr *FacetError
+			require.True(t, errors.As(err, &facetErr))
+			assert.Equal(t, tc.facet, facetErr.Facet)
+		})
+	}
+}
+
+func TestRestrictionNormalize(t *testing.T) {
+	testCases := []struct {
+		description string
+		restriction Restriction
+		value       string
+		expected    string
+	}{
+		{
+			description: "empty restriction preserves the value",
+			restriction: Restriction{},
+			value:       " a\tb ",
+			expected:    " a\tb ",
+		},
+		{
+			description: "replace converts tabs and line feeds into spaces",
+			restriction: Restriction{WhiteSpace: WhiteSpaceReplace},
+			value:       "a\tb\nc",
+			expected:    "a b c",
+		},
+		{
+			description: "collapse trims and joins white space",
+			restriction: Restriction{WhiteSpace: WhiteSpaceCollapse},
+			value:       "  Payment \n  Ref ",
+			expected:    "Payment Ref",
+		},
+		{
+			description: "upper case enumeration implies upper case",
+			restriction: Restriction{Enum: []string{"CRDT", "DBIT"}},
+			value:       " crdt ",
+			expected:    "CRDT",
+		},
+		{
+			description: "upper case pattern implies upper case",
+			restriction: Restriction{Pattern: regexp.MustCompile("[A-Z]{3,3}")},
+			value:       "eur",
+			expected:    "EUR",
+		},
+		{
+			description: "mixed case pattern keeps the case",
+			restriction: Restriction{Pattern: regexp.MustCompile("[A-Za-z]+")},
+			value:       "Eur",
+			expected:    "Eur",
+		},
+		{
+			description: "case insensitive pattern keeps the case",
+			restriction: Restriction{Pattern: regexp.MustCompile("(?i)[A-Z]+")},
+			value:       "Eur",
+			expected:    "Eur",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.restriction.Normalize(tc.value))
+		})
+	}
+}